@@ -1,9 +1,16 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"os"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/golang-migrate/migrate/v4"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/config"
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
@@ -14,15 +21,13 @@ import (
 func main() {
 	// 解析命令行参数
 	configPath := flag.String("config", "config.yaml", "配置文件路径")
-	action := flag.String("action", "up", "迁移操作: up|down|version")
+	action := flag.String("action", "up", "迁移操作: up|down|version|status|goto|force|create")
+	path := flag.String("path", "migrations", "迁移文件目录")
+	version := flag.Int("version", -1, "目标版本，0 表示没有已执行的迁移（goto/force）")
+	name := flag.String("name", "", "新迁移的名称，如 add_symbol_index（create）")
+	dryRun := flag.Bool("dry-run", false, "只打印将要执行的 SQL，不修改数据库（up/down/goto/force）")
 	flag.Parse()
 
-	// 加载配置
-	cfg, err := config.Load(*configPath)
-	if err != nil {
-		log.Fatalf("加载配置失败: %v", err)
-	}
-
 	// 创建 logger
 	logger, err := zap.NewDevelopment()
 	if err != nil {
@@ -31,11 +36,36 @@ func main() {
 	defer logger.Sync()
 
 	// 迁移文件路径
-	migrationsPath, err := filepath.Abs("migrations")
+	migrationsPath, err := filepath.Abs(*path)
 	if err != nil {
 		logger.Fatal("获取迁移文件路径失败", zap.Error(err))
 	}
 
+	// 创建迁移文件不需要连接数据库
+	if *action == "create" {
+		if *name == "" {
+			logger.Fatal("创建迁移需要 -name")
+		}
+		upFile, downFile, err := database.CreateMigration(migrationsPath, *name)
+		if err != nil {
+			logger.Fatal("创建迁移文件失败", zap.Error(err))
+		}
+		fmt.Println(upFile)
+		fmt.Println(downFile)
+		return
+	}
+
+	migrations, err := database.ListMigrations(migrationsPath)
+	if err != nil {
+		logger.Fatal("读取迁移文件失败", zap.Error(err))
+	}
+
+	// 加载配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
 	// 迁移配置
 	migrateConfig := database.MigrateConfig{
 		MigrationsPath: migrationsPath,
@@ -46,14 +76,28 @@ func main() {
 	// 执行操作
 	switch *action {
 	case "up":
+		if *dryRun {
+			current := currentVersion(logger, migrateConfig)
+			printPlan(logger, migrations, current, database.LatestMigrationVersion(migrations))
+			return
+		}
 		if err := database.RunMigrations(migrateConfig); err != nil {
-			logger.Fatal("执行迁移失败", zap.Error(err))
+			fatalMigration(logger, "执行迁移失败", err)
 		}
 		logger.Info("迁移执行成功")
 
 	case "down":
+		if *dryRun {
+			current := currentVersion(logger, migrateConfig)
+			if current == 0 {
+				fmt.Println("-- 没有已执行的迁移")
+				return
+			}
+			printPlan(logger, migrations, current, database.PreviousMigrationVersion(migrations, current))
+			return
+		}
 		if err := database.RollbackMigration(migrateConfig); err != nil {
-			logger.Fatal("回滚迁移失败", zap.Error(err))
+			fatalMigration(logger, "回滚迁移失败", err)
 		}
 		logger.Info("迁移回滚成功")
 
@@ -67,7 +111,142 @@ func main() {
 			zap.Bool("dirty", dirty),
 		)
 
+	case "status":
+		version, dirty, err := database.GetMigrationVersion(migrateConfig)
+		if err != nil {
+			logger.Fatal("获取迁移版本失败", zap.Error(err))
+		}
+		printStatus(migrations, version, dirty)
+
+	case "goto":
+		target := requireVersion(logger, migrations, *version)
+		if *dryRun {
+			printPlan(logger, migrations, currentVersion(logger, migrateConfig), target)
+			return
+		}
+		if err := database.MigrateTo(migrateConfig, target); err != nil {
+			fatalMigration(logger, "迁移到指定版本失败", err)
+		}
+		logger.Info("迁移到指定版本成功", zap.Uint("version", target))
+
+	case "force":
+		target := requireVersion(logger, migrations, *version)
+		if *dryRun {
+			version, dirty, err := database.GetMigrationVersion(migrateConfig)
+			if err != nil {
+				logger.Fatal("获取迁移版本失败", zap.Error(err))
+			}
+			fmt.Printf("-- 将迁移版本从 %d（dirty=%t）设置为 %d，不执行任何 SQL\n", version, dirty, target)
+			return
+		}
+		if err := database.ForceMigrationVersion(migrateConfig, target); err != nil {
+			logger.Fatal("设置迁移版本失败", zap.Error(err))
+		}
+		logger.Info("迁移版本已设置", zap.Uint("version", target))
+
 	default:
 		logger.Fatal("未知的迁移操作", zap.String("action", *action))
 	}
 }
+
+// requireVersion 校验 -version：必须是已有的迁移版本或 0
+func requireVersion(logger *zap.Logger, migrations []*database.Migration, version int) uint {
+	if version < 0 {
+		logger.Fatal("需要 -version")
+	}
+	if version == 0 {
+		return 0
+	}
+	for _, migration := range migrations {
+		if migration.Version == uint(version) {
+			return uint(version)
+		}
+	}
+	logger.Fatal("迁移版本不存在", zap.Int("version", version))
+	return 0
+}
+
+// currentVersion 获取当前版本，dirty 时无法计算要执行的迁移
+func currentVersion(logger *zap.Logger, migrateConfig database.MigrateConfig) uint {
+	version, dirty, err := database.GetMigrationVersion(migrateConfig)
+	if err != nil {
+		logger.Fatal("获取迁移版本失败", zap.Error(err))
+	}
+	if dirty {
+		fatalMigration(logger, "当前迁移处于 dirty 状态", migrate.ErrDirty{Version: int(version)})
+	}
+	return version
+}
+
+// fatalMigration 输出迁移错误，dirty 状态时提示如何恢复
+func fatalMigration(logger *zap.Logger, msg string, err error) {
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		logger.Fatal(msg,
+			zap.Error(err),
+			zap.String("hint", dirtyHint(uint(dirty.Version))),
+		)
+	}
+	logger.Fatal(msg, zap.Error(err))
+}
+
+// dirtyHint 迁移中途失败后的恢复方法
+func dirtyHint(version uint) string {
+	return fmt.Sprintf("迁移 %d 执行失败，手动修复数据库后执行 -action force -version <版本> 清除 dirty："+
+		"撤销了该迁移的改动时设置为上一个版本，手动完成了该迁移时设置为 %d", version, version)
+}
+
+// printPlan 打印从 current 到 target 将要执行的 SQL
+func printPlan(logger *zap.Logger, migrations []*database.Migration, current, target uint) {
+	steps, err := database.PlanMigrations(migrations, current, target)
+	if err != nil {
+		logger.Fatal("计算迁移计划失败", zap.Error(err))
+	}
+
+	fmt.Printf("-- dry-run: 当前版本 %d，目标版本 %d，共 %d 个迁移，不会修改数据库\n", current, target, len(steps))
+	for _, step := range steps {
+		content, err := os.ReadFile(step.File)
+		if err != nil {
+			logger.Fatal("读取迁移文件失败", zap.String("file", step.File), zap.Error(err))
+		}
+		fmt.Printf("\n-- ===== %s =====\n", filepath.Base(step.File))
+		fmt.Print(string(content))
+		if !strings.HasSuffix(string(content), "\n") {
+			fmt.Println()
+		}
+	}
+}
+
+// printStatus 打印每个迁移是否已执行
+func printStatus(migrations []*database.Migration, current uint, dirty bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tFILE")
+
+	pending := 0
+	found := current == 0
+	for _, migration := range migrations {
+		status := "pending"
+		switch {
+		case migration.Version == current && dirty:
+			status = "dirty"
+			found = true
+		case migration.Version == current:
+			status = "applied (current)"
+			found = true
+		case migration.Version < current:
+			status = "applied"
+		default:
+			pending++
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", migration.Version, migration.Name, status, filepath.Base(migration.UpFile))
+	}
+	w.Flush()
+
+	fmt.Printf("\n当前版本: %d，待执行: %d\n", current, pending)
+	if !found {
+		fmt.Printf("警告: 数据库版本 %d 在迁移目录中不存在\n", current)
+	}
+	if dirty {
+		fmt.Println(dirtyHint(current))
+	}
+}
@@ -0,0 +1,1043 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/api"
+	"github.com/haxrd/cryptosignal-hunter/internal/archive"
+	"github.com/haxrd/cryptosignal-hunter/internal/bitget"
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/middleware"
+	"github.com/haxrd/cryptosignal-hunter/internal/websocket"
+)
+
+const (
+	// redisOperationTimeout 单次 Redis 操作超时
+	redisOperationTimeout = 2 * time.Second
+
+	// singletonJobsLock 单实例任务的选主锁
+	singletonJobsLock = "singleton_jobs"
+
+	// collectorGroup 分片采集的成员表
+	collectorGroup = "collector"
+
+	// defaultRebalanceInterval 未配置时的分片调整间隔
+	defaultRebalanceInterval = 3 * time.Second
+
+	// storageCheckTimeout 启动时检查保留策略的超时
+	storageCheckTimeout = 10 * time.Second
+)
+
+// lifecycle 可启动、停止的组件（PriceProcessor 的实现提供清理协程）
+type lifecycle interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// application 服务组合根，负责创建、启动并按依赖顺序关闭所有子系统
+type application struct {
+	cfg    *config.Config
+	logger *zap.Logger
+
+	// 存储
+	db    *gorm.DB
+	redis *cache.Client
+
+	// DAO 与缓存
+	symbolDAO           dao.SymbolDAO
+	klineDAO            dao.KlineDAO
+	priceTickDAO        dao.PriceTickDAO
+	priceChangeRateDAO  dao.PriceChangeRateDAO
+	monitoringConfigDAO *dao.MonitoringConfigDAO
+	storageDAO          dao.StoragePolicyDAO
+	archiveDAO          dao.ArchiveDAO
+	archiver            *archive.Archiver      // 过期数据归档，未开启时为空
+	history             *archive.HistoryReader // 合并归档和数据库的历史读取，未开启归档时为空
+	priceCache          cache.PriceCache
+	scanner             cache.ScannerIndex
+
+	// 采集管道
+	persistence data_collection.AsyncPersistence
+	processor   data_collection.PriceProcessor
+	processing  bool                             // 价格处理器的清理协程是否已启动
+	candles     data_collection.CandleAggregator // 本地K线聚合，未开启时为空
+	anomaly     data_collection.AnomalyDetector  // 价格异常检测，未开启时为空
+	newExchange func() exchangeClient
+	pipeline    *tickerPipeline
+	membership  *cache.Membership // 分片采集的成员表，未开启分片时为空
+
+	// 多副本部署时只有领导者运行单实例任务
+	instanceID string
+	elector    *cache.LeaderElector
+
+	// 对外服务
+	wsServer   websocket.WebSocketServer
+	httpServer *http.Server
+
+	// 后台任务
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	jobsCancel context.CancelFunc
+	jobsWg     sync.WaitGroup
+	errCh      chan error
+}
+
+// newApplication 连接数据库和 Redis 并创建所有组件，不启动任何后台任务
+func newApplication(cfg *config.Config, logger *zap.Logger) (*application, error) {
+	app := &application{
+		cfg:    cfg,
+		logger: logger,
+		errCh:  make(chan error, 2),
+	}
+
+	db, err := database.Connect(&cfg.Database, logger)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	app.db = db
+
+	app.instanceID = cfg.Server.InstanceID
+	if app.instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			database.Close()
+			return nil, fmt.Errorf("获取主机名失败: %w", err)
+		}
+		app.instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	redisClient, err := cache.NewClient(redisConfig(&cfg.Redis), logger)
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("连接Redis失败: %w", err)
+	}
+	app.redis = redisClient
+
+	app.symbolDAO = dao.NewSymbolDAO(db, logger)
+	app.klineDAO = dao.NewKlineDAO(db, logger)
+	app.priceTickDAO = dao.NewPriceTickDAO(db, logger)
+	app.priceChangeRateDAO = dao.NewPriceChangeRateDAO(db, logger)
+	app.monitoringConfigDAO = dao.NewMonitoringConfigDAO(db, logger)
+	app.storageDAO = dao.NewStoragePolicyDAO(db, logger)
+	app.archiveDAO = dao.NewArchiveDAO(db, logger)
+	app.priceCache = cache.NewPriceCache(redisClient)
+	app.scanner = cache.NewScannerIndex(redisClient)
+
+	if cfg.Storage.Archive.Enabled {
+		if err := app.newArchiver(); err != nil {
+			app.closeStorage()
+			return nil, err
+		}
+	}
+
+	if cfg.Leader.Enabled {
+		leaderConfig := cache.DefaultLeaderConfig()
+		if cfg.Leader.LeaseTTL > 0 {
+			leaderConfig.LeaseTTL = cfg.Leader.LeaseTTL
+		}
+		if cfg.Leader.RenewInterval > 0 {
+			leaderConfig.RenewInterval = cfg.Leader.RenewInterval
+		}
+		if cfg.Leader.RetryInterval > 0 {
+			leaderConfig.RetryInterval = cfg.Leader.RetryInterval
+		}
+		lock := cache.NewLeaseLock(redisClient, singletonJobsLock, app.instanceID, logger)
+		app.elector = cache.NewLeaderElector(lock, leaderConfig, logger)
+	}
+
+	if cfg.WebSocket.Enabled {
+		wsServer, err := app.newWebSocketServer()
+		if err != nil {
+			app.closeStorage()
+			return nil, err
+		}
+		app.wsServer = wsServer
+	}
+
+	if cfg.Collector.Enabled {
+		if err := app.newCollector(); err != nil {
+			app.closeStorage()
+			return nil, err
+		}
+	}
+
+	routerConfig := &api.RouterConfig{
+		Logger:              logger,
+		Mode:                cfg.Server.Mode,
+		SymbolDAO:           app.symbolDAO,
+		KlineDAO:            app.klineDAO,
+		PriceTickDAO:        app.priceTickDAO,
+		PriceChangeRateDAO:  app.priceChangeRateDAO,
+		MonitoringConfigDAO: app.monitoringConfigDAO,
+		ScannerIndex:        app.scanner,
+	}
+	if app.wsServer != nil {
+		routerConfig.StreamHandler = app.wsServer.ServeSSE
+	}
+	if database.Replicas != nil {
+		routerConfig.ReadYourWritesWindow = database.Replicas.ReadYourWritesWindow()
+	}
+	// 开启归档时导出可以读取已被保留策略删除的日期
+	if app.history != nil {
+		routerConfig.ExportSource = app.history
+	} else {
+		routerConfig.ExportSource = app.archiveDAO
+	}
+	routerConfig.ExportLimits = api.ExportLimits{
+		MaxRange:      cfg.Storage.Export.MaxRange,
+		MaxConcurrent: cfg.Storage.Export.MaxConcurrent,
+	}
+	// 启用认证时导出需要 read 权限的 Token
+	authManager, err := app.newAuthManager()
+	if err != nil {
+		app.closeStorage()
+		return nil, err
+	}
+	if authManager != nil {
+		routerConfig.ExportAuth = middleware.RequirePermission(authManager, "read")
+	}
+	// 存储策略和从库状态接口各自开启，共用 admin 认证
+	replicaAdmin := cfg.Database.ReplicaAdminAPI && database.Replicas != nil
+	if cfg.Storage.AdminAPI || replicaAdmin {
+		adminAuth, err := app.newAdminAuth()
+		if err != nil {
+			app.closeStorage()
+			return nil, err
+		}
+		routerConfig.AdminAuth = adminAuth
+	}
+	if cfg.Storage.AdminAPI {
+		routerConfig.StoragePolicyDAO = app.storageDAO
+	}
+	if replicaAdmin {
+		routerConfig.ReplicaMonitor = database.Replicas
+	}
+
+	// 不设置 WriteTimeout：SSE 响应是长连接，写超时会在到期后切断推送，
+	// SSE 的每次写入由 ServeSSE 自行设置写截止时间
+	app.httpServer = &http.Server{
+		Addr:        fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:     api.SetupRouter(routerConfig),
+		ReadTimeout: cfg.Server.ReadTimeout,
+		IdleTimeout: cfg.Server.IdleTimeout,
+	}
+
+	return app, nil
+}
+
+// newArchiver 创建过期数据归档任务
+func (app *application) newArchiver() error {
+	archiveConfig := &app.cfg.Storage.Archive
+	store, err := archive.NewStore(archiveConfig)
+	if err != nil {
+		return fmt.Errorf("创建归档存储失败: %w", err)
+	}
+
+	archiverConfig := archive.NewArchiverConfig(archiveConfig)
+	archiver, err := archive.NewArchiver(archiverConfig, app.archiveDAO, store, app.logger)
+	if err != nil {
+		return fmt.Errorf("创建归档任务失败: %w", err)
+	}
+	app.archiver = archiver
+	app.history = archive.NewHistoryReader(app.archiveDAO, store, app.logger)
+
+	// 管理接口拒绝把归档表的保留期改为不长于归档延迟加一天，已有的过短保留期只告警
+	retention := archiverConfig.Retention()
+	app.storageDAO = dao.NewStoragePolicyDAOWithArchive(app.db, retention, app.logger)
+	app.checkArchiveRetention(retention)
+	return nil
+}
+
+// checkArchiveRetention 检查当前保留期是否会在归档之前删除数据
+func (app *application) checkArchiveRetention(retention *dao.ArchiveRetention) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageCheckTimeout)
+	defer cancel()
+	for _, err := range archive.CheckRetention(ctx, app.storageDAO, retention) {
+		app.logger.Warn("归档表的保留期过短，数据可能在归档之前被删除",
+			zap.Duration("archive_delay", retention.Delay),
+			zap.Error(err),
+		)
+	}
+}
+
+// newWebSocketServer 创建 WebSocket 服务器并注入快照、过滤配置、认证和背板
+func (app *application) newWebSocketServer() (websocket.WebSocketServer, error) {
+	cfg := app.cfg
+
+	serverConfig := websocket.DefaultServerConfig()
+	serverConfig.Host = cfg.WebSocket.Host
+	serverConfig.Port = cfg.WebSocket.Port
+	if cfg.WebSocket.MaxConnections > 0 {
+		serverConfig.MaxConnections = cfg.WebSocket.MaxConnections
+	}
+	serverConfig.AllowedOrigins = cfg.WebSocket.AllowedOrigins
+
+	wsServer := websocket.NewWebSocketServer(serverConfig, app.logger)
+	wsServer.SetSnapshotProvider(websocket.NewPriceCacheSnapshotProvider(app.priceCache))
+	wsServer.SetMonitoringConfigSource(app.monitoringConfigDAO)
+
+	// 认证未启用时不设置认证管理器，握手不要求 Token
+	authManager, err := app.newAuthManager()
+	if err != nil {
+		return nil, err
+	}
+	if authManager != nil {
+		wsServer.SetAuthManager(authManager)
+	}
+
+	if cfg.WebSocket.Backplane {
+		wsServer.SetBackplane(websocket.NewRedisBackplane(app.redis, app.instanceID, app.logger))
+	}
+
+	return wsServer, nil
+}
+
+// newAuthManager 按认证配置创建认证管理器，认证未启用时返回空
+func (app *application) newAuthManager() (data_collection.AuthManager, error) {
+	return data_collection.NewAuthManagerFromConfig(&app.cfg.Auth, app.logger)
+}
+
+// newAdminAuth 创建管理接口的认证中间件
+// 管理接口可以修改保留策略、压缩分块、删除K线和触发从库检查，未启用认证时拒绝启动，除非显式设置 storage.admin_api_insecure
+func (app *application) newAdminAuth() (gin.HandlerFunc, error) {
+	authManager, err := app.newAuthManager()
+	if err != nil {
+		return nil, err
+	}
+	if authManager != nil {
+		return middleware.RequirePermission(authManager, "admin"), nil
+	}
+	if !app.cfg.Storage.AdminAPIInsecure {
+		return nil, fmt.Errorf("开启管理接口时必须启用认证（auth.enabled），或显式设置 storage.admin_api_insecure")
+	}
+	app.logger.Warn("管理接口未启用认证（storage.admin_api_insecure），任何人都可以修改保留策略和删除数据")
+	return nil, nil
+}
+
+// newCollector 创建行情采集管道：交易所 WebSocket -> 价格处理 -> 缓存/扫描器/推送，变化率异步落库
+func (app *application) newCollector() error {
+	cfg := app.cfg
+
+	windows, err := data_collection.ParseTimeWindows(cfg.Collector.TimeWindows)
+	if err != nil {
+		return fmt.Errorf("解析采集时间窗口失败: %w", err)
+	}
+	// 监控配置读取失败时先使用配置文件的窗口，之后定期同步
+	if merged, err := app.collectorTimeWindows(context.Background()); err != nil {
+		app.logger.Warn("读取监控配置时间窗口失败", zap.Error(err))
+	} else {
+		windows = merged
+	}
+
+	writer, persistenceConfig, err := app.newDataWriter()
+	if err != nil {
+		return err
+	}
+	app.persistence = data_collection.NewAsyncPersistence(persistenceConfig, writer, app.logger)
+
+	processorConfig := data_collection.DefaultProcessorConfig()
+	if len(windows) > 0 {
+		processorConfig.TimeWindows = windows
+	}
+	app.processor = data_collection.NewPriceProcessorWithPersistence(processorConfig, app.persistence, app.logger)
+
+	exchangeConfig := bitget.DefaultWebSocketConfig()
+	exchangeConfig.URL = cfg.Bitget.WebSocketURL
+	if cfg.Bitget.PingInterval > 0 {
+		exchangeConfig.PingInterval = cfg.Bitget.PingInterval
+	}
+	if cfg.Bitget.PongTimeout > 0 {
+		exchangeConfig.PongWait = cfg.Bitget.PongTimeout
+	}
+	if cfg.Bitget.MaxReconnectAttempts > 0 {
+		exchangeConfig.MaxReconnectAttempts = cfg.Bitget.MaxReconnectAttempts
+	}
+	if cfg.Bitget.ReconnectBaseDelay > 0 {
+		exchangeConfig.ReconnectInterval = cfg.Bitget.ReconnectBaseDelay
+	}
+	// 客户端关闭后不能重新连接，每个连接使用新的客户端
+	app.newExchange = func() exchangeClient {
+		return bitget.NewWebSocketClient(exchangeConfig, app.logger)
+	}
+
+	if cfg.Collector.Sharding {
+		memberTTL := cfg.Collector.MemberTTL
+		if memberTTL <= 0 {
+			memberTTL = 3 * app.rebalanceInterval()
+		}
+		app.membership = cache.NewMembership(app.redis, collectorGroup, app.instanceID, memberTTL, app.logger)
+	}
+
+	if cfg.Collector.Candles {
+		if err := app.newCandleAggregator(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Collector.Anomaly {
+		store := data_collection.NewRedisAnomalyStateStore(&anomalyStateKV{client: app.redis.GetClient()}, 0)
+		app.anomaly = data_collection.NewAnomalyDetectorWithStateStore(anomalyRules(), store, app.logger)
+	}
+
+	app.pipeline = &tickerPipeline{
+		processor:  app.processor,
+		candles:    app.candles,
+		activity:   data_collection.NewMarketActivityDetector(nil, app.logger),
+		anomaly:    app.anomaly,
+		priceCache: app.priceCache,
+		scanner:    app.scanner,
+		wsServer:   app.wsServer,
+		timeout:    redisOperationTimeout,
+		logger:     app.logger,
+	}
+	if cfg.Collector.PersistTicks {
+		app.pipeline.persistence = app.persistence
+	}
+	return nil
+}
+
+// newCandleAggregator 创建本地K线聚合器，K线推送到 WebSocket，收盘K线经异步持久化落库
+func (app *application) newCandleAggregator() error {
+	cfg := app.cfg.Collector
+	candleConfig := data_collection.DefaultCandleAggregatorConfig()
+	if len(cfg.CandleIntervals) > 0 {
+		candleConfig.Intervals = cfg.CandleIntervals
+	}
+	if cfg.CandleWatermark > 0 {
+		candleConfig.Watermark = cfg.CandleWatermark
+	}
+	if cfg.CandlePersistIntervals != nil {
+		candleConfig.PersistIntervals = cfg.CandlePersistIntervals
+	}
+
+	var handler data_collection.CandleHandler
+	if app.wsServer != nil {
+		handler = &candlePublisher{wsServer: app.wsServer, logger: app.logger}
+	}
+
+	candles, err := data_collection.NewCandleAggregator(candleConfig, app.persistence, handler, app.logger)
+	if err != nil {
+		return fmt.Errorf("创建K线聚合器失败: %w", err)
+	}
+	app.candles = candles
+	return nil
+}
+
+// newDataWriter 按采集配置创建持久化写入器：变化率和聚合K线始终经 DAO 写入，
+// 原始行情按 ingest_mode 使用 COPY 或批量 INSERT
+func (app *application) newDataWriter() (data_collection.DataWriter, *data_collection.PersistenceConfig, error) {
+	cfg := app.cfg.Collector
+	persistenceConfig := data_collection.DefaultPersistenceConfig()
+
+	if !cfg.PersistTicks {
+		writer := data_collection.NewDatabaseWriterWithStores(persistenceConfig, data_collection.DatabaseWriterStores{
+			ChangeRates: app.priceChangeRateDAO,
+			Klines:      app.klineDAO,
+		}, app.logger)
+		return writer, persistenceConfig, nil
+	}
+
+	if cfg.PersistBatchSize > 0 {
+		persistenceConfig.BatchSize = cfg.PersistBatchSize
+	}
+	if cfg.PersistQueueSize > 0 {
+		persistenceConfig.QueueSize = cfg.PersistQueueSize
+	}
+	// 每条 Ticker 的 ID 已唯一，去重缓存会随行情速率无限增长；多个交易所连接并发提交，
+	// 批次内的提交时间不保证有序，完整性检查会整批拒绝。写入器自行校验每条数据
+	persistenceConfig.EnableDeduplication = false
+	persistenceConfig.EnableIntegrityCheck = false
+
+	switch cfg.IngestMode {
+	case "", "copy":
+		fallback := data_collection.NewDatabaseWriterWithChangeRateStore(persistenceConfig, app.priceChangeRateDAO, app.logger)
+		copier := dao.NewCopyIngester(app.db, app.logger)
+		return data_collection.NewCopyWriter(persistenceConfig, copier, fallback, app.logger), persistenceConfig, nil
+	case "insert":
+		writer := data_collection.NewDatabaseWriterWithStores(persistenceConfig, data_collection.DatabaseWriterStores{
+			ChangeRates: app.priceChangeRateDAO,
+			Ticks:       app.priceTickDAO,
+			Klines:      app.klineDAO,
+		}, app.logger)
+		return writer, persistenceConfig, nil
+	default:
+		return nil, nil, fmt.Errorf("未知的行情写入方式: %s", cfg.IngestMode)
+	}
+}
+
+// start 启动所有子系统，任一组件启动失败时关闭已启动的组件
+func (app *application) start() error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	app.cancel = cancel
+
+	if err := app.startComponents(runCtx); err != nil {
+		shutdownCtx, stop := context.WithTimeout(context.Background(), app.cfg.Server.ShutdownTimeout)
+		defer stop()
+		app.shutdown(shutdownCtx)
+		return err
+	}
+
+	// 单实例任务和分片采集最先停止，单独使用可取消的上下文
+	jobsCtx, jobsCancel := context.WithCancel(runCtx)
+	app.jobsCancel = jobsCancel
+
+	// 分片采集在每个实例上运行，各实例只订阅分配给自己的交易对
+	if app.pipeline != nil && app.membership != nil {
+		app.jobsWg.Add(1)
+		go func() {
+			defer app.jobsWg.Done()
+			app.runCollector(jobsCtx, app.membership, nil)
+		}()
+	}
+
+	app.jobsWg.Add(1)
+	go func() {
+		defer app.jobsWg.Done()
+		if app.elector != nil {
+			app.elector.Run(jobsCtx, app.runSingletonJobs)
+			return
+		}
+		app.runSingletonJobs(jobsCtx, 0)
+	}()
+
+	return nil
+}
+
+func (app *application) startComponents(runCtx context.Context) error {
+	// 先启动下游：持久化和价格处理器需要在第一条行情到达前就绪
+	if app.persistence != nil {
+		if err := app.persistence.Start(runCtx); err != nil {
+			return fmt.Errorf("启动异步持久化失败: %w", err)
+		}
+	}
+	if processor, ok := app.processor.(lifecycle); ok {
+		if err := processor.Start(runCtx); err != nil {
+			return fmt.Errorf("启动价格处理器失败: %w", err)
+		}
+		app.processing = true
+	}
+	if app.candles != nil {
+		if err := app.candles.Start(runCtx); err != nil {
+			return fmt.Errorf("启动K线聚合器失败: %w", err)
+		}
+	}
+
+	if app.pipeline != nil {
+		app.wg.Add(1)
+		go func() {
+			defer app.wg.Done()
+			app.runTimeWindowSync(runCtx)
+		}()
+	}
+
+	if app.anomaly != nil {
+		// 恢复失败时从零开始学习，不影响启动
+		restoreCtx, cancel := context.WithTimeout(runCtx, redisOperationTimeout)
+		if err := app.anomaly.RestoreState(restoreCtx); err != nil {
+			app.logger.Warn("恢复异常检测状态失败", zap.Error(err))
+		}
+		cancel()
+
+		app.wg.Add(1)
+		go func() {
+			defer app.wg.Done()
+			app.runAnomalySnapshots(runCtx)
+		}()
+	}
+
+	if app.wsServer != nil {
+		if err := app.wsServer.Start(runCtx); err != nil {
+			return fmt.Errorf("启动WebSocket服务器失败: %w", err)
+		}
+	}
+
+	// 从库延迟检查在每个实例上运行，各实例按自己的检查结果路由读取
+	if database.Replicas != nil {
+		app.wg.Add(1)
+		go func() {
+			defer app.wg.Done()
+			database.Replicas.Run(runCtx)
+		}()
+	}
+
+	app.wg.Add(1)
+	go func() {
+		defer app.wg.Done()
+		app.logger.Info("HTTP服务器监听中", zap.String("address", app.httpServer.Addr))
+		if err := app.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			app.errCh <- fmt.Errorf("HTTP服务器异常退出: %w", err)
+		}
+	}()
+
+	return nil
+}
+
+// collectorTimeWindows 合并配置文件和所有监控配置的时间窗口
+func (app *application) collectorTimeWindows(ctx context.Context) ([]data_collection.TimeWindow, error) {
+	configWindows, err := app.monitoringConfigDAO.WithContext(ctx).TimeWindows()
+	if err != nil {
+		return nil, err
+	}
+	return data_collection.ParseTimeWindows(append([][]string{app.cfg.Collector.TimeWindows}, configWindows...)...)
+}
+
+// runTimeWindowSync 定期同步价格处理器的时间窗口，监控配置在任一实例上增删改后都会生效
+func (app *application) runTimeWindowSync(ctx context.Context) {
+	interval := app.cfg.Collector.TimeWindowSyncInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := app.syncTimeWindows(ctx); err != nil {
+				app.logger.Warn("同步时间窗口失败", zap.Error(err))
+			}
+		}
+	}
+}
+
+// syncTimeWindows 时间窗口合集变化时更新价格处理器
+func (app *application) syncTimeWindows(ctx context.Context) error {
+	windows, err := app.collectorTimeWindows(ctx)
+	if err != nil {
+		return err
+	}
+	if slices.Equal(windows, app.processor.GetTimeWindows()) {
+		return nil
+	}
+	return app.processor.SetTimeWindows(windows)
+}
+
+// runAnomalySnapshots 定期将异常检测的自适应统计状态快照到 Redis，重启后从快照恢复
+func (app *application) runAnomalySnapshots(ctx context.Context) {
+	interval := app.cfg.Collector.AnomalySnapshotInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.snapshotAnomalyState(ctx)
+		}
+	}
+}
+
+// snapshotAnomalyState 快照异常检测状态，失败时保留上一次快照
+func (app *application) snapshotAnomalyState(ctx context.Context) {
+	snapshotCtx, cancel := context.WithTimeout(ctx, redisOperationTimeout)
+	defer cancel()
+	if err := app.anomaly.SnapshotState(snapshotCtx); err != nil {
+		app.logger.Warn("异常检测状态快照失败", zap.Error(err))
+	}
+}
+
+// runSingletonJobs 运行整个集群只需要一份的任务：扫描器清理，以及未开启分片时的行情采集
+// （写入共享的 Redis 和数据库，并经背板推送到所有副本）。启用选主时只在领导者上运行，
+// ctx 在失去领导权时取消；租约过期到续约失败之间旧领导者仍可能运行，因此每个任务在每次
+// 写入共享存储前校验栅栏令牌，令牌已被新的领导者取代时立即停止所有单实例任务
+func (app *application) runSingletonJobs(ctx context.Context, token int64) {
+	app.logger.Info("开始运行单实例任务",
+		zap.String("instance_id", app.instanceID),
+		zap.Int64("fencing_token", token),
+	)
+
+	jobsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fence := &singletonFence{elector: app.elector, token: token, cancel: cancel, logger: app.logger}
+
+	var jobs sync.WaitGroup
+	if ttl := app.cfg.Collector.ScannerTTL; ttl > 0 && app.pipeline != nil {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			app.pruneScanner(jobsCtx, ttl, fence)
+		}()
+	}
+
+	if interval := app.cfg.Storage.PurgeInterval; interval > 0 {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			app.purgeKlines(jobsCtx, interval, fence)
+		}()
+	}
+
+	if app.archiver != nil {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			app.archiver.Run(jobsCtx, fence.check)
+		}()
+	}
+
+	if app.pipeline != nil && app.membership == nil {
+		app.runCollector(jobsCtx, nil, fence)
+	} else {
+		<-jobsCtx.Done()
+	}
+
+	cancel()
+	jobs.Wait()
+	app.logger.Info("单实例任务已停止", zap.Int64("fencing_token", token))
+}
+
+// singletonFence 单实例任务的栅栏令牌校验，elector 为空（未启用选主）时总是允许
+type singletonFence struct {
+	elector *cache.LeaderElector
+	token   int64
+	// cancel 取消所有单实例任务
+	cancel context.CancelFunc
+	logger *zap.Logger
+}
+
+// check 在 Redis 中比较栅栏令牌，令牌已失效时取消所有单实例任务
+// Redis 不可用时返回错误但不取消任务，由选主在续约失败时让出领导权
+func (f *singletonFence) check(ctx context.Context) error {
+	if f == nil || f.elector == nil {
+		return nil
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, redisOperationTimeout)
+	defer cancel()
+	err := f.elector.Verify(checkCtx, f.token)
+	if errors.Is(err, cache.ErrLockNotHeld) {
+		f.logger.Warn("栅栏令牌已失效，停止单实例任务", zap.Int64("fencing_token", f.token), zap.Error(err))
+		f.cancel()
+	}
+	return err
+}
+
+// allow 校验栅栏令牌，失败时记录日志并返回 false，调用方跳过本次执行
+func (f *singletonFence) allow(ctx context.Context, job string) bool {
+	if err := f.check(ctx); err != nil {
+		if ctx.Err() == nil {
+			f.logger.Warn("栅栏令牌校验失败，跳过本次执行", zap.String("job", job), zap.Error(err))
+		}
+		return false
+	}
+	return true
+}
+
+// runCollector 采集行情直到 ctx 取消，每个调整间隔重新加载交易对并调整订阅
+// membership 不为空时为分片采集：按存活成员构建一致性哈希环，只订阅分配给本实例的交易对，
+// 实例加入、离开或心跳超时后各实例在下一个调整间隔内完成重新分配
+// fence 不为空时（单实例采集）每次调整前校验栅栏令牌，令牌失效时 ctx 随之取消
+func (app *application) runCollector(ctx context.Context, membership *cache.Membership, fence *singletonFence) {
+	pool := newCollectorPool(app.newExchange, app.pipeline.handleTicker,
+		app.cfg.Collector.MaxSubscriptionsPerConn, app.logger)
+	ring := data_collection.NewHashRing(0, nil)
+
+	defer func() {
+		pool.close()
+		if membership == nil {
+			return
+		}
+		// 主动离开使其他实例立即接管，ctx 已取消，使用独立的超时
+		leaveCtx, cancel := context.WithTimeout(context.Background(), redisOperationTimeout)
+		defer cancel()
+		if err := membership.Leave(leaveCtx); err != nil {
+			app.logger.Warn("离开采集成员表失败", zap.Error(err))
+		}
+	}()
+
+	ticker := time.NewTicker(app.rebalanceInterval())
+	defer ticker.Stop()
+
+	var current []string
+	for {
+		if fence.allow(ctx, "collector") {
+			current = app.rebalanceCollector(ctx, pool, ring, membership, current)
+		}
+
+		select {
+		case <-ctx.Done():
+			app.logger.Info("行情采集已停止")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rebalanceCollector 心跳、加载交易对并调整订阅，返回调整后已订阅的交易对
+// 无法确定分片时（Redis 或数据库不可用）保持当前订阅不变
+func (app *application) rebalanceCollector(ctx context.Context, pool *collectorPool, ring *data_collection.HashRing,
+	membership *cache.Membership, current []string) []string {
+	var members []string
+	if membership != nil {
+		opCtx, cancel := context.WithTimeout(ctx, redisOperationTimeout)
+		defer cancel()
+
+		if err := membership.Heartbeat(opCtx); err != nil {
+			app.logger.Warn("采集成员心跳失败，保持当前订阅", zap.Error(err))
+			return current
+		}
+		var err error
+		members, err = membership.Members(opCtx)
+		if err != nil {
+			app.logger.Warn("获取采集成员失败，保持当前订阅", zap.Error(err))
+			return current
+		}
+	}
+
+	symbols, err := app.collectorSymbols(ctx)
+	if err != nil {
+		app.logger.Warn("加载采集交易对失败，保持当前订阅", zap.Error(err))
+		return current
+	}
+	if membership != nil {
+		ring.Set(members)
+		symbols = ring.Shard(symbols, membership.MemberID())
+	}
+
+	if err := pool.apply(ctx, symbols); err != nil && ctx.Err() == nil {
+		app.logger.Warn("调整行情订阅失败，下次调整时重试", zap.Error(err))
+	}
+
+	subscribed := pool.symbols()
+	if !slices.Equal(subscribed, current) {
+		app.logger.Info("行情订阅已调整",
+			zap.Strings("members", members),
+			zap.Int("symbols", len(subscribed)),
+			zap.Int("connections", pool.connections()),
+		)
+	}
+	return subscribed
+}
+
+// collectorSymbols 返回需要采集的交易对：优先使用配置，否则使用数据库中的活跃交易对
+func (app *application) collectorSymbols(ctx context.Context) ([]string, error) {
+	if len(app.cfg.Collector.Symbols) > 0 {
+		return app.cfg.Collector.Symbols, nil
+	}
+
+	active, err := app.symbolDAO.List(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("加载活跃交易对失败: %w", err)
+	}
+	symbols := make([]string, 0, len(active))
+	for _, symbol := range active {
+		symbols = append(symbols, symbol.Symbol)
+	}
+	return symbols, nil
+}
+
+// rebalanceInterval 分片调整间隔
+func (app *application) rebalanceInterval() time.Duration {
+	if app.cfg.Collector.RebalanceInterval > 0 {
+		return app.cfg.Collector.RebalanceInterval
+	}
+	return defaultRebalanceInterval
+}
+
+// pruneScanner 定期从扫描器排行中移除长时间未更新的交易对（下架或停止推送）
+func (app *application) pruneScanner(ctx context.Context, ttl time.Duration, fence *singletonFence) {
+	ticker := time.NewTicker(ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !fence.allow(ctx, "scanner_prune") {
+				continue
+			}
+			pruneCtx, cancel := context.WithTimeout(ctx, redisOperationTimeout)
+			removed, err := app.scanner.Prune(pruneCtx, time.Now().Add(-ttl))
+			cancel()
+			if err != nil {
+				app.logger.Warn("清理扫描器排行失败", zap.Error(err))
+			} else if removed > 0 {
+				app.logger.Info("已清理过期交易对", zap.Int("removed", removed))
+			}
+		}
+	}
+}
+
+// purgeKlines 按 kline_retention_policies 定期删除各周期的过期K线
+// klines 表混合了所有周期，TimescaleDB 按 chunk 的保留策略无法区分周期
+func (app *application) purgeKlines(ctx context.Context, interval time.Duration, fence *singletonFence) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !fence.allow(ctx, "kline_purge") {
+				continue
+			}
+			app.purgeExpiredKlines(ctx)
+		}
+	}
+}
+
+// purgeExpiredKlines 删除一轮过期K线，单个周期失败不影响其他周期
+func (app *application) purgeExpiredKlines(ctx context.Context) {
+	policies, err := app.storageDAO.ListKlineRetention(ctx)
+	if err != nil {
+		app.logger.Warn("获取K线保留期失败", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, policy := range policies {
+		if policy.RetentionDays == nil {
+			continue
+		}
+		before := now.AddDate(0, 0, -*policy.RetentionDays)
+		deleted, err := app.storageDAO.PurgeKlines(ctx, policy.Granularity, before)
+		if err != nil {
+			app.logger.Warn("删除过期K线失败", zap.String("granularity", policy.Granularity), zap.Error(err))
+			continue
+		}
+		if deleted > 0 {
+			app.logger.Info("已删除过期K线",
+				zap.String("granularity", policy.Granularity),
+				zap.Int("retention_days", *policy.RetentionDays),
+				zap.Int64("deleted", deleted),
+			)
+		}
+	}
+}
+
+// fatalErrors 返回后台服务的致命错误
+func (app *application) fatalErrors() <-chan error {
+	return app.errCh
+}
+
+// shutdown 按依赖顺序关闭：先停止单实例任务（行情输入）并释放领导权，再关闭推送连接和 HTTP 服务，
+// 然后刷新持久化队列，最后关闭 Redis 和数据库
+func (app *application) shutdown(ctx context.Context) error {
+	var errs []error
+	record := func(name string, err error) {
+		if err != nil {
+			app.logger.Error("关闭组件失败", zap.String("component", name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if app.jobsCancel != nil {
+		app.jobsCancel()
+	}
+	record("jobs", waitGroup(ctx, &app.jobsWg))
+
+	// 先关闭 WebSocket 服务器：SSE 连接由它结束，否则 HTTP 服务器会一直等待 SSE 请求返回
+	if app.wsServer != nil && app.wsServer.IsRunning() {
+		record("websocket", app.wsServer.Stop(ctx))
+	}
+	if app.httpServer != nil {
+		record("http", app.httpServer.Shutdown(ctx))
+	}
+
+	if app.cancel != nil {
+		app.cancel()
+	}
+	record("workers", waitGroup(ctx, &app.wg))
+
+	if processor, ok := app.processor.(lifecycle); ok && app.processing {
+		record("processor", processor.Stop(ctx))
+	}
+	// K线聚合器停止时收盘所有K线，需要在持久化停止前提交
+	if app.candles != nil && app.candles.IsRunning() {
+		record("candles", app.candles.Stop(ctx))
+	}
+	if app.persistence != nil && app.persistence.IsRunning() {
+		record("persistence", app.persistence.Stop(ctx))
+	}
+	// 行情输入已停止，最后一次快照包含退出前学习到的全部状态
+	if app.anomaly != nil {
+		app.snapshotAnomalyState(ctx)
+	}
+
+	app.closeStorage()
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	app.logger.Info("所有组件已关闭")
+	return nil
+}
+
+// waitGroup 等待协程退出，超过关闭期限时返回 ctx 的错误，不再等待卡住的协程
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待协程退出超时: %w", ctx.Err())
+	}
+}
+
+// closeStorage 关闭 Redis 和数据库连接
+func (app *application) closeStorage() {
+	if app.redis != nil {
+		if err := app.redis.Close(); err != nil {
+			app.logger.Warn("关闭Redis连接失败", zap.Error(err))
+		}
+	}
+	if app.db != nil {
+		if err := database.Close(); err != nil {
+			app.logger.Warn("关闭数据库连接失败", zap.Error(err))
+		}
+	}
+}
+
+// redisConfig 将应用配置转换为 Redis 客户端配置，未配置的字段使用默认值
+func redisConfig(cfg *config.RedisConfig) *cache.Config {
+	redisCfg := cache.DefaultConfig()
+	redisCfg.Host = cfg.Host
+	redisCfg.Port = cfg.Port
+	redisCfg.Password = cfg.Password
+	redisCfg.DB = cfg.DB
+	if cfg.PoolSize > 0 {
+		redisCfg.PoolSize = cfg.PoolSize
+	}
+	if cfg.MinIdleConns > 0 {
+		redisCfg.MinIdleConns = cfg.MinIdleConns
+	}
+	if cfg.MaxRetries > 0 {
+		redisCfg.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.PoolTimeout > 0 {
+		redisCfg.PoolTimeout = time.Duration(cfg.PoolTimeout) * time.Second
+	}
+	if cfg.IdleTimeout > 0 {
+		redisCfg.IdleTimeout = time.Duration(cfg.IdleTimeout) * time.Second
+	}
+	return redisCfg
+}
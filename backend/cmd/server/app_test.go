@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
+)
+
+func TestApplication_NewAdminAuth(t *testing.T) {
+	newApp := func(modify func(cfg *config.Config)) *application {
+		cfg := &config.Config{}
+		cfg.Storage.AdminAPI = true
+		modify(cfg)
+		return &application{cfg: cfg, logger: zap.NewNop()}
+	}
+
+	// 未启用认证时拒绝开启管理接口
+	_, err := newApp(func(cfg *config.Config) {}).newAdminAuth()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "storage.admin_api_insecure")
+
+	// 显式允许时不校验
+	adminAuth, err := newApp(func(cfg *config.Config) {
+		cfg.Storage.AdminAPIInsecure = true
+	}).newAdminAuth()
+	require.NoError(t, err)
+	assert.Nil(t, adminAuth)
+
+	// 启用认证时需要 admin 权限
+	adminAuth, err = newApp(func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.SecretKey = "test-secret"
+	}).newAdminAuth()
+	require.NoError(t, err)
+	assert.NotNil(t, adminAuth)
+
+	// 启用认证但没有密钥时同样拒绝启动
+	_, err = newApp(func(cfg *config.Config) {
+		cfg.Auth.Enabled = true
+	}).newAdminAuth()
+	assert.Error(t, err)
+}
+
+func TestApplication_ShutdownDeadline(t *testing.T) {
+	app := &application{cfg: &config.Config{}, logger: zap.NewNop()}
+
+	// 单实例任务卡住时不超过关闭期限
+	app.jobsWg.Add(1)
+	defer app.jobsWg.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := app.shutdown(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/bitget"
+)
+
+// exchangeClient 交易所行情连接，由 bitget.WebSocketClient 实现
+type exchangeClient interface {
+	Connect(ctx context.Context) error
+	SubscribeTicker(symbols []string, callback bitget.TickerCallback) error
+	Unsubscribe(symbols []string) error
+	Close() error
+	IsConnected() bool
+	GetReconnectStatus() (attempts int, maxAttempts int, enabled bool)
+}
+
+// collectorConn 单个交易所连接及其订阅
+type collectorConn struct {
+	client  exchangeClient
+	symbols map[string]bool
+}
+
+// collectorPool 把一组交易对的行情订阅拆分到多个交易所连接
+//
+// 每个连接最多订阅 maxPerConn 个交易对。调整订阅时保持已有订阅不动，只退订不再需要的交易对、
+// 把新增交易对优先补到有空余的连接上，不足时再新建连接，避免分片变化时所有订阅重建
+type collectorPool struct {
+	newClient  func() exchangeClient
+	callback   bitget.TickerCallback
+	maxPerConn int
+	logger     *zap.Logger
+
+	conns []*collectorConn
+}
+
+// newCollectorPool 创建连接池，maxPerConn 不大于 0 时每个连接不限订阅数
+func newCollectorPool(newClient func() exchangeClient, callback bitget.TickerCallback, maxPerConn int, logger *zap.Logger) *collectorPool {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &collectorPool{
+		newClient:  newClient,
+		callback:   callback,
+		maxPerConn: maxPerConn,
+		logger:     logger,
+	}
+}
+
+// apply 把订阅调整为 desired，失败的部分在下一次调整时重试
+func (p *collectorPool) apply(ctx context.Context, desired []string) error {
+	want := make(map[string]bool, len(desired))
+	for _, symbol := range desired {
+		want[symbol] = true
+	}
+
+	var errs []error
+
+	// 放弃重连的连接不会再恢复，关闭后其交易对重新分配到其他连接
+	alive := p.conns[:0]
+	for _, conn := range p.conns {
+		if connectionLost(conn.client) {
+			p.logger.Warn("交易所连接已断开且不再重连，重新分配订阅", zap.Int("symbols", len(conn.symbols)))
+			conn.client.Close()
+			continue
+		}
+		alive = append(alive, conn)
+	}
+	p.conns = alive
+
+	// 退订不再需要的交易对，没有订阅的连接直接关闭
+	subscribed := make(map[string]bool)
+	alive = p.conns[:0]
+	for _, conn := range p.conns {
+		var removed []string
+		for symbol := range conn.symbols {
+			if !want[symbol] {
+				removed = append(removed, symbol)
+			}
+		}
+
+		if len(removed) == len(conn.symbols) {
+			conn.client.Close()
+			continue
+		}
+		if len(removed) > 0 {
+			sort.Strings(removed)
+			if err := conn.client.Unsubscribe(removed); err != nil {
+				errs = append(errs, fmt.Errorf("退订行情失败: %w", err))
+			} else {
+				for _, symbol := range removed {
+					delete(conn.symbols, symbol)
+				}
+			}
+		}
+
+		for symbol := range conn.symbols {
+			subscribed[symbol] = true
+		}
+		alive = append(alive, conn)
+	}
+	p.conns = alive
+
+	var pending []string
+	for _, symbol := range desired {
+		if !subscribed[symbol] {
+			pending = append(pending, symbol)
+			subscribed[symbol] = true
+		}
+	}
+
+	// 先补满已有连接，重连中的连接无法订阅，跳过
+	for _, conn := range p.conns {
+		if len(pending) == 0 {
+			break
+		}
+		free := len(pending)
+		if p.maxPerConn > 0 {
+			free = p.maxPerConn - len(conn.symbols)
+		}
+		if free <= 0 || !conn.client.IsConnected() {
+			continue
+		}
+		batch := pending[:min(free, len(pending))]
+		if err := p.subscribe(conn, batch); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pending = pending[len(batch):]
+	}
+
+	// 剩余交易对按上限拆分到新连接
+	for len(pending) > 0 {
+		size := len(pending)
+		if p.maxPerConn > 0 {
+			size = min(p.maxPerConn, size)
+		}
+		batch := pending[:size]
+		pending = pending[len(batch):]
+
+		client := p.newClient()
+		if err := client.Connect(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("连接交易所WebSocket失败: %w", err))
+			break
+		}
+		conn := &collectorConn{client: client, symbols: make(map[string]bool, len(batch))}
+		if err := p.subscribe(conn, batch); err != nil {
+			client.Close()
+			errs = append(errs, err)
+			break
+		}
+		p.conns = append(p.conns, conn)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *collectorPool) subscribe(conn *collectorConn, symbols []string) error {
+	if err := conn.client.SubscribeTicker(symbols, p.callback); err != nil {
+		return fmt.Errorf("订阅行情失败: %w", err)
+	}
+	for _, symbol := range symbols {
+		conn.symbols[symbol] = true
+	}
+	return nil
+}
+
+// symbols 返回当前已订阅的交易对
+func (p *collectorPool) symbols() []string {
+	var symbols []string
+	for _, conn := range p.conns {
+		for symbol := range conn.symbols {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// connections 当前连接数
+func (p *collectorPool) connections() int {
+	return len(p.conns)
+}
+
+// close 关闭所有连接
+func (p *collectorPool) close() {
+	for _, conn := range p.conns {
+		if err := conn.client.Close(); err != nil {
+			p.logger.Warn("关闭交易所连接失败", zap.Error(err))
+		}
+	}
+	p.conns = nil
+}
+
+// connectionLost 连接已断开且自动重连已耗尽或被禁用
+func connectionLost(client exchangeClient) bool {
+	if client.IsConnected() {
+		return false
+	}
+	attempts, maxAttempts, enabled := client.GetReconnectStatus()
+	return !enabled || attempts >= maxAttempts
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/bitget"
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// fakeExchange 内存中的交易所连接
+type fakeExchange struct {
+	mu            sync.Mutex
+	connected     bool
+	closed        bool
+	gaveUp        bool
+	failSubscribe bool
+	symbols       map[string]bool
+}
+
+func (f *fakeExchange) Connect(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = true
+	return nil
+}
+
+func (f *fakeExchange) SubscribeTicker(symbols []string, callback bitget.TickerCallback) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.connected || f.failSubscribe {
+		return errors.New("not connected")
+	}
+	for _, symbol := range symbols {
+		f.symbols[symbol] = true
+	}
+	return nil
+}
+
+func (f *fakeExchange) Unsubscribe(symbols []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, symbol := range symbols {
+		delete(f.symbols, symbol)
+	}
+	return nil
+}
+
+func (f *fakeExchange) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = false
+	f.closed = true
+	return nil
+}
+
+func (f *fakeExchange) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected
+}
+
+func (f *fakeExchange) GetReconnectStatus() (int, int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.gaveUp {
+		return 10, 10, true
+	}
+	return 0, 10, true
+}
+
+// disconnect 模拟连接断开，giveUp 表示自动重连已耗尽
+func (f *fakeExchange) disconnect(giveUp bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = false
+	f.gaveUp = giveUp
+}
+
+// fakeExchanges 记录创建的所有连接
+type fakeExchanges struct {
+	mu      sync.Mutex
+	clients []*fakeExchange
+}
+
+func (f *fakeExchanges) new() exchangeClient {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	client := &fakeExchange{symbols: make(map[string]bool)}
+	f.clients = append(f.clients, client)
+	return client
+}
+
+// open 返回未关闭连接的订阅数
+func (f *fakeExchanges) open() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var sizes []int
+	for _, client := range f.clients {
+		client.mu.Lock()
+		if !client.closed {
+			sizes = append(sizes, len(client.symbols))
+		}
+		client.mu.Unlock()
+	}
+	return sizes
+}
+
+// subscribed 返回未关闭连接订阅的所有交易对
+func (f *fakeExchanges) subscribed() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var symbols []string
+	for _, client := range f.clients {
+		client.mu.Lock()
+		if !client.closed {
+			for symbol := range client.symbols {
+				symbols = append(symbols, symbol)
+			}
+		}
+		client.mu.Unlock()
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+func symbolRange(from, to int) []string {
+	symbols := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		symbols = append(symbols, fmt.Sprintf("SYM%03dUSDT", i))
+	}
+	return symbols
+}
+
+func TestCollectorPool_Apply(t *testing.T) {
+	exchanges := &fakeExchanges{}
+	pool := newCollectorPool(exchanges.new, func(bitget.Ticker) {}, 3, nil)
+	ctx := context.Background()
+
+	// 按上限拆分到多个连接
+	require.NoError(t, pool.apply(ctx, symbolRange(0, 7)))
+	assert.Equal(t, []int{3, 3, 1}, exchanges.open())
+	assert.Equal(t, symbolRange(0, 7), pool.symbols())
+
+	// 新增交易对先补满已有连接
+	require.NoError(t, pool.apply(ctx, symbolRange(0, 9)))
+	assert.Equal(t, []int{3, 3, 3}, exchanges.open())
+	assert.Len(t, exchanges.clients, 3)
+
+	// 退订移除的交易对，订阅清空的连接被关闭，其余订阅保持不变
+	require.NoError(t, pool.apply(ctx, symbolRange(3, 8)))
+	assert.Equal(t, []int{3, 2}, exchanges.open())
+	assert.Equal(t, symbolRange(3, 8), exchanges.subscribed())
+	assert.Equal(t, 2, pool.connections())
+
+	// 重连中的连接保留订阅，放弃重连的连接被替换
+	exchanges.clients[1].disconnect(false)
+	require.NoError(t, pool.apply(ctx, symbolRange(3, 8)))
+	assert.Equal(t, 2, pool.connections())
+	exchanges.clients[1].disconnect(true)
+	require.NoError(t, pool.apply(ctx, symbolRange(3, 8)))
+	assert.Equal(t, []int{3, 2}, exchanges.open())
+	assert.Equal(t, symbolRange(3, 8), exchanges.subscribed())
+
+	// 已有连接订阅失败时返回错误，交易对改由新连接订阅
+	exchanges.clients[3].mu.Lock()
+	exchanges.clients[3].failSubscribe = true
+	exchanges.clients[3].mu.Unlock()
+	err := pool.apply(ctx, symbolRange(3, 9))
+	assert.Error(t, err)
+	assert.Equal(t, symbolRange(3, 9), pool.symbols())
+
+	pool.close()
+	assert.Empty(t, exchanges.open())
+	assert.Zero(t, pool.connections())
+
+	// 不限订阅数时使用单个连接
+	unlimited := &fakeExchanges{}
+	pool = newCollectorPool(unlimited.new, func(bitget.Ticker) {}, 0, nil)
+	require.NoError(t, pool.apply(ctx, symbolRange(0, 5)))
+	require.NoError(t, pool.apply(ctx, symbolRange(0, 8)))
+	assert.Equal(t, []int{8}, unlimited.open())
+}
+
+// newShardedApp 创建只包含分片采集所需组件的应用
+func newShardedApp(t *testing.T, server *miniredis.Miniredis, instanceID string, symbols []string) (*application, *fakeExchanges) {
+	port, err := strconv.Atoi(server.Port())
+	require.NoError(t, err)
+	redisCfg := cache.DefaultConfig()
+	redisCfg.Host = server.Host()
+	redisCfg.Port = port
+	client, err := cache.NewClient(redisCfg, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	cfg := &config.Config{}
+	cfg.Collector.Symbols = symbols
+	cfg.Collector.MaxSubscriptionsPerConn = 10
+	cfg.Collector.RebalanceInterval = 50 * time.Millisecond
+
+	exchanges := &fakeExchanges{}
+	app := &application{
+		cfg:         cfg,
+		logger:      zap.NewNop(),
+		redis:       client,
+		instanceID:  instanceID,
+		newExchange: exchanges.new,
+		pipeline:    &tickerPipeline{logger: zap.NewNop()},
+		membership:  cache.NewMembership(client, collectorGroup, instanceID, time.Second, zap.NewNop()),
+	}
+	return app, exchanges
+}
+
+func TestApplication_ShardedCollector(t *testing.T) {
+	server := miniredis.RunT(t)
+	symbols := symbolRange(0, 60)
+
+	appA, exchangesA := newShardedApp(t, server, "instance-a", symbols)
+	appB, exchangesB := newShardedApp(t, server, "instance-b", symbols)
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	defer cancelA()
+	doneA := make(chan struct{})
+	go func() {
+		defer close(doneA)
+		appA.runCollector(ctxA, appA.membership, nil)
+	}()
+
+	// 单个实例订阅全部交易对，按上限拆分连接
+	require.Eventually(t, func() bool {
+		return len(exchangesA.subscribed()) == len(symbols)
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Len(t, exchangesA.open(), 6)
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	doneB := make(chan struct{})
+	go func() {
+		defer close(doneB)
+		appB.runCollector(ctxB, appB.membership, nil)
+	}()
+
+	// 新实例加入后两个实例的订阅互不重叠且完整覆盖
+	ring := data_collection.NewHashRing(0, []string{"instance-a", "instance-b"})
+	expectedA := ring.Shard(symbols, "instance-a")
+	expectedB := ring.Shard(symbols, "instance-b")
+	sort.Strings(expectedA)
+	sort.Strings(expectedB)
+	require.NotEmpty(t, expectedA)
+	require.NotEmpty(t, expectedB)
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(expectedA, exchangesA.subscribed()) &&
+			assert.ObjectsAreEqual(expectedB, exchangesB.subscribed())
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// 实例离开后剩余实例接管全部交易对
+	cancelB()
+	<-doneB
+	assert.Empty(t, exchangesB.open())
+	require.Eventually(t, func() bool {
+		return len(exchangesA.subscribed()) == len(symbols)
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancelA()
+	<-doneA
+	assert.Empty(t, exchangesA.open())
+	assert.False(t, server.Exists(cache.BuildMembersKey(collectorGroup)))
+}
+
+func TestApplication_SingletonJobsFence(t *testing.T) {
+	server := miniredis.RunT(t)
+	symbols := symbolRange(0, 10)
+
+	app, exchanges := newShardedApp(t, server, "instance-a", symbols)
+	app.membership = nil
+	lock := cache.NewLeaseLock(app.redis, singletonJobsLock, "instance-a", nil)
+	app.elector = cache.NewLeaderElector(lock, nil, nil)
+
+	ctx := context.Background()
+	token, err := lock.TryAcquire(ctx, 5*time.Second)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		app.runSingletonJobs(ctx, token)
+	}()
+	require.Eventually(t, func() bool {
+		return len(exchanges.subscribed()) == len(symbols)
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// 租约过期后被其他实例获取，旧领导者在下一次调整前发现令牌失效并停止采集
+	server.FastForward(6 * time.Second)
+	other := cache.NewLeaseLock(app.redis, singletonJobsLock, "instance-b", nil)
+	_, err = other.TryAcquire(ctx, 5*time.Second)
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("令牌失效后单实例任务未停止")
+	}
+	assert.Empty(t, exchanges.open())
+}
+
+func TestApplication_SyncTimeWindows(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.MonitoringConfig{}))
+	configDAO := dao.NewMonitoringConfigDAO(db, zap.NewNop())
+
+	cfg := &config.Config{}
+	cfg.Collector.TimeWindows = []string{"1m", "5m"}
+	app := &application{
+		cfg:                 cfg,
+		logger:              zap.NewNop(),
+		monitoringConfigDAO: configDAO,
+		processor:           data_collection.NewPriceProcessor(nil, zap.NewNop()),
+	}
+	ctx := context.Background()
+
+	// 没有监控配置时只使用配置文件的窗口
+	require.NoError(t, app.syncTimeWindows(ctx))
+	assert.Equal(t, []data_collection.TimeWindow{"1m", "5m"}, app.processor.GetTimeWindows())
+
+	// 新增的监控配置窗口合并进来并按时长排序
+	monitoring := &models.MonitoringConfig{
+		Name:    "短线",
+		Filters: models.MonitoringConfigFilters{TimeWindows: []string{"10s", "5m", "4h"}, ChangeThreshold: 1},
+	}
+	require.NoError(t, configDAO.Create(monitoring))
+	require.NoError(t, app.syncTimeWindows(ctx))
+	assert.Equal(t, []data_collection.TimeWindow{"10s", "1m", "5m", "4h"}, app.processor.GetTimeWindows())
+
+	// 删除监控配置后其独有的窗口不再计算
+	require.NoError(t, configDAO.Delete(monitoring.ID))
+	require.NoError(t, app.syncTimeWindows(ctx))
+	assert.Equal(t, []data_collection.TimeWindow{"1m", "5m"}, app.processor.GetTimeWindows())
+}
@@ -1,14 +1,14 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
-	"os"
+	"os/signal"
+	"syscall"
 
 	"go.uber.org/zap"
 
 	_ "github.com/haxrd/cryptosignal-hunter/docs"
-	"github.com/haxrd/cryptosignal-hunter/internal/api"
 	"github.com/haxrd/cryptosignal-hunter/internal/config"
 )
 
@@ -51,16 +51,33 @@ func main() {
 		zap.Int("port", cfg.Server.Port),
 	)
 
-	// 设置路由
-	router := api.SetupRouter(logger, cfg.Server.Mode)
+	// 收到 SIGINT/SIGTERM 后开始优雅关闭
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// 启动服务器
-	addr := fmt.Sprintf(":%d", cfg.Server.Port)
-	logger.Info("服务器监听中", zap.String("address", addr))
-	
-	if err := router.Run(addr); err != nil {
-		logger.Fatal("服务器启动失败", zap.Error(err))
-		os.Exit(1)
+	app, err := newApplication(cfg, logger)
+	if err != nil {
+		logger.Fatal("初始化服务失败", zap.Error(err))
 	}
-}
 
+	if err := app.start(); err != nil {
+		logger.Fatal("启动服务失败", zap.Error(err))
+	}
+
+	select {
+	case <-ctx.Done():
+		logger.Info("收到退出信号，开始关闭服务")
+	case err := <-app.fatalErrors():
+		logger.Error("服务异常，开始关闭服务", zap.Error(err))
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := app.shutdown(shutdownCtx); err != nil {
+		logger.Error("服务关闭未完成", zap.Error(err))
+		return
+	}
+	logger.Info("服务已退出")
+}
@@ -0,0 +1,447 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/bitget"
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/haxrd/cryptosignal-hunter/internal/websocket"
+)
+
+// tickerPipeline 行情处理管道
+// 交易所推送的每条 Ticker 依次经过：变化率计算 -> 异步落库 -> K线聚合 -> 成交量/持仓量信号检测 -> 价格异常检测 -> 价格缓存 -> 扫描器排行 -> 实时推送
+type tickerPipeline struct {
+	processor   data_collection.PriceProcessor
+	persistence data_collection.AsyncPersistence // 为空时不保存原始行情
+	candles     data_collection.CandleAggregator // 为空时不聚合K线
+	activity    data_collection.MarketActivityDetector
+	anomaly     data_collection.AnomalyDetector // 为空时不检测价格异常
+	priceCache  cache.PriceCache
+	scanner     cache.ScannerIndex
+	wsServer    websocket.WebSocketServer // 为空时不推送
+	timeout     time.Duration             // 单条 Ticker 写入 Redis 的超时
+	logger      *zap.Logger
+}
+
+// handleTicker 处理交易所推送的 Ticker，作为 bitget.TickerCallback 使用
+func (p *tickerPipeline) handleTicker(ticker bitget.Ticker) {
+	data, err := priceDataFromTicker(ticker)
+	if err != nil {
+		p.logger.Debug("丢弃无效Ticker", zap.String("symbol", ticker.Symbol), zap.Error(err))
+		return
+	}
+
+	if err := p.processor.ProcessPrice(data); err != nil {
+		p.logger.Warn("价格处理失败", zap.String("symbol", data.Symbol), zap.Error(err))
+		return
+	}
+
+	if p.persistence != nil {
+		item := &data_collection.PersistenceItem{
+			ID:        fmt.Sprintf("tick:%s:%d", data.Symbol, data.Timestamp.UnixNano()),
+			Type:      "tick",
+			Data:      priceTickFromTicker(ticker, data),
+			Timestamp: time.Now(),
+			Priority:  5,
+		}
+		if err := p.persistence.Submit(item); err != nil {
+			p.logger.Warn("提交行情落库失败", zap.String("symbol", data.Symbol), zap.Error(err))
+		}
+	}
+
+	if p.candles != nil {
+		if err := p.candles.Process(data); err != nil {
+			p.logger.Debug("K线聚合失败", zap.String("symbol", data.Symbol), zap.Error(err))
+		}
+	}
+
+	lastPrice := data.PriceFloat()
+	scannerTicker := &cache.ScannerTicker{
+		Symbol:      data.Symbol,
+		Timestamp:   data.Timestamp,
+		LastPrice:   &lastPrice,
+		Volume24h:   optionalFloat(data.UsdtVolume24h.InexactFloat64()),
+		BidPrice:    bitget.ParseOptionalDecimal(ticker.BidPr),
+		AskPrice:    bitget.ParseOptionalDecimal(ticker.AskPr),
+		FundingRate: parseOptionalFloat(ticker.FundingRate),
+	}
+	if rates, err := p.processor.GetChangeRates(data.Symbol); err == nil {
+		scannerTicker.ChangeRates = make(map[string]float64, len(rates))
+		for window, rate := range rates {
+			scannerTicker.ChangeRates[string(window)] = rate.ChangeRate
+		}
+	}
+
+	var signals []*data_collection.MarketSignal
+	if p.activity != nil {
+		result, err := p.activity.Process(data)
+		if err != nil {
+			p.logger.Debug("市场活跃度检测失败", zap.String("symbol", data.Symbol), zap.Error(err))
+		} else if result != nil {
+			if result.SurgeRatio > 0 {
+				scannerTicker.VolumeSurge = optionalFloat(result.SurgeRatio)
+			}
+			signals = result.Signals
+		}
+	}
+	if p.anomaly != nil {
+		if signal := p.detectAnomaly(data); signal != nil {
+			signals = append(signals, signal)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	cached := cachePriceFromTicker(ticker, data)
+	if err := p.priceCache.SetPrice(ctx, cached); err != nil {
+		p.logger.Warn("更新价格缓存失败", zap.String("symbol", data.Symbol), zap.Error(err))
+	}
+	if err := p.scanner.UpdateTicker(ctx, scannerTicker); err != nil {
+		p.logger.Warn("更新扫描器失败", zap.String("symbol", data.Symbol), zap.Error(err))
+	}
+
+	if p.wsServer == nil {
+		return
+	}
+	if err := p.wsServer.Publish(websocket.TickerChannel(data.Symbol), websocket.TickerPayloadFromPrice(cached)); err != nil {
+		p.logger.Warn("推送行情失败", zap.String("symbol", data.Symbol), zap.Error(err))
+	}
+	if err := p.wsServer.UpdateMarketMetrics(scannerTicker); err != nil {
+		p.logger.Warn("更新过滤订阅指标失败", zap.String("symbol", data.Symbol), zap.Error(err))
+	}
+	for _, signal := range signals {
+		if err := p.wsServer.Publish(string(websocket.ChannelSignals), signalPayload(signal)); err != nil {
+			p.logger.Warn("推送信号失败", zap.String("symbol", signal.Symbol), zap.Error(err))
+		}
+	}
+}
+
+// anomalyRules 行情管道的异常检测规则
+// 不检测成交量异常：滚动成交量逐条几乎不变，而成交量异常优先于统计异常判定，会掩盖价格的统计异常；
+// 成交量放量由 MarketActivityDetector 检测
+func anomalyRules() *data_collection.AnomalyRules {
+	rules := data_collection.DefaultAnomalyRules()
+	rules.VolumeAnomaly.Enabled = false
+	return rules
+}
+
+// detectAnomaly 检测价格异常，价格尖峰、骤跌、异常值和统计异常转换为市场信号
+// 时间、成交量等数据质量异常不推送
+func (p *tickerPipeline) detectAnomaly(data *data_collection.PriceData) *data_collection.MarketSignal {
+	result, err := p.anomaly.DetectAnomaly(data)
+	if err != nil {
+		p.logger.Debug("价格异常检测失败", zap.String("symbol", data.Symbol), zap.Error(err))
+		return nil
+	}
+	if !result.IsAnomaly {
+		return nil
+	}
+	return anomalySignal(result)
+}
+
+// anomalySignal 将价格异常转换为市场信号，信号类型即异常类型，强度为异常评分
+func anomalySignal(result *data_collection.AnomalyResult) *data_collection.MarketSignal {
+	switch result.AnomalyType {
+	case data_collection.AnomalyTypePriceSpike, data_collection.AnomalyTypePriceDrop,
+		data_collection.AnomalyTypePriceOutlier, data_collection.AnomalyTypeStatistical:
+	default:
+		return nil
+	}
+
+	metadata := make(map[string]interface{}, len(result.Metadata)+1)
+	for key, value := range result.Metadata {
+		metadata[key] = value
+	}
+	metadata["confidence"] = result.Confidence
+
+	return &data_collection.MarketSignal{
+		Symbol:     result.Data.Symbol,
+		Type:       result.AnomalyType,
+		Direction:  anomalyDirection(result),
+		Strength:   result.Score,
+		Severity:   result.Severity,
+		Reasons:    result.Reasons,
+		Metadata:   metadata,
+		DetectedAt: result.DetectedAt,
+	}
+}
+
+// anomalyDirection 按异常的价格变化率、收益率或Z分数的符号判断方向
+func anomalyDirection(result *data_collection.AnomalyResult) string {
+	switch result.AnomalyType {
+	case data_collection.AnomalyTypePriceSpike:
+		return data_collection.SignalDirectionUp
+	case data_collection.AnomalyTypePriceDrop:
+		return data_collection.SignalDirectionDown
+	}
+	for _, key := range []string{"price_change", "return", "z_score"} {
+		value, ok := result.Metadata[key].(float64)
+		if !ok {
+			continue
+		}
+		switch {
+		case value > 0:
+			return data_collection.SignalDirectionUp
+		case value < 0:
+			return data_collection.SignalDirectionDown
+		}
+	}
+	return data_collection.SignalDirectionNeutral
+}
+
+// candlePublisher 将聚合的K线推送到 kline 频道，作为 data_collection.CandleHandler 使用
+type candlePublisher struct {
+	wsServer websocket.WebSocketServer
+	logger   *zap.Logger
+}
+
+// OnCandleUpdate 推送未收盘K线，客户端用于更新最后一根K线
+func (c *candlePublisher) OnCandleUpdate(candle *data_collection.Candle) {
+	c.publish(candle)
+}
+
+// OnCandleClosed 推送收盘K线
+func (c *candlePublisher) OnCandleClosed(candle *data_collection.Candle) {
+	c.publish(candle)
+}
+
+func (c *candlePublisher) publish(candle *data_collection.Candle) {
+	if err := c.wsServer.Publish(websocket.KlineChannel(candle.Interval, candle.Symbol), klinePayload(candle)); err != nil {
+		c.logger.Warn("推送K线失败",
+			zap.String("symbol", candle.Symbol),
+			zap.String("interval", candle.Interval),
+			zap.Error(err),
+		)
+	}
+}
+
+// priceDataFromTicker 将交易所 Ticker 转换为采集模块的价格数据
+// 价格、成交量和持仓量保持 Ticker 字符串的精确值，变化率、波动率等统计计算通过 PriceFloat 转换。
+// Ticker 只有24小时滚动成交量，不设置单条数据的 Volume；区间成交量由K线聚合从滚动成交量的差值推导
+func priceDataFromTicker(ticker bitget.Ticker) (*data_collection.PriceData, error) {
+	if ticker.Symbol == "" {
+		return nil, fmt.Errorf("交易对为空")
+	}
+	price, err := ticker.LastPrice()
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now()
+	if ms, err := strconv.ParseInt(ticker.Ts, 10, 64); err == nil && ms > 0 {
+		timestamp = time.UnixMilli(ms)
+	}
+
+	return &data_collection.PriceData{
+		Symbol:        ticker.Symbol,
+		Price:         price,
+		BidPrice:      parseDecimalOrZero(ticker.BidPr),
+		AskPrice:      parseDecimalOrZero(ticker.AskPr),
+		Timestamp:     timestamp,
+		Source:        "bitget",
+		Latency:       time.Since(timestamp),
+		BaseVolume24h: parseDecimalOrZero(ticker.BaseVolume),
+		UsdtVolume24h: parseDecimalOrZero(ticker.UsdtVolume),
+		OpenInterest:  parseDecimalOrZero(ticker.HoldingAmount),
+	}, nil
+}
+
+// cachePriceFromTicker 构建写入 Redis 价格缓存的数据，24小时涨跌幅转换为百分比
+func cachePriceFromTicker(ticker bitget.Ticker, data *data_collection.PriceData) *cache.PriceData {
+	lastPrice, _ := ticker.LastPrice()
+	return &cache.PriceData{
+		Symbol:      data.Symbol,
+		LastPrice:   lastPrice,
+		AskPrice:    bitget.ParseOptionalDecimal(ticker.AskPr),
+		BidPrice:    bitget.ParseOptionalDecimal(ticker.BidPr),
+		High24h:     bitget.ParseOptionalDecimal(ticker.High24h),
+		Low24h:      bitget.ParseOptionalDecimal(ticker.Low24h),
+		Change24h:   parseOptionalPercent(ticker.Change24h),
+		BaseVolume:  bitget.ParseOptionalDecimal(ticker.BaseVolume),
+		QuoteVolume: bitget.ParseOptionalDecimal(ticker.QuoteVolume),
+		Timestamp:   data.Timestamp,
+	}
+}
+
+// priceTickFromTicker 构建写入 price_ticks 的数据，涨跌幅转换为百分比
+func priceTickFromTicker(ticker bitget.Ticker, data *data_collection.PriceData) *models.PriceTick {
+	lastPrice, _ := ticker.LastPrice()
+	return &models.PriceTick{
+		Symbol:            data.Symbol,
+		Timestamp:         data.Timestamp,
+		LastPrice:         lastPrice,
+		AskPrice:          bitget.ParseOptionalDecimal(ticker.AskPr),
+		BidPrice:          bitget.ParseOptionalDecimal(ticker.BidPr),
+		BidSize:           bitget.ParseOptionalDecimal(ticker.BidSz),
+		AskSize:           bitget.ParseOptionalDecimal(ticker.AskSz),
+		High24h:           bitget.ParseOptionalDecimal(ticker.High24h),
+		Low24h:            bitget.ParseOptionalDecimal(ticker.Low24h),
+		Change24h:         parseOptionalPercent(ticker.Change24h),
+		BaseVolume:        bitget.ParseOptionalDecimal(ticker.BaseVolume),
+		QuoteVolume:       bitget.ParseOptionalDecimal(ticker.QuoteVolume),
+		UsdtVolume:        bitget.ParseOptionalDecimal(ticker.UsdtVolume),
+		OpenUtc:           bitget.ParseOptionalDecimal(ticker.OpenUtc),
+		ChangeUtc24h:      parseOptionalPercent(ticker.ChangeUtc24h),
+		IndexPrice:        bitget.ParseOptionalDecimal(ticker.IndexPrice),
+		FundingRate:       parseOptionalFloat(ticker.FundingRate),
+		HoldingAmount:     bitget.ParseOptionalDecimal(ticker.HoldingAmount),
+		Open24h:           bitget.ParseOptionalDecimal(ticker.Open24h),
+		MarkPrice:         bitget.ParseOptionalDecimal(ticker.MarkPrice),
+		DeliveryStartTime: parseOptionalInt(ticker.DeliveryStartTime),
+		DeliveryTime:      parseOptionalInt(ticker.DeliveryTime),
+		DeliveryStatus:    ticker.DeliveryStatus,
+	}
+}
+
+// klinePayload 构建 kline 频道数据
+func klinePayload(candle *data_collection.Candle) *websocket.KlinePayload {
+	return &websocket.KlinePayload{
+		Symbol:      candle.Symbol,
+		Interval:    candle.Interval,
+		OpenTime:    candle.OpenTime.UnixMilli(),
+		Open:        candle.Open.InexactFloat64(),
+		High:        candle.High.InexactFloat64(),
+		Low:         candle.Low.InexactFloat64(),
+		Close:       candle.Close.InexactFloat64(),
+		Volume:      candle.Volume.InexactFloat64(),
+		QuoteVolume: candle.QuoteVolume.InexactFloat64(),
+		Closed:      candle.Closed,
+	}
+}
+
+// signalPayload 构建 signals 频道数据
+func signalPayload(signal *data_collection.MarketSignal) *websocket.SignalPayload {
+	return &websocket.SignalPayload{
+		Symbol:     signal.Symbol,
+		Type:       signal.Type,
+		Direction:  signal.Direction,
+		Strength:   signal.Strength,
+		Severity:   signal.Severity,
+		Composite:  signal.Composite,
+		Reasons:    signal.Reasons,
+		Metadata:   signal.Metadata,
+		DetectedAt: signal.DetectedAt.UnixMilli(),
+	}
+}
+
+// parseDecimalOrZero 解析价格、成交量和持仓量字段，空字符串或格式错误返回 0
+func parseDecimalOrZero(value string) decimal.Decimal {
+	d := bitget.ParseOptionalDecimal(value)
+	if d == nil {
+		return decimal.Zero
+	}
+	return *d
+}
+
+// parseOptionalFloat 解析可选数值字段，空字符串或格式错误返回 nil
+func parseOptionalFloat(value string) *float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// parseOptionalPercent 解析比例字段（如 0.0125）并转换为百分比，空字符串或格式错误返回 nil
+func parseOptionalPercent(value string) *float64 {
+	f := parseOptionalFloat(value)
+	if f == nil {
+		return nil
+	}
+	percent := *f * 100
+	return &percent
+}
+
+// parseOptionalInt 解析可选整数字段（如交割时间），空字符串或格式错误返回 nil
+func parseOptionalInt(value string) *int64 {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
+// optionalFloat 零值视为缺失
+func optionalFloat(value float64) *float64 {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+// anomalyStateKV 在应用共享的 Redis 客户端上实现异常检测状态存储的键值操作，
+// 不单独建立连接池，连接随 application 关闭
+type anomalyStateKV struct {
+	client *redis.Client
+}
+
+// SetBatch 通过 Pipeline 写入，非字符串的值序列化为 JSON
+func (kv *anomalyStateKV) SetBatch(ctx context.Context, data []data_collection.CacheData) (*data_collection.CacheBatchResult, error) {
+	start := time.Now()
+	if len(data) == 0 {
+		return &data_collection.CacheBatchResult{Timestamp: start}, nil
+	}
+	pipe := kv.client.Pipeline()
+	for _, item := range data {
+		value, ok := item.Value.(string)
+		if !ok {
+			encoded, err := json.Marshal(item.Value)
+			if err != nil {
+				return nil, fmt.Errorf("序列化 %s 失败: %w", item.Key, err)
+			}
+			value = string(encoded)
+		}
+		pipe.Set(ctx, item.Key, value, item.TTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("批量写入失败: %w", err)
+	}
+	return &data_collection.CacheBatchResult{
+		TotalCount:   len(data),
+		SuccessCount: len(data),
+		Duration:     time.Since(start),
+		Timestamp:    time.Now(),
+	}, nil
+}
+
+// GetBatch 读取多个键，不存在的键不出现在结果中
+func (kv *anomalyStateKV) GetBatch(ctx context.Context, keys []string) (map[string]string, error) {
+	result := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+	values, err := kv.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("批量获取失败: %w", err)
+	}
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			result[keys[i]] = s
+		}
+	}
+	return result, nil
+}
+
+// GetKeys 用 SCAN 遍历匹配的键，避免 KEYS 阻塞共享的 Redis
+func (kv *anomalyStateKV) GetKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := kv.client.Scan(ctx, 0, pattern, 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("获取键列表失败: %w", err)
+	}
+	return keys, nil
+}
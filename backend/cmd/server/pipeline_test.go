@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/bitget"
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/haxrd/cryptosignal-hunter/internal/websocket"
+)
+
+func newTestRedisClient(t *testing.T) *cache.Client {
+	server := miniredis.RunT(t)
+	port, err := strconv.Atoi(server.Port())
+	require.NoError(t, err)
+
+	redisCfg := cache.DefaultConfig()
+	redisCfg.Host = server.Host()
+	redisCfg.Port = port
+	client, err := cache.NewClient(redisCfg, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func newTestPipeline(t *testing.T, wsServer websocket.WebSocketServer) *tickerPipeline {
+	client := newTestRedisClient(t)
+
+	processor := data_collection.NewPriceProcessor(nil, zap.NewNop())
+	require.NoError(t, processor.(lifecycle).Start(context.Background()))
+	t.Cleanup(func() { processor.(lifecycle).Stop(context.Background()) })
+
+	return &tickerPipeline{
+		processor:  processor,
+		activity:   data_collection.NewMarketActivityDetector(nil, zap.NewNop()),
+		priceCache: cache.NewPriceCache(client),
+		scanner:    cache.NewScannerIndex(client),
+		wsServer:   wsServer,
+		timeout:    time.Second,
+		logger:     zap.NewNop(),
+	}
+}
+
+func testTicker(price string, ts time.Time) bitget.Ticker {
+	return bitget.Ticker{
+		Symbol:        "BTCUSDT",
+		LastPr:        price,
+		BidPr:         "49999.5",
+		AskPr:         "50000.5",
+		High24h:       "51000",
+		Low24h:        "48000",
+		Change24h:     "0.0125",
+		BaseVolume:    "1200",
+		UsdtVolume:    "60000000",
+		FundingRate:   "0.0001",
+		HoldingAmount: "3500",
+		Ts:            strconv.FormatInt(ts.UnixMilli(), 10),
+	}
+}
+
+func TestPriceDataFromTicker(t *testing.T) {
+	ts := time.UnixMilli(1700000000000)
+	data, err := priceDataFromTicker(testTicker("50000", ts))
+	require.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", data.Symbol)
+	assert.Equal(t, "50000", data.Price.String())
+	assert.Equal(t, "49999.5", data.BidPrice.String())
+	assert.True(t, data.Volume.IsZero())
+	assert.Equal(t, "1200", data.BaseVolume24h.String())
+	assert.Equal(t, "60000000", data.UsdtVolume24h.String())
+	assert.Equal(t, "3500", data.OpenInterest.String())
+	assert.True(t, data.Timestamp.Equal(ts))
+
+	cached := cachePriceFromTicker(testTicker("50000", ts), data)
+	require.NotNil(t, cached.Change24h)
+	assert.InDelta(t, 1.25, *cached.Change24h, 1e-9)
+	assert.Nil(t, parseOptionalFloat(""))
+
+	tick := priceTickFromTicker(testTicker("50000", ts), data)
+	assert.Equal(t, "BTCUSDT", tick.Symbol)
+	assert.Equal(t, "50000", tick.LastPrice.String())
+	assert.True(t, tick.Timestamp.Equal(ts))
+	require.NotNil(t, tick.Change24h)
+	assert.InDelta(t, 1.25, *tick.Change24h, 1e-9)
+	require.NotNil(t, tick.HoldingAmount)
+	assert.Equal(t, "3500", tick.HoldingAmount.String())
+	assert.Nil(t, tick.MarkPrice)
+	assert.Nil(t, tick.DeliveryTime)
+
+	_, err = priceDataFromTicker(testTicker("", ts))
+	assert.Error(t, err)
+	_, err = priceDataFromTicker(bitget.Ticker{LastPr: "1"})
+	assert.Error(t, err)
+}
+
+func TestTickerPipeline_HandleTicker(t *testing.T) {
+	wsServer := websocket.NewWebSocketServer(nil, zap.NewNop())
+	pipeline := newTestPipeline(t, wsServer)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(wsServer.ServeSSE))
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"?channels=ticker:BTCUSDT", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	// 等待订阅确认，确保推送时连接已订阅
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.Contains(line, `"type":"subscribed"`) {
+			break
+		}
+	}
+
+	now := time.Now()
+	pipeline.handleTicker(testTicker("50000", now.Add(-time.Minute)))
+	pipeline.handleTicker(testTicker("51000", now))
+
+	// 价格缓存
+	price, err := pipeline.priceCache.GetPrice(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "51000", price.LastPrice.String())
+
+	// 扫描器排行包含变化率和资金费率
+	result, err := pipeline.scanner.Query(ctx, &cache.ScannerQuery{SortBy: cache.ScannerSortChangeRate, Window: "1m"})
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 1)
+	assert.InDelta(t, 2.0, result.Entries[0].ChangeRates["1m"], 1e-9)
+	require.NotNil(t, result.Entries[0].FundingRate)
+	assert.Equal(t, 0.0001, *result.Entries[0].FundingRate)
+
+	// ticker 频道推送
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.Contains(line, `"last_price":51000`) {
+			assert.Contains(t, line, `"channel":"ticker:BTCUSDT"`)
+			break
+		}
+	}
+}
+
+func TestTickerPipeline_PersistTicks(t *testing.T) {
+	pipeline := newTestPipeline(t, nil)
+
+	writer := data_collection.NewMockDataWriter()
+	persistenceConfig := data_collection.DefaultPersistenceConfig()
+	persistenceConfig.BatchTimeout = 10 * time.Millisecond
+	persistence := data_collection.NewAsyncPersistence(persistenceConfig, writer, zap.NewNop())
+	require.NoError(t, persistence.Start(context.Background()))
+	defer persistence.Stop(context.Background())
+	pipeline.persistence = persistence
+
+	now := time.Now()
+	pipeline.handleTicker(testTicker("50000", now.Add(-time.Second)))
+	pipeline.handleTicker(testTicker("51000", now))
+	pipeline.handleTicker(testTicker("", now))
+
+	require.Eventually(t, func() bool {
+		return writer.GetWriteCount() == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	for _, item := range writer.GetWrittenItems() {
+		assert.Equal(t, "tick", item.Type)
+		tick, ok := item.Data.(*models.PriceTick)
+		require.True(t, ok)
+		assert.Equal(t, "BTCUSDT", tick.Symbol)
+	}
+}
+
+func TestTickerPipeline_Candles(t *testing.T) {
+	wsServer := websocket.NewWebSocketServer(nil, zap.NewNop())
+	pipeline := newTestPipeline(t, wsServer)
+
+	candleConfig := data_collection.DefaultCandleAggregatorConfig()
+	candleConfig.Intervals = []string{"1s", "1m"}
+	candleConfig.PersistIntervals = nil
+	candles, err := data_collection.NewCandleAggregator(candleConfig, nil, &candlePublisher{wsServer: wsServer, logger: zap.NewNop()}, zap.NewNop())
+	require.NoError(t, err)
+	pipeline.candles = candles
+
+	httpServer := httptest.NewServer(http.HandlerFunc(wsServer.ServeSSE))
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	channel := websocket.KlineChannel("1s", "BTCUSDT")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"?channels="+channel, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.Contains(line, `"type":"subscribed"`) {
+			break
+		}
+	}
+
+	// 第二条行情的事件时间超过第一根1s K线的水位，第一根K线收盘
+	baseTime := time.Now().Add(-time.Minute).Truncate(time.Minute)
+	pipeline.handleTicker(testTicker("50000", baseTime))
+	pipeline.handleTicker(testTicker("51000", baseTime.Add(5*time.Second)))
+
+	candle, ok := candles.GetCandle("BTCUSDT", "1m")
+	require.True(t, ok)
+	assert.Equal(t, 2, candle.TickCount)
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.Contains(line, `"closed":true`) {
+			assert.Contains(t, line, `"channel":"`+channel+`"`)
+			assert.Contains(t, line, `"close":50000`)
+			break
+		}
+	}
+}
+
+func TestTickerPipeline_AnomalySignals(t *testing.T) {
+	wsServer := websocket.NewWebSocketServer(nil, zap.NewNop())
+	pipeline := newTestPipeline(t, wsServer)
+
+	store := data_collection.NewRedisAnomalyStateStore(&anomalyStateKV{client: newTestRedisClient(t).GetClient()}, 0)
+	pipeline.anomaly = data_collection.NewAnomalyDetectorWithStateStore(anomalyRules(), store, zap.NewNop())
+
+	httpServer := httptest.NewServer(http.HandlerFunc(wsServer.ServeSSE))
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"?channels="+string(websocket.ChannelSignals), nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.Contains(line, `"type":"subscribed"`) {
+			break
+		}
+	}
+
+	// 相对前两条行情 30% 的价格跳涨超过默认的20%尖峰阈值
+	now := time.Now()
+	pipeline.handleTicker(testTicker("50000", now.Add(-20*time.Second)))
+	pipeline.handleTicker(testTicker("50100", now.Add(-10*time.Second)))
+	pipeline.handleTicker(testTicker("65000", now))
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.Contains(line, data_collection.AnomalyTypePriceSpike) {
+			assert.Contains(t, line, `"direction":"up"`)
+			assert.Contains(t, line, `"symbol":"BTCUSDT"`)
+			break
+		}
+	}
+
+	// 快照后新的检测器从 Redis 恢复统计状态
+	require.NoError(t, pipeline.anomaly.SnapshotState(ctx))
+	restored := data_collection.NewAnomalyDetectorWithStateStore(anomalyRules(), store, zap.NewNop())
+	require.NoError(t, restored.RestoreState(ctx))
+	stats, ok := restored.GetSymbolStatistics("BTCUSDT")
+	require.True(t, ok)
+	assert.Equal(t, 65000.0, stats.LastPrice)
+}
+
+func TestAnomalySignal(t *testing.T) {
+	result := &data_collection.AnomalyResult{
+		Data:        &data_collection.PriceData{Symbol: "ETHUSDT"},
+		IsAnomaly:   true,
+		AnomalyType: data_collection.AnomalyTypeStatistical,
+		Confidence:  0.8,
+		Severity:    data_collection.SeverityMedium,
+		Score:       60,
+		Metadata:    map[string]interface{}{"z_score": -4.2},
+	}
+	signal := anomalySignal(result)
+	require.NotNil(t, signal)
+	assert.Equal(t, "ETHUSDT", signal.Symbol)
+	assert.Equal(t, data_collection.AnomalyTypeStatistical, signal.Type)
+	assert.Equal(t, data_collection.SignalDirectionDown, signal.Direction)
+	assert.Equal(t, 60.0, signal.Strength)
+	assert.Equal(t, 0.8, signal.Metadata["confidence"])
+
+	// 数据质量异常不作为信号推送
+	result.AnomalyType = data_collection.AnomalyTypeTimeGap
+	assert.Nil(t, anomalySignal(result))
+}
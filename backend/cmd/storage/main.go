@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/archive"
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	// 解析命令行参数
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	action := flag.String("action", "tables", "操作: tables|chunks|policy|compress|kline-retention|archive|archives|token")
+	table := flag.String("table", "", "超表或连续聚合名称（chunks/policy/compress），归档表 price_ticks/klines（archive/archives）")
+	compressAfter := flag.String("compress-after", "", "压缩策略时长，如 7d，never 表示移除（policy）")
+	dropAfter := flag.String("drop-after", "", "保留策略时长，如 14d，never 表示永久保留（policy）")
+	olderThan := flag.String("older-than", "7d", "压缩早于该时长的 chunk（compress）")
+	granularity := flag.String("granularity", "", "K线周期，为空时只列出保留期（kline-retention）")
+	retention := flag.String("retention", "", "K线保留期，按整天计，如 30d，never 表示永久保留（kline-retention）")
+	symbol := flag.String("symbol", "", "交易对，为空时列出所有交易对（archives）")
+	day := flag.String("day", "", "归档日期 YYYY-MM-DD（UTC），为空时归档所有到期日期（archive）；列出该日期起的归档（archives）")
+	user := flag.String("user", "", "Token 的用户 ID（token）")
+	permissions := flag.String("permissions", "read", "Token 的权限，逗号分隔：read 用于推送和导出，admin 用于管理接口（token）")
+	expiry := flag.Duration("expiry", 0, "Token 有效期，0 表示使用 auth.token_expiry（token）")
+	timeout := flag.Duration("timeout", 30*time.Minute, "执行超时")
+	flag.Parse()
+
+	// 加载配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	// 创建 logger
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatalf("创建 logger 失败: %v", err)
+	}
+	defer logger.Sync()
+
+	// 签发 Token 不需要连接数据库
+	if *action == "token" {
+		issueToken(logger, &cfg.Auth, *user, *permissions, *expiry)
+		return
+	}
+
+	db, err := database.Connect(&cfg.Database, logger)
+	if err != nil {
+		logger.Fatal("连接数据库失败", zap.Error(err))
+	}
+	defer database.Close()
+
+	// 开启归档时拒绝会在归档之前删除数据的保留期
+	storageDAO := dao.NewStoragePolicyDAO(db, logger)
+	if cfg.Storage.Archive.Enabled {
+		storageDAO = dao.NewStoragePolicyDAOWithArchive(db, archive.NewArchiverConfig(&cfg.Storage.Archive).Retention(), logger)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	// 执行操作
+	switch *action {
+	case "tables":
+		tables, err := storageDAO.ListTables(ctx)
+		if err != nil {
+			logger.Fatal("获取超表列表失败", zap.Error(err))
+		}
+		printTables(tables)
+
+	case "chunks":
+		requireTable(logger, *table)
+		chunks, err := storageDAO.ListChunks(ctx, *table)
+		if err != nil {
+			logger.Fatal("获取chunk列表失败", zap.Error(err))
+		}
+		printChunks(chunks)
+
+	case "policy":
+		requireTable(logger, *table)
+		if *compressAfter == "" && *dropAfter == "" {
+			logger.Fatal("-compress-after 和 -drop-after 至少需要一个")
+		}
+		if *compressAfter != "" {
+			period := parsePeriod(logger, "compress-after", *compressAfter)
+			if err := storageDAO.SetCompressionPolicy(ctx, *table, period); err != nil {
+				logger.Fatal("修改压缩策略失败", zap.Error(err))
+			}
+		}
+		if *dropAfter != "" {
+			period := parsePeriod(logger, "drop-after", *dropAfter)
+			if period != nil && *table == (models.Kline{}).TableName() {
+				logger.Fatal("klines 的保留期按周期设置，请使用 -action kline-retention")
+			}
+			if err := storageDAO.SetRetentionPolicy(ctx, *table, period); err != nil {
+				logger.Fatal("修改保留策略失败", zap.Error(err))
+			}
+		}
+		updated, err := storageDAO.GetTable(ctx, *table)
+		if err != nil {
+			logger.Fatal("获取超表失败", zap.Error(err))
+		}
+		printTables([]*models.StorageTable{updated})
+
+	case "compress":
+		requireTable(logger, *table)
+		period := parsePeriod(logger, "older-than", *olderThan)
+		if period == nil {
+			logger.Fatal("-older-than 不能为 never")
+		}
+		compressed, err := storageDAO.CompressChunks(ctx, *table, *period)
+		if err != nil {
+			logger.Fatal("手动压缩失败", zap.Error(err))
+		}
+		logger.Info("压缩完成", zap.String("table", *table), zap.Int64("compressed_chunks", compressed))
+
+	case "kline-retention":
+		if *granularity != "" {
+			if *retention == "" {
+				logger.Fatal("修改K线保留期需要 -retention")
+			}
+			period := parsePeriod(logger, "retention", *retention)
+			var days *int
+			if period != nil {
+				if *period%(24*time.Hour) != 0 {
+					logger.Fatal("K线保留期必须是整天", zap.String("retention", *retention))
+				}
+				d := int(*period / (24 * time.Hour))
+				days = &d
+			}
+			if err := storageDAO.SetKlineRetention(ctx, *granularity, days); err != nil {
+				logger.Fatal("修改K线保留期失败", zap.Error(err))
+			}
+		}
+		policies, err := storageDAO.ListKlineRetention(ctx)
+		if err != nil {
+			logger.Fatal("获取K线保留期失败", zap.Error(err))
+		}
+		printKlineRetention(policies)
+
+	case "archive":
+		archiveConfig := &cfg.Storage.Archive
+		store, err := archive.NewStore(archiveConfig)
+		if err != nil {
+			logger.Fatal("创建归档存储失败", zap.Error(err))
+		}
+		archiver, err := archive.NewArchiver(archive.NewArchiverConfig(archiveConfig), dao.NewArchiveDAO(db, logger), store, logger)
+		if err != nil {
+			logger.Fatal("创建归档任务失败", zap.Error(err))
+		}
+
+		var result archive.ArchiveResult
+		if *day != "" {
+			requireTable(logger, *table)
+			result, err = archiver.ArchiveDay(ctx, *table, parseDay(logger, *day))
+		} else {
+			result, err = archiver.ArchiveOnce(ctx)
+		}
+		if err != nil {
+			logger.Fatal("归档失败", zap.Error(err))
+		}
+		logger.Info("归档完成", zap.Int("files", result.Files), zap.Int64("rows", result.Rows), zap.Int64("bytes", result.Bytes))
+
+	case "archives":
+		requireTable(logger, *table)
+		from := time.Time{}
+		if *day != "" {
+			from = parseDay(logger, *day)
+		}
+		manifests, err := dao.NewArchiveDAO(db, logger).ListManifests(ctx, *table, *symbol, from, time.Now())
+		if err != nil {
+			logger.Fatal("获取归档清单失败", zap.Error(err))
+		}
+		printManifests(manifests)
+
+	default:
+		logger.Fatal("未知的存储操作", zap.String("action", *action))
+	}
+}
+
+// issueToken 按服务端的认证配置签发 Token 并输出到标准输出
+func issueToken(logger *zap.Logger, authCfg *config.AuthConfig, user, permissions string, expiry time.Duration) {
+	if user == "" {
+		logger.Fatal("签发 Token 需要 -user")
+	}
+	var perms []string
+	for _, p := range strings.Split(permissions, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			perms = append(perms, p)
+		}
+	}
+	if len(perms) == 0 {
+		logger.Fatal("签发 Token 需要 -permissions")
+	}
+
+	if expiry > 0 {
+		authCfg.TokenExpiry = expiry
+	}
+	authManager, err := data_collection.NewAuthManagerFromConfig(authCfg, logger)
+	if err != nil {
+		logger.Fatal("创建认证管理器失败", zap.Error(err))
+	}
+	if authManager == nil {
+		logger.Fatal("认证未启用（auth.enabled），服务端不校验 Token")
+	}
+
+	token, err := authManager.GenerateToken(user, perms)
+	if err != nil {
+		logger.Fatal("签发 Token 失败", zap.Error(err))
+	}
+	fmt.Println(token)
+}
+
+// requireTable 校验 -table 参数
+func requireTable(logger *zap.Logger, table string) {
+	if table == "" {
+		logger.Fatal("缺少 -table 参数")
+	}
+}
+
+// parsePeriod 解析策略时长，never 返回空
+func parsePeriod(logger *zap.Logger, name, value string) *time.Duration {
+	period, err := models.ParseStoragePeriod(value)
+	if err != nil {
+		logger.Fatal("参数无效", zap.String("flag", name), zap.Error(err))
+	}
+	return period
+}
+
+// parseDay 解析 -day 参数
+func parseDay(logger *zap.Logger, value string) time.Time {
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		logger.Fatal("参数无效", zap.String("flag", "day"), zap.Error(err))
+	}
+	return day
+}
+
+func printTables(tables []*models.StorageTable) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKIND\tCOMPRESS AFTER\tDROP AFTER\tCHUNKS\tCOMPRESSED\tSIZE\tRATIO")
+	for _, t := range tables {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			t.Name, t.Kind, policyString(t.CompressAfter, t.CompressionEnabled), policyString(t.DropAfter, true),
+			t.TotalChunks, t.CompressedChunks, formatBytes(t.TotalBytes), formatRatio(t.CompressionRatio))
+	}
+	w.Flush()
+}
+
+func printChunks(chunks []*models.StorageChunk) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHUNK\tRANGE START\tRANGE END\tCOMPRESSED\tSIZE\tRATIO")
+	for _, c := range chunks {
+		fmt.Fprintf(w, "%s.%s\t%s\t%s\t%t\t%s\t%s\n",
+			c.Schema, c.Name, c.RangeStart.Format(time.RFC3339), c.RangeEnd.Format(time.RFC3339),
+			c.IsCompressed, formatBytes(c.TotalBytes), formatRatio(c.CompressionRatio))
+	}
+	w.Flush()
+}
+
+func printKlineRetention(policies []*models.KlineRetentionPolicy) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GRANULARITY\tRETENTION")
+	for _, p := range policies {
+		retention := models.StoragePeriodNever
+		if p.RetentionDays != nil {
+			retention = fmt.Sprintf("%dd", *p.RetentionDays)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", p.Granularity, retention)
+	}
+	w.Flush()
+}
+
+func printManifests(manifests []*models.ArchiveManifest) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SYMBOL\tDAY\tROWS\tSIZE\tPATH")
+	for _, m := range manifests {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+			m.Symbol, m.Day.Format("2006-01-02"), m.RowCount, formatBytes(m.SizeBytes), m.Path)
+	}
+	w.Flush()
+}
+
+// policyString 策略为空时显示 never，未启用压缩时显示 disabled
+func policyString(value *string, enabled bool) string {
+	switch {
+	case !enabled:
+		return "disabled"
+	case value == nil:
+		return models.StoragePeriodNever
+	default:
+		return *value
+	}
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func formatRatio(ratio float64) string {
+	if ratio == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fx", ratio)
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
@@ -54,14 +56,14 @@ func priceCacheExamples(priceCache cache.PriceCache) {
 	fmt.Println("=== 价格缓存使用示例 ===")
 
 	// 设置单个价格
-	bidPrice := 50000.0
-	askPrice := 50010.0
-	baseVolume := 1000.0
-	quoteVolume := 50000000.0
+	bidPrice := decimal.NewFromInt(50000)
+	askPrice := decimal.NewFromInt(50010)
+	baseVolume := decimal.NewFromInt(1000)
+	quoteVolume := decimal.NewFromInt(50000000)
 
 	priceData := &cache.PriceData{
 		Symbol:      "BTCUSDT",
-		LastPrice:   50005.0,
+		LastPrice:   decimal.NewFromInt(50005),
 		BidPrice:    &bidPrice,
 		AskPrice:    &askPrice,
 		BaseVolume:  &baseVolume,
@@ -82,27 +84,27 @@ func priceCacheExamples(priceCache cache.PriceCache) {
 		log.Printf("获取价格缓存失败: %v", err)
 		return
 	}
-	fmt.Printf("✅ 获取价格缓存成功: %s - Last: %.2f, Bid: %.2f, Ask: %.2f\n",
-		retrievedPrice.Symbol, retrievedPrice.LastPrice, *retrievedPrice.BidPrice, *retrievedPrice.AskPrice)
+	fmt.Printf("✅ 获取价格缓存成功: %s - Last: %s, Bid: %s, Ask: %s\n",
+		retrievedPrice.Symbol, retrievedPrice.LastPrice.StringFixed(2), retrievedPrice.BidPrice.StringFixed(2), retrievedPrice.AskPrice.StringFixed(2))
 
 	// 批量设置价格
 	priceDataList := []*cache.PriceData{
 		{
 			Symbol:      "ETHUSDT",
-			LastPrice:   3000.0,
-			BidPrice:    &[]float64{2999.0}[0],
-			AskPrice:    &[]float64{3001.0}[0],
-			BaseVolume:  &[]float64{500.0}[0],
-			QuoteVolume: &[]float64{1500000.0}[0],
+			LastPrice:   decimal.NewFromInt(3000),
+			BidPrice:    models.DecimalPtr(decimal.NewFromInt(2999)),
+			AskPrice:    models.DecimalPtr(decimal.NewFromInt(3001)),
+			BaseVolume:  models.DecimalPtr(decimal.NewFromInt(500)),
+			QuoteVolume: models.DecimalPtr(decimal.NewFromInt(1500000)),
 			Timestamp:   time.Now(),
 		},
 		{
 			Symbol:      "ADAUSDT",
-			LastPrice:   0.5,
-			BidPrice:    &[]float64{0.499}[0],
-			AskPrice:    &[]float64{0.501}[0],
-			BaseVolume:  &[]float64{10000.0}[0],
-			QuoteVolume: &[]float64{5000.0}[0],
+			LastPrice:   decimal.RequireFromString("0.5"),
+			BidPrice:    models.DecimalPtr(decimal.RequireFromString("0.499")),
+			AskPrice:    models.DecimalPtr(decimal.RequireFromString("0.501")),
+			BaseVolume:  models.DecimalPtr(decimal.NewFromInt(10000)),
+			QuoteVolume: models.DecimalPtr(decimal.NewFromInt(5000)),
 			Timestamp:   time.Now(),
 		},
 	}
@@ -126,8 +128,8 @@ func priceCacheExamples(priceCache cache.PriceCache) {
 	fmt.Printf("✅ 批量获取价格缓存成功: %d 个\n", len(multiplePrices))
 
 	for _, price := range multiplePrices {
-		fmt.Printf("  - %s: %.2f (Bid: %.2f, Ask: %.2f)\n",
-			price.Symbol, price.LastPrice, *price.BidPrice, *price.AskPrice)
+		fmt.Printf("  - %s: %s (Bid: %s, Ask: %s)\n",
+			price.Symbol, price.LastPrice.StringFixed(2), price.BidPrice.StringFixed(2), price.AskPrice.StringFixed(2))
 	}
 }
 
@@ -209,14 +211,14 @@ func cacheConsistencyExamples(priceCache cache.PriceCache) {
 	fmt.Println("2. 缓存更新策略测试")
 
 	// 先设置价格
-	bidPrice := 51000.0
-	askPrice := 51010.0
-	baseVolume := 1200.0
-	quoteVolume := 60000000.0
+	bidPrice := decimal.NewFromInt(51000)
+	askPrice := decimal.NewFromInt(51010)
+	baseVolume := decimal.NewFromInt(1200)
+	quoteVolume := decimal.NewFromInt(60000000)
 
 	priceData := &cache.PriceData{
 		Symbol:      "BTCUSDT",
-		LastPrice:   51005.0,
+		LastPrice:   decimal.NewFromInt(51005),
 		BidPrice:    &bidPrice,
 		AskPrice:    &askPrice,
 		BaseVolume:  &baseVolume,
@@ -237,12 +239,12 @@ func cacheConsistencyExamples(priceCache cache.PriceCache) {
 		return
 	}
 
-	if retrievedPrice.LastPrice == priceData.LastPrice {
-		fmt.Printf("✅ 缓存一致性验证成功: 设置值 %.2f = 获取值 %.2f\n",
-			priceData.LastPrice, retrievedPrice.LastPrice)
+	if retrievedPrice.LastPrice.Equal(priceData.LastPrice) {
+		fmt.Printf("✅ 缓存一致性验证成功: 设置值 %s = 获取值 %s\n",
+			priceData.LastPrice.StringFixed(2), retrievedPrice.LastPrice.StringFixed(2))
 	} else {
-		fmt.Printf("❌ 缓存一致性验证失败: 设置值 %.2f != 获取值 %.2f\n",
-			priceData.LastPrice, retrievedPrice.LastPrice)
+		fmt.Printf("❌ 缓存一致性验证失败: 设置值 %s != 获取值 %s\n",
+			priceData.LastPrice.StringFixed(2), retrievedPrice.LastPrice.StringFixed(2))
 	}
 
 	// 模拟TTL过期测试
@@ -251,11 +253,11 @@ func cacheConsistencyExamples(priceCache cache.PriceCache) {
 	// 设置一个短TTL的价格（注意：实际TTL由配置控制）
 	shortTTLPrice := &cache.PriceData{
 		Symbol:      "TESTUSDT",
-		LastPrice:   100.0,
-		BidPrice:    &[]float64{99.0}[0],
-		AskPrice:    &[]float64{101.0}[0],
-		BaseVolume:  &[]float64{100.0}[0],
-		QuoteVolume: &[]float64{10000.0}[0],
+		LastPrice:   decimal.NewFromInt(100),
+		BidPrice:    models.DecimalPtr(decimal.NewFromInt(99)),
+		AskPrice:    models.DecimalPtr(decimal.NewFromInt(101)),
+		BaseVolume:  models.DecimalPtr(decimal.NewFromInt(100)),
+		QuoteVolume: models.DecimalPtr(decimal.NewFromInt(10000)),
 		Timestamp:   time.Now(),
 	}
 
@@ -281,11 +283,11 @@ func cacheConsistencyExamples(priceCache cache.PriceCache) {
 	// 批量设置100个价格
 	for i := 0; i < 100; i++ {
 		symbol := fmt.Sprintf("TEST%dUSDT", i)
-		price := 100.0 + float64(i)
-		bid := price - 0.5
-		ask := price + 0.5
-		baseVol := 1000.0 + float64(i*10)
-		quoteVol := 100000.0 + float64(i*1000)
+		price := decimal.NewFromInt(int64(100 + i))
+		bid := price.Sub(decimal.RequireFromString("0.5"))
+		ask := price.Add(decimal.RequireFromString("0.5"))
+		baseVol := decimal.NewFromInt(int64(1000 + i*10))
+		quoteVol := decimal.NewFromInt(int64(100000 + i*1000))
 
 		priceData := &cache.PriceData{
 			Symbol:      symbol,
@@ -10,6 +10,7 @@ import (
 	"github.com/haxrd/cryptosignal-hunter/internal/dao"
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -163,12 +164,12 @@ func klineExamples(klineDAO dao.KlineDAO) {
 		Symbol:      "BTCUSDT",
 		Timestamp:   now,
 		Granularity: "1h",
-		Open:        50000.0,
-		High:        51000.0,
-		Low:         49000.0,
-		Close:       50500.0,
-		BaseVolume:  1000.0,
-		QuoteVolume: 50000000.0,
+		Open:        decimal.NewFromInt(50000),
+		High:        decimal.NewFromInt(51000),
+		Low:         decimal.NewFromInt(49000),
+		Close:       decimal.NewFromInt(50500),
+		BaseVolume:  decimal.NewFromInt(1000),
+		QuoteVolume: decimal.NewFromInt(50000000),
 	}
 
 	err := klineDAO.Create(ctx, kline)
@@ -186,12 +187,12 @@ func klineExamples(klineDAO dao.KlineDAO) {
 			Symbol:      "ETHUSDT",
 			Timestamp:   timestamp,
 			Granularity: "1h",
-			Open:        3000.0 + float64(i*10),
-			High:        3100.0 + float64(i*10),
-			Low:         2900.0 + float64(i*10),
-			Close:       3050.0 + float64(i*10),
-			BaseVolume:  500.0 + float64(i*5),
-			QuoteVolume: 1500000.0 + float64(i*10000),
+			Open:        decimal.NewFromInt(int64(3000 + i*10)),
+			High:        decimal.NewFromInt(int64(3100 + i*10)),
+			Low:         decimal.NewFromInt(int64(2900 + i*10)),
+			Close:       decimal.NewFromInt(int64(3050 + i*10)),
+			BaseVolume:  decimal.NewFromInt(int64(500 + i*5)),
+			QuoteVolume: decimal.NewFromInt(int64(1500000 + i*10000)),
 		}
 		klines = append(klines, kline)
 	}
@@ -237,10 +238,10 @@ func priceTickExamples(priceTickDAO dao.PriceTickDAO) {
 	fmt.Println("\n=== PriceTickDAO 使用示例 ===")
 
 	// 创建价格数据
-	bidPrice := 50000.0
-	askPrice := 50010.0
-	baseVolume := 1000.0
-	quoteVolume := 50000000.0
+	bidPrice := decimal.NewFromInt(50000)
+	askPrice := decimal.NewFromInt(50010)
+	baseVolume := decimal.NewFromInt(1000)
+	quoteVolume := decimal.NewFromInt(50000000)
 
 	priceTick := &models.PriceTick{
 		Symbol:      "BTCUSDT",
@@ -263,10 +264,10 @@ func priceTickExamples(priceTickDAO dao.PriceTickDAO) {
 	symbols := []string{"ETHUSDT", "ADAUSDT", "DOTUSDT"}
 
 	for i, symbol := range symbols {
-		bid := 3000.0 + float64(i*100)
-		ask := bid + 10.0
-		baseVol := 500.0 + float64(i*50)
-		quoteVol := 1500000.0 + float64(i*100000)
+		bid := decimal.NewFromInt(int64(3000 + i*100))
+		ask := bid.Add(decimal.NewFromInt(10))
+		baseVol := decimal.NewFromInt(int64(500 + i*50))
+		quoteVol := decimal.NewFromInt(int64(1500000 + i*100000))
 
 		priceTick := &models.PriceTick{
 			Symbol:      symbol,
@@ -292,8 +293,8 @@ func priceTickExamples(priceTickDAO dao.PriceTickDAO) {
 		log.Printf("查询最新价格失败: %v", err)
 		return
 	}
-	fmt.Printf("✅ 查询最新价格成功: %s - Bid: %.2f, Ask: %.2f\n",
-		latestPrice.Symbol, *latestPrice.BidPrice, *latestPrice.AskPrice)
+	fmt.Printf("✅ 查询最新价格成功: %s - Bid: %s, Ask: %s\n",
+		latestPrice.Symbol, latestPrice.BidPrice.StringFixed(2), latestPrice.AskPrice.StringFixed(2))
 
 	// 批量查询最新价格
 	symbolsToQuery := []string{"BTCUSDT", "ETHUSDT", "ADAUSDT"}
@@ -341,12 +342,12 @@ func transactionExamples(db *gorm.DB, logger *zap.Logger) {
 			Symbol:      "LTCUSDT",
 			Timestamp:   time.Now().Truncate(time.Hour),
 			Granularity: "1h",
-			Open:        200.0,
-			High:        210.0,
-			Low:         190.0,
-			Close:       205.0,
-			BaseVolume:  100.0,
-			QuoteVolume: 20000.0,
+			Open:        decimal.NewFromInt(200),
+			High:        decimal.NewFromInt(210),
+			Low:         decimal.NewFromInt(190),
+			Close:       decimal.NewFromInt(205),
+			BaseVolume:  decimal.NewFromInt(100),
+			QuoteVolume: decimal.NewFromInt(20000),
 		}
 
 		if err := tx.Create(kline).Error; err != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/haxrd/cryptosignal-hunter/internal/cache"
 	"github.com/haxrd/cryptosignal-hunter/internal/dao"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -185,12 +186,12 @@ func TestIntegration_KlineEndToEnd(t *testing.T) {
 				Symbol:      "BTCUSDT",
 				Timestamp:   timestamp,
 				Granularity: "1h",
-				Open:        50000.0 + float64((7-day)*1000) + float64(hour*10),
-				High:        51000.0 + float64((7-day)*1000) + float64(hour*10),
-				Low:         49000.0 + float64((7-day)*1000) + float64(hour*10),
-				Close:       50500.0 + float64((7-day)*1000) + float64(hour*10),
-				BaseVolume:  1000.0 + float64(hour*10),
-				QuoteVolume: 50000000.0 + float64((7-day)*1000000) + float64(hour*10000),
+				Open:        decimal.NewFromInt(int64(50000 + (7-day)*1000 + hour*10)),
+				High:        decimal.NewFromInt(int64(51000 + (7-day)*1000 + hour*10)),
+				Low:         decimal.NewFromInt(int64(49000 + (7-day)*1000 + hour*10)),
+				Close:       decimal.NewFromInt(int64(50500 + (7-day)*1000 + hour*10)),
+				BaseVolume:  decimal.NewFromInt(int64(1000 + hour*10)),
+				QuoteVolume: decimal.NewFromInt(int64(50000000 + (7-day)*1000000 + hour*10000)),
 			}
 			klines = append(klines, kline)
 		}
@@ -229,13 +230,13 @@ func TestIntegration_KlineEndToEnd(t *testing.T) {
 	// 5. 验证数据完整性
 	for _, kline := range recentKlines {
 		assert.NotEmpty(t, kline.Symbol)
-		assert.True(t, kline.High >= kline.Low)
-		assert.True(t, kline.High >= kline.Open)
-		assert.True(t, kline.High >= kline.Close)
-		assert.True(t, kline.Low <= kline.Open)
-		assert.True(t, kline.Low <= kline.Close)
-		assert.True(t, kline.BaseVolume > 0)
-		assert.True(t, kline.QuoteVolume > 0)
+		assert.True(t, kline.High.GreaterThanOrEqual(kline.Low))
+		assert.True(t, kline.High.GreaterThanOrEqual(kline.Open))
+		assert.True(t, kline.High.GreaterThanOrEqual(kline.Close))
+		assert.True(t, kline.Low.LessThanOrEqual(kline.Open))
+		assert.True(t, kline.Low.LessThanOrEqual(kline.Close))
+		assert.True(t, kline.BaseVolume.IsPositive())
+		assert.True(t, kline.QuoteVolume.IsPositive())
 	}
 
 	// 6. 测试分页查询
@@ -266,17 +267,17 @@ func TestIntegration_PriceTickEndToEnd(t *testing.T) {
 	priceCache := cache.NewPriceCache(cacheClient)
 
 	// 测试数据
-	bidPrice1 := 49950.0
-	askPrice1 := 50050.0
-	baseVolume1 := 1000.0
-	bidPrice2 := 2995.0
-	askPrice2 := 3005.0
-	baseVolume2 := 5000.0
+	bidPrice1 := decimal.NewFromInt(49950)
+	askPrice1 := decimal.NewFromInt(50050)
+	baseVolume1 := decimal.NewFromInt(1000)
+	bidPrice2 := decimal.NewFromInt(2995)
+	askPrice2 := decimal.NewFromInt(3005)
+	baseVolume2 := decimal.NewFromInt(5000)
 
 	priceTicks := []*models.PriceTick{
 		{
 			Symbol:     "BTCUSDT",
-			LastPrice:  50000.0,
+			LastPrice:  decimal.NewFromInt(50000),
 			BidPrice:   &bidPrice1,
 			AskPrice:   &askPrice1,
 			BaseVolume: &baseVolume1,
@@ -284,7 +285,7 @@ func TestIntegration_PriceTickEndToEnd(t *testing.T) {
 		},
 		{
 			Symbol:     "ETHUSDT",
-			LastPrice:  3000.0,
+			LastPrice:  decimal.NewFromInt(3000),
 			BidPrice:   &bidPrice2,
 			AskPrice:   &askPrice2,
 			BaseVolume: &baseVolume2,
@@ -316,21 +317,21 @@ func TestIntegration_PriceTickEndToEnd(t *testing.T) {
 	btcPrice, err := priceCache.GetPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", btcPrice.Symbol)
-	assert.Equal(t, 50000.0, btcPrice.LastPrice)
+	assert.Equal(t, "50000", btcPrice.LastPrice.String())
 
 	ethPrice, err := priceCache.GetPrice(context.Background(), "ETHUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "ETHUSDT", ethPrice.Symbol)
-	assert.Equal(t, 3000.0, ethPrice.LastPrice)
+	assert.Equal(t, "3000", ethPrice.LastPrice.String())
 
 	// 4. 从数据库查询价格数据
 	dbBtcPrice, err := priceTickDAO.GetLatest(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", dbBtcPrice.Symbol)
-	assert.Equal(t, 50000.0, dbBtcPrice.LastPrice)
+	assert.Equal(t, "50000", dbBtcPrice.LastPrice.String())
 
 	// 5. 验证缓存和数据库数据一致性
-	assert.Equal(t, btcPrice.LastPrice, dbBtcPrice.LastPrice)
+	assert.True(t, btcPrice.LastPrice.Equal(dbBtcPrice.LastPrice))
 	assert.Equal(t, btcPrice.BidPrice, dbBtcPrice.BidPrice)
 	assert.Equal(t, btcPrice.AskPrice, dbBtcPrice.AskPrice)
 
@@ -342,15 +343,15 @@ func TestIntegration_PriceTickEndToEnd(t *testing.T) {
 	// 7. 验证价格数据完整性
 	for _, price := range allPrices {
 		assert.NotEmpty(t, price.Symbol)
-		assert.True(t, price.LastPrice > 0)
+		assert.True(t, price.LastPrice.IsPositive())
 		if price.BidPrice != nil {
-			assert.True(t, *price.BidPrice > 0)
+			assert.True(t, price.BidPrice.IsPositive())
 		}
 		if price.AskPrice != nil {
-			assert.True(t, *price.AskPrice > 0)
+			assert.True(t, price.AskPrice.IsPositive())
 		}
 		if price.BaseVolume != nil {
-			assert.True(t, *price.BaseVolume > 0)
+			assert.True(t, price.BaseVolume.IsPositive())
 		}
 	}
 
@@ -423,13 +424,13 @@ func TestIntegration_CacheTTLExpiration(t *testing.T) {
 	priceCache := cache.NewPriceCache(cacheClient)
 
 	// 测试数据
-	bidPrice := 49950.0
-	askPrice := 50050.0
-	baseVolume := 1000.0
+	bidPrice := decimal.NewFromInt(49950)
+	askPrice := decimal.NewFromInt(50050)
+	baseVolume := decimal.NewFromInt(1000)
 
 	priceData := &cache.PriceData{
 		Symbol:     "BTCUSDT",
-		LastPrice:  50000.0,
+		LastPrice:  decimal.NewFromInt(50000),
 		BidPrice:   &bidPrice,
 		AskPrice:   &askPrice,
 		BaseVolume: &baseVolume,
@@ -488,13 +489,13 @@ func TestIntegration_DataConsistency(t *testing.T) {
 	require.NoError(t, err)
 
 	// 2. 创建价格数据
-	bidPrice := 49950.0
-	askPrice := 50050.0
-	baseVolume := 1000.0
+	bidPrice := decimal.NewFromInt(49950)
+	askPrice := decimal.NewFromInt(50050)
+	baseVolume := decimal.NewFromInt(1000)
 
 	priceTick := &models.PriceTick{
 		Symbol:     "BTCUSDT",
-		LastPrice:  50000.0,
+		LastPrice:  decimal.NewFromInt(50000),
 		BidPrice:   &bidPrice,
 		AskPrice:   &askPrice,
 		BaseVolume: &baseVolume,
@@ -508,24 +509,24 @@ func TestIntegration_DataConsistency(t *testing.T) {
 		Symbol:      "BTCUSDT",
 		Timestamp:   time.Now().Add(-time.Hour),
 		Granularity: "1h",
-		Open:        49000.0,
-		High:        51000.0,
-		Low:         48000.0,
-		Close:       50000.0,
-		BaseVolume:  1000.0,
-		QuoteVolume: 50000000.0,
+		Open:        decimal.NewFromInt(49000),
+		High:        decimal.NewFromInt(51000),
+		Low:         decimal.NewFromInt(48000),
+		Close:       decimal.NewFromInt(50000),
+		BaseVolume:  decimal.NewFromInt(1000),
+		QuoteVolume: decimal.NewFromInt(50000000),
 	}
 	err = klineDAO.Create(context.Background(), kline)
 	require.NoError(t, err)
 
 	// 4. 设置缓存
-	bidPriceCache := 49950.0
-	askPriceCache := 50050.0
-	baseVolumeCache := 1000.0
+	bidPriceCache := decimal.NewFromInt(49950)
+	askPriceCache := decimal.NewFromInt(50050)
+	baseVolumeCache := decimal.NewFromInt(1000)
 
 	cacheData := &cache.PriceData{
 		Symbol:     "BTCUSDT",
-		LastPrice:  50000.0,
+		LastPrice:  decimal.NewFromInt(50000),
 		BidPrice:   &bidPriceCache,
 		AskPrice:   &askPriceCache,
 		BaseVolume: &baseVolumeCache,
@@ -544,7 +545,7 @@ func TestIntegration_DataConsistency(t *testing.T) {
 	retrievedPriceTick, err := priceTickDAO.GetLatest(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", retrievedPriceTick.Symbol)
-	assert.Equal(t, 50000.0, retrievedPriceTick.LastPrice)
+	assert.Equal(t, "50000", retrievedPriceTick.LastPrice.String())
 
 	// 验证K线数据存在
 	klines, err := klineDAO.GetByRange(context.Background(), "BTCUSDT", "1h", time.Now().Add(-2*time.Hour), time.Now(), 10, 0)
@@ -556,10 +557,10 @@ func TestIntegration_DataConsistency(t *testing.T) {
 	cachedPrice, err := priceCache.GetPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", cachedPrice.Symbol)
-	assert.Equal(t, 50000.0, cachedPrice.LastPrice)
+	assert.Equal(t, "50000", cachedPrice.LastPrice.String())
 
 	// 6. 验证数据关联性
-	assert.Equal(t, retrievedPriceTick.LastPrice, cachedPrice.LastPrice)
+	assert.True(t, retrievedPriceTick.LastPrice.Equal(cachedPrice.LastPrice))
 	assert.Equal(t, retrievedPriceTick.Symbol, cachedPrice.Symbol)
 
 	t.Log("✅ 数据一致性测试通过：交易对 → 价格数据 → K线数据 → 缓存数据，全链路一致性验证")
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+const (
+	// defaultChangeRateWindow 默认变化率窗口
+	defaultChangeRateWindow = "1m"
+
+	// defaultChangeRateLookback 未指定开始时间时的默认回溯时长
+	defaultChangeRateLookback = 24 * time.Hour
+
+	// defaultTopMoversPeriod 涨跌幅排行的默认统计周期
+	defaultTopMoversPeriod = "1h"
+)
+
+// ChangeRateHandler 价格变化率处理器
+type ChangeRateHandler struct {
+	changeRateDAO dao.PriceChangeRateDAO
+	logger        *zap.Logger
+}
+
+// NewChangeRateHandler 创建价格变化率处理器
+func NewChangeRateHandler(changeRateDAO dao.PriceChangeRateDAO, logger *zap.Logger) *ChangeRateHandler {
+	return &ChangeRateHandler{
+		changeRateDAO: changeRateDAO,
+		logger:        logger,
+	}
+}
+
+// GetChangeRateHistory 获取交易对在指定窗口的变化率历史
+func (h *ChangeRateHandler) GetChangeRateHistory(c *gin.Context) {
+	ctx := context.Background()
+
+	// 获取查询参数
+	symbol := c.GetString("symbol")
+	window := c.GetString("window")
+	page := c.GetInt("page")
+	pageSize := c.GetInt("page_size")
+
+	endTime := time.Now()
+	if value, exists := c.Get("end_time"); exists {
+		endTime = time.Unix(value.(int64), 0)
+	}
+	startTime := endTime.Add(-defaultChangeRateLookback)
+	if value, exists := c.Get("start_time"); exists {
+		startTime = time.Unix(value.(int64), 0)
+	}
+
+	h.logger.Info("获取变化率历史",
+		zap.String("symbol", symbol),
+		zap.String("window", window),
+		zap.Time("start_time", startTime),
+		zap.Time("end_time", endTime),
+		zap.Int("page", page),
+		zap.Int("page_size", pageSize),
+	)
+
+	offset := (page - 1) * pageSize
+	rates, err := h.changeRateDAO.GetHistory(ctx, symbol, window, startTime, endTime, pageSize, offset)
+	if err != nil {
+		h.logger.Error("获取变化率历史失败",
+			zap.String("symbol", symbol),
+			zap.String("window", window),
+			zap.Error(err),
+		)
+		InternalErrorResponse(c, "获取变化率历史失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	rateList := make([]map[string]interface{}, len(rates))
+	for i, rate := range rates {
+		rateList[i] = h.changeRateToMap(rate)
+	}
+
+	// 计算分页信息（简化处理，total 为当前页记录数）
+	pagination := CalculatePagination(page, pageSize, len(rates))
+
+	PaginatedResponse(c, "获取变化率历史成功", rateList, pagination)
+}
+
+// GetTopMovers 获取指定窗口在统计周期内变化最大的交易对
+func (h *ChangeRateHandler) GetTopMovers(c *gin.Context) {
+	ctx := context.Background()
+
+	window := c.GetString("window")
+	direction := c.DefaultQuery("direction", dao.MoverDirectionAbs)
+	periodStr := c.DefaultQuery("period", defaultTopMoversPeriod)
+	limitStr := c.DefaultQuery("limit", "10")
+
+	var errors ValidationErrors
+
+	period, err := models.ParseTimeWindow(periodStr)
+	if err != nil {
+		errors = append(errors, ValidationError{
+			Field:   "period",
+			Message: "统计周期格式无效，应为数字+单位(s/m/h/d)，如 1h",
+			Value:   periodStr,
+		})
+	}
+
+	if !contains([]string{dao.MoverDirectionUp, dao.MoverDirectionDown, dao.MoverDirectionAbs}, direction) {
+		errors = append(errors, ValidationError{
+			Field:   "direction",
+			Message: "排序方向必须是 up、down 或 abs",
+			Value:   direction,
+		})
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		errors = append(errors, ValidationError{
+			Field:   "limit",
+			Message: "数量必须是1-100之间的整数",
+			Value:   limitStr,
+		})
+	}
+
+	if len(errors) > 0 {
+		ValidationErrorResponse(c, "涨跌幅排行参数验证失败", errors)
+		return
+	}
+
+	since := time.Now().Add(-period)
+
+	h.logger.Info("获取涨跌幅排行",
+		zap.String("window", window),
+		zap.String("direction", direction),
+		zap.String("period", periodStr),
+		zap.Int("limit", limit),
+	)
+
+	rates, err := h.changeRateDAO.GetTopMovers(ctx, window, since, direction, limit)
+	if err != nil {
+		h.logger.Error("获取涨跌幅排行失败",
+			zap.String("window", window),
+			zap.Error(err),
+		)
+		InternalErrorResponse(c, "获取涨跌幅排行失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	rateList := make([]map[string]interface{}, len(rates))
+	for i, rate := range rates {
+		rateList[i] = h.changeRateToMap(rate)
+	}
+
+	SuccessResponse(c, "获取涨跌幅排行成功", map[string]interface{}{
+		"window":    window,
+		"direction": direction,
+		"period":    periodStr,
+		"since":     since.Unix(),
+		"movers":    rateList,
+	})
+}
+
+// changeRateToMap 将变化率模型转换为map
+func (h *ChangeRateHandler) changeRateToMap(rate *models.PriceChangeRate) map[string]interface{} {
+	return map[string]interface{}{
+		"symbol":       rate.Symbol,
+		"window":       rate.WindowSize,
+		"timestamp":    rate.Timestamp.Unix(),
+		"change_rate":  rate.ChangeRate,
+		"price_before": rate.PriceBefore,
+		"price_after":  rate.PriceAfter,
+	}
+}
+
+// ChangeRateWindowValidator 变化率时间窗口验证中间件
+func ChangeRateWindowValidator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		window := c.DefaultQuery("window", defaultChangeRateWindow)
+
+		if _, err := models.ParseTimeWindow(window); err != nil {
+			ValidationErrorResponse(c, "时间窗口参数无效", ValidationError{
+				Field:   "window",
+				Message: "时间窗口格式无效，应为数字+单位(s/m/h/d)，如 10s、5m、4h",
+				Value:   window,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("window", window)
+		c.Next()
+	}
+}
+
+// RegisterChangeRateRoutes 注册价格变化率路由
+func RegisterChangeRateRoutes(router *gin.RouterGroup, changeRateDAO dao.PriceChangeRateDAO, logger *zap.Logger) {
+	handler := NewChangeRateHandler(changeRateDAO, logger)
+
+	// 涨跌幅排行
+	router.GET("/change-rates/top-movers",
+		ChangeRateWindowValidator(),
+		handler.GetTopMovers,
+	)
+
+	// 变化率历史
+	router.GET("/change-rates/:symbol",
+		SymbolValidator(),
+		ChangeRateWindowValidator(),
+		TimeRangeValidator(),
+		PaginationValidator(),
+		handler.GetChangeRateHistory,
+	)
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// setupChangeRateTestRouter 设置变化率API测试路由
+func setupChangeRateTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.PriceChangeRate{}))
+
+	now := time.Now().UTC()
+	rates := []*models.PriceChangeRate{
+		{Symbol: "BTCUSDT", WindowSize: "10s", Timestamp: now.Add(-30 * time.Minute), ChangeRate: 0.5, PriceBefore: 100, PriceAfter: 100.5},
+		{Symbol: "BTCUSDT", WindowSize: "10s", Timestamp: now.Add(-20 * time.Minute), ChangeRate: 2.5, PriceBefore: 100, PriceAfter: 102.5},
+		{Symbol: "BTCUSDT", WindowSize: "10s", Timestamp: now.Add(-48 * time.Hour), ChangeRate: 1.0, PriceBefore: 100, PriceAfter: 101},
+		{Symbol: "BTCUSDT", WindowSize: "5m", Timestamp: now.Add(-10 * time.Minute), ChangeRate: 4.0, PriceBefore: 100, PriceAfter: 104},
+		{Symbol: "ETHUSDT", WindowSize: "10s", Timestamp: now.Add(-10 * time.Minute), ChangeRate: -3.0, PriceBefore: 100, PriceAfter: 97},
+	}
+	require.NoError(t, dao.NewPriceChangeRateDAO(db, zap.NewNop()).CreateBatch(t.Context(), rates))
+
+	router := gin.New()
+	RegisterChangeRateRoutes(router.Group("/api/v1"), dao.NewPriceChangeRateDAO(db, zap.NewNop()), zap.NewNop())
+	return router
+}
+
+// TestChangeRatesAPI_History 测试变化率历史API
+func TestChangeRatesAPI_History(t *testing.T) {
+	router := setupChangeRateTestRouter(t)
+
+	t.Run("默认查询最近24小时", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/change-rates/BTCUSDT?window=10s", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response PaginatedAPIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response.Data.([]interface{})
+		require.Len(t, data, 2)
+		latest := data[0].(map[string]interface{})
+		assert.Equal(t, "10s", latest["window"])
+		assert.Equal(t, 2.5, latest["change_rate"])
+	})
+
+	t.Run("指定时间范围", func(t *testing.T) {
+		start := time.Now().Add(-72 * time.Hour).Unix()
+		end := time.Now().Unix()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/change-rates/BTCUSDT?window=10s&start_time=%d&end_time=%d", start, end), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response PaginatedAPIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Data.([]interface{}), 3)
+	})
+
+	t.Run("无效的时间窗口", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/change-rates/BTCUSDT?window=5x", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+// TestChangeRatesAPI_TopMovers 测试涨跌幅排行API
+func TestChangeRatesAPI_TopMovers(t *testing.T) {
+	router := setupChangeRateTestRouter(t)
+
+	t.Run("按绝对值排行", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/change-rates/top-movers?window=10s&period=1h", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		movers := response.Data.(map[string]interface{})["movers"].([]interface{})
+		require.Len(t, movers, 2)
+		assert.Equal(t, "ETHUSDT", movers[0].(map[string]interface{})["symbol"])
+		assert.Equal(t, "BTCUSDT", movers[1].(map[string]interface{})["symbol"])
+		assert.Equal(t, 2.5, movers[1].(map[string]interface{})["change_rate"])
+	})
+
+	t.Run("只看涨幅", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/change-rates/top-movers?window=10s&direction=up&limit=1", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		movers := response.Data.(map[string]interface{})["movers"].([]interface{})
+		require.Len(t, movers, 1)
+		assert.Equal(t, "BTCUSDT", movers[0].(map[string]interface{})["symbol"])
+	})
+
+	t.Run("参数验证失败", func(t *testing.T) {
+		for _, query := range []string{"direction=sideways", "period=abc", "limit=0"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/change-rates/top-movers?"+query, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
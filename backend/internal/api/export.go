@@ -0,0 +1,491 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/archive"
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// 导出格式
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatNDJSON  = "ndjson"
+	ExportFormatParquet = "parquet"
+)
+
+// exportFlushRows 每写出多少行刷新一次响应，客户端可以边下载边处理
+const exportFlushRows = 1000
+
+// ExportSource 导出数据源，按时间升序逐条回调 [start, end) 内的数据，不把结果集加载到内存
+// dao.ArchiveDAO 直接读取数据库游标；archive.HistoryReader 在此基础上透明地读取已归档的日期。
+// K线从各周期的连续聚合读取，与查询接口使用同一份由行情生成的K线
+type ExportSource interface {
+	StreamTicks(ctx context.Context, symbol string, start, end time.Time, fn func(*models.PriceTick) error) error
+	StreamAggregatedKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error
+}
+
+// ExportLimits 导出接口的限制，零值表示不限制
+type ExportLimits struct {
+	MaxRange      time.Duration // 单次导出的最大时间范围
+	MaxConcurrent int           // 同时进行的导出数，每个导出在下载期间占用一个数据库连接
+}
+
+// ExportHandler 行情数据批量导出处理器
+type ExportHandler struct {
+	source ExportSource
+	limits ExportLimits
+	slots  chan struct{} // 进行中的导出，未限制并发时为空
+	logger *zap.Logger
+}
+
+// NewExportHandler 创建导出处理器
+func NewExportHandler(source ExportSource, limits ExportLimits, logger *zap.Logger) *ExportHandler {
+	h := &ExportHandler{
+		source: source,
+		limits: limits,
+		logger: logger,
+	}
+	if limits.MaxConcurrent > 0 {
+		h.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	return h
+}
+
+// exportRequest 导出参数
+type exportRequest struct {
+	symbol   string
+	interval string
+	start    time.Time
+	end      time.Time
+	format   string
+}
+
+// ExportTicks 导出价格数据
+func (h *ExportHandler) ExportTicks(c *gin.Context) {
+	req, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	writer := newTickExportWriter(req.format, c.Writer)
+	h.export(c, "ticks", req, writer, func(ctx context.Context) error {
+		return h.source.StreamTicks(ctx, req.symbol, req.start, req.end, writer.write)
+	})
+}
+
+// ExportKlines 导出K线数据
+func (h *ExportHandler) ExportKlines(c *gin.Context) {
+	req, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+	req.interval = c.GetString("interval")
+	if _, ok := dao.KlineAggregateView(req.interval); !ok {
+		ValidationErrorResponse(c, "参数验证失败", ValidationError{
+			Field:   "interval",
+			Message: "该时间间隔没有连续聚合K线，无法导出",
+			Value:   req.interval,
+		})
+		return
+	}
+
+	writer := newKlineExportWriter(req.format, c.Writer)
+	h.export(c, "klines", req, writer, func(ctx context.Context) error {
+		return h.source.StreamAggregatedKlines(ctx, req.symbol, req.interval, req.start, req.end, writer.write)
+	})
+}
+
+// parseRequest 解析 symbol、start_time、end_time 和 format 参数
+// start_time、end_time 必须同时提供，范围不能超过 ExportLimits.MaxRange
+func (h *ExportHandler) parseRequest(c *gin.Context) (*exportRequest, bool) {
+	var errs ValidationErrors
+
+	req := &exportRequest{
+		symbol: strings.ToUpper(c.Query("symbol")),
+		format: strings.ToLower(c.DefaultQuery("format", ExportFormatCSV)),
+	}
+	if !isValidSymbol(req.symbol) {
+		errs = append(errs, ValidationError{
+			Field:   "symbol",
+			Message: "交易对格式不正确，应为大写字母组合，如BTCUSDT",
+			Value:   c.Query("symbol"),
+		})
+	}
+
+	for _, field := range []string{"start_time", "end_time"} {
+		value := c.Query(field)
+		timestamp, err := parseTimestamp(value)
+		if err != nil {
+			errs = append(errs, ValidationError{
+				Field:   field,
+				Message: "时间格式无效，应为Unix时间戳或RFC3339",
+				Value:   value,
+			})
+			continue
+		}
+		if field == "start_time" {
+			req.start = time.Unix(timestamp, 0)
+		} else {
+			req.end = time.Unix(timestamp, 0)
+		}
+	}
+	if len(errs) == 0 && !req.start.Before(req.end) {
+		errs = append(errs, ValidationError{
+			Field:   "time_range",
+			Message: "开始时间必须小于结束时间",
+		})
+	}
+	if len(errs) == 0 && h.limits.MaxRange > 0 && req.end.Sub(req.start) > h.limits.MaxRange {
+		errs = append(errs, ValidationError{
+			Field:   "time_range",
+			Message: fmt.Sprintf("单次导出的时间范围不能超过%.0f小时，请分段导出", h.limits.MaxRange.Hours()),
+		})
+	}
+
+	switch req.format {
+	case ExportFormatCSV, ExportFormatNDJSON, ExportFormatParquet:
+	default:
+		errs = append(errs, ValidationError{
+			Field:   "format",
+			Message: "导出格式必须是以下值之一: csv, ndjson, parquet",
+			Value:   req.format,
+		})
+	}
+
+	if len(errs) > 0 {
+		ValidationErrorResponse(c, "参数验证失败", errs)
+		return nil, false
+	}
+	return req, true
+}
+
+// export 设置下载响应头并流式写出数据
+// 数据写出之前出错时返回错误响应；已经开始写出后无法再修改状态码，断开连接，
+// 客户端会收到不完整的分块响应而不是一个看似完整的文件
+func (h *ExportHandler) export(c *gin.Context, dataset string, req *exportRequest, writer exportWriter, stream func(ctx context.Context) error) {
+	if !h.acquire() {
+		TooManyRequestsResponse(c, "进行中的导出过多，请稍后重试", map[string]interface{}{
+			"max_concurrent": h.limits.MaxConcurrent,
+		})
+		return
+	}
+	defer h.release()
+
+	started := time.Now()
+	ctx := c.Request.Context()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", exportContentType(req.format))
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFileName(dataset, req)))
+	header.Set("Cache-Control", "no-store")
+
+	h.logger.Info("开始导出",
+		zap.String("dataset", dataset),
+		zap.String("symbol", req.symbol),
+		zap.String("interval", req.interval),
+		zap.Time("start", req.start),
+		zap.Time("end", req.end),
+		zap.String("format", req.format),
+	)
+
+	err := stream(ctx)
+	if err == nil {
+		err = writer.close()
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			h.logger.Info("客户端已断开，停止导出", zap.String("dataset", dataset), zap.String("symbol", req.symbol))
+			return
+		}
+		h.logger.Error("导出失败",
+			zap.String("dataset", dataset),
+			zap.String("symbol", req.symbol),
+			zap.Int64("rows", writer.rows()),
+			zap.Error(err),
+		)
+		if !c.Writer.Written() {
+			header.Del("Content-Type")
+			header.Del("Content-Disposition")
+			InternalErrorResponse(c, "导出失败", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		abortStream(c)
+		return
+	}
+
+	c.Writer.Flush()
+	h.logger.Info("导出完成",
+		zap.String("dataset", dataset),
+		zap.String("symbol", req.symbol),
+		zap.Int64("rows", writer.rows()),
+		zap.Duration("duration", time.Since(started)),
+	)
+}
+
+// acquire 占用一个导出名额，名额已满时立即返回 false 而不排队等待
+func (h *ExportHandler) acquire() bool {
+	if h.slots == nil {
+		return true
+	}
+	select {
+	case h.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *ExportHandler) release() {
+	if h.slots != nil {
+		<-h.slots
+	}
+}
+
+// abortStream 断开连接，放弃已写出一半的响应
+// gin 的 Hijack 在写出响应后会拒绝，这里直接劫持底层连接
+func abortStream(c *gin.Context) {
+	c.Abort()
+	var w http.ResponseWriter = c.Writer
+	if u, ok := w.(interface{ Unwrap() http.ResponseWriter }); ok {
+		w = u.Unwrap()
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
+
+// exportContentType 导出格式对应的 MIME 类型
+func exportContentType(format string) string {
+	switch format {
+	case ExportFormatNDJSON:
+		return "application/x-ndjson"
+	case ExportFormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// exportFileName 下载文件名，如 klines_BTCUSDT_1m_20240101T000000Z_20240301T000000Z.csv
+func exportFileName(dataset string, req *exportRequest) string {
+	parts := []string{dataset, req.symbol}
+	if req.interval != "" {
+		parts = append(parts, req.interval)
+	}
+	const layout = "20060102T150405Z"
+	parts = append(parts, req.start.UTC().Format(layout), req.end.UTC().Format(layout))
+	return strings.Join(parts, "_") + "." + req.format
+}
+
+// exportWriter 按导出格式写出数据
+type exportWriter interface {
+	close() error
+	rows() int64
+}
+
+// flushWriter 可以刷新的响应
+type flushWriter interface {
+	io.Writer
+	Flush()
+}
+
+// recordExportWriter 把一种数据按 CSV、NDJSON 或 Parquet 写出
+type recordExportWriter[M any, R any] struct {
+	out   flushWriter
+	count int64
+
+	csv     *csv.Writer
+	header  []string
+	values  func(*M) []string
+	json    *json.Encoder
+	parquet *archive.RecordWriter[R]
+	record  func(*M) (R, time.Time)
+}
+
+// write 写出一行，CSV 的表头在第一行之前写出
+func (w *recordExportWriter[M, R]) write(row *M) error {
+	var err error
+	switch {
+	case w.csv != nil:
+		if w.count == 0 {
+			err = w.csv.Write(w.header)
+		}
+		if err == nil {
+			err = w.csv.Write(w.values(row))
+		}
+	case w.json != nil:
+		err = w.json.Encode(row)
+	default:
+		record, ts := w.record(row)
+		err = w.parquet.Write(record, ts)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.count++
+	if w.count%exportFlushRows == 0 {
+		return w.flush()
+	}
+	return nil
+}
+
+// close 写出剩余数据（Parquet 的文件元数据），没有数据时 CSV 只有表头
+func (w *recordExportWriter[M, R]) close() error {
+	switch {
+	case w.csv != nil:
+		if w.count == 0 {
+			if err := w.csv.Write(w.header); err != nil {
+				return err
+			}
+		}
+	case w.parquet != nil:
+		if err := w.parquet.Close(); err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
+
+func (w *recordExportWriter[M, R]) rows() int64 {
+	return w.count
+}
+
+func (w *recordExportWriter[M, R]) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	w.out.Flush()
+	return nil
+}
+
+// newRecordExportWriter 按格式创建写出器
+func newRecordExportWriter[M any, R any](format string, out flushWriter, header []string, values func(*M) []string, record func(*M) (R, time.Time)) *recordExportWriter[M, R] {
+	w := &recordExportWriter[M, R]{out: out, header: header, values: values, record: record}
+	switch format {
+	case ExportFormatNDJSON:
+		w.json = json.NewEncoder(out)
+	case ExportFormatParquet:
+		w.parquet = archive.NewRecordWriter[R](out)
+	default:
+		w.csv = csv.NewWriter(out)
+	}
+	return w
+}
+
+// tickExportColumns 价格数据的 CSV 列
+var tickExportColumns = []string{
+	"symbol", "timestamp", "last_price", "ask_price", "bid_price", "bid_size", "ask_size",
+	"high_24h", "low_24h", "change_24h", "base_volume", "quote_volume", "usdt_volume",
+	"open_utc", "change_utc_24h", "index_price", "funding_rate", "holding_amount", "open_24h", "mark_price",
+	"delivery_start_time", "delivery_time", "delivery_status",
+}
+
+// klineExportColumns K线的 CSV 列
+var klineExportColumns = []string{
+	"symbol", "granularity", "timestamp", "open", "high", "low", "close", "base_volume", "quote_volume",
+}
+
+func newTickExportWriter(format string, out flushWriter) *recordExportWriter[models.PriceTick, archive.TickRecord] {
+	return newRecordExportWriter(format, out, tickExportColumns, tickCSVValues,
+		func(tick *models.PriceTick) (archive.TickRecord, time.Time) {
+			return archive.NewTickRecord(tick), tick.Timestamp
+		})
+}
+
+func newKlineExportWriter(format string, out flushWriter) *recordExportWriter[models.Kline, archive.KlineRecord] {
+	return newRecordExportWriter(format, out, klineExportColumns, klineCSVValues,
+		func(kline *models.Kline) (archive.KlineRecord, time.Time) {
+			return archive.NewKlineRecord(kline), kline.Timestamp
+		})
+}
+
+// tickCSVValues 价格数据的 CSV 行，与 Parquet 一样按字符串写出 decimal，空值为空字符串
+func tickCSVValues(tick *models.PriceTick) []string {
+	r := archive.NewTickRecord(tick)
+	return []string{
+		r.Symbol, exportTimestamp(r.Timestamp), r.LastPrice, optionalString(r.AskPrice), optionalString(r.BidPrice),
+		optionalString(r.BidSize), optionalString(r.AskSize), optionalString(r.High24h), optionalString(r.Low24h),
+		optionalFloat(r.Change24h), optionalString(r.BaseVolume), optionalString(r.QuoteVolume),
+		optionalString(r.UsdtVolume), optionalString(r.OpenUtc), optionalFloat(r.ChangeUtc24h),
+		optionalString(r.IndexPrice), optionalFloat(r.FundingRate), optionalString(r.HoldingAmount),
+		optionalString(r.Open24h), optionalString(r.MarkPrice), optionalInt(r.DeliveryStartTime),
+		optionalInt(r.DeliveryTime), r.DeliveryStatus,
+	}
+}
+
+// klineCSVValues K线的 CSV 行
+func klineCSVValues(kline *models.Kline) []string {
+	return []string{
+		kline.Symbol, kline.Granularity, exportTimestamp(kline.Timestamp),
+		kline.Open.String(), kline.High.String(), kline.Low.String(), kline.Close.String(),
+		kline.BaseVolume.String(), kline.QuoteVolume.String(),
+	}
+}
+
+// exportTimestamp 导出的时间统一为 UTC 的 RFC3339
+func exportTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func optionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optionalFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func optionalInt(i *int64) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.FormatInt(*i, 10)
+}
+
+// RegisterExportRoutes 注册数据导出路由，auth 为空时不校验
+func RegisterExportRoutes(router *gin.RouterGroup, source ExportSource, limits ExportLimits, auth gin.HandlerFunc, logger *zap.Logger) {
+	handler := NewExportHandler(source, limits, logger)
+
+	export := router.Group("/export")
+	if auth != nil {
+		export.Use(auth)
+	}
+	{
+		// 价格数据：?symbol=BTCUSDT&start_time=...&end_time=...&format=csv|ndjson|parquet
+		export.GET("/ticks", handler.ExportTicks)
+
+		// K线数据：另有 interval 参数（默认1m），只支持有连续聚合的周期
+		export.GET("/klines", IntervalValidator(), handler.ExportKlines)
+	}
+}
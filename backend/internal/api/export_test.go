@@ -0,0 +1,382 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/archive"
+	"github.com/haxrd/cryptosignal-hunter/internal/middleware"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// fakeExportSource 按顺序回调预设数据，可以在第 failAfter 行之后返回错误
+type fakeExportSource struct {
+	ticks     []*models.PriceTick
+	klines    []*models.Kline
+	failAfter int
+	err       error
+
+	symbol      string
+	granularity string
+	start       time.Time
+	end         time.Time
+}
+
+func (f *fakeExportSource) StreamTicks(ctx context.Context, symbol string, start, end time.Time, fn func(*models.PriceTick) error) error {
+	f.symbol, f.start, f.end = symbol, start, end
+	for i, tick := range f.ticks {
+		if f.err != nil && i == f.failAfter {
+			return f.err
+		}
+		if err := fn(tick); err != nil {
+			return err
+		}
+	}
+	if f.err != nil && f.failAfter >= len(f.ticks) {
+		return f.err
+	}
+	return nil
+}
+
+func (f *fakeExportSource) StreamAggregatedKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error {
+	f.symbol, f.granularity, f.start, f.end = symbol, granularity, start, end
+	for i, kline := range f.klines {
+		if f.err != nil && i == f.failAfter {
+			return f.err
+		}
+		if err := fn(kline); err != nil {
+			return err
+		}
+	}
+	if f.err != nil && f.failAfter >= len(f.klines) {
+		return f.err
+	}
+	return nil
+}
+
+// bytesFile 内存中的 Parquet 文件
+type bytesFile struct {
+	*bytes.Reader
+}
+
+func (f bytesFile) Close() error { return nil }
+
+var exportTestStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newExportTestSource() *fakeExportSource {
+	source := &fakeExportSource{}
+	for i := 0; i < 3; i++ {
+		ts := exportTestStart.Add(time.Duration(i) * time.Minute)
+		source.klines = append(source.klines, &models.Kline{
+			Symbol:      "BTCUSDT",
+			Granularity: "1m",
+			Timestamp:   ts,
+			Open:        decimal.RequireFromString("42000.1"),
+			High:        decimal.RequireFromString("42100.25"),
+			Low:         decimal.RequireFromString("41900"),
+			Close:       decimal.RequireFromString("42050.123456789"),
+			BaseVolume:  decimal.RequireFromString("12.5"),
+			QuoteVolume: decimal.RequireFromString("525000"),
+		})
+	}
+	change := 0.0123
+	source.ticks = append(source.ticks, &models.PriceTick{
+		Symbol:    "BTCUSDT",
+		Timestamp: exportTestStart,
+		LastPrice: decimal.RequireFromString("42000.5"),
+		BidPrice:  models.DecimalPtr(decimal.RequireFromString("42000.4")),
+		Change24h: &change,
+	})
+	return source
+}
+
+func setupExportTestRouter(source ExportSource) *gin.Engine {
+	return setupExportTestRouterWithConfig(&RouterConfig{ExportSource: source})
+}
+
+func setupExportTestRouterWithConfig(config *RouterConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	config.Logger = zap.NewNop()
+	RegisterAllRoutes(router.Group("/api/v1"), config)
+	return router
+}
+
+func doExportRequest(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExportAPI_Validation(t *testing.T) {
+	router := setupExportTestRouter(newExportTestSource())
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"缺少交易对", "start_time=1704067200&end_time=1704153600"},
+		{"交易对格式错误", "symbol=btc-usdt&start_time=1704067200&end_time=1704153600"},
+		{"缺少开始时间", "symbol=BTCUSDT&end_time=1704153600"},
+		{"时间格式错误", "symbol=BTCUSDT&start_time=yesterday&end_time=1704153600"},
+		{"开始时间不小于结束时间", "symbol=BTCUSDT&start_time=1704153600&end_time=1704067200"},
+		{"不支持的格式", "symbol=BTCUSDT&start_time=1704067200&end_time=1704153600&format=xlsx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doExportRequest(router, "/api/v1/export/ticks?"+tt.query)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Empty(t, w.Header().Get("Content-Disposition"))
+		})
+	}
+
+	w := doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600&interval=7m")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportAPI_KlinesCSV(t *testing.T) {
+	source := newExportTestSource()
+	router := setupExportTestRouter(source)
+
+	w := doExportRequest(router, "/api/v1/export/klines?symbol=btcusdt&interval=1m&start_time=1704067200&end_time=2024-03-01T00:00:00Z")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="klines_BTCUSDT_1m_20240101T000000Z_20240301T000000Z.csv"`, w.Header().Get("Content-Disposition"))
+
+	// 未设置 MaxRange 时不受查询接口30天范围的限制
+	assert.Equal(t, "BTCUSDT", source.symbol)
+	assert.Equal(t, "1m", source.granularity)
+	assert.True(t, exportTestStart.Equal(source.start))
+	assert.True(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Equal(source.end))
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, klineExportColumns, records[0])
+	assert.Equal(t, []string{
+		"BTCUSDT", "1m", "2024-01-01T00:00:00Z", "42000.1", "42100.25", "41900", "42050.123456789", "12.5", "525000",
+	}, records[1])
+	assert.Equal(t, "2024-01-01T00:02:00Z", records[3][2])
+}
+
+func TestExportAPI_KlinesAggregatedInterval(t *testing.T) {
+	source := newExportTestSource()
+	for _, kline := range source.klines {
+		kline.Granularity = "1h"
+	}
+	router := setupExportTestRouter(source)
+
+	// 1m 以外的周期同样从对应的连续聚合读取
+	w := doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&interval=1h&start_time=1704067200&end_time=1704153600")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1h", source.granularity)
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, "1h", records[1][1])
+
+	// 没有连续聚合的周期直接拒绝，而不是导出空文件
+	for _, interval := range []string{"30m", "1w"} {
+		source.granularity = ""
+		w := doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&interval="+interval+"&start_time=1704067200&end_time=1704153600")
+		assert.Equal(t, http.StatusBadRequest, w.Code, interval)
+		assert.Empty(t, w.Header().Get("Content-Disposition"), interval)
+		assert.Empty(t, source.granularity, interval)
+	}
+}
+
+func TestExportAPI_TicksCSV(t *testing.T) {
+	router := setupExportTestRouter(newExportTestSource())
+
+	w := doExportRequest(router, "/api/v1/export/ticks?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="ticks_BTCUSDT_20240101T000000Z_20240102T000000Z.csv"`, w.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Len(t, records[1], len(tickExportColumns))
+
+	row := make(map[string]string)
+	for i, column := range records[0] {
+		row[column] = records[1][i]
+	}
+	assert.Equal(t, "42000.5", row["last_price"])
+	assert.Equal(t, "42000.4", row["bid_price"])
+	assert.Equal(t, "", row["ask_price"])
+	assert.Equal(t, "0.0123", row["change_24h"])
+}
+
+func TestExportAPI_EmptyCSV(t *testing.T) {
+	router := setupExportTestRouter(&fakeExportSource{})
+
+	w := doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, strings.Join(klineExportColumns, ",")+"\n", w.Body.String())
+}
+
+func TestExportAPI_KlinesNDJSON(t *testing.T) {
+	router := setupExportTestRouter(newExportTestSource())
+
+	w := doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600&format=ndjson")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	var kline models.Kline
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &kline))
+	assert.Equal(t, "BTCUSDT", kline.Symbol)
+	assert.Equal(t, "42050.123456789", kline.Close.String())
+	assert.True(t, exportTestStart.Add(2*time.Minute).Equal(kline.Timestamp))
+}
+
+func TestExportAPI_KlinesParquet(t *testing.T) {
+	router := setupExportTestRouter(newExportTestSource())
+
+	w := doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600&format=parquet")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/vnd.apache.parquet", w.Header().Get("Content-Type"))
+
+	var klines []*models.Kline
+	err := archive.ReadRecords(bytesFile{bytes.NewReader(w.Body.Bytes())}, func(record *archive.KlineRecord) error {
+		kline, err := record.ToModel()
+		if err != nil {
+			return err
+		}
+		klines = append(klines, kline)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, klines, 3)
+	assert.Equal(t, "42100.25", klines[0].High.String())
+	assert.True(t, exportTestStart.Equal(klines[0].Timestamp))
+}
+
+func TestExportAPI_SourceError(t *testing.T) {
+	source := newExportTestSource()
+	source.err = errors.New("connection refused")
+	router := setupExportTestRouter(source)
+
+	// 写出任何数据之前出错，返回错误响应
+	w := doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
+
+func TestExportAPI_AbortMidStream(t *testing.T) {
+	source := newExportTestSource()
+	for i := 0; i < exportFlushRows; i++ {
+		source.klines = append(source.klines, source.klines[0])
+	}
+	source.failAfter = exportFlushRows + 1
+	source.err = errors.New("connection reset")
+
+	server := httptest.NewServer(setupExportTestRouter(source))
+	defer server.Close()
+
+	// 已经开始写出后出错，连接被断开，客户端读不到完整的响应
+	resp, err := http.Get(server.URL + "/api/v1/export/klines?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = io.ReadAll(resp.Body)
+	assert.Error(t, err)
+}
+
+func TestExportAPI_MaxRange(t *testing.T) {
+	source := newExportTestSource()
+	router := setupExportTestRouterWithConfig(&RouterConfig{
+		ExportSource: source,
+		ExportLimits: ExportLimits{MaxRange: 31 * 24 * time.Hour},
+	})
+
+	w := doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&start_time=2024-01-01T00:00:00Z&end_time=2024-03-01T00:00:00Z")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "time_range")
+	assert.True(t, source.start.IsZero(), "超出范围时不应读取数据源")
+
+	w = doExportRequest(router, "/api/v1/export/klines?symbol=BTCUSDT&start_time=2024-01-01T00:00:00Z&end_time=2024-02-01T00:00:00Z")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// blockingExportSource 在 release 关闭之前阻塞导出，模拟长时间的下载
+type blockingExportSource struct {
+	*fakeExportSource
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingExportSource) StreamAggregatedKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error {
+	b.started <- struct{}{}
+	<-b.release
+	return b.fakeExportSource.StreamAggregatedKlines(ctx, symbol, granularity, start, end, fn)
+}
+
+func TestExportAPI_MaxConcurrent(t *testing.T) {
+	source := &blockingExportSource{
+		fakeExportSource: newExportTestSource(),
+		started:          make(chan struct{}, 1),
+		release:          make(chan struct{}),
+	}
+	router := setupExportTestRouterWithConfig(&RouterConfig{
+		ExportSource: source,
+		ExportLimits: ExportLimits{MaxConcurrent: 1},
+	})
+	const path = "/api/v1/export/klines?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600"
+
+	first := make(chan int)
+	go func() {
+		first <- doExportRequest(router, path).Code
+	}()
+	<-source.started
+
+	// 名额已满时立即拒绝，不排队占用连接
+	w := doExportRequest(router, path)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+
+	close(source.release)
+	assert.Equal(t, http.StatusOK, <-first)
+
+	// 导出结束后释放名额
+	go func() { <-source.started }()
+	w = doExportRequest(router, path)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestExportAPI_Auth(t *testing.T) {
+	router := setupExportTestRouterWithConfig(&RouterConfig{
+		ExportSource: newExportTestSource(),
+		ExportAuth:   middleware.RequirePermission(&fakePermissionChecker{adminToken: "admin-token"}, "admin"),
+	})
+	const path = "/api/v1/export/ticks?symbol=BTCUSDT&start_time=1704067200&end_time=1704153600"
+
+	w := doExportRequest(router, path)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
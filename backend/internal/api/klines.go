@@ -3,14 +3,17 @@ package api
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
 
@@ -49,11 +52,15 @@ func (h *KlineHandler) GetKlines(c *gin.Context) {
 		zap.Int("page_size", pageSize),
 	)
 
+	offset, ok := klineQueryOffset(c, page, pageSize)
+	if !ok {
+		return
+	}
+
 	// 查询K线数据
 	startTimeObj := time.Unix(startTime, 0)
 	endTimeObj := time.Unix(endTime, 0)
-	offset := (page - 1) * pageSize
-	klines, err := h.klineDAO.GetByRange(ctx, symbol, interval, startTimeObj, endTimeObj, pageSize, offset)
+	klines, err := queryKlinesByRange(ctx, h.klineDAO, h.logger, symbol, interval, startTimeObj, endTimeObj, pageSize, offset)
 	total := int64(len(klines)) // 简化处理，实际应该查询总数
 	if err != nil {
 		h.logger.Error("获取K线数据失败",
@@ -99,7 +106,7 @@ func (h *KlineHandler) GetKlineStatistics(c *gin.Context) {
 	// 查询统计信息（简化实现）
 	startTimeObj := time.Unix(startTime, 0)
 	endTimeObj := time.Unix(endTime, 0)
-	klines, err := h.klineDAO.GetByRange(ctx, symbol, interval, startTimeObj, endTimeObj, 1000, 0)
+	klines, err := queryKlinesByRange(ctx, h.klineDAO, h.logger, symbol, interval, startTimeObj, endTimeObj, klineStatisticsLimit, 0)
 	if err != nil {
 		h.logger.Error("获取K线统计信息失败",
 			zap.String("symbol", symbol),
@@ -123,14 +130,14 @@ func (h *KlineHandler) GetKlineStatistics(c *gin.Context) {
 		"end_time":      endTime,
 		"total_records": stats.TotalRecords,
 		"price_range": map[string]interface{}{
-			"highest": stats.HighestPrice,
-			"lowest":  stats.LowestPrice,
-			"average": stats.AveragePrice,
+			"highest": models.JSONDecimal(stats.HighestPrice),
+			"lowest":  models.JSONDecimal(stats.LowestPrice),
+			"average": models.JSONDecimal(stats.AveragePrice),
 		},
 		"volume_stats": map[string]interface{}{
-			"total":   stats.TotalVolume,
-			"average": stats.AverageVolume,
-			"highest": stats.HighestVolume,
+			"total":   models.JSONDecimal(stats.TotalVolume),
+			"average": models.JSONDecimal(stats.AverageVolume),
+			"highest": models.JSONDecimal(stats.HighestVolume),
 		},
 		"time_range": map[string]interface{}{
 			"start":            time.Unix(startTime, 0).Format(time.RFC3339),
@@ -163,7 +170,7 @@ func (h *KlineHandler) GetKlineLatest(c *gin.Context) {
 	)
 
 	// 查询最新K线数据
-	klines, err := h.klineDAO.GetLatest(ctx, symbol, interval, limit)
+	klines, err := queryLatestKlines(ctx, h.klineDAO, h.logger, symbol, interval, limit)
 	if err != nil {
 		h.logger.Error("获取最新K线数据失败",
 			zap.String("symbol", symbol),
@@ -214,7 +221,7 @@ func (h *KlineHandler) GetKlineByTime(c *gin.Context) {
 	timeObj := time.Unix(timestamp, 0)
 	startTime := timeObj.Add(-time.Minute)
 	endTime := timeObj.Add(time.Minute)
-	klines, err := h.klineDAO.GetByRange(ctx, symbol, interval, startTime, endTime, 1, 0)
+	klines, err := queryKlinesByRange(ctx, h.klineDAO, h.logger, symbol, interval, startTime, endTime, 1, 0)
 	if err != nil {
 		h.logger.Error("根据时间获取K线数据失败",
 			zap.String("symbol", symbol),
@@ -256,19 +263,174 @@ func (h *KlineHandler) GetKlineByTime(c *gin.Context) {
 
 // 辅助方法
 
+// klineQueryMaxLimit DAO 单次查询K线的最大条数
+const klineQueryMaxLimit = 200
+
+// klineStatisticsLimit 统计信息读取的最近K线条数
+const klineStatisticsLimit = 1000
+
+// klineQueryMaxOffset 按时间范围分页查询的最大偏移量。合并两个来源需要读取前 offset+limit 条，
+// 更早的数据应通过缩小 end_time 查询
+const klineQueryMaxOffset = 5000
+
+// klineQueryOffset 计算分页偏移量，超过 klineQueryMaxOffset 时返回验证错误
+func klineQueryOffset(c *gin.Context, page, pageSize int) (int, bool) {
+	offset := (page - 1) * pageSize
+	if offset > klineQueryMaxOffset {
+		ValidationErrorResponse(c, "分页参数验证失败", ValidationError{
+			Field:   "page",
+			Message: fmt.Sprintf("分页偏移量不能超过%d，请缩小时间范围查询更早的数据", klineQueryMaxOffset),
+			Value:   strconv.Itoa(page),
+		})
+		return 0, false
+	}
+	return offset, true
+}
+
+// queryKlinesByRange 按时间范围查询K线：交易所K线优先，缺失的时间点由行情聚合生成的连续聚合K线补齐，
+// 合并后按时间倒序分页
+func queryKlinesByRange(ctx context.Context, klineDAO dao.KlineDAO, logger *zap.Logger, symbol, interval string, startTime, endTime time.Time, limit, offset int) ([]*models.Kline, error) {
+	if offset > klineQueryMaxOffset {
+		return nil, database.NewDatabaseError(
+			fmt.Sprintf("offset %d exceeds maximum %d", offset, klineQueryMaxOffset),
+			database.ErrInvalidInput,
+		)
+	}
+
+	// 无效的分页参数直接交给 DAO（由 DAO 校验参数）
+	if limit <= 0 || offset < 0 {
+		return klineDAO.GetByRange(ctx, symbol, interval, startTime, endTime, limit, offset)
+	}
+
+	// 没有连续聚合的周期只查交易所K线，超过 DAO 单次上限的 limit 分批查询
+	if _, ok := dao.KlineAggregateView(interval); !ok {
+		return fetchKlines(limit, func(batch, skip int) ([]*models.Kline, error) {
+			return klineDAO.GetByRange(ctx, symbol, interval, startTime, endTime, batch, offset+skip)
+		})
+	}
+
+	// 合并结果的前 offset+limit 条一定在两个来源各自的前 offset+limit 条中
+	count := offset + limit
+	klines, err := fetchKlines(count, func(batch, skip int) ([]*models.Kline, error) {
+		return klineDAO.GetByRange(ctx, symbol, interval, startTime, endTime, batch, skip)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	aggregated, err := fetchKlines(count, func(batch, skip int) ([]*models.Kline, error) {
+		return klineDAO.GetAggregatedByRange(ctx, symbol, interval, startTime, endTime, batch, skip)
+	})
+	if err != nil {
+		// 连续聚合不可用（如迁移未执行）时只返回交易所K线
+		logger.Warn("查询聚合K线失败",
+			zap.String("symbol", symbol),
+			zap.String("interval", interval),
+			zap.Error(err),
+		)
+		return pageKlines(klines, limit, offset), nil
+	}
+
+	merged := mergeAggregatedKlines(klines, aggregated)
+	if filled := len(merged) - len(klines); filled > 0 {
+		logger.Debug("交易所K线缺失，使用聚合K线补齐",
+			zap.String("symbol", symbol),
+			zap.String("interval", interval),
+			zap.Int("filled", filled),
+		)
+	}
+	return pageKlines(merged, limit, offset), nil
+}
+
+// queryLatestKlines 查询最新K线，交易所K线缺失的时间点由连续聚合K线补齐
+func queryLatestKlines(ctx context.Context, klineDAO dao.KlineDAO, logger *zap.Logger, symbol, interval string, limit int) ([]*models.Kline, error) {
+	klines, err := klineDAO.GetLatest(ctx, symbol, interval, limit)
+	if err != nil {
+		return klines, err
+	}
+	if _, ok := dao.KlineAggregateView(interval); !ok {
+		return klines, nil
+	}
+
+	aggregated, err := klineDAO.GetAggregatedLatest(ctx, symbol, interval, limit)
+	if err != nil {
+		logger.Warn("查询最新聚合K线失败",
+			zap.String("symbol", symbol),
+			zap.String("interval", interval),
+			zap.Error(err),
+		)
+		return klines, nil
+	}
+	return pageKlines(mergeAggregatedKlines(klines, aggregated), limit, 0), nil
+}
+
+// fetchKlines 按 DAO 的最大条数分批查询，直到取得 count 条或没有更多数据
+func fetchKlines(count int, fetch func(limit, offset int) ([]*models.Kline, error)) ([]*models.Kline, error) {
+	result := make([]*models.Kline, 0, count)
+	for len(result) < count {
+		batch := min(count-len(result), klineQueryMaxLimit)
+		page, err := fetch(batch, len(result))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, page...)
+		if len(page) < batch {
+			break
+		}
+	}
+	return result, nil
+}
+
+// mergeAggregatedKlines 用聚合K线补齐交易所K线缺失的时间点，同一时间点保留交易所K线，结果按时间倒序
+func mergeAggregatedKlines(klines, aggregated []*models.Kline) []*models.Kline {
+	if len(aggregated) == 0 {
+		return klines
+	}
+
+	seen := make(map[int64]bool, len(klines))
+	merged := make([]*models.Kline, 0, len(klines)+len(aggregated))
+	for _, kline := range klines {
+		seen[kline.Timestamp.UnixNano()] = true
+		merged = append(merged, kline)
+	}
+	for _, kline := range aggregated {
+		if !seen[kline.Timestamp.UnixNano()] {
+			merged = append(merged, kline)
+		}
+	}
+
+	slices.SortFunc(merged, func(a, b *models.Kline) int {
+		return b.Timestamp.Compare(a.Timestamp)
+	})
+	return merged
+}
+
+// pageKlines 返回按 offset 和 limit 截取的一页
+func pageKlines(klines []*models.Kline, limit, offset int) []*models.Kline {
+	if offset >= len(klines) {
+		return []*models.Kline{}
+	}
+	return klines[offset:min(offset+limit, len(klines))]
+}
+
 // KlineStatistics K线统计信息
 type KlineStatistics struct {
-	TotalRecords  int64   `json:"total_records"`
-	HighestPrice  float64 `json:"highest_price"`
-	LowestPrice   float64 `json:"lowest_price"`
-	AveragePrice  float64 `json:"average_price"`
-	TotalVolume   float64 `json:"total_volume"`
-	AverageVolume float64 `json:"average_volume"`
-	HighestVolume float64 `json:"highest_volume"`
+	TotalRecords  int64           `json:"total_records"`
+	HighestPrice  decimal.Decimal `json:"highest_price"`
+	LowestPrice   decimal.Decimal `json:"lowest_price"`
+	AveragePrice  decimal.Decimal `json:"average_price"`
+	TotalVolume   decimal.Decimal `json:"total_volume"`
+	AverageVolume decimal.Decimal `json:"average_volume"`
+	HighestVolume decimal.Decimal `json:"highest_volume"`
 }
 
 // calculateStatistics 计算K线统计信息
 func (h *KlineHandler) calculateStatistics(klines []*models.Kline) *KlineStatistics {
+	return calculateKlineStatistics(klines)
+}
+
+// calculateKlineStatistics 计算K线统计信息，价格和成交量使用精确的十进制运算
+func calculateKlineStatistics(klines []*models.Kline) *KlineStatistics {
 	if len(klines) == 0 {
 		return &KlineStatistics{}
 	}
@@ -277,33 +439,32 @@ func (h *KlineHandler) calculateStatistics(klines []*models.Kline) *KlineStatist
 		TotalRecords:  int64(len(klines)),
 		HighestPrice:  klines[0].High,
 		LowestPrice:   klines[0].Low,
-		TotalVolume:   klines[0].BaseVolume,
 		HighestVolume: klines[0].BaseVolume,
 	}
 
-	totalPrice := 0.0
-	totalVolume := 0.0
+	two := decimal.NewFromInt(2)
+	totalPrice := decimal.Zero
 
 	for _, kline := range klines {
 		// 价格统计
-		if kline.High > stats.HighestPrice {
+		if kline.High.GreaterThan(stats.HighestPrice) {
 			stats.HighestPrice = kline.High
 		}
-		if kline.Low < stats.LowestPrice {
+		if kline.Low.LessThan(stats.LowestPrice) {
 			stats.LowestPrice = kline.Low
 		}
-		totalPrice += (kline.Open + kline.Close) / 2
+		totalPrice = totalPrice.Add(kline.Open.Add(kline.Close).Div(two))
 
 		// 成交量统计
-		stats.TotalVolume += kline.BaseVolume
-		if kline.BaseVolume > stats.HighestVolume {
+		stats.TotalVolume = stats.TotalVolume.Add(kline.BaseVolume)
+		if kline.BaseVolume.GreaterThan(stats.HighestVolume) {
 			stats.HighestVolume = kline.BaseVolume
 		}
-		totalVolume += kline.BaseVolume
 	}
 
-	stats.AveragePrice = totalPrice / float64(len(klines))
-	stats.AverageVolume = totalVolume / float64(len(klines))
+	count := decimal.NewFromInt(int64(len(klines)))
+	stats.AveragePrice = totalPrice.Div(count)
+	stats.AverageVolume = stats.TotalVolume.Div(count)
 
 	return stats
 }
@@ -314,12 +475,12 @@ func (h *KlineHandler) klineToMap(kline *models.Kline) map[string]interface{} {
 		"symbol":       kline.Symbol,
 		"interval":     kline.Granularity,
 		"timestamp":    kline.Timestamp.Unix(),
-		"open":         kline.Open,
-		"high":         kline.High,
-		"low":          kline.Low,
-		"close":        kline.Close,
-		"base_volume":  kline.BaseVolume,
-		"quote_volume": kline.QuoteVolume,
+		"open":         models.JSONDecimal(kline.Open),
+		"high":         models.JSONDecimal(kline.High),
+		"low":          models.JSONDecimal(kline.Low),
+		"close":        models.JSONDecimal(kline.Close),
+		"base_volume":  models.JSONDecimal(kline.BaseVolume),
+		"quote_volume": models.JSONDecimal(kline.QuoteVolume),
 		"created_at":   kline.CreatedAt.Unix(),
 	}
 }
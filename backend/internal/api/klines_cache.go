@@ -75,10 +75,13 @@ func (h *KlineCacheHandler) GetKlinesWithCache(c *gin.Context) {
 	}
 
 	// 缓存未命中，从数据库查询
+	offset, ok := klineQueryOffset(c, page, pageSize)
+	if !ok {
+		return
+	}
 	startTimeObj := time.Unix(startTime, 0)
 	endTimeObj := time.Unix(endTime, 0)
-	offset := (page - 1) * pageSize
-	klines, err := h.klineDAO.GetByRange(ctx, symbol, interval, startTimeObj, endTimeObj, pageSize, offset)
+	klines, err := queryKlinesByRange(ctx, h.klineDAO, h.logger, symbol, interval, startTimeObj, endTimeObj, pageSize, offset)
 	total := int64(len(klines)) // 简化处理
 	if err != nil {
 		h.logger.Error("获取K线数据失败",
@@ -162,7 +165,7 @@ func (h *KlineCacheHandler) GetKlineStatisticsWithCache(c *gin.Context) {
 	// 缓存未命中，从数据库查询
 	startTimeObj := time.Unix(startTime, 0)
 	endTimeObj := time.Unix(endTime, 0)
-	klines, err := h.klineDAO.GetByRange(ctx, symbol, interval, startTimeObj, endTimeObj, 1000, 0)
+	klines, err := queryKlinesByRange(ctx, h.klineDAO, h.logger, symbol, interval, startTimeObj, endTimeObj, klineStatisticsLimit, 0)
 	if err != nil {
 		h.logger.Error("获取K线统计信息失败",
 			zap.String("symbol", symbol),
@@ -186,14 +189,14 @@ func (h *KlineCacheHandler) GetKlineStatisticsWithCache(c *gin.Context) {
 		"end_time":      endTime,
 		"total_records": stats.TotalRecords,
 		"price_range": map[string]interface{}{
-			"highest": stats.HighestPrice,
-			"lowest":  stats.LowestPrice,
-			"average": stats.AveragePrice,
+			"highest": models.JSONDecimal(stats.HighestPrice),
+			"lowest":  models.JSONDecimal(stats.LowestPrice),
+			"average": models.JSONDecimal(stats.AveragePrice),
 		},
 		"volume_stats": map[string]interface{}{
-			"total":   stats.TotalVolume,
-			"average": stats.AverageVolume,
-			"highest": stats.HighestVolume,
+			"total":   models.JSONDecimal(stats.TotalVolume),
+			"average": models.JSONDecimal(stats.AverageVolume),
+			"highest": models.JSONDecimal(stats.HighestVolume),
 		},
 		"time_range": map[string]interface{}{
 			"start":            time.Unix(startTime, 0).Format(time.RFC3339),
@@ -263,7 +266,7 @@ func (h *KlineCacheHandler) GetLatestKlinesWithCache(c *gin.Context) {
 	}
 
 	// 缓存未命中，从数据库查询
-	klines, err := h.klineDAO.GetLatest(ctx, symbol, interval, limit)
+	klines, err := queryLatestKlines(ctx, h.klineDAO, h.logger, symbol, interval, limit)
 	if err != nil {
 		h.logger.Error("获取最新K线数据失败",
 			zap.String("symbol", symbol),
@@ -351,43 +354,7 @@ func (h *KlineCacheHandler) InvalidateKlineCache(c *gin.Context) {
 
 // calculateStatistics 计算K线统计信息
 func (h *KlineCacheHandler) calculateStatistics(klines []*models.Kline) *KlineStatistics {
-	if len(klines) == 0 {
-		return &KlineStatistics{}
-	}
-
-	stats := &KlineStatistics{
-		TotalRecords:  int64(len(klines)),
-		HighestPrice:  klines[0].High,
-		LowestPrice:   klines[0].Low,
-		TotalVolume:   klines[0].BaseVolume,
-		HighestVolume: klines[0].BaseVolume,
-	}
-
-	totalPrice := 0.0
-	totalVolume := 0.0
-
-	for _, kline := range klines {
-		// 价格统计
-		if kline.High > stats.HighestPrice {
-			stats.HighestPrice = kline.High
-		}
-		if kline.Low < stats.LowestPrice {
-			stats.LowestPrice = kline.Low
-		}
-		totalPrice += (kline.Open + kline.Close) / 2
-
-		// 成交量统计
-		stats.TotalVolume += kline.BaseVolume
-		if kline.BaseVolume > stats.HighestVolume {
-			stats.HighestVolume = kline.BaseVolume
-		}
-		totalVolume += kline.BaseVolume
-	}
-
-	stats.AveragePrice = totalPrice / float64(len(klines))
-	stats.AverageVolume = totalVolume / float64(len(klines))
-
-	return stats
+	return calculateKlineStatistics(klines)
 }
 
 // generateKlineCacheKey 生成K线数据缓存键
@@ -459,12 +426,12 @@ func (h *KlineCacheHandler) klineToMap(kline *models.Kline) map[string]interface
 		"symbol":       kline.Symbol,
 		"interval":     kline.Granularity,
 		"timestamp":    kline.Timestamp.Unix(),
-		"open":         kline.Open,
-		"high":         kline.High,
-		"low":          kline.Low,
-		"close":        kline.Close,
-		"base_volume":  kline.BaseVolume,
-		"quote_volume": kline.QuoteVolume,
+		"open":         models.JSONDecimal(kline.Open),
+		"high":         models.JSONDecimal(kline.High),
+		"low":          models.JSONDecimal(kline.Low),
+		"close":        models.JSONDecimal(kline.Close),
+		"base_volume":  models.JSONDecimal(kline.BaseVolume),
+		"quote_volume": models.JSONDecimal(kline.QuoteVolume),
 		"created_at":   kline.CreatedAt.Unix(),
 	}
 }
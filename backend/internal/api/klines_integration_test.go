@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -395,12 +396,12 @@ func createLargeKlineData(t *testing.T, db *gorm.DB) {
 					Symbol:      symbol,
 					Granularity: interval,
 					Timestamp:   now.Add(-time.Duration(i) * time.Minute),
-					Open:        50000.0 + float64(i),
-					High:        50100.0 + float64(i),
-					Low:         49900.0 + float64(i),
-					Close:       50050.0 + float64(i),
-					BaseVolume:  100.0 + float64(i),
-					QuoteVolume: (50050.0 + float64(i)) * (100.0 + float64(i)),
+					Open:        decimal.NewFromInt(int64(50000 + i)),
+					High:        decimal.NewFromInt(int64(50100 + i)),
+					Low:         decimal.NewFromInt(int64(49900 + i)),
+					Close:       decimal.NewFromInt(int64(50050 + i)),
+					BaseVolume:  decimal.NewFromInt(int64(100 + i)),
+					QuoteVolume: decimal.NewFromInt(int64((50050 + i) * (100 + i))),
 					CreatedAt:   now,
 				}
 
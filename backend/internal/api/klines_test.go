@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -9,12 +10,14 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
 
@@ -38,48 +41,48 @@ func createTestKlines(t *testing.T, db *gorm.DB) []*models.Kline {
 			Symbol:      "BTCUSDT",
 			Granularity: "1m",
 			Timestamp:   now.Add(-5 * time.Minute),
-			Open:        50000.0,
-			High:        50100.0,
-			Low:         49900.0,
-			Close:       50050.0,
-			BaseVolume:  100.5,
-			QuoteVolume: 5025000.0,
+			Open:        decimal.NewFromInt(50000),
+			High:        decimal.NewFromInt(50100),
+			Low:         decimal.NewFromInt(49900),
+			Close:       decimal.NewFromInt(50050),
+			BaseVolume:  decimal.RequireFromString("100.5"),
+			QuoteVolume: decimal.NewFromInt(5025000),
 			CreatedAt:   now,
 		},
 		{
 			Symbol:      "BTCUSDT",
 			Granularity: "1m",
 			Timestamp:   now.Add(-4 * time.Minute),
-			Open:        50050.0,
-			High:        50200.0,
-			Low:         50000.0,
-			Close:       50150.0,
-			BaseVolume:  150.2,
-			QuoteVolume: 7530000.0,
+			Open:        decimal.NewFromInt(50050),
+			High:        decimal.NewFromInt(50200),
+			Low:         decimal.NewFromInt(50000),
+			Close:       decimal.NewFromInt(50150),
+			BaseVolume:  decimal.RequireFromString("150.2"),
+			QuoteVolume: decimal.NewFromInt(7530000),
 			CreatedAt:   now,
 		},
 		{
 			Symbol:      "BTCUSDT",
 			Granularity: "5m",
 			Timestamp:   now.Add(-10 * time.Minute),
-			Open:        49900.0,
-			High:        50200.0,
-			Low:         49800.0,
-			Close:       50000.0,
-			BaseVolume:  500.8,
-			QuoteVolume: 25040000.0,
+			Open:        decimal.NewFromInt(49900),
+			High:        decimal.NewFromInt(50200),
+			Low:         decimal.NewFromInt(49800),
+			Close:       decimal.NewFromInt(50000),
+			BaseVolume:  decimal.RequireFromString("500.8"),
+			QuoteVolume: decimal.NewFromInt(25040000),
 			CreatedAt:   now,
 		},
 		{
 			Symbol:      "ETHUSDT",
 			Granularity: "1m",
 			Timestamp:   now.Add(-3 * time.Minute),
-			Open:        3000.0,
-			High:        3050.0,
-			Low:         2980.0,
-			Close:       3020.0,
-			BaseVolume:  200.3,
-			QuoteVolume: 604906.0,
+			Open:        decimal.NewFromInt(3000),
+			High:        decimal.NewFromInt(3050),
+			Low:         decimal.NewFromInt(2980),
+			Close:       decimal.NewFromInt(3020),
+			BaseVolume:  decimal.RequireFromString("200.3"),
+			QuoteVolume: decimal.NewFromInt(604906),
 			CreatedAt:   now,
 		},
 	}
@@ -536,3 +539,170 @@ func TestKlinesAPI_PerformanceBasic(t *testing.T) {
 		assert.Less(t, duration, 100*time.Millisecond, "API响应时间应该小于100ms")
 	})
 }
+
+// TestKlinesAPI_AggregateFallback 测试交易所K线缺失时回退到连续聚合K线
+func TestKlinesAPI_AggregateFallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupKlineTestDB(t)
+	createTestKlines(t, db)
+
+	// SQLite 中用同结构的表模拟 klines_1h 连续聚合视图
+	hour := time.Now().UTC().Truncate(time.Hour)
+	require.NoError(t, db.Exec("CREATE TABLE klines_1h (symbol TEXT, timestamp DATETIME, open NUMERIC, high NUMERIC, low NUMERIC, close NUMERIC, base_volume NUMERIC, quote_volume NUMERIC)").Error)
+	require.NoError(t, db.Exec("INSERT INTO klines_1h VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		"BTCUSDT", hour, "49000", "50500", "48800", "50200", "1200.5", "60000000").Error)
+
+	handler := NewKlineHandler(dao.NewKlineDAO(db, zap.NewNop()), zap.NewNop())
+	router := gin.New()
+	router.GET("/klines/:symbol/latest", SymbolValidator(), IntervalValidator(), handler.GetKlineLatest)
+
+	getLatest := func(t *testing.T, interval string) []interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/klines/BTCUSDT/latest?interval="+interval, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Success)
+		return response.Data.([]interface{})
+	}
+
+	t.Run("优先返回交易所K线", func(t *testing.T) {
+		data := getLatest(t, "1m")
+		require.Len(t, data, 1)
+		assert.Equal(t, 50050.0, data[0].(map[string]interface{})["open"])
+	})
+
+	t.Run("交易所K线缺失时使用聚合K线", func(t *testing.T) {
+		data := getLatest(t, "1h")
+		require.Len(t, data, 1)
+		kline := data[0].(map[string]interface{})
+		assert.Equal(t, "1h", kline["interval"])
+		assert.Equal(t, 49000.0, kline["open"])
+		assert.Equal(t, 1200.5, kline["base_volume"])
+		assert.Equal(t, float64(hour.Unix()), kline["timestamp"])
+	})
+
+	t.Run("聚合不可用时返回空结果", func(t *testing.T) {
+		data := getLatest(t, "4h")
+		assert.Empty(t, data)
+	})
+}
+
+// createTestKlineGap 创建缺少中间一小时的1h交易所K线和对应的连续聚合，返回最近的整点
+func createTestKlineGap(t *testing.T, db *gorm.DB) time.Time {
+	hour := time.Now().UTC().Truncate(time.Hour)
+
+	// 交易所K线缺少中间一小时
+	for _, ts := range []time.Time{hour.Add(-2 * time.Hour), hour} {
+		require.NoError(t, db.Create(&models.Kline{
+			Symbol:      "BTCUSDT",
+			Granularity: "1h",
+			Timestamp:   ts,
+			Open:        decimal.NewFromInt(50000),
+			High:        decimal.NewFromInt(50100),
+			Low:         decimal.NewFromInt(49900),
+			Close:       decimal.NewFromInt(50050),
+			BaseVolume:  decimal.NewFromInt(10),
+			QuoteVolume: decimal.NewFromInt(500000),
+		}).Error)
+	}
+	require.NoError(t, db.Exec("CREATE TABLE klines_1h (symbol TEXT, timestamp DATETIME, open NUMERIC, high NUMERIC, low NUMERIC, close NUMERIC, base_volume NUMERIC, quote_volume NUMERIC)").Error)
+	for _, ts := range []time.Time{hour.Add(-2 * time.Hour), hour.Add(-time.Hour)} {
+		require.NoError(t, db.Exec("INSERT INTO klines_1h VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			"BTCUSDT", ts, "49000", "50500", "48800", "50200", "12", "600000").Error)
+	}
+	return hour
+}
+
+// TestQueryKlinesByRange_MergeAggregates 测试聚合K线补齐交易所K线缺失的时间点
+func TestQueryKlinesByRange_MergeAggregates(t *testing.T) {
+	db := setupKlineTestDB(t)
+	hour := createTestKlineGap(t, db)
+
+	klineDAO := dao.NewKlineDAO(db, zap.NewNop())
+	ctx := context.Background()
+	start, end := hour.Add(-3*time.Hour), hour.Add(time.Hour)
+
+	klines, err := queryKlinesByRange(ctx, klineDAO, zap.NewNop(), "BTCUSDT", "1h", start, end, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, klines, 3)
+	assert.True(t, hour.Equal(klines[0].Timestamp))
+	assert.True(t, hour.Add(-time.Hour).Equal(klines[1].Timestamp))
+	assert.True(t, hour.Add(-2*time.Hour).Equal(klines[2].Timestamp))
+	// 同一时间点保留交易所K线
+	assert.True(t, decimal.NewFromInt(50000).Equal(klines[0].Open))
+	assert.True(t, decimal.NewFromInt(49000).Equal(klines[1].Open))
+	assert.True(t, decimal.NewFromInt(50000).Equal(klines[2].Open))
+
+	// 分页作用于合并后的结果
+	klines, err = queryKlinesByRange(ctx, klineDAO, zap.NewNop(), "BTCUSDT", "1h", start, end, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, klines, 2)
+	assert.True(t, hour.Add(-time.Hour).Equal(klines[0].Timestamp))
+	assert.True(t, hour.Add(-2*time.Hour).Equal(klines[1].Timestamp))
+
+	klines, err = queryKlinesByRange(ctx, klineDAO, zap.NewNop(), "BTCUSDT", "1h", start, end, 2, 3)
+	require.NoError(t, err)
+	assert.Empty(t, klines)
+
+	// 超过最大偏移量时不再读取两个来源
+	_, err = queryKlinesByRange(ctx, klineDAO, zap.NewNop(), "BTCUSDT", "1h", start, end, 2, klineQueryMaxOffset+1)
+	assert.Error(t, err)
+
+	latest, err := queryLatestKlines(ctx, klineDAO, zap.NewNop(), "BTCUSDT", "1h", 2)
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	assert.True(t, hour.Add(-time.Hour).Equal(latest[1].Timestamp))
+}
+
+// TestKlinesAPI_OffsetLimit 测试分页偏移量上限
+func TestKlinesAPI_OffsetLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupKlineTestDB(t)
+	createTestKlines(t, db)
+
+	router := gin.New()
+	RegisterKlineRoutes(router.Group("/api/v1"), dao.NewKlineDAO(db, zap.NewNop()), zap.NewNop())
+
+	now := time.Now()
+	query := "/api/v1/klines/BTCUSDT?interval=1m&start_time=" + strconv.FormatInt(now.Add(-time.Hour).Unix(), 10) +
+		"&end_time=" + strconv.FormatInt(now.Unix(), 10) + "&page_size=100&page="
+
+	lastPage := klineQueryMaxOffset/100 + 1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", query+strconv.Itoa(lastPage), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", query+strconv.Itoa(lastPage+1), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Message, "分页参数验证失败")
+}
+
+// TestKlinesAPI_StatisticsFillsAggregates 测试统计信息超过 DAO 单次上限分批读取，并包含聚合K线
+func TestKlinesAPI_StatisticsFillsAggregates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupKlineTestDB(t)
+	hour := createTestKlineGap(t, db)
+
+	router := gin.New()
+	RegisterKlineRoutes(router.Group("/api/v1"), dao.NewKlineDAO(db, zap.NewNop()), zap.NewNop())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/klines/BTCUSDT/statistics?interval=1h&start_time="+
+		strconv.FormatInt(hour.Add(-3*time.Hour).Unix(), 10)+"&end_time="+strconv.FormatInt(hour.Add(time.Hour).Unix(), 10), nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data struct {
+			TotalRecords int64 `json:"total_records"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(3), response.Data.TotalRecords)
+}
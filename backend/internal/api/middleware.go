@@ -6,13 +6,19 @@ import (
 	"net"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
 )
 
+// readYourWritesCookie 客户端最近一次写入后读取走主库的截止时间（Unix 毫秒）
+const readYourWritesCookie = "primary_until"
+
 // ErrorHandler 错误处理中间件
 func ErrorHandler(logger *zap.Logger) gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
@@ -162,6 +168,34 @@ func CORSHandler() gin.HandlerFunc {
 	}
 }
 
+// ReadYourWritesHandler 跨请求的写后读一致性中间件
+// database.ReplicaRouter 只记录本进程内的写入，多实例部署时写入后的读取可能落在其他实例、读到延迟的从库。
+// 写请求通过 Cookie 把截止时间交给客户端，截止时间前同一客户端的读取以 database.WithPrimary 读主库
+func ReadYourWritesHandler(window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now()
+		primary := false
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if value, err := c.Cookie(readYourWritesCookie); err == nil {
+				until, err := strconv.ParseInt(value, 10, 64)
+				primary = err == nil && now.UnixMilli() < until
+			}
+		default:
+			until := now.Add(window).UnixMilli()
+			maxAge := int((window + time.Second - 1) / time.Second)
+			c.SetCookie(readYourWritesCookie, strconv.FormatInt(until, 10), maxAge, "/", "", false, true)
+			primary = true
+		}
+
+		if primary {
+			c.Request = c.Request.WithContext(database.WithPrimary(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
+
 // SecurityHeadersHandler 安全头处理中间件
 func SecurityHeadersHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
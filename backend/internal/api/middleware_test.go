@@ -13,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
 )
 
 // TestMiddleware_Integration 中间件集成测试
@@ -446,3 +448,47 @@ func TestJSONValidator(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
+
+// TestReadYourWritesHandler 测试写入后同一客户端的读取走主库
+func TestReadYourWritesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(ReadYourWritesHandler(10 * time.Second))
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.FormatBool(database.IsPrimaryContext(c.Request.Context())))
+	}
+	router.GET("/configs", handler)
+	router.POST("/configs", handler)
+
+	serve := func(method string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/configs", nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 没有写入标记时读从库
+	assert.Equal(t, "false", serve(http.MethodGet).Body.String())
+
+	// 写请求读主库，并返回写入标记
+	w := serve(http.MethodPost)
+	assert.Equal(t, "true", w.Body.String())
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, readYourWritesCookie, cookies[0].Name)
+	assert.Equal(t, 10, cookies[0].MaxAge)
+	assert.True(t, cookies[0].HttpOnly)
+
+	// 携带写入标记的读取走主库
+	assert.Equal(t, "true", serve(http.MethodGet, cookies[0]).Body.String())
+
+	// 标记过期或格式错误时读从库
+	expired := &http.Cookie{Name: readYourWritesCookie, Value: strconv.FormatInt(time.Now().Add(-time.Second).UnixMilli(), 10)}
+	assert.Equal(t, "false", serve(http.MethodGet, expired).Body.String())
+	invalid := &http.Cookie{Name: readYourWritesCookie, Value: "x"}
+	assert.Equal(t, "false", serve(http.MethodGet, invalid).Body.String())
+}
@@ -56,7 +56,7 @@ func (h *MonitoringConfigHandler) CreateConfig(c *gin.Context) {
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := h.dao.Create(config); err != nil {
+	if err := h.dao.WithContext(c.Request.Context()).Create(config); err != nil {
 		h.logger.Error("创建监控配置失败",
 			zap.String("name", req.Name),
 			zap.Error(err),
@@ -126,7 +126,7 @@ func (h *MonitoringConfigHandler) UpdateConfig(c *gin.Context) {
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := h.dao.Update(config); err != nil {
+	if err := h.dao.WithContext(c.Request.Context()).Update(config); err != nil {
 		h.logger.Error("更新监控配置失败",
 			zap.Int64("id", id),
 			zap.String("name", req.Name),
@@ -172,7 +172,7 @@ func (h *MonitoringConfigHandler) GetConfig(c *gin.Context) {
 
 	h.logger.Info("获取监控配置", zap.Int64("id", id))
 
-	config, err := h.dao.GetByID(id)
+	config, err := h.dao.WithContext(c.Request.Context()).GetByID(id)
 	if err != nil {
 		h.logger.Error("获取监控配置失败",
 			zap.Int64("id", id),
@@ -216,7 +216,7 @@ func (h *MonitoringConfigHandler) ListConfigs(c *gin.Context) {
 	)
 
 	offset := (page - 1) * pageSize
-	configs, total, err := h.dao.List(offset, pageSize)
+	configs, total, err := h.dao.WithContext(c.Request.Context()).List(offset, pageSize)
 	if err != nil {
 		h.logger.Error("获取监控配置列表失败", zap.Error(err))
 		InternalErrorResponse(c, "获取监控配置列表失败", map[string]interface{}{
@@ -248,7 +248,7 @@ func (h *MonitoringConfigHandler) DeleteConfig(c *gin.Context) {
 
 	h.logger.Info("删除监控配置", zap.Int64("id", id))
 
-	if err := h.dao.Delete(id); err != nil {
+	if err := h.dao.WithContext(c.Request.Context()).Delete(id); err != nil {
 		h.logger.Error("删除监控配置失败",
 			zap.Int64("id", id),
 			zap.Error(err),
@@ -281,7 +281,7 @@ func (h *MonitoringConfigHandler) DeleteConfig(c *gin.Context) {
 func (h *MonitoringConfigHandler) GetDefaultConfig(c *gin.Context) {
 	h.logger.Info("获取默认监控配置")
 
-	config, err := h.dao.GetDefault()
+	config, err := h.dao.WithContext(c.Request.Context()).GetDefault()
 	if err != nil {
 		h.logger.Error("获取默认监控配置失败", zap.Error(err))
 
@@ -313,7 +313,7 @@ func (h *MonitoringConfigHandler) SetDefaultConfig(c *gin.Context) {
 
 	h.logger.Info("设置默认监控配置", zap.Int64("id", id))
 
-	if err := h.dao.SetDefault(id); err != nil {
+	if err := h.dao.WithContext(c.Request.Context()).SetDefault(id); err != nil {
 		h.logger.Error("设置默认监控配置失败",
 			zap.Int64("id", id),
 			zap.Error(err),
@@ -360,7 +360,7 @@ func (h *MonitoringConfigHandler) SearchConfigs(c *gin.Context) {
 	)
 
 	offset := (page - 1) * pageSize
-	configs, total, err := h.dao.Search(keyword, offset, pageSize)
+	configs, total, err := h.dao.WithContext(c.Request.Context()).Search(keyword, offset, pageSize)
 	if err != nil {
 		h.logger.Error("搜索监控配置失败", zap.Error(err))
 		InternalErrorResponse(c, "搜索监控配置失败", map[string]interface{}{
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/dao"
@@ -249,8 +250,8 @@ func (h *PriceHandler) GetPriceStatistics(c *gin.Context) {
 func (h *PriceHandler) formatPriceData(price *models.PriceTick, format string) map[string]interface{} {
 	baseData := map[string]interface{}{
 		"symbol":     price.Symbol,
-		"price":      price.LastPrice,
-		"volume":     price.BaseVolume,
+		"price":      models.JSONDecimal(price.LastPrice),
+		"volume":     models.JSONDecimalPtr(price.BaseVolume),
 		"timestamp":  price.Timestamp.Unix(),
 		"created_at": price.CreatedAt.Unix(),
 	}
@@ -258,28 +259,24 @@ func (h *PriceHandler) formatPriceData(price *models.PriceTick, format string) m
 	// 根据格式进行价格格式化
 	switch format {
 	case "decimal":
-		baseData["price"] = h.formatDecimal(price.LastPrice, 2)
+		baseData["price"] = models.JSONDecimal(h.formatDecimal(price.LastPrice, 2))
 	case "integer":
-		baseData["price"] = int64(price.LastPrice)
+		baseData["price"] = price.LastPrice.IntPart()
 	case "scientific":
-		baseData["price"] = fmt.Sprintf("%.2e", price.LastPrice)
+		baseData["price"] = fmt.Sprintf("%.2e", price.LastPrice.InexactFloat64())
 	case "percentage":
-		baseData["price"] = fmt.Sprintf("%.4f%%", price.LastPrice)
+		baseData["price"] = price.LastPrice.StringFixed(4) + "%"
 	default:
 		// JSON格式，保持原始精度
-		baseData["price"] = price.LastPrice
+		baseData["price"] = models.JSONDecimal(price.LastPrice)
 	}
 
 	return baseData
 }
 
-// formatDecimal 格式化小数
-func (h *PriceHandler) formatDecimal(value float64, precision int) float64 {
-	multiplier := 1.0
-	for i := 0; i < precision; i++ {
-		multiplier *= 10
-	}
-	return float64(int64(value*multiplier)) / multiplier
+// formatDecimal 格式化小数，按精度截断
+func (h *PriceHandler) formatDecimal(value decimal.Decimal, precision int32) decimal.Decimal {
+	return value.Truncate(precision)
 }
 
 // isValidSymbol 验证交易对格式
@@ -334,37 +331,37 @@ func (h *PriceHandler) calculatePriceStatistics(prices []*models.PriceTick) map[
 	currentPrice := prices[0].LastPrice
 	highestPrice := prices[0].LastPrice
 	lowestPrice := prices[0].LastPrice
-	totalPrice := 0.0
-	totalVolume := 0.0
+	totalPrice := decimal.Zero
+	totalVolume := decimal.Zero
 
 	for _, price := range prices {
-		if price.LastPrice > highestPrice {
+		if price.LastPrice.GreaterThan(highestPrice) {
 			highestPrice = price.LastPrice
 		}
-		if price.LastPrice < lowestPrice {
+		if price.LastPrice.LessThan(lowestPrice) {
 			lowestPrice = price.LastPrice
 		}
-		totalPrice += price.LastPrice
+		totalPrice = totalPrice.Add(price.LastPrice)
 		if price.BaseVolume != nil {
-			totalVolume += *price.BaseVolume
+			totalVolume = totalVolume.Add(*price.BaseVolume)
 		}
 	}
 
-	averagePrice := totalPrice / float64(len(prices))
-	priceChange := currentPrice - averagePrice
+	averagePrice := totalPrice.Div(decimal.NewFromInt(int64(len(prices))))
+	priceChange := currentPrice.Sub(averagePrice)
 	changePercent := 0.0
-	if averagePrice > 0 {
-		changePercent = (priceChange / averagePrice) * 100
+	if averagePrice.Sign() > 0 {
+		changePercent = priceChange.Div(averagePrice).Mul(decimal.NewFromInt(100)).InexactFloat64()
 	}
 
 	return map[string]interface{}{
-		"current_price":  currentPrice,
-		"highest_price":  highestPrice,
-		"lowest_price":   lowestPrice,
-		"average_price":  averagePrice,
-		"price_change":   priceChange,
+		"current_price":  models.JSONDecimal(currentPrice),
+		"highest_price":  models.JSONDecimal(highestPrice),
+		"lowest_price":   models.JSONDecimal(lowestPrice),
+		"average_price":  models.JSONDecimal(averagePrice),
+		"price_change":   models.JSONDecimal(priceChange),
 		"change_percent": changePercent,
-		"volume":         totalVolume,
+		"volume":         models.JSONDecimal(totalVolume),
 		"trade_count":    len(prices),
 	}
 }
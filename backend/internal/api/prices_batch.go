@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/dao"
@@ -164,16 +165,16 @@ func (h *BatchPriceHandler) GetBatchPricesWithFilter(c *gin.Context) {
 	sortOrder := c.DefaultQuery("sort_order", "asc")
 
 	// 解析筛选参数
-	minPrice, _ := strconv.ParseFloat(minPriceStr, 64)
-	maxPrice, _ := strconv.ParseFloat(maxPriceStr, 64)
-	volumeThreshold, _ := strconv.ParseFloat(volumeThresholdStr, 64)
+	minPrice, _ := decimal.NewFromString(minPriceStr)
+	maxPrice, _ := decimal.NewFromString(maxPriceStr)
+	volumeThreshold, _ := decimal.NewFromString(volumeThresholdStr)
 
 	h.logger.Info("获取带筛选的批量价格",
 		zap.Strings("symbols", symbols),
 		zap.String("format", format),
-		zap.Float64("min_price", minPrice),
-		zap.Float64("max_price", maxPrice),
-		zap.Float64("volume_threshold", volumeThreshold),
+		zap.Stringer("min_price", minPrice),
+		zap.Stringer("max_price", maxPrice),
+		zap.Stringer("volume_threshold", volumeThreshold),
 		zap.String("sort_by", sortBy),
 		zap.String("sort_order", sortOrder),
 	)
@@ -443,20 +444,20 @@ func (h *BatchPriceHandler) getBatchPricesFromCache(ctx context.Context, symbols
 }
 
 // filterPrices 筛选价格
-func (h *BatchPriceHandler) filterPrices(prices []*models.PriceTick, minPrice, maxPrice, volumeThreshold float64) []*models.PriceTick {
+func (h *BatchPriceHandler) filterPrices(prices []*models.PriceTick, minPrice, maxPrice, volumeThreshold decimal.Decimal) []*models.PriceTick {
 	var filtered []*models.PriceTick
 
 	for _, price := range prices {
 		// 价格筛选
-		if minPrice > 0 && price.LastPrice < minPrice {
+		if minPrice.Sign() > 0 && price.LastPrice.LessThan(minPrice) {
 			continue
 		}
-		if maxPrice > 0 && price.LastPrice > maxPrice {
+		if maxPrice.Sign() > 0 && price.LastPrice.GreaterThan(maxPrice) {
 			continue
 		}
 
 		// 成交量筛选
-		if volumeThreshold > 0 && price.BaseVolume != nil && *price.BaseVolume < volumeThreshold {
+		if volumeThreshold.Sign() > 0 && price.BaseVolume != nil && price.BaseVolume.LessThan(volumeThreshold) {
 			continue
 		}
 
@@ -482,14 +483,14 @@ func (h *BatchPriceHandler) sortPrices(prices []*models.PriceTick, sortBy, sortO
 	case "price":
 		sort.Slice(sorted, func(i, j int) bool {
 			if sortOrder == "desc" {
-				return sorted[i].LastPrice > sorted[j].LastPrice
+				return sorted[i].LastPrice.GreaterThan(sorted[j].LastPrice)
 			}
-			return sorted[i].LastPrice < sorted[j].LastPrice
+			return sorted[i].LastPrice.LessThan(sorted[j].LastPrice)
 		})
 	case "volume":
 		sort.Slice(sorted, func(i, j int) bool {
-			volI := 0.0
-			volJ := 0.0
+			volI := decimal.Zero
+			volJ := decimal.Zero
 			if sorted[i].BaseVolume != nil {
 				volI = *sorted[i].BaseVolume
 			}
@@ -497,9 +498,9 @@ func (h *BatchPriceHandler) sortPrices(prices []*models.PriceTick, sortBy, sortO
 				volJ = *sorted[j].BaseVolume
 			}
 			if sortOrder == "desc" {
-				return volI > volJ
+				return volI.GreaterThan(volJ)
 			}
-			return volI < volJ
+			return volI.LessThan(volJ)
 		})
 	case "timestamp":
 		sort.Slice(sorted, func(i, j int) bool {
@@ -522,8 +523,8 @@ func (h *BatchPriceHandler) getPriceCacheKey(symbol string) string {
 func (h *BatchPriceHandler) formatPriceData(price *models.PriceTick, format string) map[string]interface{} {
 	baseData := map[string]interface{}{
 		"symbol":     price.Symbol,
-		"price":      price.LastPrice,
-		"volume":     price.BaseVolume,
+		"price":      models.JSONDecimal(price.LastPrice),
+		"volume":     models.JSONDecimalPtr(price.BaseVolume),
 		"timestamp":  price.Timestamp.Unix(),
 		"created_at": price.CreatedAt.Unix(),
 	}
@@ -531,28 +532,24 @@ func (h *BatchPriceHandler) formatPriceData(price *models.PriceTick, format stri
 	// 根据格式进行价格格式化
 	switch format {
 	case "decimal":
-		baseData["price"] = h.formatDecimal(price.LastPrice, 2)
+		baseData["price"] = models.JSONDecimal(h.formatDecimal(price.LastPrice, 2))
 	case "integer":
-		baseData["price"] = int64(price.LastPrice)
+		baseData["price"] = price.LastPrice.IntPart()
 	case "scientific":
-		baseData["price"] = fmt.Sprintf("%.2e", price.LastPrice)
+		baseData["price"] = fmt.Sprintf("%.2e", price.LastPrice.InexactFloat64())
 	case "percentage":
-		baseData["price"] = fmt.Sprintf("%.4f%%", price.LastPrice)
+		baseData["price"] = price.LastPrice.StringFixed(4) + "%"
 	default:
 		// JSON格式，保持原始精度
-		baseData["price"] = price.LastPrice
+		baseData["price"] = models.JSONDecimal(price.LastPrice)
 	}
 
 	return baseData
 }
 
-// formatDecimal 格式化小数
-func (h *BatchPriceHandler) formatDecimal(value float64, precision int) float64 {
-	multiplier := 1.0
-	for i := 0; i < precision; i++ {
-		multiplier *= 10
-	}
-	return float64(int64(value*multiplier)) / multiplier
+// formatDecimal 格式化小数，按精度截断
+func (h *BatchPriceHandler) formatDecimal(value decimal.Decimal, precision int32) decimal.Decimal {
+	return value.Truncate(precision)
 }
 
 // RegisterBatchPriceRoutes 注册批量价格路由
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/dao"
@@ -441,8 +442,8 @@ func (h *PriceCacheHandler) getCacheTTL(symbol string) time.Duration {
 func (h *PriceCacheHandler) formatPriceData(price *models.PriceTick, format string) map[string]interface{} {
 	baseData := map[string]interface{}{
 		"symbol":     price.Symbol,
-		"price":      price.LastPrice,
-		"volume":     price.BaseVolume,
+		"price":      models.JSONDecimal(price.LastPrice),
+		"volume":     models.JSONDecimalPtr(price.BaseVolume),
 		"timestamp":  price.Timestamp.Unix(),
 		"created_at": price.CreatedAt.Unix(),
 	}
@@ -450,28 +451,24 @@ func (h *PriceCacheHandler) formatPriceData(price *models.PriceTick, format stri
 	// 根据格式进行价格格式化
 	switch format {
 	case "decimal":
-		baseData["price"] = h.formatDecimal(price.LastPrice, 2)
+		baseData["price"] = models.JSONDecimal(h.formatDecimal(price.LastPrice, 2))
 	case "integer":
-		baseData["price"] = int64(price.LastPrice)
+		baseData["price"] = price.LastPrice.IntPart()
 	case "scientific":
-		baseData["price"] = fmt.Sprintf("%.2e", price.LastPrice)
+		baseData["price"] = fmt.Sprintf("%.2e", price.LastPrice.InexactFloat64())
 	case "percentage":
-		baseData["price"] = fmt.Sprintf("%.4f%%", price.LastPrice)
+		baseData["price"] = price.LastPrice.StringFixed(4) + "%"
 	default:
 		// JSON格式，保持原始精度
-		baseData["price"] = price.LastPrice
+		baseData["price"] = models.JSONDecimal(price.LastPrice)
 	}
 
 	return baseData
 }
 
-// formatDecimal 格式化小数
-func (h *PriceCacheHandler) formatDecimal(value float64, precision int) float64 {
-	multiplier := 1.0
-	for i := 0; i < precision; i++ {
-		multiplier *= 10
-	}
-	return float64(int64(value*multiplier)) / multiplier
+// formatDecimal 格式化小数，按精度截断
+func (h *PriceCacheHandler) formatDecimal(value decimal.Decimal, precision int32) decimal.Decimal {
+	return value.Truncate(precision)
 }
 
 // RegisterPriceCacheRoutes 注册价格缓存路由
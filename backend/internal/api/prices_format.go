@@ -2,11 +2,11 @@ package api
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
@@ -36,8 +36,8 @@ func (f *PriceFormatter) FormatPrice(c *gin.Context) {
 	// 模拟价格数据
 	price := &models.PriceTick{
 		Symbol:     symbol,
-		LastPrice:  50000.123456789,
-		BaseVolume: &[]float64{100.5}[0],
+		LastPrice:  decimal.RequireFromString("50000.123456789"),
+		BaseVolume: models.DecimalPtr(decimal.RequireFromString("100.5")),
 		Timestamp:  time.Now(),
 		CreatedAt:  time.Now(),
 	}
@@ -83,11 +83,10 @@ func (f *PriceFormatter) FormatBatchPrices(c *gin.Context) {
 	// 模拟批量价格数据
 	prices := make([]*models.PriceTick, len(symbols))
 	for i, symbol := range symbols {
-		volume := 100.5 + float64(i)*10
 		prices[i] = &models.PriceTick{
 			Symbol:     symbol,
-			LastPrice:  50000.0 + float64(i)*100,
-			BaseVolume: &volume,
+			LastPrice:  decimal.NewFromInt(50000 + int64(i)*100),
+			BaseVolume: models.DecimalPtr(decimal.RequireFromString("100.5").Add(decimal.NewFromInt(int64(i) * 10))),
 			Timestamp:  time.Now(),
 			CreatedAt:  time.Now(),
 		}
@@ -108,7 +107,7 @@ func (f *PriceFormatter) FormatBatchPrices(c *gin.Context) {
 func (f *PriceFormatter) formatPriceData(price *models.PriceTick, format, precision, currency, locale string) map[string]interface{} {
 	baseData := map[string]interface{}{
 		"symbol":     price.Symbol,
-		"volume":     price.BaseVolume,
+		"volume":     models.JSONDecimalPtr(price.BaseVolume),
 		"timestamp":  price.Timestamp.Unix(),
 		"created_at": price.CreatedAt.Unix(),
 	}
@@ -118,7 +117,7 @@ func (f *PriceFormatter) formatPriceData(price *models.PriceTick, format, precis
 	case "json":
 		baseData["price"] = f.formatJSONPrice(price.LastPrice, precision)
 	case "decimal":
-		baseData["price"] = f.formatDecimalPrice(price.LastPrice, precision)
+		baseData["price"] = models.JSONDecimal(f.formatDecimalPrice(price.LastPrice, precision))
 	case "integer":
 		baseData["price"] = f.formatIntegerPrice(price.LastPrice)
 	case "scientific":
@@ -132,7 +131,7 @@ func (f *PriceFormatter) formatPriceData(price *models.PriceTick, format, precis
 	case "compact":
 		baseData["price"] = f.formatCompactPrice(price.LastPrice, precision)
 	default:
-		baseData["price"] = price.LastPrice
+		baseData["price"] = models.JSONDecimal(price.LastPrice)
 	}
 
 	// 添加格式化信息
@@ -147,26 +146,26 @@ func (f *PriceFormatter) formatPriceData(price *models.PriceTick, format, precis
 }
 
 // formatJSONPrice 格式化JSON价格
-func (f *PriceFormatter) formatJSONPrice(price float64, precision string) interface{} {
+func (f *PriceFormatter) formatJSONPrice(price decimal.Decimal, precision string) interface{} {
 	if precision == "auto" {
-		return price
+		return models.JSONDecimal(price)
 	}
 
 	prec, err := strconv.Atoi(precision)
 	if err != nil {
-		return price
+		return models.JSONDecimal(price)
 	}
 
-	return f.roundToPrecision(price, prec)
+	return models.JSONDecimal(f.roundToPrecision(price, prec))
 }
 
 // formatDecimalPrice 格式化小数价格
-func (f *PriceFormatter) formatDecimalPrice(price float64, precision string) float64 {
+func (f *PriceFormatter) formatDecimalPrice(price decimal.Decimal, precision string) decimal.Decimal {
 	if precision == "auto" {
 		// 自动确定精度
-		if price >= 1000 {
+		if price.GreaterThanOrEqual(decimal.NewFromInt(1000)) {
 			return f.roundToPrecision(price, 2)
-		} else if price >= 1 {
+		} else if price.GreaterThanOrEqual(decimal.NewFromInt(1)) {
 			return f.roundToPrecision(price, 4)
 		} else {
 			return f.roundToPrecision(price, 8)
@@ -182,14 +181,14 @@ func (f *PriceFormatter) formatDecimalPrice(price float64, precision string) flo
 }
 
 // formatIntegerPrice 格式化整数价格
-func (f *PriceFormatter) formatIntegerPrice(price float64) int64 {
-	return int64(math.Round(price))
+func (f *PriceFormatter) formatIntegerPrice(price decimal.Decimal) int64 {
+	return price.Round(0).IntPart()
 }
 
 // formatScientificPrice 格式化科学计数法价格
-func (f *PriceFormatter) formatScientificPrice(price float64, precision string) string {
+func (f *PriceFormatter) formatScientificPrice(price decimal.Decimal, precision string) string {
 	if precision == "auto" {
-		return fmt.Sprintf("%.2e", price)
+		return fmt.Sprintf("%.2e", price.InexactFloat64())
 	}
 
 	prec, err := strconv.Atoi(precision)
@@ -198,77 +197,76 @@ func (f *PriceFormatter) formatScientificPrice(price float64, precision string)
 	}
 
 	format := fmt.Sprintf("%%.%de", prec)
-	return fmt.Sprintf(format, price)
+	return fmt.Sprintf(format, price.InexactFloat64())
 }
 
 // formatCurrencyPrice 格式化货币价格
-func (f *PriceFormatter) formatCurrencyPrice(price float64, currency, locale, precision string) string {
+func (f *PriceFormatter) formatCurrencyPrice(price decimal.Decimal, currency, locale, precision string) string {
 	// 简化的货币格式化
 	formattedPrice := f.formatDecimalPrice(price, precision)
 
 	switch currency {
 	case "USD":
-		return fmt.Sprintf("$%.2f", formattedPrice)
+		return "$" + formattedPrice.StringFixed(2)
 	case "EUR":
-		return fmt.Sprintf("€%.2f", formattedPrice)
+		return "€" + formattedPrice.StringFixed(2)
 	case "JPY":
-		return fmt.Sprintf("¥%.0f", formattedPrice)
+		return "¥" + formattedPrice.StringFixed(0)
 	case "GBP":
-		return fmt.Sprintf("£%.2f", formattedPrice)
+		return "£" + formattedPrice.StringFixed(2)
 	case "CNY":
-		return fmt.Sprintf("¥%.2f", formattedPrice)
+		return "¥" + formattedPrice.StringFixed(2)
 	default:
-		return fmt.Sprintf("%.2f %s", formattedPrice, currency)
+		return formattedPrice.StringFixed(2) + " " + currency
 	}
 }
 
 // formatPercentagePrice 格式化百分比价格
-func (f *PriceFormatter) formatPercentagePrice(price float64, precision string) string {
+func (f *PriceFormatter) formatPercentagePrice(price decimal.Decimal, precision string) string {
 	prec, err := strconv.Atoi(precision)
 	if err != nil {
 		prec = 4
 	}
 
-	format := fmt.Sprintf("%%.%df%%", prec)
-	return fmt.Sprintf(format, price)
+	return price.StringFixed(int32(prec)) + "%"
+}
+
+// priceUnits 大数值的单位，按从大到小排列
+var priceUnits = []struct {
+	exp    int32
+	suffix string
+}{
+	{12, "T"},
+	{9, "B"},
+	{6, "M"},
+	{3, "K"},
 }
 
 // formatHumanPrice 格式化人类可读价格
-func (f *PriceFormatter) formatHumanPrice(price float64, precision string) string {
-	if price >= 1e12 {
-		return fmt.Sprintf("%.2fT", price/1e12)
-	} else if price >= 1e9 {
-		return fmt.Sprintf("%.2fB", price/1e9)
-	} else if price >= 1e6 {
-		return fmt.Sprintf("%.2fM", price/1e6)
-	} else if price >= 1e3 {
-		return fmt.Sprintf("%.2fK", price/1e3)
-	} else {
-		return fmt.Sprintf("%.2f", f.formatDecimalPrice(price, precision))
-	}
+func (f *PriceFormatter) formatHumanPrice(price decimal.Decimal, precision string) string {
+	return f.formatWithUnit(price, precision, 2)
 }
 
 // formatCompactPrice 格式化紧凑价格
-func (f *PriceFormatter) formatCompactPrice(price float64, precision string) string {
-	if price >= 1e12 {
-		return fmt.Sprintf("%.1fT", price/1e12)
-	} else if price >= 1e9 {
-		return fmt.Sprintf("%.1fB", price/1e9)
-	} else if price >= 1e6 {
-		return fmt.Sprintf("%.1fM", price/1e6)
-	} else if price >= 1e3 {
-		return fmt.Sprintf("%.1fK", price/1e3)
-	} else {
-		return fmt.Sprintf("%.2f", f.formatDecimalPrice(price, precision))
+func (f *PriceFormatter) formatCompactPrice(price decimal.Decimal, precision string) string {
+	return f.formatWithUnit(price, precision, 1)
+}
+
+// formatWithUnit 大于 1000 的价格缩写为 K/M/B/T 并保留 places 位小数，其余按精度格式化
+func (f *PriceFormatter) formatWithUnit(price decimal.Decimal, precision string, places int32) string {
+	for _, unit := range priceUnits {
+		if price.GreaterThanOrEqual(decimal.New(1, unit.exp)) {
+			return price.Shift(-unit.exp).StringFixed(places) + unit.suffix
+		}
 	}
+	return f.formatDecimalPrice(price, precision).StringFixed(2)
 }
 
 // 辅助方法
 
 // roundToPrecision 四舍五入到指定精度
-func (f *PriceFormatter) roundToPrecision(value float64, precision int) float64 {
-	multiplier := math.Pow(10, float64(precision))
-	return math.Round(value*multiplier) / multiplier
+func (f *PriceFormatter) roundToPrecision(value decimal.Decimal, precision int) decimal.Decimal {
+	return value.Round(int32(precision))
 }
 
 // FormatPriceChange 格式化价格变化
@@ -281,9 +279,9 @@ func (f *PriceFormatter) FormatPriceChange(c *gin.Context) {
 	// 模拟价格变化数据
 	changeData := map[string]interface{}{
 		"symbol":         symbol,
-		"current_price":  50000.0,
-		"previous_price": 49000.0,
-		"price_change":   1000.0,
+		"current_price":  decimal.NewFromInt(50000),
+		"previous_price": decimal.NewFromInt(49000),
+		"price_change":   decimal.NewFromInt(1000),
 		"change_percent": 2.04,
 		"period":         period,
 		"timestamp":      time.Now().Unix(),
@@ -308,14 +306,15 @@ func (f *PriceFormatter) formatPriceChangeData(data map[string]interface{}, form
 	for key, value := range data {
 		switch key {
 		case "current_price", "previous_price", "price_change":
+			price := value.(decimal.Decimal)
 			if format == "decimal" {
-				formatted[key] = f.roundToPrecision(value.(float64), 2)
+				formatted[key] = models.JSONDecimal(f.roundToPrecision(price, 2))
 			} else if format == "integer" {
-				formatted[key] = int64(value.(float64))
+				formatted[key] = price.IntPart()
 			} else if format == "scientific" {
-				formatted[key] = fmt.Sprintf("%.2e", value.(float64))
+				formatted[key] = fmt.Sprintf("%.2e", price.InexactFloat64())
 			} else {
-				formatted[key] = value
+				formatted[key] = models.JSONDecimal(price)
 			}
 		case "change_percent":
 			if format == "percentage" {
@@ -342,11 +341,11 @@ func (f *PriceFormatter) FormatPriceStatistics(c *gin.Context) {
 	statsData := map[string]interface{}{
 		"symbol":        symbol,
 		"period":        period,
-		"current_price": 50000.0,
-		"highest_price": 52000.0,
-		"lowest_price":  48000.0,
-		"average_price": 50000.0,
-		"volume":        1000000.0,
+		"current_price": decimal.NewFromInt(50000),
+		"highest_price": decimal.NewFromInt(52000),
+		"lowest_price":  decimal.NewFromInt(48000),
+		"average_price": decimal.NewFromInt(50000),
+		"volume":        decimal.NewFromInt(1000000),
 		"trade_count":   1500,
 		"timestamp":     time.Now().Unix(),
 	}
@@ -370,16 +369,17 @@ func (f *PriceFormatter) formatStatisticsData(data map[string]interface{}, forma
 	for key, value := range data {
 		switch key {
 		case "current_price", "highest_price", "lowest_price", "average_price", "volume":
+			price := value.(decimal.Decimal)
 			if format == "decimal" {
-				formatted[key] = f.roundToPrecision(value.(float64), 2)
+				formatted[key] = models.JSONDecimal(f.roundToPrecision(price, 2))
 			} else if format == "integer" {
-				formatted[key] = int64(value.(float64))
+				formatted[key] = price.IntPart()
 			} else if format == "scientific" {
-				formatted[key] = fmt.Sprintf("%.2e", value.(float64))
+				formatted[key] = fmt.Sprintf("%.2e", price.InexactFloat64())
 			} else if format == "human" {
-				formatted[key] = f.formatHumanPrice(value.(float64), "2")
+				formatted[key] = f.formatHumanPrice(price, "2")
 			} else {
-				formatted[key] = value
+				formatted[key] = models.JSONDecimal(price)
 			}
 		case "trade_count":
 			formatted[key] = value
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -43,11 +44,10 @@ func createTestPriceData(t *testing.T, db *gorm.DB) []*models.PriceTick {
 	var allPrices []*models.PriceTick
 	for _, symbol := range symbols {
 		for i := 0; i < 10; i++ {
-			volume := 100.0 + float64(i)*10
 			price := &models.PriceTick{
 				Symbol:     symbol,
-				LastPrice:  50000.0 + float64(i)*100,
-				BaseVolume: &volume,
+				LastPrice:  decimal.NewFromInt(int64(50000 + i*100)),
+				BaseVolume: models.DecimalPtr(decimal.NewFromInt(int64(100 + i*10))),
 				Timestamp:  now.Add(-time.Duration(i) * time.Minute),
 				CreatedAt:  now,
 			}
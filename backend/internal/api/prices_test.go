@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -35,29 +36,29 @@ func createTestPrices(t *testing.T, db *gorm.DB) []*models.PriceTick {
 	prices := []*models.PriceTick{
 		{
 			Symbol:     "BTCUSDT",
-			LastPrice:  50000.0,
-			BaseVolume: &[]float64{100.5}[0],
+			LastPrice:  decimal.NewFromInt(50000),
+			BaseVolume: models.DecimalPtr(decimal.RequireFromString("100.5")),
 			Timestamp:  now.Add(-5 * time.Minute),
 			CreatedAt:  now,
 		},
 		{
 			Symbol:     "BTCUSDT",
-			LastPrice:  50100.0,
-			BaseVolume: &[]float64{150.2}[0],
+			LastPrice:  decimal.NewFromInt(50100),
+			BaseVolume: models.DecimalPtr(decimal.RequireFromString("150.2")),
 			Timestamp:  now.Add(-4 * time.Minute),
 			CreatedAt:  now,
 		},
 		{
 			Symbol:     "ETHUSDT",
-			LastPrice:  3000.0,
-			BaseVolume: &[]float64{200.3}[0],
+			LastPrice:  decimal.NewFromInt(3000),
+			BaseVolume: models.DecimalPtr(decimal.RequireFromString("200.3")),
 			Timestamp:  now.Add(-3 * time.Minute),
 			CreatedAt:  now,
 		},
 		{
 			Symbol:     "ADAUSDT",
-			LastPrice:  0.5,
-			BaseVolume: &[]float64{1000.0}[0],
+			LastPrice:  decimal.RequireFromString("0.5"),
+			BaseVolume: models.DecimalPtr(decimal.NewFromInt(1000)),
 			Timestamp:  now.Add(-2 * time.Minute),
 			CreatedAt:  now,
 		},
@@ -526,3 +527,36 @@ func TestPricesAPI_ConcurrencyBasic(t *testing.T) {
 		}
 	})
 }
+
+func TestPriceFormatter_DecimalRounding(t *testing.T) {
+	formatter := NewPriceFormatter(zap.NewNop())
+
+	// 1.005*100 在 float64 下为 100.49999999999999，按浮点舍入会得到 1.00
+	assert.Equal(t, "1.01", formatter.roundToPrecision(decimal.RequireFromString("1.005"), 2).String())
+	assert.Equal(t, "0.00012346", formatter.formatDecimalPrice(decimal.RequireFromString("0.000123456"), "auto").String())
+	assert.Equal(t, "$50000.13", formatter.formatCurrencyPrice(decimal.RequireFromString("50000.125"), "USD", "en-US", "2"))
+	assert.Equal(t, "1.23M", formatter.formatHumanPrice(decimal.NewFromInt(1234567), "2"))
+	assert.Equal(t, int64(3), formatter.formatIntegerPrice(decimal.RequireFromString("2.5")))
+
+	handler := &PriceHandler{}
+	assert.Equal(t, "0.29", handler.formatDecimal(decimal.RequireFromString("0.29"), 2).String())
+	assert.Equal(t, "0.29", handler.formatDecimal(decimal.RequireFromString("0.2999"), 2).String())
+}
+
+func TestCalculateKlineStatistics(t *testing.T) {
+	klines := []*models.Kline{
+		{Open: decimal.RequireFromString("0.1"), High: decimal.RequireFromString("0.3"), Low: decimal.RequireFromString("0.1"),
+			Close: decimal.RequireFromString("0.2"), BaseVolume: decimal.RequireFromString("0.1")},
+		{Open: decimal.RequireFromString("0.2"), High: decimal.RequireFromString("0.4"), Low: decimal.RequireFromString("0.05"),
+			Close: decimal.RequireFromString("0.3"), BaseVolume: decimal.RequireFromString("0.2")},
+	}
+
+	stats := calculateKlineStatistics(klines)
+	assert.Equal(t, int64(2), stats.TotalRecords)
+	assert.Equal(t, "0.4", stats.HighestPrice.String())
+	assert.Equal(t, "0.05", stats.LowestPrice.String())
+	assert.Equal(t, "0.2", stats.AveragePrice.String())
+	assert.Equal(t, "0.3", stats.TotalVolume.String(), "0.1+0.2 精确等于 0.3")
+	assert.Equal(t, "0.15", stats.AverageVolume.String())
+	assert.Equal(t, "0.2", stats.HighestVolume.String())
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+)
+
+// ReplicaMonitor 从库健康状态（database.ReplicaRouter）
+type ReplicaMonitor interface {
+	Health() *database.ReplicaHealth
+	CheckReplicas(ctx context.Context)
+}
+
+// ReplicaHandler 读写分离从库管理处理器
+type ReplicaHandler struct {
+	monitor ReplicaMonitor
+	logger  *zap.Logger
+}
+
+// NewReplicaHandler 创建从库管理处理器
+func NewReplicaHandler(monitor ReplicaMonitor, logger *zap.Logger) *ReplicaHandler {
+	return &ReplicaHandler{
+		monitor: monitor,
+		logger:  logger,
+	}
+}
+
+// GetHealth 获取各从库的复制延迟和是否在读池中
+func (h *ReplicaHandler) GetHealth(c *gin.Context) {
+	SuccessResponse(c, "获取从库状态成功", h.monitor.Health())
+}
+
+// Check 立即检查所有从库，不等待下一次定时检查
+func (h *ReplicaHandler) Check(c *gin.Context) {
+	h.monitor.CheckReplicas(c.Request.Context())
+
+	health := h.monitor.Health()
+	h.logger.Info("手动检查从库",
+		zap.Int("replicas", len(health.Replicas)),
+		zap.Int("healthy_replicas", health.HealthyReplicas),
+	)
+	SuccessResponse(c, "检查从库完成", health)
+}
+
+// RegisterReplicaRoutes 注册从库管理路由
+func RegisterReplicaRoutes(router *gin.RouterGroup, monitor ReplicaMonitor, logger *zap.Logger) {
+	handler := NewReplicaHandler(monitor, logger)
+
+	replicas := router.Group("/database/replicas")
+	{
+		replicas.GET("", handler.GetHealth)
+		replicas.POST("/check", handler.Check)
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/middleware"
+)
+
+// fakeReplicaMonitor 检查后把从库标记为已恢复
+type fakeReplicaMonitor struct {
+	health *database.ReplicaHealth
+	checks int
+}
+
+func (f *fakeReplicaMonitor) Health() *database.ReplicaHealth {
+	return f.health
+}
+
+func (f *fakeReplicaMonitor) CheckReplicas(ctx context.Context) {
+	f.checks++
+	f.health.Replicas[1].Healthy = true
+	f.health.Replicas[1].LagSeconds = 0.5
+	f.health.HealthyReplicas = 2
+}
+
+func newFakeReplicaMonitor() *fakeReplicaMonitor {
+	return &fakeReplicaMonitor{
+		health: &database.ReplicaHealth{
+			MaxLagSeconds:   5,
+			HealthyReplicas: 1,
+			Replicas: []*database.ReplicaStatus{
+				{Name: "replica1:5432", Healthy: true, LagSeconds: 0.2},
+				{Name: "replica2:5432", Healthy: false, LagSeconds: 42},
+			},
+		},
+	}
+}
+
+func setupReplicaTestRouter(monitor ReplicaMonitor) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterAllRoutes(router.Group("/api/v1"), &RouterConfig{
+		Logger:         zap.NewNop(),
+		ReplicaMonitor: monitor,
+		AdminAuth:      middleware.RequirePermission(&fakePermissionChecker{adminToken: "admin-token"}, "admin"),
+	})
+	return router
+}
+
+func TestReplicaAPI_Health(t *testing.T) {
+	router := setupReplicaTestRouter(newFakeReplicaMonitor())
+
+	w := doStorageRequest(router, http.MethodGet, "/api/v1/admin/database/replicas", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 只配置了从库监控时存储策略接口不注册
+	w = doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables", "admin-token", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doStorageRequest(router, http.MethodGet, "/api/v1/admin/database/replicas", "admin-token", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                   `json:"success"`
+		Data    database.ReplicaHealth `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, 1, response.Data.HealthyReplicas)
+	require.Len(t, response.Data.Replicas, 2)
+	assert.False(t, response.Data.Replicas[1].Healthy)
+	assert.Equal(t, 42.0, response.Data.Replicas[1].LagSeconds)
+}
+
+func TestReplicaAPI_Check(t *testing.T) {
+	monitor := newFakeReplicaMonitor()
+	router := setupReplicaTestRouter(monitor)
+
+	w := doStorageRequest(router, http.MethodPost, "/api/v1/admin/database/replicas/check", "admin-token", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, monitor.checks)
+	assert.Contains(t, w.Body.String(), `"healthy_replicas":2`)
+}
@@ -1,12 +1,16 @@
 package api
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/api/handlers"
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
 	"github.com/haxrd/cryptosignal-hunter/internal/dao"
 	"github.com/haxrd/cryptosignal-hunter/internal/middleware"
 )
@@ -18,7 +22,17 @@ type RouterConfig struct {
 	SymbolDAO            dao.SymbolDAO
 	KlineDAO             dao.KlineDAO
 	PriceTickDAO         dao.PriceTickDAO
+	PriceChangeRateDAO   dao.PriceChangeRateDAO
 	MonitoringConfigDAO  *dao.MonitoringConfigDAO
+	ScannerIndex         cache.ScannerIndex
+	StreamHandler        http.HandlerFunc // SSE 推送（WebSocketServer.ServeSSE），为空时不注册
+	StoragePolicyDAO     dao.StoragePolicyDAO // 存储策略管理，为空时不注册管理接口
+	AdminAuth            gin.HandlerFunc      // 管理接口的认证中间件，为空时不校验
+	ExportSource         ExportSource         // 批量导出的数据源，为空时不注册导出接口
+	ExportLimits         ExportLimits         // 导出的时间范围和并发限制
+	ExportAuth           gin.HandlerFunc      // 导出接口的认证中间件，为空时不校验
+	ReplicaMonitor       ReplicaMonitor       // 从库健康状态，为空时不注册从库管理接口
+	ReadYourWritesWindow time.Duration        // 写入后同一客户端的读取走主库的时间，0 表示不启用（未配置从库）
 	CacheManager         CacheManager
 }
 
@@ -52,6 +66,11 @@ func SetupRouter(config *RouterConfig) *gin.Engine {
 
 // RegisterAllRoutes 注册所有业务路由
 func RegisterAllRoutes(router *gin.RouterGroup, config *RouterConfig) {
+	// 写后读一致性：需要在注册路由之前添加
+	if config.ReadYourWritesWindow > 0 {
+		router.Use(ReadYourWritesHandler(config.ReadYourWritesWindow))
+	}
+
 	// 交易对管理API
 	RegisterSymbolRoutes(router, config.SymbolDAO, config.Logger)
 	
@@ -60,9 +79,41 @@ func RegisterAllRoutes(router *gin.RouterGroup, config *RouterConfig) {
 	
 	// 实时价格API
 	RegisterPriceRoutes(router, config.PriceTickDAO, config.Logger)
+
+	// 价格变化率API
+	RegisterChangeRateRoutes(router, config.PriceChangeRateDAO, config.Logger)
 	
 	// 配置管理API
 	RegisterMonitoringConfigRoutes(router, config.MonitoringConfigDAO, config.Logger)
+
+	// 市场扫描器API
+	if config.ScannerIndex != nil {
+		RegisterScannerRoutes(router, config.ScannerIndex, config.MonitoringConfigDAO, config.Logger)
+	}
+
+	// 实时推送API（SSE）
+	if config.StreamHandler != nil {
+		RegisterStreamRoutes(router, config.StreamHandler)
+	}
+
+	// 数据导出API（CSV/NDJSON/Parquet 流式下载）
+	if config.ExportSource != nil {
+		RegisterExportRoutes(router, config.ExportSource, config.ExportLimits, config.ExportAuth, config.Logger)
+	}
+
+	// 管理API
+	if config.StoragePolicyDAO != nil || config.ReplicaMonitor != nil {
+		admin := router.Group("/admin")
+		if config.AdminAuth != nil {
+			admin.Use(config.AdminAuth)
+		}
+		if config.StoragePolicyDAO != nil {
+			RegisterStorageRoutes(admin, config.StoragePolicyDAO, config.Logger)
+		}
+		if config.ReplicaMonitor != nil {
+			RegisterReplicaRoutes(admin, config.ReplicaMonitor, config.Logger)
+		}
+	}
 	
 	// 如果启用了缓存，注册缓存版本的路由
 	if config.CacheManager != nil {
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// ScannerHandler 市场扫描器处理器
+type ScannerHandler struct {
+	index     cache.ScannerIndex
+	configDAO *dao.MonitoringConfigDAO
+	logger    *zap.Logger
+}
+
+// NewScannerHandler 创建市场扫描器处理器
+func NewScannerHandler(index cache.ScannerIndex, configDAO *dao.MonitoringConfigDAO, logger *zap.Logger) *ScannerHandler {
+	return &ScannerHandler{
+		index:     index,
+		configDAO: configDAO,
+		logger:    logger,
+	}
+}
+
+// Scan 按指标对交易对排行，支持价格、成交量和交易对过滤
+func (h *ScannerHandler) Scan(c *gin.Context) {
+	ctx := context.Background()
+
+	page := c.GetInt("page")
+	pageSize := c.GetInt("page_size")
+	sortBy := c.DefaultQuery("sort_by", cache.ScannerSortChangeRate)
+	order := c.DefaultQuery("order", cache.ScannerOrderDesc)
+
+	var errors ValidationErrors
+
+	if !cache.ValidScannerSort(sortBy) {
+		errors = append(errors, ValidationError{
+			Field:   "sort_by",
+			Message: "排序指标必须是 " + strings.Join(cache.ScannerSortFields, "、") + " 之一",
+			Value:   sortBy,
+		})
+	}
+
+	window := ""
+	if sortBy == cache.ScannerSortChangeRate {
+		window = c.DefaultQuery("window", defaultChangeRateWindow)
+		if _, err := models.ParseTimeWindow(window); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "window",
+				Message: "时间窗口格式无效，应为数字+单位(s/m/h/d)，如 10s、5m、4h",
+				Value:   window,
+			})
+		}
+	}
+
+	if order != cache.ScannerOrderDesc && order != cache.ScannerOrderAsc {
+		errors = append(errors, ValidationError{
+			Field:   "order",
+			Message: "排序方向必须是 desc 或 asc",
+			Value:   order,
+		})
+	}
+
+	var maxAge time.Duration
+	if maxAgeStr := c.Query("max_age"); maxAgeStr != "" {
+		var err error
+		if maxAge, err = models.ParseTimeWindow(maxAgeStr); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "max_age",
+				Message: "数据时效格式无效，应为数字+单位(s/m/h/d)，如 2m",
+				Value:   maxAgeStr,
+			})
+		}
+	}
+
+	// 加载监控配置中的过滤条件，查询参数可覆盖其中的字段
+	filters := &models.MonitoringConfigFilters{}
+	if configIDStr := c.Query("config_id"); configIDStr != "" {
+		configID, err := strconv.ParseInt(configIDStr, 10, 64)
+		if err != nil || configID < 1 {
+			errors = append(errors, ValidationError{
+				Field:   "config_id",
+				Message: "配置ID必须是正整数",
+				Value:   configIDStr,
+			})
+		} else if len(errors) == 0 {
+			if h.configDAO == nil {
+				BadRequestResponse(c, "未启用监控配置", nil)
+				return
+			}
+			config, err := h.configDAO.WithContext(c.Request.Context()).GetByID(configID)
+			if err != nil {
+				if _, ok := err.(*dao.NotFoundError); ok {
+					NotFoundResponse(c, "监控配置不存在", map[string]interface{}{
+						"id": configID,
+					})
+					return
+				}
+				h.logger.Error("加载扫描器监控配置失败",
+					zap.Int64("config_id", configID),
+					zap.Error(err),
+				)
+				InternalErrorResponse(c, "加载监控配置失败", map[string]interface{}{
+					"error": err.Error(),
+				})
+				return
+			}
+			filters = &config.Filters
+		}
+	}
+
+	errors = append(errors, parseScannerFilterOverrides(c, filters)...)
+
+	if len(errors) > 0 {
+		ValidationErrorResponse(c, "扫描器参数验证失败", errors)
+		return
+	}
+
+	h.logger.Info("市场扫描",
+		zap.String("sort_by", sortBy),
+		zap.String("window", window),
+		zap.String("order", order),
+		zap.Int("symbols", len(filters.Symbols)),
+		zap.Int("page", page),
+		zap.Int("page_size", pageSize),
+	)
+
+	result, err := h.index.Query(ctx, &cache.ScannerQuery{
+		SortBy:   sortBy,
+		Window:   window,
+		Order:    order,
+		Filters:  filters,
+		Page:     page,
+		PageSize: pageSize,
+		MaxAge:   maxAge,
+	})
+	if err != nil {
+		h.logger.Error("市场扫描失败",
+			zap.String("sort_by", sortBy),
+			zap.Error(err),
+		)
+		InternalErrorResponse(c, "市场扫描失败", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	pagination := CalculatePagination(page, pageSize, result.Total)
+
+	PaginatedResponse(c, "市场扫描成功", result.Entries, pagination)
+}
+
+// parseScannerFilterOverrides 解析查询参数中的过滤条件并覆盖到 filters
+func parseScannerFilterOverrides(c *gin.Context, filters *models.MonitoringConfigFilters) ValidationErrors {
+	var errors ValidationErrors
+
+	bounds := []struct {
+		field  string
+		target **float64
+	}{
+		{"min_price", &filters.MinPrice},
+		{"max_price", &filters.MaxPrice},
+		{"min_volume", &filters.MinVolume},
+		{"max_volume", &filters.MaxVolume},
+	}
+	for _, bound := range bounds {
+		raw := c.Query(bound.field)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value < 0 {
+			errors = append(errors, ValidationError{
+				Field:   bound.field,
+				Message: "必须是非负数",
+				Value:   raw,
+			})
+			continue
+		}
+		*bound.target = &value
+	}
+
+	if filters.MinPrice != nil && filters.MaxPrice != nil && *filters.MinPrice > *filters.MaxPrice {
+		errors = append(errors, ValidationError{
+			Field:   "min_price",
+			Message: "最小价格不能大于最大价格",
+		})
+	}
+	if filters.MinVolume != nil && filters.MaxVolume != nil && *filters.MinVolume > *filters.MaxVolume {
+		errors = append(errors, ValidationError{
+			Field:   "min_volume",
+			Message: "最小成交量不能大于最大成交量",
+		})
+	}
+
+	if raw := c.Query("symbols"); raw != "" {
+		var symbols []string
+		for _, symbol := range strings.Split(raw, ",") {
+			symbol = strings.ToUpper(strings.TrimSpace(symbol))
+			if symbol != "" {
+				symbols = append(symbols, symbol)
+			}
+		}
+		filters.Symbols = symbols
+	}
+
+	return errors
+}
+
+// RegisterScannerRoutes 注册市场扫描器路由
+func RegisterScannerRoutes(router *gin.RouterGroup, index cache.ScannerIndex, configDAO *dao.MonitoringConfigDAO, logger *zap.Logger) {
+	handler := NewScannerHandler(index, configDAO, logger)
+
+	router.GET("/scanner",
+		PaginationValidator(),
+		handler.Scan,
+	)
+}
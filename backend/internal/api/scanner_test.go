@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// setupScannerTestRouter 设置扫描器API测试路由
+func setupScannerTestRouter(t *testing.T) (*gin.Engine, int64) {
+	gin.SetMode(gin.TestMode)
+
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redisConfig := cache.DefaultConfig()
+	redisConfig.Host = mr.Host()
+	redisConfig.Port = port
+	redisConfig.MinIdleConns = 0
+	client, err := cache.NewClient(redisConfig, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	index := cache.NewScannerIndex(client)
+	now := time.Now()
+	require.NoError(t, index.UpdateTickers(t.Context(), []*cache.ScannerTicker{
+		{Symbol: "BTCUSDT", Timestamp: now, LastPrice: float64Ptr(50000), Volume24h: float64Ptr(9000000),
+			ChangeRates: map[string]float64{"1m": 0.5, "5m": 3.0}},
+		{Symbol: "ETHUSDT", Timestamp: now, LastPrice: float64Ptr(3000), Volume24h: float64Ptr(5000000),
+			ChangeRates: map[string]float64{"1m": 2.0, "5m": -1.0}},
+		{Symbol: "DOGEUSDT", Timestamp: now, LastPrice: float64Ptr(0.1), Volume24h: float64Ptr(800000),
+			ChangeRates: map[string]float64{"1m": 6.0}},
+	}))
+
+	db := setupMonitoringConfigTestDB(t)
+	configDAO := dao.NewMonitoringConfigDAO(db, zap.NewNop())
+	config := &models.MonitoringConfig{
+		Name: "大盘币",
+		Filters: models.MonitoringConfigFilters{
+			TimeWindows: []string{"1m"},
+			MinPrice:    float64Ptr(1),
+		},
+	}
+	require.NoError(t, db.Create(config).Error)
+
+	router := gin.New()
+	RegisterScannerRoutes(router.Group("/api/v1"), index, configDAO, zap.NewNop())
+	return router, config.ID
+}
+
+// scanSymbols 请求扫描器并返回交易对顺序
+func scanSymbols(t *testing.T, router *gin.Engine, query string) ([]string, PaginatedAPIResponse) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/scanner?"+query, nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response PaginatedAPIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	var symbols []string
+	for _, item := range response.Data.([]interface{}) {
+		symbols = append(symbols, item.(map[string]interface{})["symbol"].(string))
+	}
+	return symbols, response
+}
+
+// TestScannerAPI 测试市场扫描器API
+func TestScannerAPI(t *testing.T) {
+	router, configID := setupScannerTestRouter(t)
+
+	t.Run("默认按1分钟变化率排序", func(t *testing.T) {
+		symbols, response := scanSymbols(t, router, "")
+		assert.Equal(t, []string{"DOGEUSDT", "ETHUSDT", "BTCUSDT"}, symbols)
+		assert.Equal(t, 3, response.Pagination.Total)
+	})
+
+	t.Run("指定窗口和升序", func(t *testing.T) {
+		symbols, _ := scanSymbols(t, router, "window=5m&order=asc")
+		assert.Equal(t, []string{"ETHUSDT", "BTCUSDT"}, symbols)
+	})
+
+	t.Run("成交量过滤和分页", func(t *testing.T) {
+		symbols, response := scanSymbols(t, router, "sort_by=volume&min_volume=1000000&page=2&page_size=1")
+		assert.Equal(t, []string{"ETHUSDT"}, symbols)
+		assert.Equal(t, 2, response.Pagination.Total)
+	})
+
+	t.Run("使用监控配置过滤并覆盖交易对", func(t *testing.T) {
+		symbols, _ := scanSymbols(t, router, fmt.Sprintf("config_id=%d", configID))
+		assert.Equal(t, []string{"ETHUSDT", "BTCUSDT"}, symbols)
+
+		symbols, _ = scanSymbols(t, router, fmt.Sprintf("config_id=%d&symbols=btcusdt,dogeusdt", configID))
+		assert.Equal(t, []string{"BTCUSDT"}, symbols)
+	})
+
+	t.Run("监控配置不存在", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/scanner?config_id=999", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("参数验证失败", func(t *testing.T) {
+		for _, query := range []string{
+			"sort_by=market_cap", "window=5x", "order=up", "min_price=-1",
+			"min_price=10&max_price=1", "config_id=abc", "max_age=0s", "page_size=500",
+		} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/v1/scanner?"+query, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// StorageHandler TimescaleDB 存储策略管理处理器
+type StorageHandler struct {
+	storageDAO dao.StoragePolicyDAO
+	logger     *zap.Logger
+}
+
+// NewStorageHandler 创建存储策略管理处理器
+func NewStorageHandler(storageDAO dao.StoragePolicyDAO, logger *zap.Logger) *StorageHandler {
+	return &StorageHandler{
+		storageDAO: storageDAO,
+		logger:     logger,
+	}
+}
+
+// updatePolicyRequest 修改压缩/保留策略的请求，字段为空表示不修改，"never" 表示移除策略
+type updatePolicyRequest struct {
+	CompressAfter *string `json:"compress_after"`
+	DropAfter     *string `json:"drop_after"`
+}
+
+// compressRequest 手动压缩请求
+type compressRequest struct {
+	OlderThan string `json:"older_than" binding:"required"`
+}
+
+// klineRetentionRequest 修改K线周期保留期的请求，"never" 表示永久保留
+type klineRetentionRequest struct {
+	Retention string `json:"retention" binding:"required"`
+}
+
+// ListTables 获取所有超表和连续聚合的策略与空间占用
+func (h *StorageHandler) ListTables(c *gin.Context) {
+	tables, err := h.storageDAO.ListTables(c.Request.Context())
+	if err != nil {
+		h.logger.Error("获取超表列表失败", zap.Error(err))
+		h.errorResponse(c, "获取超表列表失败", err)
+		return
+	}
+
+	SuccessResponse(c, "获取超表列表成功", tables)
+}
+
+// GetTable 获取单个超表或连续聚合的策略与空间占用
+func (h *StorageHandler) GetTable(c *gin.Context) {
+	name := c.Param("name")
+
+	table, err := h.storageDAO.GetTable(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("获取超表失败", zap.String("table", name), zap.Error(err))
+		h.errorResponse(c, "获取超表失败", err)
+		return
+	}
+
+	SuccessResponse(c, "获取超表成功", table)
+}
+
+// ListChunks 获取超表的 chunk 大小和压缩比
+func (h *StorageHandler) ListChunks(c *gin.Context) {
+	name := c.Param("name")
+
+	chunks, err := h.storageDAO.ListChunks(c.Request.Context(), name)
+	if err != nil {
+		h.logger.Error("获取chunk列表失败", zap.String("table", name), zap.Error(err))
+		h.errorResponse(c, "获取chunk列表失败", err)
+		return
+	}
+
+	SuccessResponse(c, "获取chunk列表成功", chunks)
+}
+
+// UpdatePolicy 修改超表或连续聚合的压缩、保留策略
+func (h *StorageHandler) UpdatePolicy(c *gin.Context) {
+	name := c.Param("name")
+
+	var req updatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequestResponse(c, "请求参数无效", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	if req.CompressAfter == nil && req.DropAfter == nil {
+		BadRequestResponse(c, "compress_after 和 drop_after 至少需要一个", nil)
+		return
+	}
+
+	var compressAfter, dropAfter *time.Duration
+	var err error
+	if req.CompressAfter != nil {
+		if compressAfter, err = models.ParseStoragePeriod(*req.CompressAfter); err != nil {
+			BadRequestResponse(c, "compress_after 无效", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	if req.DropAfter != nil {
+		if dropAfter, err = models.ParseStoragePeriod(*req.DropAfter); err != nil {
+			BadRequestResponse(c, "drop_after 无效", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	// klines 混合了所有周期，按 chunk 删除会同时删除长周期K线，保留期只能按周期设置
+	if dropAfter != nil && name == (models.Kline{}).TableName() {
+		BadRequestResponse(c, "klines 的保留期按周期设置，请使用 PUT /api/v1/admin/storage/kline-retention/:granularity", map[string]interface{}{
+			"table": name,
+		})
+		return
+	}
+
+	h.logger.Info("修改存储策略",
+		zap.String("table", name),
+		zap.Stringp("compress_after", req.CompressAfter),
+		zap.Stringp("drop_after", req.DropAfter),
+	)
+
+	ctx := c.Request.Context()
+	if req.CompressAfter != nil {
+		if err := h.storageDAO.SetCompressionPolicy(ctx, name, compressAfter); err != nil {
+			h.logger.Error("修改压缩策略失败", zap.String("table", name), zap.Error(err))
+			h.errorResponse(c, "修改压缩策略失败", err)
+			return
+		}
+	}
+	if req.DropAfter != nil {
+		if err := h.storageDAO.SetRetentionPolicy(ctx, name, dropAfter); err != nil {
+			h.logger.Error("修改保留策略失败", zap.String("table", name), zap.Error(err))
+			h.errorResponse(c, "修改保留策略失败", err)
+			return
+		}
+	}
+
+	table, err := h.storageDAO.GetTable(ctx, name)
+	if err != nil {
+		h.errorResponse(c, "获取超表失败", err)
+		return
+	}
+	SuccessResponse(c, "修改存储策略成功", table)
+}
+
+// Compress 立即压缩早于 older_than 的 chunk
+func (h *StorageHandler) Compress(c *gin.Context) {
+	name := c.Param("name")
+
+	var req compressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequestResponse(c, "请求参数无效", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	olderThan, err := models.ParseStoragePeriod(req.OlderThan)
+	if err != nil || olderThan == nil {
+		BadRequestResponse(c, "older_than 无效", map[string]interface{}{
+			"older_than": req.OlderThan,
+		})
+		return
+	}
+
+	h.logger.Info("手动压缩", zap.String("table", name), zap.String("older_than", req.OlderThan))
+
+	compressed, err := h.storageDAO.CompressChunks(c.Request.Context(), name, *olderThan)
+	if err != nil {
+		h.logger.Error("手动压缩失败", zap.String("table", name), zap.Error(err))
+		h.errorResponse(c, "手动压缩失败", err)
+		return
+	}
+
+	SuccessResponse(c, "压缩完成", map[string]interface{}{
+		"table":             name,
+		"compressed_chunks": compressed,
+	})
+}
+
+// ListKlineRetention 获取K线按周期的保留期
+func (h *StorageHandler) ListKlineRetention(c *gin.Context) {
+	policies, err := h.storageDAO.ListKlineRetention(c.Request.Context())
+	if err != nil {
+		h.logger.Error("获取K线保留期失败", zap.Error(err))
+		h.errorResponse(c, "获取K线保留期失败", err)
+		return
+	}
+
+	SuccessResponse(c, "获取K线保留期成功", policies)
+}
+
+// UpdateKlineRetention 修改K线周期的保留期，保留期按整天计
+func (h *StorageHandler) UpdateKlineRetention(c *gin.Context) {
+	granularity := c.Param("granularity")
+
+	var req klineRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequestResponse(c, "请求参数无效", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	retention, err := models.ParseStoragePeriod(req.Retention)
+	if err == nil && retention != nil && *retention%(24*time.Hour) != 0 {
+		err = errors.New("保留期必须是整天")
+	}
+	if err != nil {
+		BadRequestResponse(c, "retention 无效", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var retentionDays *int
+	if retention != nil {
+		days := int(*retention / (24 * time.Hour))
+		retentionDays = &days
+	}
+
+	h.logger.Info("修改K线保留期", zap.String("granularity", granularity), zap.String("retention", req.Retention))
+
+	if err := h.storageDAO.SetKlineRetention(c.Request.Context(), granularity, retentionDays); err != nil {
+		h.logger.Error("修改K线保留期失败", zap.String("granularity", granularity), zap.Error(err))
+		h.errorResponse(c, "修改K线保留期失败", err)
+		return
+	}
+
+	SuccessResponse(c, "修改K线保留期成功", &models.KlineRetentionPolicy{
+		Granularity:   granularity,
+		RetentionDays: retentionDays,
+		UpdatedAt:     time.Now(),
+	})
+}
+
+// errorResponse 按错误类型返回响应
+func (h *StorageHandler) errorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, database.ErrRecordNotFound):
+		NotFoundResponse(c, "超表或连续聚合不存在", map[string]interface{}{
+			"error": err.Error(),
+		})
+	case errors.Is(err, database.ErrInvalidInput):
+		BadRequestResponse(c, message, map[string]interface{}{
+			"error": err.Error(),
+		})
+	default:
+		InternalErrorResponse(c, message, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// RegisterStorageRoutes 注册存储策略管理路由
+func RegisterStorageRoutes(router *gin.RouterGroup, storageDAO dao.StoragePolicyDAO, logger *zap.Logger) {
+	handler := NewStorageHandler(storageDAO, logger)
+
+	storage := router.Group("/storage")
+	{
+		// 超表和连续聚合的策略、空间占用
+		storage.GET("/tables", handler.ListTables)
+		storage.GET("/tables/:name", handler.GetTable)
+		storage.GET("/tables/:name/chunks", handler.ListChunks)
+
+		// 修改策略、手动压缩
+		storage.PUT("/tables/:name/policy", handler.UpdatePolicy)
+		storage.POST("/tables/:name/compress", handler.Compress)
+
+		// K线按周期保留期
+		storage.GET("/kline-retention", handler.ListKlineRetention)
+		storage.PUT("/kline-retention/:granularity", handler.UpdateKlineRetention)
+	}
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/middleware"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// fakeStoragePolicyDAO 记录策略修改的存储策略DAO
+type fakeStoragePolicyDAO struct {
+	tables         map[string]*models.StorageTable
+	compressAfter  map[string]*time.Duration
+	dropAfter      map[string]*time.Duration
+	compressed     map[string]time.Duration
+	klineRetention map[string]*int
+}
+
+func newFakeStoragePolicyDAO() *fakeStoragePolicyDAO {
+	dropAfter := "14 days"
+	return &fakeStoragePolicyDAO{
+		tables: map[string]*models.StorageTable{
+			"price_ticks": {Name: "price_ticks", Kind: models.StorageKindHypertable, CompressionEnabled: true, DropAfter: &dropAfter},
+			"klines_1d":   {Name: "klines_1d", Kind: models.StorageKindContinuousAggregate},
+		},
+		compressAfter:  make(map[string]*time.Duration),
+		dropAfter:      make(map[string]*time.Duration),
+		compressed:     make(map[string]time.Duration),
+		klineRetention: make(map[string]*int),
+	}
+}
+
+func (f *fakeStoragePolicyDAO) ListTables(ctx context.Context) ([]*models.StorageTable, error) {
+	return []*models.StorageTable{f.tables["price_ticks"], f.tables["klines_1d"]}, nil
+}
+
+func (f *fakeStoragePolicyDAO) GetTable(ctx context.Context, name string) (*models.StorageTable, error) {
+	table, ok := f.tables[name]
+	if !ok {
+		return nil, database.ErrRecordNotFound
+	}
+	return table, nil
+}
+
+func (f *fakeStoragePolicyDAO) ListChunks(ctx context.Context, name string) ([]*models.StorageChunk, error) {
+	if _, ok := f.tables[name]; !ok {
+		return nil, fmt.Errorf("hypertable %s: %w", name, database.ErrRecordNotFound)
+	}
+	return []*models.StorageChunk{{Name: "_hyper_1_1_chunk", IsCompressed: true, CompressionRatio: 8}}, nil
+}
+
+func (f *fakeStoragePolicyDAO) SetCompressionPolicy(ctx context.Context, name string, compressAfter *time.Duration) error {
+	table, ok := f.tables[name]
+	if !ok {
+		return database.ErrRecordNotFound
+	}
+	if compressAfter != nil && !table.CompressionEnabled {
+		return database.NewDatabaseError("compression is not enabled", database.ErrInvalidInput)
+	}
+	f.compressAfter[name] = compressAfter
+	return nil
+}
+
+func (f *fakeStoragePolicyDAO) SetRetentionPolicy(ctx context.Context, name string, dropAfter *time.Duration) error {
+	if dropAfter != nil && name == "klines" {
+		return database.NewDatabaseError("klines mixes all granularities", database.ErrInvalidInput)
+	}
+	if _, ok := f.tables[name]; !ok {
+		return database.ErrRecordNotFound
+	}
+	f.dropAfter[name] = dropAfter
+	return nil
+}
+
+func (f *fakeStoragePolicyDAO) CompressChunks(ctx context.Context, name string, olderThan time.Duration) (int64, error) {
+	if _, ok := f.tables[name]; !ok {
+		return 0, database.ErrRecordNotFound
+	}
+	f.compressed[name] = olderThan
+	return 3, nil
+}
+
+func (f *fakeStoragePolicyDAO) ListKlineRetention(ctx context.Context) ([]*models.KlineRetentionPolicy, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (f *fakeStoragePolicyDAO) SetKlineRetention(ctx context.Context, granularity string, retentionDays *int) error {
+	f.klineRetention[granularity] = retentionDays
+	return nil
+}
+
+func (f *fakeStoragePolicyDAO) PurgeKlines(ctx context.Context, granularity string, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// fakePermissionChecker 只接受指定 Token
+type fakePermissionChecker struct {
+	adminToken string
+}
+
+func (f *fakePermissionChecker) CheckPermission(token string, permission string) (bool, error) {
+	if token == "invalid" {
+		return false, errors.New("Token签名无效")
+	}
+	return token == f.adminToken && permission == "admin", nil
+}
+
+// setupStorageTestRouter 设置存储策略API测试路由
+func setupStorageTestRouter(storageDAO *fakeStoragePolicyDAO) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	RegisterAllRoutes(router.Group("/api/v1"), &RouterConfig{
+		Logger:           zap.NewNop(),
+		StoragePolicyDAO: storageDAO,
+		AdminAuth:        middleware.RequirePermission(&fakePermissionChecker{adminToken: "admin-token"}, "admin"),
+	})
+	return router
+}
+
+func doStorageRequest(router *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestStorageAPI_AdminAuth(t *testing.T) {
+	router := setupStorageTestRouter(newFakeStoragePolicyDAO())
+
+	w := doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables", "invalid", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables", "read-token", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables", "admin-token", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                   `json:"success"`
+		Data    []*models.StorageTable `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, "price_ticks", response.Data[0].Name)
+}
+
+func TestStorageAPI_TablesAndChunks(t *testing.T) {
+	router := setupStorageTestRouter(newFakeStoragePolicyDAO())
+
+	w := doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables/price_ticks", "admin-token", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables/unknown", "admin-token", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables/price_ticks/chunks", "admin-token", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"compression_ratio":8`)
+
+	w = doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/tables/unknown/chunks", "admin-token", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStorageAPI_UpdatePolicy(t *testing.T) {
+	storageDAO := newFakeStoragePolicyDAO()
+	router := setupStorageTestRouter(storageDAO)
+	path := "/api/v1/admin/storage/tables/price_ticks/policy"
+
+	w := doStorageRequest(router, http.MethodPut, path, "admin-token", map[string]string{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doStorageRequest(router, http.MethodPut, path, "admin-token", map[string]string{"drop_after": "14x"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doStorageRequest(router, http.MethodPut, path, "admin-token", map[string]string{
+		"compress_after": "3d",
+		"drop_after":     "2w",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, storageDAO.compressAfter["price_ticks"])
+	assert.Equal(t, 3*24*time.Hour, *storageDAO.compressAfter["price_ticks"])
+	require.NotNil(t, storageDAO.dropAfter["price_ticks"])
+	assert.Equal(t, 14*24*time.Hour, *storageDAO.dropAfter["price_ticks"])
+
+	// never 移除保留策略，未提供的压缩策略不修改
+	storageDAO.compressAfter = make(map[string]*time.Duration)
+	w = doStorageRequest(router, http.MethodPut, path, "admin-token", map[string]string{"drop_after": "never"})
+	require.Equal(t, http.StatusOK, w.Code)
+	_, ok := storageDAO.dropAfter["price_ticks"]
+	assert.True(t, ok)
+	assert.Nil(t, storageDAO.dropAfter["price_ticks"])
+	assert.Empty(t, storageDAO.compressAfter)
+
+	// 未启用压缩的连续聚合
+	w = doStorageRequest(router, http.MethodPut, "/api/v1/admin/storage/tables/klines_1d/policy", "admin-token",
+		map[string]string{"compress_after": "30d"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// klines 的保留期只能按周期设置，请求被拒绝时不修改压缩策略
+	w = doStorageRequest(router, http.MethodPut, "/api/v1/admin/storage/tables/klines/policy", "admin-token",
+		map[string]string{"compress_after": "7d", "drop_after": "30d"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "kline-retention")
+	assert.Empty(t, storageDAO.compressAfter)
+	_, ok = storageDAO.dropAfter["klines"]
+	assert.False(t, ok)
+}
+
+func TestStorageAPI_Compress(t *testing.T) {
+	storageDAO := newFakeStoragePolicyDAO()
+	router := setupStorageTestRouter(storageDAO)
+
+	w := doStorageRequest(router, http.MethodPost, "/api/v1/admin/storage/tables/price_ticks/compress", "admin-token",
+		map[string]string{"older_than": "never"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doStorageRequest(router, http.MethodPost, "/api/v1/admin/storage/tables/price_ticks/compress", "admin-token",
+		map[string]string{"older_than": "2d"})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"compressed_chunks":3`)
+	assert.Equal(t, 48*time.Hour, storageDAO.compressed["price_ticks"])
+}
+
+func TestStorageAPI_KlineRetention(t *testing.T) {
+	storageDAO := newFakeStoragePolicyDAO()
+	router := setupStorageTestRouter(storageDAO)
+
+	w := doStorageRequest(router, http.MethodGet, "/api/v1/admin/storage/kline-retention", "admin-token", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = doStorageRequest(router, http.MethodPut, "/api/v1/admin/storage/kline-retention/1m", "admin-token",
+		map[string]string{"retention": "36h"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doStorageRequest(router, http.MethodPut, "/api/v1/admin/storage/kline-retention/1m", "admin-token",
+		map[string]string{"retention": "4w"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, storageDAO.klineRetention["1m"])
+	assert.Equal(t, 28, *storageDAO.klineRetention["1m"])
+
+	w = doStorageRequest(router, http.MethodPut, "/api/v1/admin/storage/kline-retention/1d", "admin-token",
+		map[string]string{"retention": "never"})
+	require.Equal(t, http.StatusOK, w.Code)
+	_, ok := storageDAO.klineRetention["1d"]
+	assert.True(t, ok)
+	assert.Nil(t, storageDAO.klineRetention["1d"])
+}
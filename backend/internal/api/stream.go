@@ -0,0 +1,13 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterStreamRoutes 注册 SSE 推送路由
+// 频道模型、消息格式和认证方式与 WebSocket 相同，处理函数由 WebSocket 服务器提供
+func RegisterStreamRoutes(router *gin.RouterGroup, handler http.HandlerFunc) {
+	router.GET("/stream", gin.WrapF(handler))
+}
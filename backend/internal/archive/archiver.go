@@ -0,0 +1,331 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// Archiver 把已结束的日期按 表/交易对/天 导出为 Parquet 并记录清单
+// 每天结束 Delay 之后归档，早于保留策略删除数据；同一 表/交易对/天 只归档一次，
+// 中途失败时下一轮从最近归档的日期继续
+type Archiver struct {
+	config     *ArchiverConfig
+	archiveDAO dao.ArchiveDAO
+	store      Store
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// Guard 在每轮和每天归档前调用，返回错误时停止本轮归档
+// 启用选主时用于校验栅栏令牌，避免已失去领导权的实例继续写入归档
+type Guard func(ctx context.Context) error
+
+// NewArchiver 创建归档任务
+func NewArchiver(config *ArchiverConfig, archiveDAO dao.ArchiveDAO, store Store, logger *zap.Logger) (*Archiver, error) {
+	if config == nil {
+		config = DefaultArchiverConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("归档配置无效: %w", err)
+	}
+	if archiveDAO == nil || store == nil {
+		return nil, fmt.Errorf("归档清单和归档存储不能为空")
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Archiver{
+		config:     config,
+		archiveDAO: archiveDAO,
+		store:      store,
+		logger:     logger,
+		now:        time.Now,
+	}, nil
+}
+
+// CheckRetention 检查归档表当前的保留期（price_ticks 的保留策略和 klines 各周期的保留天数），
+// 返回保留期不长于归档延迟加一天（数据会在归档之前被删除）的表和周期
+func CheckRetention(ctx context.Context, storageDAO dao.StoragePolicyDAO, retention *dao.ArchiveRetention) []error {
+	var errs []error
+	if slices.Contains(retention.Tables, models.ArchiveTablePriceTicks) {
+		table, err := storageDAO.GetTable(ctx, models.ArchiveTablePriceTicks)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("获取 %s 的保留策略失败: %w", models.ArchiveTablePriceTicks, err))
+		case table.DropAfter != nil:
+			if err := checkRetentionInterval(retention, models.ArchiveTablePriceTicks, *table.DropAfter); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if slices.Contains(retention.Tables, models.ArchiveTableKlines) {
+		policies, err := storageDAO.ListKlineRetention(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("获取K线保留期失败: %w", err))
+		}
+		for _, policy := range policies {
+			if policy.RetentionDays == nil {
+				continue
+			}
+			days := time.Duration(*policy.RetentionDays) * 24 * time.Hour
+			if err := retention.Check(models.ArchiveTableKlines, days); err != nil {
+				errs = append(errs, fmt.Errorf("K线周期 %s: %w", policy.Granularity, err))
+			}
+		}
+	}
+	return errs
+}
+
+// checkRetentionInterval 检查以 PostgreSQL interval 表示的保留期
+func checkRetentionInterval(retention *dao.ArchiveRetention, table, interval string) error {
+	dropAfter, err := dao.ParsePostgresInterval(interval)
+	if err != nil {
+		return fmt.Errorf("解析 %s 的保留策略失败: %w", table, err)
+	}
+	return retention.Check(table, dropAfter)
+}
+
+// Run 立即归档一轮，然后每个检查间隔归档一轮，直到 ctx 取消
+// guard 不为空时每轮和每天归档前校验，校验失败时跳过本轮
+func (a *Archiver) Run(ctx context.Context, guard Guard) {
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := a.archiveOnce(ctx, guard)
+		if err != nil && ctx.Err() == nil {
+			a.logger.Warn("归档过期数据失败", zap.Error(err))
+		}
+		if result.Files > 0 {
+			a.logger.Info("已归档过期数据",
+				zap.Int("files", result.Files),
+				zap.Int64("rows", result.Rows),
+				zap.Int64("bytes", result.Bytes),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ArchiveOnce 归档所有已到期且未归档的日期，单个表失败不影响其他表
+func (a *Archiver) ArchiveOnce(ctx context.Context) (ArchiveResult, error) {
+	return a.archiveOnce(ctx, nil)
+}
+
+// archiveOnce 归档一轮，guard 校验失败时停止本轮
+func (a *Archiver) archiveOnce(ctx context.Context, guard Guard) (ArchiveResult, error) {
+	var total ArchiveResult
+	var firstErr error
+	for _, table := range a.config.Tables {
+		if err := a.check(ctx, guard); err != nil {
+			return total, err
+		}
+		result, err := a.archiveTable(ctx, table, guard)
+		total.Files += result.Files
+		total.Rows += result.Rows
+		total.Bytes += result.Bytes
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("归档 %s 失败: %w", table, err)
+			}
+			a.logger.Warn("归档表失败", zap.String("table", table), zap.Error(err))
+		}
+	}
+	return total, firstErr
+}
+
+// archiveTable 从最近归档的日期（没有归档时从最早的数据）开始，逐天归档到截止日期之前
+func (a *Archiver) archiveTable(ctx context.Context, table string, guard Guard) (ArchiveResult, error) {
+	var result ArchiveResult
+
+	// 结束时间早于 now-Delay 的日期才归档
+	limit := models.ArchiveDay(a.now().Add(-a.config.Delay))
+
+	latest, err := a.archiveDAO.LatestManifestDay(ctx, table)
+	if err != nil {
+		return result, err
+	}
+	var day time.Time
+	if latest != nil {
+		// 最近归档的一天可能只完成了部分交易对，重新检查
+		day = *latest
+	} else {
+		earliest, err := a.archiveDAO.EarliestTimestamp(ctx, table)
+		if err != nil {
+			return result, err
+		}
+		if earliest == nil {
+			return result, nil
+		}
+		day = models.ArchiveDay(*earliest)
+	}
+
+	for ; day.Before(limit); day = day.AddDate(0, 0, 1) {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := a.check(ctx, guard); err != nil {
+			return result, err
+		}
+		dayResult, err := a.ArchiveDay(ctx, table, day)
+		result.Files += dayResult.Files
+		result.Rows += dayResult.Rows
+		result.Bytes += dayResult.Bytes
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// check 调用 guard，guard 为空时总是允许
+func (a *Archiver) check(ctx context.Context, guard Guard) error {
+	if guard == nil {
+		return nil
+	}
+	if err := guard(ctx); err != nil {
+		return fmt.Errorf("归档校验失败: %w", err)
+	}
+	return nil
+}
+
+// ArchiveDay 归档表在某天的数据，已归档的交易对跳过
+func (a *Archiver) ArchiveDay(ctx context.Context, table string, day time.Time) (ArchiveResult, error) {
+	var result ArchiveResult
+	day = models.ArchiveDay(day)
+	end := day.AddDate(0, 0, 1)
+
+	symbols, err := a.archiveDAO.ListSymbols(ctx, table, day, end)
+	if err != nil {
+		return result, err
+	}
+	if len(symbols) == 0 {
+		return result, nil
+	}
+
+	manifests, err := a.archiveDAO.ListManifests(ctx, table, "", day, day)
+	if err != nil {
+		return result, err
+	}
+	archived := make(map[string]bool, len(manifests))
+	for _, manifest := range manifests {
+		archived[manifest.Symbol] = true
+	}
+
+	for _, symbol := range symbols {
+		if archived[symbol] {
+			continue
+		}
+		manifest, err := a.archiveSymbolDay(ctx, table, symbol, day)
+		if err != nil {
+			return result, fmt.Errorf("%s %s: %w", symbol, day.Format("2006-01-02"), err)
+		}
+		if manifest == nil {
+			continue
+		}
+		result.Files++
+		result.Rows += manifest.RowCount
+		result.Bytes += manifest.SizeBytes
+	}
+	return result, nil
+}
+
+// archiveSymbolDay 把交易对一天的数据写入临时 Parquet 文件，上传后记录清单
+// 先上传后写清单：上传后失败时下一轮会覆盖同一路径重新归档
+func (a *Archiver) archiveSymbolDay(ctx context.Context, table, symbol string, day time.Time) (*models.ArchiveManifest, error) {
+	tmp, err := os.CreateTemp(a.config.TempDir, "archive-*.parquet")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	end := day.AddDate(0, 0, 1)
+	var rows int64
+	var minTs, maxTs time.Time
+	switch table {
+	case models.ArchiveTablePriceTicks:
+		writer := NewRecordWriter[TickRecord](tmp)
+		err = a.archiveDAO.StreamTicks(ctx, symbol, day, end, func(tick *models.PriceTick) error {
+			return writer.Write(NewTickRecord(tick), tick.Timestamp)
+		})
+		if err == nil {
+			err = writer.Close()
+		}
+		rows = writer.Rows()
+		minTs, maxTs = writer.TimeRange()
+	case models.ArchiveTableKlines:
+		writer := NewRecordWriter[KlineRecord](tmp)
+		err = a.archiveDAO.StreamKlines(ctx, symbol, "", day, end, func(kline *models.Kline) error {
+			return writer.Write(NewKlineRecord(kline), kline.Timestamp)
+		})
+		if err == nil {
+			err = writer.Close()
+		}
+		rows = writer.Rows()
+		minTs, maxTs = writer.TimeRange()
+	default:
+		return nil, fmt.Errorf("不支持归档的表: %s", table)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, nil
+	}
+
+	size, err := tmp.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("读取临时文件大小失败: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("读取临时文件失败: %w", err)
+	}
+
+	key := ObjectKey(table, symbol, day)
+	if err := a.store.Put(ctx, key, tmp, size); err != nil {
+		return nil, err
+	}
+
+	manifest := &models.ArchiveManifest{
+		SourceTable:  table,
+		Symbol:       symbol,
+		Day:          day,
+		Path:         key,
+		RowCount:     rows,
+		MinTimestamp: minTs,
+		MaxTimestamp: maxTs,
+		SizeBytes:    size,
+		CreatedAt:    a.now(),
+	}
+	if err := a.archiveDAO.SaveManifest(ctx, manifest); err != nil {
+		return nil, err
+	}
+
+	a.logger.Debug("已归档",
+		zap.String("table", table),
+		zap.String("symbol", symbol),
+		zap.String("uri", a.store.URI(key)),
+		zap.Int64("rows", rows),
+		zap.Int64("bytes", size),
+	)
+	return manifest, nil
+}
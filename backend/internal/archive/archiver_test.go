@@ -0,0 +1,311 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// setupArchiveTestDB 创建测试数据库，使用文件数据库保证流式读取和写入使用同一份数据
+func setupArchiveTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "archive.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, db.AutoMigrate(&models.PriceTick{}, &models.Kline{}, &models.ArchiveManifest{}))
+	return db
+}
+
+func createArchiveTick(symbol string, ts time.Time, price string) *models.PriceTick {
+	return &models.PriceTick{
+		Symbol:    symbol,
+		Timestamp: ts,
+		LastPrice: decimal.RequireFromString(price),
+		BidPrice:  models.DecimalPtr(decimal.RequireFromString(price)),
+	}
+}
+
+func createArchiveKline(symbol, granularity string, ts time.Time, price string) *models.Kline {
+	p := decimal.RequireFromString(price)
+	return &models.Kline{
+		Symbol:      symbol,
+		Timestamp:   ts,
+		Granularity: granularity,
+		Open:        p,
+		High:        p,
+		Low:         p,
+		Close:       p,
+		BaseVolume:  decimal.NewFromInt(10),
+		QuoteVolume: p.Mul(decimal.NewFromInt(10)),
+	}
+}
+
+// archiveFixture 两个交易对各有两天的旧数据和一条最近的数据
+type archiveFixture struct {
+	db       *gorm.DB
+	dao      dao.ArchiveDAO
+	store    *LocalStore
+	archiver *Archiver
+	day1     time.Time
+	day2     time.Time
+	now      time.Time
+}
+
+func newArchiveFixture(t *testing.T) *archiveFixture {
+	db := setupArchiveTestDB(t)
+	archiveDAO := dao.NewArchiveDAO(db, zap.NewNop())
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Date(2024, 2, 20, 12, 0, 0, 0, time.UTC)
+	day1 := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	config := DefaultArchiverConfig()
+	config.TempDir = t.TempDir()
+	archiver, err := NewArchiver(config, archiveDAO, store, zap.NewNop())
+	require.NoError(t, err)
+	archiver.now = func() time.Time { return now }
+
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT"} {
+		for _, day := range []time.Time{day1, day2} {
+			require.NoError(t, db.Create(createArchiveTick(symbol, day.Add(time.Hour), "100.5")).Error)
+			require.NoError(t, db.Create(createArchiveTick(symbol, day.Add(23*time.Hour), "101.25")).Error)
+			require.NoError(t, db.Create(createArchiveKline(symbol, "1m", day.Add(time.Hour), "100")).Error)
+			require.NoError(t, db.Create(createArchiveKline(symbol, "1h", day.Add(time.Hour), "100")).Error)
+		}
+		// 未到归档时间
+		require.NoError(t, db.Create(createArchiveTick(symbol, now.Add(-time.Hour), "110")).Error)
+		require.NoError(t, db.Create(createArchiveKline(symbol, "1m", now.Add(-time.Hour), "110")).Error)
+	}
+
+	return &archiveFixture{
+		db:       db,
+		dao:      archiveDAO,
+		store:    store,
+		archiver: archiver,
+		day1:     day1,
+		day2:     day2,
+		now:      now,
+	}
+}
+
+func TestNewArchiver_Validation(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	archiveDAO := dao.NewArchiveDAO(setupArchiveTestDB(t), nil)
+
+	_, err = NewArchiver(&ArchiverConfig{Tables: []string{"symbols"}, Interval: time.Hour}, archiveDAO, store, nil)
+	assert.Error(t, err)
+	_, err = NewArchiver(&ArchiverConfig{Tables: []string{models.ArchiveTableKlines}}, archiveDAO, store, nil)
+	assert.Error(t, err)
+	_, err = NewArchiver(nil, nil, store, nil)
+	assert.Error(t, err)
+
+	archiver, err := NewArchiver(nil, archiveDAO, store, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 8*24*time.Hour, archiver.config.Delay)
+}
+
+func TestArchiver_ArchiveOnce(t *testing.T) {
+	f := newArchiveFixture(t)
+	ctx := context.Background()
+
+	result, err := f.archiver.ArchiveOnce(ctx)
+	require.NoError(t, err)
+	// 2张表 × 2个交易对 × 2天
+	assert.Equal(t, 8, result.Files)
+	assert.Equal(t, int64(16), result.Rows)
+	assert.Greater(t, result.Bytes, int64(0))
+
+	manifests, err := f.dao.ListManifests(ctx, models.ArchiveTablePriceTicks, "BTCUSDT", f.day1, f.now)
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	assert.True(t, f.day1.Equal(manifests[0].Day))
+	assert.Equal(t, ObjectKey(models.ArchiveTablePriceTicks, "BTCUSDT", f.day1), manifests[0].Path)
+	assert.Equal(t, int64(2), manifests[0].RowCount)
+	assert.True(t, f.day1.Add(time.Hour).Equal(manifests[0].MinTimestamp))
+	assert.True(t, f.day1.Add(23*time.Hour).Equal(manifests[0].MaxTimestamp))
+
+	// 已归档的日期不重复归档
+	result, err = f.archiver.ArchiveOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Files)
+
+	// 新到期的一天
+	f.archiver.now = func() time.Time { return f.now.AddDate(0, 0, 9) }
+	result, err = f.archiver.ArchiveOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Files)
+}
+
+func TestArchiver_Guard(t *testing.T) {
+	f := newArchiveFixture(t)
+	ctx := context.Background()
+
+	// 第一天归档后失去领导权，后续日期不再归档
+	errNotLeader := errors.New("not leader")
+	calls := 0
+	guard := func(context.Context) error {
+		calls++
+		if calls > 2 {
+			return errNotLeader
+		}
+		return nil
+	}
+	result, err := f.archiver.archiveOnce(ctx, guard)
+	assert.ErrorIs(t, err, errNotLeader)
+	assert.Equal(t, 2, result.Files)
+
+	manifests, err := f.dao.ListManifests(ctx, models.ArchiveTablePriceTicks, "", f.day1, f.now)
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	for _, manifest := range manifests {
+		assert.True(t, f.day1.Equal(manifest.Day))
+	}
+	manifests, err = f.dao.ListManifests(ctx, models.ArchiveTableKlines, "", f.day1, f.now)
+	require.NoError(t, err)
+	assert.Empty(t, manifests)
+}
+
+// fakeRetentionDAO 只实现检查保留期需要的方法
+type fakeRetentionDAO struct {
+	dao.StoragePolicyDAO
+	dropAfter *string
+	policies  []*models.KlineRetentionPolicy
+}
+
+func (f *fakeRetentionDAO) GetTable(ctx context.Context, name string) (*models.StorageTable, error) {
+	return &models.StorageTable{Name: name, DropAfter: f.dropAfter}, nil
+}
+
+func (f *fakeRetentionDAO) ListKlineRetention(ctx context.Context) ([]*models.KlineRetentionPolicy, error) {
+	return f.policies, nil
+}
+
+func TestCheckRetention(t *testing.T) {
+	ctx := context.Background()
+	retention := DefaultArchiverConfig().Retention()
+	days := func(n int) *int { return &n }
+
+	storageDAO := &fakeRetentionDAO{
+		dropAfter: stringPtr("14 days"),
+		policies: []*models.KlineRetentionPolicy{
+			{Granularity: "1m", RetentionDays: days(30)},
+			{Granularity: "1d"},
+		},
+	}
+	assert.Empty(t, CheckRetention(ctx, storageDAO, retention))
+
+	// price_ticks 和 1m K线都会在归档之前被删除
+	storageDAO.dropAfter = stringPtr("7 days")
+	storageDAO.policies[0].RetentionDays = days(9)
+	errs := CheckRetention(ctx, storageDAO, retention)
+	require.Len(t, errs, 2)
+	assert.ErrorContains(t, errs[0], models.ArchiveTablePriceTicks)
+	assert.ErrorContains(t, errs[1], "1m")
+
+	// 只归档 klines 时不检查 price_ticks
+	retention.Tables = []string{models.ArchiveTableKlines}
+	assert.Len(t, CheckRetention(ctx, storageDAO, retention), 1)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestArchiver_ResumePartialDay(t *testing.T) {
+	f := newArchiveFixture(t)
+	ctx := context.Background()
+
+	// 模拟上一轮只归档了 BTCUSDT
+	_, err := f.archiver.archiveSymbolDay(ctx, models.ArchiveTablePriceTicks, "BTCUSDT", f.day1)
+	require.NoError(t, err)
+
+	result, err := f.archiver.ArchiveDay(ctx, models.ArchiveTablePriceTicks, f.day1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Files)
+
+	manifests, err := f.dao.ListManifests(ctx, models.ArchiveTablePriceTicks, "", f.day1, f.day1)
+	require.NoError(t, err)
+	assert.Len(t, manifests, 2)
+}
+
+func TestHistoryReader_ArchivedAndDatabase(t *testing.T) {
+	f := newArchiveFixture(t)
+	ctx := context.Background()
+
+	_, err := f.archiver.ArchiveOnce(ctx)
+	require.NoError(t, err)
+
+	// 模拟保留策略删除已归档的数据
+	require.NoError(t, f.db.Where("timestamp < ?", f.day2.AddDate(0, 0, 1)).Delete(&models.PriceTick{}).Error)
+	require.NoError(t, f.db.Where("timestamp < ?", f.day2.AddDate(0, 0, 1)).Delete(&models.Kline{}).Error)
+
+	reader := NewHistoryReader(f.dao, f.store, zap.NewNop())
+
+	ticks, err := reader.Ticks(ctx, "BTCUSDT", f.day1, f.now)
+	require.NoError(t, err)
+	require.Len(t, ticks, 5)
+	for i := 1; i < len(ticks); i++ {
+		assert.True(t, ticks[i-1].Timestamp.Before(ticks[i].Timestamp))
+	}
+	assert.Equal(t, "100.5", ticks[0].LastPrice.String())
+	require.NotNil(t, ticks[0].BidPrice)
+	assert.Equal(t, "100.5", ticks[0].BidPrice.String())
+	assert.Equal(t, "110", ticks[4].LastPrice.String())
+
+	// 范围从天中间开始和结束
+	ticks, err = reader.Ticks(ctx, "BTCUSDT", f.day1.Add(2*time.Hour), f.day2.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, ticks, 2)
+	assert.True(t, f.day1.Add(23*time.Hour).Equal(ticks[0].Timestamp))
+	assert.True(t, f.day2.Add(time.Hour).Equal(ticks[1].Timestamp))
+
+	klines, err := reader.Klines(ctx, "ETHUSDT", "1h", f.day1, f.now)
+	require.NoError(t, err)
+	require.Len(t, klines, 2)
+	assert.Equal(t, "1h", klines[0].Granularity)
+
+	klines, err = reader.Klines(ctx, "ETHUSDT", "", f.day1, f.now)
+	require.NoError(t, err)
+	assert.Len(t, klines, 5)
+
+	_, err = reader.Ticks(ctx, "", f.day1, f.now)
+	assert.Error(t, err)
+	_, err = reader.Ticks(ctx, "BTCUSDT", f.now, f.day1)
+	assert.Error(t, err)
+}
+
+func TestHistoryReader_MissingArchiveFile(t *testing.T) {
+	f := newArchiveFixture(t)
+	ctx := context.Background()
+
+	require.NoError(t, f.dao.SaveManifest(ctx, &models.ArchiveManifest{
+		SourceTable:  models.ArchiveTablePriceTicks,
+		Symbol:       "BTCUSDT",
+		Day:          f.day1,
+		Path:         ObjectKey(models.ArchiveTablePriceTicks, "BTCUSDT", f.day1),
+		RowCount:     2,
+		MinTimestamp: f.day1,
+		MaxTimestamp: f.day1,
+	}))
+
+	reader := NewHistoryReader(f.dao, f.store, nil)
+	_, err := reader.Ticks(ctx, "BTCUSDT", f.day1, f.day2)
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
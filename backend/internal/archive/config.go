@@ -0,0 +1,49 @@
+package archive
+
+import (
+	"fmt"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
+)
+
+// 归档存储类型
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// NewStore 按配置创建归档存储
+func NewStore(cfg *config.ArchiveConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", BackendLocal:
+		return NewLocalStore(cfg.Path)
+	case BackendS3:
+		return NewS3Store(&S3StoreConfig{
+			Endpoint:  cfg.S3.Endpoint,
+			Bucket:    cfg.S3.Bucket,
+			Prefix:    cfg.S3.Prefix,
+			Region:    cfg.S3.Region,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			UseSSL:    cfg.S3.UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("不支持的归档存储类型: %s", cfg.Backend)
+	}
+}
+
+// NewArchiverConfig 把配置文件中的归档配置转换为归档任务配置，未设置的项使用默认值
+func NewArchiverConfig(cfg *config.ArchiveConfig) *ArchiverConfig {
+	archiverConfig := DefaultArchiverConfig()
+	if len(cfg.Tables) > 0 {
+		archiverConfig.Tables = cfg.Tables
+	}
+	if cfg.Delay > 0 {
+		archiverConfig.Delay = cfg.Delay
+	}
+	if cfg.Interval > 0 {
+		archiverConfig.Interval = cfg.Interval
+	}
+	archiverConfig.TempDir = cfg.TempDir
+	return archiverConfig
+}
@@ -0,0 +1,288 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/shopspring/decimal"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+const (
+	// parquetBatchSize 写入和读取 Parquet 的批大小
+	parquetBatchSize = 1024
+
+	// parquetRowGroupSize 每个行组的最大行数，写入器只在内存中缓冲当前行组，
+	// 导出任意长的时间范围时内存占用不随行数增长
+	parquetRowGroupSize = 100000
+)
+
+// 价格和数量按字符串存储，与数据库的 decimal 列一样不丢失精度
+
+// TickRecord price_ticks 的 Parquet 行
+type TickRecord struct {
+	Symbol            string    `parquet:"symbol,dict"`
+	Timestamp         time.Time `parquet:"timestamp,timestamp(microsecond)"`
+	LastPrice         string    `parquet:"last_price"`
+	AskPrice          *string   `parquet:"ask_price,optional"`
+	BidPrice          *string   `parquet:"bid_price,optional"`
+	BidSize           *string   `parquet:"bid_size,optional"`
+	AskSize           *string   `parquet:"ask_size,optional"`
+	High24h           *string   `parquet:"high_24h,optional"`
+	Low24h            *string   `parquet:"low_24h,optional"`
+	Change24h         *float64  `parquet:"change_24h,optional"`
+	BaseVolume        *string   `parquet:"base_volume,optional"`
+	QuoteVolume       *string   `parquet:"quote_volume,optional"`
+	UsdtVolume        *string   `parquet:"usdt_volume,optional"`
+	OpenUtc           *string   `parquet:"open_utc,optional"`
+	ChangeUtc24h      *float64  `parquet:"change_utc_24h,optional"`
+	IndexPrice        *string   `parquet:"index_price,optional"`
+	FundingRate       *float64  `parquet:"funding_rate,optional"`
+	HoldingAmount     *string   `parquet:"holding_amount,optional"`
+	Open24h           *string   `parquet:"open_24h,optional"`
+	MarkPrice         *string   `parquet:"mark_price,optional"`
+	DeliveryStartTime *int64    `parquet:"delivery_start_time,optional"`
+	DeliveryTime      *int64    `parquet:"delivery_time,optional"`
+	DeliveryStatus    string    `parquet:"delivery_status,dict"`
+}
+
+// KlineRecord klines 的 Parquet 行
+type KlineRecord struct {
+	Symbol      string    `parquet:"symbol,dict"`
+	Timestamp   time.Time `parquet:"timestamp,timestamp(microsecond)"`
+	Granularity string    `parquet:"granularity,dict"`
+	Open        string    `parquet:"open"`
+	High        string    `parquet:"high"`
+	Low         string    `parquet:"low"`
+	Close       string    `parquet:"close"`
+	BaseVolume  string    `parquet:"base_volume"`
+	QuoteVolume string    `parquet:"quote_volume"`
+}
+
+// NewTickRecord 转换为 Parquet 行
+func NewTickRecord(tick *models.PriceTick) TickRecord {
+	return TickRecord{
+		Symbol:            tick.Symbol,
+		Timestamp:         tick.Timestamp.UTC(),
+		LastPrice:         tick.LastPrice.String(),
+		AskPrice:          decimalString(tick.AskPrice),
+		BidPrice:          decimalString(tick.BidPrice),
+		BidSize:           decimalString(tick.BidSize),
+		AskSize:           decimalString(tick.AskSize),
+		High24h:           decimalString(tick.High24h),
+		Low24h:            decimalString(tick.Low24h),
+		Change24h:         tick.Change24h,
+		BaseVolume:        decimalString(tick.BaseVolume),
+		QuoteVolume:       decimalString(tick.QuoteVolume),
+		UsdtVolume:        decimalString(tick.UsdtVolume),
+		OpenUtc:           decimalString(tick.OpenUtc),
+		ChangeUtc24h:      tick.ChangeUtc24h,
+		IndexPrice:        decimalString(tick.IndexPrice),
+		FundingRate:       tick.FundingRate,
+		HoldingAmount:     decimalString(tick.HoldingAmount),
+		Open24h:           decimalString(tick.Open24h),
+		MarkPrice:         decimalString(tick.MarkPrice),
+		DeliveryStartTime: tick.DeliveryStartTime,
+		DeliveryTime:      tick.DeliveryTime,
+		DeliveryStatus:    tick.DeliveryStatus,
+	}
+}
+
+// ToModel 转换为价格数据模型
+func (r *TickRecord) ToModel() (*models.PriceTick, error) {
+	p := decimalParser{}
+	tick := &models.PriceTick{
+		Symbol:            r.Symbol,
+		Timestamp:         r.Timestamp,
+		LastPrice:         p.required(r.LastPrice),
+		AskPrice:          p.optional(r.AskPrice),
+		BidPrice:          p.optional(r.BidPrice),
+		BidSize:           p.optional(r.BidSize),
+		AskSize:           p.optional(r.AskSize),
+		High24h:           p.optional(r.High24h),
+		Low24h:            p.optional(r.Low24h),
+		Change24h:         r.Change24h,
+		BaseVolume:        p.optional(r.BaseVolume),
+		QuoteVolume:       p.optional(r.QuoteVolume),
+		UsdtVolume:        p.optional(r.UsdtVolume),
+		OpenUtc:           p.optional(r.OpenUtc),
+		ChangeUtc24h:      r.ChangeUtc24h,
+		IndexPrice:        p.optional(r.IndexPrice),
+		FundingRate:       r.FundingRate,
+		HoldingAmount:     p.optional(r.HoldingAmount),
+		Open24h:           p.optional(r.Open24h),
+		MarkPrice:         p.optional(r.MarkPrice),
+		DeliveryStartTime: r.DeliveryStartTime,
+		DeliveryTime:      r.DeliveryTime,
+		DeliveryStatus:    r.DeliveryStatus,
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("解析归档价格数据失败 %s %s: %w", r.Symbol, r.Timestamp.Format(time.RFC3339), p.err)
+	}
+	return tick, nil
+}
+
+// NewKlineRecord 转换为 Parquet 行
+func NewKlineRecord(kline *models.Kline) KlineRecord {
+	return KlineRecord{
+		Symbol:      kline.Symbol,
+		Timestamp:   kline.Timestamp.UTC(),
+		Granularity: kline.Granularity,
+		Open:        kline.Open.String(),
+		High:        kline.High.String(),
+		Low:         kline.Low.String(),
+		Close:       kline.Close.String(),
+		BaseVolume:  kline.BaseVolume.String(),
+		QuoteVolume: kline.QuoteVolume.String(),
+	}
+}
+
+// ToModel 转换为K线模型
+func (r *KlineRecord) ToModel() (*models.Kline, error) {
+	p := decimalParser{}
+	kline := &models.Kline{
+		Symbol:      r.Symbol,
+		Timestamp:   r.Timestamp,
+		Granularity: r.Granularity,
+		Open:        p.required(r.Open),
+		High:        p.required(r.High),
+		Low:         p.required(r.Low),
+		Close:       p.required(r.Close),
+		BaseVolume:  p.required(r.BaseVolume),
+		QuoteVolume: p.required(r.QuoteVolume),
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("解析归档K线失败 %s %s %s: %w",
+			r.Symbol, r.Granularity, r.Timestamp.Format(time.RFC3339), p.err)
+	}
+	return kline, nil
+}
+
+// RecordWriter 分批写入 Parquet 行，记录行数和时间范围
+type RecordWriter[T any] struct {
+	writer *parquet.GenericWriter[T]
+	buffer []T
+	rows   int64
+	minTs  time.Time
+	maxTs  time.Time
+}
+
+// NewRecordWriter 创建写入 w 的 Parquet 写入器（zstd 压缩）
+func NewRecordWriter[T any](w io.Writer) *RecordWriter[T] {
+	return &RecordWriter[T]{
+		writer: parquet.NewGenericWriter[T](w,
+			parquet.Compression(&parquet.Zstd),
+			parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+		),
+		buffer: make([]T, 0, parquetBatchSize),
+	}
+}
+
+// Write 追加一行，ts 为该行的时间戳
+func (w *RecordWriter[T]) Write(record T, ts time.Time) error {
+	w.buffer = append(w.buffer, record)
+	if w.rows == 0 || ts.Before(w.minTs) {
+		w.minTs = ts
+	}
+	if w.rows == 0 || ts.After(w.maxTs) {
+		w.maxTs = ts
+	}
+	w.rows++
+
+	if len(w.buffer) >= parquetBatchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close 写入剩余的行和文件元数据
+func (w *RecordWriter[T]) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.writer.Close(); err != nil {
+		return fmt.Errorf("写入 Parquet 元数据失败: %w", err)
+	}
+	return nil
+}
+
+// Rows 已写入的行数
+func (w *RecordWriter[T]) Rows() int64 {
+	return w.rows
+}
+
+// TimeRange 已写入行的最早和最晚时间
+func (w *RecordWriter[T]) TimeRange() (time.Time, time.Time) {
+	return w.minTs, w.maxTs
+}
+
+func (w *RecordWriter[T]) flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+	if _, err := w.writer.Write(w.buffer); err != nil {
+		return fmt.Errorf("写入 Parquet 失败: %w", err)
+	}
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+// ReadRecords 按文件顺序逐行读取 Parquet 文件
+func ReadRecords[T any](file File, fn func(*T) error) error {
+	parquetFile, err := parquet.OpenFile(file, file.Size())
+	if err != nil {
+		return fmt.Errorf("打开 Parquet 文件失败: %w", err)
+	}
+
+	reader := parquet.NewGenericReader[T](parquetFile)
+	defer reader.Close()
+
+	batch := make([]T, parquetBatchSize)
+	for {
+		n, err := reader.Read(batch)
+		for i := 0; i < n; i++ {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取 Parquet 失败: %w", err)
+		}
+	}
+}
+
+// decimalString 把可选的 decimal 转换为字符串
+func decimalString(d *decimal.Decimal) *string {
+	if d == nil {
+		return nil
+	}
+	s := d.String()
+	return &s
+}
+
+// decimalParser 解析多个 decimal 字段，记录第一个错误
+type decimalParser struct {
+	err error
+}
+
+func (p *decimalParser) required(s string) decimal.Decimal {
+	d, err := decimal.NewFromString(s)
+	if err != nil && p.err == nil {
+		p.err = err
+	}
+	return d
+}
+
+func (p *decimalParser) optional(s *string) *decimal.Decimal {
+	if s == nil {
+		return nil
+	}
+	return models.DecimalPtr(p.required(*s))
+}
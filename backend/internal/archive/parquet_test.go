@@ -0,0 +1,116 @@
+package archive
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// bytesFile 内存中的归档文件
+type bytesFile struct {
+	*bytes.Reader
+}
+
+func (f *bytesFile) Close() error {
+	return nil
+}
+
+func newBytesFile(data []byte) *bytesFile {
+	return &bytesFile{Reader: bytes.NewReader(data)}
+}
+
+func TestTickRecord_RoundTrip(t *testing.T) {
+	change := 1.25
+	status := int64(1700000000000)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	tick := &models.PriceTick{
+		Symbol:            "BTCUSDT",
+		Timestamp:         ts,
+		LastPrice:         decimal.RequireFromString("42000.12345678"),
+		AskPrice:          models.DecimalPtr(decimal.RequireFromString("42000.5")),
+		BaseVolume:        models.DecimalPtr(decimal.RequireFromString("123456789012.12345678")),
+		Change24h:         &change,
+		DeliveryStartTime: &status,
+		DeliveryStatus:    "normal",
+	}
+
+	var buf bytes.Buffer
+	writer := NewRecordWriter[TickRecord](&buf)
+	require.NoError(t, writer.Write(NewTickRecord(tick), tick.Timestamp))
+	require.NoError(t, writer.Close())
+	assert.Equal(t, int64(1), writer.Rows())
+
+	var got []*models.PriceTick
+	err := ReadRecords(newBytesFile(buf.Bytes()), func(record *TickRecord) error {
+		model, err := record.ToModel()
+		if err != nil {
+			return err
+		}
+		got = append(got, model)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	assert.True(t, ts.Equal(got[0].Timestamp))
+	assert.Equal(t, "42000.12345678", got[0].LastPrice.String())
+	require.NotNil(t, got[0].AskPrice)
+	assert.Equal(t, "42000.5", got[0].AskPrice.String())
+	require.NotNil(t, got[0].BaseVolume)
+	assert.Equal(t, "123456789012.12345678", got[0].BaseVolume.String())
+	assert.Nil(t, got[0].BidPrice)
+	assert.Equal(t, &change, got[0].Change24h)
+	assert.Equal(t, &status, got[0].DeliveryStartTime)
+	assert.Nil(t, got[0].DeliveryTime)
+	assert.Equal(t, "normal", got[0].DeliveryStatus)
+}
+
+func TestKlineRecord_RoundTrip(t *testing.T) {
+	base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	writer := NewRecordWriter[KlineRecord](&buf)
+	// 超过一个批次，验证分批写入和读取
+	for i := 0; i < parquetBatchSize+10; i++ {
+		kline := createArchiveKline("ETHUSDT", "1m", base.Add(time.Duration(i)*time.Minute), "2300.1")
+		require.NoError(t, writer.Write(NewKlineRecord(kline), kline.Timestamp))
+	}
+	require.NoError(t, writer.Close())
+
+	minTs, maxTs := writer.TimeRange()
+	assert.Equal(t, base, minTs)
+	assert.Equal(t, base.Add(time.Duration(parquetBatchSize+9)*time.Minute), maxTs)
+
+	count := 0
+	err := ReadRecords(newBytesFile(buf.Bytes()), func(record *KlineRecord) error {
+		kline, err := record.ToModel()
+		if err != nil {
+			return err
+		}
+		assert.True(t, base.Add(time.Duration(count)*time.Minute).Equal(kline.Timestamp))
+		assert.Equal(t, "1m", kline.Granularity)
+		assert.Equal(t, "2300.1", kline.Close.String())
+		count++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, parquetBatchSize+10, count)
+}
+
+func TestKlineRecord_InvalidDecimal(t *testing.T) {
+	record := &KlineRecord{Symbol: "BTCUSDT", Granularity: "1m", Open: "abc", High: "1", Low: "1", Close: "1", BaseVolume: "1", QuoteVolume: "1"}
+	_, err := record.ToModel()
+	assert.Error(t, err)
+}
+
+func TestObjectKey(t *testing.T) {
+	day := time.Date(2024, 3, 9, 17, 30, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	assert.Equal(t, "price_ticks/symbol=BTCUSDT/date=2024-03-09/data.parquet",
+		ObjectKey(models.ArchiveTablePriceTicks, "BTCUSDT", day))
+}
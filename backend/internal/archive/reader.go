@@ -0,0 +1,201 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// HistoryReader 历史数据读取，供回测、回放等工具使用
+// 按天选择数据源：已归档的日期从 Parquet 读取，其余从数据库读取，
+// 请求范围早于数据库保留的数据时透明地加载归档；结果按时间升序
+type HistoryReader struct {
+	archiveDAO dao.ArchiveDAO
+	store      Store
+	logger     *zap.Logger
+}
+
+// NewHistoryReader 创建历史数据读取器
+func NewHistoryReader(archiveDAO dao.ArchiveDAO, store Store, logger *zap.Logger) *HistoryReader {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &HistoryReader{
+		archiveDAO: archiveDAO,
+		store:      store,
+		logger:     logger,
+	}
+}
+
+// segment 读取计划中的一段连续时间，manifest 不为空时从归档读取
+type segment struct {
+	start    time.Time
+	end      time.Time
+	manifest *models.ArchiveManifest
+}
+
+// StreamTicks 按时间升序逐条读取 [start, end) 内的价格数据
+func (r *HistoryReader) StreamTicks(ctx context.Context, symbol string, start, end time.Time, fn func(*models.PriceTick) error) error {
+	segments, err := r.plan(ctx, models.ArchiveTablePriceTicks, symbol, start, end)
+	if err != nil {
+		return err
+	}
+
+	for _, seg := range segments {
+		if seg.manifest == nil {
+			if err := r.archiveDAO.StreamTicks(ctx, symbol, seg.start, seg.end, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := r.readArchive(ctx, seg.manifest, func(file File) error {
+			return ReadRecords(file, func(record *TickRecord) error {
+				if record.Timestamp.Before(seg.start) || !record.Timestamp.Before(seg.end) {
+					return nil
+				}
+				tick, err := record.ToModel()
+				if err != nil {
+					return err
+				}
+				return fn(tick)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamKlines 按时间升序逐条读取 [start, end) 内的K线，granularity 为空时读取所有周期
+func (r *HistoryReader) StreamKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error {
+	segments, err := r.plan(ctx, models.ArchiveTableKlines, symbol, start, end)
+	if err != nil {
+		return err
+	}
+
+	for _, seg := range segments {
+		if seg.manifest == nil {
+			if err := r.archiveDAO.StreamKlines(ctx, symbol, granularity, seg.start, seg.end, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := r.readArchive(ctx, seg.manifest, func(file File) error {
+			return ReadRecords(file, func(record *KlineRecord) error {
+				if granularity != "" && record.Granularity != granularity {
+					return nil
+				}
+				if record.Timestamp.Before(seg.start) || !record.Timestamp.Before(seg.end) {
+					return nil
+				}
+				kline, err := record.ToModel()
+				if err != nil {
+					return err
+				}
+				return fn(kline)
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamAggregatedKlines 按时间升序逐条读取连续聚合中 [start, end) 内的K线
+// 连续聚合不归档，有自己的保留策略，直接从数据库读取
+func (r *HistoryReader) StreamAggregatedKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error {
+	return r.archiveDAO.StreamAggregatedKlines(ctx, symbol, granularity, start, end, fn)
+}
+
+// Ticks 读取 [start, end) 内的全部价格数据
+func (r *HistoryReader) Ticks(ctx context.Context, symbol string, start, end time.Time) ([]*models.PriceTick, error) {
+	var ticks []*models.PriceTick
+	err := r.StreamTicks(ctx, symbol, start, end, func(tick *models.PriceTick) error {
+		ticks = append(ticks, tick)
+		return nil
+	})
+	return ticks, err
+}
+
+// Klines 读取 [start, end) 内的全部K线
+func (r *HistoryReader) Klines(ctx context.Context, symbol, granularity string, start, end time.Time) ([]*models.Kline, error) {
+	var klines []*models.Kline
+	err := r.StreamKlines(ctx, symbol, granularity, start, end, func(kline *models.Kline) error {
+		klines = append(klines, kline)
+		return nil
+	})
+	return klines, err
+}
+
+// plan 把请求范围按天拆分：有归档的日期各为一段，相邻的未归档日期合并为一段数据库查询
+func (r *HistoryReader) plan(ctx context.Context, table, symbol string, start, end time.Time) ([]segment, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("交易对不能为空")
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("开始时间必须早于结束时间")
+	}
+
+	manifests, err := r.archiveDAO.ListManifests(ctx, table, symbol, start, end.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+	archived := make(map[time.Time]*models.ArchiveManifest, len(manifests))
+	for _, manifest := range manifests {
+		archived[models.ArchiveDay(manifest.Day)] = manifest
+	}
+
+	var segments []segment
+	for day := models.ArchiveDay(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+		seg := segment{
+			start:    maxTime(day, start),
+			end:      minTime(day.AddDate(0, 0, 1), end),
+			manifest: archived[day],
+		}
+		if last := len(segments) - 1; seg.manifest == nil && last >= 0 && segments[last].manifest == nil {
+			segments[last].end = seg.end
+			continue
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// readArchive 打开归档文件并读取
+func (r *HistoryReader) readArchive(ctx context.Context, manifest *models.ArchiveManifest, read func(File) error) error {
+	file, err := r.store.Open(ctx, manifest.Path)
+	if err != nil {
+		return fmt.Errorf("打开归档 %s 失败: %w", manifest.Path, err)
+	}
+	defer file.Close()
+
+	r.logger.Debug("从归档读取",
+		zap.String("table", manifest.SourceTable),
+		zap.String("symbol", manifest.Symbol),
+		zap.String("uri", r.store.URI(manifest.Path)),
+	)
+	return read(file)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,206 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// parquetContentType Parquet 文件的 MIME 类型
+const parquetContentType = "application/vnd.apache.parquet"
+
+// LocalStore 本地目录存储
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore 创建本地目录存储，目录不存在时创建
+func NewLocalStore(root string) (*LocalStore, error) {
+	if root == "" {
+		return nil, fmt.Errorf("归档目录不能为空")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("创建归档目录失败: %w", err)
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Put 先写临时文件再重命名，读取方不会看到写了一半的文件
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("创建归档目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入归档文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入归档文件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("写入归档文件失败: %w", err)
+	}
+	return nil
+}
+
+// Open 打开本地文件
+func (s *LocalStore) Open(ctx context.Context, key string) (File, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", key, ErrObjectNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开归档文件失败: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("读取归档文件信息失败: %w", err)
+	}
+	return &localFile{File: file, size: info.Size()}, nil
+}
+
+// URI 返回本地文件路径
+func (s *LocalStore) URI(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+// path 把 key 转换为本地路径，拒绝跳出归档目录的 key
+func (s *LocalStore) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned != "/"+key {
+		return "", fmt.Errorf("无效的归档文件路径: %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// localFile 本地归档文件
+type localFile struct {
+	*os.File
+	size int64
+}
+
+func (f *localFile) Size() int64 {
+	return f.size
+}
+
+// S3StoreConfig S3 兼容存储配置
+type S3StoreConfig struct {
+	Endpoint  string `json:"endpoint" yaml:"endpoint"` // 如 s3.amazonaws.com、minio:9000
+	Bucket    string `json:"bucket" yaml:"bucket"`
+	Prefix    string `json:"prefix" yaml:"prefix"` // 所有文件的路径前缀
+	Region    string `json:"region" yaml:"region"`
+	AccessKey string `json:"-" yaml:"access_key"` // 为空时从 AWS_ACCESS_KEY_ID 等环境变量读取
+	SecretKey string `json:"-" yaml:"secret_key"`
+	UseSSL    bool   `json:"use_ssl" yaml:"use_ssl"`
+}
+
+// S3Store S3 兼容存储（AWS S3、MinIO、Ceph 等）
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Store 创建 S3 兼容存储
+func NewS3Store(config *S3StoreConfig) (*S3Store, error) {
+	if config == nil || config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("S3 endpoint 和 bucket 不能为空")
+	}
+
+	creds := credentials.NewEnvAWS()
+	if config.AccessKey != "" {
+		creds = credentials.NewStaticV4(config.AccessKey, config.SecretKey, "")
+	}
+
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建 S3 客户端失败: %w", err)
+	}
+
+	return &S3Store{
+		client: client,
+		bucket: config.Bucket,
+		prefix: strings.Trim(config.Prefix, "/"),
+	}, nil
+}
+
+// Put 上传文件
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.objectName(key), r, size, minio.PutObjectOptions{
+		ContentType: parquetContentType,
+	})
+	if err != nil {
+		return fmt.Errorf("上传归档文件失败: %w", err)
+	}
+	return nil
+}
+
+// Open 打开对象，ReadAt 按范围请求读取，不会下载整个文件
+func (s *S3Store) Open(ctx context.Context, key string) (File, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, s.objectName(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("打开归档文件失败: %w", err)
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%s: %w", key, ErrObjectNotFound)
+		}
+		return nil, fmt.Errorf("读取归档文件信息失败: %w", err)
+	}
+	return &s3File{Object: object, size: info.Size}, nil
+}
+
+// URI 返回 s3://bucket/key 形式的位置
+func (s *S3Store) URI(key string) string {
+	return fmt.Sprintf("s3://%s/%s", s.bucket, s.objectName(key))
+}
+
+func (s *S3Store) objectName(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return s.prefix + "/" + key
+}
+
+// s3File S3 归档文件
+type s3File struct {
+	*minio.Object
+	size int64
+}
+
+func (f *s3File) Size() int64 {
+	return f.size
+}
@@ -0,0 +1,82 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
+)
+
+func TestLocalStore_PutOpen(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	key := "klines/symbol=BTCUSDT/date=2024-01-01/data.parquet"
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("first"), 5))
+	// 覆盖已存在的文件
+	require.NoError(t, store.Put(ctx, key, strings.NewReader("second"), 6))
+
+	file, err := store.Open(ctx, key)
+	require.NoError(t, err)
+	defer file.Close()
+
+	assert.Equal(t, int64(6), file.Size())
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, file.Size()))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	assert.Equal(t, filepath.Join(root, "klines", "symbol=BTCUSDT", "date=2024-01-01", "data.parquet"), store.URI(key))
+
+	// 不留下临时文件
+	entries, err := os.ReadDir(filepath.Dir(store.URI(key)))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLocalStore_Errors(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = store.Open(ctx, "price_ticks/missing.parquet")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	for _, key := range []string{"", "../escape.parquet", "a/../../b", "/absolute"} {
+		assert.Error(t, store.Put(ctx, key, strings.NewReader("x"), 1), key)
+	}
+
+	_, err = NewLocalStore("")
+	assert.Error(t, err)
+}
+
+func TestNewS3Store_Validation(t *testing.T) {
+	_, err := NewS3Store(nil)
+	assert.Error(t, err)
+	_, err = NewS3Store(&S3StoreConfig{Endpoint: "localhost:9000"})
+	assert.Error(t, err)
+
+	store, err := NewS3Store(&S3StoreConfig{Endpoint: "localhost:9000", Bucket: "archive", Prefix: "/prod/"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3://archive/prod/price_ticks/x.parquet", store.URI("price_ticks/x.parquet"))
+}
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore(&config.ArchiveConfig{Backend: BackendLocal, Path: t.TempDir()})
+	require.NoError(t, err)
+	assert.IsType(t, &LocalStore{}, store)
+
+	store, err = NewStore(&config.ArchiveConfig{Backend: BackendS3, S3: config.ArchiveS3Config{Endpoint: "localhost:9000", Bucket: "archive"}})
+	require.NoError(t, err)
+	assert.IsType(t, &S3Store{}, store)
+
+	_, err = NewStore(&config.ArchiveConfig{Backend: "ftp"})
+	assert.Error(t, err)
+}
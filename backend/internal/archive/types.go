@@ -0,0 +1,91 @@
+// Package archive 在保留策略删除数据之前把 price_ticks / klines 导出为 Parquet 文件，
+// 按 表/交易对/天 分区存放在本地目录或 S3 兼容存储，并提供合并归档和数据库的历史读取
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/dao"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// ErrObjectNotFound 归档存储中不存在该文件
+var ErrObjectNotFound = errors.New("归档文件不存在")
+
+// Store 归档文件存储（本地目录或 S3 兼容存储）
+type Store interface {
+	// Put 写入文件，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+
+	// Open 打开文件用于随机读取，不存在时返回 ErrObjectNotFound
+	Open(ctx context.Context, key string) (File, error)
+
+	// URI 返回文件的完整位置，用于日志和清单展示
+	URI(key string) string
+}
+
+// File 可随机读取的归档文件，Parquet 需要先读取文件末尾的元数据
+type File interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+}
+
+// ArchiverConfig 归档任务配置
+type ArchiverConfig struct {
+	Tables   []string      `json:"tables" yaml:"tables"`     // 归档的表
+	Delay    time.Duration `json:"delay" yaml:"delay"`       // 一天结束后多久归档，需覆盖最长的K线周期（1w），并小于最短的保留期
+	Interval time.Duration `json:"interval" yaml:"interval"` // 检查间隔
+	TempDir  string        `json:"temp_dir" yaml:"temp_dir"` // 上传前写 Parquet 的临时目录，为空时使用系统临时目录
+}
+
+// DefaultArchiverConfig 默认归档任务配置
+// 8天的延迟保证周K线已收盘，并早于 price_ticks（14天）和1m K线（30天）的保留期
+func DefaultArchiverConfig() *ArchiverConfig {
+	return &ArchiverConfig{
+		Tables:   []string{models.ArchiveTablePriceTicks, models.ArchiveTableKlines},
+		Delay:    8 * 24 * time.Hour,
+		Interval: time.Hour,
+	}
+}
+
+// Validate 验证配置
+func (c *ArchiverConfig) Validate() error {
+	if len(c.Tables) == 0 {
+		return fmt.Errorf("归档表不能为空")
+	}
+	for _, table := range c.Tables {
+		if !models.IsArchiveTable(table) {
+			return fmt.Errorf("不支持归档的表: %s", table)
+		}
+	}
+	if c.Delay < 0 {
+		return fmt.Errorf("归档延迟不能为负数")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("归档检查间隔必须大于0")
+	}
+	return nil
+}
+
+// Retention 归档需要的保留期下限，用于拒绝会在归档之前删除数据的保留策略
+func (c *ArchiverConfig) Retention() *dao.ArchiveRetention {
+	return &dao.ArchiveRetention{Tables: c.Tables, Delay: c.Delay}
+}
+
+// ArchiveResult 一轮归档的结果
+type ArchiveResult struct {
+	Files int   `json:"files"` // 新写入的文件数
+	Rows  int64 `json:"rows"`
+	Bytes int64 `json:"bytes"`
+}
+
+// ObjectKey 归档文件路径，按 Hive 风格分区便于 DuckDB、Spark 等工具直接读取：
+// <table>/symbol=<symbol>/date=<YYYY-MM-DD>/data.parquet
+func ObjectKey(table, symbol string, day time.Time) string {
+	return fmt.Sprintf("%s/symbol=%s/date=%s/data.parquet", table, symbol, models.ArchiveDay(day).Format("2006-01-02"))
+}
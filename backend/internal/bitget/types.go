@@ -1,7 +1,13 @@
 package bitget
 
 import (
+	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
 
 // Symbol 交易对信息
@@ -100,6 +106,50 @@ type Ticker struct {
 	MarkPrice         string `json:"markPrice"`         // 标记价格
 }
 
+// LastPrice 解析最新成交价，必须为正数
+func (t Ticker) LastPrice() (decimal.Decimal, error) {
+	price, err := decimal.NewFromString(t.LastPr)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("最新价格式错误: %q", t.LastPr)
+	}
+	if price.Sign() <= 0 {
+		return decimal.Zero, fmt.Errorf("最新价必须为正数: %q", t.LastPr)
+	}
+	return price, nil
+}
+
+// ParseOptionalDecimal 解析交易所返回的可选数值字符串，空字符串或格式错误返回 nil
+// 交易所以字符串返回价格和数量，直接解析为 decimal 可保留原始精度
+func ParseOptionalDecimal(value string) *decimal.Decimal {
+	if value == "" {
+		return nil
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return nil
+	}
+	return &d
+}
+
+// TickSize 最小价格变动单位，校验交易所返回的精度字符串后按 models.TickSizeFromPrecision 计算
+func (s Symbol) TickSize() (decimal.Decimal, error) {
+	place, err := strconv.Atoi(s.PricePlace)
+	if err != nil || place < 0 {
+		return decimal.Zero, fmt.Errorf("价格精度格式错误: %q", s.PricePlace)
+	}
+
+	var step *decimal.Decimal
+	if s.PriceEndStep != "" {
+		step = ParseOptionalDecimal(s.PriceEndStep)
+		if step == nil || step.Sign() <= 0 {
+			return decimal.Zero, fmt.Errorf("价格步长格式错误: %q", s.PriceEndStep)
+		}
+	}
+
+	tick, _ := models.TickSizeFromPrecision(&place, step)
+	return tick, nil
+}
+
 // KlineRequest K线请求参数
 type KlineRequest struct {
 	Symbol      string `json:"symbol"`              // 交易对，如 BTCUSDT
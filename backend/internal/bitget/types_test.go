@@ -0,0 +1,96 @@
+package bitget
+
+import (
+	"testing"
+)
+
+// TestTicker_LastPrice 测试最新价解析
+func TestTicker_LastPrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		lastPr  string
+		want    string
+		wantErr bool
+	}{
+		{name: "普通价格", lastPr: "50000.5", want: "50000.5"},
+		{name: "保留原始精度", lastPr: "0.00001234", want: "0.00001234"},
+		{name: "空字符串", lastPr: "", wantErr: true},
+		{name: "格式错误", lastPr: "abc", wantErr: true},
+		{name: "零价格", lastPr: "0", wantErr: true},
+		{name: "负价格", lastPr: "-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := Ticker{LastPr: tt.lastPr}.LastPrice()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际得到 %s", price)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if price.String() != tt.want {
+				t.Errorf("期望 %s，实际 %s", tt.want, price)
+			}
+		})
+	}
+}
+
+// TestParseOptionalDecimal 测试可选数值解析
+func TestParseOptionalDecimal(t *testing.T) {
+	if d := ParseOptionalDecimal(""); d != nil {
+		t.Errorf("空字符串应返回 nil，实际 %s", d)
+	}
+	if d := ParseOptionalDecimal("n/a"); d != nil {
+		t.Errorf("格式错误应返回 nil，实际 %s", d)
+	}
+
+	d := ParseOptionalDecimal("0.1")
+	if d == nil {
+		t.Fatal("有效数值不应返回 nil")
+	}
+	// 0.1 + 0.2 在 float64 下不等于 0.3
+	if sum := d.Add(*ParseOptionalDecimal("0.2")); sum.String() != "0.3" {
+		t.Errorf("期望 0.3，实际 %s", sum)
+	}
+}
+
+// TestSymbol_TickSize 测试最小价格变动单位计算
+func TestSymbol_TickSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		pricePlace   string
+		priceEndStep string
+		want         string
+		wantErr      bool
+	}{
+		{name: "一位小数步长5", pricePlace: "1", priceEndStep: "5", want: "0.5"},
+		{name: "两位小数步长1", pricePlace: "2", priceEndStep: "1", want: "0.01"},
+		{name: "缺少步长", pricePlace: "4", want: "0.0001"},
+		{name: "整数价格", pricePlace: "0", priceEndStep: "1", want: "1"},
+		{name: "精度格式错误", pricePlace: "x", priceEndStep: "1", wantErr: true},
+		{name: "负精度", pricePlace: "-1", priceEndStep: "1", wantErr: true},
+		{name: "步长为零", pricePlace: "1", priceEndStep: "0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tick, err := Symbol{PricePlace: tt.pricePlace, PriceEndStep: tt.priceEndStep}.TickSize()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际得到 %s", tick)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("计算失败: %v", err)
+			}
+			if tick.String() != tt.want {
+				t.Errorf("期望 %s，实际 %s", tt.want, tick)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -194,7 +195,7 @@ func (c *CacheAsideStrategy) readFromDatabase(ctx context.Context, symbol string
 	// 为了演示，返回模拟数据
 	return &PriceData{
 		Symbol:    symbol,
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}, nil
 }
@@ -7,11 +7,14 @@ import (
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
 
 // setupTestDB 设置测试数据库
@@ -33,11 +36,10 @@ func setupTestCache(t *testing.T) (PriceCache, *Client) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存客户端
-	cfg := DefaultConfig()
-	cfg.Host = "localhost"
-	cfg.Port = 6379
-	cacheClient, err := NewClient(cfg, logger)
-	require.NoError(t, err)
+	cacheClient := &Client{
+		client: client,
+		logger: logger,
+	}
 
 	// 创建缓存
 	cache := NewPriceCache(cacheClient)
@@ -63,7 +65,7 @@ func TestWriteThroughStrategy_Consistency(t *testing.T) {
 	// 测试数据
 	priceData := &PriceData{
 		Symbol:    "BTCUSDT",
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}
 
@@ -75,7 +77,7 @@ func TestWriteThroughStrategy_Consistency(t *testing.T) {
 	cachedData, err := cache.GetPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", cachedData.Symbol)
-	assert.Equal(t, 50000.0, cachedData.LastPrice)
+	assert.Equal(t, "50000", cachedData.LastPrice.String())
 }
 
 func TestWriteBehindStrategy_Consistency(t *testing.T) {
@@ -91,7 +93,7 @@ func TestWriteBehindStrategy_Consistency(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -105,7 +107,7 @@ func TestWriteBehindStrategy_Consistency(t *testing.T) {
 	// 测试数据
 	priceData := &PriceData{
 		Symbol:    "BTCUSDT",
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}
 
@@ -117,7 +119,7 @@ func TestWriteBehindStrategy_Consistency(t *testing.T) {
 	cachedData, err := cache.GetPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", cachedData.Symbol)
-	assert.Equal(t, 50000.0, cachedData.LastPrice)
+	assert.Equal(t, "50000", cachedData.LastPrice.String())
 
 	// 等待异步数据库写入完成
 	time.Sleep(100 * time.Millisecond)
@@ -136,7 +138,7 @@ func TestCacheAsideStrategy_Consistency(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -151,7 +153,7 @@ func TestCacheAsideStrategy_Consistency(t *testing.T) {
 	result, err := strategy.ReadPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", result.Symbol)
-	assert.Equal(t, 50000.0, result.LastPrice)
+	assert.Equal(t, "50000", result.LastPrice.String())
 
 	// 等待异步缓存更新完成
 	time.Sleep(100 * time.Millisecond)
@@ -160,7 +162,7 @@ func TestCacheAsideStrategy_Consistency(t *testing.T) {
 	cachedData, err := cache.GetPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", cachedData.Symbol)
-	assert.Equal(t, 50000.0, cachedData.LastPrice)
+	assert.Equal(t, "50000", cachedData.LastPrice.String())
 }
 
 func TestCacheInvalidationStrategy_Consistency(t *testing.T) {
@@ -176,7 +178,7 @@ func TestCacheInvalidationStrategy_Consistency(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -190,7 +192,7 @@ func TestCacheInvalidationStrategy_Consistency(t *testing.T) {
 	// 先设置价格数据
 	priceData := &PriceData{
 		Symbol:    "BTCUSDT",
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}
 
@@ -225,7 +227,7 @@ func TestCacheConsistency_ConcurrentReadWrite(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -243,7 +245,7 @@ func TestCacheConsistency_ConcurrentReadWrite(t *testing.T) {
 		go func(index int) {
 			priceData := &PriceData{
 				Symbol:    "BTCUSDT",
-				LastPrice: 50000.0 + float64(index),
+				LastPrice: decimal.NewFromInt(int64(50000 + index)),
 				Timestamp: time.Now(),
 			}
 
@@ -263,7 +265,7 @@ func TestCacheConsistency_ConcurrentReadWrite(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", cachedData.Symbol)
 	// 最终价格应该是最后一个写入的值
-	assert.True(t, cachedData.LastPrice >= 50000.0)
+	assert.True(t, cachedData.LastPrice.GreaterThanOrEqual(decimal.NewFromInt(50000)))
 }
 
 func TestCacheConsistency_DataIntegrity(t *testing.T) {
@@ -279,7 +281,7 @@ func TestCacheConsistency_DataIntegrity(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -293,14 +295,14 @@ func TestCacheConsistency_DataIntegrity(t *testing.T) {
 	// 测试数据完整性
 	priceData := &PriceData{
 		Symbol:      "BTCUSDT",
-		LastPrice:   50000.0,
-		AskPrice:    &[]float64{50001.0}[0],
-		BidPrice:    &[]float64{49999.0}[0],
-		High24h:     &[]float64{51000.0}[0],
-		Low24h:      &[]float64{49000.0}[0],
+		LastPrice:   decimal.NewFromInt(50000),
+		AskPrice:    models.DecimalPtr(decimal.NewFromInt(50001)),
+		BidPrice:    models.DecimalPtr(decimal.NewFromInt(49999)),
+		High24h:     models.DecimalPtr(decimal.NewFromInt(51000)),
+		Low24h:      models.DecimalPtr(decimal.NewFromInt(49000)),
 		Change24h:   &[]float64{1000.0}[0],
-		BaseVolume:  &[]float64{100.0}[0],
-		QuoteVolume: &[]float64{5000000.0}[0],
+		BaseVolume:  models.DecimalPtr(decimal.NewFromInt(100)),
+		QuoteVolume: models.DecimalPtr(decimal.NewFromInt(5000000)),
 		Timestamp:   time.Now(),
 	}
 
@@ -313,14 +315,14 @@ func TestCacheConsistency_DataIntegrity(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, "BTCUSDT", cachedData.Symbol)
-	assert.Equal(t, 50000.0, cachedData.LastPrice)
-	assert.Equal(t, 50001.0, *cachedData.AskPrice)
-	assert.Equal(t, 49999.0, *cachedData.BidPrice)
-	assert.Equal(t, 51000.0, *cachedData.High24h)
-	assert.Equal(t, 49000.0, *cachedData.Low24h)
+	assert.Equal(t, "50000", cachedData.LastPrice.String())
+	assert.Equal(t, "50001", cachedData.AskPrice.String())
+	assert.Equal(t, "49999", cachedData.BidPrice.String())
+	assert.Equal(t, "51000", cachedData.High24h.String())
+	assert.Equal(t, "49000", cachedData.Low24h.String())
 	assert.Equal(t, 1000.0, *cachedData.Change24h)
-	assert.Equal(t, 100.0, *cachedData.BaseVolume)
-	assert.Equal(t, 5000000.0, *cachedData.QuoteVolume)
+	assert.Equal(t, "100", cachedData.BaseVolume.String())
+	assert.Equal(t, "5000000", cachedData.QuoteVolume.String())
 }
 
 func TestCacheConsistency_TTLExpiration(t *testing.T) {
@@ -336,7 +338,7 @@ func TestCacheConsistency_TTLExpiration(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -350,7 +352,7 @@ func TestCacheConsistency_TTLExpiration(t *testing.T) {
 	// 写入数据
 	priceData := &PriceData{
 		Symbol:    "BTCUSDT",
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}
 
@@ -385,7 +387,7 @@ func TestCacheConsistency_ErrorHandling(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -424,7 +426,7 @@ func TestCacheConsistency_MultipleSymbols(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -441,7 +443,7 @@ func TestCacheConsistency_MultipleSymbols(t *testing.T) {
 	for i, symbol := range symbols {
 		priceData := &PriceData{
 			Symbol:    symbol,
-			LastPrice: 50000.0 + float64(i*1000),
+			LastPrice: decimal.NewFromInt(int64(50000 + i*1000)),
 			Timestamp: time.Now(),
 		}
 
@@ -454,7 +456,7 @@ func TestCacheConsistency_MultipleSymbols(t *testing.T) {
 		cachedData, err := cache.GetPrice(context.Background(), symbol)
 		require.NoError(t, err)
 		assert.Equal(t, symbol, cachedData.Symbol)
-		assert.Equal(t, 50000.0+float64(i*1000), cachedData.LastPrice)
+		assert.Equal(t, int64(50000+i*1000), cachedData.LastPrice.IntPart())
 	}
 }
 
@@ -471,7 +473,7 @@ func TestCacheConsistency_BatchOperations(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 测试批量获取
 	symbols := []string{"BTCUSDT", "ETHUSDT", "ADAUSDT"}
@@ -480,7 +482,7 @@ func TestCacheConsistency_BatchOperations(t *testing.T) {
 	for i, symbol := range symbols {
 		priceData := &PriceData{
 			Symbol:    symbol,
-			LastPrice: 50000.0 + float64(i*1000),
+			LastPrice: decimal.NewFromInt(int64(50000 + i*1000)),
 			Timestamp: time.Now(),
 		}
 
@@ -498,7 +500,7 @@ func TestCacheConsistency_BatchOperations(t *testing.T) {
 		data, exists := results[symbol]
 		require.True(t, exists)
 		assert.Equal(t, symbol, data.Symbol)
-		assert.Equal(t, 50000.0+float64(i*1000), data.LastPrice)
+		assert.Equal(t, int64(50000+i*1000), data.LastPrice.IntPart())
 	}
 }
 
@@ -515,7 +517,7 @@ func TestCacheConsistency_ActiveSymbols(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 设置活跃交易对列表
 	symbols := []string{"BTCUSDT", "ETHUSDT", "ADAUSDT", "DOTUSDT", "LINKUSDT"}
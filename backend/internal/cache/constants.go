@@ -18,6 +18,7 @@ const (
 	TTLSymbolList       = 300 * time.Second // 交易对列表：5分钟
 	TTLWebSocketSession = 90 * time.Second  // WebSocket 会话：90秒
 	TTLKlineData        = 300 * time.Second // K线数据：5分钟
+	TTLScannerTicker    = 300 * time.Second // 扫描器行情快照：5分钟
 )
 
 // CacheKeyType 缓存键类型
@@ -48,6 +49,16 @@ const (
 	// WebSocket 相关
 	KeyTypeWSSession     CacheKeyType = "ws_session"     // WebSocket 会话
 	KeyTypeWSHeartbeat   CacheKeyType = "ws_heartbeat"   // WebSocket 心跳
+	KeyTypeWSPresence    CacheKeyType = "ws_presence"    // WebSocket 实例频道订阅数
+	KeyTypeWSInstances   CacheKeyType = "ws_instances"   // WebSocket 在线实例
+	KeyTypeWSSequence    CacheKeyType = "ws_seq"         // WebSocket 频道序列号
+	KeyTypeWSBackplane   CacheKeyType = "ws_backplane"   // WebSocket 跨实例广播频道
+
+	// 扫描器相关
+	KeyTypeScanner       CacheKeyType = "scanner"        // 市场扫描器
+
+	// 集群相关
+	KeyTypeMembers       CacheKeyType = "members"        // 实例成员表
 
 	// 系统相关
 	KeyTypeHealth        CacheKeyType = "health"         // 健康检查
@@ -160,6 +171,42 @@ func BuildWSHeartbeatKey(sessionID string) string {
 		Build()
 }
 
+// BuildWSPresenceKey 构建 WebSocket 实例频道订阅数键
+// 格式：cryptosignal:ws_presence:instance_id
+func BuildWSPresenceKey(instanceID string) string {
+	return NewCacheKeyBuilder(KeyTypeWSPresence).
+		WithPart(instanceID).
+		Build()
+}
+
+// BuildWSInstancesKey 构建 WebSocket 在线实例有序集合键
+// 格式：cryptosignal:ws_instances
+func BuildWSInstancesKey() string {
+	return NewCacheKeyBuilder(KeyTypeWSInstances).Build()
+}
+
+// BuildWSSequenceKey 构建 WebSocket 频道序列号键
+// 格式：cryptosignal:ws_seq:ticker:BTCUSDT
+func BuildWSSequenceKey(channel string) string {
+	return NewCacheKeyBuilder(KeyTypeWSSequence).
+		WithPart(channel).
+		Build()
+}
+
+// BuildWSBackplaneChannel 构建 WebSocket 跨实例广播的 Pub/Sub 频道名
+// 格式：cryptosignal:ws_backplane
+func BuildWSBackplaneChannel() string {
+	return NewCacheKeyBuilder(KeyTypeWSBackplane).Build()
+}
+
+// BuildMembersKey 构建实例成员表有序集合键
+// 格式：cryptosignal:members:collector
+func BuildMembersKey(group string) string {
+	return NewCacheKeyBuilder(KeyTypeMembers).
+		WithPart(group).
+		Build()
+}
+
 // BuildLockKey 构建分布式锁缓存键
 // 格式：cryptosignal:lock:resource_name
 func BuildLockKey(resourceName string) string {
@@ -168,3 +215,48 @@ func BuildLockKey(resourceName string) string {
 		Build()
 }
 
+// BuildLockFenceKey 构建分布式锁的栅栏令牌计数器键
+// 格式：cryptosignal:lock:resource_name:fence
+func BuildLockFenceKey(resourceName string) string {
+	return NewCacheKeyBuilder(KeyTypeLock).
+		WithPart(resourceName).
+		WithPart("fence").
+		Build()
+}
+
+// BuildScannerRankKey 构建扫描器排行有序集合键
+// 格式：cryptosignal:scanner:rank:volume 或 cryptosignal:scanner:rank:change_rate:5m
+func BuildScannerRankKey(metric string, window string) string {
+	builder := NewCacheKeyBuilder(KeyTypeScanner).
+		WithPart("rank").
+		WithPart(metric)
+	if window != "" {
+		builder.WithPart(window)
+	}
+	return builder.Build()
+}
+
+// BuildScannerTickerKey 构建扫描器行情快照键
+// 格式：cryptosignal:scanner:ticker:BTCUSDT
+func BuildScannerTickerKey(symbol string) string {
+	return NewCacheKeyBuilder(KeyTypeScanner).
+		WithPart("ticker").
+		WithPart(symbol).
+		Build()
+}
+
+// BuildScannerUpdatedKey 构建扫描器更新时间有序集合键
+// 格式：cryptosignal:scanner:updated
+func BuildScannerUpdatedKey() string {
+	return NewCacheKeyBuilder(KeyTypeScanner).
+		WithPart("updated").
+		Build()
+}
+
+// BuildScannerRankKeysKey 构建扫描器排行键集合的键
+// 格式：cryptosignal:scanner:rank_keys
+func BuildScannerRankKeysKey() string {
+	return NewCacheKeyBuilder(KeyTypeScanner).
+		WithPart("rank_keys").
+		Build()
+}
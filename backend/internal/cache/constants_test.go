@@ -109,9 +109,21 @@ func TestBuildWSHeartbeatKey(t *testing.T) {
 	assert.Equal(t, "cryptosignal:ws_heartbeat:session-456", key)
 }
 
+func TestBuildWSBackplaneKeys(t *testing.T) {
+	assert.Equal(t, "cryptosignal:ws_presence:instance-1", BuildWSPresenceKey("instance-1"))
+	assert.Equal(t, "cryptosignal:ws_instances", BuildWSInstancesKey())
+	assert.Equal(t, "cryptosignal:ws_seq:ticker:BTCUSDT", BuildWSSequenceKey("ticker:BTCUSDT"))
+	assert.Equal(t, "cryptosignal:ws_backplane", BuildWSBackplaneChannel())
+}
+
+func TestBuildMembersKey(t *testing.T) {
+	assert.Equal(t, "cryptosignal:members:collector", BuildMembersKey("collector"))
+}
+
 func TestBuildLockKey(t *testing.T) {
 	key := BuildLockKey("resource-name")
 	assert.Equal(t, "cryptosignal:lock:resource-name", key)
+	assert.Equal(t, "cryptosignal:lock:resource-name:fence", BuildLockFenceKey("resource-name"))
 }
 
 func TestKeyTypeConstants(t *testing.T) {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -309,7 +310,7 @@ func TestCacheProtection_GetPriceWithProtection(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存和布隆过滤器
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 	bf := NewBloomFilter(client, "test:bloom", 1000, 0.01, logger)
 	nvc := NewNullValueCache(client, 60*time.Second, logger)
 
@@ -323,7 +324,7 @@ func TestCacheProtection_GetPriceWithProtection(t *testing.T) {
 	// 设置价格数据
 	priceData := &PriceData{
 		Symbol:    "BTCUSDT",
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}
 
@@ -334,7 +335,7 @@ func TestCacheProtection_GetPriceWithProtection(t *testing.T) {
 	result, err := cp.GetPriceWithProtection(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", result.Symbol)
-	assert.Equal(t, 50000.0, result.LastPrice)
+	assert.Equal(t, "50000", result.LastPrice.String())
 }
 
 func TestCacheProtection_GetPriceWithProtection_NotFound(t *testing.T) {
@@ -350,7 +351,7 @@ func TestCacheProtection_GetPriceWithProtection_NotFound(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存和布隆过滤器
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 	bf := NewBloomFilter(client, "test:bloom", 1000, 0.01, logger)
 	nvc := NewNullValueCache(client, 60*time.Second, logger)
 
@@ -381,7 +382,7 @@ func TestWriteThroughStrategy_WritePrice(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器（这里需要模拟数据库）
 	manager := &CacheConsistencyManager{
@@ -395,7 +396,7 @@ func TestWriteThroughStrategy_WritePrice(t *testing.T) {
 	// 写入价格数据
 	priceData := &PriceData{
 		Symbol:    "BTCUSDT",
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}
 
@@ -406,7 +407,7 @@ func TestWriteThroughStrategy_WritePrice(t *testing.T) {
 	result, err := cache.GetPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", result.Symbol)
-	assert.Equal(t, 50000.0, result.LastPrice)
+	assert.Equal(t, "50000", result.LastPrice.String())
 }
 
 func TestWriteBehindStrategy_WritePrice(t *testing.T) {
@@ -422,7 +423,7 @@ func TestWriteBehindStrategy_WritePrice(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -436,7 +437,7 @@ func TestWriteBehindStrategy_WritePrice(t *testing.T) {
 	// 写入价格数据
 	priceData := &PriceData{
 		Symbol:    "BTCUSDT",
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}
 
@@ -447,7 +448,7 @@ func TestWriteBehindStrategy_WritePrice(t *testing.T) {
 	result, err := cache.GetPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", result.Symbol)
-	assert.Equal(t, 50000.0, result.LastPrice)
+	assert.Equal(t, "50000", result.LastPrice.String())
 
 	// 等待异步数据库写入完成
 	time.Sleep(100 * time.Millisecond)
@@ -466,7 +467,7 @@ func TestCacheAsideStrategy_ReadPrice(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -481,7 +482,7 @@ func TestCacheAsideStrategy_ReadPrice(t *testing.T) {
 	result, err := strategy.ReadPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", result.Symbol)
-	assert.Equal(t, 50000.0, result.LastPrice)
+	assert.Equal(t, "50000", result.LastPrice.String())
 
 	// 等待异步缓存更新完成
 	time.Sleep(100 * time.Millisecond)
@@ -490,7 +491,7 @@ func TestCacheAsideStrategy_ReadPrice(t *testing.T) {
 	result2, err := cache.GetPrice(context.Background(), "BTCUSDT")
 	require.NoError(t, err)
 	assert.Equal(t, "BTCUSDT", result2.Symbol)
-	assert.Equal(t, 50000.0, result2.LastPrice)
+	assert.Equal(t, "50000", result2.LastPrice.String())
 }
 
 func TestCacheInvalidationStrategy_InvalidatePrice(t *testing.T) {
@@ -506,7 +507,7 @@ func TestCacheInvalidationStrategy_InvalidatePrice(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 
 	// 创建缓存
-	cache := NewPriceCache(NewClient(client))
+	cache := NewPriceCache(&Client{client: client, logger: logger})
 
 	// 创建一致性管理器
 	manager := &CacheConsistencyManager{
@@ -520,7 +521,7 @@ func TestCacheInvalidationStrategy_InvalidatePrice(t *testing.T) {
 	// 先设置价格数据
 	priceData := &PriceData{
 		Symbol:    "BTCUSDT",
-		LastPrice: 50000.0,
+		LastPrice: decimal.NewFromInt(50000),
 		Timestamp: time.Now(),
 	}
 
@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LeaderConfig 选主配置
+type LeaderConfig struct {
+	LeaseTTL      time.Duration // 租约时长，领导者失联后最迟在该时间后可被接替
+	RenewInterval time.Duration // 续约间隔，应明显小于 LeaseTTL
+	RetryInterval time.Duration // 非领导者尝试获取租约的间隔
+}
+
+// DefaultLeaderConfig 默认选主配置，领导者崩溃后约 6 秒内完成切换
+func DefaultLeaderConfig() *LeaderConfig {
+	return &LeaderConfig{
+		LeaseTTL:      5 * time.Second,
+		RenewInterval: 1500 * time.Millisecond,
+		RetryInterval: time.Second,
+	}
+}
+
+// LeaderFunc 成为领导者后执行的任务
+// ctx 在失去领导权或选主停止时取消，token 为本次任期的栅栏令牌；任务返回即主动放弃领导权
+type LeaderFunc func(ctx context.Context, token int64)
+
+// LeaderElector 基于租约锁的选主
+//
+// 多个副本对同一资源调用 Run，同一时刻只有一个副本执行 LeaderFunc。领导者定期续约，
+// 续约失败（锁被接替或 Redis 不可用）超过安全时限时立即取消任务，保证租约过期前已停止工作
+type LeaderElector struct {
+	lock   *LeaseLock
+	config *LeaderConfig
+	logger *zap.Logger
+
+	leading atomic.Bool
+	token   atomic.Int64
+}
+
+// NewLeaderElector 创建选主器
+func NewLeaderElector(lock *LeaseLock, config *LeaderConfig, logger *zap.Logger) *LeaderElector {
+	if config == nil {
+		config = DefaultLeaderConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &LeaderElector{
+		lock:   lock,
+		config: config,
+		logger: logger,
+	}
+}
+
+// IsLeader 当前是否为领导者
+func (e *LeaderElector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// Token 当前任期的栅栏令牌，非领导者返回 0
+func (e *LeaderElector) Token() int64 {
+	return e.token.Load()
+}
+
+// Verify 检查 token 仍是当前任期的栅栏令牌，已失去领导权时返回 ErrLockNotHeld
+func (e *LeaderElector) Verify(ctx context.Context, token int64) error {
+	return e.lock.Verify(ctx, token)
+}
+
+// Run 参与选主直到 ctx 取消，返回前会停止任务并释放租约，使其他副本立即接替
+func (e *LeaderElector) Run(ctx context.Context, fn LeaderFunc) {
+	retry := time.NewTicker(e.config.RetryInterval)
+	defer retry.Stop()
+
+	for {
+		token, err := e.lock.TryAcquire(ctx, e.config.LeaseTTL)
+		switch {
+		case err == nil:
+			e.lead(ctx, token, fn)
+		case !errors.Is(err, ErrLockNotAcquired) && ctx.Err() == nil:
+			e.logger.Warn("Failed to acquire leader lease", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-retry.C:
+		}
+	}
+}
+
+// lead 执行任务并续约，直到任务返回、失去租约或 ctx 取消
+func (e *LeaderElector) lead(ctx context.Context, token int64, fn LeaderFunc) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	e.token.Store(token)
+	e.leading.Store(true)
+	e.logger.Info("Became leader", zap.Int64("token", token))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leaderCtx, token)
+	}()
+
+	renew := time.NewTicker(e.config.RenewInterval)
+	defer renew.Stop()
+
+	// 续约持续失败时，在租约到期前留出一个续约间隔停止任务
+	deadline := e.config.LeaseTTL - e.config.RenewInterval
+	lastRenewed := time.Now()
+	lost := false
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-done:
+			e.logger.Info("Leader task finished, stepping down", zap.Int64("token", token))
+			break loop
+		case <-renew.C:
+			renewCtx, renewCancel := context.WithTimeout(ctx, e.config.RenewInterval)
+			err := e.lock.Renew(renewCtx, e.config.LeaseTTL)
+			renewCancel()
+
+			if err == nil {
+				lastRenewed = time.Now()
+				continue
+			}
+			if errors.Is(err, ErrLockNotHeld) {
+				e.logger.Warn("Leader lease lost", zap.Int64("token", token))
+				lost = true
+				break loop
+			}
+			if time.Since(lastRenewed) >= deadline {
+				e.logger.Warn("Leader lease renewal timed out, stepping down",
+					zap.Int64("token", token),
+					zap.Error(err),
+				)
+				break loop
+			}
+			e.logger.Warn("Failed to renew leader lease", zap.Int64("token", token), zap.Error(err))
+		}
+	}
+
+	cancel()
+	<-done
+
+	e.leading.Store(false)
+	e.token.Store(0)
+
+	if !lost {
+		// ctx 可能已取消，释放使用独立的超时
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), e.config.RenewInterval)
+		if err := e.lock.Release(releaseCtx); err != nil {
+			e.logger.Warn("Failed to release leader lease", zap.Error(err))
+		}
+		releaseCancel()
+	}
+	e.logger.Info("Stopped leading", zap.Int64("token", token))
+}
@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrLockNotAcquired 锁已被其他持有者占用
+	ErrLockNotAcquired = errors.New("lock is held by another owner")
+
+	// ErrLockNotHeld 锁已过期或已被其他持有者获取
+	ErrLockNotHeld = errors.New("lock is not held")
+)
+
+// acquireLockScript 获取锁：SET NX PX 成功后递增栅栏令牌，锁的值为 owner:token
+// 令牌只在获取成功时递增，保证每次成功获取的令牌严格递增
+var acquireLockScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('SET', KEYS[1], ARGV[1] .. ':' .. token, 'NX', 'PX', ARGV[2])
+return token
+`)
+
+// renewLockScript 续约：仍由本次获取持有时延长过期时间
+var renewLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLockScript 释放：仍由本次获取持有时删除，避免误删其他持有者的锁
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// LeaseLock 基于 Redis 的租约锁
+//
+// 锁带有过期时间，持有者需要在过期前续约，进程崩溃后锁会自动过期。每次获取成功都会得到
+// 严格递增的栅栏令牌（fencing token），持有者写入外部系统时携带令牌，外部系统拒绝比已见过
+// 的令牌更小的写入，即可防止暂停后恢复的旧持有者在锁过期后继续写入
+type LeaseLock struct {
+	client   *redis.Client
+	key      string
+	fenceKey string
+	owner    string
+	logger   *zap.Logger
+
+	mu    sync.Mutex
+	token int64 // 当前持有的栅栏令牌，0 表示未持有
+}
+
+// NewLeaseLock 创建租约锁，owner 用于区分持有者（如实例 ID），同一资源的不同实例必须不同
+func NewLeaseLock(client *Client, resource, owner string, logger *zap.Logger) *LeaseLock {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &LeaseLock{
+		client:   client.GetClient(),
+		key:      BuildLockKey(resource),
+		fenceKey: BuildLockFenceKey(resource),
+		owner:    owner,
+		logger:   logger,
+	}
+}
+
+// TryAcquire 尝试获取锁，成功时返回栅栏令牌，锁被占用时返回 ErrLockNotAcquired
+func (l *LeaseLock) TryAcquire(ctx context.Context, ttl time.Duration) (int64, error) {
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid lock ttl: %v", ttl)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token != 0 {
+		return 0, fmt.Errorf("lock %s already held with token %d", l.key, l.token)
+	}
+
+	token, err := acquireLockScript.Run(ctx, l.client,
+		[]string{l.key, l.fenceKey}, l.owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire lock %s: %w", l.key, err)
+	}
+	if token == 0 {
+		return 0, ErrLockNotAcquired
+	}
+
+	l.token = token
+	l.logger.Debug("Lock acquired",
+		zap.String("key", l.key),
+		zap.String("owner", l.owner),
+		zap.Int64("token", token),
+	)
+	return token, nil
+}
+
+// Renew 延长锁的过期时间，锁已丢失时返回 ErrLockNotHeld
+func (l *LeaseLock) Renew(ctx context.Context, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token == 0 {
+		return ErrLockNotHeld
+	}
+
+	renewed, err := renewLockScript.Run(ctx, l.client,
+		[]string{l.key}, l.value(), ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to renew lock %s: %w", l.key, err)
+	}
+	if renewed == 0 {
+		l.token = 0
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release 释放锁，锁已过期或被其他持有者获取时只清除本地状态
+func (l *LeaseLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.token == 0 {
+		return nil
+	}
+
+	value := l.value()
+	l.token = 0
+
+	if err := releaseLockScript.Run(ctx, l.client, []string{l.key}, value).Err(); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+
+	l.logger.Debug("Lock released", zap.String("key", l.key), zap.String("owner", l.owner))
+	return nil
+}
+
+// Token 返回当前持有的栅栏令牌，未持有时返回 0
+func (l *LeaseLock) Token() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.token
+}
+
+// Holder 返回当前持有者和令牌，锁空闲时返回空字符串
+func (l *LeaseLock) Holder(ctx context.Context) (string, int64, error) {
+	value, err := l.client.Get(ctx, l.key).Result()
+	if err == redis.Nil {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get lock holder %s: %w", l.key, err)
+	}
+	return parseLockValue(value)
+}
+
+// Verify 检查锁仍由本持有者以指定令牌持有，锁已过期或已被其他持有者获取时返回 ErrLockNotHeld
+// 单实例任务在每次写入共享存储前调用，避免已失去租约的旧领导者继续写入
+func (l *LeaseLock) Verify(ctx context.Context, token int64) error {
+	owner, current, err := l.Holder(ctx)
+	if err != nil {
+		return err
+	}
+	if owner != l.owner || current != token {
+		return fmt.Errorf("%w: %s held by %q with token %d, expected token %d",
+			ErrLockNotHeld, l.key, owner, current, token)
+	}
+	return nil
+}
+
+// value 锁在 Redis 中的值，调用方需持有 l.mu
+func (l *LeaseLock) value() string {
+	return l.owner + ":" + strconv.FormatInt(l.token, 10)
+}
+
+// parseLockValue 解析 owner:token，owner 本身可能包含冒号
+func parseLockValue(value string) (string, int64, error) {
+	for i := len(value) - 1; i >= 0; i-- {
+		if value[i] != ':' {
+			continue
+		}
+		token, err := strconv.ParseInt(value[i+1:], 10, 64)
+		if err != nil {
+			break
+		}
+		return value[:i], token, nil
+	}
+	return "", 0, fmt.Errorf("invalid lock value: %s", value)
+}
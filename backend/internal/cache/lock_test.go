@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// setupLockClient 创建基于 miniredis 的客户端
+func setupLockClient(t *testing.T) (*miniredis.Miniredis, *Client) {
+	server := miniredis.RunT(t)
+	client := &Client{
+		client: redis.NewClient(&redis.Options{Addr: server.Addr()}),
+		logger: zap.NewNop(),
+	}
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func TestLeaseLock(t *testing.T) {
+	server, client := setupLockClient(t)
+	ctx := context.Background()
+
+	lockA := NewLeaseLock(client, "collector", "instance-a", nil)
+	lockB := NewLeaseLock(client, "collector", "instance-b", nil)
+
+	tokenA, err := lockA.TryAcquire(ctx, 5*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tokenA)
+	assert.Equal(t, tokenA, lockA.Token())
+	require.NoError(t, lockA.Verify(ctx, tokenA))
+
+	_, err = lockB.TryAcquire(ctx, 5*time.Second)
+	assert.ErrorIs(t, err, ErrLockNotAcquired)
+
+	holder, token, err := lockB.Holder(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "instance-a", holder)
+	assert.Equal(t, tokenA, token)
+
+	// 续约延长过期时间
+	server.FastForward(4 * time.Second)
+	require.NoError(t, lockA.Renew(ctx, 5*time.Second))
+	server.FastForward(4 * time.Second)
+	assert.True(t, server.Exists(BuildLockKey("collector")))
+
+	// 过期后被其他实例获取，栅栏令牌递增，旧持有者续约失败
+	server.FastForward(2 * time.Second)
+	tokenB, err := lockB.TryAcquire(ctx, 5*time.Second)
+	require.NoError(t, err)
+	assert.Greater(t, tokenB, tokenA)
+
+	assert.ErrorIs(t, lockA.Renew(ctx, 5*time.Second), ErrLockNotHeld)
+	assert.Zero(t, lockA.Token())
+
+	// 旧令牌校验失败，新持有者的令牌校验通过
+	assert.ErrorIs(t, lockA.Verify(ctx, tokenA), ErrLockNotHeld)
+	assert.ErrorIs(t, lockB.Verify(ctx, tokenA), ErrLockNotHeld)
+	require.NoError(t, lockB.Verify(ctx, tokenB))
+
+	// 旧持有者释放不会删除新持有者的锁
+	require.NoError(t, lockA.Release(ctx))
+	holder, _, err = lockA.Holder(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "instance-b", holder)
+
+	require.NoError(t, lockB.Release(ctx))
+	holder, _, err = lockB.Holder(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, holder)
+
+	tokenA, err = lockA.TryAcquire(ctx, 5*time.Second)
+	require.NoError(t, err)
+	assert.Greater(t, tokenA, tokenB)
+}
+
+func TestParseLockValue(t *testing.T) {
+	owner, token, err := parseLockValue("host:1234:7")
+	require.NoError(t, err)
+	assert.Equal(t, "host:1234", owner)
+	assert.Equal(t, int64(7), token)
+
+	_, _, err = parseLockValue("invalid")
+	assert.Error(t, err)
+}
+
+func TestLeaderElector(t *testing.T) {
+	server, client := setupLockClient(t)
+
+	config := &LeaderConfig{
+		LeaseTTL:      500 * time.Millisecond,
+		RenewInterval: 100 * time.Millisecond,
+		RetryInterval: 50 * time.Millisecond,
+	}
+
+	var running atomic.Int32
+	leaders := make(chan int64, 10)
+	task := func(ctx context.Context, token int64) {
+		running.Add(1)
+		leaders <- token
+		<-ctx.Done()
+		running.Add(-1)
+	}
+
+	electorA := NewLeaderElector(NewLeaseLock(client, "jobs", "a", nil), config, nil)
+	electorB := NewLeaderElector(NewLeaseLock(client, "jobs", "b", nil), config, nil)
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	ctxB, cancelB := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); electorA.Run(ctxA, task) }()
+	firstToken := <-leaders
+	go func() { defer wg.Done(); electorB.Run(ctxB, task) }()
+
+	leader, follower, cancelLeader := electorA, electorB, cancelA
+
+	// 跟随者不执行任务
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, leader.IsLeader())
+	assert.False(t, follower.IsLeader())
+	assert.Equal(t, firstToken, leader.Token())
+
+	// 租约被删除（模拟过期后被接替）时领导者停止任务，跟随者以更大的令牌接替
+	server.Del(BuildLockKey("jobs"))
+	select {
+	case token := <-leaders:
+		assert.Greater(t, token, firstToken)
+	case <-time.After(2 * time.Second):
+		t.Fatal("跟随者未在租约丢失后接替")
+	}
+	assert.Eventually(t, func() bool {
+		return electorA.IsLeader() != electorB.IsLeader() && running.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	if electorB.IsLeader() {
+		leader, follower, cancelLeader = electorB, electorA, cancelB
+	}
+
+	// 领导者退出时释放租约，另一个实例立即接替
+	start := time.Now()
+	cancelLeader()
+	select {
+	case <-leaders:
+	case <-time.After(2 * time.Second):
+		t.Fatal("领导者退出后未完成切换")
+	}
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, follower.IsLeader())
+	assert.False(t, leader.IsLeader())
+	assert.Equal(t, int32(1), running.Load())
+
+	cancelA()
+	cancelB()
+	wg.Wait()
+	assert.Zero(t, running.Load())
+}
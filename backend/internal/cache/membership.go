@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Membership 基于 Redis 有序集合的实例成员表
+// 成员以最近一次心跳时间为分数，超过 TTL 未心跳的成员视为已离开（进程崩溃时无需主动退出）。
+// 时间统一取 Redis 服务器时间，实例之间的时钟偏差不影响存活判断
+type Membership struct {
+	client   *redis.Client
+	key      string
+	memberID string
+	ttl      time.Duration
+	logger   *zap.Logger
+}
+
+// NewMembership 创建成员表，group 区分不同用途的集群（如 collector），memberID 为本实例 ID
+func NewMembership(client *Client, group, memberID string, ttl time.Duration, logger *zap.Logger) *Membership {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Membership{
+		client:   client.GetClient(),
+		key:      BuildMembersKey(group),
+		memberID: memberID,
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+// MemberID 返回本实例 ID
+func (m *Membership) MemberID() string {
+	return m.memberID
+}
+
+// Heartbeat 加入成员表或刷新本实例的心跳时间
+func (m *Membership) Heartbeat(ctx context.Context) error {
+	now, err := m.client.Time(ctx).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get redis time: %w", err)
+	}
+
+	pipe := m.client.TxPipeline()
+	pipe.ZAdd(ctx, m.key, redis.Z{
+		Score:  float64(now.UnixMilli()),
+		Member: m.memberID,
+	})
+	// 所有成员都停止心跳后整个成员表过期
+	pipe.PExpire(ctx, m.key, 2*m.ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to heartbeat membership %s: %w", m.key, err)
+	}
+	return nil
+}
+
+// Leave 主动离开成员表，实例正常退出时调用，其他成员下一次刷新即可重新分配
+func (m *Membership) Leave(ctx context.Context) error {
+	if err := m.client.ZRem(ctx, m.key, m.memberID).Err(); err != nil {
+		return fmt.Errorf("failed to leave membership %s: %w", m.key, err)
+	}
+	return nil
+}
+
+// Members 返回按 ID 排序的存活成员，同时移除心跳超时的成员
+func (m *Membership) Members(ctx context.Context) ([]string, error) {
+	now, err := m.client.Time(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redis time: %w", err)
+	}
+
+	staleBefore := now.Add(-m.ttl).UnixMilli()
+	if err := m.client.ZRemRangeByScore(ctx, m.key, "-inf", "("+strconv.FormatInt(staleBefore, 10)).Err(); err != nil {
+		return nil, fmt.Errorf("failed to prune membership %s: %w", m.key, err)
+	}
+
+	members, err := m.client.ZRange(ctx, m.key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list membership %s: %w", m.key, err)
+	}
+
+	// 有序集合按心跳时间排序，这里统一按 ID 排序，保证所有实例看到相同的顺序
+	sort.Strings(members)
+	return members, nil
+}
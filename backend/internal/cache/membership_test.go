@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembership(t *testing.T) {
+	server, client := setupLockClient(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	server.SetTime(now)
+
+	memberB := NewMembership(client, "collector", "instance-b", 10*time.Second, nil)
+	memberA := NewMembership(client, "collector", "instance-a", 10*time.Second, nil)
+	assert.Equal(t, "instance-a", memberA.MemberID())
+
+	members, err := memberA.Members(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+
+	require.NoError(t, memberB.Heartbeat(ctx))
+	server.SetTime(now.Add(time.Second))
+	require.NoError(t, memberA.Heartbeat(ctx))
+
+	// 按 ID 排序，与心跳顺序无关
+	members, err = memberB.Members(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-a", "instance-b"}, members)
+	assert.True(t, server.Exists(BuildMembersKey("collector")))
+
+	// instance-b 心跳超时后被移除
+	server.SetTime(now.Add(10500 * time.Millisecond))
+	members, err = memberA.Members(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-a"}, members)
+
+	// 重新心跳即重新加入
+	require.NoError(t, memberB.Heartbeat(ctx))
+	require.NoError(t, memberA.Leave(ctx))
+	members, err = memberA.Members(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instance-b"}, members)
+
+	// 所有成员停止心跳后成员表过期
+	server.FastForward(20 * time.Second)
+	assert.False(t, server.Exists(BuildMembersKey("collector")))
+}
@@ -7,20 +7,48 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
 
 // PriceData 价格数据结构
+// 价格和成交量使用 decimal 精确表示，JSON 中序列化为数字（见 models.JSONDecimal）
 type PriceData struct {
-	Symbol      string    `json:"symbol"`
-	LastPrice   float64   `json:"last_price"`
-	AskPrice    *float64  `json:"ask_price,omitempty"`
-	BidPrice    *float64  `json:"bid_price,omitempty"`
-	High24h     *float64  `json:"high_24h,omitempty"`
-	Low24h      *float64  `json:"low_24h,omitempty"`
-	Change24h   *float64  `json:"change_24h,omitempty"`
-	BaseVolume  *float64  `json:"base_volume,omitempty"`
-	QuoteVolume *float64  `json:"quote_volume,omitempty"`
-	Timestamp   time.Time `json:"timestamp"`
+	Symbol      string           `json:"symbol"`
+	LastPrice   decimal.Decimal  `json:"last_price"`
+	AskPrice    *decimal.Decimal `json:"ask_price,omitempty"`
+	BidPrice    *decimal.Decimal `json:"bid_price,omitempty"`
+	High24h     *decimal.Decimal `json:"high_24h,omitempty"`
+	Low24h      *decimal.Decimal `json:"low_24h,omitempty"`
+	Change24h   *float64         `json:"change_24h,omitempty"`
+	BaseVolume  *decimal.Decimal `json:"base_volume,omitempty"`
+	QuoteVolume *decimal.Decimal `json:"quote_volume,omitempty"`
+	Timestamp   time.Time        `json:"timestamp"`
+}
+
+// MarshalJSON 价格和成交量输出为 JSON 数字
+func (p PriceData) MarshalJSON() ([]byte, error) {
+	type priceData PriceData
+	return json.Marshal(struct {
+		priceData
+		LastPrice   models.JSONDecimal  `json:"last_price"`
+		AskPrice    *models.JSONDecimal `json:"ask_price,omitempty"`
+		BidPrice    *models.JSONDecimal `json:"bid_price,omitempty"`
+		High24h     *models.JSONDecimal `json:"high_24h,omitempty"`
+		Low24h      *models.JSONDecimal `json:"low_24h,omitempty"`
+		BaseVolume  *models.JSONDecimal `json:"base_volume,omitempty"`
+		QuoteVolume *models.JSONDecimal `json:"quote_volume,omitempty"`
+	}{
+		priceData:   priceData(p),
+		LastPrice:   models.JSONDecimal(p.LastPrice),
+		AskPrice:    models.JSONDecimalPtr(p.AskPrice),
+		BidPrice:    models.JSONDecimalPtr(p.BidPrice),
+		High24h:     models.JSONDecimalPtr(p.High24h),
+		Low24h:      models.JSONDecimalPtr(p.Low24h),
+		BaseVolume:  models.JSONDecimalPtr(p.BaseVolume),
+		QuoteVolume: models.JSONDecimalPtr(p.QuoteVolume),
+	})
 }
 
 // MetricsData 指标数据结构
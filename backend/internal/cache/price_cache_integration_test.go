@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -60,7 +61,7 @@ func TestPriceCache_Integration_SetGetPrice(t *testing.T) {
 
 	t.Run("设置并获取价格", func(t *testing.T) {
 		data := createTestPriceData("BTCUSDT")
-		data.LastPrice = 50000.123456
+		data.LastPrice = decimal.RequireFromString("50000.123456")
 
 		err := cache.SetPrice(ctx, data)
 		require.NoError(t, err)
@@ -69,7 +70,7 @@ func TestPriceCache_Integration_SetGetPrice(t *testing.T) {
 		retrieved, err := cache.GetPrice(ctx, "BTCUSDT")
 		require.NoError(t, err)
 		assert.Equal(t, "BTCUSDT", retrieved.Symbol)
-		assert.Equal(t, 50000.123456, retrieved.LastPrice)
+		assert.Equal(t, "50000.123456", retrieved.LastPrice.String())
 	})
 
 	t.Run("验证 TTL 过期", func(t *testing.T) {
@@ -120,7 +121,7 @@ func TestPriceCache_Integration_BatchOperations(t *testing.T) {
 			symbols[i] = symbol
 
 			data := createTestPriceData(symbol)
-			data.LastPrice = float64(1000 + i)
+			data.LastPrice = decimal.NewFromInt(int64(1000 + i))
 
 			err := cache.SetPrice(ctx, data)
 			require.NoError(t, err)
@@ -139,7 +140,7 @@ func TestPriceCache_Integration_BatchOperations(t *testing.T) {
 		for i, symbol := range symbols {
 			data, exists := result[symbol]
 			assert.True(t, exists)
-			assert.Equal(t, float64(1000+i), data.LastPrice)
+			assert.Equal(t, int64(1000+i), data.LastPrice.IntPart())
 		}
 
 		// 性能要求：< 100ms
@@ -262,7 +263,7 @@ func TestPriceCache_Integration_ConcurrentAccess(t *testing.T) {
 				ctx := context.Background()
 				symbol := fmt.Sprintf("CONCURRENT%dUSDT", id)
 				data := createTestPriceData(symbol)
-				data.LastPrice = float64(id)
+				data.LastPrice = decimal.NewFromInt(int64(id))
 
 				err := cache.SetPrice(ctx, data)
 				errChan <- err
@@ -2,13 +2,17 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
 
 // setupTestRedis 创建测试用的 Redis 客户端（使用 miniredis）
@@ -32,24 +36,18 @@ func setupTestRedis(t *testing.T) (*Client, *miniredis.Miniredis) {
 
 // createTestPriceData 创建测试用的价格数据
 func createTestPriceData(symbol string) *PriceData {
-	askPrice := 50001.0
-	bidPrice := 49999.0
-	high24h := 51000.0
-	low24h := 49000.0
 	change24h := 2.5
-	baseVolume := 1000.5
-	quoteVolume := 50000000.0
 
 	return &PriceData{
 		Symbol:      symbol,
-		LastPrice:   50000.0,
-		AskPrice:    &askPrice,
-		BidPrice:    &bidPrice,
-		High24h:     &high24h,
-		Low24h:      &low24h,
+		LastPrice:   decimal.NewFromInt(50000),
+		AskPrice:    models.DecimalPtr(decimal.NewFromInt(50001)),
+		BidPrice:    models.DecimalPtr(decimal.NewFromInt(49999)),
+		High24h:     models.DecimalPtr(decimal.NewFromInt(51000)),
+		Low24h:      models.DecimalPtr(decimal.NewFromInt(49000)),
 		Change24h:   &change24h,
-		BaseVolume:  &baseVolume,
-		QuoteVolume: &quoteVolume,
+		BaseVolume:  models.DecimalPtr(decimal.RequireFromString("1000.5")),
+		QuoteVolume: models.DecimalPtr(decimal.NewFromInt(50000000)),
 		Timestamp:   time.Now().UTC(),
 	}
 }
@@ -121,9 +119,9 @@ func TestPriceCache_GetPrice(t *testing.T) {
 		require.NoError(t, err)
 		assert.NotNil(t, data)
 		assert.Equal(t, "BTCUSDT", data.Symbol)
-		assert.Equal(t, 50000.0, data.LastPrice)
+		assert.Equal(t, "50000", data.LastPrice.String())
 		assert.NotNil(t, data.AskPrice)
-		assert.Equal(t, 50001.0, *data.AskPrice)
+		assert.Equal(t, "50001", data.AskPrice.String())
 	})
 
 	t.Run("获取不存在的价格应返回错误", func(t *testing.T) {
@@ -137,6 +135,31 @@ func TestPriceCache_GetPrice(t *testing.T) {
 	})
 }
 
+func TestPriceData_MarshalJSON(t *testing.T) {
+	data := createTestPriceData("BTCUSDT")
+	data.AskPrice = nil
+
+	encoded, err := json.Marshal(data)
+	require.NoError(t, err)
+
+	// 价格输出为数字，未设置的可选字段省略
+	assert.Contains(t, string(encoded), `"last_price":50000`)
+	assert.Contains(t, string(encoded), `"base_volume":1000.5`)
+	assert.NotContains(t, string(encoded), `ask_price`)
+
+	// 其他包序列化 decimal 时仍为默认的字符串格式
+	plain, err := json.Marshal(struct {
+		Price decimal.Decimal `json:"price"`
+	}{decimal.RequireFromString("1.5")})
+	require.NoError(t, err)
+	assert.Equal(t, `{"price":"1.5"}`, string(plain))
+
+	var decoded PriceData
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, "1000.5", decoded.BaseVolume.String())
+	assert.Nil(t, decoded.AskPrice)
+}
+
 func TestPriceCache_GetMultiplePrices(t *testing.T) {
 	client, mr := setupTestRedis(t)
 	defer mr.Close()
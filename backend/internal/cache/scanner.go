@@ -0,0 +1,662 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// 扫描器排序指标
+const (
+	ScannerSortChangeRate  = "change_rate"  // 指定窗口的价格变化率
+	ScannerSortVolume      = "volume"       // 24小时成交量
+	ScannerSortVolumeSurge = "volume_surge" // 成交量激增倍数
+	ScannerSortFundingRate = "funding_rate" // 资金费率
+	ScannerSortSpread      = "spread"       // 买卖价差百分比
+
+	// scannerMetricPrice 最新价格，仅用于价格过滤
+	scannerMetricPrice = "price"
+)
+
+// 扫描器排序方向
+const (
+	ScannerOrderDesc = "desc"
+	ScannerOrderAsc  = "asc"
+)
+
+const (
+	// DefaultScannerMaxAge 默认忽略超过该时长未更新的交易对
+	DefaultScannerMaxAge = 2 * time.Minute
+
+	// DefaultScannerPageSize 默认分页大小
+	DefaultScannerPageSize = 20
+
+	// 行情快照哈希字段
+	scannerFieldLastPrice   = "last_price"
+	scannerFieldVolume24h   = "volume_24h"
+	scannerFieldVolumeSurge = "volume_surge"
+	scannerFieldFundingRate = "funding_rate"
+	scannerFieldSpread      = "spread"
+	scannerFieldUpdatedAt   = "updated_at"
+	scannerFieldChangeRate  = "change_rate:"
+)
+
+// ScannerSortFields 支持的排序指标
+var ScannerSortFields = []string{
+	ScannerSortChangeRate,
+	ScannerSortVolume,
+	ScannerSortVolumeSurge,
+	ScannerSortFundingRate,
+	ScannerSortSpread,
+}
+
+// ScannerTicker 单个交易对的扫描器行情更新，未设置的指标保持原值
+type ScannerTicker struct {
+	Symbol      string             `json:"symbol"`
+	Timestamp   time.Time          `json:"timestamp"`
+	LastPrice   *float64           `json:"last_price,omitempty"`
+	Volume24h   *float64           `json:"volume_24h,omitempty"`
+	VolumeSurge *float64           `json:"volume_surge,omitempty"` // 当前成交量/基线成交量
+	FundingRate *float64           `json:"funding_rate,omitempty"`
+	BidPrice    *decimal.Decimal   `json:"bid_price,omitempty"`    // 买一价，用于精确计算价差
+	AskPrice    *decimal.Decimal   `json:"ask_price,omitempty"`    // 卖一价，用于精确计算价差
+	ChangeRates map[string]float64 `json:"change_rates,omitempty"` // 时间窗口 -> 变化率(%)
+}
+
+// ScannerQuery 扫描器查询条件
+type ScannerQuery struct {
+	SortBy   string                          // 排序指标
+	Window   string                          // 时间窗口，按变化率排序时必填
+	Order    string                          // desc 或 asc，默认 desc
+	Filters  *models.MonitoringConfigFilters // 价格、成交量、交易对过滤
+	Page     int                             // 页码，从1开始
+	PageSize int                             // 每页数量
+	MaxAge   time.Duration                   // 最大数据时效，<=0 使用默认值
+}
+
+// ScannerEntry 扫描结果中的单个交易对
+type ScannerEntry struct {
+	Rank        int                `json:"rank"`
+	Symbol      string             `json:"symbol"`
+	Score       float64            `json:"score"`
+	LastPrice   *float64           `json:"last_price,omitempty"`
+	Volume24h   *float64           `json:"volume_24h,omitempty"`
+	VolumeSurge *float64           `json:"volume_surge,omitempty"`
+	FundingRate *float64           `json:"funding_rate,omitempty"`
+	Spread      *float64           `json:"spread,omitempty"`
+	ChangeRates map[string]float64 `json:"change_rates,omitempty"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// ScannerResult 扫描器查询结果
+type ScannerResult struct {
+	Entries []*ScannerEntry `json:"entries"`
+	Total   int             `json:"total"` // 过滤后的总数
+}
+
+// ScannerIndex 基于 Redis 有序集合的市场扫描器索引
+type ScannerIndex interface {
+	// UpdateTicker 更新单个交易对的排行指标
+	UpdateTicker(ctx context.Context, ticker *ScannerTicker) error
+
+	// UpdateTickers 批量更新交易对的排行指标
+	UpdateTickers(ctx context.Context, tickers []*ScannerTicker) error
+
+	// Query 按指标排序并过滤、分页
+	Query(ctx context.Context, query *ScannerQuery) (*ScannerResult, error)
+
+	// Prune 从排行中移除在指定时间之前未更新的交易对，返回移除数量
+	Prune(ctx context.Context, olderThan time.Time) (int, error)
+}
+
+// scannerIndexImpl ScannerIndex 实现
+type scannerIndexImpl struct {
+	client *redis.Client
+}
+
+// NewScannerIndex 创建 ScannerIndex 实例
+func NewScannerIndex(client *Client) ScannerIndex {
+	return &scannerIndexImpl{
+		client: client.GetClient(),
+	}
+}
+
+// ValidScannerSort 判断排序指标是否受支持
+func ValidScannerSort(sortBy string) bool {
+	for _, field := range ScannerSortFields {
+		if field == sortBy {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateTicker 更新单个交易对的排行指标
+func (s *scannerIndexImpl) UpdateTicker(ctx context.Context, ticker *ScannerTicker) error {
+	return s.UpdateTickers(ctx, []*ScannerTicker{ticker})
+}
+
+// UpdateTickers 批量更新交易对的排行指标
+func (s *scannerIndexImpl) UpdateTickers(ctx context.Context, tickers []*ScannerTicker) error {
+	if len(tickers) == 0 {
+		return nil
+	}
+
+	pipe := s.client.Pipeline()
+	rankKeys := make(map[string]struct{})
+
+	for _, ticker := range tickers {
+		if ticker == nil || ticker.Symbol == "" {
+			return fmt.Errorf("invalid scanner ticker")
+		}
+
+		timestamp := ticker.Timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+
+		fields := make(map[string]interface{})
+		addMetric := func(metric, window, field string, value float64) {
+			key := BuildScannerRankKey(metric, window)
+			pipe.ZAdd(ctx, key, redis.Z{Score: value, Member: ticker.Symbol})
+			rankKeys[key] = struct{}{}
+			fields[field] = value
+		}
+
+		if ticker.LastPrice != nil {
+			addMetric(scannerMetricPrice, "", scannerFieldLastPrice, *ticker.LastPrice)
+		}
+		if ticker.Volume24h != nil {
+			addMetric(ScannerSortVolume, "", scannerFieldVolume24h, *ticker.Volume24h)
+		}
+		if ticker.VolumeSurge != nil {
+			addMetric(ScannerSortVolumeSurge, "", scannerFieldVolumeSurge, *ticker.VolumeSurge)
+		}
+		if ticker.FundingRate != nil {
+			addMetric(ScannerSortFundingRate, "", scannerFieldFundingRate, *ticker.FundingRate)
+		}
+		if spread, ok := calculateSpread(ticker.BidPrice, ticker.AskPrice); ok {
+			addMetric(ScannerSortSpread, "", scannerFieldSpread, spread)
+		}
+		for window, rate := range ticker.ChangeRates {
+			addMetric(ScannerSortChangeRate, window, scannerFieldChangeRate+window, rate)
+		}
+
+		fields[scannerFieldUpdatedAt] = timestamp.UnixMilli()
+
+		tickerKey := BuildScannerTickerKey(ticker.Symbol)
+		pipe.HSet(ctx, tickerKey, fields)
+		pipe.Expire(ctx, tickerKey, TTLScannerTicker)
+		pipe.ZAdd(ctx, BuildScannerUpdatedKey(), redis.Z{
+			Score:  float64(timestamp.UnixMilli()),
+			Member: ticker.Symbol,
+		})
+	}
+
+	if len(rankKeys) > 0 {
+		members := make([]interface{}, 0, len(rankKeys))
+		for key := range rankKeys {
+			members = append(members, key)
+		}
+		pipe.SAdd(ctx, BuildScannerRankKeysKey(), members...)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update scanner index: %w", err)
+	}
+
+	return nil
+}
+
+// Query 按指标排序并过滤、分页
+func (s *scannerIndexImpl) Query(ctx context.Context, query *ScannerQuery) (*ScannerResult, error) {
+	if query == nil {
+		return nil, fmt.Errorf("scanner query cannot be nil")
+	}
+	if !ValidScannerSort(query.SortBy) {
+		return nil, fmt.Errorf("unsupported scanner sort field: %s", query.SortBy)
+	}
+
+	window := ""
+	if query.SortBy == ScannerSortChangeRate {
+		if query.Window == "" {
+			return nil, fmt.Errorf("window is required when sorting by %s", ScannerSortChangeRate)
+		}
+		window = query.Window
+	}
+
+	order := query.Order
+	if order == "" {
+		order = ScannerOrderDesc
+	}
+	if order != ScannerOrderDesc && order != ScannerOrderAsc {
+		return nil, fmt.Errorf("unsupported scanner order: %s", query.Order)
+	}
+
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := query.PageSize
+	if pageSize < 1 {
+		pageSize = DefaultScannerPageSize
+	}
+	maxAge := query.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultScannerMaxAge
+	}
+
+	filters := query.Filters
+	if filters == nil {
+		filters = &models.MonitoringConfigFilters{}
+	}
+
+	rankKey := BuildScannerRankKey(query.SortBy, window)
+	since := time.Now().Add(-maxAge)
+	start := (page - 1) * pageSize
+
+	// 没有交易对、价格、成交量过滤时直接在有序集合上分页，不读取整个排行
+	if len(filters.Symbols) == 0 && filters.MinPrice == nil && filters.MaxPrice == nil &&
+		filters.MinVolume == nil && filters.MaxVolume == nil {
+		return s.queryRanked(ctx, rankKey, order == ScannerOrderDesc, since, start, pageSize)
+	}
+
+	candidates, err := s.loadCandidates(ctx, rankKey, filters.Symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err = s.applyFilters(ctx, candidates, filters, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// 与有序集合的顺序一致：同分成员升序时按名称升序，降序时按名称降序
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score == candidates[j].Score {
+			if order == ScannerOrderAsc {
+				return candidates[i].Member.(string) < candidates[j].Member.(string)
+			}
+			return candidates[i].Member.(string) > candidates[j].Member.(string)
+		}
+		if order == ScannerOrderAsc {
+			return candidates[i].Score < candidates[j].Score
+		}
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	result := &ScannerResult{
+		Entries: make([]*ScannerEntry, 0),
+		Total:   len(candidates),
+	}
+
+	if start >= len(candidates) {
+		return result, nil
+	}
+	end := start + pageSize
+	if end > len(candidates) {
+		end = len(candidates)
+	}
+
+	entries, err := s.loadEntries(ctx, candidates[start:end], start)
+	if err != nil {
+		return nil, err
+	}
+	result.Entries = entries
+
+	return result, nil
+}
+
+// Prune 从排行中移除在指定时间之前未更新的交易对，返回移除数量
+func (s *scannerIndexImpl) Prune(ctx context.Context, olderThan time.Time) (int, error) {
+	updatedKey := BuildScannerUpdatedKey()
+	max := strconv.FormatInt(olderThan.UnixMilli(), 10)
+
+	stale, err := s.client.ZRangeByScore(ctx, updatedKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + max,
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stale scanner symbols: %w", err)
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	rankKeys, err := s.client.SMembers(ctx, BuildScannerRankKeysKey()).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get scanner rank keys: %w", err)
+	}
+
+	members := make([]interface{}, len(stale))
+	for i, symbol := range stale {
+		members[i] = symbol
+	}
+
+	pipe := s.client.Pipeline()
+	for _, key := range rankKeys {
+		pipe.ZRem(ctx, key, members...)
+	}
+	for _, symbol := range stale {
+		pipe.Del(ctx, BuildScannerTickerKey(symbol))
+	}
+	pipe.ZRem(ctx, updatedKey, members...)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to prune scanner index: %w", err)
+	}
+
+	return len(stale), nil
+}
+
+// queryRanked 按排名读取一页交易对，只跳过超过时效的交易对
+// 过期交易对会被 Prune 定期移除，数量通常很少：先查出它们在排行中的位置，
+// 再把过滤后的页起点换算为排行中的位置，只读取这一页（加上页内的过期交易对）
+func (s *scannerIndexImpl) queryRanked(ctx context.Context, rankKey string, desc bool, since time.Time, start, pageSize int) (*ScannerResult, error) {
+	stale, err := s.client.ZRangeByScore(ctx, BuildScannerUpdatedKey(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(since.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale scanner symbols: %w", err)
+	}
+
+	pipe := s.client.Pipeline()
+	cardCmd := pipe.ZCard(ctx, rankKey)
+	rankCmds := make([]*redis.IntCmd, len(stale))
+	for i, symbol := range stale {
+		if desc {
+			rankCmds[i] = pipe.ZRevRank(ctx, rankKey, symbol)
+		} else {
+			rankCmds[i] = pipe.ZRank(ctx, rankKey, symbol)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read scanner rank: %w", err)
+	}
+
+	staleRanks := make([]int64, 0, len(stale))
+	staleSymbols := make(map[string]struct{}, len(stale))
+	for i, cmd := range rankCmds {
+		rank, err := cmd.Result()
+		if err != nil {
+			// 不在该排行中
+			continue
+		}
+		staleRanks = append(staleRanks, rank)
+		staleSymbols[stale[i]] = struct{}{}
+	}
+	sort.Slice(staleRanks, func(i, j int) bool { return staleRanks[i] < staleRanks[j] })
+
+	result := &ScannerResult{
+		Entries: make([]*ScannerEntry, 0),
+		Total:   int(cardCmd.Val()) - len(staleRanks),
+	}
+	if start >= result.Total {
+		return result, nil
+	}
+
+	// 过滤后的第 start 个交易对在排行中的位置：加上排在它之前的过期交易对数
+	rawStart := int64(start)
+	skipped := 0
+	for _, rank := range staleRanks {
+		if rank > rawStart {
+			break
+		}
+		rawStart++
+		skipped++
+	}
+	rawStop := rawStart + int64(pageSize+len(staleRanks)-skipped) - 1
+
+	var ranked []redis.Z
+	if desc {
+		ranked, err = s.client.ZRevRangeWithScores(ctx, rankKey, rawStart, rawStop).Result()
+	} else {
+		ranked, err = s.client.ZRangeWithScores(ctx, rankKey, rawStart, rawStop).Result()
+	}
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read scanner rank: %w", err)
+	}
+
+	page := make([]redis.Z, 0, pageSize)
+	for _, item := range ranked {
+		if _, ok := staleSymbols[item.Member.(string)]; ok {
+			continue
+		}
+		page = append(page, item)
+		if len(page) == pageSize {
+			break
+		}
+	}
+
+	entries, err := s.loadEntries(ctx, page, start)
+	if err != nil {
+		return nil, err
+	}
+	result.Entries = entries
+	return result, nil
+}
+
+// loadCandidates 读取排行集合中的候选交易对，指定交易对时只读取这些成员
+func (s *scannerIndexImpl) loadCandidates(ctx context.Context, rankKey string, symbols []string) ([]redis.Z, error) {
+	if len(symbols) == 0 {
+		candidates, err := s.client.ZRangeWithScores(ctx, rankKey, 0, -1).Result()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("failed to read scanner rank: %w", err)
+		}
+		return candidates, nil
+	}
+
+	scores, err := s.scores(ctx, rankKey, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]redis.Z, 0, len(scores))
+	for _, symbol := range symbols {
+		if score, ok := scores[symbol]; ok {
+			candidates = append(candidates, redis.Z{Score: score, Member: symbol})
+		}
+	}
+	return candidates, nil
+}
+
+// applyFilters 按更新时间、价格和成交量过滤候选交易对
+func (s *scannerIndexImpl) applyFilters(ctx context.Context, candidates []redis.Z, filters *models.MonitoringConfigFilters, since time.Time) ([]redis.Z, error) {
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	symbols := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		symbols[i] = candidate.Member.(string)
+	}
+
+	// 指定交易对时逐个读取分数；否则按分数范围读取满足条件的成员，不为每个候选发送 ZSCORE
+	lookup := func(key string, min, max *float64) (map[string]float64, error) {
+		if len(filters.Symbols) > 0 {
+			return s.scores(ctx, key, symbols)
+		}
+		return s.scoresInRange(ctx, key, scoreBound(min, "-inf"), scoreBound(max, "+inf"))
+	}
+
+	sinceMillis := float64(since.UnixMilli())
+	updated, err := lookup(BuildScannerUpdatedKey(), &sinceMillis, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices, volumes map[string]float64
+	if filters.MinPrice != nil || filters.MaxPrice != nil {
+		if prices, err = lookup(BuildScannerRankKey(scannerMetricPrice, ""), filters.MinPrice, filters.MaxPrice); err != nil {
+			return nil, err
+		}
+	}
+	if filters.MinVolume != nil || filters.MaxVolume != nil {
+		if volumes, err = lookup(BuildScannerRankKey(ScannerSortVolume, ""), filters.MinVolume, filters.MaxVolume); err != nil {
+			return nil, err
+		}
+	}
+
+	filtered := candidates[:0]
+	for i, candidate := range candidates {
+		symbol := symbols[i]
+		if updatedAt, ok := updated[symbol]; !ok || updatedAt < sinceMillis {
+			continue
+		}
+		if prices != nil && !inRange(prices, symbol, filters.MinPrice, filters.MaxPrice) {
+			continue
+		}
+		if volumes != nil && !inRange(volumes, symbol, filters.MinVolume, filters.MaxVolume) {
+			continue
+		}
+		filtered = append(filtered, candidate)
+	}
+
+	return filtered, nil
+}
+
+// loadEntries 读取当前页交易对的行情快照
+func (s *scannerIndexImpl) loadEntries(ctx context.Context, page []redis.Z, offset int) ([]*ScannerEntry, error) {
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(page))
+	for i, item := range page {
+		cmds[i] = pipe.HGetAll(ctx, BuildScannerTickerKey(item.Member.(string)))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read scanner tickers: %w", err)
+	}
+
+	entries := make([]*ScannerEntry, len(page))
+	for i, item := range page {
+		entry := &ScannerEntry{
+			Rank:   offset + i + 1,
+			Symbol: item.Member.(string),
+			Score:  item.Score,
+		}
+
+		fields, err := cmds[i].Result()
+		if err == nil {
+			parseScannerFields(entry, fields)
+		}
+		entries[i] = entry
+	}
+
+	return entries, nil
+}
+
+// scores 批量读取有序集合中指定成员的分数，不存在的成员不出现在结果中
+func (s *scannerIndexImpl) scores(ctx context.Context, key string, symbols []string) (map[string]float64, error) {
+	// ZMSCORE 无法区分缺失成员与0分，这里逐个 ZSCORE 并通过 Pipeline 批量执行
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.FloatCmd, len(symbols))
+	for i, symbol := range symbols {
+		cmds[i] = pipe.ZScore(ctx, key, symbol)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read scanner scores: %w", err)
+	}
+
+	result := make(map[string]float64, len(symbols))
+	for i, cmd := range cmds {
+		score, err := cmd.Result()
+		if err != nil {
+			continue
+		}
+		result[symbols[i]] = score
+	}
+
+	return result, nil
+}
+
+// scoresInRange 读取有序集合中分数在 [min, max] 范围内的成员
+func (s *scannerIndexImpl) scoresInRange(ctx context.Context, key, min, max string) (map[string]float64, error) {
+	members, err := s.client.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read scanner scores: %w", err)
+	}
+
+	result := make(map[string]float64, len(members))
+	for _, member := range members {
+		result[member.Member.(string)] = member.Score
+	}
+	return result, nil
+}
+
+// scoreBound 将可选的分数边界转换为 ZRANGEBYSCORE 参数
+func scoreBound(value *float64, unbounded string) string {
+	if value == nil {
+		return unbounded
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// parseScannerFields 将行情快照哈希解析到扫描结果
+func parseScannerFields(entry *ScannerEntry, fields map[string]string) {
+	for field, raw := range fields {
+		if field == scannerFieldUpdatedAt {
+			if millis, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				entry.UpdatedAt = time.UnixMilli(millis)
+			}
+			continue
+		}
+
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case field == scannerFieldLastPrice:
+			entry.LastPrice = &value
+		case field == scannerFieldVolume24h:
+			entry.Volume24h = &value
+		case field == scannerFieldVolumeSurge:
+			entry.VolumeSurge = &value
+		case field == scannerFieldFundingRate:
+			entry.FundingRate = &value
+		case field == scannerFieldSpread:
+			entry.Spread = &value
+		case strings.HasPrefix(field, scannerFieldChangeRate):
+			if entry.ChangeRates == nil {
+				entry.ChangeRates = make(map[string]float64)
+			}
+			entry.ChangeRates[strings.TrimPrefix(field, scannerFieldChangeRate)] = value
+		}
+	}
+}
+
+// calculateSpread 计算买卖价差占中间价的百分比
+// 价差通常只有几个最小价格变动单位，用 decimal 计算避免两个相近浮点数相减的误差，结果作为排行分数转换为 float64
+func calculateSpread(bid, ask *decimal.Decimal) (float64, bool) {
+	if bid == nil || ask == nil || bid.Sign() <= 0 || ask.Sign() <= 0 {
+		return 0, false
+	}
+	mid := bid.Add(*ask).Div(decimal.NewFromInt(2))
+	return ask.Sub(*bid).Div(mid).Mul(decimal.NewFromInt(100)).InexactFloat64(), true
+}
+
+// inRange 判断成员分数是否在 [min, max] 范围内，缺失分数视为不满足
+func inRange(scores map[string]float64, symbol string, min, max *float64) bool {
+	value, ok := scores[symbol]
+	if !ok {
+		return false
+	}
+	if min != nil && value < *min {
+		return false
+	}
+	if max != nil && value > *max {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// seedScannerTickers 写入测试用的扫描器行情
+func seedScannerTickers(t *testing.T, index ScannerIndex) {
+	now := time.Now()
+	tickers := []*ScannerTicker{
+		{
+			Symbol: "BTCUSDT", Timestamp: now,
+			LastPrice: floatPtr(50000), Volume24h: floatPtr(9000000), VolumeSurge: floatPtr(1.2),
+			FundingRate: floatPtr(0.0001), BidPrice: models.DecimalPtr(decimal.NewFromInt(49999)), AskPrice: models.DecimalPtr(decimal.NewFromInt(50001)),
+			ChangeRates: map[string]float64{"1m": 0.5, "5m": 1.5},
+		},
+		{
+			Symbol: "ETHUSDT", Timestamp: now,
+			LastPrice: floatPtr(3000), Volume24h: floatPtr(5000000), VolumeSurge: floatPtr(3.5),
+			FundingRate: floatPtr(-0.0002), BidPrice: models.DecimalPtr(decimal.NewFromInt(2999)), AskPrice: models.DecimalPtr(decimal.NewFromInt(3001)),
+			ChangeRates: map[string]float64{"1m": -2.0, "5m": 0.2},
+		},
+		{
+			Symbol: "DOGEUSDT", Timestamp: now,
+			LastPrice: floatPtr(0.1), Volume24h: floatPtr(800000), VolumeSurge: floatPtr(0.8),
+			ChangeRates: map[string]float64{"1m": 4.0},
+		},
+		{
+			// 超过默认时效的数据不出现在结果中
+			Symbol: "XRPUSDT", Timestamp: now.Add(-10 * time.Minute),
+			LastPrice: floatPtr(0.5), Volume24h: floatPtr(7000000),
+			ChangeRates: map[string]float64{"1m": 9.0},
+		},
+	}
+	require.NoError(t, index.UpdateTickers(context.Background(), tickers))
+}
+
+func TestScannerIndex_Query(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	index := NewScannerIndex(client)
+	seedScannerTickers(t, index)
+	ctx := context.Background()
+
+	t.Run("按变化率降序", func(t *testing.T) {
+		result, err := index.Query(ctx, &ScannerQuery{SortBy: ScannerSortChangeRate, Window: "1m"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Total)
+		require.Len(t, result.Entries, 3)
+		assert.Equal(t, "DOGEUSDT", result.Entries[0].Symbol)
+		assert.Equal(t, 1, result.Entries[0].Rank)
+		assert.Equal(t, "BTCUSDT", result.Entries[1].Symbol)
+		assert.Equal(t, "ETHUSDT", result.Entries[2].Symbol)
+
+		btc := result.Entries[1]
+		assert.Equal(t, 0.5, btc.Score)
+		assert.Equal(t, 50000.0, *btc.LastPrice)
+		assert.Equal(t, map[string]float64{"1m": 0.5, "5m": 1.5}, btc.ChangeRates)
+		assert.InDelta(t, 0.004, *btc.Spread, 1e-9)
+		assert.False(t, btc.UpdatedAt.IsZero())
+	})
+
+	t.Run("升序和分页", func(t *testing.T) {
+		result, err := index.Query(ctx, &ScannerQuery{
+			SortBy: ScannerSortVolume, Order: ScannerOrderAsc, Page: 2, PageSize: 2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Total)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, "BTCUSDT", result.Entries[0].Symbol)
+		assert.Equal(t, 3, result.Entries[0].Rank)
+	})
+
+	t.Run("价格和成交量过滤", func(t *testing.T) {
+		result, err := index.Query(ctx, &ScannerQuery{
+			SortBy: ScannerSortVolumeSurge,
+			Filters: &models.MonitoringConfigFilters{
+				MinPrice:  floatPtr(1),
+				MaxVolume: floatPtr(6000000),
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, "ETHUSDT", result.Entries[0].Symbol)
+	})
+
+	t.Run("指定交易对", func(t *testing.T) {
+		result, err := index.Query(ctx, &ScannerQuery{
+			SortBy:  ScannerSortFundingRate,
+			Filters: &models.MonitoringConfigFilters{Symbols: []string{"ETHUSDT", "DOGEUSDT", "BTCUSDT"}},
+		})
+		require.NoError(t, err)
+		// DOGEUSDT 没有资金费率，不参与排行
+		require.Len(t, result.Entries, 2)
+		assert.Equal(t, "BTCUSDT", result.Entries[0].Symbol)
+		assert.Equal(t, "ETHUSDT", result.Entries[1].Symbol)
+	})
+
+	t.Run("放宽时效", func(t *testing.T) {
+		result, err := index.Query(ctx, &ScannerQuery{
+			SortBy: ScannerSortChangeRate, Window: "1m", MaxAge: time.Hour, PageSize: 1,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Total)
+		assert.Equal(t, "XRPUSDT", result.Entries[0].Symbol)
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		_, err := index.Query(ctx, nil)
+		assert.Error(t, err)
+		_, err = index.Query(ctx, &ScannerQuery{SortBy: "market_cap"})
+		assert.Error(t, err)
+		_, err = index.Query(ctx, &ScannerQuery{SortBy: ScannerSortChangeRate})
+		assert.Error(t, err)
+		_, err = index.Query(ctx, &ScannerQuery{SortBy: ScannerSortVolume, Order: "random"})
+		assert.Error(t, err)
+		assert.Error(t, index.UpdateTicker(ctx, &ScannerTicker{}))
+	})
+}
+
+func TestScannerIndex_QueryRankedPages(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	index := NewScannerIndex(client)
+	ctx := context.Background()
+
+	// 12个交易对，每隔3个一个过期，成交量两两相同以覆盖同分排序
+	now := time.Now()
+	tickers := make([]*ScannerTicker, 0, 12)
+	for i := 0; i < 12; i++ {
+		timestamp := now
+		if i%3 == 0 {
+			timestamp = now.Add(-10 * time.Minute)
+		}
+		tickers = append(tickers, &ScannerTicker{
+			Symbol:    fmt.Sprintf("SYM%02dUSDT", i),
+			Timestamp: timestamp,
+			Volume24h: floatPtr(float64(i / 2)),
+		})
+	}
+	require.NoError(t, index.UpdateTickers(ctx, tickers))
+
+	// 成交量过滤覆盖所有交易对，走读取全部候选后排序的路径，作为对照
+	everyVolume := &models.MonitoringConfigFilters{MinVolume: floatPtr(-1)}
+
+	for _, order := range []string{ScannerOrderDesc, ScannerOrderAsc} {
+		expected, err := index.Query(ctx, &ScannerQuery{SortBy: ScannerSortVolume, Order: order, Filters: everyVolume, PageSize: 100})
+		require.NoError(t, err)
+		require.Equal(t, 8, expected.Total)
+
+		var symbols []string
+		for page := 1; page <= 4; page++ {
+			result, err := index.Query(ctx, &ScannerQuery{SortBy: ScannerSortVolume, Order: order, Page: page, PageSize: 3})
+			require.NoError(t, err)
+			assert.Equal(t, 8, result.Total)
+			for i, entry := range result.Entries {
+				assert.Equal(t, (page-1)*3+i+1, entry.Rank)
+				symbols = append(symbols, entry.Symbol)
+			}
+		}
+
+		expectedSymbols := make([]string, 0, len(expected.Entries))
+		for _, entry := range expected.Entries {
+			expectedSymbols = append(expectedSymbols, entry.Symbol)
+		}
+		assert.Equal(t, expectedSymbols, symbols, order)
+	}
+}
+
+func TestScannerIndex_Prune(t *testing.T) {
+	client, mr := setupTestRedis(t)
+	defer mr.Close()
+
+	index := NewScannerIndex(client)
+	seedScannerTickers(t, index)
+	ctx := context.Background()
+
+	removed, err := index.Prune(ctx, time.Now().Add(-5*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	assert.False(t, mr.Exists(BuildScannerTickerKey("XRPUSDT")))
+	for _, key := range []string{BuildScannerRankKey(ScannerSortChangeRate, "1m"), BuildScannerRankKey(ScannerSortVolume, "")} {
+		members, err := mr.ZMembers(key)
+		require.NoError(t, err)
+		assert.NotContains(t, members, "XRPUSDT")
+	}
+
+	result, err := index.Query(ctx, &ScannerQuery{SortBy: ScannerSortChangeRate, Window: "1m", MaxAge: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+}
+
+func TestCalculateSpread(t *testing.T) {
+	bid := decimal.RequireFromString("0.1")
+	ask := decimal.RequireFromString("0.3")
+
+	// (0.3-0.1)/0.2*100 在 float64 下为 99.99999999999999
+	spread, ok := calculateSpread(&bid, &ask)
+	require.True(t, ok)
+	assert.Equal(t, 100.0, spread)
+
+	_, ok = calculateSpread(&bid, nil)
+	assert.False(t, ok)
+
+	zero := decimal.Zero
+	_, ok = calculateSpread(&zero, &ask)
+	assert.False(t, ok)
+}
@@ -10,17 +10,77 @@ import (
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Bitget   BitgetConfig   `mapstructure:"bitget"`
-	Log      LogConfig      `mapstructure:"log"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Bitget    BitgetConfig    `mapstructure:"bitget"`
+	Log       LogConfig       `mapstructure:"log"`
+	WebSocket WebSocketConfig `mapstructure:"websocket"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	Collector CollectorConfig `mapstructure:"collector"`
+	Leader    LeaderConfig    `mapstructure:"leader"`
+	Storage   StorageConfig   `mapstructure:"storage"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"` // debug/release
+	Port            int           `mapstructure:"port"`
+	Mode            string        `mapstructure:"mode"`             // debug/release
+	InstanceID      string        `mapstructure:"instance_id"`      // 实例 ID（选主和背板使用），为空时使用 主机名-进程号
+	ReadTimeout     time.Duration `mapstructure:"read_timeout"`     // 读取请求超时
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`     // keep-alive 空闲超时
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"` // 优雅关闭的最长等待时间
+}
+
+// WebSocketConfig WebSocket 推送服务配置
+type WebSocketConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	Host           string `mapstructure:"host"`
+	Port           int    `mapstructure:"port"`
+	MaxConnections int    `mapstructure:"max_connections"`
+	Backplane      bool   `mapstructure:"backplane"` // 多实例部署时通过 Redis Pub/Sub 转发消息
+
+	AllowedOrigins []string `mapstructure:"allowed_origins"` // 允许握手的浏览器来源，为空时只允许同源，"*" 允许所有来源
+}
+
+// AuthConfig 认证配置（REST API 与 WebSocket/SSE 共用）
+type AuthConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	SecretKey   string        `mapstructure:"secret_key"`
+	TokenExpiry time.Duration `mapstructure:"token_expiry"`
+	Issuer      string        `mapstructure:"issuer"`
+}
+
+// CollectorConfig 行情采集配置
+type CollectorConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Symbols     []string      `mapstructure:"symbols"`      // 采集的交易对，为空时使用数据库中的活跃交易对
+	TimeWindows []string      `mapstructure:"time_windows"` // 变化率计算窗口，与所有监控配置的 filters.time_windows 合并
+	ScannerTTL  time.Duration `mapstructure:"scanner_ttl"`  // 超过该时间未更新的交易对从扫描器排行中移除
+
+	TimeWindowSyncInterval time.Duration `mapstructure:"time_window_sync_interval"` // 重新读取监控配置时间窗口的间隔
+
+	// 原始行情落库
+	PersistTicks     bool   `mapstructure:"persist_ticks"`      // 是否保存每条 Ticker 到 price_ticks
+	IngestMode       string `mapstructure:"ingest_mode"`        // 写入方式：copy（COPY FROM STDIN）或 insert（GORM 批量 INSERT）
+	PersistBatchSize int    `mapstructure:"persist_batch_size"` // 每批写入的最大条数
+	PersistQueueSize int    `mapstructure:"persist_queue_size"` // 待写入队列容量
+
+	// 分片采集：交易对按一致性哈希分配到所有存活实例，每个实例只订阅自己的分片
+	Sharding                bool          `mapstructure:"sharding"`
+	MaxSubscriptionsPerConn int           `mapstructure:"max_subscriptions_per_conn"` // 单个交易所连接的最大订阅数，超过时拆分到多个连接
+	MemberTTL               time.Duration `mapstructure:"member_ttl"`                 // 实例超过该时间未心跳视为离开
+	RebalanceInterval       time.Duration `mapstructure:"rebalance_interval"`         // 心跳并重新计算分片的间隔
+
+	// 本地K线聚合：由 Ticker 流实时生成K线，推送到 kline 频道，收盘后落库
+	Candles                bool          `mapstructure:"candles"`
+	CandleIntervals        []string      `mapstructure:"candle_intervals"`         // 聚合周期
+	CandleWatermark        time.Duration `mapstructure:"candle_watermark"`         // 允许的迟到时长，超过后K线收盘
+	CandlePersistIntervals []string      `mapstructure:"candle_persist_intervals"` // 收盘后写入 klines 表的周期，以 local_ 周期落库
+
+	// 价格异常检测：价格异常作为信号推送，各交易对的自适应统计状态定期快照到 Redis，重启后恢复
+	Anomaly                 bool          `mapstructure:"anomaly"`
+	AnomalySnapshotInterval time.Duration `mapstructure:"anomaly_snapshot_interval"` // 统计状态快照间隔
 }
 
 // DatabaseConfig 数据库配置
@@ -36,6 +96,13 @@ type DatabaseConfig struct {
 	ConnMaxLifetime int             `mapstructure:"conn_max_lifetime"`  // 连接最大生命周期（秒）
 	ConnMaxIdleTime int             `mapstructure:"conn_max_idle_time"` // 空闲连接超时（秒）
 	Replicas        []ReplicaConfig `mapstructure:"replicas"`           // 从库配置列表
+
+	// 从库路由：延迟过大的从库移出读池，写入后一段时间内读取同一张表走主库
+	ReplicaMaxLag        time.Duration `mapstructure:"replica_max_lag"`         // 复制延迟超过该值时移出读池
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`  // 从库延迟检查间隔
+	ReadYourWritesWindow time.Duration `mapstructure:"read_your_writes_window"` // 写入后该时间内读取走主库
+	ReadYourWritesTables []string      `mapstructure:"read_your_writes_tables"` // 需要写后读一致性的表
+	ReplicaAdminAPI      bool          `mapstructure:"replica_admin_api"`       // 开启 /api/v1/admin/database/replicas，需要启用认证并使用 admin 权限的 Token
 }
 
 // ReplicaConfig 从库配置
@@ -83,6 +150,55 @@ type LogConfig struct {
 	Output string `mapstructure:"output"` // stdout, stderr, file
 }
 
+// LeaderConfig 选主配置
+// 多副本部署时只有领导者运行行情采集等单实例任务，所有副本都提供读服务
+type LeaderConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	LeaseTTL      time.Duration `mapstructure:"lease_ttl"`      // 租约时长，领导者失联后最迟在该时间后被接替
+	RenewInterval time.Duration `mapstructure:"renew_interval"` // 续约间隔
+	RetryInterval time.Duration `mapstructure:"retry_interval"` // 非领导者尝试获取租约的间隔
+}
+
+// StorageConfig 存储策略配置
+type StorageConfig struct {
+	AdminAPI         bool          `mapstructure:"admin_api"`          // 开启 /api/v1/admin/storage，需要启用认证并使用 admin 权限的 Token
+	AdminAPIInsecure bool          `mapstructure:"admin_api_insecure"` // 允许未启用认证时开启管理接口，仅用于本地开发
+	PurgeInterval    time.Duration `mapstructure:"purge_interval"`     // 按 kline_retention_policies 删除过期K线的间隔，0 表示不删除
+	Archive          ArchiveConfig `mapstructure:"archive"`
+	Export           ExportConfig  `mapstructure:"export"`
+}
+
+// ExportConfig 数据导出接口（/api/v1/export）的限制
+// 每个导出在整个下载期间占用一个数据库连接
+type ExportConfig struct {
+	MaxRange      time.Duration `mapstructure:"max_range"`      // 单次导出的最大时间范围，0 表示不限制
+	MaxConcurrent int           `mapstructure:"max_concurrent"` // 同时进行的导出数，超出时返回 429，0 表示不限制
+}
+
+// ArchiveConfig 过期数据归档配置
+// 保留策略删除之前把 price_ticks / klines 按 表/交易对/天 导出为 Parquet，记录到 archive_manifests
+type ArchiveConfig struct {
+	Enabled  bool            `mapstructure:"enabled"`
+	Backend  string          `mapstructure:"backend"`  // local/s3
+	Path     string          `mapstructure:"path"`     // local 的归档目录
+	Tables   []string        `mapstructure:"tables"`   // 归档的表
+	Delay    time.Duration   `mapstructure:"delay"`    // 一天结束后多久归档，需大于最长的K线周期并小于最短的保留期
+	Interval time.Duration   `mapstructure:"interval"` // 检查间隔
+	TempDir  string          `mapstructure:"temp_dir"` // 上传前写 Parquet 的临时目录，为空时使用系统临时目录
+	S3       ArchiveS3Config `mapstructure:"s3"`
+}
+
+// ArchiveS3Config S3 兼容存储配置
+type ArchiveS3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Bucket    string `mapstructure:"bucket"`
+	Prefix    string `mapstructure:"prefix"`
+	Region    string `mapstructure:"region"`
+	AccessKey string `mapstructure:"access_key"` // 为空时从 AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY 环境变量读取
+	SecretKey string `mapstructure:"secret_key"`
+	UseSSL    bool   `mapstructure:"use_ssl"`
+}
+
 // Load 加载配置文件
 func Load(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
@@ -91,6 +207,9 @@ func Load(configPath string) (*Config, error) {
 	// 设置默认值
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.mode", "debug")
+	viper.SetDefault("server.read_timeout", "15s")
+	viper.SetDefault("server.idle_timeout", "120s")
+	viper.SetDefault("server.shutdown_timeout", "30s")
 
 	// Database 默认配置
 	viper.SetDefault("database.host", "localhost")
@@ -99,6 +218,11 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("database.max_idle_conns", 10)
 	viper.SetDefault("database.conn_max_lifetime", 3600) // 1 hour
 	viper.SetDefault("database.conn_max_idle_time", 600) // 10 minutes
+	viper.SetDefault("database.replica_max_lag", "5s")
+	viper.SetDefault("database.replica_check_interval", "5s")
+	viper.SetDefault("database.replica_admin_api", false)
+	viper.SetDefault("database.read_your_writes_window", "10s")
+	viper.SetDefault("database.read_your_writes_tables", []string{"monitoring_configs", "symbols"})
 
 	// Redis 默认配置
 	viper.SetDefault("redis.host", "localhost")
@@ -123,6 +247,57 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("bitget.reconnect_base_delay", "1s")
 	viper.SetDefault("bitget.reconnect_max_delay", "60s")
 
+	// WebSocket 默认配置
+	viper.SetDefault("websocket.enabled", true)
+	viper.SetDefault("websocket.host", "0.0.0.0")
+	viper.SetDefault("websocket.port", 8081)
+	viper.SetDefault("websocket.max_connections", 1000)
+	viper.SetDefault("websocket.backplane", false)
+	viper.SetDefault("websocket.allowed_origins", []string{})
+
+	// 认证默认配置
+	viper.SetDefault("auth.enabled", false)
+	viper.SetDefault("auth.token_expiry", "24h")
+	viper.SetDefault("auth.issuer", "cryptosignal-hunter")
+
+	// 采集默认配置
+	viper.SetDefault("collector.enabled", true)
+	viper.SetDefault("collector.time_windows", []string{"1m", "5m", "15m"})
+	viper.SetDefault("collector.scanner_ttl", "5m")
+	viper.SetDefault("collector.time_window_sync_interval", "30s")
+	viper.SetDefault("collector.persist_ticks", true)
+	viper.SetDefault("collector.ingest_mode", "copy")
+	viper.SetDefault("collector.persist_batch_size", 2000)
+	viper.SetDefault("collector.persist_queue_size", 20000)
+	viper.SetDefault("collector.sharding", false)
+	viper.SetDefault("collector.max_subscriptions_per_conn", 50)
+	viper.SetDefault("collector.member_ttl", "10s")
+	viper.SetDefault("collector.rebalance_interval", "3s")
+	viper.SetDefault("collector.candles", true)
+	viper.SetDefault("collector.candle_intervals", []string{"1s", "1m", "5m"})
+	viper.SetDefault("collector.candle_watermark", "2s")
+	viper.SetDefault("collector.candle_persist_intervals", []string{"1m", "5m"})
+	viper.SetDefault("collector.anomaly", true)
+	viper.SetDefault("collector.anomaly_snapshot_interval", "1m")
+
+	// 选主默认配置
+	viper.SetDefault("leader.enabled", false)
+	viper.SetDefault("leader.lease_ttl", "5s")
+	viper.SetDefault("leader.renew_interval", "1500ms")
+	viper.SetDefault("leader.retry_interval", "1s")
+	viper.SetDefault("storage.admin_api", false)
+	viper.SetDefault("storage.admin_api_insecure", false)
+	viper.SetDefault("storage.purge_interval", "1h")
+	viper.SetDefault("storage.archive.enabled", false)
+	viper.SetDefault("storage.archive.backend", "local")
+	viper.SetDefault("storage.archive.path", "./data/archive")
+	viper.SetDefault("storage.archive.tables", []string{"price_ticks", "klines"})
+	viper.SetDefault("storage.archive.delay", "192h")
+	viper.SetDefault("storage.archive.interval", "1h")
+	viper.SetDefault("storage.archive.s3.use_ssl", true)
+	viper.SetDefault("storage.export.max_range", "2208h")
+	viper.SetDefault("storage.export.max_concurrent", 4)
+
 	if err := viper.ReadInConfig(); err != nil {
 		// 如果配置文件不存在，使用默认值
 		log.Printf("警告: 无法读取配置文件 (%v), 使用默认值", err)
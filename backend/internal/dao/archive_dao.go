@@ -0,0 +1,255 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const errMsgArchiveTable = "table %q cannot be archived"
+
+// ArchiveDAO 归档清单及归档数据源的访问接口
+type ArchiveDAO interface {
+	// SaveManifest 记录归档文件，同一 表/交易对/天 重复归档时覆盖
+	SaveManifest(ctx context.Context, manifest *models.ArchiveManifest) error
+
+	// ListManifests 查询 [startDay, endDay] 内的归档清单（按交易对、日期升序），symbol 为空时查询所有交易对
+	ListManifests(ctx context.Context, table, symbol string, startDay, endDay time.Time) ([]*models.ArchiveManifest, error)
+
+	// LatestManifestDay 查询表最近归档的日期，没有归档时返回空
+	LatestManifestDay(ctx context.Context, table string) (*time.Time, error)
+
+	// EarliestTimestamp 查询表中最早的数据时间，表为空时返回空
+	EarliestTimestamp(ctx context.Context, table string) (*time.Time, error)
+
+	// ListSymbols 查询 [start, end) 内有数据的交易对
+	ListSymbols(ctx context.Context, table string, start, end time.Time) ([]string, error)
+
+	// StreamTicks 按时间升序逐条读取 [start, end) 内的价格数据
+	StreamTicks(ctx context.Context, symbol string, start, end time.Time, fn func(*models.PriceTick) error) error
+
+	// StreamKlines 按时间升序逐条读取 [start, end) 内的K线，granularity 为空时读取所有周期
+	StreamKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error
+
+	// StreamAggregatedKlines 按时间升序逐条读取连续聚合中 [start, end) 内的K线，没有连续聚合的周期返回错误
+	StreamAggregatedKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error
+}
+
+// archiveDAOImpl ArchiveDAO 实现
+type archiveDAOImpl struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewArchiveDAO 创建 ArchiveDAO 实例
+func NewArchiveDAO(db *gorm.DB, logger *zap.Logger) ArchiveDAO {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &archiveDAOImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveManifest 记录归档文件
+func (d *archiveDAOImpl) SaveManifest(ctx context.Context, manifest *models.ArchiveManifest) error {
+	if manifest == nil || manifest.Symbol == "" || manifest.Path == "" {
+		return database.ErrInvalidInput
+	}
+	if !models.IsArchiveTable(manifest.SourceTable) {
+		return database.NewDatabaseError(fmt.Sprintf(errMsgArchiveTable, manifest.SourceTable), database.ErrInvalidInput)
+	}
+	manifest.Day = models.ArchiveDay(manifest.Day)
+
+	start := time.Now()
+	err := d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "source_table"}, {Name: "symbol"}, {Name: "day"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"path", "row_count", "min_timestamp", "max_timestamp", "size_bytes", "created_at",
+			}),
+		}).
+		Create(manifest).Error
+	logDAOOperation(d.logger, "ArchiveDAO.SaveManifest", time.Since(start), err,
+		zap.String("table", manifest.SourceTable),
+		zap.String("symbol", manifest.Symbol),
+		zap.Time("day", manifest.Day),
+	)
+	if err != nil {
+		return database.WrapDatabaseError(err, "failed to save archive manifest")
+	}
+	return nil
+}
+
+// ListManifests 查询归档清单
+func (d *archiveDAOImpl) ListManifests(ctx context.Context, table, symbol string, startDay, endDay time.Time) ([]*models.ArchiveManifest, error) {
+	if err := validateArchiveTable(table); err != nil {
+		return nil, err
+	}
+	if startDay.After(endDay) {
+		return nil, database.NewDatabaseError("start day must be before or equal to end day", database.ErrInvalidInput)
+	}
+
+	query := d.db.WithContext(ctx).
+		Where("source_table = ? AND day >= ? AND day <= ?", table, models.ArchiveDay(startDay), models.ArchiveDay(endDay))
+	if symbol != "" {
+		query = query.Where("symbol = ?", symbol)
+	}
+
+	var manifests []*models.ArchiveManifest
+	if err := query.Order("symbol ASC, day ASC").Find(&manifests).Error; err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to list archive manifests")
+	}
+	return manifests, nil
+}
+
+// LatestManifestDay 查询表最近归档的日期
+func (d *archiveDAOImpl) LatestManifestDay(ctx context.Context, table string) (*time.Time, error) {
+	if err := validateArchiveTable(table); err != nil {
+		return nil, err
+	}
+
+	var manifests []*models.ArchiveManifest
+	err := d.db.WithContext(ctx).
+		Where("source_table = ?", table).
+		Order("day DESC").
+		Limit(1).
+		Find(&manifests).Error
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to get latest archive day")
+	}
+	if len(manifests) == 0 {
+		return nil, nil
+	}
+	day := models.ArchiveDay(manifests[0].Day)
+	return &day, nil
+}
+
+// EarliestTimestamp 查询表中最早的数据时间
+func (d *archiveDAOImpl) EarliestTimestamp(ctx context.Context, table string) (*time.Time, error) {
+	if err := validateArchiveTable(table); err != nil {
+		return nil, err
+	}
+
+	var timestamps []time.Time
+	err := d.db.WithContext(ctx).
+		Table(table).
+		Order("timestamp ASC").
+		Limit(1).
+		Pluck("timestamp", &timestamps).Error
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to get earliest timestamp")
+	}
+	if len(timestamps) == 0 {
+		return nil, nil
+	}
+	return &timestamps[0], nil
+}
+
+// ListSymbols 查询时间范围内有数据的交易对
+func (d *archiveDAOImpl) ListSymbols(ctx context.Context, table string, start, end time.Time) ([]string, error) {
+	if err := validateArchiveTable(table); err != nil {
+		return nil, err
+	}
+
+	var symbols []string
+	err := d.db.WithContext(ctx).
+		Table(table).
+		Where("timestamp >= ? AND timestamp < ?", start, end).
+		Distinct("symbol").
+		Order("symbol ASC").
+		Pluck("symbol", &symbols).Error
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to list symbols")
+	}
+	return symbols, nil
+}
+
+// StreamTicks 按时间升序逐条读取价格数据
+func (d *archiveDAOImpl) StreamTicks(ctx context.Context, symbol string, start, end time.Time, fn func(*models.PriceTick) error) error {
+	if symbol == "" {
+		return database.NewDatabaseError(errMsgSymbolEmpty, database.ErrInvalidInput)
+	}
+
+	query := d.db.WithContext(ctx).
+		Model(&models.PriceTick{}).
+		Where("symbol = ? AND timestamp >= ? AND timestamp < ?", symbol, start, end).
+		Order("timestamp ASC")
+	return streamRows(d.db, query, "failed to stream price ticks", fn)
+}
+
+// StreamKlines 按时间升序逐条读取K线
+func (d *archiveDAOImpl) StreamKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error {
+	if symbol == "" {
+		return database.NewDatabaseError(errMsgSymbolEmpty, database.ErrInvalidInput)
+	}
+
+	query := d.db.WithContext(ctx).
+		Model(&models.Kline{}).
+		Where("symbol = ? AND timestamp >= ? AND timestamp < ?", symbol, start, end)
+	if granularity != "" {
+		query = query.Where("granularity = ?", granularity)
+	}
+	query = query.Order("timestamp ASC, granularity ASC")
+	return streamRows(d.db, query, "failed to stream klines", fn)
+}
+
+// StreamAggregatedKlines 按时间升序逐条读取连续聚合K线
+func (d *archiveDAOImpl) StreamAggregatedKlines(ctx context.Context, symbol, granularity string, start, end time.Time, fn func(*models.Kline) error) error {
+	if symbol == "" {
+		return database.NewDatabaseError(errMsgSymbolEmpty, database.ErrInvalidInput)
+	}
+
+	view, ok := KlineAggregateView(granularity)
+	if !ok {
+		return database.NewDatabaseError(
+			fmt.Sprintf(errMsgNoAggregate, granularity),
+			database.ErrInvalidInput,
+		)
+	}
+
+	query := d.db.WithContext(ctx).
+		Table(view).
+		Select(klineAggregateColumns, granularity).
+		Where("symbol = ? AND timestamp >= ? AND timestamp < ?", symbol, start, end).
+		Order("timestamp ASC")
+	return streamRows(d.db, query, "failed to stream aggregated klines", fn)
+}
+
+// streamRows 逐行扫描查询结果，避免整天的数据一次加载到内存
+func streamRows[T any](db *gorm.DB, query *gorm.DB, message string, fn func(*T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return database.WrapDatabaseError(err, message)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := new(T)
+		if err := db.ScanRows(rows, row); err != nil {
+			return database.WrapDatabaseError(err, message)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return database.WrapDatabaseError(err, message)
+	}
+	return nil
+}
+
+// validateArchiveTable 校验表是否可归档，表名会拼接到查询中
+func validateArchiveTable(table string) error {
+	if !models.IsArchiveTable(table) {
+		return database.NewDatabaseError(fmt.Sprintf(errMsgArchiveTable, table), database.ErrInvalidInput)
+	}
+	return nil
+}
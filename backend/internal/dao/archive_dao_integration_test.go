@@ -0,0 +1,73 @@
+// +build integration
+
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestArchiveDAO_Integration 集成测试：归档清单和流式读取（需要已执行全部迁移）
+func TestArchiveDAO_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过集成测试")
+	}
+
+	db, logger := setupKlineIntegrationTestDB(t)
+	dao := NewArchiveDAO(db, logger)
+	ctx := context.Background()
+
+	symbol := "ARCHIVETESTUSDT"
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t.Cleanup(func() {
+		db.Where("symbol = ?", symbol).Delete(&models.ArchiveManifest{})
+		db.Where("symbol = ?", symbol).Delete(&models.PriceTick{})
+	})
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Create(&models.PriceTick{
+			Symbol:    symbol,
+			Timestamp: day.Add(time.Duration(3-i) * time.Hour),
+			LastPrice: decimal.RequireFromString("1.23456789"),
+		}).Error)
+	}
+
+	symbols, err := dao.ListSymbols(ctx, models.ArchiveTablePriceTicks, day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Contains(t, symbols, symbol)
+
+	var ticks []*models.PriceTick
+	err = dao.StreamTicks(ctx, symbol, day, day.AddDate(0, 0, 1), func(tick *models.PriceTick) error {
+		ticks = append(ticks, tick)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, ticks, 3)
+	assert.True(t, day.Add(time.Hour).Equal(ticks[0].Timestamp))
+	assert.Equal(t, "1.23456789", ticks[0].LastPrice.String())
+
+	manifest := &models.ArchiveManifest{
+		SourceTable:  models.ArchiveTablePriceTicks,
+		Symbol:       symbol,
+		Day:          day,
+		Path:         "price_ticks/symbol=" + symbol + "/date=2020-01-01/data.parquet",
+		RowCount:     3,
+		MinTimestamp: ticks[0].Timestamp,
+		MaxTimestamp: ticks[2].Timestamp,
+	}
+	require.NoError(t, dao.SaveManifest(ctx, manifest))
+	manifest.RowCount = 4
+	require.NoError(t, dao.SaveManifest(ctx, manifest))
+
+	manifests, err := dao.ListManifests(ctx, models.ArchiveTablePriceTicks, symbol, day, day)
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+	assert.Equal(t, int64(4), manifests[0].RowCount)
+	assert.True(t, day.Equal(manifests[0].Day))
+}
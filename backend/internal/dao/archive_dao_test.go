@@ -0,0 +1,196 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupArchiveTestDB 创建测试数据库
+func setupArchiveTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.PriceTick{}, &models.Kline{}, &models.ArchiveManifest{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestArchiveDAO_Validation(t *testing.T) {
+	dao := NewArchiveDAO(setupArchiveTestDB(t), nil)
+	ctx := context.Background()
+	now := time.Now()
+
+	assert.ErrorIs(t, dao.SaveManifest(ctx, nil), database.ErrInvalidInput)
+	assert.ErrorIs(t, dao.SaveManifest(ctx, &models.ArchiveManifest{SourceTable: "symbols", Symbol: "BTCUSDT", Path: "x"}), database.ErrInvalidInput)
+
+	_, err := dao.ListManifests(ctx, "symbols", "", now, now)
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+	_, err = dao.ListManifests(ctx, models.ArchiveTableKlines, "", now, now.AddDate(0, 0, -1))
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+	_, err = dao.EarliestTimestamp(ctx, "symbols; DROP TABLE klines")
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+	_, err = dao.ListSymbols(ctx, "monitoring_configs", now, now)
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+	assert.ErrorIs(t, dao.StreamTicks(ctx, "", now, now, nil), database.ErrInvalidInput)
+	assert.ErrorIs(t, dao.StreamKlines(ctx, "", "", now, now, nil), database.ErrInvalidInput)
+	assert.ErrorIs(t, dao.StreamAggregatedKlines(ctx, "", "1h", now, now, nil), database.ErrInvalidInput)
+	assert.ErrorIs(t, dao.StreamAggregatedKlines(ctx, "BTCUSDT", "1w", now, now, nil), database.ErrInvalidInput)
+}
+
+func TestArchiveDAO_Manifests(t *testing.T) {
+	dao := NewArchiveDAO(setupArchiveTestDB(t), zap.NewNop())
+	ctx := context.Background()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	latest, err := dao.LatestManifestDay(ctx, models.ArchiveTablePriceTicks)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	for i, symbol := range []string{"ETHUSDT", "BTCUSDT"} {
+		require.NoError(t, dao.SaveManifest(ctx, &models.ArchiveManifest{
+			SourceTable:  models.ArchiveTablePriceTicks,
+			Symbol:       symbol,
+			Day:          day.AddDate(0, 0, i).Add(15 * time.Hour), // 截断到当天零点
+			Path:         "price_ticks/" + symbol,
+			RowCount:     10,
+			MinTimestamp: day,
+			MaxTimestamp: day,
+		}))
+	}
+
+	// 重复归档覆盖原记录
+	require.NoError(t, dao.SaveManifest(ctx, &models.ArchiveManifest{
+		SourceTable:  models.ArchiveTablePriceTicks,
+		Symbol:       "ETHUSDT",
+		Day:          day,
+		Path:         "price_ticks/ETHUSDT-v2",
+		RowCount:     20,
+		MinTimestamp: day,
+		MaxTimestamp: day,
+	}))
+
+	manifests, err := dao.ListManifests(ctx, models.ArchiveTablePriceTicks, "", day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	assert.Equal(t, "BTCUSDT", manifests[0].Symbol)
+	assert.Equal(t, "ETHUSDT", manifests[1].Symbol)
+	assert.Equal(t, "price_ticks/ETHUSDT-v2", manifests[1].Path)
+	assert.Equal(t, int64(20), manifests[1].RowCount)
+
+	manifests, err = dao.ListManifests(ctx, models.ArchiveTablePriceTicks, "ETHUSDT", day.AddDate(0, 0, 1), day.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Empty(t, manifests)
+
+	manifests, err = dao.ListManifests(ctx, models.ArchiveTableKlines, "", day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Empty(t, manifests)
+
+	latest, err = dao.LatestManifestDay(ctx, models.ArchiveTablePriceTicks)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.True(t, day.AddDate(0, 0, 1).Equal(*latest))
+}
+
+func TestArchiveDAO_Source(t *testing.T) {
+	db := setupArchiveTestDB(t)
+	dao := NewArchiveDAO(db, zap.NewNop())
+	ctx := context.Background()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	earliest, err := dao.EarliestTimestamp(ctx, models.ArchiveTableKlines)
+	require.NoError(t, err)
+	assert.Nil(t, earliest)
+
+	for i, symbol := range []string{"ETHUSDT", "BTCUSDT", "BTCUSDT"} {
+		ts := day.Add(time.Duration(3-i) * time.Hour)
+		require.NoError(t, db.Create(&models.Kline{
+			Symbol: symbol, Timestamp: ts, Granularity: "1m",
+			Open: decimal.NewFromInt(1), High: decimal.NewFromInt(1), Low: decimal.NewFromInt(1), Close: decimal.NewFromInt(1),
+			BaseVolume: decimal.NewFromInt(1), QuoteVolume: decimal.NewFromInt(1),
+		}).Error)
+		require.NoError(t, db.Create(&models.Kline{
+			Symbol: symbol, Timestamp: ts, Granularity: "1h",
+			Open: decimal.NewFromInt(1), High: decimal.NewFromInt(1), Low: decimal.NewFromInt(1), Close: decimal.NewFromInt(1),
+			BaseVolume: decimal.NewFromInt(1), QuoteVolume: decimal.NewFromInt(1),
+		}).Error)
+	}
+	require.NoError(t, db.Create(&models.PriceTick{Symbol: "BTCUSDT", Timestamp: day.AddDate(0, 0, 1), LastPrice: decimal.NewFromInt(1)}).Error)
+
+	earliest, err = dao.EarliestTimestamp(ctx, models.ArchiveTableKlines)
+	require.NoError(t, err)
+	require.NotNil(t, earliest)
+	assert.True(t, day.Add(time.Hour).Equal(*earliest))
+
+	symbols, err := dao.ListSymbols(ctx, models.ArchiveTableKlines, day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, symbols)
+
+	symbols, err = dao.ListSymbols(ctx, models.ArchiveTablePriceTicks, day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Empty(t, symbols)
+
+	var klines []*models.Kline
+	err = dao.StreamKlines(ctx, "BTCUSDT", "", day, day.AddDate(0, 0, 1), func(kline *models.Kline) error {
+		klines = append(klines, kline)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, klines, 4)
+	assert.True(t, day.Add(time.Hour).Equal(klines[0].Timestamp))
+	assert.Equal(t, "1h", klines[0].Granularity)
+	assert.Equal(t, "1m", klines[1].Granularity)
+	assert.True(t, day.Add(2*time.Hour).Equal(klines[2].Timestamp))
+
+	klines = nil
+	err = dao.StreamKlines(ctx, "BTCUSDT", "1m", day, day.AddDate(0, 0, 1), func(kline *models.Kline) error {
+		klines = append(klines, kline)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, klines, 2)
+
+	// 回调返回的错误原样返回
+	stop := assert.AnError
+	err = dao.StreamTicks(ctx, "BTCUSDT", day, day.AddDate(0, 0, 2), func(tick *models.PriceTick) error {
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestArchiveDAO_StreamAggregatedKlines(t *testing.T) {
+	db := setupArchiveTestDB(t)
+	dao := NewArchiveDAO(db, zap.NewNop())
+	ctx := context.Background()
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	createTestKlineAggregate(t, db, "klines_1h", "BTCUSDT",
+		day.Add(2*time.Hour), day, day.Add(time.Hour), day.AddDate(0, 0, 1))
+	require.NoError(t, db.Exec("INSERT INTO klines_1h (symbol, timestamp, close) VALUES (?, ?, ?)", "ETHUSDT", day, "2500").Error)
+
+	var klines []*models.Kline
+	err := dao.StreamAggregatedKlines(ctx, "BTCUSDT", "1h", day, day.AddDate(0, 0, 1), func(kline *models.Kline) error {
+		klines = append(klines, kline)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, klines, 3)
+	for i, kline := range klines {
+		assert.True(t, day.Add(time.Duration(i)*time.Hour).Equal(kline.Timestamp))
+		assert.Equal(t, "1h", kline.Granularity)
+		assert.Equal(t, "50500.25", kline.Close.String())
+	}
+}
@@ -0,0 +1,154 @@
+// +build integration
+
+package dao
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// setupCopyIntegrationDB 连接集成测试数据库并清空 price_ticks 和 klines
+func setupCopyIntegrationDB(tb testing.TB) *gorm.DB {
+	host := os.Getenv("TEST_DB_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	cfg := &config.DatabaseConfig{
+		Host:            host,
+		Port:            5432,
+		User:            "postgres",
+		Password:        "postgres",
+		DBName:          "cryptosignal",
+		MaxOpenConns:    10,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 3600,
+	}
+
+	db, err := database.Connect(cfg, zap.NewNop())
+	require.NoError(tb, err)
+	require.NoError(tb, db.Exec("TRUNCATE TABLE price_ticks").Error)
+	require.NoError(tb, db.Exec("TRUNCATE TABLE klines").Error)
+	return db
+}
+
+func benchmarkTicks(n int, base time.Time) []*models.PriceTick {
+	ticks := make([]*models.PriceTick, n)
+	for i := range ticks {
+		ticks[i] = createTestPriceTick(fmt.Sprintf("SYM%03dUSDT", i%500), base.Add(time.Duration(i)*time.Millisecond))
+	}
+	return ticks
+}
+
+func TestCopyIngester_PriceTicks_Integration(t *testing.T) {
+	db := setupCopyIntegrationDB(t)
+	ingester := NewCopyIngester(db, zap.NewNop())
+	ctx := context.Background()
+
+	ticks := benchmarkTicks(5000, time.Now().Add(-time.Hour))
+	ticks[0].DeliveryStatus = "delivery_normal"
+	copied, err := ingester.CopyPriceTicks(ctx, ticks)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000), copied)
+
+	var count int64
+	require.NoError(t, db.Model(&models.PriceTick{}).Count(&count).Error)
+	assert.Equal(t, int64(5000), count)
+
+	latest, err := NewPriceTickDAO(db, zap.NewNop()).GetLatest(ctx, ticks[0].Symbol)
+	require.NoError(t, err)
+	assert.Equal(t, "50000", latest.LastPrice.String())
+	require.NotNil(t, latest.BidPrice)
+	assert.Equal(t, "49999", latest.BidPrice.String())
+}
+
+func TestCopyIngester_Klines_Integration(t *testing.T) {
+	db := setupCopyIntegrationDB(t)
+	ingester := NewCopyIngester(db, zap.NewNop())
+	ctx := context.Background()
+
+	ts := time.Now().Truncate(time.Minute).Add(-time.Hour)
+	klines := []*models.Kline{
+		{Symbol: "BTCUSDT", Granularity: "1m", Timestamp: ts, Open: decimal.NewFromInt(1), High: decimal.NewFromInt(2), Low: decimal.NewFromInt(1), Close: decimal.NewFromInt(2)},
+		{Symbol: "BTCUSDT", Granularity: "1m", Timestamp: ts.Add(time.Minute), Open: decimal.NewFromInt(2), High: decimal.NewFromInt(3), Low: decimal.NewFromInt(2), Close: decimal.NewFromInt(3)},
+	}
+	merged, err := ingester.CopyKlines(ctx, klines)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), merged)
+
+	// 已存在的K线被新值覆盖，新K线被插入
+	klines = []*models.Kline{
+		{Symbol: "BTCUSDT", Granularity: "1m", Timestamp: ts.Add(time.Minute), Open: decimal.NewFromInt(2), High: decimal.NewFromInt(5), Low: decimal.NewFromInt(2), Close: decimal.NewFromInt(4)},
+		{Symbol: "BTCUSDT", Granularity: "1m", Timestamp: ts.Add(2 * time.Minute), Open: decimal.NewFromInt(4), High: decimal.NewFromInt(4), Low: decimal.NewFromInt(3), Close: decimal.NewFromInt(3)},
+	}
+	merged, err = ingester.CopyKlines(ctx, klines)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), merged)
+
+	stored, err := NewKlineDAO(db, zap.NewNop()).GetByRange(ctx, "BTCUSDT", "1m", ts, ts.Add(2*time.Minute), 10, 0)
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+	assert.Equal(t, "5", stored[1].High.String())
+	assert.Equal(t, "4", stored[1].Close.String())
+}
+
+// benchmarkIngest 每次迭代写入 batchSize 条价格数据，报告每秒写入行数（不含生成数据的时间）
+func benchmarkIngest(b *testing.B, batchSize int, write func(ctx context.Context, ticks []*models.PriceTick) error) {
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ticks := benchmarkTicks(batchSize, base.Add(time.Duration(i)*time.Minute))
+		b.StartTimer()
+
+		require.NoError(b, write(ctx, ticks))
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N*batchSize)/b.Elapsed().Seconds(), "rows/s")
+}
+
+// BenchmarkPriceTickIngest_Insert 当前路径：PriceTickDAO.CreateBatch，每 1000 条一次 INSERT
+func BenchmarkPriceTickIngest_Insert(b *testing.B) {
+	for _, batchSize := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("batch=%d", batchSize), func(b *testing.B) {
+			db := setupCopyIntegrationDB(b)
+			tickDAO := NewPriceTickDAO(db, zap.NewNop())
+			benchmarkIngest(b, batchSize, func(ctx context.Context, ticks []*models.PriceTick) error {
+				for start := 0; start < len(ticks); start += 1000 {
+					if err := tickDAO.CreateBatch(ctx, ticks[start:min(start+1000, len(ticks))]); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+	}
+}
+
+// BenchmarkPriceTickIngest_Copy COPY 路径：每个批次一次 COPY
+func BenchmarkPriceTickIngest_Copy(b *testing.B) {
+	for _, batchSize := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("batch=%d", batchSize), func(b *testing.B) {
+			ingester := NewCopyIngester(setupCopyIntegrationDB(b), zap.NewNop())
+			benchmarkIngest(b, batchSize, func(ctx context.Context, ticks []*models.PriceTick) error {
+				_, err := ingester.CopyPriceTicks(ctx, ticks)
+				return err
+			})
+		})
+	}
+}
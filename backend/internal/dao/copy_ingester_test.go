@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyIngester_Validation(t *testing.T) {
+	db, logger := setupPriceTickTestDB(t)
+	ingester := NewCopyIngester(db, logger)
+	ctx := context.Background()
+
+	_, err := ingester.CopyPriceTicks(ctx, nil)
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+
+	_, err = ingester.CopyPriceTicks(ctx, []*models.PriceTick{
+		createTestPriceTick("BTCUSDT", time.Now()),
+		{Symbol: "ETHUSDT"},
+	})
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+
+	_, err = ingester.CopyKlines(ctx, []*models.Kline{{Symbol: "BTCUSDT"}})
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+
+	// COPY 需要 pgx 驱动
+	_, err = ingester.CopyPriceTicks(ctx, []*models.PriceTick{createTestPriceTick("BTCUSDT", time.Now())})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires the pgx driver")
+}
+
+func TestCopyRows(t *testing.T) {
+	now := time.Now()
+	tick := createTestPriceTick("BTCUSDT", now)
+	row := priceTickCopyRow(tick, now)
+	require.Len(t, row, len(priceTickCopyColumns))
+	assert.Equal(t, "BTCUSDT", row[0])
+	assert.Equal(t, now, row[len(row)-1], "created_at 为空时使用写入时间")
+	assert.Nil(t, row[22], "空的交割状态写入 NULL")
+	assert.Equal(t, pgtype.Numeric{Int: big.NewInt(50000), Valid: true}, row[2])
+	assert.Equal(t, pgtype.Numeric{}, row[15], "空的指数价格写入 NULL")
+
+	kline := &models.Kline{Symbol: "BTCUSDT", Granularity: "1m", Timestamp: now, CreatedAt: now.Add(-time.Hour)}
+	row = klineCopyRow(kline, now)
+	require.Len(t, row, len(klineCopyColumns))
+	assert.Equal(t, now.Add(-time.Hour), row[len(row)-1])
+}
+
+func TestDedupeKlines(t *testing.T) {
+	ts := time.Now().Truncate(time.Minute)
+	klines := []*models.Kline{
+		{Symbol: "BTCUSDT", Granularity: "1m", Timestamp: ts, Close: decimal.NewFromInt(1)},
+		{Symbol: "ETHUSDT", Granularity: "1m", Timestamp: ts, Close: decimal.NewFromInt(2)},
+		{Symbol: "BTCUSDT", Granularity: "5m", Timestamp: ts, Close: decimal.NewFromInt(3)},
+		{Symbol: "BTCUSDT", Granularity: "1m", Timestamp: ts, Close: decimal.NewFromInt(4)},
+	}
+
+	unique := dedupeKlines(klines)
+	require.Len(t, unique, 3)
+	assert.Equal(t, int64(4), unique[0].Close.IntPart(), "重复K线以最后一条为准")
+	assert.Equal(t, int64(2), unique[1].Close.IntPart())
+	assert.Equal(t, int64(3), unique[2].Close.IntPart())
+}
+
+func TestNumeric(t *testing.T) {
+	value := numeric(decimal.RequireFromString("0.00012345"))
+	assert.Equal(t, pgtype.Numeric{Int: big.NewInt(12345), Exp: -8, Valid: true}, value)
+
+	value = numeric(decimal.RequireFromString("-67890.1"))
+	assert.Equal(t, pgtype.Numeric{Int: big.NewInt(-678901), Exp: -1, Valid: true}, value)
+
+	assert.False(t, optionalNumeric(nil).Valid)
+}
@@ -16,6 +16,7 @@ const (
 	errMsgSymbolEmpty       = "symbol cannot be empty"
 	errMsgGranularityEmpty  = "granularity cannot be empty"
 	errMsgLimitOutOfRange   = "limit %d must be between 1 and 200"
+	errMsgNoAggregate       = "granularity %q has no continuous aggregate"
 	orderByTimestampDesc    = "timestamp DESC"
 )
 
@@ -35,6 +36,34 @@ type KlineDAO interface {
 
 	// GetBySymbolAndGranularity 按交易对和周期查询K线数据（支持分页，按时间降序）
 	GetBySymbolAndGranularity(ctx context.Context, symbol, granularity string, limit, offset int) ([]*models.Kline, error)
+
+	// GetAggregatedByRange 从连续聚合按时间范围查询K线数据（支持分页，按时间降序），用于交易所K线缺失时补全
+	GetAggregatedByRange(ctx context.Context, symbol, granularity string, startTime, endTime time.Time, limit, offset int) ([]*models.Kline, error)
+
+	// GetAggregatedLatest 从连续聚合查询最新N条K线数据（按时间降序）
+	GetAggregatedLatest(ctx context.Context, symbol, granularity string, limit int) ([]*models.Kline, error)
+}
+
+// klineAggregateViews K线周期对应的连续聚合视图（见迁移 000013），同时兼容 API 的小写周期和交易所的大写周期
+var klineAggregateViews = map[string]string{
+	"1m":  "klines_1m",
+	"5m":  "klines_5m",
+	"15m": "klines_15m",
+	"1h":  "klines_1h",
+	"1H":  "klines_1h",
+	"4h":  "klines_4h",
+	"4H":  "klines_4h",
+	"1d":  "klines_1d",
+	"1D":  "klines_1d",
+}
+
+// klineAggregateColumns 连续聚合没有 granularity 列，查询时以请求的周期补上
+const klineAggregateColumns = "symbol, timestamp, ? AS granularity, open, high, low, close, base_volume, quote_volume"
+
+// KlineAggregateView 返回周期对应的连续聚合视图名，不支持的周期返回 false
+func KlineAggregateView(granularity string) (string, bool) {
+	view, ok := klineAggregateViews[granularity]
+	return view, ok
 }
 
 // klineDAOImpl KlineDAO 实现
@@ -214,3 +243,83 @@ func (d *klineDAOImpl) GetBySymbolAndGranularity(ctx context.Context, symbol, gr
 	return klines, nil
 }
 
+// GetAggregatedByRange 从连续聚合按时间范围查询K线数据（支持分页，按时间降序）
+func (d *klineDAOImpl) GetAggregatedByRange(ctx context.Context, symbol, granularity string, startTime, endTime time.Time, limit, offset int) ([]*models.Kline, error) {
+	if symbol == "" {
+		return nil, database.NewDatabaseError(errMsgSymbolEmpty, database.ErrInvalidInput)
+	}
+
+	view, ok := KlineAggregateView(granularity)
+	if !ok {
+		return nil, database.NewDatabaseError(
+			fmt.Sprintf(errMsgNoAggregate, granularity),
+			database.ErrInvalidInput,
+		)
+	}
+
+	if startTime.After(endTime) {
+		return nil, database.NewDatabaseError("start time must be before or equal to end time", database.ErrInvalidInput)
+	}
+
+	if limit <= 0 || limit > 200 {
+		return nil, database.NewDatabaseError(
+			fmt.Sprintf(errMsgLimitOutOfRange, limit),
+			database.ErrInvalidInput,
+		)
+	}
+
+	var klines []*models.Kline
+
+	err := d.db.WithContext(ctx).
+		Table(view).
+		Select(klineAggregateColumns, granularity).
+		Where("symbol = ? AND timestamp >= ? AND timestamp <= ?", symbol, startTime, endTime).
+		Order(orderByTimestampDesc).
+		Limit(limit).
+		Offset(offset).
+		Find(&klines).Error
+
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to get aggregated klines by range")
+	}
+
+	return klines, nil
+}
+
+// GetAggregatedLatest 从连续聚合查询最新N条K线数据（按时间降序）
+func (d *klineDAOImpl) GetAggregatedLatest(ctx context.Context, symbol, granularity string, limit int) ([]*models.Kline, error) {
+	if symbol == "" {
+		return nil, database.NewDatabaseError(errMsgSymbolEmpty, database.ErrInvalidInput)
+	}
+
+	view, ok := KlineAggregateView(granularity)
+	if !ok {
+		return nil, database.NewDatabaseError(
+			fmt.Sprintf(errMsgNoAggregate, granularity),
+			database.ErrInvalidInput,
+		)
+	}
+
+	if limit <= 0 || limit > 200 {
+		return nil, database.NewDatabaseError(
+			fmt.Sprintf(errMsgLimitOutOfRange, limit),
+			database.ErrInvalidInput,
+		)
+	}
+
+	var klines []*models.Kline
+
+	err := d.db.WithContext(ctx).
+		Table(view).
+		Select(klineAggregateColumns, granularity).
+		Where("symbol = ?", symbol).
+		Order(orderByTimestampDesc).
+		Limit(limit).
+		Find(&klines).Error
+
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to get latest aggregated klines")
+	}
+
+	return klines, nil
+}
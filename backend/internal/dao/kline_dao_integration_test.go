@@ -12,6 +12,7 @@ import (
 	"github.com/haxrd/cryptosignal-hunter/internal/config"
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -71,7 +72,7 @@ func TestKlineDAO_Integration_Create(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, found, 1)
 		assert.Equal(t, "BTCUSDT", found[0].Symbol)
-		assert.Equal(t, 50000.0, found[0].Open)
+		assert.Equal(t, "50000", found[0].Open.String())
 	})
 
 	t.Run("验证唯一约束生效", func(t *testing.T) {
@@ -377,10 +378,10 @@ func TestKlineDAO_Integration_DataConsistency(t *testing.T) {
 		kline := createTestKline("PRECISION", "1m", now)
 		
 		// 设置高精度价格（8位小数）
-		kline.Open = 12345.12345678
-		kline.High = 12346.87654321
-		kline.Low = 12344.11111111
-		kline.Close = 12345.99999999
+		kline.Open = decimal.RequireFromString("12345.12345678")
+		kline.High = decimal.RequireFromString("12346.87654321")
+		kline.Low = decimal.RequireFromString("12344.11111111")
+		kline.Close = decimal.RequireFromString("12345.99999999")
 
 		err := dao.Create(ctx, kline)
 		require.NoError(t, err)
@@ -390,10 +391,10 @@ func TestKlineDAO_Integration_DataConsistency(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, found, 1)
 
-		assert.Equal(t, 12345.12345678, found[0].Open)
-		assert.Equal(t, 12346.87654321, found[0].High)
-		assert.Equal(t, 12344.11111111, found[0].Low)
-		assert.Equal(t, 12345.99999999, found[0].Close)
+		assert.Equal(t, "12345.12345678", found[0].Open.String())
+		assert.Equal(t, "12346.87654321", found[0].High.String())
+		assert.Equal(t, "12344.11111111", found[0].Low.String())
+		assert.Equal(t, "12345.99999999", found[0].Close.String())
 	})
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -36,12 +37,12 @@ func createTestKline(symbol, granularity string, timestamp time.Time) *models.Kl
 		Symbol:      symbol,
 		Timestamp:   timestamp,
 		Granularity: granularity,
-		Open:        50000.0,
-		High:        51000.0,
-		Low:         49000.0,
-		Close:       50500.0,
-		BaseVolume:  100.5,
-		QuoteVolume: 5000000.0,
+		Open:        decimal.NewFromInt(50000),
+		High:        decimal.NewFromInt(51000),
+		Low:         decimal.NewFromInt(49000),
+		Close:       decimal.NewFromInt(50500),
+		BaseVolume:  decimal.RequireFromString("100.5"),
+		QuoteVolume: decimal.NewFromInt(5000000),
 	}
 }
 
@@ -62,10 +63,10 @@ func TestKlineDAO_Create(t *testing.T) {
 			"BTCUSDT", now, "1m").First(&saved).Error
 		require.NoError(t, err)
 		assert.Equal(t, "BTCUSDT", saved.Symbol)
-		assert.Equal(t, 50000.0, saved.Open)
-		assert.Equal(t, 51000.0, saved.High)
-		assert.Equal(t, 49000.0, saved.Low)
-		assert.Equal(t, 50500.0, saved.Close)
+		assert.Equal(t, "50000", saved.Open.String())
+		assert.Equal(t, "51000", saved.High.String())
+		assert.Equal(t, "49000", saved.Low.String())
+		assert.Equal(t, "50500", saved.Close.String())
 	})
 
 	t.Run("创建重复K线应返回错误", func(t *testing.T) {
@@ -396,3 +397,92 @@ func TestKlineDAO_GetBySymbolAndGranularity(t *testing.T) {
 	})
 }
 
+
+// createTestKlineAggregate 在 SQLite 中创建与连续聚合视图同结构的表并写入数据（连续聚合没有 granularity 列）
+func createTestKlineAggregate(t *testing.T, db *gorm.DB, view string, symbol string, timestamps ...time.Time) {
+	err := db.Exec("CREATE TABLE " + view + " (symbol TEXT, timestamp DATETIME, open NUMERIC, high NUMERIC, low NUMERIC, close NUMERIC, base_volume NUMERIC, quote_volume NUMERIC)").Error
+	require.NoError(t, err)
+
+	for _, ts := range timestamps {
+		err := db.Exec("INSERT INTO "+view+" VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			symbol, ts, "50000.5", "51000", "49000", "50500.25", "12.5", "625000").Error
+		require.NoError(t, err)
+	}
+}
+
+func TestKlineAggregateView(t *testing.T) {
+	view, ok := KlineAggregateView("1h")
+	assert.True(t, ok)
+	assert.Equal(t, "klines_1h", view)
+
+	// 兼容交易所的大写周期
+	view, ok = KlineAggregateView("4H")
+	assert.True(t, ok)
+	assert.Equal(t, "klines_4h", view)
+
+	_, ok = KlineAggregateView("30m")
+	assert.False(t, ok)
+}
+
+func TestKlineDAO_GetAggregatedByRange(t *testing.T) {
+	db, logger := setupKlineTestDB(t)
+	dao := NewKlineDAO(db, logger)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(5 * time.Minute)
+	createTestKlineAggregate(t, db, "klines_5m", "BTCUSDT",
+		now, now.Add(-5*time.Minute), now.Add(-10*time.Minute), now.Add(-15*time.Minute))
+
+	t.Run("成功查询时间范围内的聚合K线", func(t *testing.T) {
+		result, err := dao.GetAggregatedByRange(ctx, "BTCUSDT", "5m", now.Add(-10*time.Minute), now, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+
+		// 按时间降序，周期取请求值
+		assert.True(t, now.Equal(result[0].Timestamp))
+		assert.Equal(t, "5m", result[0].Granularity)
+		assert.Equal(t, "50000.5", result[0].Open.String())
+		assert.Equal(t, "50500.25", result[0].Close.String())
+		assert.Equal(t, "12.5", result[0].BaseVolume.String())
+	})
+
+	t.Run("支持分页查询", func(t *testing.T) {
+		result, err := dao.GetAggregatedByRange(ctx, "BTCUSDT", "5m", now.Add(-15*time.Minute), now, 2, 2)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.True(t, now.Add(-10*time.Minute).Equal(result[0].Timestamp))
+	})
+
+	t.Run("没有连续聚合的周期应返回错误", func(t *testing.T) {
+		_, err := dao.GetAggregatedByRange(ctx, "BTCUSDT", "30m", now.Add(-10*time.Minute), now, 10, 0)
+		assert.ErrorIs(t, err, database.ErrInvalidInput)
+	})
+
+	t.Run("空交易对应返回错误", func(t *testing.T) {
+		_, err := dao.GetAggregatedByRange(ctx, "", "5m", now.Add(-10*time.Minute), now, 10, 0)
+		assert.ErrorIs(t, err, database.ErrInvalidInput)
+	})
+
+	t.Run("起始时间晚于结束时间应返回错误", func(t *testing.T) {
+		_, err := dao.GetAggregatedByRange(ctx, "BTCUSDT", "5m", now, now.Add(-10*time.Minute), 10, 0)
+		assert.ErrorIs(t, err, database.ErrInvalidInput)
+	})
+}
+
+func TestKlineDAO_GetAggregatedLatest(t *testing.T) {
+	db, logger := setupKlineTestDB(t)
+	dao := NewKlineDAO(db, logger)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	createTestKlineAggregate(t, db, "klines_1h", "ETHUSDT", now.Add(-2*time.Hour), now, now.Add(-time.Hour))
+
+	result, err := dao.GetAggregatedLatest(ctx, "ETHUSDT", "1H", 2)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.True(t, now.Equal(result[0].Timestamp))
+	assert.Equal(t, "1H", result[0].Granularity)
+
+	_, err = dao.GetAggregatedLatest(ctx, "ETHUSDT", "1H", 0)
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+}
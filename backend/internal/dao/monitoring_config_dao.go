@@ -1,12 +1,14 @@
 package dao
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
@@ -25,6 +27,14 @@ func NewMonitoringConfigDAO(db *gorm.DB, logger *zap.Logger) *MonitoringConfigDA
 	}
 }
 
+// WithContext 返回在 ctx 中执行查询的 DAO，ctx 由 database.WithPrimary 标记时读取走主库
+func (dao *MonitoringConfigDAO) WithContext(ctx context.Context) *MonitoringConfigDAO {
+	return &MonitoringConfigDAO{
+		db:     dao.db.WithContext(ctx),
+		logger: dao.logger,
+	}
+}
+
 // Create 创建监控配置
 func (dao *MonitoringConfigDAO) Create(config *models.MonitoringConfig) error {
 	start := time.Now()
@@ -35,9 +45,9 @@ func (dao *MonitoringConfigDAO) Create(config *models.MonitoringConfig) error {
 		return err
 	}
 
-	// 检查名称唯一性
+	// 检查名称唯一性（读主库，从库可能还没有刚创建的配置）
 	var count int64
-	if err := dao.db.Model(&models.MonitoringConfig{}).Where("name = ?", config.Name).Count(&count).Error; err != nil {
+	if err := dao.db.Clauses(dbresolver.Write).Model(&models.MonitoringConfig{}).Where("name = ?", config.Name).Count(&count).Error; err != nil {
 		dao.logger.Error("检查配置名称唯一性失败", zap.Error(err))
 		return err
 	}
@@ -169,7 +179,7 @@ func (dao *MonitoringConfigDAO) Update(config *models.MonitoringConfig) error {
 
 	// 检查配置是否存在
 	var existingConfig models.MonitoringConfig
-	if err := dao.db.First(&existingConfig, config.ID).Error; err != nil {
+	if err := dao.db.Clauses(dbresolver.Write).First(&existingConfig, config.ID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return &NotFoundError{Resource: "monitoring_config", ID: config.ID}
 		}
@@ -179,7 +189,7 @@ func (dao *MonitoringConfigDAO) Update(config *models.MonitoringConfig) error {
 
 	// 检查名称唯一性（排除自己）
 	var count int64
-	if err := dao.db.Model(&models.MonitoringConfig{}).Where("name = ? AND id != ?", config.Name, config.ID).Count(&count).Error; err != nil {
+	if err := dao.db.Clauses(dbresolver.Write).Model(&models.MonitoringConfig{}).Where("name = ? AND id != ?", config.Name, config.ID).Count(&count).Error; err != nil {
 		dao.logger.Error("检查配置名称唯一性失败", zap.Error(err))
 		return err
 	}
@@ -215,7 +225,7 @@ func (dao *MonitoringConfigDAO) Delete(id int64) error {
 
 	// 检查配置是否存在
 	var config models.MonitoringConfig
-	if err := dao.db.First(&config, id).Error; err != nil {
+	if err := dao.db.Clauses(dbresolver.Write).First(&config, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			dao.logger.Warn("监控配置不存在", zap.Int64("id", id))
 			return &NotFoundError{Resource: "monitoring_config", ID: id}
@@ -250,7 +260,7 @@ func (dao *MonitoringConfigDAO) SetDefault(id int64) error {
 
 	// 检查配置是否存在
 	var config models.MonitoringConfig
-	if err := dao.db.First(&config, id).Error; err != nil {
+	if err := dao.db.Clauses(dbresolver.Write).First(&config, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			dao.logger.Warn("监控配置不存在", zap.Int64("id", id))
 			return &NotFoundError{Resource: "monitoring_config", ID: id}
@@ -285,6 +295,21 @@ func (dao *MonitoringConfigDAO) clearDefaultConfig() error {
 	return dao.db.Model(&models.MonitoringConfig{}).Where("is_default = ?", true).Update("is_default", false).Error
 }
 
+// TimeWindows 获取所有监控配置的时间窗口，每个配置一组，供价格处理器合并后计算变化率
+func (dao *MonitoringConfigDAO) TimeWindows() ([][]string, error) {
+	var configs []*models.MonitoringConfig
+	if err := dao.db.Select("id", "filters").Find(&configs).Error; err != nil {
+		dao.logger.Error("获取监控配置时间窗口失败", zap.Error(err))
+		return nil, err
+	}
+
+	windows := make([][]string, 0, len(configs))
+	for _, config := range configs {
+		windows = append(windows, config.Filters.TimeWindows)
+	}
+	return windows, nil
+}
+
 // Search 搜索监控配置
 func (dao *MonitoringConfigDAO) Search(keyword string, offset, limit int) ([]*models.MonitoringConfig, int64, error) {
 	start := time.Now()
@@ -0,0 +1,208 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 涨跌幅排行方向
+const (
+	MoverDirectionUp   = "up"   // 涨幅最大
+	MoverDirectionDown = "down" // 跌幅最大
+	MoverDirectionAbs  = "abs"  // 变化幅度绝对值最大
+)
+
+const errMsgWindowSizeEmpty = "window size cannot be empty"
+
+// PriceChangeRateDAO 价格变化率数据访问接口
+type PriceChangeRateDAO interface {
+	// Create 创建单条变化率数据
+	Create(ctx context.Context, rate *models.PriceChangeRate) error
+
+	// CreateBatch 批量创建变化率数据（单次最多1000条）
+	CreateBatch(ctx context.Context, rates []*models.PriceChangeRate) error
+
+	// GetHistory 按时间范围查询交易对在指定窗口的变化率历史（支持分页，按时间降序）
+	GetHistory(ctx context.Context, symbol, windowSize string, startTime, endTime time.Time, limit, offset int) ([]*models.PriceChangeRate, error)
+
+	// GetTopMovers 查询指定窗口在 since 之后变化最大的交易对（每个交易对取其最极端的一条记录）
+	GetTopMovers(ctx context.Context, windowSize string, since time.Time, direction string, limit int) ([]*models.PriceChangeRate, error)
+}
+
+// priceChangeRateDAOImpl PriceChangeRateDAO 实现
+type priceChangeRateDAOImpl struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewPriceChangeRateDAO 创建 PriceChangeRateDAO 实例
+func NewPriceChangeRateDAO(db *gorm.DB, logger *zap.Logger) PriceChangeRateDAO {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &priceChangeRateDAOImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// validateChangeRate 验证变化率数据
+func validateChangeRate(rate *models.PriceChangeRate) error {
+	if rate == nil {
+		return database.ErrInvalidInput
+	}
+	if rate.Symbol == "" {
+		return database.NewDatabaseError(errMsgSymbolEmpty, database.ErrInvalidInput)
+	}
+	if rate.WindowSize == "" {
+		return database.NewDatabaseError(errMsgWindowSizeEmpty, database.ErrInvalidInput)
+	}
+	if rate.PriceBefore <= 0 || rate.PriceAfter <= 0 {
+		return database.NewDatabaseError("prices must be positive", database.ErrInvalidInput)
+	}
+	return nil
+}
+
+// Create 创建单条变化率数据
+func (d *priceChangeRateDAOImpl) Create(ctx context.Context, rate *models.PriceChangeRate) error {
+	if err := validateChangeRate(rate); err != nil {
+		return err
+	}
+
+	result := d.db.WithContext(ctx).Create(rate)
+	if result.Error != nil {
+		return database.WrapDatabaseError(result.Error, "failed to create price change rate")
+	}
+
+	return nil
+}
+
+// CreateBatch 批量创建变化率数据（单次最多1000条）
+func (d *priceChangeRateDAOImpl) CreateBatch(ctx context.Context, rates []*models.PriceChangeRate) error {
+	if len(rates) == 0 {
+		return database.ErrInvalidInput
+	}
+
+	if len(rates) > 1000 {
+		return database.NewDatabaseError(
+			fmt.Sprintf("batch size %d exceeds maximum 1000", len(rates)),
+			database.ErrInvalidInput,
+		)
+	}
+
+	for i, rate := range rates {
+		if err := validateChangeRate(rate); err != nil {
+			return database.NewDatabaseError(
+				fmt.Sprintf("price change rate at index %d is invalid", i),
+				database.ErrInvalidInput,
+			)
+		}
+	}
+
+	start := startOperation()
+	result := d.db.WithContext(ctx).Create(rates)
+	logDAOOperation(d.logger, "PriceChangeRateDAO.CreateBatch", durationSince(start), result.Error,
+		zap.Int("count", len(rates)))
+
+	if result.Error != nil {
+		return database.WrapDatabaseError(result.Error, "failed to batch create price change rates")
+	}
+
+	return nil
+}
+
+// GetHistory 按时间范围查询交易对在指定窗口的变化率历史（支持分页，按时间降序）
+func (d *priceChangeRateDAOImpl) GetHistory(ctx context.Context, symbol, windowSize string, startTime, endTime time.Time, limit, offset int) ([]*models.PriceChangeRate, error) {
+	if symbol == "" {
+		return nil, database.NewDatabaseError(errMsgSymbolEmpty, database.ErrInvalidInput)
+	}
+
+	if windowSize == "" {
+		return nil, database.NewDatabaseError(errMsgWindowSizeEmpty, database.ErrInvalidInput)
+	}
+
+	if startTime.After(endTime) {
+		return nil, database.NewDatabaseError("start time must be before or equal to end time", database.ErrInvalidInput)
+	}
+
+	if limit <= 0 || limit > 200 {
+		return nil, database.NewDatabaseError(
+			fmt.Sprintf(errMsgLimitOutOfRange, limit),
+			database.ErrInvalidInput,
+		)
+	}
+
+	var rates []*models.PriceChangeRate
+
+	err := d.db.WithContext(ctx).
+		Where("symbol = ? AND window_size = ? AND timestamp >= ? AND timestamp <= ?",
+			symbol, windowSize, startTime, endTime).
+		Order(orderByTimestampDesc).
+		Limit(limit).
+		Offset(offset).
+		Find(&rates).Error
+
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to get price change rate history")
+	}
+
+	return rates, nil
+}
+
+// GetTopMovers 查询指定窗口在 since 之后变化最大的交易对（每个交易对取其最极端的一条记录）
+func (d *priceChangeRateDAOImpl) GetTopMovers(ctx context.Context, windowSize string, since time.Time, direction string, limit int) ([]*models.PriceChangeRate, error) {
+	if windowSize == "" {
+		return nil, database.NewDatabaseError(errMsgWindowSizeEmpty, database.ErrInvalidInput)
+	}
+
+	if limit <= 0 || limit > 200 {
+		return nil, database.NewDatabaseError(
+			fmt.Sprintf(errMsgLimitOutOfRange, limit),
+			database.ErrInvalidInput,
+		)
+	}
+
+	var rankExpr string
+	switch direction {
+	case MoverDirectionUp:
+		rankExpr = "change_rate DESC"
+	case MoverDirectionDown:
+		rankExpr = "change_rate ASC"
+	case "", MoverDirectionAbs:
+		rankExpr = "ABS(change_rate) DESC"
+	default:
+		return nil, database.NewDatabaseError(
+			fmt.Sprintf("invalid direction %q", direction),
+			database.ErrInvalidInput,
+		)
+	}
+
+	// 每个交易对只保留排名第一的记录，再在交易对之间排序
+	ranked := d.db.Model(&models.PriceChangeRate{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY symbol ORDER BY "+rankExpr+", timestamp DESC) AS rn").
+		Where("window_size = ? AND timestamp >= ?", windowSize, since)
+
+	var rates []*models.PriceChangeRate
+
+	start := startOperation()
+	err := d.db.WithContext(ctx).
+		Table("(?) AS ranked", ranked).
+		Where("rn = 1").
+		Order(rankExpr).
+		Limit(limit).
+		Find(&rates).Error
+	logSlowQuery(d.logger, "PriceChangeRateDAO.GetTopMovers", durationSince(start),
+		zap.String("window_size", windowSize))
+
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to get top movers")
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupPriceChangeRateTestDB 创建测试数据库
+func setupPriceChangeRateTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.PriceChangeRate{})
+	require.NoError(t, err)
+
+	return db
+}
+
+// createTestChangeRate 创建测试用的变化率数据
+func createTestChangeRate(symbol, window string, changeRate float64, timestamp time.Time) *models.PriceChangeRate {
+	return &models.PriceChangeRate{
+		Symbol:      symbol,
+		Timestamp:   timestamp,
+		WindowSize:  window,
+		ChangeRate:  changeRate,
+		PriceBefore: 100,
+		PriceAfter:  100 * (1 + changeRate/100),
+	}
+}
+
+func TestPriceChangeRateDAO_CreateAndHistory(t *testing.T) {
+	db := setupPriceChangeRateTestDB(t)
+	dao := NewPriceChangeRateDAO(db, zap.NewNop())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	t.Run("参数校验", func(t *testing.T) {
+		assert.ErrorIs(t, dao.Create(ctx, nil), database.ErrInvalidInput)
+		assert.Error(t, dao.Create(ctx, createTestChangeRate("", "1m", 1, now)))
+		assert.Error(t, dao.Create(ctx, createTestChangeRate("BTCUSDT", "", 1, now)))
+		assert.ErrorIs(t, dao.CreateBatch(ctx, nil), database.ErrInvalidInput)
+
+		invalid := createTestChangeRate("BTCUSDT", "1m", 1, now)
+		invalid.PriceBefore = 0
+		assert.Error(t, dao.CreateBatch(ctx, []*models.PriceChangeRate{invalid}))
+	})
+
+	t.Run("按窗口和时间范围查询历史", func(t *testing.T) {
+		var rates []*models.PriceChangeRate
+		for i := 0; i < 5; i++ {
+			rates = append(rates,
+				createTestChangeRate("BTCUSDT", "10s", float64(i), now.Add(-time.Duration(i)*time.Minute)),
+				createTestChangeRate("BTCUSDT", "5m", float64(i)*2, now.Add(-time.Duration(i)*time.Minute)),
+			)
+		}
+		require.NoError(t, dao.CreateBatch(ctx, rates))
+		require.NoError(t, dao.Create(ctx, createTestChangeRate("ETHUSDT", "10s", 9, now)))
+
+		history, err := dao.GetHistory(ctx, "BTCUSDT", "10s", now.Add(-3*time.Minute), now, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, history, 4)
+		assert.True(t, history[0].Timestamp.After(history[1].Timestamp), "按时间降序")
+		for _, rate := range history {
+			assert.Equal(t, "10s", rate.WindowSize)
+			assert.Equal(t, "BTCUSDT", rate.Symbol)
+		}
+
+		page, err := dao.GetHistory(ctx, "BTCUSDT", "10s", now.Add(-time.Hour), now, 2, 2)
+		require.NoError(t, err)
+		assert.Len(t, page, 2)
+
+		_, err = dao.GetHistory(ctx, "BTCUSDT", "10s", now, now.Add(-time.Hour), 10, 0)
+		assert.Error(t, err)
+		_, err = dao.GetHistory(ctx, "BTCUSDT", "10s", now.Add(-time.Hour), now, 0, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestPriceChangeRateDAO_GetTopMovers(t *testing.T) {
+	db := setupPriceChangeRateTestDB(t)
+	dao := NewPriceChangeRateDAO(db, zap.NewNop())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	rates := []*models.PriceChangeRate{
+		createTestChangeRate("BTCUSDT", "1m", 1.5, now.Add(-2*time.Minute)),
+		createTestChangeRate("BTCUSDT", "1m", 3.0, now.Add(-time.Minute)),
+		createTestChangeRate("ETHUSDT", "1m", -5.0, now.Add(-time.Minute)),
+		createTestChangeRate("SOLUSDT", "1m", 2.0, now),
+		createTestChangeRate("DOGEUSDT", "1m", 10.0, now.Add(-2*time.Hour)), // 超出查询范围
+		createTestChangeRate("XRPUSDT", "5m", 20.0, now),                    // 其他窗口
+	}
+	require.NoError(t, dao.CreateBatch(ctx, rates))
+
+	since := now.Add(-time.Hour)
+
+	t.Run("按绝对值排序且每个交易对只出现一次", func(t *testing.T) {
+		movers, err := dao.GetTopMovers(ctx, "1m", since, MoverDirectionAbs, 10)
+		require.NoError(t, err)
+		require.Len(t, movers, 3)
+		assert.Equal(t, "ETHUSDT", movers[0].Symbol)
+		assert.Equal(t, "BTCUSDT", movers[1].Symbol)
+		assert.Equal(t, 3.0, movers[1].ChangeRate)
+		assert.Equal(t, "SOLUSDT", movers[2].Symbol)
+	})
+
+	t.Run("按方向排序", func(t *testing.T) {
+		up, err := dao.GetTopMovers(ctx, "1m", since, MoverDirectionUp, 1)
+		require.NoError(t, err)
+		require.Len(t, up, 1)
+		assert.Equal(t, "BTCUSDT", up[0].Symbol)
+
+		down, err := dao.GetTopMovers(ctx, "1m", since, MoverDirectionDown, 1)
+		require.NoError(t, err)
+		require.Len(t, down, 1)
+		assert.Equal(t, "ETHUSDT", down[0].Symbol)
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		_, err := dao.GetTopMovers(ctx, "", since, MoverDirectionAbs, 10)
+		assert.Error(t, err)
+		_, err = dao.GetTopMovers(ctx, "1m", since, "sideways", 10)
+		assert.Error(t, err)
+		_, err = dao.GetTopMovers(ctx, "1m", since, MoverDirectionAbs, 500)
+		assert.Error(t, err)
+	})
+}
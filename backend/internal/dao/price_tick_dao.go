@@ -56,7 +56,7 @@ func (d *priceTickDAOImpl) Create(ctx context.Context, tick *models.PriceTick) e
 		return database.NewDatabaseError(errMsgSymbolEmpty, database.ErrInvalidInput)
 	}
 
-	if tick.LastPrice <= 0 {
+	if tick.LastPrice.Sign() <= 0 {
 		return database.NewDatabaseError("last price must be positive", database.ErrInvalidInput)
 	}
 
@@ -83,7 +83,7 @@ func (d *priceTickDAOImpl) CreateBatch(ctx context.Context, ticks []*models.Pric
 
 	// 验证所有价格数据有效
 	for i, tick := range ticks {
-		if tick == nil || tick.Symbol == "" || tick.LastPrice <= 0 {
+		if tick == nil || tick.Symbol == "" || tick.LastPrice.Sign() <= 0 {
 			return database.NewDatabaseError(
 				fmt.Sprintf("price tick at index %d is invalid", i),
 				database.ErrInvalidInput,
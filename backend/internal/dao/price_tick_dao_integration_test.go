@@ -12,6 +12,7 @@ import (
 	"github.com/haxrd/cryptosignal-hunter/internal/config"
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -70,7 +71,7 @@ func TestPriceTickDAO_Integration_Create(t *testing.T) {
 		found, err := dao.GetLatest(ctx, "BTCUSDT")
 		require.NoError(t, err)
 		assert.Equal(t, "BTCUSDT", found.Symbol)
-		assert.Equal(t, 50000.0, found.LastPrice)
+		assert.Equal(t, "50000", found.LastPrice.String())
 	})
 
 	t.Run("验证可以插入相同时间戳的多条数据", func(t *testing.T) {
@@ -82,7 +83,7 @@ func TestPriceTickDAO_Integration_Create(t *testing.T) {
 
 		// PriceTick 表没有唯一约束，应该允许插入多条相同时间的数据
 		tick2 := createTestPriceTick("ETHUSDT", now)
-		tick2.LastPrice = 3000.0
+		tick2.LastPrice = decimal.NewFromInt(3000)
 		err = dao.Create(ctx, tick2)
 		require.NoError(t, err)
 
@@ -323,16 +324,14 @@ func TestPriceTickDAO_Integration_DataConsistency(t *testing.T) {
 		assert.True(t, now.Sub(found.Timestamp).Abs() < time.Microsecond)
 	})
 
-	t.Run("验证浮点数精度", func(t *testing.T) {
+	t.Run("验证十进制精度", func(t *testing.T) {
 		now := time.Now().UTC().Truncate(time.Second)
 		tick := createTestPriceTick("PRECISION", now)
 
 		// 设置高精度价格（8位小数）
-		tick.LastPrice = 12345.12345678
-		askPrice := 12346.87654321
-		bidPrice := 12344.11111111
-		tick.AskPrice = &askPrice
-		tick.BidPrice = &bidPrice
+		tick.LastPrice = decimal.RequireFromString("12345.12345678")
+		tick.AskPrice = models.DecimalPtr(decimal.RequireFromString("12346.87654321"))
+		tick.BidPrice = models.DecimalPtr(decimal.RequireFromString("12344.11111111"))
 
 		err := dao.Create(ctx, tick)
 		require.NoError(t, err)
@@ -341,11 +340,11 @@ func TestPriceTickDAO_Integration_DataConsistency(t *testing.T) {
 		found, err := dao.GetLatest(ctx, "PRECISION")
 		require.NoError(t, err)
 
-		assert.Equal(t, 12345.12345678, found.LastPrice)
+		assert.Equal(t, "12345.12345678", found.LastPrice.String())
 		assert.NotNil(t, found.AskPrice)
-		assert.Equal(t, 12346.87654321, *found.AskPrice)
+		assert.Equal(t, "12346.87654321", found.AskPrice.String())
 		assert.NotNil(t, found.BidPrice)
-		assert.Equal(t, 12344.11111111, *found.BidPrice)
+		assert.Equal(t, "12344.11111111", found.BidPrice.String())
 	})
 
 	t.Run("验证可选字段处理", func(t *testing.T) {
@@ -353,7 +352,7 @@ func TestPriceTickDAO_Integration_DataConsistency(t *testing.T) {
 		tick := &models.PriceTick{
 			Symbol:    "MINIMAL",
 			Timestamp: now,
-			LastPrice: 100.0,
+			LastPrice: decimal.NewFromInt(100),
 			// 其他字段都是 nil（可选）
 		}
 
@@ -363,7 +362,7 @@ func TestPriceTickDAO_Integration_DataConsistency(t *testing.T) {
 		// 查询并验证
 		found, err := dao.GetLatest(ctx, "MINIMAL")
 		require.NoError(t, err)
-		assert.Equal(t, 100.0, found.LastPrice)
+		assert.Equal(t, "100", found.LastPrice.String())
 		assert.Nil(t, found.AskPrice)
 		assert.Nil(t, found.BidPrice)
 		assert.Nil(t, found.High24h)
@@ -391,7 +390,7 @@ func TestPriceTickDAO_Integration_GetLatestMultiple(t *testing.T) {
 		// 为每个交易对创建10条历史数据
 		for j := 0; j < 10; j++ {
 			tick := createTestPriceTick(symbol, now.Add(-time.Duration(j)*time.Minute))
-			tick.LastPrice = float64(1000 + i)
+			tick.LastPrice = decimal.NewFromInt(int64(1000 + i))
 			err := dao.Create(ctx, tick)
 			require.NoError(t, err)
 		}
@@ -411,7 +410,7 @@ func TestPriceTickDAO_Integration_GetLatestMultiple(t *testing.T) {
 			tick, exists := result[symbol]
 			assert.True(t, exists, "symbol %s should exist", symbol)
 			assert.NotNil(t, tick)
-			assert.Equal(t, float64(1000+i), tick.LastPrice)
+			assert.Equal(t, int64(1000+i), tick.LastPrice.IntPart())
 			assert.Equal(t, now, tick.Timestamp) // 最新时间戳
 		}
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -32,24 +33,16 @@ func setupPriceTickTestDB(t *testing.T) (*gorm.DB, *zap.Logger) {
 
 // createTestPriceTick 创建测试用的价格数据
 func createTestPriceTick(symbol string, timestamp time.Time) *models.PriceTick {
-	lastPrice := 50000.0
-	askPrice := 50001.0
-	bidPrice := 49999.0
-	high24h := 51000.0
-	low24h := 49000.0
-	baseVolume := 1000.5
-	quoteVolume := 50000000.0
-
 	return &models.PriceTick{
 		Symbol:      symbol,
 		Timestamp:   timestamp,
-		LastPrice:   lastPrice,
-		AskPrice:    &askPrice,
-		BidPrice:    &bidPrice,
-		High24h:     &high24h,
-		Low24h:      &low24h,
-		BaseVolume:  &baseVolume,
-		QuoteVolume: &quoteVolume,
+		LastPrice:   decimal.NewFromInt(50000),
+		AskPrice:    models.DecimalPtr(decimal.NewFromInt(50001)),
+		BidPrice:    models.DecimalPtr(decimal.NewFromInt(49999)),
+		High24h:     models.DecimalPtr(decimal.NewFromInt(51000)),
+		Low24h:      models.DecimalPtr(decimal.NewFromInt(49000)),
+		BaseVolume:  models.DecimalPtr(decimal.RequireFromString("1000.5")),
+		QuoteVolume: models.DecimalPtr(decimal.NewFromInt(50000000)),
 	}
 }
 
@@ -69,9 +62,9 @@ func TestPriceTickDAO_Create(t *testing.T) {
 		err = db.Where("symbol = ? AND timestamp = ?", "BTCUSDT", now).First(&saved).Error
 		require.NoError(t, err)
 		assert.Equal(t, "BTCUSDT", saved.Symbol)
-		assert.Equal(t, 50000.0, saved.LastPrice)
+		assert.Equal(t, "50000", saved.LastPrice.String())
 		assert.NotNil(t, saved.AskPrice)
-		assert.Equal(t, 50001.0, *saved.AskPrice)
+		assert.Equal(t, "50001", saved.AskPrice.String())
 	})
 
 	t.Run("允许相同交易对的多条数据", func(t *testing.T) {
@@ -82,7 +75,7 @@ func TestPriceTickDAO_Create(t *testing.T) {
 
 		// PriceTick 没有唯一约束，应该允许插入多条相同时间的数据
 		tick2 := createTestPriceTick("ETHUSDT", now)
-		tick2.LastPrice = 3000.0
+		tick2.LastPrice = decimal.NewFromInt(3000)
 		err = dao.Create(ctx, tick2)
 		require.NoError(t, err)
 	})
@@ -102,7 +95,7 @@ func TestPriceTickDAO_Create(t *testing.T) {
 	t.Run("创建零价格应返回错误", func(t *testing.T) {
 		now := time.Now().UTC()
 		tick := createTestPriceTick("BTCUSDT", now)
-		tick.LastPrice = 0
+		tick.LastPrice = decimal.Zero
 		err := dao.Create(ctx, tick)
 		assert.ErrorIs(t, err, database.ErrInvalidInput)
 	})
@@ -110,7 +103,7 @@ func TestPriceTickDAO_Create(t *testing.T) {
 	t.Run("创建负价格应返回错误", func(t *testing.T) {
 		now := time.Now().UTC()
 		tick := createTestPriceTick("BTCUSDT", now)
-		tick.LastPrice = -100
+		tick.LastPrice = decimal.NewFromInt(-100)
 		err := dao.Create(ctx, tick)
 		assert.ErrorIs(t, err, database.ErrInvalidInput)
 	})
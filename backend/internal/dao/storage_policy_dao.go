@@ -0,0 +1,532 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const errMsgCompressionDisabled = "compression is not enabled on %s"
+
+// errMsgRetentionBeforeArchive 保留期不长于归档延迟加一天时数据在归档之前就被删除
+const errMsgRetentionBeforeArchive = "retention of %s (%s) must be longer than the archive delay plus one day (%s), otherwise data is dropped before it is archived"
+
+// errMsgKlineRetention klines 表混合了所有周期，按 chunk 删除会同时删除长周期K线
+const errMsgKlineRetention = "klines mixes all granularities, set per-granularity retention with SetKlineRetention instead"
+
+// storageTargetsSQL 当前 schema 下的超表和连续聚合，连续聚合的数据存放在其物化超表中
+const storageTargetsSQL = `WITH targets AS (
+	SELECT hypertable_name AS name, 'hypertable' AS kind,
+		hypertable_schema AS storage_schema, hypertable_name AS storage_name, compression_enabled
+	FROM timescaledb_information.hypertables
+	WHERE hypertable_schema = current_schema()
+	UNION ALL
+	SELECT view_name, 'continuous_aggregate',
+		materialization_hypertable_schema, materialization_hypertable_name, compression_enabled
+	FROM timescaledb_information.continuous_aggregates
+	WHERE view_schema = current_schema()
+)`
+
+// listStorageTablesSQL 查询策略、chunk 数量和空间占用
+const listStorageTablesSQL = storageTargetsSQL + `
+SELECT t.name, t.kind, t.compression_enabled,
+	(SELECT j.config->>'compress_after' FROM timescaledb_information.jobs j
+		WHERE j.proc_name = 'policy_compression'
+			AND j.hypertable_schema = t.storage_schema AND j.hypertable_name = t.storage_name
+		LIMIT 1) AS compress_after,
+	(SELECT j.config->>'drop_after' FROM timescaledb_information.jobs j
+		WHERE j.proc_name = 'policy_retention'
+			AND j.hypertable_schema = t.storage_schema AND j.hypertable_name = t.storage_name
+		LIMIT 1) AS drop_after,
+	(SELECT count(*) FROM timescaledb_information.chunks c
+		WHERE c.hypertable_schema = t.storage_schema AND c.hypertable_name = t.storage_name) AS total_chunks,
+	(SELECT count(*) FROM timescaledb_information.chunks c
+		WHERE c.hypertable_schema = t.storage_schema AND c.hypertable_name = t.storage_name
+			AND c.is_compressed) AS compressed_chunks,
+	hypertable_size(format('%I.%I', t.storage_schema, t.storage_name)::regclass) AS total_bytes,
+	COALESCE(s.before_compression_total_bytes, 0) AS before_compression,
+	COALESCE(s.after_compression_total_bytes, 0) AS after_compression
+FROM targets t
+LEFT JOIN LATERAL hypertable_compression_stats(format('%I.%I', t.storage_schema, t.storage_name)::regclass) s ON true`
+
+// listStorageChunksSQL 查询超表的 chunk 及压缩统计，参数依次为物化超表、schema、表名
+const listStorageChunksSQL = `SELECT c.chunk_schema AS schema, c.chunk_name AS name,
+	c.range_start, c.range_end, c.is_compressed,
+	pg_total_relation_size(format('%I.%I', c.chunk_schema, c.chunk_name)::regclass) AS total_bytes,
+	COALESCE(s.before_compression_total_bytes, 0) AS before_compression,
+	COALESCE(s.after_compression_total_bytes, 0) AS after_compression
+FROM timescaledb_information.chunks c
+LEFT JOIN chunk_compression_stats(?::regclass) s
+	ON s.chunk_schema = c.chunk_schema AND s.chunk_name = c.chunk_name
+WHERE c.hypertable_schema = ? AND c.hypertable_name = ?
+ORDER BY c.range_start DESC`
+
+// StoragePolicyDAO TimescaleDB 压缩、保留策略及K线按周期保留期的管理接口
+type StoragePolicyDAO interface {
+	// ListTables 列出所有超表和连续聚合的策略与空间占用
+	ListTables(ctx context.Context) ([]*models.StorageTable, error)
+
+	// GetTable 查询单个超表或连续聚合
+	GetTable(ctx context.Context, name string) (*models.StorageTable, error)
+
+	// ListChunks 列出超表或连续聚合的 chunk（按时间降序）
+	ListChunks(ctx context.Context, name string) ([]*models.StorageChunk, error)
+
+	// SetCompressionPolicy 设置压缩策略，compressAfter 为空时移除策略
+	SetCompressionPolicy(ctx context.Context, name string, compressAfter *time.Duration) error
+
+	// SetRetentionPolicy 设置保留策略，dropAfter 为空时移除策略（永久保留）
+	// klines 不能设置保留策略，应使用 SetKlineRetention
+	SetRetentionPolicy(ctx context.Context, name string, dropAfter *time.Duration) error
+
+	// CompressChunks 立即压缩早于 olderThan 的未压缩 chunk，返回压缩的 chunk 数
+	CompressChunks(ctx context.Context, name string, olderThan time.Duration) (int64, error)
+
+	// ListKlineRetention 列出K线按周期的保留期
+	ListKlineRetention(ctx context.Context) ([]*models.KlineRetentionPolicy, error)
+
+	// SetKlineRetention 设置周期的保留天数，retentionDays 为空表示永久保留
+	SetKlineRetention(ctx context.Context, granularity string, retentionDays *int) error
+
+	// PurgeKlines 删除周期早于 before 的K线，返回删除的行数
+	PurgeKlines(ctx context.Context, granularity string, before time.Time) (int64, error)
+}
+
+// ArchiveRetention 开启归档时保留期的下限
+// 一天的数据在当天结束 Delay 之后才归档，归档表的保留期必须长于 Delay 加一天，否则数据在归档之前就被删除
+type ArchiveRetention struct {
+	Tables []string      // 归档的表（price_ticks、klines）
+	Delay  time.Duration // 一天结束后多久归档
+}
+
+// MinRetention 归档表允许的最短保留期（不含）
+func (r *ArchiveRetention) MinRetention() time.Duration {
+	return r.Delay + 24*time.Hour
+}
+
+// Check 检查表的保留期是否长于归档需要的最短保留期，不归档的表总是通过
+func (r *ArchiveRetention) Check(table string, retention time.Duration) error {
+	if r == nil || !slices.Contains(r.Tables, table) || retention > r.MinRetention() {
+		return nil
+	}
+	return database.NewDatabaseError(
+		fmt.Sprintf(errMsgRetentionBeforeArchive, table, PostgresInterval(retention), PostgresInterval(r.MinRetention())),
+		database.ErrInvalidInput)
+}
+
+// storagePolicyDAOImpl StoragePolicyDAO 实现
+type storagePolicyDAOImpl struct {
+	db     *gorm.DB
+	logger *zap.Logger
+
+	// archive 开启归档时保留期的下限，为空时不限制
+	archive *ArchiveRetention
+}
+
+// NewStoragePolicyDAO 创建 StoragePolicyDAO 实例
+func NewStoragePolicyDAO(db *gorm.DB, logger *zap.Logger) StoragePolicyDAO {
+	return NewStoragePolicyDAOWithArchive(db, nil, logger)
+}
+
+// NewStoragePolicyDAOWithArchive 创建开启归档时使用的 StoragePolicyDAO 实例，
+// 拒绝把归档表的保留期设置为不长于归档延迟加一天
+func NewStoragePolicyDAOWithArchive(db *gorm.DB, archive *ArchiveRetention, logger *zap.Logger) StoragePolicyDAO {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &storagePolicyDAOImpl{
+		db:      db,
+		logger:  logger,
+		archive: archive,
+	}
+}
+
+// storageTableRow 策略查询结果
+type storageTableRow struct {
+	Name               string
+	Kind               string
+	CompressionEnabled bool
+	CompressAfter      *string
+	DropAfter          *string
+	TotalChunks        int64
+	CompressedChunks   int64
+	TotalBytes         int64
+	BeforeCompression  int64
+	AfterCompression   int64
+}
+
+// storageTarget 超表或连续聚合对应的物化超表
+type storageTarget struct {
+	Name               string
+	Kind               string
+	StorageSchema      string
+	StorageName        string
+	CompressionEnabled bool
+}
+
+// regclass 物化超表的限定名
+func (t *storageTarget) regclass() string {
+	return pgx.Identifier{t.StorageSchema, t.StorageName}.Sanitize()
+}
+
+// ListTables 列出所有超表和连续聚合的策略与空间占用
+func (d *storagePolicyDAOImpl) ListTables(ctx context.Context) ([]*models.StorageTable, error) {
+	start := time.Now()
+
+	var rows []storageTableRow
+	err := d.db.WithContext(ctx).Raw(listStorageTablesSQL + " ORDER BY t.kind DESC, t.name").Scan(&rows).Error
+	logDAOOperation(d.logger, "StoragePolicyDAO.ListTables", time.Since(start), err, zap.Int("count", len(rows)))
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to list hypertables")
+	}
+
+	tables := make([]*models.StorageTable, 0, len(rows))
+	for i := range rows {
+		tables = append(tables, rows[i].toModel())
+	}
+	return tables, nil
+}
+
+// GetTable 查询单个超表或连续聚合
+func (d *storagePolicyDAOImpl) GetTable(ctx context.Context, name string) (*models.StorageTable, error) {
+	if name == "" {
+		return nil, database.ErrInvalidInput
+	}
+
+	var rows []storageTableRow
+	err := d.db.WithContext(ctx).Raw(listStorageTablesSQL+" WHERE t.name = ?", name).Scan(&rows).Error
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to get hypertable")
+	}
+	if len(rows) == 0 {
+		return nil, database.ErrRecordNotFound
+	}
+	return rows[0].toModel(), nil
+}
+
+// ListChunks 列出超表或连续聚合的 chunk（按时间降序）
+func (d *storagePolicyDAOImpl) ListChunks(ctx context.Context, name string) ([]*models.StorageChunk, error) {
+	target, err := d.target(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var chunks []*models.StorageChunk
+	err = d.db.WithContext(ctx).
+		Raw(listStorageChunksSQL, target.regclass(), target.StorageSchema, target.StorageName).
+		Scan(&chunks).Error
+	logDAOOperation(d.logger, "StoragePolicyDAO.ListChunks", time.Since(start), err,
+		zap.String("table", name), zap.Int("count", len(chunks)))
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to list chunks")
+	}
+
+	for _, chunk := range chunks {
+		// 压缩后数据移到内部的压缩 chunk，原 chunk 只剩空表
+		if chunk.IsCompressed && chunk.AfterCompression > 0 {
+			chunk.TotalBytes = chunk.AfterCompression
+		}
+		chunk.CompressionRatio = models.CompressionRatio(chunk.BeforeCompression, chunk.AfterCompression)
+	}
+	return chunks, nil
+}
+
+// SetCompressionPolicy 设置压缩策略，compressAfter 为空时移除策略
+func (d *storagePolicyDAOImpl) SetCompressionPolicy(ctx context.Context, name string, compressAfter *time.Duration) error {
+	target, err := d.target(ctx, name)
+	if err != nil {
+		return err
+	}
+	if compressAfter != nil {
+		if *compressAfter <= 0 {
+			return database.NewDatabaseError("compress_after must be positive", database.ErrInvalidInput)
+		}
+		if !target.CompressionEnabled {
+			return database.NewDatabaseError(fmt.Sprintf(errMsgCompressionDisabled, name), database.ErrInvalidInput)
+		}
+	}
+
+	err = d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT remove_compression_policy(?::regclass, if_exists => true)", name).Error; err != nil {
+			return err
+		}
+		if compressAfter == nil {
+			return nil
+		}
+		return tx.Exec("SELECT add_compression_policy(?::regclass, ?::interval)", name, PostgresInterval(*compressAfter)).Error
+	})
+	if err != nil {
+		return database.WrapDatabaseError(err, "failed to set compression policy")
+	}
+
+	d.logger.Info("Compression policy updated", zap.String("table", name), zap.Stringp("compress_after", intervalString(compressAfter)))
+	return nil
+}
+
+// SetRetentionPolicy 设置保留策略，dropAfter 为空时移除策略（永久保留）
+// klines 只能移除策略，保留期按周期通过 SetKlineRetention 设置
+func (d *storagePolicyDAOImpl) SetRetentionPolicy(ctx context.Context, name string, dropAfter *time.Duration) error {
+	if dropAfter != nil && name == (models.Kline{}).TableName() {
+		return database.NewDatabaseError(errMsgKlineRetention, database.ErrInvalidInput)
+	}
+	if _, err := d.target(ctx, name); err != nil {
+		return err
+	}
+	if dropAfter != nil && *dropAfter <= 0 {
+		return database.NewDatabaseError("drop_after must be positive", database.ErrInvalidInput)
+	}
+	if dropAfter != nil {
+		if err := d.archive.Check(name, *dropAfter); err != nil {
+			return err
+		}
+	}
+
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT remove_retention_policy(?::regclass, if_exists => true)", name).Error; err != nil {
+			return err
+		}
+		if dropAfter == nil {
+			return nil
+		}
+		return tx.Exec("SELECT add_retention_policy(?::regclass, ?::interval)", name, PostgresInterval(*dropAfter)).Error
+	})
+	if err != nil {
+		return database.WrapDatabaseError(err, "failed to set retention policy")
+	}
+
+	d.logger.Info("Retention policy updated", zap.String("table", name), zap.Stringp("drop_after", intervalString(dropAfter)))
+	return nil
+}
+
+// CompressChunks 立即压缩早于 olderThan 的未压缩 chunk，返回压缩的 chunk 数
+func (d *storagePolicyDAOImpl) CompressChunks(ctx context.Context, name string, olderThan time.Duration) (int64, error) {
+	if olderThan <= 0 {
+		return 0, database.NewDatabaseError("older_than must be positive", database.ErrInvalidInput)
+	}
+	target, err := d.target(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	if !target.CompressionEnabled {
+		return 0, database.NewDatabaseError(fmt.Sprintf(errMsgCompressionDisabled, name), database.ErrInvalidInput)
+	}
+
+	start := time.Now()
+	var compressed int64
+	err = d.db.WithContext(ctx).Raw(`SELECT count(*) FROM (
+	SELECT compress_chunk(c, if_not_compressed => true)
+	FROM show_chunks(?::regclass, older_than => ?::interval) c
+) compressed`, target.regclass(), PostgresInterval(olderThan)).Scan(&compressed).Error
+	logDAOOperation(d.logger, "StoragePolicyDAO.CompressChunks", time.Since(start), err,
+		zap.String("table", name), zap.Int64("chunks", compressed))
+	if err != nil {
+		return 0, database.WrapDatabaseError(err, "failed to compress chunks")
+	}
+	return compressed, nil
+}
+
+// ListKlineRetention 列出K线按周期的保留期
+func (d *storagePolicyDAOImpl) ListKlineRetention(ctx context.Context) ([]*models.KlineRetentionPolicy, error) {
+	var policies []*models.KlineRetentionPolicy
+	if err := d.db.WithContext(ctx).Order("granularity").Find(&policies).Error; err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to list kline retention policies")
+	}
+	return policies, nil
+}
+
+// SetKlineRetention 设置周期的保留天数，retentionDays 为空表示永久保留
+func (d *storagePolicyDAOImpl) SetKlineRetention(ctx context.Context, granularity string, retentionDays *int) error {
+	if granularity == "" {
+		return database.NewDatabaseError(errMsgGranularityEmpty, database.ErrInvalidInput)
+	}
+	if retentionDays != nil && *retentionDays <= 0 {
+		return database.NewDatabaseError("retention days must be positive", database.ErrInvalidInput)
+	}
+	if retentionDays != nil {
+		if err := d.archive.Check(models.ArchiveTableKlines, time.Duration(*retentionDays)*24*time.Hour); err != nil {
+			return err
+		}
+	}
+
+	policy := &models.KlineRetentionPolicy{
+		Granularity:   granularity,
+		RetentionDays: retentionDays,
+		UpdatedAt:     time.Now(),
+	}
+	err := d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "granularity"}},
+			DoUpdates: clause.AssignmentColumns([]string{"retention_days", "updated_at"}),
+		}).
+		Create(policy).Error
+	if err != nil {
+		return database.WrapDatabaseError(err, "failed to set kline retention policy")
+	}
+	return nil
+}
+
+// PurgeKlines 删除周期早于 before 的K线，返回删除的行数
+// 已压缩的 chunk 需要解压后删除（TimescaleDB 2.11+ 自动处理），应在低峰期执行
+func (d *storagePolicyDAOImpl) PurgeKlines(ctx context.Context, granularity string, before time.Time) (int64, error) {
+	if granularity == "" {
+		return 0, database.NewDatabaseError(errMsgGranularityEmpty, database.ErrInvalidInput)
+	}
+	if before.IsZero() {
+		return 0, database.NewDatabaseError("purge time cannot be zero", database.ErrInvalidInput)
+	}
+
+	start := time.Now()
+	result := d.db.WithContext(ctx).
+		Where("granularity = ? AND timestamp < ?", granularity, before).
+		Delete(&models.Kline{})
+	logDAOOperation(d.logger, "StoragePolicyDAO.PurgeKlines", time.Since(start), result.Error,
+		zap.String("granularity", granularity), zap.Int64("rows", result.RowsAffected))
+	if result.Error != nil {
+		return 0, database.WrapDatabaseError(result.Error, "failed to purge klines")
+	}
+	return result.RowsAffected, nil
+}
+
+// target 查询超表或连续聚合对应的物化超表
+func (d *storagePolicyDAOImpl) target(ctx context.Context, name string) (*storageTarget, error) {
+	if name == "" {
+		return nil, database.ErrInvalidInput
+	}
+
+	var targets []storageTarget
+	err := d.db.WithContext(ctx).
+		Raw(storageTargetsSQL+" SELECT * FROM targets WHERE name = ?", name).
+		Scan(&targets).Error
+	if err != nil {
+		return nil, database.WrapDatabaseError(err, "failed to look up hypertable")
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("hypertable %s: %w", name, database.ErrRecordNotFound)
+	}
+	return &targets[0], nil
+}
+
+// toModel 转换为存储策略模型
+func (r *storageTableRow) toModel() *models.StorageTable {
+	return &models.StorageTable{
+		Name:               r.Name,
+		Kind:               r.Kind,
+		CompressionEnabled: r.CompressionEnabled,
+		CompressAfter:      r.CompressAfter,
+		DropAfter:          r.DropAfter,
+		TotalChunks:        r.TotalChunks,
+		CompressedChunks:   r.CompressedChunks,
+		TotalBytes:         r.TotalBytes,
+		BeforeCompression:  r.BeforeCompression,
+		AfterCompression:   r.AfterCompression,
+		CompressionRatio:   models.CompressionRatio(r.BeforeCompression, r.AfterCompression),
+	}
+}
+
+// PostgresInterval 将时长格式化为 PostgreSQL interval 字面量，能整除时使用天或小时以便阅读
+func PostgresInterval(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%d days", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%d minutes", d/time.Minute)
+	default:
+		return fmt.Sprintf("%d seconds", d/time.Second)
+	}
+}
+
+// ParsePostgresInterval 解析 PostgreSQL 输出的 interval（如 "14 days"、"1 day 12:00:00"、"36:00:00"），
+// 月按30天、年按365天计算
+func ParsePostgresInterval(interval string) (time.Duration, error) {
+	fields := strings.Fields(interval)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty interval")
+	}
+
+	var total time.Duration
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if strings.Contains(field, ":") {
+			clock, err := parseIntervalClock(field)
+			if err != nil {
+				return 0, fmt.Errorf("invalid interval %q: %w", interval, err)
+			}
+			total += clock
+			continue
+		}
+
+		if i+1 >= len(fields) {
+			return 0, fmt.Errorf("invalid interval %q: missing unit", interval)
+		}
+		value, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q: %w", interval, err)
+		}
+		i++
+		unit, ok := intervalUnits[strings.TrimSuffix(fields[i], "s")]
+		if !ok {
+			return 0, fmt.Errorf("invalid interval %q: unknown unit %s", interval, fields[i])
+		}
+		total += time.Duration(value) * unit
+	}
+	return total, nil
+}
+
+// intervalUnits PostgreSQL interval 输出的单位（去掉复数后缀）
+var intervalUnits = map[string]time.Duration{
+	"year":   365 * 24 * time.Hour,
+	"mon":    30 * 24 * time.Hour,
+	"day":    24 * time.Hour,
+	"hour":   time.Hour,
+	"minute": time.Minute,
+	"second": time.Second,
+}
+
+// parseIntervalClock 解析 interval 中的 [-]HH:MM:SS 部分
+func parseIntervalClock(clock string) (time.Duration, error) {
+	sign := time.Duration(1)
+	if strings.HasPrefix(clock, "-") {
+		sign = -1
+		clock = clock[1:]
+	}
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %s", clock)
+	}
+	hours, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, err
+	}
+	return sign * (time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second))), nil
+}
+
+// intervalString 用于日志，空表示移除策略
+func intervalString(d *time.Duration) *string {
+	if d == nil {
+		return nil
+	}
+	s := PostgresInterval(*d)
+	return &s
+}
@@ -0,0 +1,70 @@
+// +build integration
+
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStoragePolicyDAO_Integration 集成测试：查询和修改 TimescaleDB 策略（需要已执行全部迁移）
+func TestStoragePolicyDAO_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("跳过集成测试")
+	}
+
+	db, logger := setupKlineIntegrationTestDB(t)
+	dao := NewStoragePolicyDAO(db, logger)
+	ctx := context.Background()
+
+	tables, err := dao.ListTables(ctx)
+	require.NoError(t, err)
+	kinds := make(map[string]string)
+	for _, table := range tables {
+		kinds[table.Name] = table.Kind
+	}
+	assert.Equal(t, models.StorageKindHypertable, kinds["price_ticks"])
+	assert.Equal(t, models.StorageKindHypertable, kinds["klines"])
+	assert.Equal(t, models.StorageKindContinuousAggregate, kinds["klines_1d"])
+
+	ticks, err := dao.GetTable(ctx, "price_ticks")
+	require.NoError(t, err)
+	require.NotNil(t, ticks.DropAfter)
+	assert.True(t, ticks.CompressionEnabled)
+
+	_, err = dao.GetTable(ctx, "not_a_table")
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+	_, err = dao.ListChunks(ctx, "not_a_table")
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+
+	_, err = dao.ListChunks(ctx, "price_ticks")
+	require.NoError(t, err)
+
+	// 修改后恢复迁移中的配置
+	dropAfter := 21 * 24 * time.Hour
+	require.NoError(t, dao.SetRetentionPolicy(ctx, "price_ticks", &dropAfter))
+	ticks, err = dao.GetTable(ctx, "price_ticks")
+	require.NoError(t, err)
+	require.NotNil(t, ticks.DropAfter)
+	assert.Equal(t, "21 days", *ticks.DropAfter)
+
+	dropAfter = 14 * 24 * time.Hour
+	require.NoError(t, dao.SetRetentionPolicy(ctx, "price_ticks", &dropAfter))
+
+	require.NoError(t, dao.SetCompressionPolicy(ctx, "klines", nil))
+	klines, err := dao.GetTable(ctx, "klines")
+	require.NoError(t, err)
+	assert.Nil(t, klines.CompressAfter)
+
+	compressAfter := 7 * 24 * time.Hour
+	require.NoError(t, dao.SetCompressionPolicy(ctx, "klines", &compressAfter))
+
+	_, err = dao.CompressChunks(ctx, "price_ticks", 7*24*time.Hour)
+	require.NoError(t, err)
+}
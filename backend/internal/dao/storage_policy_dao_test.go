@@ -0,0 +1,154 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/database"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupStoragePolicyTestDB 创建测试数据库
+func setupStoragePolicyTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.Kline{}, &models.KlineRetentionPolicy{})
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestPostgresInterval(t *testing.T) {
+	assert.Equal(t, "14 days", PostgresInterval(14*24*time.Hour))
+	assert.Equal(t, "36 hours", PostgresInterval(36*time.Hour))
+	assert.Equal(t, "90 minutes", PostgresInterval(90*time.Minute))
+	assert.Equal(t, "45 seconds", PostgresInterval(45*time.Second))
+}
+
+func TestParsePostgresInterval(t *testing.T) {
+	cases := map[string]time.Duration{
+		"14 days":          14 * 24 * time.Hour,
+		"1 day":            24 * time.Hour,
+		"1 day 12:00:00":   36 * time.Hour,
+		"36:00:00":         36 * time.Hour,
+		"00:00:01.5":       1500 * time.Millisecond,
+		"1 mon 2 days":     32 * 24 * time.Hour,
+		"1 year":           365 * 24 * time.Hour,
+		"90 minutes":       90 * time.Minute,
+		"-01:00:00":        -time.Hour,
+		"7 days -01:00:00": 7*24*time.Hour - time.Hour,
+	}
+	for interval, expected := range cases {
+		parsed, err := ParsePostgresInterval(interval)
+		require.NoError(t, err, interval)
+		assert.Equal(t, expected, parsed, interval)
+	}
+
+	for _, interval := range []string{"", "14", "days", "1:2", "2 weeks"} {
+		_, err := ParsePostgresInterval(interval)
+		assert.Error(t, err, interval)
+	}
+}
+
+func TestArchiveRetention_Check(t *testing.T) {
+	retention := &ArchiveRetention{Tables: []string{models.ArchiveTablePriceTicks}, Delay: 8 * 24 * time.Hour}
+	assert.Equal(t, 9*24*time.Hour, retention.MinRetention())
+
+	assert.NoError(t, retention.Check(models.ArchiveTablePriceTicks, 14*24*time.Hour))
+	assert.ErrorIs(t, retention.Check(models.ArchiveTablePriceTicks, 9*24*time.Hour), database.ErrInvalidInput)
+	assert.ErrorIs(t, retention.Check(models.ArchiveTablePriceTicks, 3*24*time.Hour), database.ErrInvalidInput)
+	// 不归档的表不限制
+	assert.NoError(t, retention.Check(models.ArchiveTableKlines, 24*time.Hour))
+
+	var disabled *ArchiveRetention
+	assert.NoError(t, disabled.Check(models.ArchiveTablePriceTicks, time.Hour))
+}
+
+func TestStoragePolicyDAO_ArchiveRetention(t *testing.T) {
+	db := setupStoragePolicyTestDB(t)
+	dao := NewStoragePolicyDAOWithArchive(db, &ArchiveRetention{
+		Tables: []string{models.ArchiveTablePriceTicks, models.ArchiveTableKlines},
+		Delay:  8 * 24 * time.Hour,
+	}, zap.NewNop())
+	ctx := context.Background()
+
+	// 保留期不长于归档延迟加一天时拒绝，数据会在归档之前被删除
+	days := 9
+	err := dao.SetKlineRetention(ctx, "1m", &days)
+	assert.ErrorIs(t, err, database.ErrInvalidInput)
+	assert.Contains(t, err.Error(), "archive delay")
+
+	days = 10
+	require.NoError(t, dao.SetKlineRetention(ctx, "1m", &days))
+	require.NoError(t, dao.SetKlineRetention(ctx, "1d", nil))
+}
+
+func TestStoragePolicyDAO_KlineRetention(t *testing.T) {
+	db := setupStoragePolicyTestDB(t)
+	dao := NewStoragePolicyDAO(db, zap.NewNop())
+	ctx := context.Background()
+
+	t.Run("参数校验", func(t *testing.T) {
+		days := 0
+		assert.ErrorIs(t, dao.SetKlineRetention(ctx, "", nil), database.ErrInvalidInput)
+		assert.ErrorIs(t, dao.SetKlineRetention(ctx, "1m", &days), database.ErrInvalidInput)
+		_, err := dao.PurgeKlines(ctx, "", time.Now())
+		assert.ErrorIs(t, err, database.ErrInvalidInput)
+		_, err = dao.PurgeKlines(ctx, "1m", time.Time{})
+		assert.ErrorIs(t, err, database.ErrInvalidInput)
+		_, err = dao.CompressChunks(ctx, "price_ticks", 0)
+		assert.ErrorIs(t, err, database.ErrInvalidInput)
+
+		// klines 混合了所有周期，只能按周期设置保留期
+		dropAfter := 30 * 24 * time.Hour
+		err = dao.SetRetentionPolicy(ctx, "klines", &dropAfter)
+		assert.ErrorIs(t, err, database.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "SetKlineRetention")
+	})
+
+	t.Run("设置和更新保留期", func(t *testing.T) {
+		days := 30
+		require.NoError(t, dao.SetKlineRetention(ctx, "1m", &days))
+		require.NoError(t, dao.SetKlineRetention(ctx, "1d", nil))
+
+		days = 7
+		require.NoError(t, dao.SetKlineRetention(ctx, "1m", &days))
+
+		policies, err := dao.ListKlineRetention(ctx)
+		require.NoError(t, err)
+		require.Len(t, policies, 2)
+		assert.Equal(t, "1d", policies[0].Granularity)
+		assert.Nil(t, policies[0].RetentionDays)
+		assert.Equal(t, "1m", policies[1].Granularity)
+		require.NotNil(t, policies[1].RetentionDays)
+		assert.Equal(t, 7, *policies[1].RetentionDays)
+	})
+
+	t.Run("按周期删除过期K线", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Minute)
+		klines := []*models.Kline{
+			createTestKline("BTCUSDT", "1m", now.Add(-10*24*time.Hour)),
+			createTestKline("BTCUSDT", "1m", now.Add(-time.Hour)),
+			createTestKline("BTCUSDT", "1d", now.Add(-10*24*time.Hour)),
+		}
+		require.NoError(t, db.Create(klines).Error)
+
+		deleted, err := dao.PurgeKlines(ctx, "1m", now.Add(-7*24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		var remaining int64
+		require.NoError(t, db.Model(&models.Kline{}).Count(&remaining).Error)
+		assert.Equal(t, int64(2), remaining)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
 	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -62,7 +63,7 @@ func TestSymbolDAO_Integration_Create(t *testing.T) {
 	t.Run("插入真实数据并验证", func(t *testing.T) {
 		makerFee := 0.0002
 		takerFee := 0.0006
-		minTradeNum := 0.001
+		minTradeNum := decimal.RequireFromString("0.001")
 		pricePlace := 2
 		volumePlace := 4
 
@@ -7,6 +7,7 @@ import (
 	"github.com/haxrd/cryptosignal-hunter/internal/database"
 	"github.com/haxrd/cryptosignal-hunter/internal/models"
 	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -34,7 +35,7 @@ func setupTestDB(t *testing.T) (*gorm.DB, *zap.Logger) {
 func createTestSymbol(symbol string) *models.Symbol {
 	makerFee := 0.0002
 	takerFee := 0.0006
-	minTradeNum := 0.001
+	minTradeNum := decimal.RequireFromString("0.001")
 	pricePlace := 2
 	volumePlace := 4
 
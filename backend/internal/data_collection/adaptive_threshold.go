@@ -0,0 +1,190 @@
+package data_collection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// anomalyStateKeyPrefix 异常检测状态缓存键前缀
+	anomalyStateKeyPrefix = "anomaly_state"
+
+	// defaultAnomalyStateTTL 异常检测状态默认保留时间
+	defaultAnomalyStateTTL = 7 * 24 * time.Hour
+
+	// quantileLower / quantileUpper 在线估计的四分位数
+	quantileLower = 0.25
+	quantileUpper = 0.75
+)
+
+// NewSymbolStatistics 创建交易对统计状态
+func NewSymbolStatistics(symbol string) *SymbolStatistics {
+	return &SymbolStatistics{Symbol: symbol}
+}
+
+// ReturnFrom 计算相对于最近价格的收益率
+func (s *SymbolStatistics) ReturnFrom(price float64) (float64, bool) {
+	if s.LastPrice <= 0 {
+		return 0, false
+	}
+	return (price - s.LastPrice) / s.LastPrice, true
+}
+
+// Update 使用新价格更新在线统计
+// alpha 为EWMA衰减系数，取值 (0, 1]，越大越偏重最近的数据
+func (s *SymbolStatistics) Update(price float64, alpha float64, ts time.Time) {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.1
+	}
+
+	ret, ok := s.ReturnFrom(price)
+	if price > 0 {
+		s.LastPrice = price
+	}
+	s.UpdatedAt = ts
+	if !ok {
+		return
+	}
+
+	if s.Count == 0 {
+		s.MeanReturn = ret
+		s.Variance = 0
+		s.Q1 = ret
+		s.Q3 = ret
+		s.Count = 1
+		return
+	}
+
+	// EWMA均值与方差（增量形式）
+	diff := ret - s.MeanReturn
+	incr := alpha * diff
+	s.MeanReturn += incr
+	s.Variance = (1 - alpha) * (s.Variance + diff*incr)
+
+	// 四分位数随机逼近：步长与当前波动率成比例，使估计随波动区间伸缩
+	step := alpha * s.StdDev()
+	if step == 0 {
+		step = alpha * math.Abs(diff)
+	}
+	s.Q1 += step * (quantileLower - indicator(ret <= s.Q1))
+	s.Q3 += step * (quantileUpper - indicator(ret <= s.Q3))
+	if s.Q1 > s.Q3 {
+		s.Q1, s.Q3 = s.Q3, s.Q1
+	}
+
+	s.Count++
+}
+
+// StdDev 收益率标准差
+func (s *SymbolStatistics) StdDev() float64 {
+	if s.Variance <= 0 {
+		return 0
+	}
+	return math.Sqrt(s.Variance)
+}
+
+// ZScore 计算收益率相对于当前波动区间的Z分数
+func (s *SymbolStatistics) ZScore(ret float64) float64 {
+	stdDev := s.StdDev()
+	if stdDev == 0 {
+		return 0
+	}
+	return (ret - s.MeanReturn) / stdDev
+}
+
+// IQRBounds 按IQR倍数计算收益率的正常区间
+func (s *SymbolStatistics) IQRBounds(multiplier float64) (float64, float64) {
+	iqr := s.Q3 - s.Q1
+	return s.Q1 - multiplier*iqr, s.Q3 + multiplier*iqr
+}
+
+// IsWarm 样本数是否足以启用自适应阈值
+func (s *SymbolStatistics) IsWarm(minSamples int) bool {
+	return s.Count >= int64(minSamples) && s.Variance > 0
+}
+
+// Clone 复制统计状态
+func (s *SymbolStatistics) Clone() *SymbolStatistics {
+	clone := *s
+	return &clone
+}
+
+// indicator 布尔值转换为0/1
+func indicator(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// redisAnomalyStateStore 基于Redis的异常检测状态存储
+type redisAnomalyStateStore struct {
+	cache AnomalyStateKV
+	ttl   time.Duration
+}
+
+// NewRedisAnomalyStateStore 创建基于Redis的异常检测状态存储
+func NewRedisAnomalyStateStore(cache AnomalyStateKV, ttl time.Duration) AnomalyStateStore {
+	if ttl <= 0 {
+		ttl = defaultAnomalyStateTTL
+	}
+	return &redisAnomalyStateStore{
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+// SaveStates 保存所有交易对的统计状态
+func (s *redisAnomalyStateStore) SaveStates(ctx context.Context, states []*SymbolStatistics) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	data := make([]CacheData, 0, len(states))
+	for _, state := range states {
+		data = append(data, CacheData{
+			Key:       fmt.Sprintf("%s:%s", anomalyStateKeyPrefix, state.Symbol),
+			Value:     state,
+			TTL:       s.ttl,
+			Timestamp: time.Now(),
+		})
+	}
+
+	result, err := s.cache.SetBatch(ctx, data)
+	if err != nil {
+		return fmt.Errorf("保存异常检测状态失败: %w", err)
+	}
+	if result != nil && result.ErrorCount > 0 {
+		return fmt.Errorf("保存异常检测状态失败: %d/%d 条写入错误", result.ErrorCount, result.TotalCount)
+	}
+	return nil
+}
+
+// LoadStates 加载所有交易对的统计状态
+func (s *redisAnomalyStateStore) LoadStates(ctx context.Context) ([]*SymbolStatistics, error) {
+	keys, err := s.cache.GetKeys(ctx, anomalyStateKeyPrefix+":*")
+	if err != nil {
+		return nil, fmt.Errorf("获取异常检测状态键失败: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := s.cache.GetBatch(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("读取异常检测状态失败: %w", err)
+	}
+
+	states := make([]*SymbolStatistics, 0, len(values))
+	for key, value := range values {
+		var state SymbolStatistics
+		if err := json.Unmarshal([]byte(value), &state); err != nil {
+			return nil, fmt.Errorf("反序列化异常检测状态失败 (%s): %w", key, err)
+		}
+		states = append(states, &state)
+	}
+	return states, nil
+}
@@ -0,0 +1,170 @@
+package data_collection
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// feedOscillatingPrices 以给定振幅向检测器输入交替涨跌的价格序列
+func feedOscillatingPrices(t *testing.T, detector AnomalyDetector, symbol string, amplitude float64, count int, start time.Time) (float64, time.Time) {
+	price := 100.0
+	ts := start
+	for i := 0; i < count; i++ {
+		if i%2 == 0 {
+			price *= 1 + amplitude
+		} else {
+			price *= 1 - amplitude
+		}
+		ts = ts.Add(2 * time.Second)
+		require.NoError(t, detector.UpdateHistory(&PriceData{
+			Symbol:    symbol,
+			Price:     decimal.NewFromFloat(price),
+			Volume:    decimal.NewFromFloat(1000),
+			Timestamp: ts,
+			Source:    "test",
+		}))
+	}
+	return price, ts
+}
+
+func TestSymbolStatistics_Update(t *testing.T) {
+	stats := NewSymbolStatistics("BTCUSDT")
+
+	// 第一个价格只记录基准，不产生收益率样本
+	stats.Update(100, 0.1, time.Now())
+	assert.Equal(t, int64(0), stats.Count)
+	assert.Equal(t, 100.0, stats.LastPrice)
+
+	for i := 0; i < 200; i++ {
+		if i%2 == 0 {
+			stats.Update(101, 0.1, time.Now())
+		} else {
+			stats.Update(100, 0.1, time.Now())
+		}
+	}
+
+	assert.Equal(t, int64(200), stats.Count)
+	assert.InDelta(t, 0.0, stats.MeanReturn, 0.002)
+	assert.InDelta(t, 0.01, stats.StdDev(), 0.003)
+	assert.Less(t, stats.Q1, stats.Q3)
+	assert.True(t, stats.IsWarm(20))
+
+	ret, ok := stats.ReturnFrom(110)
+	require.True(t, ok)
+	assert.InDelta(t, 0.1, ret, 1e-9)
+	assert.Greater(t, math.Abs(stats.ZScore(ret)), 5.0)
+}
+
+func TestAnomalyDetector_AdaptiveThreshold(t *testing.T) {
+	rules := DefaultAnomalyRules()
+	rules.PatternAnomaly.Enabled = false
+	rules.VolumeAnomaly.Enabled = false
+	detector := NewAnomalyDetector(rules, zap.NewNop())
+
+	start := time.Now().Add(-time.Hour)
+	btcPrice, btcTime := feedOscillatingPrices(t, detector, "BTCUSDT", 0.001, 100, start)
+	memePrice, memeTime := feedOscillatingPrices(t, detector, "PEPEUSDT", 0.03, 100, start)
+
+	t.Run("低波动交易对的2%变动为异常", func(t *testing.T) {
+		result, err := detector.DetectAnomaly(&PriceData{
+			Symbol:    "BTCUSDT",
+			Price:     decimal.NewFromFloat(btcPrice * 1.02),
+			Volume:    decimal.NewFromFloat(1000),
+			Timestamp: btcTime.Add(2 * time.Second),
+			Source:    "test",
+		})
+		require.NoError(t, err)
+		assert.True(t, result.IsAnomaly)
+		assert.Equal(t, AnomalyTypePriceOutlier, result.AnomalyType)
+		assert.Equal(t, true, result.Metadata["adaptive"])
+	})
+
+	t.Run("高波动交易对的2%变动为正常", func(t *testing.T) {
+		result, err := detector.DetectAnomaly(&PriceData{
+			Symbol:    "PEPEUSDT",
+			Price:     decimal.NewFromFloat(memePrice * 1.02),
+			Volume:    decimal.NewFromFloat(1000),
+			Timestamp: memeTime.Add(2 * time.Second),
+			Source:    "test",
+		})
+		require.NoError(t, err)
+		assert.False(t, result.IsAnomaly, "reasons: %v", result.Reasons)
+	})
+
+	t.Run("超出自适应Z分数阈值的变动为统计异常", func(t *testing.T) {
+		stats, ok := detector.GetSymbolStatistics("PEPEUSDT")
+		require.True(t, ok)
+
+		// 取介于统计阈值与异常值阈值之间的Z分数
+		ret := stats.MeanReturn + 2.75*stats.StdDev()
+		result, err := detector.DetectAnomaly(&PriceData{
+			Symbol:    "PEPEUSDT",
+			Price:     decimal.NewFromFloat(stats.LastPrice * (1 + ret)),
+			Volume:    decimal.NewFromFloat(1000),
+			Timestamp: memeTime.Add(4 * time.Second),
+			Source:    "test",
+		})
+		require.NoError(t, err)
+		assert.True(t, result.IsAnomaly)
+		assert.Equal(t, AnomalyTypeStatistical, result.AnomalyType)
+		assert.Equal(t, true, result.Metadata["adaptive"])
+	})
+
+	t.Run("统计状态查询", func(t *testing.T) {
+		stats, ok := detector.GetSymbolStatistics("BTCUSDT")
+		require.True(t, ok)
+		assert.Equal(t, "BTCUSDT", stats.Symbol)
+		assert.GreaterOrEqual(t, stats.Count, int64(100))
+
+		_, ok = detector.GetSymbolStatistics("UNKNOWN")
+		assert.False(t, ok)
+	})
+}
+
+func TestAnomalyDetector_StateSnapshotRestore(t *testing.T) {
+	server := miniredis.RunT(t)
+	port, err := strconv.Atoi(server.Port())
+	require.NoError(t, err)
+
+	cache := NewRedisCache(NewCacheConfig(server.Host(), port, "", 0), zap.NewNop())
+	defer cache.Close()
+	store := NewRedisAnomalyStateStore(cache, time.Hour)
+	ctx := context.Background()
+
+	detector := NewAnomalyDetectorWithStateStore(DefaultAnomalyRules(), store, zap.NewNop())
+	feedOscillatingPrices(t, detector, "BTCUSDT", 0.001, 50, time.Now().Add(-time.Hour))
+	feedOscillatingPrices(t, detector, "ETHUSDT", 0.002, 50, time.Now().Add(-time.Hour))
+
+	require.NoError(t, detector.SnapshotState(ctx))
+	assert.True(t, server.Exists("anomaly_state:BTCUSDT"))
+	assert.True(t, server.Exists("anomaly_state:ETHUSDT"))
+
+	// 模拟重启：新检测器从Redis恢复状态
+	restored := NewAnomalyDetectorWithStateStore(DefaultAnomalyRules(), store, zap.NewNop())
+	require.NoError(t, restored.RestoreState(ctx))
+
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT"} {
+		original, ok := detector.GetSymbolStatistics(symbol)
+		require.True(t, ok)
+		loaded, ok := restored.GetSymbolStatistics(symbol)
+		require.True(t, ok)
+		assert.Equal(t, original.Count, loaded.Count)
+		assert.InDelta(t, original.Variance, loaded.Variance, 1e-15)
+		assert.InDelta(t, original.LastPrice, loaded.LastPrice, 1e-9)
+	}
+
+	t.Run("未配置状态存储", func(t *testing.T) {
+		plain := NewAnomalyDetector(DefaultAnomalyRules(), zap.NewNop())
+		assert.Error(t, plain.SnapshotState(ctx))
+		assert.Error(t, plain.RestoreState(ctx))
+	})
+}
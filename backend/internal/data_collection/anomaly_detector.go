@@ -1,6 +1,7 @@
 package data_collection
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
@@ -22,6 +23,10 @@ type anomalyDetectorImpl struct {
 	volumeHistory map[string][]float64
 	timeHistory   map[string][]time.Time
 
+	// 自适应阈值的在线统计状态
+	symbolStats map[string]*SymbolStatistics
+	stateStore  AnomalyStateStore
+
 	// 统计信息
 	totalProcessed atomic.Int64
 	anomalyCount   atomic.Int64
@@ -34,6 +39,12 @@ type anomalyDetectorImpl struct {
 
 // NewAnomalyDetector 创建新的异常检测器
 func NewAnomalyDetector(rules *AnomalyRules, logger *zap.Logger) AnomalyDetector {
+	return NewAnomalyDetectorWithStateStore(rules, nil, logger)
+}
+
+// NewAnomalyDetectorWithStateStore 创建带状态存储的异常检测器
+// 状态存储用于在重启前后保存和恢复各交易对的自适应统计状态
+func NewAnomalyDetectorWithStateStore(rules *AnomalyRules, store AnomalyStateStore, logger *zap.Logger) AnomalyDetector {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
@@ -47,6 +58,8 @@ func NewAnomalyDetector(rules *AnomalyRules, logger *zap.Logger) AnomalyDetector
 		priceHistory:   make(map[string][]*PriceData),
 		volumeHistory:  make(map[string][]float64),
 		timeHistory:    make(map[string][]time.Time),
+		symbolStats:    make(map[string]*SymbolStatistics),
+		stateStore:     store,
 		typeCounts:     make(map[string]int64),
 		severityCounts: make(map[string]int64),
 	}
@@ -138,11 +151,19 @@ func (a *anomalyDetectorImpl) UpdateHistory(data *PriceData) error {
 	a.priceHistory[symbol] = append(a.priceHistory[symbol], data)
 
 	// 更新交易量历史
-	a.volumeHistory[symbol] = append(a.volumeHistory[symbol], data.Volume)
+	a.volumeHistory[symbol] = append(a.volumeHistory[symbol], data.Volume.InexactFloat64())
 
 	// 更新时间历史
 	a.timeHistory[symbol] = append(a.timeHistory[symbol], data.Timestamp)
 
+	// 更新自适应统计
+	stats, exists := a.symbolStats[symbol]
+	if !exists {
+		stats = NewSymbolStatistics(symbol)
+		a.symbolStats[symbol] = stats
+	}
+	stats.Update(data.PriceFloat(), a.rules.GlobalSettings.LearningRate, data.Timestamp)
+
 	// 限制历史数据大小
 	maxSize := a.rules.GlobalSettings.HistorySize
 	if len(a.priceHistory[symbol]) > maxSize {
@@ -199,6 +220,7 @@ func (a *anomalyDetectorImpl) Reset() error {
 	a.priceHistory = make(map[string][]*PriceData)
 	a.volumeHistory = make(map[string][]float64)
 	a.timeHistory = make(map[string][]time.Time)
+	a.symbolStats = make(map[string]*SymbolStatistics)
 	a.typeCounts = make(map[string]int64)
 	a.severityCounts = make(map[string]int64)
 
@@ -224,6 +246,63 @@ func (a *anomalyDetectorImpl) SetRules(rules *AnomalyRules) error {
 	return nil
 }
 
+// GetSymbolStatistics 获取交易对的自适应统计状态
+func (a *anomalyDetectorImpl) GetSymbolStatistics(symbol string) (*SymbolStatistics, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	stats, exists := a.symbolStats[symbol]
+	if !exists {
+		return nil, false
+	}
+	return stats.Clone(), true
+}
+
+// SnapshotState 将自适应统计状态快照到状态存储
+func (a *anomalyDetectorImpl) SnapshotState(ctx context.Context) error {
+	if a.stateStore == nil {
+		return fmt.Errorf("未配置异常检测状态存储")
+	}
+
+	a.mu.RLock()
+	states := make([]*SymbolStatistics, 0, len(a.symbolStats))
+	for _, stats := range a.symbolStats {
+		states = append(states, stats.Clone())
+	}
+	a.mu.RUnlock()
+
+	if err := a.stateStore.SaveStates(ctx, states); err != nil {
+		return err
+	}
+
+	a.logger.Debug("异常检测状态快照完成", zap.Int("symbols", len(states)))
+	return nil
+}
+
+// RestoreState 从状态存储恢复自适应统计状态
+func (a *anomalyDetectorImpl) RestoreState(ctx context.Context) error {
+	if a.stateStore == nil {
+		return fmt.Errorf("未配置异常检测状态存储")
+	}
+
+	states, err := a.stateStore.LoadStates(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	for _, state := range states {
+		if state == nil || state.Symbol == "" {
+			continue
+		}
+		a.symbolStats[state.Symbol] = state
+	}
+	a.mu.Unlock()
+
+	a.logger.Info("异常检测状态已恢复", zap.Int("symbols", len(states)))
+	return nil
+}
+
 // detectPriceAnomaly 检测价格异常
 func (a *anomalyDetectorImpl) detectPriceAnomaly(data *PriceData, result *AnomalyResult) {
 	if !a.rules.PriceAnomaly.Enabled {
@@ -239,8 +318,8 @@ func (a *anomalyDetectorImpl) detectPriceAnomaly(data *PriceData, result *Anomal
 	}
 
 	// 计算价格变化率
-	prevPrice := history[len(history)-2].Price
-	priceChange := (data.Price - prevPrice) / prevPrice
+	prevPrice := history[len(history)-2].PriceFloat()
+	priceChange := (data.PriceFloat() - prevPrice) / prevPrice
 
 	// 检查价格尖峰
 	if priceChange > a.rules.PriceAnomaly.PriceSpikeThreshold {
@@ -264,8 +343,27 @@ func (a *anomalyDetectorImpl) detectPriceAnomaly(data *PriceData, result *Anomal
 		return
 	}
 
+	// 自适应模式：按收益率相对于交易对自身波动率判断异常值
+	if a.rules.GlobalSettings.AdaptiveThreshold {
+		if stats, ok := a.warmSymbolStatistics(data.Symbol); ok {
+			if ret, ok := stats.ReturnFrom(data.PriceFloat()); ok {
+				if zScore := stats.ZScore(ret); math.Abs(zScore) > a.rules.PriceAnomaly.OutlierThreshold {
+					result.IsAnomaly = true
+					result.AnomalyType = AnomalyTypePriceOutlier
+					result.Severity = SeverityMedium
+					result.Reasons = append(result.Reasons, fmt.Sprintf("价格异常值: 收益率=%.4f%%, 波动率=%.4f%%", ret*100, stats.StdDev()*100))
+					result.Suggestions = append(result.Suggestions, "验证数据源和价格计算")
+					result.Metadata["is_outlier"] = true
+					result.Metadata["z_score"] = zScore
+					result.Metadata["adaptive"] = true
+				}
+			}
+			return
+		}
+	}
+
 	// 检查价格异常值
-	if a.isPriceOutlier(data.Price, history) {
+	if a.isPriceOutlier(data.PriceFloat(), history) {
 		result.IsAnomaly = true
 		result.AnomalyType = AnomalyTypePriceOutlier
 		result.Severity = SeverityMedium
@@ -351,7 +449,7 @@ func (a *anomalyDetectorImpl) detectVolumeAnomaly(data *PriceData, result *Anoma
 	}
 
 	// 检查零交易量
-	if data.Volume == 0 && !a.rules.VolumeAnomaly.ZeroVolumeAllowed {
+	if data.Volume.IsZero() && !a.rules.VolumeAnomaly.ZeroVolumeAllowed {
 		result.IsAnomaly = true
 		result.AnomalyType = AnomalyTypeZeroVolume
 		result.Severity = SeverityMedium
@@ -364,7 +462,7 @@ func (a *anomalyDetectorImpl) detectVolumeAnomaly(data *PriceData, result *Anoma
 	// 计算交易量变化率
 	prevVolume := volumeHistory[len(volumeHistory)-2]
 	if prevVolume > 0 {
-		volumeChange := (data.Volume - prevVolume) / prevVolume
+		volumeChange := (data.Volume.InexactFloat64() - prevVolume) / prevVolume
 
 		// 检查交易量尖峰
 		if volumeChange > a.rules.VolumeAnomaly.VolumeSpikeThreshold {
@@ -395,6 +493,14 @@ func (a *anomalyDetectorImpl) detectStatisticalAnomaly(data *PriceData, result *
 		return
 	}
 
+	// 自适应模式：按交易对自身的波动区间评估收益率
+	if a.rules.GlobalSettings.AdaptiveThreshold {
+		if stats, ok := a.warmSymbolStatistics(data.Symbol); ok {
+			a.detectAdaptiveStatisticalAnomaly(data, stats, result)
+			return
+		}
+	}
+
 	a.mu.RLock()
 	history := a.priceHistory[data.Symbol]
 	a.mu.RUnlock()
@@ -404,7 +510,7 @@ func (a *anomalyDetectorImpl) detectStatisticalAnomaly(data *PriceData, result *
 	}
 
 	// 计算Z分数
-	zScore := a.calculateZScore(data.Price, history)
+	zScore := a.calculateZScore(data.PriceFloat(), history)
 	if math.Abs(zScore) > a.rules.StatisticalAnomaly.ZScoreThreshold {
 		result.IsAnomaly = true
 		result.AnomalyType = AnomalyTypeStatistical
@@ -416,7 +522,7 @@ func (a *anomalyDetectorImpl) detectStatisticalAnomaly(data *PriceData, result *
 	}
 
 	// 计算IQR异常
-	if a.isIQRAnomaly(data.Price, history) {
+	if a.isIQRAnomaly(data.PriceFloat(), history) {
 		result.IsAnomaly = true
 		result.AnomalyType = AnomalyTypeStatistical
 		result.Severity = SeverityMedium
@@ -427,6 +533,58 @@ func (a *anomalyDetectorImpl) detectStatisticalAnomaly(data *PriceData, result *
 	}
 }
 
+// detectAdaptiveStatisticalAnomaly 基于交易对在线统计检测收益率异常
+// 阈值随交易对的波动率伸缩：同样2%的变动，对高波动币种可能正常，对BTC则是显著异常
+func (a *anomalyDetectorImpl) detectAdaptiveStatisticalAnomaly(data *PriceData, stats *SymbolStatistics, result *AnomalyResult) {
+	ret, ok := stats.ReturnFrom(data.PriceFloat())
+	if !ok {
+		return
+	}
+
+	zScore := stats.ZScore(ret)
+	if math.Abs(zScore) > a.rules.StatisticalAnomaly.ZScoreThreshold {
+		result.IsAnomaly = true
+		result.AnomalyType = AnomalyTypeStatistical
+		result.Severity = SeverityMedium
+		if math.Abs(zScore) > 2*a.rules.StatisticalAnomaly.ZScoreThreshold {
+			result.Severity = SeverityHigh
+		}
+		result.Reasons = append(result.Reasons, fmt.Sprintf("自适应统计异常: 收益率=%.4f%%, Z分数=%.2f", ret*100, zScore))
+		result.Suggestions = append(result.Suggestions, "收益率超出该交易对当前波动区间，关注是否有行情异动")
+		result.Metadata["z_score"] = zScore
+		result.Metadata["return"] = ret
+		result.Metadata["volatility"] = stats.StdDev()
+		result.Metadata["adaptive"] = true
+		return
+	}
+
+	lower, upper := stats.IQRBounds(a.rules.StatisticalAnomaly.IQRMultiplier)
+	if upper > lower && (ret < lower || ret > upper) {
+		result.IsAnomaly = true
+		result.AnomalyType = AnomalyTypeStatistical
+		result.Severity = SeverityMedium
+		result.Reasons = append(result.Reasons, fmt.Sprintf("自适应IQR异常: 收益率=%.4f%%", ret*100))
+		result.Suggestions = append(result.Suggestions, "检查收益率分布是否发生变化")
+		result.Metadata["iqr_anomaly"] = true
+		result.Metadata["return"] = ret
+		result.Metadata["iqr_lower"] = lower
+		result.Metadata["iqr_upper"] = upper
+		result.Metadata["adaptive"] = true
+	}
+}
+
+// warmSymbolStatistics 获取已完成预热的交易对统计状态
+func (a *anomalyDetectorImpl) warmSymbolStatistics(symbol string) (*SymbolStatistics, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	stats, exists := a.symbolStats[symbol]
+	if !exists || !stats.IsWarm(a.rules.StatisticalAnomaly.MovingAverageWindow) {
+		return nil, false
+	}
+	return stats.Clone(), true
+}
+
 // detectPatternAnomaly 检测模式异常
 func (a *anomalyDetectorImpl) detectPatternAnomaly(data *PriceData, result *AnomalyResult) {
 	if !a.rules.PatternAnomaly.Enabled {
@@ -442,7 +600,7 @@ func (a *anomalyDetectorImpl) detectPatternAnomaly(data *PriceData, result *Anom
 	}
 
 	// 检测趋势变化
-	if a.detectTrendChange(history, data.Price) {
+	if a.detectTrendChange(history, data.PriceFloat()) {
 		result.IsAnomaly = true
 		result.AnomalyType = AnomalyTypeTrend
 		result.Severity = SeverityMedium
@@ -453,7 +611,7 @@ func (a *anomalyDetectorImpl) detectPatternAnomaly(data *PriceData, result *Anom
 	}
 
 	// 检测周期性模式
-	if a.detectCyclicalPattern(history, data.Price) {
+	if a.detectCyclicalPattern(history, data.PriceFloat()) {
 		result.IsAnomaly = true
 		result.AnomalyType = AnomalyTypeCyclical
 		result.Severity = SeverityLow
@@ -509,13 +667,13 @@ func (a *anomalyDetectorImpl) isPriceOutlier(price float64, history []*PriceData
 	// 计算均值和标准差
 	var sum float64
 	for _, h := range history {
-		sum += h.Price
+		sum += h.PriceFloat()
 	}
 	mean := sum / float64(len(history))
 
 	var variance float64
 	for _, h := range history {
-		variance += math.Pow(h.Price-mean, 2)
+		variance += math.Pow(h.PriceFloat()-mean, 2)
 	}
 	stdDev := math.Sqrt(variance / float64(len(history)))
 
@@ -533,13 +691,13 @@ func (a *anomalyDetectorImpl) calculateZScore(price float64, history []*PriceDat
 	// 计算均值和标准差
 	var sum float64
 	for _, h := range history {
-		sum += h.Price
+		sum += h.PriceFloat()
 	}
 	mean := sum / float64(len(history))
 
 	var variance float64
 	for _, h := range history {
-		variance += math.Pow(h.Price-mean, 2)
+		variance += math.Pow(h.PriceFloat()-mean, 2)
 	}
 	stdDev := math.Sqrt(variance / float64(len(history)))
 
@@ -559,7 +717,7 @@ func (a *anomalyDetectorImpl) isIQRAnomaly(price float64, history []*PriceData)
 	// 提取价格数据
 	prices := make([]float64, len(history))
 	for i, h := range history {
-		prices[i] = h.Price
+		prices[i] = h.PriceFloat()
 	}
 	prices = append(prices, price)
 
@@ -594,7 +752,7 @@ func (a *anomalyDetectorImpl) detectTrendChange(history []*PriceData, currentPri
 
 	var sum float64
 	for i := len(history) - window; i < len(history); i++ {
-		sum += history[i].Price
+		sum += history[i].PriceFloat()
 	}
 	avg := sum / float64(window)
 
@@ -622,7 +780,7 @@ func (a *anomalyDetectorImpl) detectCyclicalPattern(history []*PriceData, curren
 
 	var sum float64
 	for i := len(history) - cycleLength; i < len(history); i++ {
-		sum += history[i].Price
+		sum += history[i].PriceFloat()
 	}
 	expectedPrice := sum / float64(cycleLength)
 
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -21,9 +22,9 @@ func TestAnomalyDetector_Simple(t *testing.T) {
 		// 正常数据
 		normalData := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     100.0,
+			Price:     decimal.NewFromFloat(100.0),
 			Timestamp: time.Now().Add(-5 * time.Minute),
-			Volume:    1000.0,
+			Volume:    decimal.NewFromFloat(1000.0),
 			Source:    "test",
 		}
 
@@ -38,9 +39,9 @@ func TestAnomalyDetector_Simple(t *testing.T) {
 		// 添加历史数据
 		historyData := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     100.0,
+			Price:     decimal.NewFromFloat(100.0),
 			Timestamp: time.Now().Add(-10 * time.Minute),
-			Volume:    1000.0,
+			Volume:    decimal.NewFromFloat(1000.0),
 			Source:    "test",
 		}
 		detector.UpdateHistory(historyData)
@@ -48,9 +49,9 @@ func TestAnomalyDetector_Simple(t *testing.T) {
 		// 价格尖峰
 		spikeData := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     150.0, // 50%上涨
+			Price:     decimal.NewFromFloat(150.0), // 50%上涨
 			Timestamp: time.Now().Add(-9 * time.Minute),
-			Volume:    1000.0,
+			Volume:    decimal.NewFromFloat(1000.0),
 			Source:    "test",
 		}
 
@@ -64,9 +65,9 @@ func TestAnomalyDetector_Simple(t *testing.T) {
 	t.Run("未来时间检测", func(t *testing.T) {
 		futureData := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     100.0,
+			Price:     decimal.NewFromFloat(100.0),
 			Timestamp: time.Now().Add(2 * time.Minute), // 未来时间
-			Volume:    1000.0,
+			Volume:    decimal.NewFromFloat(1000.0),
 			Source:    "test",
 		}
 
@@ -81,18 +82,18 @@ func TestAnomalyDetector_Simple(t *testing.T) {
 		// 先添加历史数据
 		historyData := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     100.0,
+			Price:     decimal.NewFromFloat(100.0),
 			Timestamp: time.Now().Add(-5 * time.Minute),
-			Volume:    1000.0,
+			Volume:    decimal.NewFromFloat(1000.0),
 			Source:    "test",
 		}
 		detector.UpdateHistory(historyData)
 
 		zeroVolumeData := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     100.0,
+			Price:     decimal.NewFromFloat(100.0),
 			Timestamp: time.Now().Add(-4 * time.Minute),
-			Volume:    0.0, // 零交易量
+			Volume:    decimal.Zero, // 零交易量
 			Source:    "test",
 		}
 
@@ -135,9 +136,9 @@ func TestAnomalyDetector_Batch(t *testing.T) {
 	// 先添加历史数据
 	historyData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     basePrice,
+		Price:     decimal.NewFromFloat(basePrice),
 		Timestamp: baseTime,
-		Volume:    1000.0,
+		Volume:    decimal.NewFromFloat(1000.0),
 		Source:    "test",
 	}
 	detector.UpdateHistory(historyData)
@@ -145,23 +146,23 @@ func TestAnomalyDetector_Batch(t *testing.T) {
 	data := []*PriceData{
 		{
 			Symbol:    "BTCUSDT",
-			Price:     basePrice + 0.1, // 正常价格
+			Price:     decimal.NewFromFloat(basePrice + 0.1), // 正常价格
 			Timestamp: baseTime.Add(1 * time.Minute),
-			Volume:    1000.0,
+			Volume:    decimal.NewFromFloat(1000.0),
 			Source:    "test",
 		},
 		{
 			Symbol:    "BTCUSDT",
-			Price:     basePrice + 50.0, // 异常价格
+			Price:     decimal.NewFromFloat(basePrice + 50.0), // 异常价格
 			Timestamp: baseTime.Add(2 * time.Minute),
-			Volume:    1000.0,
+			Volume:    decimal.NewFromFloat(1000.0),
 			Source:    "test",
 		},
 		{
 			Symbol:    "BTCUSDT",
-			Price:     basePrice + 0.2, // 正常价格
+			Price:     decimal.NewFromFloat(basePrice + 0.2), // 正常价格
 			Timestamp: baseTime.Add(3 * time.Minute),
-			Volume:    1000.0,
+			Volume:    decimal.NewFromFloat(1000.0),
 			Source:    "test",
 		},
 	}
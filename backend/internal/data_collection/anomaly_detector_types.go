@@ -1,6 +1,7 @@
 package data_collection
 
 import (
+	"context"
 	"time"
 )
 
@@ -23,6 +24,15 @@ type AnomalyDetector interface {
 
 	// 设置检测规则
 	SetRules(rules *AnomalyRules) error
+
+	// 获取交易对的自适应统计状态
+	GetSymbolStatistics(symbol string) (*SymbolStatistics, bool)
+
+	// 将自适应统计状态快照到状态存储
+	SnapshotState(ctx context.Context) error
+
+	// 从状态存储恢复自适应统计状态
+	RestoreState(ctx context.Context) error
 }
 
 // AnomalyResult 异常检测结果
@@ -116,6 +126,36 @@ type GlobalAnomalySettings struct {
 	AdaptiveThreshold bool    `json:"adaptive_threshold" yaml:"adaptive_threshold"` // 自适应阈值
 }
 
+// SymbolStatistics 单个交易对的在线统计状态（用于自适应阈值）
+// 统计对象为相邻价格之间的收益率，均值/方差采用EWMA，四分位数采用随机逼近在线估计，
+// 衰减系数由 GlobalAnomalySettings.LearningRate 决定
+type SymbolStatistics struct {
+	Symbol     string    `json:"symbol"`
+	Count      int64     `json:"count"`       // 已学习的收益率样本数
+	LastPrice  float64   `json:"last_price"`  // 最近一次价格
+	MeanReturn float64   `json:"mean_return"` // 收益率EWMA均值
+	Variance   float64   `json:"variance"`    // 收益率EWMA方差
+	Q1         float64   `json:"q1"`          // 收益率下四分位数估计
+	Q3         float64   `json:"q3"`          // 收益率上四分位数估计
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AnomalyStateStore 异常检测状态存储接口
+type AnomalyStateStore interface {
+	// 保存所有交易对的统计状态
+	SaveStates(ctx context.Context, states []*SymbolStatistics) error
+
+	// 加载所有交易对的统计状态
+	LoadStates(ctx context.Context) ([]*SymbolStatistics, error)
+}
+
+// AnomalyStateKV 基于Redis的异常检测状态存储使用的键值操作，RedisCache 满足该接口
+type AnomalyStateKV interface {
+	SetBatch(ctx context.Context, data []CacheData) (*CacheBatchResult, error)
+	GetBatch(ctx context.Context, keys []string) (map[string]string, error)
+	GetKeys(ctx context.Context, pattern string) ([]string, error)
+}
+
 // AnomalyStats 异常检测统计
 type AnomalyStats struct {
 	TotalProcessed       int64            `json:"total_processed"`
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -40,7 +41,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 		validItem := &PersistenceItem{
 			ID:        "valid_test",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -58,7 +59,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:        "",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -67,7 +68,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:        "no_type",
 				Type:      "",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -85,7 +86,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:        "invalid_timestamp",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: time.Time{},
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -94,7 +95,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:        "future_timestamp",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: time.Now().Add(2 * time.Hour),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -103,7 +104,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:        "past_timestamp",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: time.Now().Add(-25 * time.Hour),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -112,7 +113,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:        "invalid_priority",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: time.Now(),
 				Priority:  -1,
 				CreatedAt: time.Now(),
@@ -121,7 +122,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:         "invalid_retry",
 				Type:       "price",
-				Data:       &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:       &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp:  time.Now(),
 				Priority:   1,
 				RetryCount: 15,
@@ -157,7 +158,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:        "consistency_1",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: now.Add(1 * time.Second),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -165,7 +166,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			{
 				ID:        "consistency_2",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50001.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50001.0)},
 				Timestamp: now,
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -185,7 +186,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 		duplicateItem := &PersistenceItem{
 			ID:        "consistency_1", // 重复ID
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50002.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50002.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -214,7 +215,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 			batchItems[i] = &PersistenceItem{
 				ID:        fmt.Sprintf("batch_%d", i),
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i)},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i))},
 				Timestamp: time.Now().Add(time.Duration(i) * time.Second),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -251,7 +252,7 @@ func TestAsyncPersistence_IntegrityCheck(t *testing.T) {
 		invalidItem := &PersistenceItem{
 			ID:        "", // 无效ID
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -280,7 +281,7 @@ func TestAsyncPersistence_IntegrityChecker(t *testing.T) {
 		validItem := &PersistenceItem{
 			ID:        "test",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -47,7 +48,7 @@ func TestAsyncPersistence_Performance(t *testing.T) {
 			item := &PersistenceItem{
 				ID:        fmt.Sprintf("perf_single_%d", i),
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i)},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i))},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -94,7 +95,7 @@ func TestAsyncPersistence_Performance(t *testing.T) {
 					item := &PersistenceItem{
 						ID:        fmt.Sprintf("perf_concurrent_%d_%d", goroutineID, j),
 						Type:      "price",
-						Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(goroutineID*100+j)},
+						Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(goroutineID*100+j))},
 						Timestamp: time.Now(),
 						Priority:  1,
 						CreatedAt: time.Now(),
@@ -142,7 +143,7 @@ func TestAsyncPersistence_Performance(t *testing.T) {
 				items[j] = &PersistenceItem{
 					ID:        fmt.Sprintf("perf_batch_%d_%d", i, j),
 					Type:      "price",
-					Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i*batchSize+j)},
+					Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i*batchSize+j))},
 					Timestamp: time.Now(),
 					Priority:  1,
 					CreatedAt: time.Now(),
@@ -185,7 +186,7 @@ func TestAsyncPersistence_Performance(t *testing.T) {
 			item := &PersistenceItem{
 				ID:        fmt.Sprintf("perf_mixed_%d", i),
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i)},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i))},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -200,7 +201,7 @@ func TestAsyncPersistence_Performance(t *testing.T) {
 					batchItems[j] = &PersistenceItem{
 						ID:        fmt.Sprintf("perf_mixed_batch_%d_%d", i, j),
 						Type:      "price",
-						Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i*5+j)},
+						Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i*5+j))},
 						Timestamp: time.Now(),
 						Priority:  1,
 						CreatedAt: time.Now(),
@@ -264,7 +265,7 @@ func TestAsyncPersistence_MemoryUsage(t *testing.T) {
 			item := &PersistenceItem{
 				ID:        fmt.Sprintf("memory_%d", i),
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i)},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i))},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -345,7 +346,7 @@ func TestAsyncPersistence_StressTest(t *testing.T) {
 					item := &PersistenceItem{
 						ID:        fmt.Sprintf("stress_%d_%d", goroutineID, j),
 						Type:      "price",
-						Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(goroutineID*100+j)},
+						Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(goroutineID*100+j))},
 						Timestamp: time.Now(),
 						Priority:  1,
 						CreatedAt: time.Now(),
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -43,7 +44,7 @@ func TestAsyncPersistence_RetryMechanism(t *testing.T) {
 		item := &PersistenceItem{
 			ID:        "retry_test",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -98,7 +99,7 @@ func TestAsyncPersistence_RetryMechanism(t *testing.T) {
 			item := &PersistenceItem{
 				ID:        "test",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -120,7 +121,7 @@ func TestAsyncPersistence_RetryMechanism(t *testing.T) {
 			item := &PersistenceItem{
 				ID:        "test",
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -139,7 +140,7 @@ func TestAsyncPersistence_RetryMechanism(t *testing.T) {
 		item := &PersistenceItem{
 			ID:        "test",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -160,7 +161,7 @@ func TestAsyncPersistence_RetryMechanism(t *testing.T) {
 		item := &PersistenceItem{
 			ID:        "test",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -212,7 +213,7 @@ func TestAsyncPersistence_RetryIntegration(t *testing.T) {
 			item := &PersistenceItem{
 				ID:        fmt.Sprintf("retry_integration_%d", i),
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i)},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i))},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -47,7 +48,7 @@ func TestAsyncPersistence_BasicOperations(t *testing.T) {
 		item := &PersistenceItem{
 			ID:        "test_1",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			Metadata:  map[string]interface{}{"source": "test"},
@@ -77,7 +78,7 @@ func TestAsyncPersistence_BasicOperations(t *testing.T) {
 			items[i] = &PersistenceItem{
 				ID:        fmt.Sprintf("batch_%d", i),
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i)},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i))},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -109,7 +110,7 @@ func TestAsyncPersistence_BasicOperations(t *testing.T) {
 		item := &PersistenceItem{
 			ID:        "queue_test",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -133,7 +134,7 @@ func TestAsyncPersistence_BasicOperations(t *testing.T) {
 		item := &PersistenceItem{
 			ID:        "flush_test",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -184,7 +185,7 @@ func TestAsyncPersistence_ConcurrentOperations(t *testing.T) {
 					item := &PersistenceItem{
 						ID:        fmt.Sprintf("concurrent_%d_%d", goroutineID, j),
 						Type:      "price",
-						Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(goroutineID*100+j)},
+						Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(goroutineID*100+j))},
 						Timestamp: time.Now(),
 						Priority:  1,
 						CreatedAt: time.Now(),
@@ -239,7 +240,7 @@ func TestAsyncPersistence_ConcurrentOperations(t *testing.T) {
 					items[j] = &PersistenceItem{
 						ID:        fmt.Sprintf("batch_%d_%d", goroutineID, j),
 						Type:      "price",
-						Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(goroutineID*100+j)},
+						Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(goroutineID*100+j))},
 						Timestamp: time.Now(),
 						Priority:  1,
 						CreatedAt: time.Now(),
@@ -297,7 +298,7 @@ func TestAsyncPersistence_Stats(t *testing.T) {
 			item := &PersistenceItem{
 				ID:        fmt.Sprintf("stats_%d", i),
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i)},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i))},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -327,7 +328,7 @@ func TestAsyncPersistence_Stats(t *testing.T) {
 		item := &PersistenceItem{
 			ID:        "reset_stats",
 			Type:      "price",
-			Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0},
+			Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0)},
 			Timestamp: time.Now(),
 			Priority:  1,
 			CreatedAt: time.Now(),
@@ -402,7 +403,7 @@ func TestAsyncPersistence_QueueOverflow(t *testing.T) {
 			item := &PersistenceItem{
 				ID:        fmt.Sprintf("overflow_%d", i),
 				Type:      "price",
-				Data:      &PriceData{Symbol: "BTCUSDT", Price: 50000.0 + float64(i)},
+				Data:      &PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0 + float64(i))},
 				Timestamp: time.Now(),
 				Priority:  1,
 				CreatedAt: time.Now(),
@@ -7,10 +7,13 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
 )
 
 // AuthConfig 认证配置
@@ -61,6 +64,28 @@ type authManagerImpl struct {
 	logger *zap.Logger
 }
 
+// NewAuthManagerFromConfig 按配置文件的认证配置创建认证管理器，认证未启用时返回空
+// 服务端校验 Token 和命令行签发 Token 使用同一份配置
+func NewAuthManagerFromConfig(cfg *config.AuthConfig, logger *zap.Logger) (AuthManager, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.SecretKey == "" {
+		return nil, fmt.Errorf("启用认证时必须配置 auth.secret_key")
+	}
+
+	authConfig := DefaultAuthConfig()
+	authConfig.Enabled = true
+	authConfig.SecretKey = cfg.SecretKey
+	if cfg.TokenExpiry > 0 {
+		authConfig.TokenExpiry = cfg.TokenExpiry
+	}
+	if cfg.Issuer != "" {
+		authConfig.Issuer = cfg.Issuer
+	}
+	return NewAuthManager(authConfig, logger), nil
+}
+
 // NewAuthManager 创建认证管理器
 func NewAuthManager(config *AuthConfig, logger *zap.Logger) AuthManager {
 	if logger == nil {
@@ -91,12 +116,12 @@ func (am *authManagerImpl) GenerateToken(userID string, permissions []string) (s
 		Issuer:      am.config.Issuer,
 	}
 
-	// 简单的HMAC签名实现
+	// 简单的HMAC签名实现，时间戳精确到纳秒，保证刷新后的Token与原Token不同
 	tokenData := fmt.Sprintf("%s:%s:%d:%d:%s",
 		claims.UserID,
 		strings.Join(claims.Permissions, ","),
-		claims.IssuedAt.Unix(),
-		claims.ExpiresAt.Unix(),
+		claims.IssuedAt.UnixNano(),
+		claims.ExpiresAt.UnixNano(),
 		claims.Issuer,
 	)
 
@@ -125,13 +150,14 @@ func (am *authManagerImpl) ValidateToken(token string) (*TokenClaims, error) {
 		return nil, fmt.Errorf("Token解码失败: %w", err)
 	}
 
+	// Token数据本身包含冒号，签名是最后一个冒号之后的部分
 	tokenStr := string(tokenBytes)
-	parts := strings.Split(tokenStr, ":")
-	if len(parts) != 2 {
+	sep := strings.LastIndex(tokenStr, ":")
+	if sep < 0 {
 		return nil, fmt.Errorf("Token格式无效")
 	}
 
-	tokenData, signature := parts[0], parts[1]
+	tokenData, signature := tokenStr[:sep], tokenStr[sep+1:]
 
 	// 验证签名
 	if !am.verifySignature(tokenData, signature) {
@@ -195,16 +221,23 @@ func (am *authManagerImpl) verifySignature(data, signature string) bool {
 // parseTokenData 解析Token数据
 func (am *authManagerImpl) parseTokenData(data string) (*TokenClaims, error) {
 	parts := strings.Split(data, ":")
-	if len(parts) < 2 {
+	if len(parts) < 4 {
 		return nil, fmt.Errorf("Token数据格式无效")
 	}
 
 	userID := parts[0]
 	permissionsStr := parts[1]
 
-	// 简化处理，使用当前时间
-	issuedAt := time.Now()
-	expiresAt := time.Now().Add(24 * time.Hour)
+	issuedAtNano, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("签发时间无效: %w", err)
+	}
+	expiresAtNano, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("过期时间无效: %w", err)
+	}
+	issuedAt := time.Unix(0, issuedAtNano)
+	expiresAt := time.Unix(0, expiresAtNano)
 
 	permissions := []string{}
 	if permissionsStr != "" {
@@ -231,22 +264,22 @@ func AuthMiddleware(authManager AuthManager, requiredPermission string) func(htt
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// 获取Authorization头
 			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				http.Error(w, "未提供认证信息", http.StatusUnauthorized)
-				return
-			}
 
 			// 检查Bearer格式
-			if !strings.HasPrefix(authHeader, "Bearer ") {
+			if authHeader != "" && !strings.HasPrefix(authHeader, "Bearer ") {
 				http.Error(w, "认证格式无效", http.StatusUnauthorized)
 				return
 			}
 
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 
-			// 验证Token
+			// 验证Token，认证未启用时不需要认证头
 			claims, err := authManager.ValidateToken(token)
 			if err != nil {
+				if authHeader == "" {
+					http.Error(w, "未提供认证信息", http.StatusUnauthorized)
+					return
+				}
 				http.Error(w, fmt.Sprintf("Token验证失败: %v", err), http.StatusUnauthorized)
 				return
 			}
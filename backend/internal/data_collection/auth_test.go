@@ -9,6 +9,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/config"
 )
 
 func TestAuthManager_BasicOperations(t *testing.T) {
@@ -119,6 +121,40 @@ func TestAuthManager_BasicOperations(t *testing.T) {
 	})
 }
 
+func TestNewAuthManagerFromConfig(t *testing.T) {
+	t.Run("未启用认证", func(t *testing.T) {
+		authManager, err := NewAuthManagerFromConfig(&config.AuthConfig{}, zap.NewNop())
+		require.NoError(t, err)
+		assert.Nil(t, authManager)
+	})
+
+	t.Run("缺少密钥", func(t *testing.T) {
+		_, err := NewAuthManagerFromConfig(&config.AuthConfig{Enabled: true}, zap.NewNop())
+		assert.Error(t, err)
+	})
+
+	t.Run("签发的Token可以被同一配置校验", func(t *testing.T) {
+		cfg := &config.AuthConfig{Enabled: true, SecretKey: "test-secret-key", Issuer: "test-issuer"}
+		issuer, err := NewAuthManagerFromConfig(cfg, zap.NewNop())
+		require.NoError(t, err)
+		token, err := issuer.GenerateToken("exporter", []string{"read"})
+		require.NoError(t, err)
+
+		verifier, err := NewAuthManagerFromConfig(cfg, zap.NewNop())
+		require.NoError(t, err)
+		claims, err := verifier.ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "test-issuer", claims.Issuer)
+
+		allowed, err := verifier.CheckPermission(token, "read")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		allowed, err = verifier.CheckPermission(token, "admin")
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+}
+
 func TestAuthManager_ErrorCases(t *testing.T) {
 	t.Run("认证未启用", func(t *testing.T) {
 		config := DefaultAuthConfig()
@@ -0,0 +1,431 @@
+package data_collection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// candleInterval 解析后的聚合周期
+type candleInterval struct {
+	name     string
+	duration time.Duration
+}
+
+// candleSeries 单个交易对单个周期的K线序列
+type candleSeries struct {
+	open        []*Candle // 未收盘K线，按开盘时间升序（水位内可能同时存在多根）
+	closedUntil time.Time // 早于该时间的K线已收盘，落在其中的数据丢弃
+	started     bool      // 是否已创建过K线
+}
+
+// candleSymbolState 单个交易对的聚合状态
+type candleSymbolState struct {
+	maxTimestamp      time.Time       // 最新数据时间（事件时间水位）
+	lastBaseVolume24h decimal.Decimal // 最近一次24小时交易币成交量
+	lastUsdtVolume24h decimal.Decimal // 最近一次24小时USDT成交额
+	series            map[string]*candleSeries
+}
+
+// candleAggregatorImpl K线聚合器实现
+type candleAggregatorImpl struct {
+	config      *CandleAggregatorConfig
+	intervals   []candleInterval
+	persist     map[string]bool
+	persistence AsyncPersistence // 为空时收盘K线不落库
+	handler     CandleHandler    // 为空时不输出
+	logger      *zap.Logger
+
+	mu      sync.Mutex
+	symbols map[string]*candleSymbolState
+
+	processedCount atomic.Int64
+	lateCount      atomic.Int64
+	closedCount    atomic.Int64
+
+	running atomic.Bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewCandleAggregator 创建K线聚合器，收盘的 PersistIntervals 周期K线提交到 persistence 落库
+func NewCandleAggregator(config *CandleAggregatorConfig, persistence AsyncPersistence, handler CandleHandler, logger *zap.Logger) (CandleAggregator, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if config == nil {
+		config = DefaultCandleAggregatorConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	intervals := make([]candleInterval, 0, len(config.Intervals))
+	for _, name := range config.Intervals {
+		duration, err := models.ParseTimeWindow(name)
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, candleInterval{name: name, duration: duration})
+	}
+
+	persist := make(map[string]bool, len(config.PersistIntervals))
+	for _, name := range config.PersistIntervals {
+		persist[name] = true
+	}
+
+	return &candleAggregatorImpl{
+		config:      config,
+		intervals:   intervals,
+		persist:     persist,
+		persistence: persistence,
+		handler:     handler,
+		logger:      logger,
+		symbols:     make(map[string]*candleSymbolState),
+	}, nil
+}
+
+// Process 处理单个价格数据
+func (a *candleAggregatorImpl) Process(data *PriceData) error {
+	if data == nil {
+		return fmt.Errorf("数据不能为空")
+	}
+	if data.Symbol == "" {
+		return fmt.Errorf("交易对不能为空")
+	}
+	if !data.Price.IsPositive() {
+		return fmt.Errorf("价格必须大于0")
+	}
+	if data.Timestamp.IsZero() {
+		return fmt.Errorf("时间戳不能为空")
+	}
+
+	a.mu.Lock()
+	state, exists := a.symbols[data.Symbol]
+	if !exists {
+		state = &candleSymbolState{series: make(map[string]*candleSeries, len(a.intervals))}
+		a.symbols[data.Symbol] = state
+	}
+
+	// 只有按时间顺序到达的数据才能推导区间成交量，乱序数据只更新价格
+	var baseVolume, usdtVolume decimal.Decimal
+	if !data.Timestamp.Before(state.maxTimestamp) {
+		baseVolume = volumeDelta(state.lastBaseVolume24h, data.BaseVolume24h)
+		usdtVolume = volumeDelta(state.lastUsdtVolume24h, data.UsdtVolume24h)
+		if data.BaseVolume24h.IsPositive() {
+			state.lastBaseVolume24h = data.BaseVolume24h
+		}
+		if data.UsdtVolume24h.IsPositive() {
+			state.lastUsdtVolume24h = data.UsdtVolume24h
+		}
+		state.maxTimestamp = data.Timestamp
+	}
+
+	var updated, closed []*Candle
+	for _, interval := range a.intervals {
+		series, ok := state.series[interval.name]
+		if !ok {
+			series = &candleSeries{}
+			state.series[interval.name] = series
+		}
+
+		// 所属K线已收盘，或已落后事件时间水位（交易对在该周期内没有其他数据）
+		openTime := data.Timestamp.Truncate(interval.duration)
+		closeAt := openTime.Add(interval.duration + a.config.Watermark)
+		if openTime.Before(series.closedUntil) || !closeAt.After(state.maxTimestamp) {
+			a.lateCount.Add(1)
+			continue
+		}
+
+		candle := series.candle(data.Symbol, interval, openTime, data.Timestamp)
+		candle.apply(data.Price, data.Timestamp, baseVolume, usdtVolume)
+		snapshot := *candle
+		updated = append(updated, &snapshot)
+
+		// 事件时间超过水位的K线直接收盘，不必等待定时检查
+		closed = append(closed, a.closeSeries(series, state.maxTimestamp)...)
+	}
+	a.mu.Unlock()
+
+	a.processedCount.Add(1)
+	a.emit(updated, closed)
+	return nil
+}
+
+// Advance 按当前时间收盘已超过水位的K线
+func (a *candleAggregatorImpl) Advance(now time.Time) {
+	a.mu.Lock()
+	var closed []*Candle
+	for _, state := range a.symbols {
+		watermark := now
+		if state.maxTimestamp.After(watermark) {
+			watermark = state.maxTimestamp
+		}
+		for _, interval := range a.intervals {
+			if series, ok := state.series[interval.name]; ok {
+				closed = append(closed, a.closeSeries(series, watermark)...)
+			}
+		}
+	}
+	a.mu.Unlock()
+
+	a.emit(nil, closed)
+}
+
+// Flush 收盘所有未收盘的K线
+// 结束时间晚于当前时间的K线只包含部分周期的数据，标记为不完整，推送但不落库，
+// 避免重启后同一周期的K线被不完整的数据占用
+func (a *candleAggregatorImpl) Flush() {
+	now := time.Now()
+
+	a.mu.Lock()
+	var closed []*Candle
+	for _, state := range a.symbols {
+		for _, interval := range a.intervals {
+			series, ok := state.series[interval.name]
+			if !ok || len(series.open) == 0 {
+				continue
+			}
+			for _, candle := range series.open {
+				if candle.CloseTime.After(now) {
+					candle.Partial = true
+				}
+			}
+			last := series.open[len(series.open)-1]
+			closed = append(closed, a.closeSeries(series, last.CloseTime.Add(a.config.Watermark))...)
+		}
+	}
+	a.mu.Unlock()
+
+	a.emit(nil, closed)
+}
+
+// GetCandle 获取交易对指定周期最新的未收盘K线
+func (a *candleAggregatorImpl) GetCandle(symbol, interval string) (*Candle, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.symbols[symbol]
+	if !ok {
+		return nil, false
+	}
+	series, ok := state.series[interval]
+	if !ok || len(series.open) == 0 {
+		return nil, false
+	}
+	snapshot := *series.open[len(series.open)-1]
+	return &snapshot, true
+}
+
+// GetStats 获取聚合统计
+func (a *candleAggregatorImpl) GetStats() *CandleAggregatorStats {
+	a.mu.Lock()
+	openCandles := 0
+	for _, state := range a.symbols {
+		for _, series := range state.series {
+			openCandles += len(series.open)
+		}
+	}
+	symbols := len(a.symbols)
+	a.mu.Unlock()
+
+	return &CandleAggregatorStats{
+		ProcessedCount: a.processedCount.Load(),
+		LateCount:      a.lateCount.Load(),
+		ClosedCount:    a.closedCount.Load(),
+		OpenCandles:    openCandles,
+		Symbols:        symbols,
+	}
+}
+
+// Start 启动定时收盘协程
+func (a *candleAggregatorImpl) Start(ctx context.Context) error {
+	if a.running.Load() {
+		return fmt.Errorf("K线聚合器已在运行中")
+	}
+	a.running.Store(true)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	a.cancel = cancel
+	a.wg.Add(1)
+	go a.advanceWorker(runCtx)
+
+	a.logger.Info("K线聚合器启动",
+		zap.Strings("intervals", a.config.Intervals),
+		zap.Duration("watermark", a.config.Watermark),
+	)
+	return nil
+}
+
+// Stop 停止定时收盘协程，并收盘所有K线使其落库
+func (a *candleAggregatorImpl) Stop(ctx context.Context) error {
+	if !a.running.Load() {
+		return fmt.Errorf("K线聚合器未运行")
+	}
+	a.running.Store(false)
+	a.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("停止K线聚合器超时: %w", ctx.Err())
+	}
+
+	a.Flush()
+	a.logger.Info("K线聚合器已停止")
+	return nil
+}
+
+// IsRunning 定时收盘协程是否在运行
+func (a *candleAggregatorImpl) IsRunning() bool {
+	return a.running.Load()
+}
+
+// advanceWorker 定时收盘协程
+func (a *candleAggregatorImpl) advanceWorker(ctx context.Context) {
+	defer a.wg.Done()
+	ticker := time.NewTicker(a.config.AdvanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.Advance(now)
+		}
+	}
+}
+
+// closeSeries 收盘 结束时间+水位 不晚于 watermark 的K线，返回收盘K线的副本，调用方持有锁
+func (a *candleAggregatorImpl) closeSeries(series *candleSeries, watermark time.Time) []*Candle {
+	var closed []*Candle
+	remaining := series.open[:0]
+	for _, candle := range series.open {
+		if candle.CloseTime.Add(a.config.Watermark).After(watermark) {
+			remaining = append(remaining, candle)
+			continue
+		}
+		candle.Closed = true
+		if candle.CloseTime.After(series.closedUntil) {
+			series.closedUntil = candle.CloseTime
+		}
+		closed = append(closed, candle)
+	}
+	series.open = remaining
+	return closed
+}
+
+// emit 在锁外输出K线：先输出收盘K线，再输出未收盘K线的更新
+func (a *candleAggregatorImpl) emit(updated, closed []*Candle) {
+	for _, candle := range closed {
+		a.closedCount.Add(1)
+		a.persistCandle(candle)
+		if a.handler != nil {
+			a.handler.OnCandleClosed(candle)
+		}
+	}
+	if a.handler == nil {
+		return
+	}
+	for _, candle := range updated {
+		a.handler.OnCandleUpdate(candle)
+	}
+}
+
+// persistCandle 提交收盘K线落库，不完整的K线不落库，失败不影响推送
+func (a *candleAggregatorImpl) persistCandle(candle *Candle) {
+	if a.persistence == nil || !a.persist[candle.Interval] || candle.Partial {
+		return
+	}
+
+	model := candle.ToModel()
+	item := &PersistenceItem{
+		ID:        fmt.Sprintf("kline:%s:%s:%d", model.Symbol, model.Granularity, model.Timestamp.UnixMilli()),
+		Type:      "kline",
+		Data:      model,
+		Timestamp: time.Now(),
+		Priority:  5,
+	}
+	if err := a.persistence.Submit(item); err != nil {
+		a.logger.Warn("提交K线落库失败",
+			zap.String("symbol", candle.Symbol),
+			zap.String("interval", candle.Interval),
+			zap.Error(err),
+		)
+	}
+}
+
+// candle 返回开盘时间对应的未收盘K线，不存在时按开盘时间顺序插入新K线
+// 序列的第一根K线在开盘之后才收到数据时，缺少启动前的数据，标记为不完整
+func (s *candleSeries) candle(symbol string, interval candleInterval, openTime, timestamp time.Time) *Candle {
+	i := sort.Search(len(s.open), func(i int) bool {
+		return !s.open[i].OpenTime.Before(openTime)
+	})
+	if i < len(s.open) && s.open[i].OpenTime.Equal(openTime) {
+		return s.open[i]
+	}
+
+	candle := &Candle{
+		Symbol:    symbol,
+		Interval:  interval.name,
+		OpenTime:  openTime,
+		CloseTime: openTime.Add(interval.duration),
+		Partial:   !s.started && timestamp.After(openTime),
+	}
+	s.started = true
+	s.open = append(s.open, nil)
+	copy(s.open[i+1:], s.open[i:])
+	s.open[i] = candle
+	return candle
+}
+
+// apply 将一条价格数据计入K线，开盘价和收盘价按数据时间而不是到达顺序确定
+func (c *Candle) apply(price decimal.Decimal, timestamp time.Time, baseVolume, usdtVolume decimal.Decimal) {
+	if c.TickCount == 0 {
+		c.Open, c.High, c.Low, c.Close = price, price, price, price
+		c.firstTick, c.lastTick = timestamp, timestamp
+	} else {
+		if timestamp.Before(c.firstTick) {
+			c.Open = price
+			c.firstTick = timestamp
+		}
+		if !timestamp.Before(c.lastTick) {
+			c.Close = price
+			c.lastTick = timestamp
+		}
+		if price.GreaterThan(c.High) {
+			c.High = price
+		}
+		if price.LessThan(c.Low) {
+			c.Low = price
+		}
+	}
+
+	c.Volume = c.Volume.Add(baseVolume)
+	c.QuoteVolume = c.QuoteVolume.Add(usdtVolume)
+	c.TickCount++
+}
+
+// volumeDelta 由24小时滚动成交量推导两条数据之间的成交量
+// 滚动窗口移出的旧成交会使计数回落，此时只能确定新成交不小于0
+func volumeDelta(last, current decimal.Decimal) decimal.Decimal {
+	if !last.IsPositive() || current.LessThanOrEqual(last) {
+		return decimal.Zero
+	}
+	return current.Sub(last)
+}
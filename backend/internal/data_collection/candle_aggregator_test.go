@@ -0,0 +1,275 @@
+package data_collection
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// recordingCandleHandler 记录输出的K线
+type recordingCandleHandler struct {
+	mu      sync.Mutex
+	updates []*Candle
+	closed  []*Candle
+}
+
+func (h *recordingCandleHandler) OnCandleUpdate(candle *Candle) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.updates = append(h.updates, candle)
+}
+
+func (h *recordingCandleHandler) OnCandleClosed(candle *Candle) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = append(h.closed, candle)
+}
+
+func (h *recordingCandleHandler) closedCandles(interval string) []*Candle {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var result []*Candle
+	for _, candle := range h.closed {
+		if candle.Interval == interval {
+			result = append(result, candle)
+		}
+	}
+	return result
+}
+
+func createCandleTick(symbol string, price float64, timestamp time.Time, baseVolume24h float64) *PriceData {
+	data := createPriceData(symbol, price, timestamp, "test")
+	data.BaseVolume24h = decimal.NewFromFloat(baseVolume24h)
+	data.UsdtVolume24h = data.BaseVolume24h.Mul(data.Price)
+	return data
+}
+
+func newTestCandleAggregator(t *testing.T, intervals []string, watermark time.Duration, handler CandleHandler) *candleAggregatorImpl {
+	config := DefaultCandleAggregatorConfig()
+	config.Intervals = intervals
+	config.PersistIntervals = nil
+	config.Watermark = watermark
+	aggregator, err := NewCandleAggregator(config, nil, handler, zap.NewNop())
+	require.NoError(t, err)
+	return aggregator.(*candleAggregatorImpl)
+}
+
+func TestCandleAggregatorConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultCandleAggregatorConfig().Validate())
+
+	config := DefaultCandleAggregatorConfig()
+	config.Intervals = nil
+	assert.Error(t, config.Validate())
+
+	config = DefaultCandleAggregatorConfig()
+	config.Intervals = []string{"1m", "7x"}
+	assert.Error(t, config.Validate())
+
+	config = DefaultCandleAggregatorConfig()
+	config.PersistIntervals = []string{"1h"}
+	assert.Error(t, config.Validate())
+
+	config = DefaultCandleAggregatorConfig()
+	config.Watermark = -time.Second
+	assert.Error(t, config.Validate())
+
+	_, err := NewCandleAggregator(config, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestCandleAggregator_OHLCV(t *testing.T) {
+	handler := &recordingCandleHandler{}
+	aggregator := newTestCandleAggregator(t, []string{"1m"}, 2*time.Second, handler)
+
+	baseTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 100, baseTime.Add(1*time.Second), 1000)))
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 105, baseTime.Add(10*time.Second), 1010)))
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 95, baseTime.Add(20*time.Second), 1015)))
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 102, baseTime.Add(50*time.Second), 1012)))
+
+	candle, ok := aggregator.GetCandle("BTCUSDT", "1m")
+	require.True(t, ok)
+	assert.Equal(t, baseTime, candle.OpenTime)
+	assert.Equal(t, baseTime.Add(time.Minute), candle.CloseTime)
+	assert.Equal(t, "100", candle.Open.String())
+	assert.Equal(t, "105", candle.High.String())
+	assert.Equal(t, "95", candle.Low.String())
+	assert.Equal(t, "102", candle.Close.String())
+	// 首条数据没有前值，成交量回落的数据计为0
+	assert.Equal(t, "15", candle.Volume.String())
+	assert.Equal(t, 4, candle.TickCount)
+	assert.False(t, candle.Closed)
+
+	assert.Len(t, handler.updates, 4)
+	assert.Empty(t, handler.closed)
+
+	_, ok = aggregator.GetCandle("ETHUSDT", "1m")
+	assert.False(t, ok)
+}
+
+func TestCandleAggregator_OutOfOrderWithinWatermark(t *testing.T) {
+	aggregator := newTestCandleAggregator(t, []string{"1s"}, 2*time.Second, nil)
+
+	baseTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 100, baseTime.Add(500*time.Millisecond), 0)))
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 101, baseTime.Add(1500*time.Millisecond), 0)))
+	// 乱序到达的更早数据仍在水位内，应成为第一根K线的开盘价
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 99, baseTime.Add(100*time.Millisecond), 0)))
+
+	series := aggregator.symbols["BTCUSDT"].series["1s"]
+	require.Len(t, series.open, 2)
+	first := series.open[0]
+	assert.Equal(t, baseTime, first.OpenTime)
+	assert.Equal(t, "99", first.Open.String())
+	assert.Equal(t, "100", first.Close.String())
+	assert.Equal(t, "99", first.Low.String())
+	assert.Equal(t, 2, first.TickCount)
+	assert.Equal(t, int64(0), aggregator.GetStats().LateCount)
+}
+
+func TestCandleAggregator_LateTicksDropped(t *testing.T) {
+	handler := &recordingCandleHandler{}
+	aggregator := newTestCandleAggregator(t, []string{"1s"}, time.Second, handler)
+
+	baseTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 100, baseTime.Add(100*time.Millisecond), 0)))
+	// 事件时间推进到 3.5s，第一根K线（结束时间1s+水位1s）收盘
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 101, baseTime.Add(3500*time.Millisecond), 0)))
+	require.Len(t, handler.closedCandles("1s"), 1)
+	assert.True(t, handler.closedCandles("1s")[0].Closed)
+
+	// 属于已收盘K线的数据被丢弃
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 50, baseTime.Add(200*time.Millisecond), 0)))
+	// 落后事件时间水位的数据同样丢弃
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 50, baseTime.Add(1200*time.Millisecond), 0)))
+	// 水位内的迟到数据仍然计入
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 102, baseTime.Add(2600*time.Millisecond), 0)))
+
+	stats := aggregator.GetStats()
+	assert.Equal(t, int64(5), stats.ProcessedCount)
+	assert.Equal(t, int64(2), stats.LateCount)
+	assert.Equal(t, int64(1), stats.ClosedCount)
+	assert.Equal(t, 2, stats.OpenCandles)
+	assert.Equal(t, 1, stats.Symbols)
+	assert.Equal(t, "100", handler.closedCandles("1s")[0].Close.String())
+}
+
+func TestCandleAggregator_AdvanceAndFlush(t *testing.T) {
+	handler := &recordingCandleHandler{}
+	aggregator := newTestCandleAggregator(t, []string{"1s", "1m"}, 2*time.Second, handler)
+
+	baseTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 100, baseTime.Add(100*time.Millisecond), 0)))
+	require.NoError(t, aggregator.Process(createCandleTick("ETHUSDT", 10, baseTime.Add(200*time.Millisecond), 0)))
+
+	// 未超过水位时不收盘
+	aggregator.Advance(baseTime.Add(2 * time.Second))
+	assert.Empty(t, handler.closed)
+
+	// 没有新数据时按当前时间收盘
+	aggregator.Advance(baseTime.Add(3 * time.Second))
+	assert.Len(t, handler.closedCandles("1s"), 2)
+	assert.Empty(t, handler.closedCandles("1m"))
+
+	aggregator.Flush()
+	assert.Len(t, handler.closedCandles("1m"), 2)
+	assert.Equal(t, 0, aggregator.GetStats().OpenCandles)
+	assert.Equal(t, int64(4), aggregator.GetStats().ClosedCount)
+}
+
+func TestCandleAggregator_Persistence(t *testing.T) {
+	writer := NewMockDataWriter()
+	persistenceConfig := DefaultPersistenceConfig()
+	persistenceConfig.BatchTimeout = 10 * time.Millisecond
+	persistenceConfig.WorkerCount = 1
+	persistence := NewAsyncPersistence(persistenceConfig, writer, zap.NewNop())
+
+	ctx := context.Background()
+	require.NoError(t, persistence.Start(ctx))
+
+	config := DefaultCandleAggregatorConfig()
+	config.AdvanceInterval = 10 * time.Millisecond
+	aggregator, err := NewCandleAggregator(config, persistence, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	// 已结束的K线：首条数据恰好在开盘时间，K线完整
+	baseTime := time.Now().Truncate(5 * time.Minute).Add(-10 * time.Minute)
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 100, baseTime, 1000)))
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 110, baseTime.Add(time.Second), 1003)))
+	// 启动后首条数据晚于开盘时间，首根K线缺少之前的数据
+	require.NoError(t, aggregator.Process(createCandleTick("ETHUSDT", 10, baseTime.Add(30*time.Second), 100)))
+
+	require.NoError(t, aggregator.Start(ctx))
+	assert.True(t, aggregator.IsRunning())
+	assert.Error(t, aggregator.Start(ctx))
+
+	// 定时收盘后只有完整的 1m、5m K线落库
+	require.Eventually(t, func() bool {
+		return writer.GetWriteCount() == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// 当前周期的K线在停止时尚未结束，只推送不落库
+	require.NoError(t, aggregator.Process(createCandleTick("BTCUSDT", 120, time.Now(), 1005)))
+	require.NoError(t, aggregator.Stop(ctx))
+	assert.False(t, aggregator.IsRunning())
+	assert.Error(t, aggregator.Stop(ctx))
+	require.NoError(t, persistence.Stop(ctx))
+	assert.Equal(t, int64(2), writer.GetWriteCount())
+
+	granularities := make(map[string]bool)
+	for _, item := range writer.GetWrittenItems() {
+		assert.Equal(t, "kline", item.Type)
+		kline := item.Data.(*models.Kline)
+		granularities[kline.Granularity] = true
+		assert.Equal(t, "BTCUSDT", kline.Symbol)
+		assert.True(t, baseTime.Equal(kline.Timestamp))
+		assert.Equal(t, "100", kline.Open.String())
+		assert.Equal(t, "110", kline.Close.String())
+		assert.Equal(t, "3", kline.BaseVolume.String())
+	}
+	// 本地K线以 local_ 周期落库，不覆盖交易所K线
+	assert.Equal(t, map[string]bool{"local_1m": true, "local_5m": true}, granularities)
+}
+
+func TestCandleAggregator_DecimalPrices(t *testing.T) {
+	aggregator := newTestCandleAggregator(t, []string{"1m"}, time.Second, nil)
+
+	baseTime := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	volumes := []string{"1234.1", "1234.3", "1234.6"}
+	for i, price := range []string{"0.1", "0.3", "0.2"} {
+		data := createCandleTick("BTCUSDT", 0, baseTime.Add(time.Duration(i)*time.Second), 0)
+		data.Price = decimal.RequireFromString(price)
+		data.BaseVolume24h = decimal.RequireFromString(volumes[i])
+		data.UsdtVolume24h = decimal.RequireFromString(volumes[i]).Mul(data.Price)
+		require.NoError(t, aggregator.Process(data))
+	}
+
+	// 价格和成交量按行情的十进制值聚合，没有浮点误差
+	candle, ok := aggregator.GetCandle("BTCUSDT", "1m")
+	require.True(t, ok)
+	assert.Equal(t, "0.1", candle.Open.String())
+	assert.Equal(t, "0.3", candle.High.String())
+	assert.Equal(t, "0.1", candle.Low.String())
+	assert.Equal(t, "0.2", candle.Close.String())
+	assert.Equal(t, "0.5", candle.Volume.String())
+	assert.False(t, candle.Partial)
+	assert.Equal(t, "local_1m", candle.ToModel().Granularity)
+}
+
+func TestCandleAggregator_InvalidData(t *testing.T) {
+	aggregator := newTestCandleAggregator(t, []string{"1m"}, time.Second, nil)
+
+	assert.Error(t, aggregator.Process(nil))
+	assert.Error(t, aggregator.Process(createCandleTick("", 100, time.Now(), 0)))
+	assert.Error(t, aggregator.Process(createCandleTick("BTCUSDT", 0, time.Now(), 0)))
+	assert.Error(t, aggregator.Process(createCandleTick("BTCUSDT", 100, time.Time{}, 0)))
+	assert.Equal(t, int64(0), aggregator.GetStats().ProcessedCount)
+}
@@ -0,0 +1,144 @@
+package data_collection
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// CandleAggregator 行情K线聚合器接口
+// 将价格数据流按周期聚合为内存中的 OHLCV K线：K线在 结束时间+水位 之后收盘，
+// 水位内到达的迟到或乱序数据仍计入所属K线，所属K线已收盘的数据丢弃
+type CandleAggregator interface {
+	// Process 处理单个价格数据，更新各周期的未收盘K线
+	Process(data *PriceData) error
+
+	// Advance 按当前时间收盘已超过水位的K线（交易对长时间没有新数据时也能收盘）
+	Advance(now time.Time)
+
+	// Flush 收盘所有未收盘的K线，尚未结束的K线数据不完整，只推送不落库
+	Flush()
+
+	// GetCandle 获取交易对指定周期最新的未收盘K线
+	GetCandle(symbol, interval string) (*Candle, bool)
+
+	// GetStats 获取聚合统计
+	GetStats() *CandleAggregatorStats
+
+	// Start 启动定时收盘协程
+	Start(ctx context.Context) error
+
+	// Stop 停止定时收盘协程并收盘所有K线
+	Stop(ctx context.Context) error
+
+	// IsRunning 定时收盘协程是否在运行
+	IsRunning() bool
+}
+
+// CandleHandler K线输出处理器
+type CandleHandler interface {
+	// OnCandleUpdate 未收盘K线更新，用于实时图表的最后一根K线
+	OnCandleUpdate(candle *Candle)
+
+	// OnCandleClosed K线收盘
+	OnCandleClosed(candle *Candle)
+}
+
+// CandleAggregatorConfig K线聚合配置
+type CandleAggregatorConfig struct {
+	Intervals        []string      `json:"intervals" yaml:"intervals"`                 // 聚合周期（如 1s、1m、5m）
+	Watermark        time.Duration `json:"watermark" yaml:"watermark"`                 // 允许的迟到时长，应大于交易所推送延迟
+	PersistIntervals []string      `json:"persist_intervals" yaml:"persist_intervals"` // 收盘后写入 klines 表的周期，以 local_ 周期落库
+	AdvanceInterval  time.Duration `json:"advance_interval" yaml:"advance_interval"`   // 检查收盘的间隔
+}
+
+// DefaultCandleAggregatorConfig 返回默认的K线聚合配置
+// 1s K线只用于实时推送，数量过大不落库；1m、5m 以 local_1m、local_5m 周期落库，不与交易所K线混用
+func DefaultCandleAggregatorConfig() *CandleAggregatorConfig {
+	return &CandleAggregatorConfig{
+		Intervals:        []string{"1s", "1m", "5m"},
+		Watermark:        2 * time.Second,
+		PersistIntervals: []string{"1m", "5m"},
+		AdvanceInterval:  250 * time.Millisecond,
+	}
+}
+
+// Validate 校验配置
+func (c *CandleAggregatorConfig) Validate() error {
+	if len(c.Intervals) == 0 {
+		return fmt.Errorf("K线周期不能为空")
+	}
+	for _, interval := range c.Intervals {
+		if _, err := models.ParseTimeWindow(interval); err != nil {
+			return fmt.Errorf("K线周期无效: %w", err)
+		}
+	}
+	for _, interval := range c.PersistIntervals {
+		if !slices.Contains(c.Intervals, interval) {
+			return fmt.Errorf("落库周期 %s 不在聚合周期中", interval)
+		}
+		if len(models.LocalKlineGranularity(interval)) > maxKlineGranularityLength {
+			return fmt.Errorf("落库周期 %s 过长", interval)
+		}
+	}
+	if c.Watermark < 0 {
+		return fmt.Errorf("水位不能为负数")
+	}
+	if c.AdvanceInterval <= 0 {
+		return fmt.Errorf("收盘检查间隔必须大于0")
+	}
+	return nil
+}
+
+// maxKlineGranularityLength klines.granularity 列的长度
+const maxKlineGranularityLength = 10
+
+// Candle 聚合生成的K线，价格直接取自行情的精确价格
+// 成交量由相邻两条行情的24小时滚动成交量之差推导，乱序数据只更新价格
+type Candle struct {
+	Symbol      string          `json:"symbol"`
+	Interval    string          `json:"interval"`
+	OpenTime    time.Time       `json:"open_time"`
+	CloseTime   time.Time       `json:"close_time"` // 结束时间（不含）
+	Open        decimal.Decimal `json:"open"`
+	High        decimal.Decimal `json:"high"`
+	Low         decimal.Decimal `json:"low"`
+	Close       decimal.Decimal `json:"close"`
+	Volume      decimal.Decimal `json:"volume"`       // 交易币成交量
+	QuoteVolume decimal.Decimal `json:"quote_volume"` // USDT成交额
+	TickCount   int             `json:"tick_count"`
+	Closed      bool            `json:"closed"`
+	Partial     bool            `json:"partial"` // 缺少部分周期的数据（启动后的首根K线、停止时尚未结束的K线），不落库
+
+	firstTick time.Time // 开盘价对应的数据时间
+	lastTick  time.Time // 收盘价对应的数据时间
+}
+
+// ToModel 转换为K线数据模型，周期使用 local_ 前缀与交易所K线区分
+func (c *Candle) ToModel() *models.Kline {
+	return &models.Kline{
+		Symbol:      c.Symbol,
+		Timestamp:   c.OpenTime,
+		Granularity: models.LocalKlineGranularity(c.Interval),
+		Open:        c.Open,
+		High:        c.High,
+		Low:         c.Low,
+		Close:       c.Close,
+		BaseVolume:  c.Volume,
+		QuoteVolume: c.QuoteVolume,
+	}
+}
+
+// CandleAggregatorStats K线聚合统计
+type CandleAggregatorStats struct {
+	ProcessedCount int64 `json:"processed_count"` // 处理的价格数据数
+	LateCount      int64 `json:"late_count"`      // 因所属K线已收盘而丢弃的次数（按周期计）
+	ClosedCount    int64 `json:"closed_count"`    // 收盘的K线数
+	OpenCandles    int   `json:"open_candles"`    // 当前未收盘的K线数
+	Symbols        int   `json:"symbols"`         // 交易对数
+}
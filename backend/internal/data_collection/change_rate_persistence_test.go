@@ -0,0 +1,105 @@
+package data_collection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// fakeChangeRateStore 记录写入的变化率
+type fakeChangeRateStore struct {
+	mu      sync.Mutex
+	rates   []*models.PriceChangeRate
+	batches int
+	err     error
+}
+
+func (s *fakeChangeRateStore) CreateBatch(ctx context.Context, rates []*models.PriceChangeRate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.batches++
+	s.rates = append(s.rates, rates...)
+	return nil
+}
+
+func TestDatabaseWriter_ChangeRates(t *testing.T) {
+	store := &fakeChangeRateStore{}
+	writer := NewDatabaseWriterWithChangeRateStore(DefaultPersistenceConfig(), store, zap.NewNop())
+	ctx := context.Background()
+
+	now := time.Now()
+	items := []*PersistenceItem{
+		{ID: "1", Type: "changerate", Timestamp: now, Data: &ProcessedPriceChangeRate{
+			Symbol: "BTCUSDT", TimeWindow: "10s", ChangeRate: 1.5, StartPrice: 100, EndPrice: 101.5, Timestamp: now,
+		}},
+		{ID: "2", Type: "changerate", Timestamp: now, Data: &ProcessedPriceChangeRate{
+			Symbol: "BTCUSDT", TimeWindow: "5m", ChangeRate: -2, StartPrice: 100, EndPrice: 98, Timestamp: now,
+		}},
+		{ID: "3", Type: "changerate", Timestamp: now, Data: "invalid"},
+	}
+
+	result, err := writer.WriteBatch(ctx, items)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.SuccessCount)
+	assert.Equal(t, 1, result.ErrorCount)
+	assert.Equal(t, 1, store.batches, "同一批次的变化率合并写入")
+	require.Len(t, store.rates, 2)
+	assert.Equal(t, "10s", store.rates[0].WindowSize)
+	assert.Equal(t, 100.0, store.rates[0].PriceBefore)
+	assert.Equal(t, 101.5, store.rates[0].PriceAfter)
+
+	store.err = fmt.Errorf("connection refused")
+	result, err = writer.WriteBatch(ctx, items[:2])
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ErrorCount)
+	assert.True(t, result.Errors[0].Retryable)
+}
+
+func TestPriceProcessor_PersistChangeRates(t *testing.T) {
+	writer := NewMockDataWriter()
+	persistenceConfig := DefaultPersistenceConfig()
+	persistenceConfig.BatchTimeout = 10 * time.Millisecond
+	persistenceConfig.WorkerCount = 1
+	persistence := NewAsyncPersistence(persistenceConfig, writer, zap.NewNop())
+
+	ctx := context.Background()
+	require.NoError(t, persistence.Start(ctx))
+	defer persistence.Stop(ctx)
+
+	config := DefaultProcessorConfig()
+	config.TimeWindows = []TimeWindow{TimeWindow10s, TimeWindow1m}
+	config.PersistInterval = 10 * time.Second
+	processor := NewPriceProcessorWithPersistence(config, persistence, zap.NewNop())
+	require.NoError(t, processor.(*priceProcessorImpl).Start(ctx))
+	defer processor.(*priceProcessorImpl).Stop(ctx)
+
+	// 25秒内每秒一条数据，按10秒采样每个窗口应持久化3次（0s、10s、20s）
+	baseTime := time.Now().Add(-time.Minute)
+	for i := 0; i < 25; i++ {
+		price := createPriceData("BTCUSDT", 100+float64(i), baseTime.Add(time.Duration(i)*time.Second), "test")
+		require.NoError(t, processor.ProcessPrice(price))
+	}
+
+	require.Eventually(t, func() bool {
+		return writer.GetWriteCount() == 6
+	}, 2*time.Second, 10*time.Millisecond)
+
+	perWindow := make(map[string]int)
+	for _, item := range writer.GetWrittenItems() {
+		assert.Equal(t, "changerate", item.Type)
+		rate := item.Data.(*ProcessedPriceChangeRate)
+		perWindow[rate.TimeWindow]++
+	}
+	assert.Equal(t, map[string]int{"10s": 3, "1m": 3}, perWindow)
+}
@@ -0,0 +1,175 @@
+package data_collection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// BulkCopier 基于 COPY 的批量写入接口（由 dao.CopyIngester 实现）
+type BulkCopier interface {
+	CopyPriceTicks(ctx context.Context, ticks []*models.PriceTick) (int64, error)
+	CopyKlines(ctx context.Context, klines []*models.Kline) (int64, error)
+}
+
+// CopyWriter 使用 COPY 写入价格数据和K线的数据写入器
+// 每个批次中的价格数据和K线各用一次 COPY 写入，不受单次 INSERT 的条数限制；
+// 其他类型（变化率、交易对）交给 fallback 写入
+type CopyWriter struct {
+	config   *PersistenceConfig
+	copier   BulkCopier
+	fallback DataWriter
+	logger   *zap.Logger
+}
+
+// NewCopyWriter 创建 COPY 数据写入器，fallback 为空时其他类型的数据仅记录日志
+func NewCopyWriter(config *PersistenceConfig, copier BulkCopier, fallback DataWriter, logger *zap.Logger) DataWriter {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if fallback == nil {
+		fallback = NewDatabaseWriter(config, logger)
+	}
+
+	return &CopyWriter{
+		config:   config,
+		copier:   copier,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+// Write 写入单个数据
+func (w *CopyWriter) Write(ctx context.Context, item *PersistenceItem) error {
+	if item.Type != "tick" && item.Type != "kline" {
+		return w.fallback.Write(ctx, item)
+	}
+
+	result, err := w.WriteBatch(ctx, []*PersistenceItem{item})
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%s", result.Errors[0].Error)
+	}
+	return nil
+}
+
+// WriteBatch 批量写入
+func (w *CopyWriter) WriteBatch(ctx context.Context, items []*PersistenceItem) (*PersistenceResult, error) {
+	start := time.Now()
+	result := &PersistenceResult{
+		Timestamp: time.Now(),
+		Errors:    make([]PersistenceError, 0),
+	}
+
+	var ticks, klines, others []*PersistenceItem
+	for _, item := range items {
+		switch item.Type {
+		case "tick":
+			ticks = append(ticks, item)
+		case "kline":
+			klines = append(klines, item)
+		default:
+			others = append(others, item)
+		}
+	}
+
+	record := func(successCount int, errors []PersistenceError) {
+		result.SuccessCount += successCount
+		result.ErrorCount += len(errors)
+		result.Errors = append(result.Errors, errors...)
+	}
+
+	if len(ticks) > 0 {
+		record(copyItems(ctx, ticks, "价格数据格式错误", validPriceTick, w.copier.CopyPriceTicks))
+	}
+	if len(klines) > 0 {
+		record(copyItems(ctx, klines, "K线数据格式错误", validKline, w.copier.CopyKlines))
+	}
+	if len(others) > 0 {
+		fallbackResult, err := w.fallback.WriteBatch(ctx, others)
+		if err != nil {
+			return nil, err
+		}
+		record(fallbackResult.SuccessCount, fallbackResult.Errors)
+	}
+
+	result.Duration = time.Since(start)
+
+	w.logger.Debug("COPY批量写入完成",
+		zap.Int("ticks", len(ticks)),
+		zap.Int("klines", len(klines)),
+		zap.Int("others", len(others)),
+		zap.Int("success_count", result.SuccessCount),
+		zap.Int("error_count", result.ErrorCount),
+		zap.Duration("duration", result.Duration))
+
+	return result, nil
+}
+
+// HealthCheck 健康检查
+func (w *CopyWriter) HealthCheck(ctx context.Context) error {
+	return w.fallback.HealthCheck(ctx)
+}
+
+// Close 关闭连接
+func (w *CopyWriter) Close() error {
+	return w.fallback.Close()
+}
+
+// copyItems 过滤无效数据后用一次 COPY 写入同类型数据
+// COPY 是单条语句，任意一行失败整批回滚，因此先剔除无效数据（不可重试），写入失败时整批可重试
+func copyItems[M any](ctx context.Context, items []*PersistenceItem, formatError string,
+	valid func(M) bool, bulkCopy func(context.Context, []M) (int64, error)) (int, []PersistenceError) {
+	errors := make([]PersistenceError, 0)
+
+	accepted := make([]*PersistenceItem, 0, len(items))
+	records := make([]M, 0, len(items))
+	for _, item := range items {
+		record, ok := item.Data.(M)
+		if !ok || !valid(record) {
+			errors = append(errors, PersistenceError{
+				ItemID:    item.ID,
+				Error:     formatError,
+				Timestamp: time.Now(),
+				Retryable: false,
+			})
+			continue
+		}
+		accepted = append(accepted, item)
+		records = append(records, record)
+	}
+
+	if len(records) == 0 {
+		return 0, errors
+	}
+
+	if _, err := bulkCopy(ctx, records); err != nil {
+		for _, item := range accepted {
+			errors = append(errors, PersistenceError{
+				ItemID:    item.ID,
+				Error:     err.Error(),
+				Timestamp: time.Now(),
+				Retryable: true,
+			})
+		}
+		return 0, errors
+	}
+
+	return len(records), errors
+}
+
+// validPriceTick 与 dao 的校验规则一致
+func validPriceTick(tick *models.PriceTick) bool {
+	return tick != nil && tick.Symbol != "" && tick.LastPrice.Sign() > 0
+}
+
+// validKline 与 dao 的校验规则一致
+func validKline(kline *models.Kline) bool {
+	return kline != nil && kline.Symbol != "" && kline.Granularity != ""
+}
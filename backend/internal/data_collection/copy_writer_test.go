@@ -0,0 +1,131 @@
+package data_collection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// fakeBulkCopier 记录每次 COPY 写入的数据
+type fakeBulkCopier struct {
+	mu          sync.Mutex
+	tickCopies  [][]*models.PriceTick
+	klineCopies [][]*models.Kline
+	err         error
+}
+
+func (c *fakeBulkCopier) CopyPriceTicks(ctx context.Context, ticks []*models.PriceTick) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.tickCopies = append(c.tickCopies, ticks)
+	return int64(len(ticks)), nil
+}
+
+func (c *fakeBulkCopier) CopyKlines(ctx context.Context, klines []*models.Kline) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.klineCopies = append(c.klineCopies, klines)
+	return int64(len(klines)), nil
+}
+
+// fakeTickStore 记录每次 INSERT 写入的条数
+type fakeTickStore struct {
+	batches []int
+}
+
+func (s *fakeTickStore) CreateBatch(ctx context.Context, ticks []*models.PriceTick) error {
+	s.batches = append(s.batches, len(ticks))
+	return nil
+}
+
+func tickItems(n int, now time.Time) []*PersistenceItem {
+	items := make([]*PersistenceItem, n)
+	for i := range items {
+		items[i] = &PersistenceItem{
+			ID:        fmt.Sprintf("tick-%d", i),
+			Type:      "tick",
+			Timestamp: now,
+			Data:      &models.PriceTick{Symbol: "BTCUSDT", Timestamp: now.Add(time.Duration(i)), LastPrice: decimal.NewFromInt(50000)},
+		}
+	}
+	return items
+}
+
+func TestCopyWriter_WriteBatch(t *testing.T) {
+	copier := &fakeBulkCopier{}
+	rates := &fakeChangeRateStore{}
+	fallback := NewDatabaseWriterWithChangeRateStore(DefaultPersistenceConfig(), rates, zap.NewNop())
+	writer := NewCopyWriter(DefaultPersistenceConfig(), copier, fallback, nil)
+	ctx := context.Background()
+
+	now := time.Now()
+	items := tickItems(3, now)
+	items = append(items,
+		&PersistenceItem{ID: "bad-tick", Type: "tick", Timestamp: now, Data: &models.PriceTick{Symbol: "BTCUSDT"}},
+		&PersistenceItem{ID: "kline-1", Type: "kline", Timestamp: now, Data: &models.Kline{
+			Symbol: "BTCUSDT", Granularity: "1m", Timestamp: now, Open: decimal.NewFromInt(1), High: decimal.NewFromInt(2), Low: decimal.NewFromInt(1), Close: decimal.NewFromInt(2),
+		}},
+		&PersistenceItem{ID: "bad-kline", Type: "kline", Timestamp: now, Data: "invalid"},
+		&PersistenceItem{ID: "rate-1", Type: "changerate", Timestamp: now, Data: &ProcessedPriceChangeRate{
+			Symbol: "BTCUSDT", TimeWindow: "1m", ChangeRate: 1, StartPrice: 100, EndPrice: 101, Timestamp: now,
+		}},
+	)
+
+	result, err := writer.WriteBatch(ctx, items)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.SuccessCount)
+	assert.Equal(t, 2, result.ErrorCount)
+	for _, e := range result.Errors {
+		assert.False(t, e.Retryable, e.ItemID)
+	}
+
+	// 同一批次的价格数据和K线各一次 COPY，其他类型交给 fallback
+	require.Len(t, copier.tickCopies, 1)
+	assert.Len(t, copier.tickCopies[0], 3)
+	require.Len(t, copier.klineCopies, 1)
+	assert.Len(t, copier.klineCopies[0], 1)
+	assert.Len(t, rates.rates, 1)
+
+	// COPY 失败时整批可重试
+	copier.err = fmt.Errorf("connection reset")
+	result, err = writer.WriteBatch(ctx, tickItems(2, now))
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.SuccessCount)
+	require.Len(t, result.Errors, 2)
+	assert.True(t, result.Errors[0].Retryable)
+
+	assert.Error(t, writer.Write(ctx, tickItems(1, now)[0]))
+	copier.err = nil
+	assert.NoError(t, writer.Write(ctx, tickItems(1, now)[0]))
+	assert.NoError(t, writer.Write(ctx, items[6]))
+	assert.Len(t, rates.rates, 2)
+}
+
+func TestDatabaseWriter_Ticks(t *testing.T) {
+	ticks := &fakeTickStore{}
+	writer := NewDatabaseWriterWithStores(DefaultPersistenceConfig(), DatabaseWriterStores{Ticks: ticks}, zap.NewNop())
+
+	// 按 DAO 的单次上限分段 INSERT
+	result, err := writer.WriteBatch(context.Background(), tickItems(2500, time.Now()))
+	require.NoError(t, err)
+	assert.Equal(t, 2500, result.SuccessCount)
+	assert.Equal(t, []int{1000, 1000, 500}, ticks.batches)
+
+	require.NoError(t, writer.Write(context.Background(), tickItems(1, time.Now())[0]))
+	assert.Equal(t, []int{1000, 1000, 500, 1}, ticks.batches)
+}
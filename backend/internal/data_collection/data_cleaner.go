@@ -8,6 +8,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
@@ -143,7 +144,7 @@ func (c *dataCleanerImpl) cleanPrice(cleaned *CleanedPriceData) {
 		originalPrice := cleanedData.Price
 		cleanedData.Price = c.roundPrice(cleanedData.Price, c.rules.PricePrecision)
 
-		if originalPrice != cleanedData.Price {
+		if !originalPrice.Equal(cleanedData.Price) {
 			c.addChange(cleaned, "price", originalPrice, cleanedData.Price, "精度调整", 0.9)
 		}
 	}
@@ -155,27 +156,27 @@ func (c *dataCleanerImpl) cleanPrice(cleaned *CleanedPriceData) {
 	}
 
 	// 买卖价清洗
-	if cleanedData.BidPrice > 0 && cleanedData.AskPrice > 0 {
+	if cleanedData.BidPrice.IsPositive() && cleanedData.AskPrice.IsPositive() {
 		originalBid := cleanedData.BidPrice
 		originalAsk := cleanedData.AskPrice
 
 		cleanedData.BidPrice = c.roundPrice(cleanedData.BidPrice, c.rules.PricePrecision)
 		cleanedData.AskPrice = c.roundPrice(cleanedData.AskPrice, c.rules.PricePrecision)
 
-		if originalBid != cleanedData.BidPrice {
+		if !originalBid.Equal(cleanedData.BidPrice) {
 			c.addChange(cleaned, "bid_price", originalBid, cleanedData.BidPrice, "精度调整", 0.9)
 		}
-		if originalAsk != cleanedData.AskPrice {
+		if !originalAsk.Equal(cleanedData.AskPrice) {
 			c.addChange(cleaned, "ask_price", originalAsk, cleanedData.AskPrice, "精度调整", 0.9)
 		}
 	}
 
 	// 交易量清洗
-	if cleanedData.Volume > 0 {
+	if cleanedData.Volume.IsPositive() {
 		originalVolume := cleanedData.Volume
 		cleanedData.Volume = c.roundPrice(cleanedData.Volume, 2) // 交易量保留2位小数
 
-		if originalVolume != cleanedData.Volume {
+		if !originalVolume.Equal(cleanedData.Volume) {
 			c.addChange(cleaned, "volume", originalVolume, cleanedData.Volume, "精度调整", 0.8)
 		}
 	}
@@ -294,20 +295,19 @@ func (c *dataCleanerImpl) copyPriceData(original *PriceData) *PriceData {
 	}
 }
 
-// roundPrice 价格舍入
-func (c *dataCleanerImpl) roundPrice(price float64, precision int) float64 {
-	multiplier := math.Pow(10, float64(precision))
+// roundPrice 按十进制精确舍入价格
+func (c *dataCleanerImpl) roundPrice(value decimal.Decimal, precision int) decimal.Decimal {
+	places := int32(precision)
 
 	switch c.rules.PriceRounding {
-	case "round":
-		return math.Round(price*multiplier) / multiplier
 	case "floor":
-		return math.Floor(price*multiplier) / multiplier
+		value = value.RoundFloor(places)
 	case "ceil":
-		return math.Ceil(price*multiplier) / multiplier
+		value = value.RoundCeil(places)
 	default:
-		return math.Round(price*multiplier) / multiplier
+		value = value.Round(places)
 	}
+	return value
 }
 
 // roundTime 时间舍入
@@ -5,6 +5,8 @@ import (
 	"sort"
 	"sync"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // DataMerger 数据合并器
@@ -141,13 +143,13 @@ func (dm *DataMerger) mergePriceData(items []*PersistenceItem) *PriceData {
 	}
 
 	// 计算统计信息
-	var totalVolume float64
+	totalVolume := decimal.Zero
 	var avgLatency time.Duration
 	var sourceCount int
 
 	for _, item := range items {
 		if priceData, ok := item.Data.(*PriceData); ok {
-			totalVolume += priceData.Volume
+			totalVolume = totalVolume.Add(priceData.Volume)
 			avgLatency += priceData.Latency
 			sourceCount++
 		}
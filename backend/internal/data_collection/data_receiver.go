@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
@@ -280,7 +281,7 @@ func (r *dataReceiverImpl) connectWebSocketSource(source DataSourceConfig) {
 			for _, symbol := range r.config.Symbols {
 				price := &PriceData{
 					Symbol:    symbol,
-					Price:     50000.0 + float64(time.Now().UnixNano()%1000),
+					Price:     decimal.NewFromInt(50000 + time.Now().UnixNano()%1000),
 					Timestamp: time.Now(),
 					Source:    source.Name,
 				}
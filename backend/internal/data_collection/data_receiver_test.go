@@ -94,7 +94,7 @@ func TestDataReceiver_DataReception(t *testing.T) {
 		case data := <-dataChan:
 			require.NotNil(t, data)
 			assert.NotEmpty(t, data.Symbol)
-			assert.True(t, data.Price > 0)
+			assert.True(t, data.Price.IsPositive())
 			assert.False(t, data.Timestamp.IsZero())
 			receivedCount++
 		case <-timeout:
@@ -158,7 +158,7 @@ func TestMessageParser_JSONParsing(t *testing.T) {
 		priceData, err := parser.ParseMessage(jsonData)
 		require.NoError(t, err)
 		assert.Equal(t, "BTCUSDT", priceData.Symbol)
-		assert.Equal(t, 50000.0, priceData.Price)
+		assert.Equal(t, "50000", priceData.Price.String())
 		assert.Equal(t, "test", priceData.Source)
 		assert.False(t, priceData.Timestamp.IsZero())
 	})
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -20,10 +21,10 @@ func TestDataValidator_BasicValidation(t *testing.T) {
 	// 测试有效数据
 	validData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.0,
-		BidPrice:  49999.0,
-		AskPrice:  50001.0,
-		Volume:    100.0,
+		Price:     decimal.NewFromFloat(50000.0),
+		BidPrice:  decimal.NewFromFloat(49999.0),
+		AskPrice:  decimal.NewFromFloat(50001.0),
+		Volume:    decimal.NewFromFloat(100.0),
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "test",
 		Latency:   10 * time.Millisecond,
@@ -45,10 +46,10 @@ func TestDataValidator_RequiredFields(t *testing.T) {
 
 	// 测试缺少必需字段
 	invalidData := &PriceData{
-		Symbol:    "",          // 缺少交易对
-		Price:     0,           // 无效价格
-		Timestamp: time.Time{}, // 空时间戳
-		Source:    "",          // 缺少数据源
+		Symbol:    "",                      // 缺少交易对
+		Price:     decimal.NewFromFloat(0), // 无效价格
+		Timestamp: time.Time{},             // 空时间戳
+		Source:    "",                      // 缺少数据源
 	}
 
 	result := validator.ValidatePriceData(invalidData)
@@ -77,7 +78,7 @@ func TestDataValidator_PriceRange(t *testing.T) {
 	// 测试价格超出范围
 	invalidData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     2000000.0, // 超出最大价格
+		Price:     decimal.NewFromFloat(2000000.0), // 超出最大价格
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "test",
 	}
@@ -107,7 +108,7 @@ func TestDataValidator_TimestampValidation(t *testing.T) {
 	// 测试未来时间戳
 	futureData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.0,
+		Price:     decimal.NewFromFloat(50000.0),
 		Timestamp: time.Now().Add(10 * time.Minute), // 未来10分钟
 		Source:    "test",
 	}
@@ -139,10 +140,10 @@ func TestDataValidator_DataQuality(t *testing.T) {
 	// 测试低质量数据
 	lowQualityData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.0,
-		BidPrice:  40000.0, // 异常大的价差
-		AskPrice:  60000.0,
-		Volume:    0.0, // 零交易量
+		Price:     decimal.NewFromFloat(50000.0),
+		BidPrice:  decimal.NewFromFloat(40000.0), // 异常大的价差
+		AskPrice:  decimal.NewFromFloat(60000.0),
+		Volume:    decimal.Zero, // 零交易量
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "test",
 		Latency:   20 * time.Second, // 高延迟
@@ -173,9 +174,9 @@ func TestDataValidator_BatchValidation(t *testing.T) {
 
 	// 测试批量验证
 	batchData := []*PriceData{
-		{Symbol: "BTCUSDT", Price: 50000.0, Timestamp: time.Now().Add(-1 * time.Minute), Source: "test"},
-		{Symbol: "ETHUSDT", Price: 3000.0, Timestamp: time.Now().Add(-2 * time.Minute), Source: "test"},
-		{Symbol: "", Price: 0.0, Timestamp: time.Time{}, Source: ""}, // 无效数据
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0), Timestamp: time.Now().Add(-1 * time.Minute), Source: "test"},
+		{Symbol: "ETHUSDT", Price: decimal.NewFromFloat(3000.0), Timestamp: time.Now().Add(-2 * time.Minute), Source: "test"},
+		{Symbol: "", Price: decimal.Zero, Timestamp: time.Time{}, Source: ""}, // 无效数据
 	}
 
 	results := validator.ValidateBatchData(batchData)
@@ -199,14 +200,14 @@ func TestDataValidator_ValidationStats(t *testing.T) {
 	// 验证一些数据
 	validData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.0,
+		Price:     decimal.NewFromFloat(50000.0),
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "test",
 	}
 
 	invalidData := &PriceData{
 		Symbol:    "",
-		Price:     0,
+		Price:     decimal.NewFromFloat(0),
 		Timestamp: time.Time{},
 		Source:    "",
 	}
@@ -234,10 +235,10 @@ func TestDataCleaner_BasicCleaning(t *testing.T) {
 	// 测试数据清洗
 	originalData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.123456789, // 需要精度调整
-		BidPrice:  49999.987654321,
-		AskPrice:  50001.111111111,
-		Volume:    100.999999,
+		Price:     decimal.NewFromFloat(50000.123456789), // 需要精度调整
+		BidPrice:  decimal.NewFromFloat(49999.987654321),
+		AskPrice:  decimal.NewFromFloat(50001.111111111),
+		Volume:    decimal.NewFromFloat(100.999999),
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "TEST",                     // 需要标准化
 		Latency:   12345678 * time.Nanosecond, // 需要精度调整
@@ -262,7 +263,7 @@ func TestDataCleaner_PricePrecision(t *testing.T) {
 
 	originalData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.123456789,
+		Price:     decimal.NewFromFloat(50000.123456789),
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "test",
 	}
@@ -271,16 +272,16 @@ func TestDataCleaner_PricePrecision(t *testing.T) {
 	require.NotNil(t, cleaned)
 
 	// 检查价格精度
-	expectedPrice := 50000.12
-	assert.Equal(t, expectedPrice, cleaned.Cleaned.Price, "价格应该被舍入到2位小数")
+	expectedPrice := decimal.RequireFromString("50000.12")
+	assert.True(t, expectedPrice.Equal(cleaned.Cleaned.Price), "价格应该被舍入到2位小数")
 
 	// 检查是否有变更记录
 	hasPriceChange := false
 	for _, change := range cleaned.Changes {
 		if change.Field == "price" {
 			hasPriceChange = true
-			assert.Equal(t, 50000.123456789, change.Original)
-			assert.Equal(t, expectedPrice, change.Cleaned)
+			assert.Equal(t, "50000.123456789", change.Original.(decimal.Decimal).String())
+			assert.Equal(t, expectedPrice.String(), change.Cleaned.(decimal.Decimal).String())
 			break
 		}
 	}
@@ -298,7 +299,7 @@ func TestDataCleaner_TimePrecision(t *testing.T) {
 	originalTime := time.Now().Add(-1 * time.Minute).Add(500 * time.Millisecond)
 	originalData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.0,
+		Price:     decimal.NewFromFloat(50000.0),
 		Timestamp: originalTime,
 		Source:    "test",
 	}
@@ -333,7 +334,7 @@ func TestDataCleaner_SourceNormalization(t *testing.T) {
 
 	originalData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.0,
+		Price:     decimal.NewFromFloat(50000.0),
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "TEST", // 大写需要标准化
 	}
@@ -366,8 +367,8 @@ func TestDataCleaner_BatchCleaning(t *testing.T) {
 
 	// 测试批量清洗
 	batchData := []*PriceData{
-		{Symbol: "BTCUSDT", Price: 50000.123456, Timestamp: time.Now().Add(-1 * time.Minute), Source: "test"},
-		{Symbol: "ETHUSDT", Price: 3000.987654, Timestamp: time.Now().Add(-2 * time.Minute), Source: "test"},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.123456), Timestamp: time.Now().Add(-1 * time.Minute), Source: "test"},
+		{Symbol: "ETHUSDT", Price: decimal.NewFromFloat(3000.987654), Timestamp: time.Now().Add(-2 * time.Minute), Source: "test"},
 	}
 
 	results := cleaner.CleanBatchData(batchData)
@@ -391,7 +392,7 @@ func TestDataCleaner_CleaningStats(t *testing.T) {
 	// 清洗一些数据
 	originalData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.123456,
+		Price:     decimal.NewFromFloat(50000.123456),
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "test",
 	}
@@ -417,10 +418,10 @@ func TestDataValidator_Integration(t *testing.T) {
 	// 测试数据
 	testData := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.123456789,
-		BidPrice:  49999.987654321,
-		AskPrice:  50001.111111111,
-		Volume:    100.999999,
+		Price:     decimal.NewFromFloat(50000.123456789),
+		BidPrice:  decimal.NewFromFloat(49999.987654321),
+		AskPrice:  decimal.NewFromFloat(50001.111111111),
+		Volume:    decimal.NewFromFloat(100.999999),
 		Timestamp: time.Now().Add(-1 * time.Minute),
 		Source:    "TEST",
 		Latency:   12345678 * time.Nanosecond,
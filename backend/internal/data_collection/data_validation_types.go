@@ -2,6 +2,8 @@ package data_collection
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // DataValidator 数据验证器接口
@@ -139,6 +141,11 @@ type PriceRange struct {
 	Symbols map[string]*PriceRange `json:"symbols" yaml:"symbols"` // 按交易对的价格范围
 }
 
+// Contains 价格是否在范围内（含边界）
+func (r *PriceRange) Contains(price decimal.Decimal) bool {
+	return !price.LessThan(decimal.NewFromFloat(r.Min)) && !price.GreaterThan(decimal.NewFromFloat(r.Max))
+}
+
 // TimeRange 时间范围
 type TimeRange struct {
 	Min           time.Time     `json:"min" yaml:"min"`
@@ -7,9 +7,16 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
+// extremePriceMin / extremePriceMax 超出该范围的价格视为可能异常
+var (
+	extremePriceMin = decimal.RequireFromString("0.000001")
+	extremePriceMax = decimal.NewFromInt(1000000)
+)
+
 // dataValidatorImpl 数据验证器实现
 type dataValidatorImpl struct {
 	rules  *ValidationRules
@@ -145,7 +152,7 @@ func (v *dataValidatorImpl) validateRequiredFields(data *PriceData, result *Vali
 				v.addError(result, "symbol", "REQUIRED_FIELD", "交易对符号不能为空", "error")
 			}
 		case "price":
-			if data.Price <= 0 {
+			if !data.Price.IsPositive() {
 				v.addError(result, "price", "INVALID_PRICE", "价格必须大于0", "error")
 			}
 		case "timestamp":
@@ -167,16 +174,16 @@ func (v *dataValidatorImpl) validatePriceRange(data *PriceData, result *Validati
 	}
 
 	// 检查全局价格范围
-	if data.Price < v.rules.PriceRange.Min || data.Price > v.rules.PriceRange.Max {
+	if !v.rules.PriceRange.Contains(data.Price) {
 		v.addError(result, "price", "PRICE_OUT_OF_RANGE",
-			fmt.Sprintf("价格 %f 超出范围 [%f, %f]", data.Price, v.rules.PriceRange.Min, v.rules.PriceRange.Max), "error")
+			fmt.Sprintf("价格 %s 超出范围 [%f, %f]", data.Price, v.rules.PriceRange.Min, v.rules.PriceRange.Max), "error")
 	}
 
 	// 检查交易对特定价格范围
 	if symbolRange, exists := v.rules.PriceRange.Symbols[data.Symbol]; exists {
-		if data.Price < symbolRange.Min || data.Price > symbolRange.Max {
+		if !symbolRange.Contains(data.Price) {
 			v.addError(result, "price", "SYMBOL_PRICE_OUT_OF_RANGE",
-				fmt.Sprintf("交易对 %s 价格 %f 超出范围 [%f, %f]", data.Symbol, data.Price, symbolRange.Min, symbolRange.Max), "error")
+				fmt.Sprintf("交易对 %s 价格 %s 超出范围 [%f, %f]", data.Symbol, data.Price, symbolRange.Min, symbolRange.Max), "error")
 		}
 	}
 }
@@ -210,9 +217,9 @@ func (v *dataValidatorImpl) validateTimestamp(data *PriceData, result *Validatio
 // validateDataQuality 验证数据质量
 func (v *dataValidatorImpl) validateDataQuality(data *PriceData, result *ValidationResult) {
 	// 检查交易量
-	if data.Volume < v.rules.MinVolume {
+	if data.Volume.LessThan(decimal.NewFromFloat(v.rules.MinVolume)) {
 		v.addWarning(result, "volume", "LOW_VOLUME",
-			fmt.Sprintf("交易量 %f 低于最小值 %f", data.Volume, v.rules.MinVolume), 0.6)
+			fmt.Sprintf("交易量 %s 低于最小值 %f", data.Volume, v.rules.MinVolume), 0.6)
 	}
 
 	// 检查延迟
@@ -221,14 +228,14 @@ func (v *dataValidatorImpl) validateDataQuality(data *PriceData, result *Validat
 			fmt.Sprintf("延迟 %v 超过最大值 %v", data.Latency, v.rules.MaxLatency), 0.7)
 	}
 
-	// 检查买卖价差
-	if data.BidPrice > 0 && data.AskPrice > 0 {
-		spread := data.AskPrice - data.BidPrice
-		spreadPercent := (spread / data.Price) * 100
+	// 检查买卖价差，使用精确的十进制运算
+	if data.BidPrice.IsPositive() && data.AskPrice.IsPositive() && data.Price.IsPositive() {
+		spread := data.AskPrice.Sub(data.BidPrice)
+		spreadPercent := spread.Div(data.Price).Mul(decimal.NewFromInt(100))
 
-		if spreadPercent > 1.0 { // 价差超过1%
+		if spreadPercent.GreaterThan(decimal.NewFromInt(1)) { // 价差超过1%
 			v.addWarning(result, "spread", "WIDE_SPREAD",
-				fmt.Sprintf("买卖价差 %.4f%% 较大", spreadPercent), 0.5)
+				fmt.Sprintf("买卖价差 %s%% 较大", spreadPercent.StringFixed(4)), 0.5)
 		}
 	}
 }
@@ -239,14 +246,14 @@ func (v *dataValidatorImpl) detectAnomalies(data *PriceData, result *ValidationR
 	// 例如：与历史数据比较、统计异常检测等
 
 	// 简单的价格异常检测
-	if data.Price <= 0 {
+	if !data.Price.IsPositive() {
 		v.addError(result, "price", "ANOMALY_NEGATIVE_PRICE", "价格异常：负数或零", "error")
 	}
 
 	// 检查价格是否过于极端
-	if data.Price > 1000000 || data.Price < 0.000001 {
+	if data.Price.GreaterThan(extremePriceMax) || data.Price.LessThan(extremePriceMin) {
 		v.addWarning(result, "price", "EXTREME_PRICE",
-			fmt.Sprintf("价格 %f 可能异常", data.Price), 0.9)
+			fmt.Sprintf("价格 %s 可能异常", data.Price), 0.9)
 	}
 }
 
@@ -6,14 +6,44 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
 
+// maxInsertBatchSize 单次 INSERT 的最大条数（与 DAO 的 CreateBatch 上限一致）
+const maxInsertBatchSize = 1000
+
+// ChangeRateStore 价格变化率存储接口（由 dao.PriceChangeRateDAO 实现）
+type ChangeRateStore interface {
+	CreateBatch(ctx context.Context, rates []*models.PriceChangeRate) error
+}
+
+// TickStore 价格数据存储接口（由 dao.PriceTickDAO 实现）
+type TickStore interface {
+	CreateBatch(ctx context.Context, ticks []*models.PriceTick) error
+}
+
+// KlineStore K线存储接口（由 dao.KlineDAO 实现）
+type KlineStore interface {
+	CreateBatch(ctx context.Context, klines []*models.Kline) error
+}
+
+// DatabaseWriterStores 数据库写入器使用的存储，为空的存储对应类型的数据仅记录日志
+type DatabaseWriterStores struct {
+	ChangeRates ChangeRateStore
+	Ticks       TickStore
+	Klines      KlineStore
+}
+
 // DatabaseWriter 数据库写入器
 type DatabaseWriter struct {
 	config *PersistenceConfig
 	logger *zap.Logger
-	// 这里可以添加数据库连接
-	// db *gorm.DB
+
+	// 各类型数据的存储，为空时仅记录日志
+	changeRates ChangeRateStore
+	ticks       TickStore
+	klines      KlineStore
 }
 
 // NewDatabaseWriter 创建数据库写入器
@@ -28,6 +58,26 @@ func NewDatabaseWriter(config *PersistenceConfig, logger *zap.Logger) DataWriter
 	}
 }
 
+// NewDatabaseWriterWithChangeRateStore 创建写入价格变化率表的数据库写入器
+func NewDatabaseWriterWithChangeRateStore(config *PersistenceConfig, changeRates ChangeRateStore, logger *zap.Logger) DataWriter {
+	return NewDatabaseWriterWithStores(config, DatabaseWriterStores{ChangeRates: changeRates}, logger)
+}
+
+// NewDatabaseWriterWithStores 创建通过 DAO 批量 INSERT 写入的数据库写入器
+func NewDatabaseWriterWithStores(config *PersistenceConfig, stores DatabaseWriterStores, logger *zap.Logger) DataWriter {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &DatabaseWriter{
+		config:      config,
+		logger:      logger,
+		changeRates: stores.ChangeRates,
+		ticks:       stores.Ticks,
+		klines:      stores.Klines,
+	}
+}
+
 // Write 写入单个数据
 func (d *DatabaseWriter) Write(ctx context.Context, item *PersistenceItem) error {
 	// 这里实现单个数据写入逻辑
@@ -38,6 +88,10 @@ func (d *DatabaseWriter) Write(ctx context.Context, item *PersistenceItem) error
 		return d.writePriceData(ctx, item)
 	case "changerate":
 		return d.writeChangeRateData(ctx, item)
+	case "tick":
+		return d.writeTickData(ctx, item)
+	case "kline":
+		return d.writeKlineData(ctx, item)
 	case "symbol":
 		return d.writeSymbolData(ctx, item)
 	default:
@@ -110,7 +164,7 @@ func (d *DatabaseWriter) writePriceData(ctx context.Context, item *PersistenceIt
 	// 模拟数据库写入
 	d.logger.Debug("写入价格数据",
 		zap.String("symbol", priceData.Symbol),
-		zap.Float64("price", priceData.Price),
+		zap.String("price", priceData.Price.String()),
 		zap.Time("timestamp", priceData.Timestamp))
 
 	// 这里应该执行实际的数据库插入操作
@@ -122,25 +176,58 @@ func (d *DatabaseWriter) writePriceData(ctx context.Context, item *PersistenceIt
 
 // writeChangeRateData 写入变化率数据
 func (d *DatabaseWriter) writeChangeRateData(ctx context.Context, item *PersistenceItem) error {
-	// 这里实现变化率数据写入逻辑
-	// 例如：插入到change_rates表
-
 	changeRateData, ok := item.Data.(*ProcessedPriceChangeRate)
 	if !ok {
 		return fmt.Errorf("变化率数据格式错误")
 	}
 
-	// 模拟数据库写入
 	d.logger.Debug("写入变化率数据",
 		zap.String("symbol", changeRateData.Symbol),
 		zap.String("time_window", changeRateData.TimeWindow),
 		zap.Float64("change_rate", changeRateData.ChangeRate),
 		zap.Time("timestamp", changeRateData.Timestamp))
 
-	// 这里应该执行实际的数据库插入操作
-	// 例如：
-	// return d.db.WithContext(ctx).Create(changeRateData).Error
+	if d.changeRates == nil {
+		return nil
+	}
+
+	if err := d.changeRates.CreateBatch(ctx, []*models.PriceChangeRate{changeRateData.ToModel()}); err != nil {
+		return fmt.Errorf("写入变化率数据失败: %w", err)
+	}
+	return nil
+}
+
+// writeTickData 写入单条价格数据
+func (d *DatabaseWriter) writeTickData(ctx context.Context, item *PersistenceItem) error {
+	tick, ok := item.Data.(*models.PriceTick)
+	if !ok {
+		return fmt.Errorf("价格数据格式错误")
+	}
+	if d.ticks == nil {
+		d.logger.Debug("写入价格数据", zap.String("symbol", tick.Symbol), zap.Time("timestamp", tick.Timestamp))
+		return nil
+	}
 
+	if err := d.ticks.CreateBatch(ctx, []*models.PriceTick{tick}); err != nil {
+		return fmt.Errorf("写入价格数据失败: %w", err)
+	}
+	return nil
+}
+
+// writeKlineData 写入单条K线数据
+func (d *DatabaseWriter) writeKlineData(ctx context.Context, item *PersistenceItem) error {
+	kline, ok := item.Data.(*models.Kline)
+	if !ok {
+		return fmt.Errorf("K线数据格式错误")
+	}
+	if d.klines == nil {
+		d.logger.Debug("写入K线数据", zap.String("symbol", kline.Symbol), zap.Time("timestamp", kline.Timestamp))
+		return nil
+	}
+
+	if err := d.klines.CreateBatch(ctx, []*models.Kline{kline}); err != nil {
+		return fmt.Errorf("写入K线数据失败: %w", err)
+	}
 	return nil
 }
 
@@ -182,6 +269,17 @@ func (d *DatabaseWriter) groupItemsByType(items []*PersistenceItem) map[string][
 
 // writeBatchByType 按类型批量写入
 func (d *DatabaseWriter) writeBatchByType(ctx context.Context, itemType string, items []*PersistenceItem) (int, []PersistenceError) {
+	switch {
+	case itemType == "changerate" && d.changeRates != nil:
+		return writeInChunks(ctx, items, "变化率数据格式错误", func(data *ProcessedPriceChangeRate) *models.PriceChangeRate {
+			return data.ToModel()
+		}, d.changeRates.CreateBatch)
+	case itemType == "tick" && d.ticks != nil:
+		return writeInChunks(ctx, items, "价格数据格式错误", identity[*models.PriceTick], d.ticks.CreateBatch)
+	case itemType == "kline" && d.klines != nil:
+		return writeInChunks(ctx, items, "K线数据格式错误", identity[*models.Kline], d.klines.CreateBatch)
+	}
+
 	successCount := 0
 	errors := make([]PersistenceError, 0)
 
@@ -201,3 +299,51 @@ func (d *DatabaseWriter) writeBatchByType(ctx context.Context, itemType string,
 
 	return successCount, errors
 }
+
+// writeInChunks 将同类型数据转换为模型后按最大批量分段写入
+// 数据格式错误的项目不可重试，写入失败的整段可重试
+func writeInChunks[D any, M any](ctx context.Context, items []*PersistenceItem, formatError string,
+	convert func(D) M, write func(context.Context, []M) error) (int, []PersistenceError) {
+	successCount := 0
+	errors := make([]PersistenceError, 0)
+
+	valid := make([]*PersistenceItem, 0, len(items))
+	records := make([]M, 0, len(items))
+	for _, item := range items {
+		data, ok := item.Data.(D)
+		if !ok {
+			errors = append(errors, PersistenceError{
+				ItemID:    item.ID,
+				Error:     formatError,
+				Timestamp: time.Now(),
+				Retryable: false,
+			})
+			continue
+		}
+		valid = append(valid, item)
+		records = append(records, convert(data))
+	}
+
+	for start := 0; start < len(records); start += maxInsertBatchSize {
+		end := min(start+maxInsertBatchSize, len(records))
+		if err := write(ctx, records[start:end]); err != nil {
+			for _, item := range valid[start:end] {
+				errors = append(errors, PersistenceError{
+					ItemID:    item.ID,
+					Error:     err.Error(),
+					Timestamp: time.Now(),
+					Retryable: true,
+				})
+			}
+			continue
+		}
+		successCount += end - start
+	}
+
+	return successCount, errors
+}
+
+// identity 数据本身即为模型时的转换函数
+func identity[T any](v T) T {
+	return v
+}
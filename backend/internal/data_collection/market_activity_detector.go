@@ -0,0 +1,399 @@
+package data_collection
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// activityPoint 价格/持仓量观察点
+type activityPoint struct {
+	timestamp    time.Time
+	price        float64
+	openInterest float64
+}
+
+// activityState 单个交易对的活跃度内部状态
+type activityState struct {
+	SymbolActivity
+	points []activityPoint // 按时间升序，仅保留观察窗口内的数据
+}
+
+// marketActivityDetectorImpl 市场活跃度检测器实现
+type marketActivityDetectorImpl struct {
+	rules  *MarketActivityRules
+	logger *zap.Logger
+
+	mu     sync.Mutex
+	states map[string]*activityState
+}
+
+// NewMarketActivityDetector 创建市场活跃度检测器
+func NewMarketActivityDetector(rules *MarketActivityRules, logger *zap.Logger) MarketActivityDetector {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if rules == nil {
+		rules = DefaultMarketActivityRules()
+	}
+
+	return &marketActivityDetectorImpl{
+		rules:  rules,
+		logger: logger,
+		states: make(map[string]*activityState),
+	}
+}
+
+// Process 处理单个数据点
+func (m *marketActivityDetectorImpl) Process(data *PriceData) (*MarketActivityResult, error) {
+	if data == nil {
+		return nil, fmt.Errorf("数据不能为空")
+	}
+	if data.Symbol == "" {
+		return nil, fmt.Errorf("交易对不能为空")
+	}
+	if !data.Price.IsPositive() {
+		return nil, fmt.Errorf("价格必须大于0")
+	}
+
+	result := &MarketActivityResult{
+		Symbol:       data.Symbol,
+		Timestamp:    data.Timestamp,
+		OpenInterest: data.OpenInterest.InexactFloat64(),
+		Signals:      []*MarketSignal{},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.rules.Enabled {
+		return result, nil
+	}
+
+	state, exists := m.states[data.Symbol]
+	if !exists {
+		state = &activityState{SymbolActivity: SymbolActivity{Symbol: data.Symbol}}
+		m.states[data.Symbol] = state
+	}
+
+	// 乱序数据不参与推导
+	if !state.LastTimestamp.IsZero() && !data.Timestamp.After(state.LastTimestamp) {
+		return result, nil
+	}
+
+	m.deriveIntervalVolume(state, data, result)
+	m.updateWindows(state, data, result)
+	m.buildCompositeSignals(data, result)
+
+	state.LastTimestamp = data.Timestamp
+	state.LastPrice = data.PriceFloat()
+	if data.OpenInterest.IsPositive() {
+		state.OpenInterest = data.OpenInterest.InexactFloat64()
+	}
+	state.LastSurgeRatio = result.SurgeRatio
+
+	return result, nil
+}
+
+// ProcessBatch 批量处理数据点
+func (m *marketActivityDetectorImpl) ProcessBatch(data []*PriceData) ([]*MarketActivityResult, error) {
+	results := make([]*MarketActivityResult, len(data))
+
+	for i, d := range data {
+		result, err := m.Process(d)
+		if err != nil {
+			return nil, fmt.Errorf("处理第%d个数据时出错: %w", i, err)
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+// GetSymbolActivity 获取交易对的活跃度状态
+func (m *marketActivityDetectorImpl) GetSymbolActivity(symbol string) (*SymbolActivity, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[symbol]
+	if !exists {
+		return nil, false
+	}
+	activity := state.SymbolActivity
+	return &activity, true
+}
+
+// SetRules 设置检测规则
+func (m *marketActivityDetectorImpl) SetRules(rules *MarketActivityRules) error {
+	if rules == nil {
+		return fmt.Errorf("规则不能为空")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rules = rules
+	return nil
+}
+
+// Reset 重置检测器状态
+func (m *marketActivityDetectorImpl) Reset() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.states = make(map[string]*activityState)
+	return nil
+}
+
+// deriveIntervalVolume 由24小时滚动成交额推导区间成交量并检测激增
+func (m *marketActivityDetectorImpl) deriveIntervalVolume(state *activityState, data *PriceData, result *MarketActivityResult) {
+	volume24h := quoteVolume24h(data)
+	if volume24h <= 0 {
+		return
+	}
+
+	lastVolume := state.LastVolume24h
+	lastTime := state.LastTimestamp
+	state.LastVolume24h = volume24h
+
+	if lastVolume <= 0 || lastTime.IsZero() {
+		return
+	}
+
+	interval := data.Timestamp.Sub(lastTime)
+	if interval <= 0 || (m.rules.MaxIntervalGap > 0 && interval > m.rules.MaxIntervalGap) {
+		// 间隔过长，区间成交量无法可靠推导，重新开始
+		return
+	}
+
+	// 24小时计数同时包含新成交和滚出窗口的旧成交，计数回落时只能确定新成交不小于0
+	delta := volume24h - lastVolume
+	if delta < 0 {
+		delta = 0
+		result.VolumeEstimated = true
+	}
+
+	seconds := interval.Seconds()
+	rate := delta / seconds
+
+	result.IntervalVolume = delta
+	result.IntervalSeconds = seconds
+	result.VolumeRate = rate
+	result.BaselineRate = state.BaselineRate
+
+	if state.BaselineSamples >= m.rules.MinBaselineSamples && state.BaselineRate > 0 {
+		result.SurgeRatio = rate / state.BaselineRate
+
+		if result.SurgeRatio >= m.rules.VolumeSurgeRatio && delta >= m.rules.MinIntervalVolume {
+			strength := math.Min(100, 50*result.SurgeRatio/m.rules.VolumeSurgeRatio)
+			result.Signals = append(result.Signals, &MarketSignal{
+				Symbol:    data.Symbol,
+				Type:      SignalTypeVolumeSurge,
+				Direction: SignalDirectionNeutral,
+				Strength:  strength,
+				Severity:  signalSeverity(strength),
+				Reasons:   []string{fmt.Sprintf("成交量激增: 区间成交额=%.2f, 速率为基线的%.2f倍", delta, result.SurgeRatio)},
+				Metadata: map[string]interface{}{
+					"interval_volume": delta,
+					"volume_rate":     rate,
+					"baseline_rate":   state.BaselineRate,
+					"surge_ratio":     result.SurgeRatio,
+				},
+				DetectedAt: time.Now(),
+			})
+		}
+	}
+
+	// 更新基线（激增区间也参与学习，使基线能跟随成交活跃度的趋势变化）
+	if state.BaselineSamples == 0 {
+		state.BaselineRate = rate
+	} else {
+		state.BaselineRate += m.rules.BaselineAlpha * (rate - state.BaselineRate)
+	}
+	state.BaselineSamples++
+}
+
+// updateWindows 更新观察窗口并计算价格与持仓量变化
+func (m *marketActivityDetectorImpl) updateWindows(state *activityState, data *PriceData, result *MarketActivityResult) {
+	openInterest := data.OpenInterest.InexactFloat64()
+	state.points = append(state.points, activityPoint{
+		timestamp:    data.Timestamp,
+		price:        data.PriceFloat(),
+		openInterest: openInterest,
+	})
+
+	maxWindow := m.rules.PriceMoveWindow
+	if m.rules.OpenInterestWindow > maxWindow {
+		maxWindow = m.rules.OpenInterestWindow
+	}
+	cutoff := data.Timestamp.Add(-maxWindow)
+	trim := 0
+	for trim < len(state.points)-1 && state.points[trim].timestamp.Before(cutoff) {
+		trim++
+	}
+	if trim > 0 {
+		state.points = append(state.points[:0], state.points[trim:]...)
+	}
+
+	// 价格变化
+	if ref, ok := windowReference(state.points, data.Timestamp.Add(-m.rules.PriceMoveWindow)); ok && ref.price > 0 {
+		result.PriceChange = (data.PriceFloat() - ref.price) / ref.price
+	}
+
+	// 持仓量变化
+	if openInterest <= 0 {
+		return
+	}
+	ref, ok := firstWithOpenInterest(state.points, data.Timestamp.Add(-m.rules.OpenInterestWindow))
+	if !ok {
+		return
+	}
+	result.OpenInterestChange = (openInterest - ref.openInterest) / ref.openInterest
+
+	threshold := m.rules.OpenInterestChangeThreshold
+	if threshold <= 0 || math.Abs(result.OpenInterestChange) < threshold {
+		return
+	}
+
+	signalType := SignalTypeOpenInterestSurge
+	direction := SignalDirectionUp
+	if result.OpenInterestChange < 0 {
+		signalType = SignalTypeOpenInterestDrop
+		direction = SignalDirectionDown
+	}
+	strength := math.Min(100, 50*math.Abs(result.OpenInterestChange)/threshold)
+	result.Signals = append(result.Signals, &MarketSignal{
+		Symbol:    data.Symbol,
+		Type:      signalType,
+		Direction: direction,
+		Strength:  strength,
+		Severity:  signalSeverity(strength),
+		Reasons: []string{fmt.Sprintf("持仓量%v内变化%.2f%%: %.4f -> %.4f",
+			m.rules.OpenInterestWindow, result.OpenInterestChange*100, ref.openInterest, openInterest)},
+		Metadata: map[string]interface{}{
+			"open_interest":        openInterest,
+			"open_interest_before": ref.openInterest,
+			"open_interest_change": result.OpenInterestChange,
+		},
+		DetectedAt: time.Now(),
+	})
+}
+
+// buildCompositeSignals 组合价格变动、成交量激增和持仓量变化为复合信号
+func (m *marketActivityDetectorImpl) buildCompositeSignals(data *PriceData, result *MarketActivityResult) {
+	threshold := m.rules.PriceMoveThreshold
+	if threshold <= 0 || math.Abs(result.PriceChange) < threshold {
+		return
+	}
+
+	priceUp := result.PriceChange > 0
+	direction := SignalDirectionDown
+	if priceUp {
+		direction = SignalDirectionUp
+	}
+
+	volumeSurge := result.HasSignal(SignalTypeVolumeSurge)
+	oiUp := result.HasSignal(SignalTypeOpenInterestSurge)
+	oiDown := result.HasSignal(SignalTypeOpenInterestDrop)
+
+	priceStrength := math.Min(100, 50*math.Abs(result.PriceChange)/threshold)
+	components := []float64{priceStrength}
+	for _, signal := range result.Signals {
+		components = append(components, signal.Strength)
+	}
+
+	var composites []string
+	if volumeSurge {
+		composites = append(composites, SignalTypeVolumeBreakout)
+		if oiUp && priceUp {
+			composites = append(composites, SignalTypeLongBuildup)
+		}
+		if oiUp && !priceUp {
+			composites = append(composites, SignalTypeShortBuildup)
+		}
+	}
+	if oiDown && priceUp {
+		composites = append(composites, SignalTypeShortCovering)
+	}
+	if oiDown && !priceUp {
+		composites = append(composites, SignalTypeLongLiquidation)
+	}
+
+	if len(composites) == 0 {
+		return
+	}
+
+	// 多个维度相互印证，强度取各分量均值并给予加成
+	var sum float64
+	for _, c := range components {
+		sum += c
+	}
+	strength := math.Min(100, sum/float64(len(components))+10*float64(len(components)-1))
+
+	for _, signalType := range composites {
+		result.Signals = append(result.Signals, &MarketSignal{
+			Symbol:    data.Symbol,
+			Type:      signalType,
+			Direction: direction,
+			Strength:  strength,
+			Severity:  signalSeverity(strength),
+			Composite: true,
+			Reasons: []string{fmt.Sprintf("价格%v内变化%.2f%%, 激增倍数=%.2f, 持仓量变化%.2f%%",
+				m.rules.PriceMoveWindow, result.PriceChange*100, result.SurgeRatio, result.OpenInterestChange*100)},
+			Metadata: map[string]interface{}{
+				"price_change":         result.PriceChange,
+				"surge_ratio":          result.SurgeRatio,
+				"open_interest_change": result.OpenInterestChange,
+			},
+			DetectedAt: time.Now(),
+		})
+	}
+}
+
+// quoteVolume24h 获取24小时USDT成交额，缺失时由交易币成交量折算
+func quoteVolume24h(data *PriceData) float64 {
+	if data.UsdtVolume24h.IsPositive() {
+		return data.UsdtVolume24h.InexactFloat64()
+	}
+	if data.BaseVolume24h.IsPositive() {
+		return data.BaseVolume24h.Mul(data.Price).InexactFloat64()
+	}
+	return 0
+}
+
+// windowReference 返回窗口起点之后的第一个观察点
+func windowReference(points []activityPoint, since time.Time) (activityPoint, bool) {
+	for _, p := range points {
+		if !p.timestamp.Before(since) {
+			return p, true
+		}
+	}
+	return activityPoint{}, false
+}
+
+// firstWithOpenInterest 返回窗口起点之后第一个带持仓量的观察点
+func firstWithOpenInterest(points []activityPoint, since time.Time) (activityPoint, bool) {
+	for _, p := range points {
+		if !p.timestamp.Before(since) && p.openInterest > 0 {
+			return p, true
+		}
+	}
+	return activityPoint{}, false
+}
+
+// signalSeverity 根据信号强度确定严重程度
+func signalSeverity(strength float64) string {
+	switch {
+	case strength >= 90:
+		return SeverityCritical
+	case strength >= 75:
+		return SeverityHigh
+	case strength >= 50:
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
@@ -0,0 +1,185 @@
+package data_collection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// activityFeed 按固定间隔生成带24小时成交额和持仓量的数据
+type activityFeed struct {
+	symbol       string
+	ts           time.Time
+	price        float64
+	volume24h    float64
+	openInterest float64
+}
+
+func (f *activityFeed) next(interval time.Duration, tradedUsdt, priceChange, oiChange float64) *PriceData {
+	f.ts = f.ts.Add(interval)
+	f.price *= 1 + priceChange
+	f.volume24h += tradedUsdt
+	f.openInterest *= 1 + oiChange
+	return &PriceData{
+		Symbol:        f.symbol,
+		Price:         decimal.NewFromFloat(f.price),
+		Timestamp:     f.ts,
+		UsdtVolume24h: decimal.NewFromFloat(f.volume24h),
+		OpenInterest:  decimal.NewFromFloat(f.openInterest),
+		Source:        "test",
+	}
+}
+
+// warmUp 以稳定的成交速率建立基线
+func warmUp(t *testing.T, detector MarketActivityDetector, feed *activityFeed, n int) {
+	for i := 0; i < n; i++ {
+		result, err := detector.Process(feed.next(time.Second, 10000, 0, 0))
+		require.NoError(t, err)
+		assert.Empty(t, result.Signals)
+	}
+}
+
+func TestMarketActivityDetector_VolumeSurge(t *testing.T) {
+	detector := NewMarketActivityDetector(DefaultMarketActivityRules(), zap.NewNop())
+	feed := &activityFeed{symbol: "BTCUSDT", ts: time.Now().Add(-time.Hour), price: 100, volume24h: 1e8, openInterest: 5000}
+
+	warmUp(t, detector, feed, 30)
+
+	activity, ok := detector.GetSymbolActivity("BTCUSDT")
+	require.True(t, ok)
+	assert.InDelta(t, 10000, activity.BaselineRate, 1)
+
+	t.Run("区间成交量由24小时计数差推导", func(t *testing.T) {
+		result, err := detector.Process(feed.next(2*time.Second, 20000, 0, 0))
+		require.NoError(t, err)
+		assert.InDelta(t, 20000, result.IntervalVolume, 1e-6)
+		assert.InDelta(t, 10000, result.VolumeRate, 1e-6)
+		assert.InDelta(t, 1.0, result.SurgeRatio, 0.01)
+		assert.False(t, result.HasSignal(SignalTypeVolumeSurge))
+	})
+
+	t.Run("成交速率超过基线倍数时触发激增", func(t *testing.T) {
+		result, err := detector.Process(feed.next(time.Second, 50000, 0, 0))
+		require.NoError(t, err)
+		assert.InDelta(t, 5.0, result.SurgeRatio, 0.05)
+		assert.True(t, result.HasSignal(SignalTypeVolumeSurge))
+		assert.False(t, result.HasSignal(SignalTypeVolumeBreakout), "价格未变动不应产生复合信号")
+	})
+
+	t.Run("24小时计数回落时区间成交量记为0", func(t *testing.T) {
+		result, err := detector.Process(feed.next(time.Second, -30000, 0, 0))
+		require.NoError(t, err)
+		assert.True(t, result.VolumeEstimated)
+		assert.Equal(t, 0.0, result.IntervalVolume)
+	})
+
+	t.Run("乱序数据被忽略", func(t *testing.T) {
+		result, err := detector.Process(&PriceData{
+			Symbol:        "BTCUSDT",
+			Price:         decimal.NewFromFloat(100),
+			Timestamp:     feed.ts.Add(-10 * time.Second),
+			UsdtVolume24h: decimal.NewFromFloat(feed.volume24h),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result.Signals)
+		assert.Zero(t, result.IntervalVolume)
+	})
+}
+
+func TestMarketActivityDetector_OpenInterestAndComposite(t *testing.T) {
+	newDetector := func() (MarketActivityDetector, *activityFeed) {
+		detector := NewMarketActivityDetector(DefaultMarketActivityRules(), zap.NewNop())
+		feed := &activityFeed{symbol: "ETHUSDT", ts: time.Now().Add(-time.Hour), price: 100, volume24h: 1e8, openInterest: 5000}
+		warmUp(t, detector, feed, 30)
+		return detector, feed
+	}
+
+	t.Run("放量上涨且持仓增加为多头建仓", func(t *testing.T) {
+		detector, feed := newDetector()
+		result, err := detector.Process(feed.next(time.Second, 60000, 0.02, 0.05))
+		require.NoError(t, err)
+
+		assert.True(t, result.HasSignal(SignalTypeVolumeSurge))
+		assert.True(t, result.HasSignal(SignalTypeOpenInterestSurge))
+		assert.True(t, result.HasSignal(SignalTypeVolumeBreakout))
+		assert.True(t, result.HasSignal(SignalTypeLongBuildup))
+		assert.InDelta(t, 0.02, result.PriceChange, 1e-9)
+		assert.InDelta(t, 0.05, result.OpenInterestChange, 1e-9)
+
+		for _, signal := range result.Signals {
+			if signal.Composite {
+				assert.Equal(t, SignalDirectionUp, signal.Direction)
+				assert.LessOrEqual(t, signal.Strength, 100.0)
+			}
+		}
+	})
+
+	t.Run("放量下跌且持仓增加为空头建仓", func(t *testing.T) {
+		detector, feed := newDetector()
+		result, err := detector.Process(feed.next(time.Second, 60000, -0.02, 0.05))
+		require.NoError(t, err)
+		assert.True(t, result.HasSignal(SignalTypeShortBuildup))
+		assert.False(t, result.HasSignal(SignalTypeLongBuildup))
+	})
+
+	t.Run("上涨且持仓减少为空头回补", func(t *testing.T) {
+		detector, feed := newDetector()
+		result, err := detector.Process(feed.next(time.Second, 10000, 0.02, -0.05))
+		require.NoError(t, err)
+		assert.True(t, result.HasSignal(SignalTypeOpenInterestDrop))
+		assert.True(t, result.HasSignal(SignalTypeShortCovering))
+		assert.False(t, result.HasSignal(SignalTypeVolumeBreakout))
+	})
+
+	t.Run("下跌且持仓减少为多头平仓", func(t *testing.T) {
+		detector, feed := newDetector()
+		result, err := detector.Process(feed.next(time.Second, 10000, -0.02, -0.05))
+		require.NoError(t, err)
+		assert.True(t, result.HasSignal(SignalTypeLongLiquidation))
+	})
+
+	t.Run("缓慢累积的持仓量变化在窗口内被识别", func(t *testing.T) {
+		detector, feed := newDetector()
+		var result *MarketActivityResult
+		var err error
+		for i := 0; i < 10; i++ {
+			result, err = detector.Process(feed.next(10*time.Second, 10000*10, 0, 0.004))
+			require.NoError(t, err)
+		}
+		assert.Greater(t, result.OpenInterestChange, 0.03)
+		assert.True(t, result.HasSignal(SignalTypeOpenInterestSurge))
+	})
+}
+
+func TestMarketActivityDetector_Management(t *testing.T) {
+	detector := NewMarketActivityDetector(nil, nil)
+
+	_, err := detector.Process(nil)
+	assert.Error(t, err)
+	_, err = detector.Process(&PriceData{Symbol: "BTCUSDT"})
+	assert.Error(t, err)
+	assert.Error(t, detector.SetRules(nil))
+
+	results, err := detector.ProcessBatch([]*PriceData{
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(100), Timestamp: time.Now(), BaseVolume24h: decimal.NewFromInt(1000)},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(100), Timestamp: time.Now().Add(time.Second), BaseVolume24h: decimal.NewFromInt(1010)},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.InDelta(t, 1000, results[1].IntervalVolume, 1e-6, "交易币成交量按价格折算为USDT")
+
+	require.NoError(t, detector.Reset())
+	_, ok := detector.GetSymbolActivity("BTCUSDT")
+	assert.False(t, ok)
+
+	disabled := DefaultMarketActivityRules()
+	disabled.Enabled = false
+	require.NoError(t, detector.SetRules(disabled))
+	result, err := detector.Process(&PriceData{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(100), Timestamp: time.Now()})
+	require.NoError(t, err)
+	assert.Empty(t, result.Signals)
+}
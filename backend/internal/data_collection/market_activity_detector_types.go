@@ -0,0 +1,138 @@
+package data_collection
+
+import (
+	"time"
+)
+
+// MarketActivityDetector 市场活跃度检测器接口
+// 基于24小时滚动成交量与持仓量推导区间成交量，检测成交量激增、持仓量剧变，并与价格变动组合为复合信号
+type MarketActivityDetector interface {
+	// 处理单个数据点，返回区间指标与触发的信号
+	Process(data *PriceData) (*MarketActivityResult, error)
+
+	// 批量处理数据点
+	ProcessBatch(data []*PriceData) ([]*MarketActivityResult, error)
+
+	// 获取交易对的活跃度状态
+	GetSymbolActivity(symbol string) (*SymbolActivity, bool)
+
+	// 设置检测规则
+	SetRules(rules *MarketActivityRules) error
+
+	// 重置检测器状态
+	Reset() error
+}
+
+// MarketActivityRules 市场活跃度检测规则
+type MarketActivityRules struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// 成交量激增检测
+	BaselineAlpha      float64       `json:"baseline_alpha" yaml:"baseline_alpha"`             // 成交速率基线的EWMA系数
+	MinBaselineSamples int           `json:"min_baseline_samples" yaml:"min_baseline_samples"` // 基线生效所需的最少区间数
+	VolumeSurgeRatio   float64       `json:"volume_surge_ratio" yaml:"volume_surge_ratio"`     // 成交量激增倍数（区间速率/基线速率）
+	MinIntervalVolume  float64       `json:"min_interval_volume" yaml:"min_interval_volume"`   // 最小区间成交额（USDT），低于此值不判定激增
+	MaxIntervalGap     time.Duration `json:"max_interval_gap" yaml:"max_interval_gap"`         // 最大区间间隔，超过则重新开始推导
+
+	// 持仓量变化检测
+	OpenInterestWindow          time.Duration `json:"open_interest_window" yaml:"open_interest_window"`                     // 持仓量变化观察窗口
+	OpenInterestChangeThreshold float64       `json:"open_interest_change_threshold" yaml:"open_interest_change_threshold"` // 持仓量变化率阈值 (0.05 = 5%)
+
+	// 价格变动（用于复合信号）
+	PriceMoveWindow    time.Duration `json:"price_move_window" yaml:"price_move_window"`       // 价格变动观察窗口
+	PriceMoveThreshold float64       `json:"price_move_threshold" yaml:"price_move_threshold"` // 价格变动阈值 (0.01 = 1%)
+}
+
+// MarketActivityResult 市场活跃度检测结果
+type MarketActivityResult struct {
+	Symbol    string    `json:"symbol"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// 区间成交量
+	IntervalVolume  float64 `json:"interval_volume"`  // 区间成交额（USDT）
+	IntervalSeconds float64 `json:"interval_seconds"` // 区间时长（秒）
+	VolumeRate      float64 `json:"volume_rate"`      // 区间成交速率（USDT/秒）
+	BaselineRate    float64 `json:"baseline_rate"`    // 基线成交速率（USDT/秒）
+	SurgeRatio      float64 `json:"surge_ratio"`      // 激增倍数
+	VolumeEstimated bool    `json:"volume_estimated"` // 区间成交量是否为估计值（24小时窗口滚出导致计数回落）
+
+	// 持仓量与价格
+	OpenInterest       float64 `json:"open_interest"`        // 当前持仓量
+	OpenInterestChange float64 `json:"open_interest_change"` // 窗口内持仓量变化率
+	PriceChange        float64 `json:"price_change"`         // 窗口内价格变化率
+
+	// 触发的信号
+	Signals []*MarketSignal `json:"signals"`
+}
+
+// HasSignal 是否触发了指定类型的信号
+func (r *MarketActivityResult) HasSignal(signalType string) bool {
+	for _, signal := range r.Signals {
+		if signal.Type == signalType {
+			return true
+		}
+	}
+	return false
+}
+
+// MarketSignal 市场信号
+type MarketSignal struct {
+	Symbol     string                 `json:"symbol"`
+	Type       string                 `json:"type"`      // 信号类型
+	Direction  string                 `json:"direction"` // 方向 (up, down, neutral)
+	Strength   float64                `json:"strength"`  // 信号强度 (0-100)
+	Severity   string                 `json:"severity"`  // 严重程度
+	Composite  bool                   `json:"composite"` // 是否为复合信号
+	Reasons    []string               `json:"reasons"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	DetectedAt time.Time              `json:"detected_at"`
+}
+
+// SymbolActivity 交易对活跃度状态
+type SymbolActivity struct {
+	Symbol          string    `json:"symbol"`
+	LastVolume24h   float64   `json:"last_volume_24h"`  // 最近一次24小时成交额计数
+	LastTimestamp   time.Time `json:"last_timestamp"`   // 最近一次数据时间
+	BaselineRate    float64   `json:"baseline_rate"`    // 基线成交速率（USDT/秒）
+	BaselineSamples int       `json:"baseline_samples"` // 基线已学习的区间数
+	LastSurgeRatio  float64   `json:"last_surge_ratio"` // 最近一次激增倍数
+	OpenInterest    float64   `json:"open_interest"`    // 最近一次持仓量
+	LastPrice       float64   `json:"last_price"`       // 最近一次价格
+}
+
+// 市场信号类型常量
+const (
+	SignalTypeVolumeSurge       = "volume_surge"        // 成交量激增
+	SignalTypeOpenInterestSurge = "open_interest_surge" // 持仓量激增
+	SignalTypeOpenInterestDrop  = "open_interest_drop"  // 持仓量骤降
+
+	// 复合信号
+	SignalTypeVolumeBreakout  = "volume_breakout"  // 放量突破（价格变动 + 成交量激增）
+	SignalTypeLongBuildup     = "long_buildup"     // 多头建仓（价涨 + 持仓增 + 放量）
+	SignalTypeShortBuildup    = "short_buildup"    // 空头建仓（价跌 + 持仓增 + 放量）
+	SignalTypeShortCovering   = "short_covering"   // 空头回补（价涨 + 持仓减）
+	SignalTypeLongLiquidation = "long_liquidation" // 多头平仓（价跌 + 持仓减）
+)
+
+// 信号方向常量
+const (
+	SignalDirectionUp      = "up"
+	SignalDirectionDown    = "down"
+	SignalDirectionNeutral = "neutral"
+)
+
+// DefaultMarketActivityRules 返回默认的市场活跃度检测规则
+func DefaultMarketActivityRules() *MarketActivityRules {
+	return &MarketActivityRules{
+		Enabled:                     true,
+		BaselineAlpha:               0.05,
+		MinBaselineSamples:          20,
+		VolumeSurgeRatio:            3.0,
+		MinIntervalVolume:           1000,
+		MaxIntervalGap:              2 * time.Minute,
+		OpenInterestWindow:          5 * time.Minute,
+		OpenInterestChangeThreshold: 0.03, // 3%
+		PriceMoveWindow:             5 * time.Minute,
+		PriceMoveThreshold:          0.01, // 1%
+	}
+}
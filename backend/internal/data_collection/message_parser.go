@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
@@ -47,7 +48,7 @@ func (p *jsonMessageParser) ParseMessage(data []byte) (*PriceData, error) {
 		return nil, fmt.Errorf("提取交易对失败: %w", err)
 	}
 
-	price, err := p.extractFloat64(rawData, p.config.PriceFields)
+	price, err := p.extractDecimal(rawData, p.config.PriceFields)
 	if err != nil {
 		return nil, fmt.Errorf("提取价格失败: %w", err)
 	}
@@ -161,7 +162,7 @@ func (p *jsonMessageParser) parseSingleItem(item map[string]interface{}) (*Price
 		return nil, err
 	}
 
-	price, err := p.extractFloat64(item, p.config.PriceFields)
+	price, err := p.extractDecimal(item, p.config.PriceFields)
 	if err != nil {
 		return nil, err
 	}
@@ -202,25 +203,25 @@ func (p *jsonMessageParser) extractString(data map[string]interface{}, fields []
 	return "", fmt.Errorf("未找到字段: %v", fields)
 }
 
-// extractFloat64 提取浮点数字段
-func (p *jsonMessageParser) extractFloat64(data map[string]interface{}, fields []string) (float64, error) {
+// extractDecimal 提取数值字段，字符串按十进制精确解析
+func (p *jsonMessageParser) extractDecimal(data map[string]interface{}, fields []string) (decimal.Decimal, error) {
 	for _, field := range fields {
 		if value, exists := data[field]; exists {
 			switch v := value.(type) {
 			case float64:
-				return v, nil
+				return decimal.NewFromFloat(v), nil
 			case string:
-				if f, err := strconv.ParseFloat(v, 64); err == nil {
-					return f, nil
+				if d, err := decimal.NewFromString(v); err == nil {
+					return d, nil
 				}
 			case int:
-				return float64(v), nil
+				return decimal.NewFromInt(int64(v)), nil
 			case int64:
-				return float64(v), nil
+				return decimal.NewFromInt(v), nil
 			}
 		}
 	}
-	return 0, fmt.Errorf("未找到数值字段: %v", fields)
+	return decimal.Zero, fmt.Errorf("未找到数值字段: %v", fields)
 }
 
 // extractTime 提取时间字段
@@ -282,21 +283,21 @@ func (p *jsonMessageParser) extractStringOrDefault(data map[string]interface{},
 }
 
 // validatePriceData 验证价格数据
-func (p *jsonMessageParser) validatePriceData(symbol string, price float64, timestamp time.Time) error {
+func (p *jsonMessageParser) validatePriceData(symbol string, price decimal.Decimal, timestamp time.Time) error {
 	// 验证交易对
 	if symbol == "" {
 		return fmt.Errorf("交易对不能为空")
 	}
 
 	// 验证价格
-	if price <= 0 {
+	if !price.IsPositive() {
 		return fmt.Errorf("价格必须大于0")
 	}
-	if price < p.config.MinPrice {
-		return fmt.Errorf("价格 %f 小于最小值 %f", price, p.config.MinPrice)
+	if price.LessThan(decimal.NewFromFloat(p.config.MinPrice)) {
+		return fmt.Errorf("价格 %s 小于最小值 %f", price, p.config.MinPrice)
 	}
-	if price > p.config.MaxPrice {
-		return fmt.Errorf("价格 %f 大于最大值 %f", price, p.config.MaxPrice)
+	if price.GreaterThan(decimal.NewFromFloat(p.config.MaxPrice)) {
+		return fmt.Errorf("价格 %s 大于最大值 %f", price, p.config.MaxPrice)
 	}
 
 	// 验证时间戳
@@ -311,13 +312,7 @@ func (p *jsonMessageParser) validatePriceData(symbol string, price float64, time
 }
 
 // cleanPrice 清洗价格数据
-func (p *jsonMessageParser) cleanPrice(price float64) float64 {
-	// 应用精度限制
-	multiplier := float64(1)
-	for i := 0; i < p.config.PricePrecision; i++ {
-		multiplier *= 10
-	}
-
-	cleaned := float64(int64(price*multiplier)) / multiplier
-	return cleaned
+func (p *jsonMessageParser) cleanPrice(price decimal.Decimal) decimal.Decimal {
+	// 应用精度限制，超出精度的部分截断
+	return price.Truncate(int32(p.config.PricePrecision))
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -588,9 +589,9 @@ func generateTestPriceData(size int) []*PriceData {
 	for i := 0; i < size; i++ {
 		data[i] = &PriceData{
 			Symbol:    fmt.Sprintf("BTCUSDT_%d", i%10),
-			Price:     basePrice + float64(i)*0.01 + rand.Float64()*10,
+			Price:     decimal.NewFromFloat(basePrice + float64(i)*0.01 + rand.Float64()*10),
 			Timestamp: baseTime.Add(time.Duration(i) * time.Minute),
-			Volume:    1000.0 + rand.Float64()*5000,
+			Volume:    decimal.NewFromFloat(1000.0 + rand.Float64()*5000),
 			Source:    "test",
 		}
 	}
@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
@@ -16,8 +17,13 @@ type priceProcessorImpl struct {
 	logger *zap.Logger
 
 	// 数据存储
-	priceHistory map[string][]*PriceData                             // 按交易对存储价格历史
-	changeRates  map[string]map[TimeWindow]*ProcessedPriceChangeRate // 按交易对和时间窗口存储变化率
+	timeWindows []TimeWindow                                        // 当前计算的时间窗口
+	series      map[string]*priceWindowSeries                       // 按交易对存储多窗口价格序列
+	changeRates map[string]map[TimeWindow]*ProcessedPriceChangeRate // 按交易对和时间窗口存储变化率
+
+	// 变化率持久化
+	persistence   AsyncPersistence
+	lastPersisted map[string]map[TimeWindow]time.Time // 按交易对和时间窗口记录最近一次持久化的数据时间
 
 	// 状态管理
 	mu             sync.RWMutex
@@ -36,6 +42,11 @@ type priceProcessorImpl struct {
 
 // NewPriceProcessor 创建新的价格处理器
 func NewPriceProcessor(config *ProcessorConfig, logger *zap.Logger) PriceProcessor {
+	return NewPriceProcessorWithPersistence(config, nil, logger)
+}
+
+// NewPriceProcessorWithPersistence 创建将变化率按采样间隔提交到异步持久化的价格处理器
+func NewPriceProcessorWithPersistence(config *ProcessorConfig, persistence AsyncPersistence, logger *zap.Logger) PriceProcessor {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
@@ -43,11 +54,20 @@ func NewPriceProcessor(config *ProcessorConfig, logger *zap.Logger) PriceProcess
 		config = DefaultProcessorConfig()
 	}
 
+	windows := config.TimeWindows
+	if err := validateTimeWindows(windows); err != nil {
+		logger.Warn("时间窗口配置无效，使用默认时间窗口", zap.Error(err))
+		windows = DefaultProcessorConfig().TimeWindows
+	}
+
 	return &priceProcessorImpl{
-		config:       config,
-		logger:       logger,
-		priceHistory: make(map[string][]*PriceData),
-		changeRates:  make(map[string]map[TimeWindow]*ProcessedPriceChangeRate),
+		config:        config,
+		logger:        logger,
+		timeWindows:   append([]TimeWindow(nil), windows...),
+		series:        make(map[string]*priceWindowSeries),
+		changeRates:   make(map[string]map[TimeWindow]*ProcessedPriceChangeRate),
+		persistence:   persistence,
+		lastPersisted: make(map[string]map[TimeWindow]time.Time),
 	}
 }
 
@@ -114,21 +134,20 @@ func (p *priceProcessorImpl) cleanupWorker() {
 }
 
 // cleanupOldData 清理旧数据
+// 窗口外的数据在写入时已被淘汰，这里只释放长时间没有新数据的交易对
 func (p *priceProcessorImpl) cleanupOldData() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	cutoffTime := time.Now().Add(-p.config.DataRetention)
 
-	for symbol, history := range p.priceHistory {
-		// 清理旧的价格历史
-		var newHistory []*PriceData
-		for _, price := range history {
-			if price.Timestamp.After(cutoffTime) {
-				newHistory = append(newHistory, price)
-			}
+	for symbol, series := range p.series {
+		last, ok := series.last()
+		if !ok || last.timestamp.Before(cutoffTime) {
+			delete(p.series, symbol)
+			delete(p.changeRates, symbol)
+			delete(p.lastPersisted, symbol)
 		}
-		p.priceHistory[symbol] = newHistory
 	}
 }
 
@@ -143,7 +162,7 @@ func (p *priceProcessorImpl) ProcessPrice(price *PriceData) error {
 		p.errorCount.Add(1)
 		p.logger.Error("价格数据验证失败",
 			zap.String("symbol", price.Symbol),
-			zap.Float64("price", price.Price),
+			zap.String("price", price.Price.String()),
 			zap.Time("timestamp", price.Timestamp),
 			zap.String("source", price.Source),
 		)
@@ -159,25 +178,33 @@ func (p *priceProcessorImpl) ProcessPrice(price *PriceData) error {
 		p.anomalyCount.Add(1)
 		p.logger.Warn("检测到异常价格数据",
 			zap.String("symbol", cleanedPrice.Symbol),
-			zap.Float64("price", cleanedPrice.Price),
+			zap.String("price", cleanedPrice.Price.String()),
 			zap.Time("timestamp", cleanedPrice.Timestamp),
 		)
 	}
 
-	// 存储价格历史
-	p.mu.Lock()
-	if p.priceHistory[cleanedPrice.Symbol] == nil {
-		p.priceHistory[cleanedPrice.Symbol] = make([]*PriceData, 0)
-	}
-	p.priceHistory[cleanedPrice.Symbol] = append(p.priceHistory[cleanedPrice.Symbol], cleanedPrice)
-	p.mu.Unlock()
-
-	// 计算变化率
-	err := p.calculateChangeRates(cleanedPrice.Symbol)
+	// 写入价格序列并计算变化率
+	accepted, pending, err := p.appendAndCalculate(cleanedPrice)
 	if err != nil {
 		p.errorCount.Add(1)
 		return fmt.Errorf("计算变化率失败: %w", err)
 	}
+	if !accepted {
+		p.logger.Debug("忽略乱序价格数据",
+			zap.String("symbol", cleanedPrice.Symbol),
+			zap.Time("timestamp", cleanedPrice.Timestamp),
+		)
+	}
+
+	// 提交变化率持久化，失败不影响实时计算
+	if len(pending) > 0 {
+		if err := p.persistence.SubmitBatch(pending); err != nil {
+			p.logger.Warn("提交变化率持久化失败",
+				zap.String("symbol", cleanedPrice.Symbol),
+				zap.Error(err),
+			)
+		}
+	}
 
 	p.processedCount.Add(1)
 	p.lastProcessed = time.Now()
@@ -247,7 +274,7 @@ func (p *priceProcessorImpl) ValidateData(price *PriceData) bool {
 	if price.Symbol == "" {
 		return false
 	}
-	if price.Price <= 0 {
+	if !price.Price.IsPositive() {
 		return false
 	}
 	if price.Timestamp.After(time.Now()) {
@@ -262,16 +289,19 @@ func (p *priceProcessorImpl) ValidateData(price *PriceData) bool {
 // DetectAnomaly 检测异常数据
 func (p *priceProcessorImpl) DetectAnomaly(price *PriceData) bool {
 	p.mu.RLock()
-	history := p.priceHistory[price.Symbol]
+	var last pricePoint
+	var ok bool
+	if series := p.series[price.Symbol]; series != nil {
+		last, ok = series.last()
+	}
 	p.mu.RUnlock()
 
-	if len(history) == 0 {
+	if !ok {
 		return false // 第一个数据点不算异常
 	}
 
 	// 获取最近的价格进行比较
-	lastPrice := history[len(history)-1].Price
-	changeRate := calculateChangeRate(lastPrice, price.Price)
+	changeRate := calculateChangeRate(last.price, price.PriceFloat())
 
 	// 检查是否超过异常阈值
 	return changeRate > p.config.AnomalyThreshold || changeRate < -p.config.AnomalyThreshold
@@ -293,56 +323,114 @@ func (p *priceProcessorImpl) CleanData(price *PriceData) *PriceData {
 	}
 
 	// 确保价格为正数
-	if cleaned.Price <= 0 {
-		cleaned.Price = 0.01 // 设置一个很小的正数
+	if !cleaned.Price.IsPositive() {
+		cleaned.Price = decimal.RequireFromString("0.01") // 设置一个很小的正数
 	}
 
 	return cleaned
 }
 
-// calculateChangeRates 计算变化率
-func (p *priceProcessorImpl) calculateChangeRates(symbol string) error {
+// SetTimeWindows 设置计算的时间窗口
+func (p *priceProcessorImpl) SetTimeWindows(windows []TimeWindow) error {
+	if err := validateTimeWindows(windows); err != nil {
+		return fmt.Errorf("设置时间窗口失败: %w", err)
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	history := p.priceHistory[symbol]
-	if len(history) == 0 {
-		return fmt.Errorf("没有价格历史数据")
+	p.timeWindows = append([]TimeWindow(nil), windows...)
+	for symbol, series := range p.series {
+		series.setWindows(p.timeWindows)
+		p.changeRates[symbol] = make(map[TimeWindow]*ProcessedPriceChangeRate)
+		p.storeChangeRates(symbol, series)
 	}
 
-	// 初始化变化率存储
-	if p.changeRates[symbol] == nil {
-		p.changeRates[symbol] = make(map[TimeWindow]*ProcessedPriceChangeRate)
+	p.logger.Info("价格处理器时间窗口已更新", zap.Any("time_windows", windows))
+	return nil
+}
+
+// GetTimeWindows 获取当前计算的时间窗口
+func (p *priceProcessorImpl) GetTimeWindows() []TimeWindow {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]TimeWindow(nil), p.timeWindows...)
+}
+
+// appendAndCalculate 写入价格序列并计算各时间窗口的变化率，同时返回待持久化的变化率
+// 早于该交易对最新数据的乱序数据不参与计算，返回 false
+func (p *priceProcessorImpl) appendAndCalculate(price *PriceData) (bool, []*PersistenceItem, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	series := p.series[price.Symbol]
+	if series == nil {
+		series = newPriceWindowSeries(p.timeWindows)
+		p.series[price.Symbol] = series
+	}
+	if !series.push(price.Timestamp, price.PriceFloat()) {
+		return false, nil, nil
 	}
 
-	currentPriceData := history[len(history)-1]
-	currentTime := currentPriceData.Timestamp
-	currentPrice := currentPriceData.Price
+	if p.changeRates[price.Symbol] == nil {
+		p.changeRates[price.Symbol] = make(map[TimeWindow]*ProcessedPriceChangeRate)
+	}
+	if err := p.storeChangeRates(price.Symbol, series); err != nil {
+		return true, nil, err
+	}
+	return true, p.collectPersistenceItems(price.Symbol), nil
+}
 
-	// 为每个时间窗口计算变化率
-	for _, window := range p.config.TimeWindows {
-		startTime := getWindowStartTime(currentTime, window)
+// collectPersistenceItems 按采样间隔挑选需要持久化的变化率，调用方需持有写锁
+func (p *priceProcessorImpl) collectPersistenceItems(symbol string) []*PersistenceItem {
+	if p.persistence == nil {
+		return nil
+	}
 
-		// 查找时间窗口内的起始价格（时间窗口内最早的数据点）
-		var startPrice float64
-		var found bool
+	last := p.lastPersisted[symbol]
+	if last == nil {
+		last = make(map[TimeWindow]time.Time)
+		p.lastPersisted[symbol] = last
+	}
 
-		// 从最早的数据开始查找，找到时间窗口内的第一个数据点
-		for i := 0; i < len(history); i++ {
-			if history[i].Timestamp.After(startTime) || history[i].Timestamp.Equal(startTime) {
-				startPrice = history[i].Price
-				found = true
-				break
-			}
+	var items []*PersistenceItem
+	for window, rate := range p.changeRates[symbol] {
+		if !rate.IsValid {
+			continue
 		}
+		if persistedAt, ok := last[window]; ok && rate.Timestamp.Sub(persistedAt) < p.config.PersistInterval {
+			continue
+		}
+		last[window] = rate.Timestamp
+
+		snapshot := *rate
+		items = append(items, &PersistenceItem{
+			ID:        fmt.Sprintf("changerate:%s:%s:%d", symbol, window, rate.Timestamp.UnixNano()),
+			Type:      "changerate",
+			Data:      &snapshot,
+			Timestamp: time.Now(),
+			Priority:  5,
+		})
+	}
+	return items
+}
+
+// storeChangeRates 根据价格序列计算并保存变化率，调用方需持有写锁
+func (p *priceProcessorImpl) storeChangeRates(symbol string, series *priceWindowSeries) error {
+	current, ok := series.last()
+	if !ok {
+		return fmt.Errorf("没有价格历史数据")
+	}
 
-		if !found {
-			// 如果没有找到时间窗口内的数据，使用当前价格
-			startPrice = currentPrice
+	// 为每个时间窗口计算变化率，窗口起始价格为窗口内最早的数据点
+	for _, cursor := range series.cursors {
+		snapshot, ok := series.snapshot(cursor)
+		if !ok {
+			continue
 		}
 
 		// 计算变化率
-		changeRate := calculateChangeRate(startPrice, currentPrice)
+		changeRate := calculateChangeRate(snapshot.startPrice, current.price)
 
 		// 检查数据有效性
 		isValid := p.validateChangeRate(changeRate)
@@ -351,18 +439,18 @@ func (p *priceProcessorImpl) calculateChangeRates(symbol string) error {
 		isAnomaly := changeRate > p.config.AnomalyThreshold || changeRate < -p.config.AnomalyThreshold
 
 		// 创建变化率记录
-		rate := &ProcessedPriceChangeRate{
+		p.changeRates[symbol][cursor.window] = &ProcessedPriceChangeRate{
 			Symbol:     symbol,
-			TimeWindow: string(window),
+			TimeWindow: string(cursor.window),
 			ChangeRate: changeRate,
-			StartPrice: startPrice,
-			EndPrice:   currentPrice,
-			Timestamp:  currentTime,
+			StartPrice: snapshot.startPrice,
+			EndPrice:   current.price,
+			HighPrice:  snapshot.highPrice,
+			LowPrice:   snapshot.lowPrice,
+			Timestamp:  current.timestamp,
 			IsValid:    isValid,
 			IsAnomaly:  isAnomaly,
 		}
-
-		p.changeRates[symbol][window] = rate
 	}
 
 	return nil
@@ -381,16 +469,3 @@ func calculateChangeRate(startPrice, endPrice float64) float64 {
 	}
 	return ((endPrice - startPrice) / startPrice) * 100
 }
-
-func getWindowStartTime(timestamp time.Time, window TimeWindow) time.Time {
-	switch window {
-	case TimeWindow1m:
-		return timestamp.Add(-1 * time.Minute)
-	case TimeWindow5m:
-		return timestamp.Add(-5 * time.Minute)
-	case TimeWindow15m:
-		return timestamp.Add(-15 * time.Minute)
-	default:
-		return timestamp.Add(-1 * time.Minute)
-	}
-}
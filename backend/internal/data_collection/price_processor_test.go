@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -17,18 +18,18 @@ func TestPriceChangeRateCalculation(t *testing.T) {
 	// 创建测试数据
 	baseTime := time.Now()
 	prices := []*PriceData{
-		{Symbol: "BTCUSDT", Price: 50000.0, Timestamp: baseTime, Source: "test"},
-		{Symbol: "BTCUSDT", Price: 51000.0, Timestamp: baseTime.Add(1 * time.Minute), Source: "test"},
-		{Symbol: "BTCUSDT", Price: 52000.0, Timestamp: baseTime.Add(2 * time.Minute), Source: "test"},
-		{Symbol: "BTCUSDT", Price: 53000.0, Timestamp: baseTime.Add(3 * time.Minute), Source: "test"},
-		{Symbol: "BTCUSDT", Price: 54000.0, Timestamp: baseTime.Add(4 * time.Minute), Source: "test"},
-		{Symbol: "BTCUSDT", Price: 55000.0, Timestamp: baseTime.Add(5 * time.Minute), Source: "test"},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0), Timestamp: baseTime, Source: "test"},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(51000.0), Timestamp: baseTime.Add(1 * time.Minute), Source: "test"},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(52000.0), Timestamp: baseTime.Add(2 * time.Minute), Source: "test"},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(53000.0), Timestamp: baseTime.Add(3 * time.Minute), Source: "test"},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(54000.0), Timestamp: baseTime.Add(4 * time.Minute), Source: "test"},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(55000.0), Timestamp: baseTime.Add(5 * time.Minute), Source: "test"},
 	}
 
 	// 测试1分钟变化率计算
 	t.Run("1分钟变化率计算", func(t *testing.T) {
-		startPrice := prices[0].Price
-		endPrice := prices[1].Price
+		startPrice := prices[0].PriceFloat()
+		endPrice := prices[1].PriceFloat()
 		expectedRate := ((endPrice - startPrice) / startPrice) * 100
 
 		changeRate := calculateChangeRate(startPrice, endPrice)
@@ -38,8 +39,8 @@ func TestPriceChangeRateCalculation(t *testing.T) {
 
 	// 测试5分钟变化率计算
 	t.Run("5分钟变化率计算", func(t *testing.T) {
-		startPrice := prices[0].Price
-		endPrice := prices[5].Price
+		startPrice := prices[0].PriceFloat()
+		endPrice := prices[5].PriceFloat()
 		expectedRate := ((endPrice - startPrice) / startPrice) * 100
 
 		changeRate := calculateChangeRate(startPrice, endPrice)
@@ -73,7 +74,7 @@ func TestPriceDataValidation(t *testing.T) {
 	t.Run("有效数据验证", func(t *testing.T) {
 		validPrice := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     50000.0,
+			Price:     decimal.NewFromFloat(50000.0),
 			Timestamp: time.Now(),
 			Source:    "test",
 		}
@@ -91,7 +92,7 @@ func TestPriceDataValidation(t *testing.T) {
 				name: "空符号",
 				price: &PriceData{
 					Symbol:    "",
-					Price:     50000.0,
+					Price:     decimal.NewFromFloat(50000.0),
 					Timestamp: time.Now(),
 					Source:    "test",
 				},
@@ -100,7 +101,7 @@ func TestPriceDataValidation(t *testing.T) {
 				name: "负价格",
 				price: &PriceData{
 					Symbol:    "BTCUSDT",
-					Price:     -1000.0,
+					Price:     decimal.NewFromFloat(-1000.0),
 					Timestamp: time.Now(),
 					Source:    "test",
 				},
@@ -109,7 +110,7 @@ func TestPriceDataValidation(t *testing.T) {
 				name: "零价格",
 				price: &PriceData{
 					Symbol:    "BTCUSDT",
-					Price:     0.0,
+					Price:     decimal.Zero,
 					Timestamp: time.Now(),
 					Source:    "test",
 				},
@@ -118,7 +119,7 @@ func TestPriceDataValidation(t *testing.T) {
 				name: "未来时间戳",
 				price: &PriceData{
 					Symbol:    "BTCUSDT",
-					Price:     50000.0,
+					Price:     decimal.NewFromFloat(50000.0),
 					Timestamp: time.Now().Add(1 * time.Hour),
 					Source:    "test",
 				},
@@ -127,7 +128,7 @@ func TestPriceDataValidation(t *testing.T) {
 				name: "空数据源",
 				price: &PriceData{
 					Symbol:    "BTCUSDT",
-					Price:     50000.0,
+					Price:     decimal.NewFromFloat(50000.0),
 					Timestamp: time.Now(),
 					Source:    "",
 				},
@@ -150,7 +151,7 @@ func TestAnomalyDetection(t *testing.T) {
 	t.Run("正常价格变化", func(t *testing.T) {
 		normalPrice := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     50000.0,
+			Price:     decimal.NewFromFloat(50000.0),
 			Timestamp: time.Now(),
 			Source:    "test",
 		}
@@ -162,7 +163,7 @@ func TestAnomalyDetection(t *testing.T) {
 	t.Run("异常价格变化", func(t *testing.T) {
 		anomalyPrice := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     60000.0, // 20% 变化，超过10%阈值
+			Price:     decimal.NewFromFloat(60000.0), // 20% 变化，超过10%阈值
 			Timestamp: time.Now(),
 			Source:    "test",
 		}
@@ -174,7 +175,7 @@ func TestAnomalyDetection(t *testing.T) {
 	t.Run("边界情况", func(t *testing.T) {
 		boundaryPrice := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     55000.0, // 10% 变化，刚好等于阈值
+			Price:     decimal.NewFromFloat(55000.0), // 10% 变化，刚好等于阈值
 			Timestamp: time.Now(),
 			Source:    "test",
 		}
@@ -209,7 +210,7 @@ func TestPriceProcessorInterface(t *testing.T) {
 	// 测试单个价格处理
 	price := &PriceData{
 		Symbol:    "BTCUSDT",
-		Price:     50000.0,
+		Price:     decimal.NewFromFloat(50000.0),
 		Timestamp: time.Now(),
 		Source:    "test",
 	}
@@ -219,8 +220,8 @@ func TestPriceProcessorInterface(t *testing.T) {
 
 	// 测试批量处理
 	prices := []*PriceData{
-		{Symbol: "BTCUSDT", Price: 50000.0, Timestamp: time.Now(), Source: "test"},
-		{Symbol: "ETHUSDT", Price: 3000.0, Timestamp: time.Now(), Source: "test"},
+		{Symbol: "BTCUSDT", Price: decimal.NewFromFloat(50000.0), Timestamp: time.Now(), Source: "test"},
+		{Symbol: "ETHUSDT", Price: decimal.NewFromFloat(3000.0), Timestamp: time.Now(), Source: "test"},
 	}
 
 	err = processor.ProcessBatch(prices)
@@ -235,7 +236,7 @@ func validatePriceDataTest(price *PriceData) bool {
 	if price.Symbol == "" {
 		return false
 	}
-	if price.Price <= 0 {
+	if !price.Price.IsPositive() {
 		return false
 	}
 	if price.Timestamp.After(time.Now()) {
@@ -251,7 +252,7 @@ func detectAnomalyTest(currentPrice *PriceData, previousPrice float64, threshold
 	if previousPrice == 0 {
 		return false
 	}
-	changeRate := calculateChangeRateTest(previousPrice, currentPrice.Price)
+	changeRate := calculateChangeRateTest(previousPrice, currentPrice.PriceFloat())
 	return changeRate > threshold || changeRate < -threshold
 }
 
@@ -1,38 +1,109 @@
 package data_collection
 
 import (
+	"fmt"
+	"sort"
 	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
 )
 
 // ProcessedPriceData 处理后的价格数据结构
 type ProcessedPriceData struct {
-	Symbol    string    `json:"symbol"`    // 交易对符号
-	Price     float64   `json:"price"`     // 价格
-	Timestamp time.Time `json:"timestamp"` // 时间戳
-	Source    string    `json:"source"`    // 数据源
+	Symbol    string          `json:"symbol"`    // 交易对符号
+	Price     decimal.Decimal `json:"price"`     // 价格
+	Timestamp time.Time       `json:"timestamp"` // 时间戳
+	Source    string          `json:"source"`    // 数据源
 }
 
 // ProcessedPriceChangeRate 处理后的价格变化率
 type ProcessedPriceChangeRate struct {
 	Symbol     string    `json:"symbol"`      // 交易对符号
-	TimeWindow string    `json:"time_window"` // 时间窗口 (1m, 5m, 15m)
+	TimeWindow string    `json:"time_window"` // 时间窗口 (10s, 1m, 5m, 4h ...)
 	ChangeRate float64   `json:"change_rate"` // 变化率 (百分比)
 	StartPrice float64   `json:"start_price"` // 起始价格
 	EndPrice   float64   `json:"end_price"`   // 结束价格
+	HighPrice  float64   `json:"high_price"`  // 窗口内最高价
+	LowPrice   float64   `json:"low_price"`   // 窗口内最低价
 	Timestamp  time.Time `json:"timestamp"`   // 计算时间戳
 	IsValid    bool      `json:"is_valid"`    // 数据是否有效
 	IsAnomaly  bool      `json:"is_anomaly"`  // 是否为异常数据
 }
 
+// ToModel 转换为价格变化率数据模型
+func (r *ProcessedPriceChangeRate) ToModel() *models.PriceChangeRate {
+	return &models.PriceChangeRate{
+		Symbol:      r.Symbol,
+		Timestamp:   r.Timestamp,
+		WindowSize:  r.TimeWindow,
+		ChangeRate:  r.ChangeRate,
+		PriceBefore: r.StartPrice,
+		PriceAfter:  r.EndPrice,
+	}
+}
+
 // TimeWindow 时间窗口类型
+// 格式为正整数 + 单位（s、m、h、d），除下列常用窗口外可配置任意长度
 type TimeWindow string
 
 const (
+	TimeWindow10s TimeWindow = "10s"
+	TimeWindow30s TimeWindow = "30s"
 	TimeWindow1m  TimeWindow = "1m"
 	TimeWindow5m  TimeWindow = "5m"
 	TimeWindow15m TimeWindow = "15m"
+	TimeWindow1h  TimeWindow = "1h"
+	TimeWindow4h  TimeWindow = "4h"
+	TimeWindow24h TimeWindow = "24h"
 )
 
+// Duration 解析时间窗口时长
+func (w TimeWindow) Duration() (time.Duration, error) {
+	return models.ParseTimeWindow(string(w))
+}
+
+// ParseTimeWindows 解析并合并时间窗口列表（如多个监控配置的 filters.time_windows）
+// 结果去重并按时长升序排列
+func ParseTimeWindows(windows ...[]string) ([]TimeWindow, error) {
+	durations := make(map[TimeWindow]time.Duration)
+	for _, list := range windows {
+		for _, window := range list {
+			duration, err := TimeWindow(window).Duration()
+			if err != nil {
+				return nil, err
+			}
+			durations[TimeWindow(window)] = duration
+		}
+	}
+
+	result := make([]TimeWindow, 0, len(durations))
+	for window := range durations {
+		result = append(result, window)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if durations[result[i]] != durations[result[j]] {
+			return durations[result[i]] < durations[result[j]]
+		}
+		return result[i] < result[j]
+	})
+	return result, nil
+}
+
+// validateTimeWindows 校验时间窗口配置
+func validateTimeWindows(windows []TimeWindow) error {
+	if len(windows) == 0 {
+		return fmt.Errorf("时间窗口不能为空")
+	}
+	for _, window := range windows {
+		if _, err := window.Duration(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // PriceProcessor 价格处理器接口
 type PriceProcessor interface {
 	// ProcessPrice 处理单个价格数据
@@ -55,6 +126,12 @@ type PriceProcessor interface {
 
 	// CleanData 清洗数据
 	CleanData(price *PriceData) *PriceData
+
+	// SetTimeWindows 设置计算的时间窗口（可在运行中调整，已有历史数据会用于重建新窗口）
+	SetTimeWindows(windows []TimeWindow) error
+
+	// GetTimeWindows 获取当前计算的时间窗口
+	GetTimeWindows() []TimeWindow
 }
 
 // ProcessorConfig 处理器配置
@@ -62,8 +139,9 @@ type ProcessorConfig struct {
 	TimeWindows      []TimeWindow  `json:"time_windows" yaml:"time_windows"`           // 支持的时间窗口
 	MaxPriceChange   float64       `json:"max_price_change" yaml:"max_price_change"`   // 最大价格变化率阈值
 	AnomalyThreshold float64       `json:"anomaly_threshold" yaml:"anomaly_threshold"` // 异常检测阈值
-	DataRetention    time.Duration `json:"data_retention" yaml:"data_retention"`       // 数据保留时间
+	DataRetention    time.Duration `json:"data_retention" yaml:"data_retention"`       // 交易对无数据超过该时间后释放其状态
 	CleanupInterval  time.Duration `json:"cleanup_interval" yaml:"cleanup_interval"`   // 清理间隔
+	PersistInterval  time.Duration `json:"persist_interval" yaml:"persist_interval"`   // 每个交易对每个窗口的变化率持久化采样间隔
 }
 
 // DefaultProcessorConfig 返回默认的处理器配置
@@ -74,6 +152,7 @@ func DefaultProcessorConfig() *ProcessorConfig {
 		AnomalyThreshold: 10.0,           // 10% 异常检测阈值
 		DataRetention:    24 * time.Hour, // 24小时数据保留
 		CleanupInterval:  time.Hour,      // 1小时清理间隔
+		PersistInterval:  10 * time.Second,
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -237,10 +238,10 @@ func TestRedisCache_DataConsistency(t *testing.T) {
 		// 创建价格数据
 		price := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     50000.0,
-			BidPrice:  49999.0,
-			AskPrice:  50001.0,
-			Volume:    1000.0,
+			Price:     decimal.NewFromFloat(50000.0),
+			BidPrice:  decimal.NewFromFloat(49999.0),
+			AskPrice:  decimal.NewFromFloat(50001.0),
+			Volume:    decimal.NewFromFloat(1000.0),
 			Timestamp: time.Now(),
 			Source:    "test",
 			Latency:   10 * time.Millisecond,
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -160,10 +161,10 @@ func TestRedisCache_Performance(t *testing.T) {
 		for i := 0; i < operations; i++ {
 			price := &PriceData{
 				Symbol:    fmt.Sprintf("BTCUSDT_%d", i),
-				Price:     50000.0 + float64(i),
-				BidPrice:  49999.0 + float64(i),
-				AskPrice:  50001.0 + float64(i),
-				Volume:    1000.0 + float64(i),
+				Price:     decimal.NewFromFloat(50000.0 + float64(i)),
+				BidPrice:  decimal.NewFromFloat(49999.0 + float64(i)),
+				AskPrice:  decimal.NewFromFloat(50001.0 + float64(i)),
+				Volume:    decimal.NewFromFloat(1000.0 + float64(i)),
 				Timestamp: time.Now(),
 				Source:    "test",
 				Latency:   10 * time.Millisecond,
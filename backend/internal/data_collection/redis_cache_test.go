@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -191,10 +192,10 @@ func TestRedisCache_PriceDataOperations(t *testing.T) {
 	t.Run("设置和获取价格数据", func(t *testing.T) {
 		price := &PriceData{
 			Symbol:    "BTCUSDT",
-			Price:     50000.0,
-			BidPrice:  49999.0,
-			AskPrice:  50001.0,
-			Volume:    1000.0,
+			Price:     decimal.NewFromFloat(50000.0),
+			BidPrice:  decimal.NewFromFloat(49999.0),
+			AskPrice:  decimal.NewFromFloat(50001.0),
+			Volume:    decimal.NewFromFloat(1000.0),
 			Timestamp: time.Now(),
 			Source:    "test",
 			Latency:   10 * time.Millisecond,
@@ -218,13 +219,13 @@ func TestRedisCache_PriceDataOperations(t *testing.T) {
 		prices := []*PriceData{
 			{
 				Symbol:    "BTCUSDT",
-				Price:     50000.0,
+				Price:     decimal.NewFromFloat(50000.0),
 				Timestamp: time.Now(),
 				Source:    "test",
 			},
 			{
 				Symbol:    "ETHUSDT",
-				Price:     3000.0,
+				Price:     decimal.NewFromFloat(3000.0),
 				Timestamp: time.Now(),
 				Source:    "test",
 			},
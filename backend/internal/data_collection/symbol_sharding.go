@@ -0,0 +1,115 @@
+package data_collection
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// DefaultHashRingReplicas 每个成员的默认虚拟节点数
+// 虚拟节点越多分布越均匀，160 个虚拟节点时各成员分到的交易对数偏差通常在 10% 以内
+const DefaultHashRingReplicas = 160
+
+// HashRing 一致性哈希环，用于把交易对分配到采集实例
+//
+// 每个成员在环上放置若干虚拟节点，交易对归属于顺时针方向的第一个虚拟节点。成员加入或离开时
+// 只有约 1/N 的交易对改变归属，其余交易对的连接和订阅保持不变。所有实例使用相同的成员列表
+// 构建的环完全相同，无需协调即可得到互不重叠、完整覆盖的分片
+type HashRing struct {
+	replicas int
+	hashes   []uint64          // 已排序的虚拟节点哈希
+	owners   map[uint64]string // 虚拟节点哈希 -> 成员
+	members  []string
+}
+
+// NewHashRing 创建一致性哈希环，replicas 不大于 0 时使用 DefaultHashRingReplicas
+func NewHashRing(replicas int, members []string) *HashRing {
+	if replicas <= 0 {
+		replicas = DefaultHashRingReplicas
+	}
+
+	ring := &HashRing{
+		replicas: replicas,
+		owners:   make(map[uint64]string),
+	}
+	ring.Set(members)
+	return ring
+}
+
+// Set 重建环上的成员，重复的成员只计一次
+func (r *HashRing) Set(members []string) {
+	r.hashes = r.hashes[:0]
+	r.owners = make(map[uint64]string, len(members)*r.replicas)
+	r.members = r.members[:0]
+
+	seen := make(map[string]bool, len(members))
+	for _, member := range members {
+		if member == "" || seen[member] {
+			continue
+		}
+		seen[member] = true
+		r.members = append(r.members, member)
+
+		for i := 0; i < r.replicas; i++ {
+			hash := hashKey(member + "#" + strconv.Itoa(i))
+			// 哈希冲突时保留字典序较小的成员，保证结果与成员顺序无关
+			if owner, exists := r.owners[hash]; exists {
+				if member < owner {
+					r.owners[hash] = member
+				}
+				continue
+			}
+			r.owners[hash] = member
+			r.hashes = append(r.hashes, hash)
+		}
+	}
+
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	sort.Strings(r.members)
+}
+
+// Members 返回环上的成员（按字典序）
+func (r *HashRing) Members() []string {
+	members := make([]string, len(r.members))
+	copy(members, r.members)
+	return members
+}
+
+// Get 返回 key 所属的成员，环为空时返回空字符串
+func (r *HashRing) Get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	hash := hashKey(key)
+	idx := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+	if idx == len(r.hashes) {
+		idx = 0
+	}
+	return r.owners[r.hashes[idx]]
+}
+
+// Shard 返回分配给 member 的交易对，保持输入顺序
+func (r *HashRing) Shard(symbols []string, member string) []string {
+	shard := make([]string, 0, len(symbols)/max(len(r.members), 1)+1)
+	for _, symbol := range symbols {
+		if r.Get(symbol) == member {
+			shard = append(shard, symbol)
+		}
+	}
+	return shard
+}
+
+// hashKey 64 位 FNV-1a 哈希，再经 splitmix64 的终结步骤打散
+// 虚拟节点的键只有末尾几个字符不同，FNV 的高位在这种输入下分布不够均匀
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,65 @@
+package data_collection
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSymbols(n int) []string {
+	symbols := make([]string, n)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%03dUSDT", i)
+	}
+	return symbols
+}
+
+func TestHashRing(t *testing.T) {
+	empty := NewHashRing(0, nil)
+	assert.Equal(t, "", empty.Get("BTCUSDT"))
+	assert.Empty(t, empty.Shard([]string{"BTCUSDT"}, "a"))
+
+	symbols := testSymbols(600)
+	ring := NewHashRing(0, []string{"c", "a", "b", "a", ""})
+	assert.Equal(t, []string{"a", "b", "c"}, ring.Members())
+
+	// 成员顺序不影响结果
+	reordered := NewHashRing(0, []string{"b", "c", "a"})
+	for _, symbol := range symbols {
+		assert.Equal(t, ring.Get(symbol), reordered.Get(symbol), symbol)
+	}
+
+	// 各分片互不重叠、完整覆盖，且大致均匀
+	seen := make(map[string]string)
+	for _, member := range ring.Members() {
+		shard := ring.Shard(symbols, member)
+		assert.InDelta(t, 200, len(shard), 60, member)
+		for _, symbol := range shard {
+			require.NotContains(t, seen, symbol)
+			seen[symbol] = member
+		}
+	}
+	assert.Len(t, seen, len(symbols))
+
+	// 新成员加入时只有分给它的交易对改变归属
+	ring.Set([]string{"a", "b", "c", "d"})
+	moved := 0
+	for _, symbol := range symbols {
+		owner := ring.Get(symbol)
+		if owner != seen[symbol] {
+			assert.Equal(t, "d", owner, symbol)
+			moved++
+		}
+	}
+	assert.InDelta(t, 150, moved, 60)
+
+	// 成员离开时只有它的交易对改变归属
+	ring.Set([]string{"a", "c"})
+	for _, symbol := range symbols {
+		if seen[symbol] != "b" {
+			assert.Equal(t, seen[symbol], ring.Get(symbol), symbol)
+		}
+	}
+}
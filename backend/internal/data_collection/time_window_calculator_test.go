@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -14,10 +15,10 @@ import (
 func createPriceData(symbol string, price float64, timestamp time.Time, source string) *PriceData {
 	return &PriceData{
 		Symbol:    symbol,
-		Price:     price,
-		BidPrice:  price - 1.0,
-		AskPrice:  price + 1.0,
-		Volume:    100.0,
+		Price:     decimal.NewFromFloat(price),
+		BidPrice:  decimal.NewFromFloat(price - 1.0),
+		AskPrice:  decimal.NewFromFloat(price + 1.0),
+		Volume:    decimal.NewFromFloat(100.0),
 		Timestamp: timestamp,
 		Source:    source,
 		Latency:   10 * time.Millisecond,
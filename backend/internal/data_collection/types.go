@@ -3,6 +3,8 @@ package data_collection
 import (
 	"context"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // DataCollectionService 数据采集服务接口
@@ -121,15 +123,27 @@ type DataCollectionEvent struct {
 }
 
 // PriceData 价格数据
+// 价格、成交量和持仓量使用 decimal 精确表示，清洗、校验、价差和K线聚合直接使用；变化率、波动率、
+// 成交量异常等统计计算通过 PriceFloat 或 InexactFloat64 转换为 float64
 type PriceData struct {
-	Symbol    string        `json:"symbol"`
-	Price     float64       `json:"price"`
-	BidPrice  float64       `json:"bid_price"`
-	AskPrice  float64       `json:"ask_price"`
-	Volume    float64       `json:"volume"`
-	Timestamp time.Time     `json:"timestamp"`
-	Source    string        `json:"source"`
-	Latency   time.Duration `json:"latency"`
+	Symbol    string          `json:"symbol"`
+	Price     decimal.Decimal `json:"price"`
+	BidPrice  decimal.Decimal `json:"bid_price"`
+	AskPrice  decimal.Decimal `json:"ask_price"`
+	Volume    decimal.Decimal `json:"volume"`
+	Timestamp time.Time       `json:"timestamp"`
+	Source    string          `json:"source"`
+	Latency   time.Duration   `json:"latency"`
+
+	// 24小时滚动成交量与持仓量（来自合约Ticker，可选，缺失时为零）
+	BaseVolume24h decimal.Decimal `json:"base_volume_24h"` // 24小时交易币成交量
+	UsdtVolume24h decimal.Decimal `json:"usdt_volume_24h"` // 24小时USDT成交额
+	OpenInterest  decimal.Decimal `json:"open_interest"`   // 当前持仓量（交易币数量）
+}
+
+// PriceFloat 价格的 float64 近似值，用于统计计算
+func (p *PriceData) PriceFloat() float64 {
+	return p.Price.InexactFloat64()
 }
 
 // PriceChangeRate 价格变化率
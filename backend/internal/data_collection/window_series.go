@@ -0,0 +1,183 @@
+package data_collection
+
+import (
+	"time"
+)
+
+// pricePoint 价格观察点
+type pricePoint struct {
+	timestamp time.Time
+	price     float64
+}
+
+// seqDeque 存放序号的双端队列（单调队列使用）
+type seqDeque struct {
+	items []uint64
+	head  int
+}
+
+func (d *seqDeque) empty() bool       { return d.head >= len(d.items) }
+func (d *seqDeque) front() uint64     { return d.items[d.head] }
+func (d *seqDeque) back() uint64      { return d.items[len(d.items)-1] }
+func (d *seqDeque) pushBack(v uint64) { d.items = append(d.items, v) }
+func (d *seqDeque) popBack()          { d.items = d.items[:len(d.items)-1] }
+
+// popFront 弹出队首，必要时压缩底层数组避免无限增长
+func (d *seqDeque) popFront() {
+	d.head++
+	if d.head > 64 && d.head*2 > len(d.items) {
+		n := copy(d.items, d.items[d.head:])
+		d.items = d.items[:n]
+		d.head = 0
+	}
+}
+
+// windowCursor 单个时间窗口的游标
+// start 为窗口内最早观察点的序号；maxQ/minQ 为单调队列，队首分别是窗口内最高价/最低价的序号
+type windowCursor struct {
+	window   TimeWindow
+	duration time.Duration
+	start    uint64
+	maxQ     seqDeque
+	minQ     seqDeque
+}
+
+// windowSnapshot 窗口计算结果
+type windowSnapshot struct {
+	startPrice float64
+	startTime  time.Time
+	highPrice  float64
+	lowPrice   float64
+	samples    int
+}
+
+// priceWindowSeries 单个交易对的多窗口价格序列
+// 底层为按时间有序的环形缓冲区，每个窗口维护一个游标和两条单调队列，
+// 每个观察点进出每个窗口各一次，因此每次写入的均摊复杂度为 O(窗口数)，与窗口长度和数据量无关
+type priceWindowSeries struct {
+	buf     []pricePoint
+	first   uint64 // 缓冲区中最早观察点的序号
+	next    uint64 // 下一个观察点的序号
+	cursors []*windowCursor
+}
+
+// newPriceWindowSeries 创建多窗口价格序列
+func newPriceWindowSeries(windows []TimeWindow) *priceWindowSeries {
+	s := &priceWindowSeries{buf: make([]pricePoint, 16)}
+	s.setWindows(windows)
+	return s
+}
+
+// setWindows 重新配置时间窗口，使用缓冲区中保留的数据重建游标
+func (s *priceWindowSeries) setWindows(windows []TimeWindow) {
+	points := make([]pricePoint, 0, s.len())
+	for seq := s.first; seq < s.next; seq++ {
+		points = append(points, s.at(seq))
+	}
+
+	s.cursors = make([]*windowCursor, 0, len(windows))
+	for _, window := range windows {
+		duration, err := window.Duration()
+		if err != nil {
+			continue
+		}
+		s.cursors = append(s.cursors, &windowCursor{window: window, duration: duration})
+	}
+
+	s.first, s.next = 0, 0
+	for _, p := range points {
+		s.push(p.timestamp, p.price)
+	}
+}
+
+// len 缓冲区中的观察点数量
+func (s *priceWindowSeries) len() int {
+	return int(s.next - s.first)
+}
+
+// at 按序号获取观察点
+func (s *priceWindowSeries) at(seq uint64) pricePoint {
+	return s.buf[seq%uint64(len(s.buf))]
+}
+
+// last 最新观察点
+func (s *priceWindowSeries) last() (pricePoint, bool) {
+	if s.next == s.first {
+		return pricePoint{}, false
+	}
+	return s.at(s.next - 1), true
+}
+
+// push 写入新的观察点，早于最新观察点的乱序数据不会写入
+func (s *priceWindowSeries) push(ts time.Time, price float64) bool {
+	if last, ok := s.last(); ok && ts.Before(last.timestamp) {
+		return false
+	}
+
+	if s.len() == len(s.buf) {
+		s.grow()
+	}
+	seq := s.next
+	s.buf[seq%uint64(len(s.buf))] = pricePoint{timestamp: ts, price: price}
+	s.next++
+
+	oldest := seq
+	for _, c := range s.cursors {
+		// 维护单调队列
+		for !c.maxQ.empty() && s.at(c.maxQ.back()).price <= price {
+			c.maxQ.popBack()
+		}
+		c.maxQ.pushBack(seq)
+		for !c.minQ.empty() && s.at(c.minQ.back()).price >= price {
+			c.minQ.popBack()
+		}
+		c.minQ.pushBack(seq)
+
+		// 推进窗口起点（起点时间包含在窗口内）
+		if c.start < s.first {
+			c.start = s.first
+		}
+		cutoff := ts.Add(-c.duration)
+		for c.start < seq && s.at(c.start).timestamp.Before(cutoff) {
+			c.start++
+		}
+		for c.maxQ.front() < c.start {
+			c.maxQ.popFront()
+		}
+		for c.minQ.front() < c.start {
+			c.minQ.popFront()
+		}
+
+		if c.start < oldest {
+			oldest = c.start
+		}
+	}
+
+	// 丢弃所有窗口都不再需要的数据（无窗口时仅保留最新观察点）
+	s.first = oldest
+	return true
+}
+
+// grow 扩容环形缓冲区
+func (s *priceWindowSeries) grow() {
+	buf := make([]pricePoint, len(s.buf)*2)
+	for seq := s.first; seq < s.next; seq++ {
+		buf[seq%uint64(len(buf))] = s.at(seq)
+	}
+	s.buf = buf
+}
+
+// snapshot 获取各窗口的计算结果
+func (s *priceWindowSeries) snapshot(c *windowCursor) (windowSnapshot, bool) {
+	if s.next == s.first || c.maxQ.empty() || c.minQ.empty() {
+		return windowSnapshot{}, false
+	}
+	start := s.at(c.start)
+	return windowSnapshot{
+		startPrice: start.price,
+		startTime:  start.timestamp,
+		highPrice:  s.at(c.maxQ.front()).price,
+		lowPrice:   s.at(c.minQ.front()).price,
+		samples:    int(s.next - c.start),
+	}, true
+}
@@ -0,0 +1,158 @@
+package data_collection
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTimeWindow_Parse(t *testing.T) {
+	valid := map[TimeWindow]time.Duration{
+		TimeWindow10s: 10 * time.Second,
+		"3m":          3 * time.Minute,
+		TimeWindow4h:  4 * time.Hour,
+		TimeWindow24h: 24 * time.Hour,
+		"7d":          7 * 24 * time.Hour,
+	}
+	for window, expected := range valid {
+		duration, err := window.Duration()
+		require.NoError(t, err, window)
+		assert.Equal(t, expected, duration, window)
+	}
+
+	for _, window := range []TimeWindow{"", "m", "0s", "05m", "+5m", "-1m", "1w", "1.5h", "8d"} {
+		_, err := window.Duration()
+		assert.Error(t, err, window)
+	}
+
+	windows, err := ParseTimeWindows([]string{"5m", "10s"}, []string{"1h", "5m", "30s"})
+	require.NoError(t, err)
+	assert.Equal(t, []TimeWindow{TimeWindow10s, TimeWindow30s, TimeWindow5m, TimeWindow1h}, windows)
+
+	_, err = ParseTimeWindows([]string{"5m", "abc"})
+	assert.Error(t, err)
+}
+
+func TestPriceWindowSeries_MatchesBruteForce(t *testing.T) {
+	windows := []TimeWindow{TimeWindow10s, TimeWindow1m, "3m"}
+	series := newPriceWindowSeries(windows)
+
+	type point struct {
+		ts    time.Time
+		price float64
+	}
+	var all []point
+	rng := rand.New(rand.NewSource(1))
+	ts := time.Now().Add(-time.Hour)
+	price := 100.0
+
+	for i := 0; i < 2000; i++ {
+		ts = ts.Add(time.Duration(rng.Intn(3000)) * time.Millisecond)
+		price *= 1 + (rng.Float64()-0.5)*0.01
+		require.True(t, series.push(ts, price))
+		all = append(all, point{ts, price})
+
+		for _, cursor := range series.cursors {
+			snapshot, ok := series.snapshot(cursor)
+			require.True(t, ok)
+
+			// 暴力计算窗口内的起始价、最高价、最低价
+			cutoff := ts.Add(-cursor.duration)
+			var start, high, low float64
+			found := false
+			for _, p := range all {
+				if p.ts.Before(cutoff) {
+					continue
+				}
+				if !found {
+					start, high, low, found = p.price, p.price, p.price, true
+				}
+				high = max(high, p.price)
+				low = min(low, p.price)
+			}
+			assert.Equal(t, start, snapshot.startPrice)
+			assert.Equal(t, high, snapshot.highPrice)
+			assert.Equal(t, low, snapshot.lowPrice)
+		}
+	}
+
+	// 只保留最长窗口所需的数据
+	assert.Less(t, series.len(), 200)
+}
+
+func TestPriceProcessor_ConfigurableTimeWindows(t *testing.T) {
+	config := DefaultProcessorConfig()
+	config.TimeWindows = []TimeWindow{TimeWindow10s, TimeWindow1m, TimeWindow1h}
+	processor := NewPriceProcessor(config, zap.NewNop())
+
+	ctx := context.Background()
+	require.NoError(t, processor.(*priceProcessorImpl).Start(ctx))
+	defer processor.(*priceProcessorImpl).Stop(ctx)
+
+	baseTime := time.Now().Add(-10 * time.Minute)
+	prices := []*PriceData{
+		createPriceData("BTCUSDT", 100, baseTime, "test"),
+		createPriceData("BTCUSDT", 101, baseTime.Add(30*time.Second), "test"),
+		createPriceData("BTCUSDT", 99, baseTime.Add(50*time.Second), "test"),
+		createPriceData("BTCUSDT", 105, baseTime.Add(55*time.Second), "test"),
+		createPriceData("BTCUSDT", 106, baseTime.Add(58*time.Second), "test"),
+	}
+	require.NoError(t, processor.ProcessBatch(prices))
+
+	t.Run("10秒窗口捕捉短时拉升", func(t *testing.T) {
+		rate, err := processor.GetChangeRate("BTCUSDT", TimeWindow10s)
+		require.NoError(t, err)
+		assert.Equal(t, 99.0, rate.StartPrice)
+		assert.Equal(t, 106.0, rate.EndPrice)
+		assert.Equal(t, 106.0, rate.HighPrice)
+		assert.Equal(t, 99.0, rate.LowPrice)
+		assert.InDelta(t, 7.07, rate.ChangeRate, 0.01)
+		assert.True(t, rate.IsValid)
+	})
+
+	t.Run("长窗口包含全部数据", func(t *testing.T) {
+		rate, err := processor.GetChangeRate("BTCUSDT", TimeWindow1h)
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, rate.StartPrice)
+		assert.Equal(t, 99.0, rate.LowPrice)
+		assert.InDelta(t, 6.0, rate.ChangeRate, 1e-9)
+	})
+
+	t.Run("乱序数据不影响变化率", func(t *testing.T) {
+		require.NoError(t, processor.ProcessPrice(createPriceData("BTCUSDT", 1, baseTime.Add(40*time.Second), "test")))
+		rate, err := processor.GetChangeRate("BTCUSDT", TimeWindow10s)
+		require.NoError(t, err)
+		assert.Equal(t, 106.0, rate.EndPrice)
+		assert.Equal(t, 99.0, rate.LowPrice)
+	})
+
+	t.Run("运行中调整时间窗口", func(t *testing.T) {
+		assert.Error(t, processor.SetTimeWindows(nil))
+		assert.Error(t, processor.SetTimeWindows([]TimeWindow{"1x"}))
+
+		require.NoError(t, processor.SetTimeWindows([]TimeWindow{TimeWindow30s, "3m"}))
+		assert.Equal(t, []TimeWindow{TimeWindow30s, "3m"}, processor.GetTimeWindows())
+
+		rates, err := processor.GetChangeRates("BTCUSDT")
+		require.NoError(t, err)
+		require.Len(t, rates, 2)
+		assert.Equal(t, 101.0, rates[TimeWindow30s].StartPrice)
+		assert.Equal(t, 100.0, rates["3m"].StartPrice)
+
+		_, err = processor.GetChangeRate("BTCUSDT", TimeWindow10s)
+		assert.Error(t, err)
+	})
+
+	t.Run("长时间无数据的交易对被释放", func(t *testing.T) {
+		impl := processor.(*priceProcessorImpl)
+		impl.config.DataRetention = time.Minute
+		impl.cleanupOldData()
+		_, err := processor.GetChangeRates("BTCUSDT")
+		assert.Error(t, err)
+	})
+}
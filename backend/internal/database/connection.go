@@ -64,9 +64,11 @@ func Connect(cfg *config.DatabaseConfig, log *zap.Logger) (*gorm.DB, error) {
 
 	// 配置读写分离（如果提供了从库配置）
 	if len(cfg.Replicas) > 0 {
-		if err := setupReadWriteSplitting(db, cfg, log); err != nil {
+		router, err := setupReadWriteSplitting(db, cfg, log)
+		if err != nil {
 			return nil, fmt.Errorf("failed to setup read-write splitting: %w", err)
 		}
+		Replicas = router
 	}
 
 	log.Info("Database connected successfully",
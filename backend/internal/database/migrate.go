@@ -2,9 +2,11 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
@@ -19,7 +21,7 @@ type MigrateConfig struct {
 }
 
 // RunMigrations 执行数据库迁移
-func RunMigrations(config MigrateConfig) error {
+func RunMigrations(config MigrateConfig) (err error) {
 	if config.Logger == nil {
 		config.Logger = zap.NewNop()
 	}
@@ -28,33 +30,11 @@ func RunMigrations(config MigrateConfig) error {
 		zap.String("migrations_path", config.MigrationsPath),
 	)
 
-	// 连接数据库
-	db, err := sql.Open("postgres", config.DatabaseURL)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
-	defer db.Close()
-
-	// 验证连接
-	if err := db.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	// 创建 PostgreSQL 驱动实例
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	m, closeFn, err := newMigrate(config)
 	if err != nil {
-		return fmt.Errorf("failed to create postgres driver: %w", err)
-	}
-
-	// 创建迁移实例
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", config.MigrationsPath),
-		"postgres",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
 	}
+	defer func() { err = errors.Join(err, closeFn()) }()
 
 	// 执行迁移
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
@@ -80,32 +60,18 @@ func RunMigrations(config MigrateConfig) error {
 }
 
 // RollbackMigration 回滚最近的一次迁移
-func RollbackMigration(config MigrateConfig) error {
+func RollbackMigration(config MigrateConfig) (err error) {
 	if config.Logger == nil {
 		config.Logger = zap.NewNop()
 	}
 
 	config.Logger.Info("Rolling back last migration")
 
-	db, err := sql.Open("postgres", config.DatabaseURL)
+	m, closeFn, err := newMigrate(config)
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
-	defer db.Close()
-
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		return fmt.Errorf("failed to create postgres driver: %w", err)
-	}
-
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file://%s", config.MigrationsPath),
-		"postgres",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
 	}
+	defer func() { err = errors.Join(err, closeFn()) }()
 
 	// 回滚一步
 	if err := m.Steps(-1); err != nil {
@@ -126,31 +92,118 @@ func RollbackMigration(config MigrateConfig) error {
 }
 
 // GetMigrationVersion 获取当前迁移版本
-func GetMigrationVersion(config MigrateConfig) (uint, bool, error) {
+func GetMigrationVersion(config MigrateConfig) (version uint, dirty bool, err error) {
+	m, closeFn, err := newMigrate(config)
+	if err != nil {
+		return 0, false, err
+	}
+	defer func() { err = errors.Join(err, closeFn()) }()
+
+	version, dirty, err = m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+// MigrateTo 升级或回滚到指定版本，0 表示回滚所有迁移
+func MigrateTo(config MigrateConfig, version uint) (err error) {
+	if config.Logger == nil {
+		config.Logger = zap.NewNop()
+	}
+
+	config.Logger.Info("Migrating to version", zap.Uint("target", version))
+
+	m, closeFn, err := newMigrate(config)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, closeFn()) }()
+
+	if version == 0 {
+		err = m.Down()
+	} else {
+		err = m.Migrate(version)
+	}
+	if err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+
+	current, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	config.Logger.Info("Migration completed",
+		zap.Uint("version", current),
+		zap.Bool("dirty", dirty),
+	)
+
+	return nil
+}
+
+// ForceMigrationVersion 设置迁移版本并清除 dirty 标记，不执行任何 SQL，0 表示没有已执行的迁移
+// 用于迁移中途失败后手动修复数据库：修复到迁移执行前的状态时设置为上一个版本，
+// 手动完成了该迁移时设置为该版本
+func ForceMigrationVersion(config MigrateConfig, version uint) (err error) {
+	if config.Logger == nil {
+		config.Logger = zap.NewNop()
+	}
+
+	m, closeFn, err := newMigrate(config)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, closeFn()) }()
+
+	forced := int(version)
+	if version == 0 {
+		forced = migratedb.NilVersion
+	}
+	if err := m.Force(forced); err != nil {
+		return fmt.Errorf("failed to force migration version %d: %w", version, err)
+	}
+
+	config.Logger.Info("Migration version forced", zap.Uint("version", version))
+
+	return nil
+}
+
+// newMigrate 连接数据库并创建迁移实例，返回的函数关闭源文件驱动和数据库驱动（包括连接）
+func newMigrate(config MigrateConfig) (*migrate.Migrate, func() error, error) {
 	db, err := sql.Open("postgres", config.DatabaseURL)
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to open database: %w", err)
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// 验证连接
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to ping database: %w", err)
 	}
-	defer db.Close()
 
+	// 创建 PostgreSQL 驱动实例
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to create postgres driver: %w", err)
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to create postgres driver: %w", err)
 	}
 
+	// 创建迁移实例
 	m, err := migrate.NewWithDatabaseInstance(
 		fmt.Sprintf("file://%s", config.MigrationsPath),
 		"postgres",
 		driver,
 	)
 	if err != nil {
-		return 0, false, fmt.Errorf("failed to create migrate instance: %w", err)
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to create migrate instance: %w", err)
 	}
 
-	version, dirty, err := m.Version()
-	if err != nil && err != migrate.ErrNilVersion {
-		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
-	}
-
-	return version, dirty, nil
+	// 数据库驱动持有 db，关闭驱动时一并关闭连接
+	return m, func() error {
+		srcErr, dbErr := m.Close()
+		return errors.Join(srcErr, dbErr)
+	}, nil
 }
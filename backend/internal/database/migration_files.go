@@ -0,0 +1,204 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFilePattern 迁移文件名：{版本}_{名称}.up.sql / {版本}_{名称}.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// migrationVersionWidth 新建迁移的版本号位数，与已有文件（000001_...）一致
+const migrationVersionWidth = 6
+
+// Migration 迁移目录中的一个版本
+type Migration struct {
+	Version  uint
+	Name     string
+	UpFile   string // up 文件路径
+	DownFile string // down 文件路径，可能为空
+}
+
+// MigrationStep 执行到目标版本需要运行的一个迁移文件
+type MigrationStep struct {
+	Migration *Migration
+	Up        bool
+	File      string
+}
+
+// ListMigrations 按版本升序列出迁移目录中的迁移
+func ListMigrations(migrationsPath string) ([]*Migration, error) {
+	entries, err := os.ReadDir(migrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		if version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s: versions start at 1", entry.Name())
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, migration.Name, match[2])
+		}
+
+		path := filepath.Join(migrationsPath, entry.Name())
+		if match[3] == "up" {
+			migration.UpFile = path
+		} else {
+			migration.DownFile = path
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpFile == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// PlanMigrations 计算从 current 执行到 target 需要运行的迁移文件，0 表示没有已执行的迁移
+// 升级按版本升序运行 up 文件，回滚按版本降序运行 down 文件
+func PlanMigrations(migrations []*Migration, current, target uint) ([]MigrationStep, error) {
+	if err := requireMigration(migrations, current); err != nil {
+		return nil, fmt.Errorf("current version: %w", err)
+	}
+	if err := requireMigration(migrations, target); err != nil {
+		return nil, fmt.Errorf("target version: %w", err)
+	}
+
+	var steps []MigrationStep
+	if target >= current {
+		for _, migration := range migrations {
+			if migration.Version > current && migration.Version <= target {
+				steps = append(steps, MigrationStep{Migration: migration, Up: true, File: migration.UpFile})
+			}
+		}
+		return steps, nil
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if migration.Version > target && migration.Version <= current {
+			if migration.DownFile == "" {
+				return nil, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			steps = append(steps, MigrationStep{Migration: migration, Up: false, File: migration.DownFile})
+		}
+	}
+	return steps, nil
+}
+
+// PreviousMigrationVersion 回滚一步后的版本，current 是第一个迁移时返回 0
+func PreviousMigrationVersion(migrations []*Migration, current uint) uint {
+	var previous uint
+	for _, migration := range migrations {
+		if migration.Version >= current {
+			break
+		}
+		previous = migration.Version
+	}
+	return previous
+}
+
+// LatestMigrationVersion 最新的迁移版本，没有迁移时返回 0
+func LatestMigrationVersion(migrations []*Migration) uint {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// CreateMigration 以下一个版本号创建空的 up/down 迁移文件，返回两个文件的路径
+// name 转换为小写下划线形式，如 "Add Symbol Index" -> <下一个版本号>_add_symbol_index
+func CreateMigration(migrationsPath, name string) (string, string, error) {
+	name = normalizeMigrationName(name)
+	if name == "" {
+		return "", "", fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	migrations, err := ListMigrations(migrationsPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	base := fmt.Sprintf("%0*d_%s", migrationVersionWidth, LatestMigrationVersion(migrations)+1, name)
+	upFile := filepath.Join(migrationsPath, base+".up.sql")
+	downFile := filepath.Join(migrationsPath, base+".down.sql")
+
+	if err := writeNewFile(upFile, fmt.Sprintf("-- %s\n\n", name)); err != nil {
+		return "", "", err
+	}
+	if err := writeNewFile(downFile, fmt.Sprintf("-- 回滚 %s\n\n", name)); err != nil {
+		os.Remove(upFile)
+		return "", "", err
+	}
+	return upFile, downFile, nil
+}
+
+func requireMigration(migrations []*Migration, version uint) error {
+	if version == 0 {
+		return nil
+	}
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("migration %d not found", version)
+}
+
+func normalizeMigrationName(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// writeNewFile 创建文件，文件已存在时返回错误
+func writeNewFile(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create migration file: %w", err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write migration file: %w", err)
+	}
+	return file.Close()
+}
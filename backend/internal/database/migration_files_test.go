@@ -0,0 +1,132 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigrationFiles 在临时目录中创建迁移文件
+func writeMigrationFiles(t *testing.T, names ...string) string {
+	dir := t.TempDir()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("-- "+name+"\n"), 0o644))
+	}
+	return dir
+}
+
+func TestListMigrations(t *testing.T) {
+	dir := writeMigrationFiles(t,
+		"000002_create_symbols.up.sql", "000002_create_symbols.down.sql",
+		"000001_enable_timescaledb.up.sql", "000001_enable_timescaledb.down.sql",
+		"000010_add_index.up.sql",
+		"README.md",
+	)
+
+	migrations, err := ListMigrations(dir)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, uint(1), migrations[0].Version)
+	assert.Equal(t, "enable_timescaledb", migrations[0].Name)
+	assert.Equal(t, filepath.Join(dir, "000002_create_symbols.down.sql"), migrations[1].DownFile)
+	assert.Equal(t, uint(10), migrations[2].Version)
+	assert.Empty(t, migrations[2].DownFile)
+	assert.Equal(t, uint(10), LatestMigrationVersion(migrations))
+
+	// 同一版本两个名称
+	dir = writeMigrationFiles(t, "000001_a.up.sql", "000001_b.up.sql")
+	_, err = ListMigrations(dir)
+	assert.Error(t, err)
+
+	// 只有 down 文件
+	dir = writeMigrationFiles(t, "000001_a.down.sql")
+	_, err = ListMigrations(dir)
+	assert.Error(t, err)
+}
+
+func TestListMigrations_Repository(t *testing.T) {
+	migrations, err := ListMigrations(filepath.Join("..", "..", "migrations"))
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	// 仓库中的迁移版本连续，且都有 down 文件
+	for i, migration := range migrations {
+		assert.Equal(t, uint(i+1), migration.Version, migration.Name)
+		assert.NotEmpty(t, migration.DownFile, migration.Name)
+	}
+}
+
+func TestPlanMigrations(t *testing.T) {
+	dir := writeMigrationFiles(t,
+		"000001_a.up.sql", "000001_a.down.sql",
+		"000002_b.up.sql", "000002_b.down.sql",
+		"000003_c.up.sql", "000003_c.down.sql",
+		"000004_d.up.sql",
+	)
+	migrations, err := ListMigrations(dir)
+	require.NoError(t, err)
+
+	files := func(steps []MigrationStep) []string {
+		names := make([]string, 0, len(steps))
+		for _, step := range steps {
+			names = append(names, filepath.Base(step.File))
+		}
+		return names
+	}
+
+	steps, err := PlanMigrations(migrations, 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"000001_a.up.sql", "000002_b.up.sql", "000003_c.up.sql"}, files(steps))
+	assert.True(t, steps[0].Up)
+
+	steps, err = PlanMigrations(migrations, 3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"000003_c.down.sql", "000002_b.down.sql"}, files(steps))
+	assert.False(t, steps[0].Up)
+
+	steps, err = PlanMigrations(migrations, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"000002_b.down.sql", "000001_a.down.sql"}, files(steps))
+
+	steps, err = PlanMigrations(migrations, 2, 2)
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	// 缺少 down 文件、版本不存在
+	_, err = PlanMigrations(migrations, 4, 3)
+	assert.Error(t, err)
+	_, err = PlanMigrations(migrations, 0, 7)
+	assert.Error(t, err)
+	_, err = PlanMigrations(migrations, 9, 1)
+	assert.Error(t, err)
+
+	assert.Equal(t, uint(2), PreviousMigrationVersion(migrations, 3))
+	assert.Equal(t, uint(0), PreviousMigrationVersion(migrations, 1))
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := writeMigrationFiles(t, "000014_a.up.sql", "000014_a.down.sql")
+
+	upFile, downFile, err := CreateMigration(dir, "  Add Symbol-Index! ")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "000015_add_symbol_index.up.sql"), upFile)
+	assert.Equal(t, filepath.Join(dir, "000015_add_symbol_index.down.sql"), downFile)
+	assert.FileExists(t, upFile)
+	assert.FileExists(t, downFile)
+
+	migrations, err := ListMigrations(dir)
+	require.NoError(t, err)
+	assert.Equal(t, uint(15), LatestMigrationVersion(migrations))
+
+	upFile, _, err = CreateMigration(dir, "next")
+	require.NoError(t, err)
+	assert.Equal(t, "000016_next.up.sql", filepath.Base(upFile))
+
+	_, _, err = CreateMigration(dir, "!!!")
+	assert.Error(t, err)
+	_, _, err = CreateMigration(filepath.Join(dir, "missing"), "x")
+	assert.Error(t, err)
+}
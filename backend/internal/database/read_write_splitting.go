@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -14,16 +15,30 @@ import (
 )
 
 // setupReadWriteSplitting 设置读写分离
-func setupReadWriteSplitting(db *gorm.DB, cfg *config.DatabaseConfig, log *zap.Logger) error {
+// 查询由 ReplicaRouter 路由：延迟过大的从库移出读池，写后读和要求读主库的查询走主库
+func setupReadWriteSplitting(db *gorm.DB, cfg *config.DatabaseConfig, log *zap.Logger) (*ReplicaRouter, error) {
 	if len(cfg.Replicas) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	// 构建从库 DSN 列表
+	router := NewReplicaRouter(replicaRouterConfig(cfg), log)
+
+	// 主库连接用于采样 WAL 位置，与从库的回放位置比较得到复制延迟
+	primary, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get primary connection: %w", err)
+	}
+	router.SetPrimary(primary)
+
+	// 构建从库 DSN 列表，从库连接池同时用于路由和延迟检查
 	replicaDSNs := make([]gorm.Dialector, 0, len(cfg.Replicas))
 	for _, replica := range cfg.Replicas {
-		dsn := replica.GetReplicaDSN()
-		replicaDSNs = append(replicaDSNs, postgres.Open(dsn))
+		conn, err := sql.Open("pgx", replica.GetReplicaDSN())
+		if err != nil {
+			return nil, fmt.Errorf("failed to open replica %s:%d: %w", replica.Host, replica.Port, err)
+		}
+		router.AddReplica(fmt.Sprintf("%s:%d", replica.Host, replica.Port), conn)
+		replicaDSNs = append(replicaDSNs, postgres.New(postgres.Config{Conn: conn}))
 		log.Info("Adding read replica",
 			zap.String("host", replica.Host),
 			zap.Int("port", replica.Port),
@@ -31,11 +46,11 @@ func setupReadWriteSplitting(db *gorm.DB, cfg *config.DatabaseConfig, log *zap.L
 	}
 
 	// 配置 DBResolver 插件
-	err := db.Use(dbresolver.Register(dbresolver.Config{
+	err = db.Use(dbresolver.Register(dbresolver.Config{
 		// 从库用于 SELECT 查询
 		Replicas: replicaDSNs,
-		// 读写分离策略：随机选择从库
-		Policy: dbresolver.RandomPolicy{},
+		// 读写分离策略：在未被移出读池的从库中随机选择
+		Policy: router,
 	}).
 		SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second).
 		SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second).
@@ -43,14 +58,40 @@ func setupReadWriteSplitting(db *gorm.DB, cfg *config.DatabaseConfig, log *zap.L
 		SetMaxOpenConns(cfg.MaxOpenConns))
 
 	if err != nil {
-		return fmt.Errorf("failed to configure dbresolver: %w", err)
+		return nil, fmt.Errorf("failed to configure dbresolver: %w", err)
+	}
+	if err := router.Install(db); err != nil {
+		return nil, fmt.Errorf("failed to register replica router: %w", err)
 	}
 
+	// 启动时先检查一次，延迟过大的从库不进入读池
+	router.CheckReplicas(context.Background())
+
 	log.Info("Read-write splitting configured successfully",
 		zap.Int("replicas_count", len(cfg.Replicas)),
+		zap.Int("healthy_replicas", router.Health().HealthyReplicas),
+		zap.Duration("max_lag", router.config.MaxLag),
 	)
 
-	return nil
+	return router, nil
+}
+
+// replicaRouterConfig 从数据库配置生成从库路由配置，未设置的项使用默认值
+func replicaRouterConfig(cfg *config.DatabaseConfig) *ReplicaRouterConfig {
+	routerConfig := DefaultReplicaRouterConfig()
+	if cfg.ReplicaMaxLag > 0 {
+		routerConfig.MaxLag = cfg.ReplicaMaxLag
+	}
+	if cfg.ReplicaCheckInterval > 0 {
+		routerConfig.CheckInterval = cfg.ReplicaCheckInterval
+	}
+	if cfg.ReadYourWritesWindow > 0 {
+		routerConfig.ReadYourWritesWindow = cfg.ReadYourWritesWindow
+	}
+	if cfg.ReadYourWritesTables != nil {
+		routerConfig.ReadYourWritesTables = cfg.ReadYourWritesTables
+	}
+	return routerConfig
 }
 
 // MonitorReplicationLag 监控复制延迟
@@ -0,0 +1,445 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	// primaryWALQuery 在主库上查询当前 WAL 位置
+	primaryWALQuery = `SELECT pg_current_wal_lsn()::text`
+
+	// replicaStateQuery 在从库上查询是否处于恢复模式、WAL 接收进程状态和已回放的 WAL 位置
+	// 从库停止接收 WAL 后，已接收的 WAL 很快全部回放，只比较从库自身的接收和回放位置会得到延迟 0，
+	// 因此回放位置与主库的 WAL 位置比较，并要求 WAL 接收进程处于 streaming 状态
+	replicaStateQuery = `
+	SELECT pg_is_in_recovery(),
+		COALESCE((SELECT status FROM pg_stat_wal_receiver), ''),
+		COALESCE(pg_last_wal_replay_lsn()::text, '')`
+)
+
+// Replicas 从库路由，未配置从库时为空
+var Replicas *ReplicaRouter
+
+// ReplicaRouterConfig 从库路由配置
+type ReplicaRouterConfig struct {
+	MaxLag               time.Duration // 复制延迟超过该值（或检查失败）时移出读池
+	CheckInterval        time.Duration // 延迟检查间隔
+	CheckTimeout         time.Duration // 单个从库检查超时
+	ReadYourWritesWindow time.Duration // 写入后该时间内读取同一张表走主库
+	ReadYourWritesTables []string      // 需要写后读一致性的表
+}
+
+// DefaultReplicaRouterConfig 默认从库路由配置
+func DefaultReplicaRouterConfig() *ReplicaRouterConfig {
+	return &ReplicaRouterConfig{
+		MaxLag:               5 * time.Second,
+		CheckInterval:        5 * time.Second,
+		CheckTimeout:         3 * time.Second,
+		ReadYourWritesWindow: 10 * time.Second,
+		ReadYourWritesTables: []string{"monitoring_configs", "symbols"},
+	}
+}
+
+// ReplicaStatus 从库健康状态
+type ReplicaStatus struct {
+	Name         string     `json:"name"`
+	Healthy      bool       `json:"healthy"`                 // 是否在读池中
+	LagSeconds   float64    `json:"lag_seconds"`             // 最近一次检查的复制延迟
+	LastError    string     `json:"last_error,omitempty"`    // 最近一次检查的错误
+	CheckedAt    *time.Time `json:"checked_at,omitempty"`    // 最近一次检查时间
+	EvictedSince *time.Time `json:"evicted_since,omitempty"` // 移出读池的时间
+}
+
+// ReplicaHealth 读写分离状态
+type ReplicaHealth struct {
+	MaxLagSeconds   float64          `json:"max_lag_seconds"`
+	HealthyReplicas int              `json:"healthy_replicas"`
+	Replicas        []*ReplicaStatus `json:"replicas"`
+}
+
+// walSample 主库 WAL 位置的采样
+type walSample struct {
+	lsn uint64
+	at  time.Time
+}
+
+// replica 从库连接及其最近一次检查结果
+type replica struct {
+	name   string
+	conn   *sql.DB
+	status ReplicaStatus
+}
+
+// ReplicaRouter 延迟感知的从库路由
+// 作为 dbresolver 的负载均衡策略只在健康的从库中选择；没有健康的从库、
+// 上下文要求读主库或者最近写过查询的表时，查询改走主库
+type ReplicaRouter struct {
+	config *ReplicaRouterConfig
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	primary  *sql.DB
+	replicas []*replica
+	byConn   map[gorm.ConnPool]*replica
+	sticky   map[string]bool
+	writes   map[string]time.Time
+
+	// walSamples 每次检查时采样的主库 WAL 位置，按时间升序
+	// 从库的延迟为它尚未回放到的最早一次采样距今的时间
+	walSamples []walSample
+
+	// 查询从库复制延迟，测试中可替换
+	checkLag func(ctx context.Context, conn *sql.DB) (time.Duration, error)
+	now      func() time.Time
+}
+
+// NewReplicaRouter 创建从库路由
+func NewReplicaRouter(config *ReplicaRouterConfig, logger *zap.Logger) *ReplicaRouter {
+	if config == nil {
+		config = DefaultReplicaRouterConfig()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	sticky := make(map[string]bool, len(config.ReadYourWritesTables))
+	for _, table := range config.ReadYourWritesTables {
+		sticky[table] = true
+	}
+
+	r := &ReplicaRouter{
+		config: config,
+		logger: logger,
+		byConn: make(map[gorm.ConnPool]*replica),
+		sticky: sticky,
+		writes: make(map[string]time.Time),
+		now:    time.Now,
+	}
+	r.checkLag = r.queryReplicaLag
+	return r
+}
+
+// SetPrimary 设置主库连接，用于采样主库的 WAL 位置
+func (r *ReplicaRouter) SetPrimary(conn *sql.DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.primary = conn
+}
+
+// ReadYourWritesWindow 写入后读取走主库的时间窗口
+func (r *ReplicaRouter) ReadYourWritesWindow() time.Duration {
+	return r.config.ReadYourWritesWindow
+}
+
+// AddReplica 添加从库，conn 需要与注册到 dbresolver 的 Dialector 使用同一个连接池
+// 新添加的从库在读池中，第一次检查后按延迟决定是否移出
+func (r *ReplicaRouter) AddReplica(name string, conn *sql.DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := &replica{
+		name:   name,
+		conn:   conn,
+		status: ReplicaStatus{Name: name, Healthy: true},
+	}
+	r.replicas = append(r.replicas, rep)
+	r.byConn[conn] = rep
+}
+
+// Install 注册路由回调，需要在注册 dbresolver 之后调用
+// 读取回调在 dbresolver 选择从库之后执行，需要时重新选择主库
+func (r *ReplicaRouter) Install(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Query().After("gorm:db_resolver").Before("gorm:query").Register("database:replica_router", r.routeRead); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:db_resolver").Before("gorm:row").Register("database:replica_router", r.routeRead); err != nil {
+		return err
+	}
+	if err := callback.Raw().After("gorm:db_resolver").Before("gorm:raw").Register("database:replica_router", r.routeRead); err != nil {
+		return err
+	}
+	if err := callback.Create().After("gorm:create").Register("database:record_write", r.recordWrite); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Register("database:record_write", r.recordWrite); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Register("database:record_write", r.recordWrite)
+}
+
+// Resolve 实现 dbresolver.Policy，在健康的从库中随机选择
+// dbresolver 只有一个从库时不调用策略，由 routeRead 负责改走主库
+func (r *ReplicaRouter) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	r.mu.RLock()
+	healthy := make([]gorm.ConnPool, 0, len(connPools))
+	for _, pool := range connPools {
+		if rep, ok := r.byConn[pool]; !ok || rep.status.Healthy {
+			healthy = append(healthy, pool)
+		}
+	}
+	r.mu.RUnlock()
+
+	// 检查和查询之间从库全部被移出时，routeRead 已经放行，这里退回到全部从库
+	if len(healthy) == 0 {
+		healthy = connPools
+	}
+	return healthy[rand.Intn(len(healthy))]
+}
+
+// routeRead 决定查询是否改走主库
+func (r *ReplicaRouter) routeRead(db *gorm.DB) {
+	if r.readFromPrimary(db.Statement) {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+func (r *ReplicaRouter) readFromPrimary(stmt *gorm.Statement) bool {
+	if stmt.Context != nil && IsPrimaryContext(stmt.Context) {
+		return true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.hasHealthyReplicaLocked() {
+		return true
+	}
+	if stmt.Table != "" {
+		if writtenAt, ok := r.writes[stmt.Table]; ok && r.now().Sub(writtenAt) < r.config.ReadYourWritesWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// recordWrite 记录需要写后读一致性的表的写入时间
+func (r *ReplicaRouter) recordWrite(db *gorm.DB) {
+	table := db.Statement.Table
+	if table == "" || !r.sticky[table] {
+		return
+	}
+
+	r.mu.Lock()
+	r.writes[table] = r.now()
+	r.mu.Unlock()
+}
+
+func (r *ReplicaRouter) hasHealthyReplicaLocked() bool {
+	for _, rep := range r.replicas {
+		if rep.status.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckReplicas 检查所有从库的复制延迟，移出延迟过大或不可达的从库，恢复追上的从库
+func (r *ReplicaRouter) CheckReplicas(ctx context.Context) {
+	r.samplePrimary(ctx)
+
+	r.mu.RLock()
+	replicas := append([]*replica(nil), r.replicas...)
+	r.mu.RUnlock()
+
+	for _, rep := range replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, r.config.CheckTimeout)
+		lag, err := r.checkLag(checkCtx, rep.conn)
+		cancel()
+		r.updateStatus(rep, lag, err)
+	}
+}
+
+func (r *ReplicaRouter) updateStatus(rep *replica, lag time.Duration, err error) {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := &rep.status
+	status.CheckedAt = &now
+	status.LagSeconds = lag.Seconds()
+	status.LastError = ""
+	if err != nil {
+		status.LagSeconds = 0
+		status.LastError = err.Error()
+	}
+
+	healthy := err == nil && lag <= r.config.MaxLag
+	switch {
+	case status.Healthy && !healthy:
+		status.Healthy = false
+		status.EvictedSince = &now
+		r.logger.Warn("Evicting replica from read pool",
+			zap.String("replica", rep.name),
+			zap.Duration("lag", lag),
+			zap.Duration("max_lag", r.config.MaxLag),
+			zap.Error(err),
+		)
+		if !r.hasHealthyReplicaLocked() {
+			r.logger.Warn("No healthy replicas, reads are routed to the primary")
+		}
+	case !status.Healthy && healthy:
+		r.logger.Info("Replica caught up, restoring to read pool",
+			zap.String("replica", rep.name),
+			zap.Duration("lag", lag),
+			zap.Duration("evicted_for", now.Sub(*status.EvictedSince)),
+		)
+		status.Healthy = true
+		status.EvictedSince = nil
+	}
+}
+
+// Run 按间隔检查从库，直到 ctx 取消
+func (r *ReplicaRouter) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.CheckReplicas(ctx)
+		}
+	}
+}
+
+// Health 返回所有从库的健康状态
+func (r *ReplicaRouter) Health() *ReplicaHealth {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	health := &ReplicaHealth{
+		MaxLagSeconds: r.config.MaxLag.Seconds(),
+		Replicas:      make([]*ReplicaStatus, 0, len(r.replicas)),
+	}
+	for _, rep := range r.replicas {
+		status := rep.status
+		if status.Healthy {
+			health.HealthyReplicas++
+		}
+		health.Replicas = append(health.Replicas, &status)
+	}
+	return health
+}
+
+// samplePrimary 采样主库当前的 WAL 位置，只保留计算延迟需要的采样
+// 早于 MaxLag+CheckInterval 的采样不再需要：落后于它们的从库无论如何都会被移出
+func (r *ReplicaRouter) samplePrimary(ctx context.Context) {
+	r.mu.RLock()
+	primary := r.primary
+	r.mu.RUnlock()
+	if primary == nil {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, r.config.CheckTimeout)
+	defer cancel()
+
+	var text string
+	if err := primary.QueryRowContext(checkCtx, primaryWALQuery).Scan(&text); err != nil {
+		r.logger.Warn("Failed to sample primary WAL position", zap.Error(err))
+		return
+	}
+	lsn, err := parseLSN(text)
+	if err != nil {
+		r.logger.Warn("Failed to parse primary WAL position", zap.String("lsn", text), zap.Error(err))
+		return
+	}
+	r.recordPrimaryLSN(lsn)
+}
+
+// recordPrimaryLSN 记录一次主库 WAL 位置采样
+func (r *ReplicaRouter) recordPrimaryLSN(lsn uint64) {
+	now := r.now()
+	cutoff := now.Add(-(r.config.MaxLag + r.config.CheckInterval))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	samples := r.walSamples
+	for len(samples) > 0 && samples[0].at.Before(cutoff) {
+		samples = samples[1:]
+	}
+	r.walSamples = append(samples, walSample{lsn: lsn, at: now})
+}
+
+// lagBehindPrimary 回放到 replayLSN 的从库落后主库的时间，没有主库采样时返回 0
+func (r *ReplicaRouter) lagBehindPrimary(replayLSN uint64) time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, sample := range r.walSamples {
+		if sample.lsn > replayLSN {
+			return r.now().Sub(sample.at)
+		}
+	}
+	return 0
+}
+
+// queryReplicaLag 在从库上查询复制延迟
+// 不处于恢复模式（不是从库）时延迟为 0；WAL 接收进程不在 streaming 状态时返回错误
+func (r *ReplicaRouter) queryReplicaLag(ctx context.Context, conn *sql.DB) (time.Duration, error) {
+	var inRecovery bool
+	var status, replayText string
+	if err := conn.QueryRowContext(ctx, replicaStateQuery).Scan(&inRecovery, &status, &replayText); err != nil {
+		return 0, err
+	}
+	if !inRecovery {
+		return 0, nil
+	}
+	if status != "streaming" {
+		return 0, fmt.Errorf("WAL receiver is not streaming (status %q)", status)
+	}
+
+	replayLSN, err := parseLSN(replayText)
+	if err != nil {
+		return 0, err
+	}
+	return r.lagBehindPrimary(replayLSN), nil
+}
+
+// parseLSN 解析 PostgreSQL 的 pg_lsn 文本（如 16/B374D848）
+func parseLSN(text string) (uint64, error) {
+	high, low, ok := strings.Cut(text, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid LSN %q", text)
+	}
+	hi, err := strconv.ParseUint(high, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", text, err)
+	}
+	lo, err := strconv.ParseUint(low, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", text, err)
+	}
+	return hi<<32 | lo, nil
+}
+
+// primaryContextKey 标记读主库的上下文
+type primaryContextKey struct{}
+
+// WithPrimary 返回要求读主库的上下文，用于写入后立即读取的请求
+//
+//	db.WithContext(database.WithPrimary(ctx)).First(&config, id)
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// IsPrimaryContext 上下文是否要求读主库
+func IsPrimaryContext(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryContextKey{}).(bool)
+	return primary
+}
@@ -0,0 +1,231 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// routedRow 记录数据来自哪个库的测试表
+type routedRow struct {
+	ID     int64 `gorm:"primaryKey"`
+	Source string
+}
+
+// routedConfig 需要写后读一致性的测试表
+type routedConfig struct {
+	ID     int64 `gorm:"primaryKey"`
+	Source string
+}
+
+// replicaRouterFixture 主库和两个从库分别是独立的 sqlite 文件，每个库写入不同的 source，
+// 查询结果的 source 说明查询被路由到了哪个库
+type replicaRouterFixture struct {
+	db     *gorm.DB
+	router *ReplicaRouter
+
+	mu   sync.Mutex
+	lags map[*sql.DB]time.Duration
+	errs map[*sql.DB]error
+	now  time.Time
+}
+
+func setupReplicaRouterFixture(t *testing.T, replicaNames ...string) *replicaRouterFixture {
+	dir := t.TempDir()
+	open := func(name string) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(dir, name+".db")), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&routedRow{}, &routedConfig{}))
+		require.NoError(t, db.Create(&routedRow{ID: 1, Source: name}).Error)
+		require.NoError(t, db.Create(&routedConfig{ID: 1, Source: name}).Error)
+		return db
+	}
+
+	f := &replicaRouterFixture{
+		db:   open("primary"),
+		lags: make(map[*sql.DB]time.Duration),
+		errs: make(map[*sql.DB]error),
+		now:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	config := DefaultReplicaRouterConfig()
+	config.ReadYourWritesTables = []string{"routed_configs"}
+	f.router = NewReplicaRouter(config, nil)
+	f.router.checkLag = func(ctx context.Context, conn *sql.DB) (time.Duration, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.lags[conn], f.errs[conn]
+	}
+	f.router.now = func() time.Time {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.now
+	}
+
+	dialectors := make([]gorm.Dialector, 0, len(replicaNames))
+	for _, name := range replicaNames {
+		conn, err := open(name).DB()
+		require.NoError(t, err)
+		f.router.AddReplica(name, conn)
+		dialectors = append(dialectors, sqlite.New(sqlite.Config{Conn: conn}))
+	}
+	require.NoError(t, f.db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   f.router,
+	})))
+	require.NoError(t, f.router.Install(f.db))
+
+	return f
+}
+
+func (f *replicaRouterFixture) setLag(name string, lag time.Duration, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, rep := range f.router.replicas {
+		if rep.name == name {
+			f.lags[rep.conn] = lag
+			f.errs[rep.conn] = err
+		}
+	}
+}
+
+func (f *replicaRouterFixture) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// readSources 多次查询，返回结果来自的库
+func (f *replicaRouterFixture) readSources(t *testing.T, ctx context.Context, model interface{}) map[string]bool {
+	sources := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		var source string
+		require.NoError(t, f.db.WithContext(ctx).Model(model).Where("id = ?", 1).Pluck("source", &source).Error)
+		sources[source] = true
+	}
+	return sources
+}
+
+func TestReplicaRouter_EvictsLaggingReplica(t *testing.T) {
+	f := setupReplicaRouterFixture(t, "replica1", "replica2")
+	ctx := context.Background()
+
+	assert.Equal(t, map[string]bool{"replica1": true, "replica2": true}, f.readSources(t, ctx, &routedRow{}))
+
+	// replica1 延迟超过阈值，移出读池
+	f.setLag("replica1", 30*time.Second, nil)
+	f.router.CheckReplicas(ctx)
+	assert.Equal(t, map[string]bool{"replica2": true}, f.readSources(t, ctx, &routedRow{}))
+
+	health := f.router.Health()
+	assert.Equal(t, 1, health.HealthyReplicas)
+	assert.Equal(t, 5.0, health.MaxLagSeconds)
+	require.Len(t, health.Replicas, 2)
+	assert.False(t, health.Replicas[0].Healthy)
+	assert.Equal(t, 30.0, health.Replicas[0].LagSeconds)
+	assert.NotNil(t, health.Replicas[0].EvictedSince)
+	assert.True(t, health.Replicas[1].Healthy)
+
+	// 追上后恢复
+	f.setLag("replica1", time.Second, nil)
+	f.router.CheckReplicas(ctx)
+	assert.Equal(t, map[string]bool{"replica1": true, "replica2": true}, f.readSources(t, ctx, &routedRow{}))
+	assert.Nil(t, f.router.Health().Replicas[0].EvictedSince)
+}
+
+func TestReplicaRouter_FallsBackToPrimary(t *testing.T) {
+	f := setupReplicaRouterFixture(t, "replica1")
+	ctx := context.Background()
+
+	assert.Equal(t, map[string]bool{"replica1": true}, f.readSources(t, ctx, &routedRow{}))
+
+	// 唯一的从库不可达，读取走主库
+	f.setLag("replica1", 0, errors.New("connection refused"))
+	f.router.CheckReplicas(ctx)
+	assert.Equal(t, map[string]bool{"primary": true}, f.readSources(t, ctx, &routedRow{}))
+
+	health := f.router.Health()
+	assert.Equal(t, 0, health.HealthyReplicas)
+	assert.Equal(t, "connection refused", health.Replicas[0].LastError)
+
+	var source string
+	require.NoError(t, f.db.Raw("SELECT source FROM routed_rows WHERE id = 1").Scan(&source).Error)
+	assert.Equal(t, "primary", source)
+}
+
+func TestReplicaRouter_ReadYourWrites(t *testing.T) {
+	f := setupReplicaRouterFixture(t, "replica1", "replica2")
+	ctx := context.Background()
+
+	require.NoError(t, f.db.Model(&routedConfig{}).Where("id = ?", 1).Update("source", "primary-updated").Error)
+
+	// 写入后窗口内读取同一张表走主库
+	assert.Equal(t, map[string]bool{"primary-updated": true}, f.readSources(t, ctx, &routedConfig{}))
+	// 其他表不受影响
+	assert.Equal(t, map[string]bool{"replica1": true, "replica2": true}, f.readSources(t, ctx, &routedRow{}))
+
+	// 窗口过后恢复从从库读取
+	f.advance(DefaultReplicaRouterConfig().ReadYourWritesWindow)
+	assert.Equal(t, map[string]bool{"replica1": true, "replica2": true}, f.readSources(t, ctx, &routedConfig{}))
+
+	// 不在列表中的表写入后不改变路由
+	require.NoError(t, f.db.Create(&routedRow{ID: 2, Source: "primary"}).Error)
+	assert.Equal(t, map[string]bool{"replica1": true, "replica2": true}, f.readSources(t, ctx, &routedRow{}))
+}
+
+func TestReplicaRouter_WithPrimary(t *testing.T) {
+	f := setupReplicaRouterFixture(t, "replica1", "replica2")
+
+	assert.Equal(t, map[string]bool{"primary": true}, f.readSources(t, WithPrimary(context.Background()), &routedRow{}))
+	assert.True(t, IsPrimaryContext(WithPrimary(context.Background())))
+	assert.False(t, IsPrimaryContext(context.Background()))
+}
+
+func TestReplicaRouter_LagBehindPrimary(t *testing.T) {
+	f := setupReplicaRouterFixture(t)
+
+	// 没有主库采样时无法比较
+	assert.Equal(t, time.Duration(0), f.router.lagBehindPrimary(100))
+
+	f.router.recordPrimaryLSN(100)
+	f.advance(5 * time.Second)
+	f.router.recordPrimaryLSN(200)
+	f.advance(5 * time.Second)
+
+	// 已回放到主库最新位置
+	assert.Equal(t, time.Duration(0), f.router.lagBehindPrimary(200))
+	// 停止接收 WAL 的从库停在第一次采样之前，延迟随时间增长
+	assert.Equal(t, 10*time.Second, f.router.lagBehindPrimary(50))
+	assert.Equal(t, 5*time.Second, f.router.lagBehindPrimary(100))
+
+	// 超过 MaxLag+CheckInterval 的采样被丢弃
+	f.advance(10 * time.Second)
+	f.router.recordPrimaryLSN(200)
+	require.Len(t, f.router.walSamples, 1)
+	assert.Equal(t, time.Duration(0), f.router.lagBehindPrimary(200))
+}
+
+func TestParseLSN(t *testing.T) {
+	lsn, err := parseLSN("16/B374D848")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x16B374D848), lsn)
+
+	lsn, err = parseLSN("0/0")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), lsn)
+
+	_, err = parseLSN("")
+	assert.Error(t, err)
+	_, err = parseLSN("x/1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionChecker 校验 Token 是否具有权限（data_collection.AuthManager 实现该接口）
+type PermissionChecker interface {
+	CheckPermission(token string, permission string) (bool, error)
+}
+
+// RequirePermission 要求请求的 Authorization: Bearer Token 具有指定权限
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		token := strings.TrimPrefix(authHeader, "Bearer ")
+		if !strings.HasPrefix(authHeader, "Bearer ") || token == "" {
+			abortWithError(c, http.StatusUnauthorized, "UNAUTHORIZED", "未提供Token")
+			return
+		}
+
+		allowed, err := checker.CheckPermission(token, permission)
+		if err != nil {
+			abortWithError(c, http.StatusUnauthorized, "UNAUTHORIZED", "Token验证失败")
+			return
+		}
+		if !allowed {
+			abortWithError(c, http.StatusForbidden, "FORBIDDEN", "缺少 "+permission+" 权限")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// abortWithError 以统一的API响应格式终止请求
+func abortWithError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"success":   false,
+		"message":   message,
+		"code":      code,
+		"timestamp": time.Now().Unix(),
+	})
+}
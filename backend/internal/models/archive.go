@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// 可归档的表
+const (
+	ArchiveTablePriceTicks = "price_ticks"
+	ArchiveTableKlines     = "klines"
+)
+
+// ArchiveManifest 已归档为 Parquet 的数据，每个 表/交易对/天 一个文件
+type ArchiveManifest struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	SourceTable  string    `gorm:"type:varchar(50);not null;uniqueIndex:uq_archive_manifests_table_symbol_day,priority:1" json:"source_table"`
+	Symbol       string    `gorm:"type:varchar(50);not null;uniqueIndex:uq_archive_manifests_table_symbol_day,priority:2" json:"symbol"`
+	Day          time.Time `gorm:"type:date;not null;uniqueIndex:uq_archive_manifests_table_symbol_day,priority:3" json:"day"` // 数据所属日期（UTC 零点）
+	Path         string    `gorm:"type:text;not null" json:"path"`                                                             // 文件在归档存储中的路径
+	RowCount     int64     `gorm:"not null" json:"row_count"`
+	MinTimestamp time.Time `gorm:"not null" json:"min_timestamp"`
+	MaxTimestamp time.Time `gorm:"not null" json:"max_timestamp"`
+	SizeBytes    int64     `gorm:"not null" json:"size_bytes"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (ArchiveManifest) TableName() string {
+	return "archive_manifests"
+}
+
+// IsArchiveTable 是否为可归档的表
+func IsArchiveTable(table string) bool {
+	return table == ArchiveTablePriceTicks || table == ArchiveTableKlines
+}
+
+// ArchiveDay 返回时间所属日期（UTC 零点）
+func ArchiveDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
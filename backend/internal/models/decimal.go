@@ -0,0 +1,62 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// 价格和数量使用 decimal.Decimal 表示，与数据库的 decimal(20,8) 列和交易所返回的字符串一一对应，
+// 避免 float64 在解析、存储和价差计算中引入舍入误差
+
+// JSONDecimal 在 JSON 中输出为数字（不带引号）的 decimal，保持 API 响应格式不变
+// decimal.Decimal 默认输出为字符串；这里不修改全局的 decimal.MarshalJSONWithoutQuotes，
+// 由需要输出数字的结构体和响应显式转换
+type JSONDecimal decimal.Decimal
+
+// MarshalJSON 输出为 JSON 数字
+func (d JSONDecimal) MarshalJSON() ([]byte, error) {
+	return []byte(decimal.Decimal(d).String()), nil
+}
+
+// UnmarshalJSON 接受数字或字符串
+func (d *JSONDecimal) UnmarshalJSON(data []byte) error {
+	return (*decimal.Decimal)(d).UnmarshalJSON(data)
+}
+
+// JSONDecimalPtr 把可选的 decimal 转换为 JSONDecimal，nil 保持为 nil
+func JSONDecimalPtr(d *decimal.Decimal) *JSONDecimal {
+	if d == nil {
+		return nil
+	}
+	v := JSONDecimal(*d)
+	return &v
+}
+
+// DecimalPtr 返回 d 的指针，用于可选的价格字段
+func DecimalPtr(d decimal.Decimal) *decimal.Decimal {
+	return &d
+}
+
+// DecimalFromFloatPtr 把可选的 float64 转换为 decimal，nil 保持为 nil
+func DecimalFromFloatPtr(f *float64) *decimal.Decimal {
+	if f == nil {
+		return nil
+	}
+	return DecimalPtr(decimal.NewFromFloat(*f))
+}
+
+// FloatFromDecimalPtr 把可选的 decimal 转换为 float64，用于统计计算等不要求精确的场景
+func FloatFromDecimalPtr(d *decimal.Decimal) *float64 {
+	if d == nil {
+		return nil
+	}
+	f := d.InexactFloat64()
+	return &f
+}
+
+// RoundToStep 把 value 四舍五入到 step 的整数倍，step 不大于 0 时原样返回
+func RoundToStep(value, step decimal.Decimal) decimal.Decimal {
+	if step.Sign() <= 0 {
+		return value
+	}
+	return value.Div(step).Round(0).Mul(step)
+}
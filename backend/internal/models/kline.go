@@ -1,24 +1,58 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // Kline K线数据模型
 type Kline struct {
-	Symbol      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_klines_unique,priority:1;index:idx_klines_symbol_granularity_timestamp,priority:1" json:"symbol"`
-	Timestamp   time.Time `gorm:"not null;uniqueIndex:idx_klines_unique,priority:2;index:idx_klines_symbol_granularity_timestamp,priority:3,sort:desc;index:idx_klines_timestamp,sort:desc" json:"timestamp"`
-	Granularity string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_klines_unique,priority:3;index:idx_klines_symbol_granularity_timestamp,priority:2;index:idx_klines_granularity" json:"granularity"`
-	Open        float64   `gorm:"type:decimal(20,8);not null" json:"open"`
-	High        float64   `gorm:"type:decimal(20,8);not null" json:"high"`
-	Low         float64   `gorm:"type:decimal(20,8);not null" json:"low"`
-	Close       float64   `gorm:"type:decimal(20,8);not null" json:"close"`
-	BaseVolume  float64   `gorm:"type:decimal(30,8);not null" json:"base_volume"`
-	QuoteVolume float64   `gorm:"type:decimal(30,8);not null" json:"quote_volume"`
-	CreatedAt   time.Time `json:"created_at"`
+	Symbol      string          `gorm:"type:varchar(50);not null;uniqueIndex:idx_klines_unique,priority:1;index:idx_klines_symbol_granularity_timestamp,priority:1" json:"symbol"`
+	Timestamp   time.Time       `gorm:"not null;uniqueIndex:idx_klines_unique,priority:2;index:idx_klines_symbol_granularity_timestamp,priority:3,sort:desc;index:idx_klines_timestamp,sort:desc" json:"timestamp"`
+	Granularity string          `gorm:"type:varchar(10);not null;uniqueIndex:idx_klines_unique,priority:3;index:idx_klines_symbol_granularity_timestamp,priority:2;index:idx_klines_granularity" json:"granularity"`
+	Open        decimal.Decimal `gorm:"type:decimal(20,8);not null" json:"open"`
+	High        decimal.Decimal `gorm:"type:decimal(20,8);not null" json:"high"`
+	Low         decimal.Decimal `gorm:"type:decimal(20,8);not null" json:"low"`
+	Close       decimal.Decimal `gorm:"type:decimal(20,8);not null" json:"close"`
+	BaseVolume  decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"base_volume"`
+	QuoteVolume decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"quote_volume"`
+	CreatedAt   time.Time       `json:"created_at"`
 }
 
 // TableName 指定表名
 func (Kline) TableName() string {
 	return "klines"
 }
+
+// LocalKlineGranularityPrefix 由行情流本地聚合的K线落库时的周期前缀
+// 本地K线与交易所K线使用不同的周期（如 local_1m 与 1m），合并写入时不会覆盖交易所K线
+const LocalKlineGranularityPrefix = "local_"
+
+// LocalKlineGranularity 返回本地聚合K线落库使用的周期
+func LocalKlineGranularity(interval string) string {
+	return LocalKlineGranularityPrefix + interval
+}
+
+// MarshalJSON 价格和成交量输出为 JSON 数字
+func (k Kline) MarshalJSON() ([]byte, error) {
+	type kline Kline
+	return json.Marshal(struct {
+		kline
+		Open        JSONDecimal `json:"open"`
+		High        JSONDecimal `json:"high"`
+		Low         JSONDecimal `json:"low"`
+		Close       JSONDecimal `json:"close"`
+		BaseVolume  JSONDecimal `json:"base_volume"`
+		QuoteVolume JSONDecimal `json:"quote_volume"`
+	}{
+		kline:       kline(k),
+		Open:        JSONDecimal(k.Open),
+		High:        JSONDecimal(k.High),
+		Low:         JSONDecimal(k.Low),
+		Close:       JSONDecimal(k.Close),
+		BaseVolume:  JSONDecimal(k.BaseVolume),
+		QuoteVolume: JSONDecimal(k.QuoteVolume),
+	})
+}
@@ -10,7 +10,7 @@ import (
 
 // MonitoringConfigFilters 监控配置过滤器
 type MonitoringConfigFilters struct {
-	TimeWindows     []string `json:"time_windows"`         // 时间窗口 ["1m", "5m", "15m"]
+	TimeWindows     []string `json:"time_windows"`         // 时间窗口，如 ["10s", "1m", "5m", "4h"]
 	ChangeThreshold float64  `json:"change_threshold"`     // 变化阈值
 	VolumeThreshold float64  `json:"volume_threshold"`     // 成交量阈值
 	Symbols         []string `json:"symbols"`              // 指定交易对
@@ -82,6 +82,12 @@ func (mc *MonitoringConfig) IsValid() error {
 		return &ValidationError{Field: "filters.time_windows", Message: "时间窗口不能为空"}
 	}
 
+	for _, window := range mc.Filters.TimeWindows {
+		if _, err := ParseTimeWindow(window); err != nil {
+			return &ValidationError{Field: "filters.time_windows", Message: err.Error()}
+		}
+	}
+
 	if mc.Filters.ChangeThreshold <= 0 {
 		return &ValidationError{Field: "filters.change_threshold", Message: "变化阈值必须大于0"}
 	}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// PriceChangeRate 价格变化率数据模型
+type PriceChangeRate struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Symbol      string    `gorm:"type:varchar(50);not null;index:idx_price_change_rates_symbol_timestamp,priority:1" json:"symbol"`
+	Timestamp   time.Time `gorm:"not null;index:idx_price_change_rates_symbol_timestamp,priority:2,sort:desc" json:"timestamp"`
+	WindowSize  string    `gorm:"column:window_size;type:varchar(10);not null;index:idx_price_change_rates_window_size" json:"window_size"`
+	ChangeRate  float64   `gorm:"column:change_rate;type:decimal(10,6);not null" json:"change_rate"`
+	PriceBefore float64   `gorm:"column:price_before;type:decimal(20,8);not null" json:"price_before"`
+	PriceAfter  float64   `gorm:"column:price_after;type:decimal(20,8);not null" json:"price_after"`
+	Volume24h   *float64  `gorm:"column:volume_24h;type:decimal(20,8)" json:"volume_24h,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (PriceChangeRate) TableName() string {
+	return "price_change_rates"
+}
@@ -1,38 +1,81 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // PriceTick 实时价格数据模型
 type PriceTick struct {
-	Symbol            string    `gorm:"type:varchar(50);not null;index:idx_price_ticks_symbol_timestamp,priority:1" json:"symbol"`
-	Timestamp         time.Time `gorm:"not null;index:idx_price_ticks_symbol_timestamp,priority:2,sort:desc;index:idx_price_ticks_timestamp,sort:desc" json:"timestamp"`
-	LastPrice         float64   `gorm:"column:last_price;type:decimal(20,8);not null" json:"last_price"`
-	AskPrice          *float64  `gorm:"column:ask_price;type:decimal(20,8)" json:"ask_price,omitempty"`
-	BidPrice          *float64  `gorm:"column:bid_price;type:decimal(20,8)" json:"bid_price,omitempty"`
-	BidSize           *float64  `gorm:"column:bid_size;type:decimal(20,8)" json:"bid_size,omitempty"`
-	AskSize           *float64  `gorm:"column:ask_size;type:decimal(20,8)" json:"ask_size,omitempty"`
-	High24h           *float64  `gorm:"column:high_24h;type:decimal(20,8)" json:"high_24h,omitempty"`
-	Low24h            *float64  `gorm:"column:low_24h;type:decimal(20,8)" json:"low_24h,omitempty"`
-	Change24h         *float64  `gorm:"column:change_24h;type:decimal(10,4)" json:"change_24h,omitempty"`
-	BaseVolume        *float64  `gorm:"column:base_volume;type:decimal(30,8)" json:"base_volume,omitempty"`
-	QuoteVolume       *float64  `gorm:"column:quote_volume;type:decimal(30,8)" json:"quote_volume,omitempty"`
-	UsdtVolume        *float64  `gorm:"column:usdt_volume;type:decimal(30,8)" json:"usdt_volume,omitempty"`
-	OpenUtc           *float64  `gorm:"column:open_utc;type:decimal(20,8)" json:"open_utc,omitempty"`
-	ChangeUtc24h      *float64  `gorm:"column:change_utc_24h;type:decimal(10,4)" json:"change_utc_24h,omitempty"`
-	IndexPrice        *float64  `gorm:"column:index_price;type:decimal(20,8)" json:"index_price,omitempty"`
-	FundingRate       *float64  `gorm:"column:funding_rate;type:decimal(10,6)" json:"funding_rate,omitempty"`
-	HoldingAmount     *float64  `gorm:"column:holding_amount;type:decimal(30,8)" json:"holding_amount,omitempty"`
-	Open24h           *float64  `gorm:"column:open_24h;type:decimal(20,8)" json:"open_24h,omitempty"`
-	MarkPrice         *float64  `gorm:"column:mark_price;type:decimal(20,8)" json:"mark_price,omitempty"`
-	DeliveryStartTime *int64    `json:"delivery_start_time,omitempty"`
-	DeliveryTime      *int64    `json:"delivery_time,omitempty"`
-	DeliveryStatus    string    `gorm:"type:varchar(30)" json:"delivery_status,omitempty"`
-	CreatedAt         time.Time `json:"created_at"`
+	Symbol            string           `gorm:"type:varchar(50);not null;index:idx_price_ticks_symbol_timestamp,priority:1" json:"symbol"`
+	Timestamp         time.Time        `gorm:"not null;index:idx_price_ticks_symbol_timestamp,priority:2,sort:desc;index:idx_price_ticks_timestamp,sort:desc" json:"timestamp"`
+	LastPrice         decimal.Decimal  `gorm:"column:last_price;type:decimal(20,8);not null" json:"last_price"`
+	AskPrice          *decimal.Decimal `gorm:"column:ask_price;type:decimal(20,8)" json:"ask_price,omitempty"`
+	BidPrice          *decimal.Decimal `gorm:"column:bid_price;type:decimal(20,8)" json:"bid_price,omitempty"`
+	BidSize           *decimal.Decimal `gorm:"column:bid_size;type:decimal(20,8)" json:"bid_size,omitempty"`
+	AskSize           *decimal.Decimal `gorm:"column:ask_size;type:decimal(20,8)" json:"ask_size,omitempty"`
+	High24h           *decimal.Decimal `gorm:"column:high_24h;type:decimal(20,8)" json:"high_24h,omitempty"`
+	Low24h            *decimal.Decimal `gorm:"column:low_24h;type:decimal(20,8)" json:"low_24h,omitempty"`
+	Change24h         *float64         `gorm:"column:change_24h;type:decimal(10,4)" json:"change_24h,omitempty"`
+	BaseVolume        *decimal.Decimal `gorm:"column:base_volume;type:decimal(30,8)" json:"base_volume,omitempty"`
+	QuoteVolume       *decimal.Decimal `gorm:"column:quote_volume;type:decimal(30,8)" json:"quote_volume,omitempty"`
+	UsdtVolume        *decimal.Decimal `gorm:"column:usdt_volume;type:decimal(30,8)" json:"usdt_volume,omitempty"`
+	OpenUtc           *decimal.Decimal `gorm:"column:open_utc;type:decimal(20,8)" json:"open_utc,omitempty"`
+	ChangeUtc24h      *float64         `gorm:"column:change_utc_24h;type:decimal(10,4)" json:"change_utc_24h,omitempty"`
+	IndexPrice        *decimal.Decimal `gorm:"column:index_price;type:decimal(20,8)" json:"index_price,omitempty"`
+	FundingRate       *float64         `gorm:"column:funding_rate;type:decimal(10,6)" json:"funding_rate,omitempty"`
+	HoldingAmount     *decimal.Decimal `gorm:"column:holding_amount;type:decimal(30,8)" json:"holding_amount,omitempty"`
+	Open24h           *decimal.Decimal `gorm:"column:open_24h;type:decimal(20,8)" json:"open_24h,omitempty"`
+	MarkPrice         *decimal.Decimal `gorm:"column:mark_price;type:decimal(20,8)" json:"mark_price,omitempty"`
+	DeliveryStartTime *int64           `json:"delivery_start_time,omitempty"`
+	DeliveryTime      *int64           `json:"delivery_time,omitempty"`
+	DeliveryStatus    string           `gorm:"type:varchar(30)" json:"delivery_status,omitempty"`
+	CreatedAt         time.Time        `json:"created_at"`
 }
 
 // TableName 指定表名
 func (PriceTick) TableName() string {
 	return "price_ticks"
 }
+
+// MarshalJSON 价格和数量输出为 JSON 数字
+func (t PriceTick) MarshalJSON() ([]byte, error) {
+	type priceTick PriceTick
+	return json.Marshal(struct {
+		priceTick
+		LastPrice     JSONDecimal  `json:"last_price"`
+		AskPrice      *JSONDecimal `json:"ask_price,omitempty"`
+		BidPrice      *JSONDecimal `json:"bid_price,omitempty"`
+		BidSize       *JSONDecimal `json:"bid_size,omitempty"`
+		AskSize       *JSONDecimal `json:"ask_size,omitempty"`
+		High24h       *JSONDecimal `json:"high_24h,omitempty"`
+		Low24h        *JSONDecimal `json:"low_24h,omitempty"`
+		BaseVolume    *JSONDecimal `json:"base_volume,omitempty"`
+		QuoteVolume   *JSONDecimal `json:"quote_volume,omitempty"`
+		UsdtVolume    *JSONDecimal `json:"usdt_volume,omitempty"`
+		OpenUtc       *JSONDecimal `json:"open_utc,omitempty"`
+		IndexPrice    *JSONDecimal `json:"index_price,omitempty"`
+		HoldingAmount *JSONDecimal `json:"holding_amount,omitempty"`
+		Open24h       *JSONDecimal `json:"open_24h,omitempty"`
+		MarkPrice     *JSONDecimal `json:"mark_price,omitempty"`
+	}{
+		priceTick:     priceTick(t),
+		LastPrice:     JSONDecimal(t.LastPrice),
+		AskPrice:      JSONDecimalPtr(t.AskPrice),
+		BidPrice:      JSONDecimalPtr(t.BidPrice),
+		BidSize:       JSONDecimalPtr(t.BidSize),
+		AskSize:       JSONDecimalPtr(t.AskSize),
+		High24h:       JSONDecimalPtr(t.High24h),
+		Low24h:        JSONDecimalPtr(t.Low24h),
+		BaseVolume:    JSONDecimalPtr(t.BaseVolume),
+		QuoteVolume:   JSONDecimalPtr(t.QuoteVolume),
+		UsdtVolume:    JSONDecimalPtr(t.UsdtVolume),
+		OpenUtc:       JSONDecimalPtr(t.OpenUtc),
+		IndexPrice:    JSONDecimalPtr(t.IndexPrice),
+		HoldingAmount: JSONDecimalPtr(t.HoldingAmount),
+		Open24h:       JSONDecimalPtr(t.Open24h),
+		MarkPrice:     JSONDecimalPtr(t.MarkPrice),
+	})
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StoragePeriodNever 表示移除策略（不压缩或永久保留）
+const StoragePeriodNever = "never"
+
+// 存储对象类型
+const (
+	StorageKindHypertable          = "hypertable"           // 超表
+	StorageKindContinuousAggregate = "continuous_aggregate" // 连续聚合
+)
+
+// StorageTable 超表或连续聚合的压缩、保留策略和空间占用
+type StorageTable struct {
+	Name               string  `json:"name"`
+	Kind               string  `json:"kind"`
+	CompressionEnabled bool    `json:"compression_enabled"`
+	CompressAfter      *string `json:"compress_after,omitempty"` // 压缩策略的时间阈值（PostgreSQL interval），为空表示没有压缩策略
+	DropAfter          *string `json:"drop_after,omitempty"`     // 保留策略的时间阈值，为空表示永久保留
+	TotalChunks        int64   `json:"total_chunks"`
+	CompressedChunks   int64   `json:"compressed_chunks"`
+	TotalBytes         int64   `json:"total_bytes"`              // 当前占用空间（压缩后）
+	BeforeCompression  int64   `json:"before_compression_bytes"` // 已压缩 chunk 压缩前的大小
+	AfterCompression   int64   `json:"after_compression_bytes"`  // 已压缩 chunk 压缩后的大小
+	CompressionRatio   float64 `json:"compression_ratio"`        // 压缩比（压缩前/压缩后），没有已压缩 chunk 时为0
+}
+
+// StorageChunk 超表的单个 chunk
+type StorageChunk struct {
+	Schema            string    `json:"schema"`
+	Name              string    `json:"name"`
+	RangeStart        time.Time `json:"range_start"`
+	RangeEnd          time.Time `json:"range_end"`
+	IsCompressed      bool      `json:"is_compressed"`
+	TotalBytes        int64     `json:"total_bytes"`
+	BeforeCompression int64     `json:"before_compression_bytes"`
+	AfterCompression  int64     `json:"after_compression_bytes"`
+	CompressionRatio  float64   `json:"compression_ratio"`
+}
+
+// KlineRetentionPolicy K线按周期的保留期
+type KlineRetentionPolicy struct {
+	Granularity   string    `gorm:"type:varchar(10);primaryKey" json:"granularity"`
+	RetentionDays *int      `gorm:"column:retention_days" json:"retention_days"` // 为空表示永久保留
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (KlineRetentionPolicy) TableName() string {
+	return "kline_retention_policies"
+}
+
+// CompressionRatio 计算压缩比，压缩后大小为0时返回0
+func CompressionRatio(before, after int64) float64 {
+	if after <= 0 {
+		return 0
+	}
+	return float64(before) / float64(after)
+}
+
+// ParseStoragePeriod 解析策略时长（数字+单位 h/d/w，如 12h、14d、8w），
+// "never" 返回空表示移除策略
+func ParseStoragePeriod(period string) (*time.Duration, error) {
+	period = strings.TrimSpace(period)
+	if period == StoragePeriodNever {
+		return nil, nil
+	}
+	if len(period) < 2 {
+		return nil, fmt.Errorf("无效的策略时长: %q", period)
+	}
+
+	value, err := strconv.Atoi(period[:len(period)-1])
+	if err != nil || value <= 0 {
+		return nil, fmt.Errorf("无效的策略时长: %q", period)
+	}
+
+	var unit time.Duration
+	switch period[len(period)-1] {
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return nil, fmt.Errorf("无效的策略时长单位: %q", period)
+	}
+
+	duration := time.Duration(value) * unit
+	return &duration, nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 时间窗口格式：正整数 + 单位（s 秒、m 分钟、h 小时、d 天），如 10s、3m、4h、1d
+
+// MaxTimeWindow 支持的最大时间窗口
+const MaxTimeWindow = 7 * 24 * time.Hour
+
+// ParseTimeWindow 解析时间窗口字符串
+func ParseTimeWindow(window string) (time.Duration, error) {
+	window = strings.TrimSpace(window)
+	if len(window) < 2 || window[0] < '1' || window[0] > '9' {
+		return 0, fmt.Errorf("无效的时间窗口: %q", window)
+	}
+
+	value, err := strconv.Atoi(window[:len(window)-1])
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("无效的时间窗口: %q", window)
+	}
+
+	var unit time.Duration
+	switch window[len(window)-1] {
+	case 's':
+		unit = time.Second
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("无效的时间窗口单位: %q", window)
+	}
+
+	duration := time.Duration(value) * unit
+	if duration > MaxTimeWindow {
+		return 0, fmt.Errorf("时间窗口 %q 超过最大值 %v", window, MaxTimeWindow)
+	}
+	return duration, nil
+}
@@ -0,0 +1,198 @@
+package websocket
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// setupAuthServer 创建启用认证的测试服务器
+func setupAuthServer(t *testing.T, config *ServerConfig) (*WebSocketServerImpl, data_collection.AuthManager, string) {
+	authConfig := data_collection.DefaultAuthConfig()
+	authConfig.Enabled = true
+	authConfig.SecretKey = "test-secret-key"
+	authManager := data_collection.NewAuthManager(authConfig, zap.NewNop())
+
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	server.SetAuthManager(authManager)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	t.Cleanup(httpServer.Close)
+	return server, authManager, "ws" + strings.TrimPrefix(httpServer.URL, "http")
+}
+
+// dialWithToken 通过查询参数携带 Token 建立连接
+func dialWithToken(t *testing.T, wsURL, token string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+url.QueryEscape(token), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readCloseCode 读取直到连接关闭，返回关闭码
+func readCloseCode(t *testing.T, conn *websocket.Conn) int {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		require.True(t, errors.As(err, &closeErr), "期望关闭帧，实际: %v", err)
+		return closeErr.Code
+	}
+}
+
+// TestWebSocketServer_Auth 测试握手认证
+func TestWebSocketServer_Auth(t *testing.T) {
+	config := DefaultServerConfig()
+	config.PongWait = 5 * time.Second
+	server, authManager, wsURL := setupAuthServer(t, config)
+
+	t.Run("缺少Token", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		assert.Equal(t, CloseCodeUnauthorized, readCloseCode(t, conn))
+	})
+
+	t.Run("无效Token", func(t *testing.T) {
+		conn := dialWithToken(t, wsURL, "invalid-token")
+		assert.Equal(t, CloseCodeUnauthorized, readCloseCode(t, conn))
+	})
+
+	t.Run("权限不足", func(t *testing.T) {
+		token, err := authManager.GenerateToken("user-write", []string{"write"})
+		require.NoError(t, err)
+		conn := dialWithToken(t, wsURL, token)
+		assert.Equal(t, CloseCodeUnauthorized, readCloseCode(t, conn))
+	})
+
+	t.Run("查询参数", func(t *testing.T) {
+		token, err := authManager.GenerateToken("user-query", []string{"read"})
+		require.NoError(t, err)
+		conn := dialWithToken(t, wsURL, token)
+
+		require.NoError(t, conn.WriteJSON(Message{Type: MessageTypePing}))
+		var pong Message
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		require.NoError(t, conn.ReadJSON(&pong))
+		assert.Equal(t, MessageTypePong, pong.Type)
+
+		connections := server.GetConnections()
+		require.Len(t, connections, 1)
+		assert.Equal(t, "user-query", connections[0].UserID)
+		assert.False(t, connections[0].ExpiresAt.IsZero())
+	})
+
+	t.Run("子协议", func(t *testing.T) {
+		token, err := authManager.GenerateToken("user-protocol", []string{"read"})
+		require.NoError(t, err)
+		raw, err := base64.StdEncoding.DecodeString(token)
+		require.NoError(t, err)
+
+		dialer := &websocket.Dialer{Subprotocols: []string{tokenProtocol, base64.RawURLEncoding.EncodeToString(raw)}}
+		conn, resp, err := dialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		assert.Equal(t, tokenProtocol, resp.Header.Get("Sec-WebSocket-Protocol"))
+		assert.Equal(t, tokenProtocol, conn.Subprotocol())
+
+		require.NoError(t, conn.WriteJSON(Message{Type: MessageTypePing}))
+		var pong Message
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		require.NoError(t, conn.ReadJSON(&pong))
+		assert.Equal(t, MessageTypePong, pong.Type)
+	})
+}
+
+// TestWebSocketServer_UserLimits 测试单用户连接数和交易对订阅上限
+func TestWebSocketServer_UserLimits(t *testing.T) {
+	config := DefaultServerConfig()
+	config.PongWait = 5 * time.Second
+	config.MaxConnectionsPerUser = 2
+	config.MaxSymbolsPerUser = 2
+	server, authManager, wsURL := setupAuthServer(t, config)
+
+	token, err := authManager.GenerateToken("user-1", []string{"read"})
+	require.NoError(t, err)
+
+	first := dialWithToken(t, wsURL, token)
+	second := dialWithToken(t, wsURL, token)
+	third := dialWithToken(t, wsURL, token)
+	assert.Equal(t, CloseCodeConnectionLimit, readCloseCode(t, third))
+
+	// 其他用户不受影响
+	otherToken, err := authManager.GenerateToken("user-2", []string{"read"})
+	require.NoError(t, err)
+	dialWithToken(t, wsURL, otherToken)
+	assert.Eventually(t, func() bool { return server.GetConnectionCount() == 3 }, time.Second, 10*time.Millisecond)
+
+	// 两个连接合计最多订阅两个交易对，无交易对的频道不计入
+	require.NoError(t, first.WriteJSON(Message{Type: MessageTypeSubscribe, Channels: []string{"ticker:BTCUSDT", "depth:BTCUSDT", "signals"}}))
+	ack := readAck(t, first)
+	assert.Len(t, ack.Channels, 3)
+	assert.Empty(t, ack.Errors)
+
+	require.NoError(t, second.WriteJSON(Message{Type: MessageTypeSubscribe, Channels: []string{"kline:1m:BTCUSDT", "ticker:ETHUSDT", "ticker:SOLUSDT"}}))
+	ack = readAck(t, second)
+	assert.Equal(t, []string{"kline:1m:BTCUSDT", "ticker:ETHUSDT"}, ack.Channels)
+	require.Len(t, ack.Errors, 1)
+	assert.Equal(t, "ticker:SOLUSDT", ack.Errors[0].Channel)
+	assert.Equal(t, ErrCodeSymbolLimit, ack.Errors[0].Code)
+
+	// 旧版按交易对订阅同样受限
+	require.NoError(t, first.WriteJSON(Message{Type: MessageTypeSubscribe, Symbols: []string{"SOLUSDT"}}))
+	var errMsg ErrorMessage
+	first.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, first.ReadJSON(&errMsg))
+	assert.Equal(t, ErrCodeSymbolLimit, errMsg.Code)
+
+	// 连接断开后释放名额
+	second.Close()
+	assert.Eventually(t, func() bool { return server.GetConnectionCount() == 2 }, time.Second, 10*time.Millisecond)
+	fourth := dialWithToken(t, wsURL, token)
+	require.NoError(t, fourth.WriteJSON(Message{Type: MessageTypeSubscribe, Channels: []string{"ticker:SOLUSDT"}}))
+	ack = readAck(t, fourth)
+	assert.Equal(t, []string{"ticker:SOLUSDT"}, ack.Channels)
+}
+
+// expiringAuthManager 返回指定过期时间的认证管理器
+type expiringAuthManager struct {
+	data_collection.AuthManager
+	expiresAt time.Time
+}
+
+func (m *expiringAuthManager) ValidateToken(token string) (*data_collection.TokenClaims, error) {
+	return &data_collection.TokenClaims{UserID: "user-expiring", Permissions: []string{"read"}, ExpiresAt: m.expiresAt}, nil
+}
+
+func (m *expiringAuthManager) CheckPermission(token string, permission string) (bool, error) {
+	return true, nil
+}
+
+// TestWebSocketServer_TokenExpiry 测试连接期间 Token 过期
+func TestWebSocketServer_TokenExpiry(t *testing.T) {
+	config := DefaultServerConfig()
+	config.PongWait = 5 * time.Second
+
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	server.SetAuthManager(&expiringAuthManager{expiresAt: time.Now().Add(200 * time.Millisecond)})
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+
+	conn := dialWithToken(t, "ws"+strings.TrimPrefix(httpServer.URL, "http"), "token")
+	assert.Equal(t, CloseCodeTokenExpired, readCloseCode(t, conn))
+	assert.Eventually(t, func() bool { return server.GetConnectionCount() == 0 }, time.Second, 10*time.Millisecond)
+}
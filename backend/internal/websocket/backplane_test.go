@@ -0,0 +1,175 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+)
+
+// TestDecodeBackplaneMessage 测试背板消息解析
+func TestDecodeBackplaneMessage(t *testing.T) {
+	message, err := decodeBackplaneMessage("42\n" + `{"kind":"channel","target":"ticker:BTCUSDT","instance":"ws-a","data":{"symbol":"BTCUSDT"},"timestamp":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), message.Seq)
+	assert.Equal(t, BackplaneKindChannel, message.Kind)
+	assert.Equal(t, "ticker:BTCUSDT", message.Target)
+	assert.JSONEq(t, `{"symbol":"BTCUSDT"}`, string(message.Data))
+
+	_, err = decodeBackplaneMessage(`{"kind":"all"}`)
+	assert.Error(t, err)
+	_, err = decodeBackplaneMessage("x\n{}")
+	assert.Error(t, err)
+}
+
+// TestRedisBackplane_Presence 测试在线状态和连接会话
+func TestRedisBackplane_Presence(t *testing.T) {
+	mr, client := setupRedisClient(t)
+	ctx := context.Background()
+
+	backplaneA := NewRedisBackplane(client, "ws-a", zap.NewNop())
+	backplaneB := NewRedisBackplane(client, "ws-b", zap.NewNop())
+	assert.NotEmpty(t, NewRedisBackplane(client, "", nil).InstanceID())
+
+	require.NoError(t, backplaneA.UpdatePresence(ctx, &PresenceSnapshot{
+		Connections: 3,
+		Channels:    map[string]int{"ticker:BTCUSDT": 2, "signals": 1},
+	}))
+	require.NoError(t, backplaneB.UpdatePresence(ctx, &PresenceSnapshot{
+		Connections: 2,
+		Channels:    map[string]int{"ticker:BTCUSDT": 1},
+	}))
+
+	// 超过会话 TTL 未刷新的实例被清理
+	require.NoError(t, client.GetClient().ZAdd(ctx, cache.BuildWSInstancesKey(), redis.Z{
+		Score:  float64(time.Now().Add(-2 * cache.TTLWebSocketSession).UnixMilli()),
+		Member: "ws-stale",
+	}).Err())
+
+	stats, err := backplaneA.GetClusterStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ws-a", "ws-b"}, stats.Instances)
+	assert.Equal(t, 5, stats.Connections)
+	assert.Equal(t, map[string]int{"ticker:BTCUSDT": 3, "signals": 1}, stats.Channels)
+
+	// 频道订阅数整体覆盖
+	require.NoError(t, backplaneA.UpdatePresence(ctx, &PresenceSnapshot{Connections: 1}))
+	assert.False(t, mr.Exists(cache.BuildWSPresenceKey("ws-a")))
+
+	require.NoError(t, backplaneB.RemovePresence(ctx))
+	stats, err = backplaneA.GetClusterStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ws-a"}, stats.Instances)
+	assert.Equal(t, 1, stats.Connections)
+	assert.Empty(t, stats.Channels)
+
+	// 连接会话
+	require.NoError(t, backplaneA.SaveSessions(ctx, []*SessionInfo{{
+		ConnID:        "conn_1",
+		Instance:      "ws-a",
+		Subscriptions: []string{"ticker:BTCUSDT", "signals"},
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}}))
+	sessionKey := cache.BuildWSSessionKey("conn_1")
+	assert.Equal(t, "ws-a", mr.HGet(sessionKey, "instance"))
+	assert.Equal(t, "ticker:BTCUSDT,signals", mr.HGet(sessionKey, "subscriptions"))
+	assert.Equal(t, cache.TTLWebSocketSession, mr.TTL(sessionKey))
+
+	require.NoError(t, backplaneA.RemoveSession(ctx, "conn_1"))
+	assert.False(t, mr.Exists(sessionKey))
+}
+
+// startBackplaneServer 启动接入背板的服务器，返回客户端连接地址
+func startBackplaneServer(t *testing.T, client *cache.Client, instanceID string) (*WebSocketServerImpl, string) {
+	config := DefaultServerConfig()
+	config.Port = 0
+	config.PongWait = 5 * time.Second
+	config.MaxMessageSize = 4096
+
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	server.SetBackplane(NewRedisBackplane(client, instanceID, zap.NewNop()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, server.Start(ctx))
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop(context.Background())
+		cancel()
+	})
+
+	return server, "ws" + strings.TrimPrefix(httpServer.URL, "http")
+}
+
+// TestWebSocketServer_Backplane 测试跨实例推送与在线状态
+func TestWebSocketServer_Backplane(t *testing.T) {
+	mr, client := setupRedisClient(t)
+
+	serverA, _ := startBackplaneServer(t, client, "ws-a")
+	serverB, wsURL := startBackplaneServer(t, client, "ws-b")
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribe, Channels: []string{"ticker:BTCUSDT"}}))
+	assert.Equal(t, MessageTypeSubscribed, readChannelMessage(t, conn).Type)
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribe, Symbols: []string{"ETHUSDT"}}))
+	assert.Equal(t, "subscribe_success", readChannelMessage(t, conn).Type)
+
+	// 在实例 A 发布，连接在实例 B 的客户端收到带全局序列号的推送
+	for _, price := range []float64{50001, 50002} {
+		require.NoError(t, serverA.Publish("ticker:BTCUSDT", &TickerPayload{Symbol: "BTCUSDT", LastPrice: price}))
+	}
+	for i, price := range []float64{50001, 50002} {
+		message := readChannelMessage(t, conn)
+		assert.Equal(t, MessageTypeUpdate, message.Type)
+		assert.Equal(t, "ticker:BTCUSDT", message.Channel)
+		assert.Equal(t, uint64(i+1), message.Seq)
+
+		var ticker TickerPayload
+		require.NoError(t, json.Unmarshal(message.Data, &ticker))
+		assert.Equal(t, price, ticker.LastPrice)
+	}
+	seq, err := mr.Get(cache.BuildWSSequenceKey("ticker:BTCUSDT"))
+	require.NoError(t, err)
+	assert.Equal(t, "2", seq)
+
+	// 旧版按交易对广播同样跨实例
+	require.NoError(t, serverA.BroadcastToSymbol("ETHUSDT", Message{Type: "price_update", Symbol: "ETHUSDT"}))
+	assert.Equal(t, "price_update", readChannelMessage(t, conn).Type)
+
+	require.NoError(t, serverA.BroadcastToAll(Message{Type: "notice"}))
+	assert.Equal(t, "notice", readChannelMessage(t, conn).Type)
+
+	// 实例 B 的重放缓冲区也记录了跨实例推送
+	state := serverB.getChannelState("ticker:BTCUSDT")
+	state.mu.Lock()
+	missed, ok := state.replay.since(1, state.seq)
+	state.mu.Unlock()
+	require.True(t, ok)
+	require.Len(t, missed, 1)
+	assert.Equal(t, uint64(2), missed[0].Seq)
+
+	// 在线状态汇总两个实例
+	serverB.reportPresence()
+	stats, err := serverA.backplane.GetClusterStats(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ws-a", "ws-b"}, stats.Instances)
+	assert.Equal(t, 1, stats.Connections)
+	assert.Equal(t, 1, stats.Channels["ticker:BTCUSDT"])
+	assert.Equal(t, 1, stats.Channels["ETHUSDT"])
+}
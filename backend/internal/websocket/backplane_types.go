@@ -0,0 +1,379 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+)
+
+// BackplaneKind 跨实例消息类型
+type BackplaneKind string
+
+const (
+	BackplaneKindChannel BackplaneKind = "channel" // 频道推送，携带全局序列号
+	BackplaneKindSymbol  BackplaneKind = "symbol"  // 按交易对广播（旧版订阅）
+	BackplaneKindAll     BackplaneKind = "all"     // 向所有连接广播
+	BackplaneKindMetrics BackplaneKind = "metrics" // 行情指标，各实例据此评估过滤订阅
+)
+
+// BackplaneMessage 经由背板分发到各实例的消息
+type BackplaneMessage struct {
+	Kind      BackplaneKind   `json:"kind"`
+	Target    string          `json:"target,omitempty"` // 频道名或交易对
+	Instance  string          `json:"instance"`         // 发布消息的实例
+	Seq       uint64          `json:"-"`                // 频道消息的全局序列号，由 Redis 分配
+	Data      json.RawMessage `json:"data"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// PresenceSnapshot 本实例的连接数和各频道订阅数
+type PresenceSnapshot struct {
+	Connections int            `json:"connections"`
+	Channels    map[string]int `json:"channels"`
+}
+
+// SessionInfo 保存在 Redis 中的连接会话
+type SessionInfo struct {
+	ConnID        string    `json:"conn_id"`
+	Instance      string    `json:"instance"`
+	UserID        string    `json:"user_id,omitempty"`
+	Subscriptions []string  `json:"subscriptions"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ClusterStats 集群内所有在线实例的汇总
+type ClusterStats struct {
+	Instances   []string       `json:"instances"`
+	Connections int            `json:"connections"`
+	Channels    map[string]int `json:"channels"` // 频道 -> 全部实例的订阅数
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// Backplane 多实例间的消息分发与在线状态
+// 任一实例发布的消息会投递给所有实例（包括自身），由各实例推送给本地订阅者
+type Backplane interface {
+	// Start 订阅背板并在后台把收到的消息交给 handler，订阅确认后返回
+	Start(ctx context.Context, handler func(*BackplaneMessage)) error
+	Stop(ctx context.Context) error
+
+	// PublishChannel 发布频道消息并返回分配的全局序列号
+	PublishChannel(ctx context.Context, channel string, data json.RawMessage) (uint64, error)
+	PublishSymbol(ctx context.Context, symbol string, data json.RawMessage) error
+	PublishAll(ctx context.Context, data json.RawMessage) error
+	PublishMetrics(ctx context.Context, symbol string, data json.RawMessage) error
+
+	// 在线状态
+	UpdatePresence(ctx context.Context, snapshot *PresenceSnapshot) error
+	RemovePresence(ctx context.Context) error
+	SaveSessions(ctx context.Context, sessions []*SessionInfo) error
+	RemoveSession(ctx context.Context, connID string) error
+	GetClusterStats(ctx context.Context) (*ClusterStats, error)
+
+	InstanceID() string
+}
+
+// publishChannelScript 原子地递增频道序列号并发布，保证序列号顺序与投递顺序一致
+var publishChannelScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+redis.call('PUBLISH', ARGV[2], seq .. '\n' .. ARGV[1])
+return seq
+`)
+
+// redisBackplane 基于 Redis Pub/Sub 的背板实现
+// 消息格式为 "<序列号>\n<JSON>"，非频道消息的序列号为 0
+type redisBackplane struct {
+	client     *redis.Client
+	instanceID string
+	logger     *zap.Logger
+
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+}
+
+// NewRedisBackplane 创建 Redis 背板，instanceID 为空时自动生成
+func NewRedisBackplane(client *cache.Client, instanceID string, logger *zap.Logger) Backplane {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if instanceID == "" {
+		instanceID = generateInstanceID()
+	}
+
+	return &redisBackplane{
+		client:     client.GetClient(),
+		instanceID: instanceID,
+		logger:     logger,
+	}
+}
+
+// InstanceID 返回当前实例ID
+func (b *redisBackplane) InstanceID() string {
+	return b.instanceID
+}
+
+// Start 订阅背板频道
+func (b *redisBackplane) Start(ctx context.Context, handler func(*BackplaneMessage)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pubsub != nil {
+		return fmt.Errorf("背板已启动")
+	}
+
+	pubsub := b.client.Subscribe(ctx, cache.BuildWSBackplaneChannel())
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("订阅背板失败: %w", err)
+	}
+	b.pubsub = pubsub
+
+	go func() {
+		for payload := range pubsub.Channel() {
+			message, err := decodeBackplaneMessage(payload.Payload)
+			if err != nil {
+				b.logger.Warn("背板消息解析失败", zap.Error(err))
+				continue
+			}
+			handler(message)
+		}
+	}()
+
+	b.logger.Info("WebSocket背板已启动", zap.String("instance", b.instanceID))
+	return nil
+}
+
+// Stop 取消订阅，后台协程在通道关闭后退出
+func (b *redisBackplane) Stop(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pubsub == nil {
+		return nil
+	}
+	err := b.pubsub.Close()
+	b.pubsub = nil
+	return err
+}
+
+// PublishChannel 发布频道消息
+func (b *redisBackplane) PublishChannel(ctx context.Context, channel string, data json.RawMessage) (uint64, error) {
+	payload, err := b.encode(BackplaneKindChannel, channel, data)
+	if err != nil {
+		return 0, err
+	}
+
+	seq, err := publishChannelScript.Run(ctx, b.client,
+		[]string{cache.BuildWSSequenceKey(channel)},
+		payload, cache.BuildWSBackplaneChannel(),
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("发布频道消息失败: %w", err)
+	}
+	return uint64(seq), nil
+}
+
+// PublishSymbol 发布交易对广播
+func (b *redisBackplane) PublishSymbol(ctx context.Context, symbol string, data json.RawMessage) error {
+	return b.publish(ctx, BackplaneKindSymbol, symbol, data)
+}
+
+// PublishAll 发布全局广播
+func (b *redisBackplane) PublishAll(ctx context.Context, data json.RawMessage) error {
+	return b.publish(ctx, BackplaneKindAll, "", data)
+}
+
+// PublishMetrics 发布交易对行情指标
+func (b *redisBackplane) PublishMetrics(ctx context.Context, symbol string, data json.RawMessage) error {
+	return b.publish(ctx, BackplaneKindMetrics, symbol, data)
+}
+
+func (b *redisBackplane) publish(ctx context.Context, kind BackplaneKind, target string, data json.RawMessage) error {
+	payload, err := b.encode(kind, target, data)
+	if err != nil {
+		return err
+	}
+	if err := b.client.Publish(ctx, cache.BuildWSBackplaneChannel(), "0\n"+payload).Err(); err != nil {
+		return fmt.Errorf("发布背板消息失败: %w", err)
+	}
+	return nil
+}
+
+func (b *redisBackplane) encode(kind BackplaneKind, target string, data json.RawMessage) (string, error) {
+	payload, err := json.Marshal(&BackplaneMessage{
+		Kind:      kind,
+		Target:    target,
+		Instance:  b.instanceID,
+		Data:      data,
+		Timestamp: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化背板消息失败: %w", err)
+	}
+	return string(payload), nil
+}
+
+// UpdatePresence 写入本实例的连接数和频道订阅数，并刷新在线实例列表
+func (b *redisBackplane) UpdatePresence(ctx context.Context, snapshot *PresenceSnapshot) error {
+	heartbeatKey := cache.BuildWSHeartbeatKey(b.instanceID)
+	presenceKey := cache.BuildWSPresenceKey(b.instanceID)
+	now := time.Now()
+
+	pipe := b.client.TxPipeline()
+	pipe.HSet(ctx, heartbeatKey,
+		"connections", snapshot.Connections,
+		"updated_at", now.UnixMilli(),
+	)
+	pipe.Expire(ctx, heartbeatKey, cache.TTLWebSocketSession)
+
+	pipe.Del(ctx, presenceKey)
+	if len(snapshot.Channels) > 0 {
+		values := make(map[string]interface{}, len(snapshot.Channels))
+		for channel, count := range snapshot.Channels {
+			values[channel] = count
+		}
+		pipe.HSet(ctx, presenceKey, values)
+		pipe.Expire(ctx, presenceKey, cache.TTLWebSocketSession)
+	}
+
+	pipe.ZAdd(ctx, cache.BuildWSInstancesKey(), redis.Z{
+		Score:  float64(now.UnixMilli()),
+		Member: b.instanceID,
+	})
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("更新在线状态失败: %w", err)
+	}
+	return nil
+}
+
+// RemovePresence 删除本实例的在线状态，实例正常退出时调用
+func (b *redisBackplane) RemovePresence(ctx context.Context) error {
+	pipe := b.client.TxPipeline()
+	pipe.Del(ctx, cache.BuildWSHeartbeatKey(b.instanceID), cache.BuildWSPresenceKey(b.instanceID))
+	pipe.ZRem(ctx, cache.BuildWSInstancesKey(), b.instanceID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("删除在线状态失败: %w", err)
+	}
+	return nil
+}
+
+// SaveSessions 批量保存连接会话
+func (b *redisBackplane) SaveSessions(ctx context.Context, sessions []*SessionInfo) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	pipe := b.client.Pipeline()
+	for _, session := range sessions {
+		key := cache.BuildWSSessionKey(session.ConnID)
+		pipe.HSet(ctx, key,
+			"instance", session.Instance,
+			"subscriptions", strings.Join(session.Subscriptions, ","),
+			"created_at", session.CreatedAt.UnixMilli(),
+			"updated_at", session.UpdatedAt.UnixMilli(),
+		)
+		pipe.Expire(ctx, key, cache.TTLWebSocketSession)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("保存连接会话失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveSession 删除连接会话
+func (b *redisBackplane) RemoveSession(ctx context.Context, connID string) error {
+	if err := b.client.Del(ctx, cache.BuildWSSessionKey(connID)).Err(); err != nil {
+		return fmt.Errorf("删除连接会话失败: %w", err)
+	}
+	return nil
+}
+
+// GetClusterStats 汇总所有在线实例的状态，超过会话 TTL 未刷新的实例视为下线并移除
+func (b *redisBackplane) GetClusterStats(ctx context.Context) (*ClusterStats, error) {
+	instancesKey := cache.BuildWSInstancesKey()
+	now := time.Now()
+	staleBefore := now.Add(-cache.TTLWebSocketSession).UnixMilli()
+
+	if err := b.client.ZRemRangeByScore(ctx, instancesKey, "-inf", "("+strconv.FormatInt(staleBefore, 10)).Err(); err != nil {
+		return nil, fmt.Errorf("清理下线实例失败: %w", err)
+	}
+
+	instances, err := b.client.ZRange(ctx, instancesKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取在线实例失败: %w", err)
+	}
+
+	stats := &ClusterStats{
+		Instances: instances,
+		Channels:  make(map[string]int),
+		UpdatedAt: now,
+	}
+	if len(instances) == 0 {
+		return stats, nil
+	}
+
+	pipe := b.client.Pipeline()
+	connectionCmds := make([]*redis.StringCmd, len(instances))
+	presenceCmds := make([]*redis.MapStringStringCmd, len(instances))
+	for i, instance := range instances {
+		connectionCmds[i] = pipe.HGet(ctx, cache.BuildWSHeartbeatKey(instance), "connections")
+		presenceCmds[i] = pipe.HGetAll(ctx, cache.BuildWSPresenceKey(instance))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("获取实例状态失败: %w", err)
+	}
+
+	for i := range instances {
+		if connections, err := connectionCmds[i].Int(); err == nil {
+			stats.Connections += connections
+		}
+		for channel, value := range presenceCmds[i].Val() {
+			if count, err := strconv.Atoi(value); err == nil {
+				stats.Channels[channel] += count
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// decodeBackplaneMessage 解析 "<序列号>\n<JSON>" 格式的背板消息
+func decodeBackplaneMessage(payload string) (*BackplaneMessage, error) {
+	seqPart, body, ok := strings.Cut(payload, "\n")
+	if !ok {
+		return nil, fmt.Errorf("背板消息格式错误")
+	}
+
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("背板消息序列号无效: %w", err)
+	}
+
+	var message BackplaneMessage
+	if err := json.Unmarshal([]byte(body), &message); err != nil {
+		return nil, fmt.Errorf("背板消息反序列化失败: %w", err)
+	}
+	message.Seq = seq
+	return &message, nil
+}
+
+// generateInstanceID 生成实例ID
+func generateInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "ws"
+	}
+	return fmt.Sprintf("%s-%d", hostname, time.Now().UnixNano())
+}
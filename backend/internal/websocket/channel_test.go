@@ -0,0 +1,153 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestParseChannel 测试频道解析
+func TestParseChannel(t *testing.T) {
+	valid := map[string]string{
+		"ticker:BTCUSDT":   "ticker:BTCUSDT",
+		"ticker:btcusdt":   "ticker:BTCUSDT",
+		"kline:1m:ETHUSDT": "kline:1m:ETHUSDT",
+		"depth:SOLUSDT":    "depth:SOLUSDT",
+		"signals":          "signals",
+		"alerts":           "alerts",
+	}
+	for name, expected := range valid {
+		channel, err := ParseChannel(name)
+		require.Nil(t, err, name)
+		assert.Equal(t, expected, channel.String())
+	}
+
+	invalid := map[string]string{
+		"ticker":           ErrCodeInvalidChannel,
+		"ticker:BTC-USDT":  ErrCodeInvalidSymbol,
+		"kline:BTCUSDT":    ErrCodeInvalidChannel,
+		"kline:2m:BTCUSDT": ErrCodeInvalidInterval,
+		"signals:BTCUSDT":  ErrCodeInvalidChannel,
+		"trades:BTCUSDT":   ErrCodeUnknownChannel,
+		"":                 ErrCodeUnknownChannel,
+	}
+	for name, code := range invalid {
+		_, err := ParseChannel(name)
+		require.NotNil(t, err, name)
+		assert.Equal(t, code, err.Code, name)
+		assert.Equal(t, name, err.Channel)
+	}
+}
+
+// TestValidatePayload 测试频道数据校验
+func TestValidatePayload(t *testing.T) {
+	ticker, _ := ParseChannel("ticker:BTCUSDT")
+	kline, _ := ParseChannel("kline:5m:BTCUSDT")
+	signals, _ := ParseChannel("signals")
+
+	assert.NoError(t, validatePayload(ticker, &TickerPayload{Symbol: "BTCUSDT"}))
+	assert.NoError(t, validatePayload(kline, KlinePayload{Symbol: "BTCUSDT", Interval: "5m"}))
+	assert.NoError(t, validatePayload(signals, &SignalPayload{Symbol: "ETHUSDT"}))
+
+	assert.Error(t, validatePayload(ticker, &TickerPayload{Symbol: "ETHUSDT"}))
+	assert.Error(t, validatePayload(ticker, &DepthPayload{Symbol: "BTCUSDT"}))
+	assert.Error(t, validatePayload(kline, KlinePayload{Symbol: "BTCUSDT", Interval: "1m"}))
+	assert.Error(t, validatePayload(signals, map[string]interface{}{"symbol": "BTCUSDT"}))
+}
+
+// setupChannelTestServer 使用 httptest 启动服务器并建立客户端连接
+func setupChannelTestServer(t *testing.T, config *ServerConfig) (*WebSocketServerImpl, *websocket.Conn) {
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	t.Cleanup(httpServer.Close)
+
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	require.Eventually(t, func() bool {
+		return server.GetConnectionCount() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	return server, conn
+}
+
+// readAck 读取订阅确认
+func readAck(t *testing.T, conn *websocket.Conn) *SubscriptionAck {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var ack SubscriptionAck
+	require.NoError(t, conn.ReadJSON(&ack))
+	return &ack
+}
+
+// TestWebSocketServer_ChannelSubscription 测试频道订阅、确认和推送路由
+func TestWebSocketServer_ChannelSubscription(t *testing.T) {
+	config := DefaultServerConfig()
+	config.PongWait = 5 * time.Second
+	config.MaxMessageSize = 4096
+	config.MaxSubscriptionsPerConnection = 3
+	server, conn := setupChannelTestServer(t, config)
+
+	require.NoError(t, conn.WriteJSON(Message{
+		Type:     MessageTypeSubscribe,
+		ID:       "req-1",
+		Channels: []string{"ticker:btcusdt", "kline:1m:BTCUSDT", "kline:2m:BTCUSDT", "trades:BTCUSDT", "signals", "alerts"},
+	}))
+
+	ack := readAck(t, conn)
+	assert.Equal(t, MessageTypeSubscribed, ack.Type)
+	assert.Equal(t, "req-1", ack.ID)
+	assert.Equal(t, []string{"ticker:BTCUSDT", "kline:1m:BTCUSDT", "signals"}, ack.Channels)
+	require.Len(t, ack.Errors, 3)
+	assert.Equal(t, ErrCodeInvalidInterval, ack.Errors[0].Code)
+	assert.Equal(t, ErrCodeUnknownChannel, ack.Errors[1].Code)
+	assert.Equal(t, ErrCodeSubscriptionLimit, ack.Errors[2].Code)
+	assert.Equal(t, "alerts", ack.Errors[2].Channel)
+
+	connID := server.GetConnections()[0].ID
+	assert.Equal(t, []string{connID}, server.GetSubscribers("ticker:BTCUSDT"))
+
+	t.Run("按频道推送", func(t *testing.T) {
+		require.NoError(t, server.Publish("ticker:ETHUSDT", &TickerPayload{Symbol: "ETHUSDT", LastPrice: 3000}))
+		require.NoError(t, server.Publish("ticker:BTCUSDT", &TickerPayload{Symbol: "BTCUSDT", LastPrice: 50000}))
+		assert.Error(t, server.Publish("ticker:BTCUSDT", &KlinePayload{Symbol: "BTCUSDT"}))
+		assert.Error(t, server.Publish("orders", &AlertPayload{}))
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		var update struct {
+			Type    string        `json:"type"`
+			Channel string        `json:"channel"`
+			Data    TickerPayload `json:"data"`
+		}
+		require.NoError(t, conn.ReadJSON(&update))
+		assert.Equal(t, MessageTypeUpdate, update.Type)
+		assert.Equal(t, "ticker:BTCUSDT", update.Channel)
+		assert.Equal(t, 50000.0, update.Data.LastPrice)
+	})
+
+	t.Run("取消订阅", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(Message{
+			Type:     MessageTypeUnsubscribe,
+			ID:       "req-2",
+			Channels: []string{"ticker:BTCUSDT", "depth:BTCUSDT"},
+		}))
+
+		ack := readAck(t, conn)
+		assert.Equal(t, MessageTypeUnsubscribed, ack.Type)
+		assert.Equal(t, "req-2", ack.ID)
+		assert.Equal(t, []string{"ticker:BTCUSDT"}, ack.Channels)
+		require.Len(t, ack.Errors, 1)
+		assert.Equal(t, ErrCodeNotSubscribed, ack.Errors[0].Code)
+
+		assert.Empty(t, server.GetSubscribers("ticker:BTCUSDT"))
+		assert.Equal(t, []string{"kline:1m:BTCUSDT", "signals"}, server.GetSubscriptions(connID))
+	})
+}
@@ -0,0 +1,271 @@
+package websocket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChannelType 频道类型
+type ChannelType string
+
+const (
+	ChannelTicker  ChannelType = "ticker"  // 行情：ticker:BTCUSDT
+	ChannelKline   ChannelType = "kline"   // K线：kline:1m:BTCUSDT
+	ChannelSignals ChannelType = "signals" // 市场信号：signals
+	ChannelAlerts  ChannelType = "alerts"  // 告警：alerts
+	ChannelDepth   ChannelType = "depth"   // 盘口深度：depth:BTCUSDT
+)
+
+// 消息类型
+const (
+	MessageTypeSubscribe    = "subscribe"
+	MessageTypeUnsubscribe  = "unsubscribe"
+	MessageTypePing         = "ping"
+	MessageTypePong         = "pong"
+	MessageTypeSubscribed   = "subscribed"   // 频道订阅确认
+	MessageTypeUnsubscribed = "unsubscribed" // 频道取消订阅确认
+	MessageTypeUpdate       = "update"       // 频道数据推送
+	MessageTypeSnapshot     = "snapshot"     // 频道快照
+	MessageTypeResume       = "resume"       // 断线重连后恢复订阅
+	MessageTypeResumed      = "resumed"      // 恢复确认
+)
+
+// 订阅错误码
+const (
+	ErrCodeInvalidMessage     = "INVALID_MESSAGE"      // 消息无法解析
+	ErrCodeInvalidMessageType = "INVALID_MESSAGE_TYPE" // 无效的消息类型
+	ErrCodeInvalidChannel     = "INVALID_CHANNEL"      // 频道格式错误
+	ErrCodeUnknownChannel     = "UNKNOWN_CHANNEL"      // 不支持的频道类型
+	ErrCodeInvalidSymbol      = "INVALID_SYMBOL"       // 交易对格式错误
+	ErrCodeInvalidInterval    = "INVALID_INTERVAL"     // K线周期不支持
+	ErrCodeSubscriptionLimit  = "SUBSCRIPTION_LIMIT"   // 超过单连接订阅上限
+	ErrCodeSymbolLimit        = "SYMBOL_LIMIT"         // 超过单用户交易对订阅上限
+	ErrCodeNotSubscribed      = "NOT_SUBSCRIBED"       // 取消未订阅的频道
+	ErrCodeInvalidRate        = "INVALID_RATE"         // 推送频率无效
+)
+
+// KlineIntervals 支持的K线周期
+var KlineIntervals = []string{"1s", "1m", "5m", "15m", "30m", "1h", "4h", "1d", "1w"}
+
+// Channel 解析后的频道
+type Channel struct {
+	Type     ChannelType `json:"type"`
+	Interval string      `json:"interval,omitempty"`
+	Symbol   string      `json:"symbol,omitempty"`
+}
+
+// String 返回规范化的频道名
+func (c *Channel) String() string {
+	switch c.Type {
+	case ChannelKline:
+		return fmt.Sprintf("%s:%s:%s", c.Type, c.Interval, c.Symbol)
+	case ChannelTicker, ChannelDepth:
+		return fmt.Sprintf("%s:%s", c.Type, c.Symbol)
+	default:
+		return string(c.Type)
+	}
+}
+
+// ParseChannel 解析频道名，交易对统一转为大写
+func ParseChannel(name string) (*Channel, *ChannelError) {
+	parts := strings.Split(strings.TrimSpace(name), ":")
+	channelType := ChannelType(strings.ToLower(parts[0]))
+
+	newError := func(code, message string) *ChannelError {
+		return &ChannelError{Channel: name, Code: code, Message: message}
+	}
+
+	switch channelType {
+	case ChannelTicker, ChannelDepth:
+		if len(parts) != 2 {
+			return nil, newError(ErrCodeInvalidChannel, fmt.Sprintf("频道格式应为 %s:<交易对>", channelType))
+		}
+		symbol, ok := normalizeSymbol(parts[1])
+		if !ok {
+			return nil, newError(ErrCodeInvalidSymbol, "交易对格式无效")
+		}
+		return &Channel{Type: channelType, Symbol: symbol}, nil
+
+	case ChannelKline:
+		if len(parts) != 3 {
+			return nil, newError(ErrCodeInvalidChannel, "频道格式应为 kline:<周期>:<交易对>")
+		}
+		if !contains(KlineIntervals, parts[1]) {
+			return nil, newError(ErrCodeInvalidInterval, "K线周期必须是 "+strings.Join(KlineIntervals, "、")+" 之一")
+		}
+		symbol, ok := normalizeSymbol(parts[2])
+		if !ok {
+			return nil, newError(ErrCodeInvalidSymbol, "交易对格式无效")
+		}
+		return &Channel{Type: channelType, Interval: parts[1], Symbol: symbol}, nil
+
+	case ChannelSignals, ChannelAlerts:
+		if len(parts) != 1 {
+			return nil, newError(ErrCodeInvalidChannel, fmt.Sprintf("频道 %s 不接受参数", channelType))
+		}
+		return &Channel{Type: channelType}, nil
+
+	default:
+		return nil, newError(ErrCodeUnknownChannel, "不支持的频道类型")
+	}
+}
+
+// TickerChannel 构建行情频道名
+func TickerChannel(symbol string) string {
+	return (&Channel{Type: ChannelTicker, Symbol: symbol}).String()
+}
+
+// KlineChannel 构建K线频道名
+func KlineChannel(interval, symbol string) string {
+	return (&Channel{Type: ChannelKline, Interval: interval, Symbol: symbol}).String()
+}
+
+// DepthChannel 构建盘口深度频道名
+func DepthChannel(symbol string) string {
+	return (&Channel{Type: ChannelDepth, Symbol: symbol}).String()
+}
+
+// ChannelError 频道订阅错误
+type ChannelError struct {
+	Channel string `json:"channel"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ChannelError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Channel, e.Message)
+}
+
+// SubscriptionAck 频道订阅/取消订阅确认
+type SubscriptionAck struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id,omitempty"`     // 客户端请求ID
+	Channels  []string        `json:"channels"`         // 成功处理的频道
+	Errors    []*ChannelError `json:"errors,omitempty"` // 失败的频道及原因
+	Timestamp int64           `json:"timestamp"`
+}
+
+// ChannelMessage 频道数据推送，Seq 为频道内单调递增的序列号
+// 快照消息的 Seq 为快照对应的最新序列号，Data 为空表示无法重放且没有快照，客户端应丢弃本地状态
+type ChannelMessage struct {
+	Type      string      `json:"type"`
+	Channel   string      `json:"channel"`
+	Seq       uint64      `json:"seq"`
+	Data      interface{} `json:"data"`
+	Timestamp int64       `json:"timestamp"`
+}
+
+// TickerPayload ticker 频道数据
+type TickerPayload struct {
+	Symbol        string  `json:"symbol"`
+	LastPrice     float64 `json:"last_price"`
+	BidPrice      float64 `json:"bid_price,omitempty"`
+	AskPrice      float64 `json:"ask_price,omitempty"`
+	High24h       float64 `json:"high_24h,omitempty"`
+	Low24h        float64 `json:"low_24h,omitempty"`
+	Volume24h     float64 `json:"volume_24h,omitempty"`
+	ChangeRate24h float64 `json:"change_rate_24h"`
+	Timestamp     int64   `json:"timestamp"`
+}
+
+// KlinePayload kline 频道数据
+type KlinePayload struct {
+	Symbol      string  `json:"symbol"`
+	Interval    string  `json:"interval"`
+	OpenTime    int64   `json:"open_time"`
+	Open        float64 `json:"open"`
+	High        float64 `json:"high"`
+	Low         float64 `json:"low"`
+	Close       float64 `json:"close"`
+	Volume      float64 `json:"volume"`
+	QuoteVolume float64 `json:"quote_volume,omitempty"`
+	Closed      bool    `json:"closed"` // K线是否已收盘
+}
+
+// SignalPayload signals 频道数据
+type SignalPayload struct {
+	Symbol     string                 `json:"symbol"`
+	Type       string                 `json:"type"`
+	Direction  string                 `json:"direction"`
+	Strength   float64                `json:"strength"`
+	Severity   string                 `json:"severity"`
+	Composite  bool                   `json:"composite"`
+	Reasons    []string               `json:"reasons,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	DetectedAt int64                  `json:"detected_at"`
+}
+
+// AlertPayload alerts 频道数据
+type AlertPayload struct {
+	ID        string                 `json:"id"`
+	Level     string                 `json:"level"`
+	Title     string                 `json:"title"`
+	Message   string                 `json:"message"`
+	Symbol    string                 `json:"symbol,omitempty"`
+	Source    string                 `json:"source,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Timestamp int64                  `json:"timestamp"`
+}
+
+// DepthLevel 盘口档位 [价格, 数量]
+type DepthLevel [2]float64
+
+// DepthPayload depth 频道数据
+type DepthPayload struct {
+	Symbol    string       `json:"symbol"`
+	Bids      []DepthLevel `json:"bids"`
+	Asks      []DepthLevel `json:"asks"`
+	Timestamp int64        `json:"timestamp"`
+}
+
+// validatePayload 校验推送数据与频道类型及交易对是否匹配
+func validatePayload(channel *Channel, data interface{}) error {
+	var payloadType ChannelType
+	var symbol, interval string
+
+	switch payload := data.(type) {
+	case TickerPayload:
+		payloadType, symbol = ChannelTicker, payload.Symbol
+	case *TickerPayload:
+		payloadType, symbol = ChannelTicker, payload.Symbol
+	case KlinePayload:
+		payloadType, symbol, interval = ChannelKline, payload.Symbol, payload.Interval
+	case *KlinePayload:
+		payloadType, symbol, interval = ChannelKline, payload.Symbol, payload.Interval
+	case SignalPayload, *SignalPayload:
+		payloadType = ChannelSignals
+	case AlertPayload, *AlertPayload:
+		payloadType = ChannelAlerts
+	case DepthPayload:
+		payloadType, symbol = ChannelDepth, payload.Symbol
+	case *DepthPayload:
+		payloadType, symbol = ChannelDepth, payload.Symbol
+	default:
+		return fmt.Errorf("频道 %s 不支持的数据类型 %T", channel, data)
+	}
+
+	if payloadType != channel.Type {
+		return fmt.Errorf("频道 %s 不能推送 %s 数据", channel, payloadType)
+	}
+	if channel.Symbol != "" && symbol != channel.Symbol {
+		return fmt.Errorf("频道 %s 的数据交易对不匹配: %s", channel, symbol)
+	}
+	if channel.Interval != "" && interval != channel.Interval {
+		return fmt.Errorf("频道 %s 的K线周期不匹配: %s", channel, interval)
+	}
+	return nil
+}
+
+// normalizeSymbol 规范化交易对名称，只允许字母和数字
+func normalizeSymbol(symbol string) (string, bool) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if len(symbol) < 2 || len(symbol) > 30 {
+		return "", false
+	}
+	for _, r := range symbol {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return "", false
+		}
+	}
+	return symbol, true
+}
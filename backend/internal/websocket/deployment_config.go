@@ -126,11 +126,9 @@ func NewWebSocketService(config *DeploymentConfig) (*WebSocketService, error) {
 		return nil, fmt.Errorf("创建日志器失败: %v", err)
 	}
 
-	// 创建连接管理器
-	connManager := NewMockConnectionManager()
-
-	// 创建核心组件
+	// 创建核心组件，服务器同时作为连接管理器
 	server := NewWebSocketServer(&config.Server, logger)
+	connManager := server
 	subscriptionManager := NewSubscriptionManager(logger)
 	broadcastManager := NewBroadcastManager(&config.Broadcast, connManager, logger)
 	heartbeatManager := NewHeartbeatManager(&config.Heartbeat, connManager, logger)
@@ -0,0 +1,518 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Envelope 中 oneof payload 的字段编号，与 stream.proto 保持一致
+const (
+	envelopeFieldType      protowire.Number = 1
+	envelopeFieldChannel   protowire.Number = 2
+	envelopeFieldSeq       protowire.Number = 3
+	envelopeFieldTimestamp protowire.Number = 4
+	envelopeFieldTicker    protowire.Number = 10
+	envelopeFieldKline     protowire.Number = 11
+	envelopeFieldSignal    protowire.Number = 12
+	envelopeFieldAlert     protowire.Number = 13
+	envelopeFieldDepth     protowire.Number = 14
+	envelopeFieldJSON      protowire.Number = 15
+)
+
+// ProtobufEnvelope 解码后的 Envelope，Payload 为对应的 *XxxPayload，JSON 为无 schema 消息的原始 JSON
+type ProtobufEnvelope struct {
+	Type      string
+	Channel   string
+	Seq       uint64
+	Timestamp int64
+	Payload   interface{}
+	JSON      json.RawMessage
+}
+
+// protobufEncoder Protobuf 编码器，按 stream.proto 手工编码，避免引入代码生成
+type protobufEncoder struct{}
+
+func (protobufEncoder) Encoding() Encoding {
+	return EncodingProtobuf
+}
+
+func (protobufEncoder) Encode(message interface{}) (int, []byte, error) {
+	data, err := encodeProtobufEnvelope(message)
+	if err != nil {
+		return 0, nil, err
+	}
+	return websocket.BinaryMessage, data, nil
+}
+
+func (protobufEncoder) Decode(data []byte, msg *Message) error {
+	return fmt.Errorf("protobuf 编码的连接请使用 JSON 文本帧发送指令")
+}
+
+// encodeProtobufEnvelope 编码推送消息，频道消息使用结构化 payload，其他消息放入 json 字段
+func encodeProtobufEnvelope(message interface{}) ([]byte, error) {
+	var w protoWriter
+
+	channelMessage, ok := message.(*ChannelMessage)
+	if !ok {
+		if value, isValue := message.(ChannelMessage); isValue {
+			channelMessage, ok = &value, true
+		}
+	}
+	if !ok {
+		raw, isRaw := message.(json.RawMessage)
+		if !isRaw {
+			var err error
+			if raw, err = json.Marshal(message); err != nil {
+				return nil, fmt.Errorf("JSON 编码失败: %w", err)
+			}
+		}
+		w.string(envelopeFieldType, protobufMessageType(message))
+		w.bytes(envelopeFieldJSON, raw)
+		return w.buf, nil
+	}
+
+	data := channelMessage.Data
+	if raw, isRaw := data.(json.RawMessage); isRaw {
+		var err error
+		if data, err = decodeRawPayload(channelMessage.Channel, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	w.string(envelopeFieldType, channelMessage.Type)
+	w.string(envelopeFieldChannel, channelMessage.Channel)
+	w.uint64(envelopeFieldSeq, channelMessage.Seq)
+	w.int64(envelopeFieldTimestamp, channelMessage.Timestamp)
+
+	switch payload := data.(type) {
+	case nil:
+	case TickerPayload:
+		w.message(envelopeFieldTicker, encodeTickerPayload(&payload))
+	case *TickerPayload:
+		w.message(envelopeFieldTicker, encodeTickerPayload(payload))
+	case KlinePayload:
+		w.message(envelopeFieldKline, encodeKlinePayload(&payload))
+	case *KlinePayload:
+		w.message(envelopeFieldKline, encodeKlinePayload(payload))
+	case SignalPayload:
+		w.message(envelopeFieldSignal, encodeSignalPayload(&payload))
+	case *SignalPayload:
+		w.message(envelopeFieldSignal, encodeSignalPayload(payload))
+	case AlertPayload:
+		w.message(envelopeFieldAlert, encodeAlertPayload(&payload))
+	case *AlertPayload:
+		w.message(envelopeFieldAlert, encodeAlertPayload(payload))
+	case DepthPayload:
+		w.message(envelopeFieldDepth, encodeDepthPayload(&payload))
+	case *DepthPayload:
+		w.message(envelopeFieldDepth, encodeDepthPayload(payload))
+	default:
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("JSON 编码失败: %w", err)
+		}
+		w.bytes(envelopeFieldJSON, raw)
+	}
+
+	return w.buf, nil
+}
+
+// protobufMessageType 提取非频道消息的类型
+func protobufMessageType(message interface{}) string {
+	switch msg := message.(type) {
+	case Message:
+		return msg.Type
+	case *Message:
+		return msg.Type
+	case *SubscriptionAck:
+		return msg.Type
+	case ErrorMessage, *ErrorMessage:
+		return "error"
+	default:
+		return ""
+	}
+}
+
+func encodeTickerPayload(p *TickerPayload) []byte {
+	var w protoWriter
+	w.string(1, p.Symbol)
+	w.double(2, p.LastPrice)
+	w.double(3, p.BidPrice)
+	w.double(4, p.AskPrice)
+	w.double(5, p.High24h)
+	w.double(6, p.Low24h)
+	w.double(7, p.Volume24h)
+	w.double(8, p.ChangeRate24h)
+	w.int64(9, p.Timestamp)
+	return w.buf
+}
+
+func encodeKlinePayload(p *KlinePayload) []byte {
+	var w protoWriter
+	w.string(1, p.Symbol)
+	w.string(2, p.Interval)
+	w.int64(3, p.OpenTime)
+	w.double(4, p.Open)
+	w.double(5, p.High)
+	w.double(6, p.Low)
+	w.double(7, p.Close)
+	w.double(8, p.Volume)
+	w.double(9, p.QuoteVolume)
+	w.bool(10, p.Closed)
+	return w.buf
+}
+
+func encodeSignalPayload(p *SignalPayload) []byte {
+	var w protoWriter
+	w.string(1, p.Symbol)
+	w.string(2, p.Type)
+	w.string(3, p.Direction)
+	w.double(4, p.Strength)
+	w.string(5, p.Severity)
+	w.bool(6, p.Composite)
+	for _, reason := range p.Reasons {
+		w.buf = protowire.AppendTag(w.buf, 7, protowire.BytesType)
+		w.buf = protowire.AppendString(w.buf, reason)
+	}
+	w.int64(8, p.DetectedAt)
+	w.metadata(9, p.Metadata)
+	return w.buf
+}
+
+func encodeAlertPayload(p *AlertPayload) []byte {
+	var w protoWriter
+	w.string(1, p.ID)
+	w.string(2, p.Level)
+	w.string(3, p.Title)
+	w.string(4, p.Message)
+	w.string(5, p.Symbol)
+	w.string(6, p.Source)
+	w.int64(7, p.Timestamp)
+	w.metadata(8, p.Metadata)
+	return w.buf
+}
+
+func encodeDepthPayload(p *DepthPayload) []byte {
+	var w protoWriter
+	w.string(1, p.Symbol)
+	w.levels(2, p.Bids)
+	w.levels(3, p.Asks)
+	w.int64(4, p.Timestamp)
+	return w.buf
+}
+
+// DecodeProtobufEnvelope 解码服务端推送的 Protobuf 二进制帧
+func DecodeProtobufEnvelope(data []byte) (*ProtobufEnvelope, error) {
+	envelope := &ProtobufEnvelope{}
+	err := consumeProtoFields(data, func(num protowire.Number, v protoValue) error {
+		var err error
+		switch num {
+		case envelopeFieldType:
+			envelope.Type = v.string()
+		case envelopeFieldChannel:
+			envelope.Channel = v.string()
+		case envelopeFieldSeq:
+			envelope.Seq = v.num
+		case envelopeFieldTimestamp:
+			envelope.Timestamp = int64(v.num)
+		case envelopeFieldTicker:
+			envelope.Payload, err = decodeTickerPayload(v.bytes)
+		case envelopeFieldKline:
+			envelope.Payload, err = decodeKlinePayload(v.bytes)
+		case envelopeFieldSignal:
+			envelope.Payload, err = decodeSignalPayload(v.bytes)
+		case envelopeFieldAlert:
+			envelope.Payload, err = decodeAlertPayload(v.bytes)
+		case envelopeFieldDepth:
+			envelope.Payload, err = decodeDepthPayload(v.bytes)
+		case envelopeFieldJSON:
+			envelope.JSON = append(json.RawMessage{}, v.bytes...)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Protobuf 解码失败: %w", err)
+	}
+	return envelope, nil
+}
+
+func decodeTickerPayload(data []byte) (*TickerPayload, error) {
+	p := &TickerPayload{}
+	return p, consumeProtoFields(data, func(num protowire.Number, v protoValue) error {
+		switch num {
+		case 1:
+			p.Symbol = v.string()
+		case 2:
+			p.LastPrice = v.double()
+		case 3:
+			p.BidPrice = v.double()
+		case 4:
+			p.AskPrice = v.double()
+		case 5:
+			p.High24h = v.double()
+		case 6:
+			p.Low24h = v.double()
+		case 7:
+			p.Volume24h = v.double()
+		case 8:
+			p.ChangeRate24h = v.double()
+		case 9:
+			p.Timestamp = int64(v.num)
+		}
+		return nil
+	})
+}
+
+func decodeKlinePayload(data []byte) (*KlinePayload, error) {
+	p := &KlinePayload{}
+	return p, consumeProtoFields(data, func(num protowire.Number, v protoValue) error {
+		switch num {
+		case 1:
+			p.Symbol = v.string()
+		case 2:
+			p.Interval = v.string()
+		case 3:
+			p.OpenTime = int64(v.num)
+		case 4:
+			p.Open = v.double()
+		case 5:
+			p.High = v.double()
+		case 6:
+			p.Low = v.double()
+		case 7:
+			p.Close = v.double()
+		case 8:
+			p.Volume = v.double()
+		case 9:
+			p.QuoteVolume = v.double()
+		case 10:
+			p.Closed = v.num != 0
+		}
+		return nil
+	})
+}
+
+func decodeSignalPayload(data []byte) (*SignalPayload, error) {
+	p := &SignalPayload{}
+	return p, consumeProtoFields(data, func(num protowire.Number, v protoValue) error {
+		switch num {
+		case 1:
+			p.Symbol = v.string()
+		case 2:
+			p.Type = v.string()
+		case 3:
+			p.Direction = v.string()
+		case 4:
+			p.Strength = v.double()
+		case 5:
+			p.Severity = v.string()
+		case 6:
+			p.Composite = v.num != 0
+		case 7:
+			p.Reasons = append(p.Reasons, v.string())
+		case 8:
+			p.DetectedAt = int64(v.num)
+		case 9:
+			return json.Unmarshal(v.bytes, &p.Metadata)
+		}
+		return nil
+	})
+}
+
+func decodeAlertPayload(data []byte) (*AlertPayload, error) {
+	p := &AlertPayload{}
+	return p, consumeProtoFields(data, func(num protowire.Number, v protoValue) error {
+		switch num {
+		case 1:
+			p.ID = v.string()
+		case 2:
+			p.Level = v.string()
+		case 3:
+			p.Title = v.string()
+		case 4:
+			p.Message = v.string()
+		case 5:
+			p.Symbol = v.string()
+		case 6:
+			p.Source = v.string()
+		case 7:
+			p.Timestamp = int64(v.num)
+		case 8:
+			return json.Unmarshal(v.bytes, &p.Metadata)
+		}
+		return nil
+	})
+}
+
+func decodeDepthPayload(data []byte) (*DepthPayload, error) {
+	p := &DepthPayload{}
+	return p, consumeProtoFields(data, func(num protowire.Number, v protoValue) error {
+		var err error
+		switch num {
+		case 1:
+			p.Symbol = v.string()
+		case 2:
+			p.Bids, err = v.levels(p.Bids)
+		case 3:
+			p.Asks, err = v.levels(p.Asks)
+		case 4:
+			p.Timestamp = int64(v.num)
+		}
+		return err
+	})
+}
+
+// protoWriter 按 proto3 规则追加字段，零值字段不写出
+type protoWriter struct {
+	buf []byte
+}
+
+func (w *protoWriter) string(num protowire.Number, v string) {
+	if v != "" {
+		w.buf = protowire.AppendTag(w.buf, num, protowire.BytesType)
+		w.buf = protowire.AppendString(w.buf, v)
+	}
+}
+
+func (w *protoWriter) bytes(num protowire.Number, v []byte) {
+	if len(v) > 0 {
+		w.buf = protowire.AppendTag(w.buf, num, protowire.BytesType)
+		w.buf = protowire.AppendBytes(w.buf, v)
+	}
+}
+
+// message 写出嵌套消息，即使为空也写出，以保留 oneof 的选择
+func (w *protoWriter) message(num protowire.Number, v []byte) {
+	w.buf = protowire.AppendTag(w.buf, num, protowire.BytesType)
+	w.buf = protowire.AppendBytes(w.buf, v)
+}
+
+func (w *protoWriter) double(num protowire.Number, v float64) {
+	if v != 0 {
+		w.buf = protowire.AppendTag(w.buf, num, protowire.Fixed64Type)
+		w.buf = protowire.AppendFixed64(w.buf, math.Float64bits(v))
+	}
+}
+
+func (w *protoWriter) int64(num protowire.Number, v int64) {
+	if v != 0 {
+		w.buf = protowire.AppendTag(w.buf, num, protowire.VarintType)
+		w.buf = protowire.AppendVarint(w.buf, uint64(v))
+	}
+}
+
+func (w *protoWriter) uint64(num protowire.Number, v uint64) {
+	if v != 0 {
+		w.buf = protowire.AppendTag(w.buf, num, protowire.VarintType)
+		w.buf = protowire.AppendVarint(w.buf, v)
+	}
+}
+
+func (w *protoWriter) bool(num protowire.Number, v bool) {
+	if v {
+		w.buf = protowire.AppendTag(w.buf, num, protowire.VarintType)
+		w.buf = protowire.AppendVarint(w.buf, 1)
+	}
+}
+
+// levels 以 packed repeated double 写出盘口档位
+func (w *protoWriter) levels(num protowire.Number, levels []DepthLevel) {
+	if len(levels) == 0 {
+		return
+	}
+	w.buf = protowire.AppendTag(w.buf, num, protowire.BytesType)
+	w.buf = protowire.AppendVarint(w.buf, uint64(len(levels)*16))
+	for _, level := range levels {
+		w.buf = protowire.AppendFixed64(w.buf, math.Float64bits(level[0]))
+		w.buf = protowire.AppendFixed64(w.buf, math.Float64bits(level[1]))
+	}
+}
+
+func (w *protoWriter) metadata(num protowire.Number, metadata map[string]interface{}) {
+	if len(metadata) == 0 {
+		return
+	}
+	if raw, err := json.Marshal(metadata); err == nil {
+		w.bytes(num, raw)
+	}
+}
+
+// protoValue 解码出的字段值，varint 和 fixed64 存放在 num 中
+type protoValue struct {
+	typ   protowire.Type
+	num   uint64
+	bytes []byte
+}
+
+func (v protoValue) string() string {
+	return string(v.bytes)
+}
+
+func (v protoValue) double() float64 {
+	return math.Float64frombits(v.num)
+}
+
+// levels 解析 packed 或逐个写出的 repeated double 并追加为盘口档位
+func (v protoValue) levels(levels []DepthLevel) ([]DepthLevel, error) {
+	var values []float64
+	if v.typ == protowire.Fixed64Type {
+		values = append(values, v.double())
+	} else {
+		for b := v.bytes; len(b) > 0; {
+			bits, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			values = append(values, math.Float64frombits(bits))
+			b = b[n:]
+		}
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("盘口档位数据不完整")
+	}
+	for i := 0; i < len(values); i += 2 {
+		levels = append(levels, DepthLevel{values[i], values[i+1]})
+	}
+	return levels, nil
+}
+
+// consumeProtoFields 逐个读取字段并交给 set 处理，不认识的字段类型直接跳过
+func consumeProtoFields(data []byte, set func(num protowire.Number, v protoValue) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		v := protoValue{typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			v.num, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			v.num, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			v.bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := set(num, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
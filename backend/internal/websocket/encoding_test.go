@@ -0,0 +1,237 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+	"go.uber.org/zap"
+)
+
+// TestParseEncoding 测试编码协商参数解析
+func TestParseEncoding(t *testing.T) {
+	for value, expected := range map[string]Encoding{
+		"":         EncodingJSON,
+		"json":     EncodingJSON,
+		"MsgPack":  EncodingMsgpack,
+		"protobuf": EncodingProtobuf,
+	} {
+		encoding, err := ParseEncoding(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, encoding)
+	}
+
+	_, err := ParseEncoding("xml")
+	assert.Error(t, err)
+}
+
+// TestMsgpackEncoder 测试 MessagePack 编解码
+func TestMsgpackEncoder(t *testing.T) {
+	encoder := NewMessageEncoder(EncodingMsgpack)
+
+	// 经背板转发的原始 JSON 按频道类型还原后编码
+	frameType, data, err := encoder.Encode(&ChannelMessage{
+		Type:    MessageTypeUpdate,
+		Channel: "ticker:BTCUSDT",
+		Seq:     7,
+		Data:    json.RawMessage(`{"symbol":"BTCUSDT","last_price":50000.5,"change_rate_24h":1.2,"timestamp":1}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, frameType)
+
+	var decoded map[string]interface{}
+	require.NoError(t, codec.NewDecoderBytes(data, msgpackHandle).Decode(&decoded))
+	assert.Equal(t, "ticker:BTCUSDT", decoded["channel"])
+	assert.EqualValues(t, 7, decoded["seq"])
+	payload := decoded["data"].(map[string]interface{})
+	assert.Equal(t, 50000.5, payload["last_price"])
+	assert.NotContains(t, payload, "bid_price", "沿用 json 标签的 omitempty")
+
+	// 客户端以二进制帧发送的指令
+	rate := 4.0
+	_, data, err = encoder.Encode(&Message{Type: MessageTypeSubscribe, Channels: []string{"ticker:ETHUSDT"}, MaxRate: &rate})
+	require.NoError(t, err)
+	var msg Message
+	require.NoError(t, encoder.Decode(data, &msg))
+	assert.Equal(t, MessageTypeSubscribe, msg.Type)
+	assert.Equal(t, []string{"ticker:ETHUSDT"}, msg.Channels)
+	require.NotNil(t, msg.MaxRate)
+	assert.Equal(t, 4.0, *msg.MaxRate)
+}
+
+// TestProtobufEncoder 测试 Protobuf 编解码
+func TestProtobufEncoder(t *testing.T) {
+	encoder := NewMessageEncoder(EncodingProtobuf)
+
+	payloads := map[string]interface{}{
+		"ticker:BTCUSDT": &TickerPayload{
+			Symbol: "BTCUSDT", LastPrice: 50000.5, BidPrice: 50000, AskPrice: 50001,
+			High24h: 51000, Low24h: 49000, Volume24h: 1234.5, ChangeRate24h: -1.25, Timestamp: 1700000000000,
+		},
+		"kline:1m:BTCUSDT": &KlinePayload{
+			Symbol: "BTCUSDT", Interval: "1m", OpenTime: 1700000000000,
+			Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 10, QuoteVolume: 15, Closed: true,
+		},
+		"signals": &SignalPayload{
+			Symbol: "ETHUSDT", Type: "volume_spike", Direction: "up", Strength: 0.8, Severity: "high",
+			Composite: true, Reasons: []string{"volume", "price"}, Metadata: map[string]interface{}{"ratio": 3.5},
+			DetectedAt: 1700000000000,
+		},
+		"alerts": &AlertPayload{
+			ID: "a-1", Level: "warning", Title: "t", Message: "m", Symbol: "BTCUSDT", Source: "monitor",
+			Metadata: map[string]interface{}{"k": "v"}, Timestamp: 1700000000000,
+		},
+		"depth:BTCUSDT": &DepthPayload{
+			Symbol: "BTCUSDT", Bids: []DepthLevel{{50000, 1.5}, {49999, 2}}, Asks: []DepthLevel{{50001, 0.5}},
+			Timestamp: 1700000000000,
+		},
+	}
+
+	for channel, payload := range payloads {
+		frameType, data, err := encoder.Encode(&ChannelMessage{
+			Type: MessageTypeUpdate, Channel: channel, Seq: 42, Data: payload, Timestamp: 1700000000001,
+		})
+		require.NoError(t, err, channel)
+		assert.Equal(t, websocket.BinaryMessage, frameType)
+
+		envelope, err := DecodeProtobufEnvelope(data)
+		require.NoError(t, err, channel)
+		assert.Equal(t, MessageTypeUpdate, envelope.Type)
+		assert.Equal(t, channel, envelope.Channel)
+		assert.Equal(t, uint64(42), envelope.Seq)
+		assert.Equal(t, int64(1700000000001), envelope.Timestamp)
+		assert.Equal(t, payload, envelope.Payload, channel)
+	}
+
+	// 原始 JSON 数据按频道 schema 编码
+	_, data, err := encoder.Encode(&ChannelMessage{
+		Type: MessageTypeUpdate, Channel: "ticker:ETHUSDT", Seq: 1,
+		Data: json.RawMessage(`{"symbol":"ETHUSDT","last_price":3000}`),
+	})
+	require.NoError(t, err)
+	envelope, err := DecodeProtobufEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, &TickerPayload{Symbol: "ETHUSDT", LastPrice: 3000}, envelope.Payload)
+
+	// 空快照没有 payload
+	_, data, err = encoder.Encode(&ChannelMessage{Type: MessageTypeSnapshot, Channel: "signals", Seq: 3})
+	require.NoError(t, err)
+	envelope, err = DecodeProtobufEnvelope(data)
+	require.NoError(t, err)
+	assert.Nil(t, envelope.Payload)
+	assert.Nil(t, envelope.JSON)
+
+	// 没有 schema 的消息放入 json 字段
+	_, data, err = encoder.Encode(&SubscriptionAck{Type: MessageTypeSubscribed, Channels: []string{"signals"}})
+	require.NoError(t, err)
+	envelope, err = DecodeProtobufEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, MessageTypeSubscribed, envelope.Type)
+	assert.JSONEq(t, `{"type":"subscribed","channels":["signals"],"timestamp":0}`, string(envelope.JSON))
+
+	assert.Error(t, encoder.Decode(data, &Message{}))
+	_, err = DecodeProtobufEnvelope([]byte{0xff})
+	assert.Error(t, err)
+}
+
+// TestWebSocketServer_EncodingNegotiation 测试握手时协商编码与压缩
+func TestWebSocketServer_EncodingNegotiation(t *testing.T) {
+	config := DefaultServerConfig()
+	config.PongWait = 5 * time.Second
+	config.MaxMessageSize = 4096
+	config.CompressionThreshold = 0
+
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	dialer := &websocket.Dialer{EnableCompression: true}
+
+	_, resp, err := dialer.Dial(wsURL+"?encoding=xml", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	subscribe := func(conn *websocket.Conn) {
+		require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribe, Channels: []string{"ticker:BTCUSDT"}}))
+	}
+	readFrame := func(conn *websocket.Conn) (int, []byte) {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		frameType, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		return frameType, data
+	}
+
+	msgpackConn, resp, err := dialer.Dial(wsURL+"?encoding=msgpack", nil)
+	require.NoError(t, err)
+	defer msgpackConn.Close()
+	assert.Contains(t, resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+	subscribe(msgpackConn)
+
+	protobufConn, _, err := dialer.Dial(wsURL+"?encoding=protobuf", nil)
+	require.NoError(t, err)
+	defer protobufConn.Close()
+	subscribe(protobufConn)
+
+	jsonConn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer jsonConn.Close()
+	subscribe(jsonConn)
+
+	// 确认消息
+	frameType, data := readFrame(msgpackConn)
+	assert.Equal(t, websocket.BinaryMessage, frameType)
+	var ack SubscriptionAck
+	require.NoError(t, codec.NewDecoderBytes(data, msgpackHandle).Decode(&ack))
+	assert.Equal(t, MessageTypeSubscribed, ack.Type)
+
+	_, data = readFrame(protobufConn)
+	envelope, err := DecodeProtobufEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, MessageTypeSubscribed, envelope.Type)
+
+	assert.Equal(t, MessageTypeSubscribed, readChannelMessage(t, jsonConn).Type)
+
+	require.NoError(t, server.Publish("ticker:BTCUSDT", &TickerPayload{Symbol: "BTCUSDT", LastPrice: 50000}))
+
+	_, data = readFrame(msgpackConn)
+	var update struct {
+		Channel string        `json:"channel"`
+		Seq     uint64        `json:"seq"`
+		Data    TickerPayload `json:"data"`
+	}
+	require.NoError(t, codec.NewDecoderBytes(data, msgpackHandle).Decode(&update))
+	assert.Equal(t, uint64(1), update.Seq)
+	assert.Equal(t, 50000.0, update.Data.LastPrice)
+
+	_, data = readFrame(protobufConn)
+	envelope, err = DecodeProtobufEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, &TickerPayload{Symbol: "BTCUSDT", LastPrice: 50000}, envelope.Payload)
+
+	message := readChannelMessage(t, jsonConn)
+	assert.Equal(t, uint64(1), message.Seq)
+
+	// 二进制帧按协商的编码解析，解析失败返回错误而不断开连接
+	var ping []byte
+	require.NoError(t, codec.NewEncoderBytes(&ping, msgpackHandle).Encode(&Message{Type: MessageTypePing}))
+	require.NoError(t, msgpackConn.WriteMessage(websocket.BinaryMessage, ping))
+	_, data = readFrame(msgpackConn)
+	var pong Message
+	require.NoError(t, codec.NewDecoderBytes(data, msgpackHandle).Decode(&pong))
+	assert.Equal(t, MessageTypePong, pong.Type)
+
+	require.NoError(t, jsonConn.WriteMessage(websocket.TextMessage, []byte("{invalid")))
+	var errMsg ErrorMessage
+	jsonConn.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, jsonConn.ReadJSON(&errMsg))
+	assert.Equal(t, ErrCodeInvalidMessage, errMsg.Code)
+	assert.Equal(t, 3, server.GetConnectionCount())
+}
@@ -0,0 +1,181 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/ugorji/go/codec"
+)
+
+// Encoding 推送消息编码，客户端在握手时通过 ?encoding= 协商
+type Encoding string
+
+const (
+	EncodingJSON     Encoding = "json"     // JSON 文本帧（默认）
+	EncodingMsgpack  Encoding = "msgpack"  // MessagePack 二进制帧，字段名与 JSON 相同
+	EncodingProtobuf Encoding = "protobuf" // Protobuf 二进制帧，schema 见 stream.proto
+)
+
+// ParseEncoding 解析客户端请求的编码，空字符串表示 JSON
+func ParseEncoding(value string) (Encoding, error) {
+	switch Encoding(strings.ToLower(strings.TrimSpace(value))) {
+	case "", EncodingJSON:
+		return EncodingJSON, nil
+	case EncodingMsgpack:
+		return EncodingMsgpack, nil
+	case EncodingProtobuf:
+		return EncodingProtobuf, nil
+	default:
+		return "", fmt.Errorf("不支持的编码: %s，可选 json、msgpack、protobuf", value)
+	}
+}
+
+// MessageEncoder 连接的消息编解码器
+type MessageEncoder interface {
+	Encoding() Encoding
+	// Encode 编码推送消息，返回 websocket 帧类型和数据
+	Encode(message interface{}) (frameType int, data []byte, err error)
+	// Decode 解码客户端发送的二进制帧，文本帧始终按 JSON 解析
+	Decode(data []byte, msg *Message) error
+}
+
+// NewMessageEncoder 创建指定编码的编解码器
+func NewMessageEncoder(encoding Encoding) MessageEncoder {
+	switch encoding {
+	case EncodingMsgpack:
+		return msgpackEncoder{}
+	case EncodingProtobuf:
+		return protobufEncoder{}
+	default:
+		return jsonEncoder{}
+	}
+}
+
+// jsonEncoder JSON 编解码器
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encoding() Encoding {
+	return EncodingJSON
+}
+
+func (jsonEncoder) Encode(message interface{}) (int, []byte, error) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return 0, nil, fmt.Errorf("JSON 编码失败: %w", err)
+	}
+	return websocket.TextMessage, data, nil
+}
+
+func (jsonEncoder) Decode(data []byte, msg *Message) error {
+	return json.Unmarshal(data, msg)
+}
+
+// msgpackHandle MessagePack 编码配置，沿用结构体的 json 标签
+var msgpackHandle = func() *codec.MsgpackHandle {
+	handle := &codec.MsgpackHandle{}
+	handle.WriteExt = true // 字符串和二进制使用 str8/bin 类型
+	handle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	handle.RawToString = true
+	return handle
+}()
+
+// msgpackEncoders 复用 MessagePack 编码器，创建编码器的开销远大于编码本身
+var msgpackEncoders = sync.Pool{
+	New: func() interface{} {
+		return codec.NewEncoderBytes(nil, msgpackHandle)
+	},
+}
+
+// msgpackEncoder MessagePack 编解码器
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) Encoding() Encoding {
+	return EncodingMsgpack
+}
+
+func (msgpackEncoder) Encode(message interface{}) (int, []byte, error) {
+	message, err := normalizeMessage(message)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var data []byte
+	encoder := msgpackEncoders.Get().(*codec.Encoder)
+	encoder.ResetBytes(&data)
+	err = encoder.Encode(message)
+	encoder.ResetBytes(nil)
+	msgpackEncoders.Put(encoder)
+	if err != nil {
+		return 0, nil, fmt.Errorf("MessagePack 编码失败: %w", err)
+	}
+	return websocket.BinaryMessage, data, nil
+}
+
+func (msgpackEncoder) Decode(data []byte, msg *Message) error {
+	if err := codec.NewDecoderBytes(data, msgpackHandle).Decode(msg); err != nil {
+		return fmt.Errorf("MessagePack 解码失败: %w", err)
+	}
+	return nil
+}
+
+// normalizeMessage 把经背板转发的原始 JSON 还原为结构化数据，供二进制编码使用
+func normalizeMessage(message interface{}) (interface{}, error) {
+	switch msg := message.(type) {
+	case json.RawMessage:
+		var value interface{}
+		if err := json.Unmarshal(msg, &value); err != nil {
+			return nil, fmt.Errorf("解析原始消息失败: %w", err)
+		}
+		return value, nil
+	case *ChannelMessage:
+		if raw, ok := msg.Data.(json.RawMessage); ok {
+			data, err := decodeRawPayload(msg.Channel, raw)
+			if err != nil {
+				return nil, err
+			}
+			normalized := *msg
+			normalized.Data = data
+			return &normalized, nil
+		}
+	}
+	return message, nil
+}
+
+// decodeRawPayload 按频道类型把原始 JSON 解析为对应的 Payload
+func decodeRawPayload(channelName string, raw json.RawMessage) (interface{}, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+
+	var payload interface{}
+	if channel, chErr := ParseChannel(channelName); chErr == nil {
+		switch channel.Type {
+		case ChannelTicker:
+			payload = &TickerPayload{}
+		case ChannelKline:
+			payload = &KlinePayload{}
+		case ChannelSignals:
+			payload = &SignalPayload{}
+		case ChannelAlerts:
+			payload = &AlertPayload{}
+		case ChannelDepth:
+			payload = &DepthPayload{}
+		}
+	}
+	if payload == nil {
+		var value interface{}
+		payload = &value
+	}
+
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return nil, fmt.Errorf("解析频道 %s 数据失败: %w", channelName, err)
+	}
+	if value, ok := payload.(*interface{}); ok {
+		return *value, nil
+	}
+	return payload, nil
+}
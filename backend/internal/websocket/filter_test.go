@@ -0,0 +1,272 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
+
+// filterTestMessage 过滤订阅测试中读取的消息
+type filterTestMessage struct {
+	Type     string           `json:"type"`
+	ID       string           `json:"id"`
+	Code     string           `json:"code"`
+	FilterID string           `json:"filter_id"`
+	Filter   *FilterPredicate `json:"filter"`
+	Symbol   string           `json:"symbol"`
+	Data     *MarketMetrics   `json:"data"`
+}
+
+func readFilterMessage(t *testing.T, conn *websocket.Conn) *filterTestMessage {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var msg filterTestMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return &msg
+}
+
+// assertNoMessage 确认之前没有待收的推送：发送 ping 后收到的下一条消息应是 pong
+// 读超时会使 gorilla 连接永久失效，因此不能用超时判断
+func assertNoMessage(t *testing.T, conn *websocket.Conn) {
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypePing}))
+	assert.Equal(t, MessageTypePong, readFilterMessage(t, conn).Type)
+}
+
+// stubConfigSource 内存监控配置来源
+type stubConfigSource map[int64]*models.MonitoringConfig
+
+func (s stubConfigSource) GetByID(id int64) (*models.MonitoringConfig, error) {
+	config, exists := s[id]
+	if !exists {
+		return nil, fmt.Errorf("monitoring config not found: %d", id)
+	}
+	return config, nil
+}
+
+// TestFilterPredicate 测试过滤条件校验与匹配
+func TestFilterPredicate(t *testing.T) {
+	t.Run("校验", func(t *testing.T) {
+		for name, predicate := range map[string]*FilterPredicate{
+			"空条件":     {},
+			"缺少窗口":    {MinChange: float64Ptr(2)},
+			"窗口无效":    {Windows: []string{"5x"}, MinChange: float64Ptr(2)},
+			"负变化率":    {Windows: []string{"5m"}, MinChange: float64Ptr(-1)},
+			"价格区间无效":  {MinPrice: float64Ptr(2), MaxPrice: float64Ptr(1)},
+			"成交量区间无效": {MinVolume: float64Ptr(2), MaxVolume: float64Ptr(1)},
+			"交易对无效":   {Symbols: []string{"BTC-USDT"}},
+		} {
+			assert.Error(t, predicate.Validate(), name)
+		}
+
+		predicate := &FilterPredicate{Symbols: []string{"btcusdt"}}
+		require.NoError(t, predicate.Validate())
+		assert.Equal(t, []string{"BTCUSDT"}, predicate.Symbols)
+	})
+
+	t.Run("匹配", func(t *testing.T) {
+		predicate := &FilterPredicate{
+			Windows:   []string{"1m", "5m"},
+			MinChange: float64Ptr(2),
+			MinVolume: float64Ptr(1000),
+		}
+		require.NoError(t, predicate.Validate())
+
+		metrics := &MarketMetrics{
+			Symbol:      "BTCUSDT",
+			Volume24h:   float64Ptr(5000),
+			ChangeRates: map[string]float64{"1m": 0.5, "5m": -2.5, "1h": 10},
+		}
+		assert.True(t, predicate.Match(metrics), "任一窗口的变化率绝对值达到阈值")
+
+		metrics.ChangeRates["5m"] = 1.9
+		assert.False(t, predicate.Match(metrics))
+
+		metrics.ChangeRates["5m"] = 3
+		metrics.Volume24h = nil
+		assert.False(t, predicate.Match(metrics), "缺少成交量时不满足")
+	})
+
+	t.Run("合并监控配置", func(t *testing.T) {
+		config := &models.MonitoringConfig{
+			ID: 3,
+			Filters: models.MonitoringConfigFilters{
+				TimeWindows:     []string{"5m", "4h"},
+				ChangeThreshold: 5,
+				VolumeThreshold: 1000,
+				Symbols:         []string{"BTCUSDT", "ETHUSDT"},
+				MaxPrice:        float64Ptr(100000),
+			},
+		}
+
+		merged := mergeMonitoringConfig(config, &FilterPredicate{ConfigID: 3, MinChange: float64Ptr(2)})
+		require.NoError(t, merged.Validate())
+		assert.Equal(t, int64(3), merged.ConfigID)
+		assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, merged.Symbols)
+		assert.Equal(t, []string{"5m", "4h"}, merged.Windows)
+		assert.Equal(t, 2.0, *merged.MinChange, "客户端条件覆盖配置")
+		assert.Equal(t, 1000.0, *merged.MinVolume, "成交量阈值作为成交量下限")
+		assert.Equal(t, 100000.0, *merged.MaxPrice)
+	})
+}
+
+// TestWebSocketServer_FilterSubscription 测试过滤订阅的进入、更新和退出通知
+func TestWebSocketServer_FilterSubscription(t *testing.T) {
+	config := DefaultServerConfig()
+	config.PongWait = 5 * time.Second
+	config.MaxMessageSize = 4096
+	config.MaxFiltersPerConnection = 2
+
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	server.SetMonitoringConfigSource(stubConfigSource{
+		7: {ID: 7, Filters: models.MonitoringConfigFilters{
+			TimeWindows: []string{"5m"}, ChangeThreshold: 2, Symbols: []string{"ETHUSDT"},
+		}},
+	})
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	update := func(symbol string, change5m, volume float64) {
+		require.NoError(t, server.UpdateMarketMetrics(&cache.ScannerTicker{
+			Symbol:      symbol,
+			Timestamp:   time.Now(),
+			LastPrice:   float64Ptr(100),
+			Volume24h:   float64Ptr(volume),
+			ChangeRates: map[string]float64{"5m": change5m},
+		}))
+	}
+
+	// 订阅前已满足条件的交易对在确认后立即推送
+	update("BTCUSDT", 3, 5000)
+	update("SOLUSDT", 1, 5000)
+
+	require.NoError(t, conn.WriteJSON(Message{
+		Type:   MessageTypeSubscribeFilter,
+		ID:     "req-1",
+		Filter: &FilterPredicate{Windows: []string{"5m"}, MinChange: float64Ptr(2), MinVolume: float64Ptr(1000)},
+	}))
+	ack := readFilterMessage(t, conn)
+	assert.Equal(t, MessageTypeFilterSubscribed, ack.Type)
+	assert.Equal(t, "req-1", ack.ID)
+	assert.Equal(t, "f1", ack.FilterID)
+
+	enter := readFilterMessage(t, conn)
+	assert.Equal(t, MessageTypeFilterEnter, enter.Type)
+	assert.Equal(t, "BTCUSDT", enter.Symbol)
+	assert.Equal(t, 3.0, enter.Data.ChangeRates["5m"])
+
+	// 不满足条件的更新不推送
+	update("SOLUSDT", 1.5, 5000)
+	assertNoMessage(t, conn)
+
+	update("SOLUSDT", -2.5, 5000)
+	enter = readFilterMessage(t, conn)
+	assert.Equal(t, MessageTypeFilterEnter, enter.Type)
+	assert.Equal(t, "SOLUSDT", enter.Symbol)
+
+	update("SOLUSDT", -4, 5000)
+	updated := readFilterMessage(t, conn)
+	assert.Equal(t, MessageTypeFilterUpdate, updated.Type)
+	assert.Equal(t, -4.0, updated.Data.ChangeRates["5m"])
+
+	update("SOLUSDT", -4, 500)
+	exit := readFilterMessage(t, conn)
+	assert.Equal(t, MessageTypeFilterExit, exit.Type)
+	assert.Equal(t, "SOLUSDT", exit.Symbol)
+	assert.Equal(t, 500.0, *exit.Data.Volume24h)
+
+	// 按监控配置订阅，只关注配置中的交易对
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribeFilter, Filter: &FilterPredicate{ConfigID: 7}}))
+	ack = readFilterMessage(t, conn)
+	assert.Equal(t, "f2", ack.FilterID)
+	assert.Equal(t, []string{"ETHUSDT"}, ack.Filter.Symbols)
+
+	update("ETHUSDT", 2.2, 10)
+	enter = readFilterMessage(t, conn)
+	assert.Equal(t, "f2", enter.FilterID)
+	assert.Equal(t, "ETHUSDT", enter.Symbol)
+
+	// 超过上限和无效条件
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribeFilter, Filter: &FilterPredicate{Symbols: []string{"BTCUSDT"}}}))
+	assert.Equal(t, ErrCodeFilterLimit, readFilterMessage(t, conn).Code)
+
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeUnsubscribeFilter, ID: "req-2", FilterID: "f1"}))
+	ack = readFilterMessage(t, conn)
+	assert.Equal(t, MessageTypeFilterUnsubscribed, ack.Type)
+	assert.Equal(t, "f1", ack.FilterID)
+
+	update("BTCUSDT", 1, 5000)
+	assertNoMessage(t, conn)
+
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeUnsubscribeFilter, FilterID: "f1"}))
+	assert.Equal(t, ErrCodeFilterNotFound, readFilterMessage(t, conn).Code)
+
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribeFilter, Filter: &FilterPredicate{ConfigID: 8}}))
+	assert.Equal(t, ErrCodeInvalidFilter, readFilterMessage(t, conn).Code)
+
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribeFilter, Filter: &FilterPredicate{MinChange: float64Ptr(1)}}))
+	assert.Equal(t, ErrCodeInvalidFilter, readFilterMessage(t, conn).Code)
+}
+
+// TestWebSocketServer_FilterBackplane 测试行情指标经背板到达其他实例的过滤订阅
+func TestWebSocketServer_FilterBackplane(t *testing.T) {
+	_, client := setupRedisClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newServer := func(instanceID string) (*WebSocketServerImpl, string) {
+		config := DefaultServerConfig()
+		config.Port = 0
+		config.PongWait = 5 * time.Second
+		config.MaxMessageSize = 4096
+
+		server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+		server.SetBackplane(NewRedisBackplane(client, instanceID, zap.NewNop()))
+		require.NoError(t, server.backplane.Start(ctx, server.handleBackplaneMessage))
+		t.Cleanup(func() { server.backplane.Stop(context.Background()) })
+
+		httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+		t.Cleanup(httpServer.Close)
+		return server, "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	}
+
+	serverA, _ := newServer("instance-a")
+	_, urlB := newServer("instance-b")
+
+	conn, _, err := websocket.DefaultDialer.Dial(urlB, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribeFilter, Filter: &FilterPredicate{Symbols: []string{"BTCUSDT"}}}))
+	assert.Equal(t, MessageTypeFilterSubscribed, readFilterMessage(t, conn).Type)
+
+	require.NoError(t, serverA.UpdateMarketMetrics(&cache.ScannerTicker{Symbol: "BTCUSDT", LastPrice: float64Ptr(50000)}))
+
+	event := readFilterMessage(t, conn)
+	assert.Equal(t, MessageTypeFilterEnter, event.Type)
+	assert.Equal(t, 50000.0, *event.Data.LastPrice)
+
+	raw, err := json.Marshal(event.Data)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "volume_24h")
+}
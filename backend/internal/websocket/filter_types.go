@@ -0,0 +1,282 @@
+package websocket
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/models"
+)
+
+// 过滤订阅消息类型
+const (
+	MessageTypeSubscribeFilter    = "subscribe_filter"
+	MessageTypeUnsubscribeFilter  = "unsubscribe_filter"
+	MessageTypeFilterSubscribed   = "filter_subscribed"
+	MessageTypeFilterUnsubscribed = "filter_unsubscribed"
+	MessageTypeFilterEnter        = "filter_enter"  // 交易对开始满足条件
+	MessageTypeFilterUpdate       = "filter_update" // 满足条件的交易对指标更新
+	MessageTypeFilterExit         = "filter_exit"   // 交易对不再满足条件
+)
+
+// 过滤订阅错误码
+const (
+	ErrCodeInvalidFilter  = "INVALID_FILTER"   // 过滤条件无效
+	ErrCodeFilterLimit    = "FILTER_LIMIT"     // 超过单连接过滤订阅上限
+	ErrCodeFilterNotFound = "FILTER_NOT_FOUND" // 取消不存在的过滤订阅
+)
+
+// MonitoringConfigSource 按 ID 加载监控配置，dao.MonitoringConfigDAO 满足该接口
+type MonitoringConfigSource interface {
+	GetByID(id int64) (*models.MonitoringConfig, error)
+}
+
+// FilterPredicate 过滤订阅条件，设置的条件需同时满足
+// 指定 ConfigID 时以监控配置的过滤条件为基础，其余字段覆盖配置中的同名条件
+type FilterPredicate struct {
+	ConfigID  int64    `json:"config_id,omitempty"`
+	Symbols   []string `json:"symbols,omitempty"`    // 自选交易对，为空表示全部交易对
+	Windows   []string `json:"windows,omitempty"`    // 变化率时间窗口，任一窗口满足即可
+	MinChange *float64 `json:"min_change,omitempty"` // 变化率绝对值下限(%)
+	MinPrice  *float64 `json:"min_price,omitempty"`
+	MaxPrice  *float64 `json:"max_price,omitempty"`
+	MinVolume *float64 `json:"min_volume,omitempty"` // 24小时成交量下限
+	MaxVolume *float64 `json:"max_volume,omitempty"`
+}
+
+// MarketMetrics 单个交易对的最新行情指标，由 UpdateMarketMetrics 按交易对合并
+type MarketMetrics struct {
+	Symbol      string             `json:"symbol"`
+	LastPrice   *float64           `json:"last_price,omitempty"`
+	Volume24h   *float64           `json:"volume_24h,omitempty"`
+	VolumeSurge *float64           `json:"volume_surge,omitempty"`
+	FundingRate *float64           `json:"funding_rate,omitempty"`
+	ChangeRates map[string]float64 `json:"change_rates,omitempty"` // 时间窗口 -> 变化率(%)
+	Timestamp   int64              `json:"timestamp"`
+}
+
+// FilterAck 过滤订阅/取消订阅确认
+type FilterAck struct {
+	Type      string           `json:"type"`
+	ID        string           `json:"id,omitempty"` // 客户端请求ID
+	FilterID  string           `json:"filter_id"`
+	Filter    *FilterPredicate `json:"filter,omitempty"` // 合并监控配置后实际生效的条件
+	Timestamp int64            `json:"timestamp"`
+}
+
+// FilterEvent 过滤订阅推送，Data 为交易对当前的指标
+type FilterEvent struct {
+	Type      string         `json:"type"`
+	FilterID  string         `json:"filter_id"`
+	Symbol    string         `json:"symbol"`
+	Data      *MarketMetrics `json:"data"`
+	Timestamp int64          `json:"timestamp"`
+}
+
+// connectionFilter 连接的一个过滤订阅
+type connectionFilter struct {
+	id        string
+	conn      *Connection
+	predicate *FilterPredicate
+	symbols   map[string]bool // 为空表示全部交易对
+	matching  map[string]bool // 当前满足条件的交易对
+}
+
+// newConnectionFilter 校验过滤条件并创建过滤订阅
+func newConnectionFilter(id string, conn *Connection, predicate *FilterPredicate) (*connectionFilter, error) {
+	if err := predicate.Validate(); err != nil {
+		return nil, err
+	}
+
+	filter := &connectionFilter{
+		id:        id,
+		conn:      conn,
+		predicate: predicate,
+		matching:  make(map[string]bool),
+	}
+	if len(predicate.Symbols) > 0 {
+		filter.symbols = make(map[string]bool, len(predicate.Symbols))
+		for _, symbol := range predicate.Symbols {
+			filter.symbols[symbol] = true
+		}
+	}
+	return filter, nil
+}
+
+// Validate 校验过滤条件并规范化交易对
+func (p *FilterPredicate) Validate() error {
+	for i, symbol := range p.Symbols {
+		normalized, ok := normalizeSymbol(symbol)
+		if !ok {
+			return fmt.Errorf("交易对格式无效: %s", symbol)
+		}
+		p.Symbols[i] = normalized
+	}
+
+	for _, window := range p.Windows {
+		if _, err := models.ParseTimeWindow(window); err != nil {
+			return fmt.Errorf("时间窗口格式无效: %s", window)
+		}
+	}
+
+	if p.MinChange != nil {
+		if *p.MinChange < 0 || math.IsNaN(*p.MinChange) {
+			return fmt.Errorf("min_change 不能为负数")
+		}
+		if len(p.Windows) == 0 {
+			return fmt.Errorf("设置 min_change 时必须指定 windows")
+		}
+	}
+
+	if p.MinPrice != nil && p.MaxPrice != nil && *p.MinPrice > *p.MaxPrice {
+		return fmt.Errorf("min_price 不能大于 max_price")
+	}
+	if p.MinVolume != nil && p.MaxVolume != nil && *p.MinVolume > *p.MaxVolume {
+		return fmt.Errorf("min_volume 不能大于 max_volume")
+	}
+
+	if len(p.Symbols) == 0 && p.MinChange == nil && p.MinPrice == nil && p.MaxPrice == nil &&
+		p.MinVolume == nil && p.MaxVolume == nil {
+		return fmt.Errorf("过滤条件不能为空")
+	}
+	return nil
+}
+
+// mergeMonitoringConfig 以监控配置的过滤条件为基础，合并客户端指定的条件
+// 配置的成交量阈值在未指定 min_volume 时作为成交量下限
+func mergeMonitoringConfig(config *models.MonitoringConfig, predicate *FilterPredicate) *FilterPredicate {
+	filters := config.Filters
+	merged := &FilterPredicate{
+		ConfigID:  config.ID,
+		Symbols:   append([]string{}, filters.Symbols...),
+		Windows:   append([]string{}, filters.TimeWindows...),
+		MinPrice:  filters.MinPrice,
+		MaxPrice:  filters.MaxPrice,
+		MinVolume: filters.MinVolume,
+		MaxVolume: filters.MaxVolume,
+	}
+	if filters.ChangeThreshold > 0 {
+		threshold := filters.ChangeThreshold
+		merged.MinChange = &threshold
+	}
+	if merged.MinVolume == nil && filters.VolumeThreshold > 0 {
+		threshold := filters.VolumeThreshold
+		merged.MinVolume = &threshold
+	}
+
+	if len(predicate.Symbols) > 0 {
+		merged.Symbols = predicate.Symbols
+	}
+	if len(predicate.Windows) > 0 {
+		merged.Windows = predicate.Windows
+	}
+	if predicate.MinChange != nil {
+		merged.MinChange = predicate.MinChange
+	}
+	if predicate.MinPrice != nil {
+		merged.MinPrice = predicate.MinPrice
+	}
+	if predicate.MaxPrice != nil {
+		merged.MaxPrice = predicate.MaxPrice
+	}
+	if predicate.MinVolume != nil {
+		merged.MinVolume = predicate.MinVolume
+	}
+	if predicate.MaxVolume != nil {
+		merged.MaxVolume = predicate.MaxVolume
+	}
+	return merged
+}
+
+// Match 判断交易对指标是否满足条件，缺少条件所需的指标时视为不满足
+func (p *FilterPredicate) Match(metrics *MarketMetrics) bool {
+	if p.MinChange != nil {
+		matched := false
+		for _, window := range p.Windows {
+			if rate, ok := metrics.ChangeRates[window]; ok && math.Abs(rate) >= *p.MinChange {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if (p.MinPrice != nil || p.MaxPrice != nil) && !valueInRange(metrics.LastPrice, p.MinPrice, p.MaxPrice) {
+		return false
+	}
+	if (p.MinVolume != nil || p.MaxVolume != nil) && !valueInRange(metrics.Volume24h, p.MinVolume, p.MaxVolume) {
+		return false
+	}
+	return true
+}
+
+// match 判断交易对是否满足过滤订阅
+func (f *connectionFilter) match(metrics *MarketMetrics) bool {
+	if f.symbols != nil && !f.symbols[metrics.Symbol] {
+		return false
+	}
+	return f.predicate.Match(metrics)
+}
+
+// valueInRange 判断指标是否在区间内，指标缺失时返回 false
+func valueInRange(value, min, max *float64) bool {
+	if value == nil {
+		return false
+	}
+	if min != nil && *value < *min {
+		return false
+	}
+	if max != nil && *value > *max {
+		return false
+	}
+	return true
+}
+
+// merge 合并扫描器行情更新，未设置的指标保持原值
+func (m *MarketMetrics) merge(ticker *cache.ScannerTicker) {
+	if ticker.LastPrice != nil {
+		m.LastPrice = floatPtr(*ticker.LastPrice)
+	}
+	if ticker.Volume24h != nil {
+		m.Volume24h = floatPtr(*ticker.Volume24h)
+	}
+	if ticker.VolumeSurge != nil {
+		m.VolumeSurge = floatPtr(*ticker.VolumeSurge)
+	}
+	if ticker.FundingRate != nil {
+		m.FundingRate = floatPtr(*ticker.FundingRate)
+	}
+	if len(ticker.ChangeRates) > 0 {
+		if m.ChangeRates == nil {
+			m.ChangeRates = make(map[string]float64, len(ticker.ChangeRates))
+		}
+		for window, rate := range ticker.ChangeRates {
+			m.ChangeRates[window] = rate
+		}
+	}
+	if ticker.Timestamp.IsZero() {
+		m.Timestamp = time.Now().UnixMilli()
+	} else {
+		m.Timestamp = ticker.Timestamp.UnixMilli()
+	}
+}
+
+// floatPtr 复制指标值，避免与调用方共享指针
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+// clone 复制指标，推送的消息可能在写协程中编码，不能与后续合并共享数据
+func (m *MarketMetrics) clone() *MarketMetrics {
+	cloned := *m
+	if m.ChangeRates != nil {
+		cloned.ChangeRates = make(map[string]float64, len(m.ChangeRates))
+		for window, rate := range m.ChangeRates {
+			cloned.ChangeRates[window] = rate
+		}
+	}
+	return &cloned
+}
@@ -0,0 +1,192 @@
+package websocket
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tickerUpdate(seq uint64) *ChannelMessage {
+	return &ChannelMessage{Type: MessageTypeUpdate, Channel: "ticker:BTCUSDT", Seq: seq}
+}
+
+// drainQueue 取出当前所有可发送的消息
+func drainQueue(q *outboundQueue, now time.Time) []interface{} {
+	var messages []interface{}
+	for {
+		message, _, _ := q.next(now)
+		if message == nil {
+			return messages
+		}
+		messages = append(messages, message)
+	}
+}
+
+// TestOutboundQueue_Conflation 测试积压时合并同频道的更新
+func TestOutboundQueue_Conflation(t *testing.T) {
+	q := newOutboundQueue(16, 0, nil, nil)
+
+	// 积压未达到容量的 1/4 时不合并
+	require.NoError(t, q.push(tickerUpdate(1)))
+	require.NoError(t, q.push(tickerUpdate(2)))
+	require.NoError(t, q.push(tickerUpdate(3)))
+	signal := &ChannelMessage{Type: MessageTypeUpdate, Channel: "signals", Seq: 1}
+	require.NoError(t, q.push(signal))
+	require.NoError(t, q.push(tickerUpdate(4)))
+	require.NoError(t, q.push(signal))
+
+	messages := drainQueue(q, time.Now())
+	require.Len(t, messages, 5)
+	assert.Equal(t, uint64(2), messages[1].(*ChannelMessage).Seq)
+	assert.Equal(t, uint64(4), messages[2].(*ChannelMessage).Seq, "seq 3 被 seq 4 覆盖")
+	assert.Equal(t, signal, messages[3], "非行情频道不合并")
+	assert.Equal(t, signal, messages[4])
+	assert.Equal(t, uint64(1), q.stats().Conflated)
+
+	// 快照之后的更新不会合并到快照之前
+	require.NoError(t, q.push(signal))
+	require.NoError(t, q.push(tickerUpdate(5)))
+	snapshot := &ChannelMessage{Type: MessageTypeSnapshot, Channel: "ticker:BTCUSDT", Seq: 5}
+	require.NoError(t, q.push(snapshot))
+	require.NoError(t, q.push(tickerUpdate(6)))
+
+	messages = drainQueue(q, time.Now())
+	require.Len(t, messages, 4)
+	assert.Equal(t, uint64(5), messages[1].(*ChannelMessage).Seq)
+	assert.Equal(t, snapshot, messages[2])
+	assert.Equal(t, uint64(6), messages[3].(*ChannelMessage).Seq)
+}
+
+// TestOutboundQueue_MaxRate 测试按频道限速
+func TestOutboundQueue_MaxRate(t *testing.T) {
+	q := newOutboundQueue(64, 0, nil, nil)
+	q.setMaxRate(4)
+	start := time.Now()
+
+	require.NoError(t, q.push(tickerUpdate(1)))
+	message, _, _ := q.next(start)
+	assert.Equal(t, uint64(1), message.(*ChannelMessage).Seq)
+
+	// 间隔内到达的更新只保留最新一条，其他消息不受影响
+	require.NoError(t, q.push(tickerUpdate(2)))
+	require.NoError(t, q.push(tickerUpdate(3)))
+	require.NoError(t, q.push(&ChannelMessage{Type: MessageTypeUpdate, Channel: "alerts"}))
+	require.NoError(t, q.push(tickerUpdate(4)))
+
+	now := start.Add(100 * time.Millisecond)
+	message, _, _ = q.next(now)
+	assert.Equal(t, "alerts", message.(*ChannelMessage).Channel)
+	message, wait, _ := q.next(now)
+	assert.Nil(t, message)
+	assert.Equal(t, 150*time.Millisecond, wait)
+
+	message, _, _ = q.next(start.Add(250 * time.Millisecond))
+	assert.Equal(t, uint64(4), message.(*ChannelMessage).Seq)
+	assert.Equal(t, uint64(2), q.stats().Conflated)
+	assert.Equal(t, 0, q.len())
+
+	// 取消限速
+	q.setMaxRate(0)
+	require.NoError(t, q.push(tickerUpdate(5)))
+	message, _, _ = q.next(start.Add(260 * time.Millisecond))
+	assert.Equal(t, uint64(5), message.(*ChannelMessage).Seq)
+}
+
+// TestOutboundQueue_SlowConsumer 测试队列溢出时丢弃消息并在持续溢出后触发断开
+func TestOutboundQueue_SlowConsumer(t *testing.T) {
+	var slowCalls atomic.Int32
+	counters := &outboundCounters{}
+	q := newOutboundQueue(2, 20*time.Millisecond, counters, func() { slowCalls.Add(1) })
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, q.push(&Message{Type: "notice"}))
+	}
+	assert.Equal(t, uint64(2), q.stats().Dropped)
+	assert.Equal(t, uint64(2), counters.dropped.Load())
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, q.push(&Message{Type: "notice"}))
+	require.NoError(t, q.push(&Message{Type: "notice"}))
+	assert.Eventually(t, func() bool { return slowCalls.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	// 消费到半数以下后重新计时
+	q2 := newOutboundQueue(2, 20*time.Millisecond, nil, func() { slowCalls.Add(1) })
+	q2.push(&Message{})
+	q2.push(&Message{})
+	q2.push(&Message{})
+	time.Sleep(30 * time.Millisecond)
+	q2.next(time.Now())
+	q2.next(time.Now())
+	q2.push(&Message{})
+	q2.push(&Message{})
+	q2.push(&Message{})
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), slowCalls.Load())
+
+	q.close()
+	assert.Error(t, q.push(&Message{}))
+	_, _, closed := q.next(time.Now())
+	assert.True(t, closed)
+}
+
+// TestWebSocketServer_OutboundQueue 测试客户端限速和慢消费者断开
+func TestWebSocketServer_OutboundQueue(t *testing.T) {
+	config := DefaultServerConfig()
+	config.PongWait = 5 * time.Second
+	config.MaxMessageSize = 4096
+	config.MessageQueueSize = 8
+	config.SlowConsumerTimeout = 50 * time.Millisecond
+	server, conn := setupChannelTestServer(t, config)
+
+	rate := 5.0
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribe, Channels: []string{"ticker:BTCUSDT"}, MaxRate: &rate}))
+	assert.Equal(t, MessageTypeSubscribed, readChannelMessage(t, conn).Type)
+
+	for price := 1.0; price <= 5; price++ {
+		require.NoError(t, server.Publish("ticker:BTCUSDT", &TickerPayload{Symbol: "BTCUSDT", LastPrice: price}))
+	}
+	assert.Equal(t, uint64(1), readChannelMessage(t, conn).Seq)
+	assert.Equal(t, uint64(5), readChannelMessage(t, conn).Seq, "限速期间的更新被合并")
+	assert.Equal(t, uint64(3), server.GetOutboundMetrics().Conflated)
+
+	invalid := -1.0
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribe, Channels: []string{"signals"}, MaxRate: &invalid}))
+	var errMsg ErrorMessage
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, conn.ReadJSON(&errMsg))
+	assert.Equal(t, ErrCodeInvalidRate, errMsg.Code)
+
+	// 阻塞写协程模拟慢消费者
+	connections := server.GetConnections()
+	require.Len(t, connections, 1)
+	serverConn := connections[0]
+	serverConn.writeMu.Lock()
+
+	notice := &Message{Type: "notice"}
+	fill := func() {
+		for i := 0; i <= server.config.MessageQueueSize+1; i++ {
+			server.SendToConnection(serverConn.ID, notice)
+		}
+	}
+	fill()
+	time.Sleep(server.config.SlowConsumerTimeout)
+	fill()
+	serverConn.writeMu.Unlock()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var err error
+	for err == nil {
+		_, _, err = conn.ReadMessage()
+	}
+	closeErr, ok := err.(*websocket.CloseError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, CloseCodeSlowConsumer, closeErr.Code)
+
+	metrics := server.GetOutboundMetrics()
+	assert.Equal(t, uint64(1), metrics.SlowConsumerDisconnects)
+	assert.NotZero(t, metrics.Dropped)
+}
@@ -0,0 +1,288 @@
+package websocket
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebSocket 关闭码（4000-4999 为应用自定义）
+const (
+	CloseCodeUnauthorized    = 4001 // Token 缺失、无效或权限不足
+	CloseCodeTokenExpired    = 4002 // 连接期间 Token 过期
+	CloseCodeSlowConsumer    = 4008 // 客户端消费过慢，发送队列持续溢出
+	CloseCodeConnectionLimit = 4029 // 超过单个用户的连接数上限
+)
+
+// OutboundMetrics 发送队列统计
+type OutboundMetrics struct {
+	Sent                    uint64 `json:"sent"`                      // 已写出的消息数
+	Dropped                 uint64 `json:"dropped"`                   // 队列已满被丢弃的消息数
+	Conflated               uint64 `json:"conflated"`                 // 被更新消息覆盖的消息数
+	SlowConsumerDisconnects uint64 `json:"slow_consumer_disconnects"` // 因消费过慢断开的连接数
+	Queued                  int    `json:"queued"`                    // 当前排队中的消息数
+}
+
+// outboundCounters 服务器级发送计数，由所有连接的发送队列共享
+type outboundCounters struct {
+	sent                    atomic.Uint64
+	dropped                 atomic.Uint64
+	conflated               atomic.Uint64
+	slowConsumerDisconnects atomic.Uint64
+}
+
+// outboundItem 队列中的待发送消息，key 非空表示可以被同频道更新的消息覆盖
+type outboundItem struct {
+	message interface{}
+	key     string
+	order   uint64 // 入队顺序，覆盖时更新
+}
+
+// outboundQueue 单个连接的有界发送队列，由连接专属的写协程消费
+//
+// 积压超过容量的 1/4 时视为客户端跟不上，ticker/depth 频道的更新只保留每个频道最新的一条；
+// 设置了最大推送频率时，同一频道两次推送的间隔内到达的更新也只保留最新一条；
+// 队列已满时新消息被丢弃，持续溢出超过 slowTimeout 则触发 onSlow 断开连接
+type outboundQueue struct {
+	mu        sync.Mutex
+	items     []*outboundItem
+	pending   map[string]*outboundItem // 队列中每个频道最新的可合并消息
+	throttled map[string]*outboundItem // 受频率限制暂缓发送的可合并消息
+	lastSent  map[string]time.Time
+	pushed    uint64
+
+	capacity      int
+	minInterval   time.Duration // 同一频道两次推送的最小间隔，0 表示不限制
+	slowTimeout   time.Duration
+	overflowSince time.Time
+	slow          bool
+	closed        bool
+
+	sent      uint64
+	dropped   uint64
+	conflated uint64
+
+	counters *outboundCounters
+	onSlow   func()
+	notify   chan struct{}
+	done     chan struct{}
+}
+
+// newOutboundQueue 创建发送队列
+func newOutboundQueue(capacity int, slowTimeout time.Duration, counters *outboundCounters, onSlow func()) *outboundQueue {
+	if counters == nil {
+		counters = &outboundCounters{}
+	}
+	return &outboundQueue{
+		pending:     make(map[string]*outboundItem),
+		throttled:   make(map[string]*outboundItem),
+		lastSent:    make(map[string]time.Time),
+		capacity:    capacity,
+		slowTimeout: slowTimeout,
+		counters:    counters,
+		onSlow:      onSlow,
+		notify:      make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+}
+
+// push 消息入队；队列已满时丢弃消息并返回 nil，只有队列已关闭时返回错误
+func (q *outboundQueue) push(message interface{}) error {
+	key, channel := conflationKey(message)
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return fmt.Errorf("连接发送队列已关闭")
+	}
+
+	q.pushed++
+	if key != "" {
+		if item, exists := q.throttled[key]; exists {
+			q.replace(item, message)
+			return nil
+		}
+		if item, exists := q.pending[key]; exists && q.behind() {
+			q.replace(item, message)
+			return nil
+		}
+	} else if channel != "" {
+		// 快照等消息之后的更新不能再合并到它之前的消息中
+		delete(q.pending, channel)
+		if _, exists := q.throttled[channel]; exists {
+			delete(q.throttled, channel)
+			q.conflated++
+			q.counters.conflated.Add(1)
+		}
+	}
+
+	if len(q.items) >= q.capacity {
+		q.dropped++
+		q.counters.dropped.Add(1)
+		if q.overflowSince.IsZero() {
+			q.overflowSince = now
+		} else if q.slowTimeout > 0 && !q.slow && now.Sub(q.overflowSince) >= q.slowTimeout {
+			q.slow = true
+			if q.onSlow != nil {
+				go q.onSlow()
+			}
+		}
+		return nil
+	}
+
+	item := &outboundItem{message: message, key: key, order: q.pushed}
+	q.items = append(q.items, item)
+	if key != "" {
+		q.pending[key] = item
+	}
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next 取出下一条可以发送的消息；没有可发送的消息时返回最近一条限速消息的等待时间（0 表示无）
+func (q *outboundQueue) next(now time.Time) (message interface{}, wait time.Duration, closed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, 0, true
+	}
+
+	for key, item := range q.throttled {
+		if !now.Before(q.lastSent[key].Add(q.minInterval)) {
+			delete(q.throttled, key)
+			q.lastSent[key] = now
+			return item.message, 0, false
+		}
+	}
+
+	for len(q.items) > 0 {
+		item := q.items[0]
+		q.items[0] = nil
+		q.items = q.items[1:]
+		if len(q.items) <= q.capacity/2 {
+			q.overflowSince = time.Time{}
+		}
+
+		if item.key != "" {
+			if q.pending[item.key] == item {
+				delete(q.pending, item.key)
+			}
+			if q.minInterval > 0 && now.Before(q.lastSent[item.key].Add(q.minInterval)) {
+				// 同一频道只暂缓最新的一条
+				throttled, exists := q.throttled[item.key]
+				if !exists || throttled.order < item.order {
+					q.throttled[item.key] = item
+				}
+				if exists {
+					q.conflated++
+					q.counters.conflated.Add(1)
+				}
+				continue
+			}
+			q.lastSent[item.key] = now
+		}
+		return item.message, 0, false
+	}
+
+	for key := range q.throttled {
+		due := q.lastSent[key].Add(q.minInterval).Sub(now)
+		if wait == 0 || due < wait {
+			wait = due
+		}
+	}
+	return nil, wait, false
+}
+
+// behind 客户端是否跟不上推送，调用方需持有 mu
+func (q *outboundQueue) behind() bool {
+	return len(q.items) >= q.capacity/4
+}
+
+// replace 用新消息覆盖待发送的消息，调用方需持有 mu
+func (q *outboundQueue) replace(item *outboundItem, message interface{}) {
+	item.message = message
+	item.order = q.pushed
+	q.conflated++
+	q.counters.conflated.Add(1)
+}
+
+// setMaxRate 设置每个频道每秒最多推送的更新数，0 表示不限制
+func (q *outboundQueue) setMaxRate(rate float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if rate <= 0 {
+		q.minInterval = 0
+	} else {
+		q.minInterval = time.Duration(float64(time.Second) / rate)
+	}
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// recordSent 记录一条消息已写出
+func (q *outboundQueue) recordSent() {
+	q.mu.Lock()
+	q.sent++
+	q.mu.Unlock()
+	q.counters.sent.Add(1)
+}
+
+// len 当前排队中的消息数
+func (q *outboundQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items) + len(q.throttled)
+}
+
+// stats 返回本连接的发送统计
+func (q *outboundQueue) stats() OutboundMetrics {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return OutboundMetrics{
+		Sent:      q.sent,
+		Dropped:   q.dropped,
+		Conflated: q.conflated,
+		Queued:    len(q.items) + len(q.throttled),
+	}
+}
+
+// close 关闭队列并通知写协程退出，未发送的消息被丢弃
+func (q *outboundQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	q.items = nil
+	close(q.done)
+}
+
+// conflationKey 返回可合并消息的频道名，以及消息所属的频道
+// 只有 ticker 和 depth 频道的更新可以合并，它们都表示完整的最新状态
+func conflationKey(message interface{}) (key, channel string) {
+	channelMessage, ok := message.(*ChannelMessage)
+	if !ok {
+		return "", ""
+	}
+	if channelMessage.Type == MessageTypeUpdate &&
+		(strings.HasPrefix(channelMessage.Channel, string(ChannelTicker)+":") ||
+			strings.HasPrefix(channelMessage.Channel, string(ChannelDepth)+":")) {
+		return channelMessage.Channel, channelMessage.Channel
+	}
+	return "", channelMessage.Channel
+}
@@ -1,6 +1,8 @@
 package websocket
 
 import (
+	"bytes"
+	"compress/flate"
 	"context"
 	"fmt"
 	"runtime"
@@ -428,3 +430,94 @@ func TestPerformanceMonitor_StatsRetrieval(t *testing.T) {
 	nonExistentStats := monitor.GetLatencyStats("non_existent")
 	assert.Nil(t, nonExistentStats)
 }
+
+// encodingBenchmarkMessages 编码基准测试使用的典型推送
+func encodingBenchmarkMessages() map[string]*ChannelMessage {
+	now := time.Now().UnixMilli()
+	bids := make([]DepthLevel, 20)
+	asks := make([]DepthLevel, 20)
+	for i := range bids {
+		bids[i] = DepthLevel{50000 - float64(i)*0.5, 1.25 + float64(i)}
+		asks[i] = DepthLevel{50000.5 + float64(i)*0.5, 0.75 + float64(i)}
+	}
+
+	return map[string]*ChannelMessage{
+		"ticker": {Type: MessageTypeUpdate, Channel: "ticker:BTCUSDT", Seq: 123456, Timestamp: now, Data: &TickerPayload{
+			Symbol: "BTCUSDT", LastPrice: 50123.45, BidPrice: 50123.4, AskPrice: 50123.5,
+			High24h: 51000, Low24h: 49000.1, Volume24h: 12345.678, ChangeRate24h: 1.2345, Timestamp: now,
+		}},
+		"kline": {Type: MessageTypeUpdate, Channel: "kline:1m:BTCUSDT", Seq: 123456, Timestamp: now, Data: &KlinePayload{
+			Symbol: "BTCUSDT", Interval: "1m", OpenTime: now, Open: 50100, High: 50150.5, Low: 50090,
+			Close: 50123.45, Volume: 12.345, QuoteVolume: 618765.4, Closed: false,
+		}},
+		"signal": {Type: MessageTypeUpdate, Channel: "signals", Seq: 123456, Timestamp: now, Data: &SignalPayload{
+			Symbol: "BTCUSDT", Type: "volume_spike", Direction: "up", Strength: 0.82, Severity: "high",
+			Composite: true, Reasons: []string{"成交量放大", "价格突破"}, DetectedAt: now,
+		}},
+		"depth": {Type: MessageTypeUpdate, Channel: "depth:BTCUSDT", Seq: 123456, Timestamp: now, Data: &DepthPayload{
+			Symbol: "BTCUSDT", Bids: bids, Asks: asks, Timestamp: now,
+		}},
+	}
+}
+
+// TestEncodingPayloadSize 比较各编码的消息大小，Protobuf 应小于 JSON 和 MessagePack
+// MessagePack 的浮点数固定占 9 字节，盘口这类以短小数为主的消息可能比 JSON 更大
+func TestEncodingPayloadSize(t *testing.T) {
+	for name, message := range encodingBenchmarkMessages() {
+		sizes := make(map[Encoding]int)
+		for _, encoding := range []Encoding{EncodingJSON, EncodingMsgpack, EncodingProtobuf} {
+			_, data, err := NewMessageEncoder(encoding).Encode(message)
+			require.NoError(t, err)
+			sizes[encoding] = len(data)
+		}
+		t.Logf("%s: json=%d msgpack=%d protobuf=%d", name, sizes[EncodingJSON], sizes[EncodingMsgpack], sizes[EncodingProtobuf])
+
+		assert.Less(t, sizes[EncodingProtobuf], sizes[EncodingJSON], name)
+		assert.Less(t, sizes[EncodingProtobuf], sizes[EncodingMsgpack], name)
+	}
+}
+
+// BenchmarkEncoding 比较各编码的 CPU 开销和消息大小（bytes/msg 为压缩前大小）
+func BenchmarkEncoding(b *testing.B) {
+	for name, message := range encodingBenchmarkMessages() {
+		for _, encoding := range []Encoding{EncodingJSON, EncodingMsgpack, EncodingProtobuf} {
+			encoder := NewMessageEncoder(encoding)
+			b.Run(fmt.Sprintf("%s/%s", name, encoding), func(b *testing.B) {
+				b.ReportAllocs()
+				var size int
+				for i := 0; i < b.N; i++ {
+					_, data, err := encoder.Encode(message)
+					if err != nil {
+						b.Fatal(err)
+					}
+					size = len(data)
+				}
+				b.ReportMetric(float64(size), "bytes/msg")
+			})
+		}
+	}
+}
+
+// BenchmarkEncodingCompressed 比较各编码在 permessage-deflate 压缩后的大小和 CPU 开销
+func BenchmarkEncodingCompressed(b *testing.B) {
+	for name, message := range encodingBenchmarkMessages() {
+		for _, encoding := range []Encoding{EncodingJSON, EncodingMsgpack, EncodingProtobuf} {
+			_, data, err := NewMessageEncoder(encoding).Encode(message)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(fmt.Sprintf("%s/%s", name, encoding), func(b *testing.B) {
+				b.ReportAllocs()
+				var buf bytes.Buffer
+				writer, _ := flate.NewWriter(&buf, flate.BestSpeed)
+				for i := 0; i < b.N; i++ {
+					buf.Reset()
+					writer.Reset(&buf)
+					writer.Write(data)
+					writer.Flush()
+				}
+				b.ReportMetric(float64(buf.Len()), "bytes/msg")
+			})
+		}
+	}
+}
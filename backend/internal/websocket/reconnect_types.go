@@ -68,6 +68,7 @@ type ConnectionState struct {
 	ConnID        string                 `json:"conn_id"`
 	Subscriptions []string               `json:"subscriptions"`
 	LastMessageID string                 `json:"last_message_id"`
+	Sequences     map[string]uint64      `json:"sequences,omitempty"` // 各频道最后收到的序列号，用于 resume
 	LastActivity  time.Time              `json:"last_activity"`
 	CustomData    map[string]interface{} `json:"custom_data"`
 	CreatedAt     time.Time              `json:"created_at"`
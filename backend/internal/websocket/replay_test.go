@@ -0,0 +1,214 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+)
+
+// TestReplayBuffer 测试重放缓冲区
+func TestReplayBuffer(t *testing.T) {
+	buffer := newReplayBuffer(3)
+	for seq := uint64(1); seq <= 5; seq++ {
+		buffer.append(&ChannelMessage{Seq: seq})
+	}
+
+	seqs := func(messages []*ChannelMessage) []uint64 {
+		result := make([]uint64, len(messages))
+		for i, message := range messages {
+			result[i] = message.Seq
+		}
+		return result
+	}
+
+	missed, ok := buffer.since(2, 5)
+	require.True(t, ok)
+	assert.Equal(t, []uint64{3, 4, 5}, seqs(missed))
+
+	missed, ok = buffer.since(4, 5)
+	require.True(t, ok)
+	assert.Equal(t, []uint64{5}, seqs(missed))
+
+	missed, ok = buffer.since(5, 5)
+	assert.True(t, ok)
+	assert.Empty(t, missed)
+
+	_, ok = buffer.since(1, 5)
+	assert.False(t, ok, "消息2已被覆盖")
+	_, ok = buffer.since(9, 5)
+	assert.False(t, ok, "客户端序列号超前")
+
+	_, ok = newReplayBuffer(0).since(0, 1)
+	assert.False(t, ok)
+}
+
+// TestNewResumeMessage 测试根据连接状态构建恢复请求
+func TestNewResumeMessage(t *testing.T) {
+	state := &ConnectionState{
+		Subscriptions: []string{"ticker:ETHUSDT", "signals"},
+		Sequences:     map[string]uint64{"ticker:ETHUSDT": 7, "depth:BTCUSDT": 3},
+	}
+
+	msg := NewResumeMessage("req-1", state)
+	assert.Equal(t, MessageTypeResume, msg.Type)
+	assert.Equal(t, []string{"signals", "ticker:ETHUSDT"}, msg.Channels)
+	assert.Equal(t, map[string]uint64{"ticker:ETHUSDT": 7, "signals": 0}, msg.Sequences)
+}
+
+// setupRedisClient 创建连接到 miniredis 的客户端
+func setupRedisClient(t *testing.T) (*miniredis.Miniredis, *cache.Client) {
+	mr := miniredis.RunT(t)
+	port, err := strconv.Atoi(mr.Port())
+	require.NoError(t, err)
+
+	redisConfig := cache.DefaultConfig()
+	redisConfig.Host = mr.Host()
+	redisConfig.Port = port
+	redisConfig.MinIdleConns = 0
+	client, err := cache.NewClient(redisConfig, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return mr, client
+}
+
+// setupPriceCache 创建 miniredis 支持的价格缓存
+func setupPriceCache(t *testing.T) cache.PriceCache {
+	_, client := setupRedisClient(t)
+	return cache.NewPriceCache(client)
+}
+
+// channelTestMessage 测试中读取的频道消息
+type channelTestMessage struct {
+	Type     string          `json:"type"`
+	Channel  string          `json:"channel"`
+	Seq      uint64          `json:"seq"`
+	Data     json.RawMessage `json:"data"`
+	Channels []string        `json:"channels"`
+}
+
+func readChannelMessage(t *testing.T, conn *websocket.Conn) *channelTestMessage {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var message channelTestMessage
+	require.NoError(t, conn.ReadJSON(&message))
+	return &message
+}
+
+// TestWebSocketServer_SnapshotAndResume 测试订阅快照与断线恢复
+func TestWebSocketServer_SnapshotAndResume(t *testing.T) {
+	priceCache := setupPriceCache(t)
+	bid := decimal.NewFromInt(49999)
+	require.NoError(t, priceCache.SetPrice(context.Background(), &cache.PriceData{
+		Symbol: "BTCUSDT", LastPrice: decimal.NewFromInt(50000), BidPrice: &bid, Timestamp: time.Now(),
+	}))
+
+	config := DefaultServerConfig()
+	config.PongWait = 5 * time.Second
+	config.MaxMessageSize = 4096
+	config.ReplayBufferSize = 3
+
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	server.SetSnapshotProvider(NewPriceCacheSnapshotProvider(priceCache))
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		return conn
+	}
+	publish := func(price float64) {
+		require.NoError(t, server.Publish("ticker:BTCUSDT", &TickerPayload{Symbol: "BTCUSDT", LastPrice: price}))
+	}
+
+	// 订阅后先收到确认，再收到来自 Redis 的快照
+	conn := dial()
+	defer conn.Close()
+	require.NoError(t, conn.WriteJSON(Message{Type: MessageTypeSubscribe, Channels: []string{"ticker:BTCUSDT", "signals"}}))
+
+	ack := readChannelMessage(t, conn)
+	assert.Equal(t, MessageTypeSubscribed, ack.Type)
+
+	snapshot := readChannelMessage(t, conn)
+	assert.Equal(t, MessageTypeSnapshot, snapshot.Type)
+	assert.Equal(t, "ticker:BTCUSDT", snapshot.Channel)
+	assert.Equal(t, uint64(0), snapshot.Seq)
+	var ticker TickerPayload
+	require.NoError(t, json.Unmarshal(snapshot.Data, &ticker))
+	assert.Equal(t, 50000.0, ticker.LastPrice)
+	assert.Equal(t, 49999.0, ticker.BidPrice)
+
+	// 实时推送带有递增序列号
+	publish(50001)
+	publish(50002)
+	assert.Equal(t, uint64(1), readChannelMessage(t, conn).Seq)
+	assert.Equal(t, uint64(2), readChannelMessage(t, conn).Seq)
+	conn.Close()
+
+	// 断线期间的推送
+	publish(50003)
+	publish(50004)
+
+	t.Run("从缓冲区补发遗漏消息", func(t *testing.T) {
+		conn := dial()
+		defer conn.Close()
+		require.NoError(t, conn.WriteJSON(Message{
+			Type:      MessageTypeResume,
+			ID:        "resume-1",
+			Sequences: map[string]uint64{"ticker:BTCUSDT": 2},
+		}))
+
+		ack := readChannelMessage(t, conn)
+		assert.Equal(t, MessageTypeResumed, ack.Type)
+		assert.Equal(t, []string{"ticker:BTCUSDT"}, ack.Channels)
+
+		for _, expected := range []uint64{3, 4} {
+			message := readChannelMessage(t, conn)
+			assert.Equal(t, MessageTypeUpdate, message.Type)
+			assert.Equal(t, expected, message.Seq)
+		}
+
+		publish(50005)
+		assert.Equal(t, uint64(5), readChannelMessage(t, conn).Seq)
+	})
+
+	t.Run("缓冲区不足时下发快照", func(t *testing.T) {
+		conn := dial()
+		defer conn.Close()
+		require.NoError(t, conn.WriteJSON(Message{
+			Type:      MessageTypeResume,
+			Sequences: map[string]uint64{"ticker:BTCUSDT": 1, "signals": 4},
+		}))
+
+		ack := readChannelMessage(t, conn)
+		assert.Equal(t, []string{"signals", "ticker:BTCUSDT"}, ack.Channels)
+
+		// signals 频道从未推送过，序列号超前，下发空快照
+		message := readChannelMessage(t, conn)
+		assert.Equal(t, MessageTypeSnapshot, message.Type)
+		assert.Equal(t, "signals", message.Channel)
+		assert.Equal(t, "null", string(message.Data))
+
+		message = readChannelMessage(t, conn)
+		assert.Equal(t, MessageTypeSnapshot, message.Type)
+		assert.Equal(t, "ticker:BTCUSDT", message.Channel)
+		assert.Equal(t, uint64(5), message.Seq)
+		require.NoError(t, json.Unmarshal(message.Data, &ticker))
+		assert.Equal(t, 50000.0, ticker.LastPrice)
+	})
+}
@@ -0,0 +1,91 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+)
+
+// replayBuffer 频道消息的有界环形缓冲区，按序列号保存最近的推送
+type replayBuffer struct {
+	messages []*ChannelMessage
+	start    int // 最旧消息的位置
+	size     int
+}
+
+// newReplayBuffer 创建容量为 capacity 的重放缓冲区
+func newReplayBuffer(capacity int) *replayBuffer {
+	return &replayBuffer{
+		messages: make([]*ChannelMessage, capacity),
+	}
+}
+
+// append 追加消息，缓冲区已满时覆盖最旧的消息
+func (b *replayBuffer) append(message *ChannelMessage) {
+	if len(b.messages) == 0 {
+		return
+	}
+	if b.size < len(b.messages) {
+		b.messages[(b.start+b.size)%len(b.messages)] = message
+		b.size++
+		return
+	}
+	b.messages[b.start] = message
+	b.start = (b.start + 1) % len(b.messages)
+}
+
+// since 返回序列号大于 lastSeq 的消息；缓冲区已不包含 lastSeq 之后的全部消息时返回 false
+func (b *replayBuffer) since(lastSeq, currentSeq uint64) ([]*ChannelMessage, bool) {
+	if lastSeq > currentSeq {
+		// 客户端序列号超前（例如服务端重启），无法重放
+		return nil, false
+	}
+	if lastSeq == currentSeq {
+		return nil, true
+	}
+	if b.size == 0 {
+		return nil, false
+	}
+
+	oldest := b.messages[b.start].Seq
+	if lastSeq+1 < oldest {
+		return nil, false
+	}
+
+	missed := make([]*ChannelMessage, 0, currentSeq-lastSeq)
+	for i := 0; i < b.size; i++ {
+		message := b.messages[(b.start+i)%len(b.messages)]
+		if message.Seq > lastSeq {
+			missed = append(missed, message)
+		}
+	}
+	return missed, true
+}
+
+// channelState 单个频道的序列号和重放缓冲区
+// mu 在分配序列号到发送完成期间持有，保证同一频道的消息按序列号顺序送达
+type channelState struct {
+	mu     sync.Mutex
+	seq    uint64
+	replay *replayBuffer
+}
+
+// NewResumeMessage 根据保存的连接状态构建 resume 请求
+func NewResumeMessage(requestID string, state *ConnectionState) *Message {
+	sequences := make(map[string]uint64, len(state.Subscriptions))
+	for _, channel := range state.Subscriptions {
+		sequences[channel] = state.Sequences[channel]
+	}
+
+	channels := make([]string, 0, len(sequences))
+	for channel := range sequences {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	return &Message{
+		Type:      MessageTypeResume,
+		ID:        requestID,
+		Channels:  channels,
+		Sequences: sequences,
+	}
+}
@@ -2,12 +2,16 @@ package websocket
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
 	"go.uber.org/zap"
 )
 
@@ -18,11 +22,28 @@ type WebSocketServerImpl struct {
 	server        *http.Server
 	upgrader      websocket.Upgrader
 	connections   map[string]*Connection
-	subscriptions map[string][]string // symbol -> connection IDs
+	subscriptions map[string][]string // symbol/channel -> connection IDs
+	userConns     map[string][]string // user ID -> connection IDs
 	mu            sync.RWMutex
 	running       bool
 	startTime     time.Time
 	lastActivity  time.Time
+
+	// 频道序列号和重放缓冲区
+	channelStates   map[string]*channelState
+	channelStatesMu sync.Mutex
+
+	snapshotProvider SnapshotProvider
+	backplane        Backplane                   // 为空时仅在本实例内分发
+	authManager      data_collection.AuthManager // 为空时不要求认证
+
+	// 过滤订阅，filtersMu 同时保护各交易对的最新指标
+	filters      map[string][]*connectionFilter // connection ID -> filters
+	metrics      map[string]*MarketMetrics      // symbol -> 最新指标
+	configSource MonitoringConfigSource
+	filtersMu    sync.Mutex
+
+	outboundCounters outboundCounters
 }
 
 // NewWebSocketServer 创建WebSocket服务器
@@ -34,19 +55,45 @@ func NewWebSocketServer(config *ServerConfig, logger *zap.Logger) WebSocketServe
 		logger = zap.NewNop()
 	}
 
-	return &WebSocketServerImpl{
+	server := &WebSocketServerImpl{
 		config:        config,
 		logger:        logger,
 		connections:   make(map[string]*Connection),
 		subscriptions: make(map[string][]string),
+		userConns:     make(map[string][]string),
+		filters:       make(map[string][]*connectionFilter),
+		metrics:       make(map[string]*MarketMetrics),
+		channelStates: make(map[string]*channelState),
 		upgrader: websocket.Upgrader{
-			ReadBufferSize:  config.ReadBufferSize,
-			WriteBufferSize: config.WriteBufferSize,
-			CheckOrigin: func(r *http.Request) bool {
-				return true // 允许所有来源，生产环境需要限制
-			},
+			ReadBufferSize:    config.ReadBufferSize,
+			WriteBufferSize:   config.WriteBufferSize,
+			EnableCompression: config.EnableCompression,
 		},
 	}
+	server.upgrader.CheckOrigin = server.checkOrigin
+	return server
+}
+
+// checkOrigin 校验握手请求的来源：不带 Origin、同源或在 AllowedOrigins 中的请求允许升级
+func (s *WebSocketServerImpl) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range s.config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	s.logger.Warn("拒绝来源不允许的WebSocket连接",
+		zap.String("origin", origin),
+		zap.String("remote_addr", r.RemoteAddr),
+	)
+	return false
 }
 
 // Start 启动WebSocket服务器
@@ -69,6 +116,13 @@ func (s *WebSocketServerImpl) Start(ctx context.Context) error {
 		WriteTimeout: s.config.WriteWait,
 	}
 
+	// 先订阅背板，避免启动后遗漏其他实例的消息
+	if s.backplane != nil {
+		if err := s.backplane.Start(ctx, s.handleBackplaneMessage); err != nil {
+			return fmt.Errorf("启动背板失败: %w", err)
+		}
+	}
+
 	// 启动服务器
 	go func() {
 		s.logger.Info("启动WebSocket服务器", zap.String("addr", s.server.Addr))
@@ -84,6 +138,11 @@ func (s *WebSocketServerImpl) Start(ctx context.Context) error {
 	// 启动心跳检测
 	go s.startHeartbeat(ctx)
 
+	// 启动在线状态上报
+	if s.backplane != nil {
+		go s.startPresence(ctx)
+	}
+
 	return nil
 }
 
@@ -98,7 +157,7 @@ func (s *WebSocketServerImpl) Stop(ctx context.Context) error {
 
 	// 关闭所有连接
 	for _, conn := range s.connections {
-		conn.Conn.Close()
+		s.abortConnection(conn)
 	}
 
 	// 停止HTTP服务器
@@ -106,6 +165,15 @@ func (s *WebSocketServerImpl) Stop(ctx context.Context) error {
 		s.server.Shutdown(ctx)
 	}
 
+	if s.backplane != nil {
+		if err := s.backplane.RemovePresence(ctx); err != nil {
+			s.logger.Warn("删除在线状态失败", zap.Error(err))
+		}
+		if err := s.backplane.Stop(ctx); err != nil {
+			s.logger.Warn("停止背板失败", zap.Error(err))
+		}
+	}
+
 	s.running = false
 	s.logger.Info("WebSocket服务器已停止")
 
@@ -141,18 +209,40 @@ func (s *WebSocketServerImpl) GetConnections() []*Connection {
 // handleWebSocket 处理WebSocket连接
 func (s *WebSocketServerImpl) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	// 检查连接数限制
-	if len(s.connections) >= s.config.MaxConnections {
+	if s.GetConnectionCount() >= s.config.MaxConnections {
 		http.Error(w, "连接数已达上限", http.StatusServiceUnavailable)
 		return
 	}
 
+	// 协商推送编码
+	encoding, err := ParseEncoding(r.URL.Query().Get("encoding"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 校验 Token，结果在升级后处理
+	claims, responseHeader, authErr := s.authenticate(r)
+
 	// 升级到WebSocket连接
-	conn, err := s.upgrader.Upgrade(w, r, nil)
+	conn, err := s.upgrader.Upgrade(w, r, responseHeader)
 	if err != nil {
 		s.logger.Error("WebSocket升级失败", zap.Error(err))
 		return
 	}
 
+	// 浏览器拿不到握手失败时的 HTTP 状态码，认证失败通过关闭码告知客户端
+	if authErr != nil {
+		s.logger.Warn("WebSocket认证失败", zap.String("remote_addr", r.RemoteAddr), zap.Error(authErr))
+		s.closeWithCode(conn, CloseCodeUnauthorized, "unauthorized")
+		return
+	}
+	if s.config.EnableCompression && s.config.CompressionLevel != 0 {
+		if err := conn.SetCompressionLevel(s.config.CompressionLevel); err != nil {
+			s.logger.Warn("设置压缩级别失败", zap.Int("level", s.config.CompressionLevel), zap.Error(err))
+		}
+	}
+
 	// 创建连接对象
 	connectionID := generateConnectionID()
 	connection := &Connection{
@@ -162,10 +252,26 @@ func (s *WebSocketServerImpl) handleWebSocket(w http.ResponseWriter, r *http.Req
 		LastPing:      time.Now(),
 		CreatedAt:     time.Now(),
 		IsActive:      true,
+		Encoding:      encoding,
+		encoder:       NewMessageEncoder(encoding),
+	}
+	if claims != nil {
+		connection.UserID = claims.UserID
+		connection.ExpiresAt = claims.ExpiresAt
+	}
+	if s.attachOutbound(connection) {
+		go s.writeLoop(connection)
 	}
 
 	// 添加连接到管理器
-	s.addConnection(connection)
+	if err := s.addConnection(connection); err != nil {
+		if connection.outbound != nil {
+			connection.outbound.close()
+		}
+		s.logger.Warn("拒绝WebSocket连接", zap.String("user_id", connection.UserID), zap.Error(err))
+		s.closeWithCode(conn, CloseCodeConnectionLimit, "too many connections")
+		return
+	}
 
 	// 设置连接参数
 	conn.SetReadLimit(int64(s.config.MaxMessageSize))
@@ -176,23 +282,67 @@ func (s *WebSocketServerImpl) handleWebSocket(w http.ResponseWriter, r *http.Req
 		return nil
 	})
 
-	s.logger.Info("新WebSocket连接建立", zap.String("conn_id", connectionID))
+	expiry := s.watchExpiry(connection)
+
+	s.saveSession(connection)
+	s.logger.Info("新WebSocket连接建立",
+		zap.String("conn_id", connectionID),
+		zap.String("user_id", connection.UserID),
+		zap.String("encoding", string(encoding)),
+	)
 
 	// 处理消息循环
 	s.handleMessages(connection)
 
 	// 清理连接
-	s.removeConnection(connectionID)
+	s.releaseConnection(connection, expiry)
 	s.logger.Info("WebSocket连接关闭", zap.String("conn_id", connectionID))
 }
 
+// attachOutbound 按配置为连接创建发送队列，返回是否需要启动写协程
+func (s *WebSocketServerImpl) attachOutbound(conn *Connection) bool {
+	if s.config.MessageQueueSize <= 0 {
+		return false
+	}
+	conn.outbound = newOutboundQueue(
+		s.config.MessageQueueSize,
+		s.config.SlowConsumerTimeout,
+		&s.outboundCounters,
+		func() { s.disconnectSlowConsumer(conn) },
+	)
+	return true
+}
+
+// watchExpiry Token 到期时主动关闭连接，未认证的连接返回 nil
+func (s *WebSocketServerImpl) watchExpiry(conn *Connection) *time.Timer {
+	if conn.ExpiresAt.IsZero() {
+		return nil
+	}
+	return time.AfterFunc(time.Until(conn.ExpiresAt), func() {
+		s.logger.Info("Token已过期，关闭连接", zap.String("conn_id", conn.ID), zap.String("user_id", conn.UserID))
+		s.closeConnection(conn, CloseCodeTokenExpired, "token expired")
+	})
+}
+
+// releaseConnection 连接结束后停止定时器、关闭发送队列并清理订阅和会话
+func (s *WebSocketServerImpl) releaseConnection(conn *Connection, expiry *time.Timer) {
+	if expiry != nil {
+		expiry.Stop()
+	}
+	if conn.outbound != nil {
+		conn.outbound.close()
+	}
+	s.removeConnection(conn.ID)
+	s.removeFilters(conn.ID)
+	s.removeSession(conn.ID)
+}
+
 // handleMessages 处理消息循环
 func (s *WebSocketServerImpl) handleMessages(conn *Connection) {
 	defer conn.Conn.Close()
 
 	for {
-		var msg Message
-		err := conn.Conn.ReadJSON(&msg)
+		frameType, data, err := conn.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				s.logger.Error("WebSocket读取错误", zap.String("conn_id", conn.ID), zap.Error(err))
@@ -200,6 +350,12 @@ func (s *WebSocketServerImpl) handleMessages(conn *Connection) {
 			break
 		}
 
+		var msg Message
+		if err := s.decodeMessage(conn, frameType, data, &msg); err != nil {
+			s.sendError(conn, ErrCodeInvalidMessage, err.Error())
+			continue
+		}
+
 		s.lastActivity = time.Now()
 		s.processMessage(conn, &msg)
 	}
@@ -208,14 +364,34 @@ func (s *WebSocketServerImpl) handleMessages(conn *Connection) {
 // processMessage 处理消息
 func (s *WebSocketServerImpl) processMessage(conn *Connection, msg *Message) {
 	switch msg.Type {
-	case "subscribe":
-		s.handleSubscribe(conn, msg.Symbols)
-	case "unsubscribe":
-		s.handleUnsubscribe(conn, msg.Symbols)
-	case "ping":
+	case MessageTypeSubscribe:
+		if !s.applyMaxRate(conn, msg.MaxRate) {
+			return
+		}
+		if len(msg.Channels) > 0 {
+			s.handleChannelSubscribe(conn, msg.ID, msg.Channels)
+		} else {
+			s.handleSubscribe(conn, msg.Symbols)
+		}
+	case MessageTypeUnsubscribe:
+		if len(msg.Channels) > 0 {
+			s.handleChannelUnsubscribe(conn, msg.ID, msg.Channels)
+		} else {
+			s.handleUnsubscribe(conn, msg.Symbols)
+		}
+	case MessageTypeResume:
+		if !s.applyMaxRate(conn, msg.MaxRate) {
+			return
+		}
+		s.handleResume(conn, msg)
+	case MessageTypeSubscribeFilter:
+		s.handleFilterSubscribe(conn, msg)
+	case MessageTypeUnsubscribeFilter:
+		s.handleFilterUnsubscribe(conn, msg)
+	case MessageTypePing:
 		s.handlePing(conn)
 	default:
-		s.sendError(conn, "INVALID_MESSAGE_TYPE", "无效的消息类型")
+		s.sendError(conn, ErrCodeInvalidMessageType, "无效的消息类型")
 	}
 }
 
@@ -224,6 +400,18 @@ func (s *WebSocketServerImpl) handleSubscribe(conn *Connection, symbols []string
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// 检查用户的交易对订阅上限
+	if limit := s.config.MaxSymbolsPerUser; conn.UserID != "" && limit > 0 {
+		userSymbols := s.userSymbolsLocked(conn.UserID)
+		for _, symbol := range symbols {
+			userSymbols[symbol] = true
+		}
+		if len(userSymbols) > limit {
+			s.sendError(conn, ErrCodeSymbolLimit, fmt.Sprintf("每个用户最多订阅 %d 个交易对", limit))
+			return
+		}
+	}
+
 	// 添加订阅
 	for _, symbol := range symbols {
 		if !contains(conn.Subscriptions, symbol) {
@@ -277,7 +465,22 @@ func (s *WebSocketServerImpl) handlePing(conn *Connection) {
 }
 
 // BroadcastToSymbol 向特定交易对的所有订阅者广播消息
+// 配置了背板时经由背板发布，由每个实例推送给本地订阅者
 func (s *WebSocketServerImpl) BroadcastToSymbol(symbol string, message interface{}) error {
+	if s.backplane != nil {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("序列化广播消息失败: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteWait)
+		defer cancel()
+		return s.backplane.PublishSymbol(ctx, symbol, data)
+	}
+	return s.broadcastToSymbolLocal(symbol, message)
+}
+
+// broadcastToSymbolLocal 向本实例内特定交易对的订阅者广播消息
+func (s *WebSocketServerImpl) broadcastToSymbolLocal(symbol string, message interface{}) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -295,8 +498,22 @@ func (s *WebSocketServerImpl) BroadcastToSymbol(symbol string, message interface
 	return nil
 }
 
-// BroadcastToAll 向所有连接广播消息
+// BroadcastToAll 向所有连接广播消息，配置了背板时覆盖所有实例的连接
 func (s *WebSocketServerImpl) BroadcastToAll(message interface{}) error {
+	if s.backplane != nil {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("序列化广播消息失败: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteWait)
+		defer cancel()
+		return s.backplane.PublishAll(ctx, data)
+	}
+	return s.broadcastToAllLocal(message)
+}
+
+// broadcastToAllLocal 向本实例的所有连接广播消息
+func (s *WebSocketServerImpl) broadcastToAllLocal(message interface{}) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -397,12 +614,30 @@ func (s *WebSocketServerImpl) GetSubscribers(symbol string) []string {
 	return result
 }
 
+// IsConnectionActive 检查连接是否活跃
+func (s *WebSocketServerImpl) IsConnectionActive(connID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conn, exists := s.connections[connID]
+	return exists && conn.IsActive
+}
+
 // 辅助方法
 
-func (s *WebSocketServerImpl) addConnection(conn *Connection) {
+// addConnection 登记连接，超过单个用户的连接数上限时返回错误
+func (s *WebSocketServerImpl) addConnection(conn *Connection) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+
+	if conn.UserID != "" {
+		if limit := s.config.MaxConnectionsPerUser; limit > 0 && len(s.userConns[conn.UserID]) >= limit {
+			return fmt.Errorf("用户 %s 的连接数已达上限 %d", conn.UserID, limit)
+		}
+		s.userConns[conn.UserID] = append(s.userConns[conn.UserID], conn.ID)
+	}
 	s.connections[conn.ID] = conn
+	return nil
 }
 
 func (s *WebSocketServerImpl) removeConnection(connID string) {
@@ -414,13 +649,81 @@ func (s *WebSocketServerImpl) removeConnection(connID string) {
 		for _, symbol := range conn.Subscriptions {
 			s.subscriptions[symbol] = removeFromSlice(s.subscriptions[symbol], connID)
 		}
+		if conn.UserID != "" {
+			s.userConns[conn.UserID] = removeFromSlice(s.userConns[conn.UserID], connID)
+			if len(s.userConns[conn.UserID]) == 0 {
+				delete(s.userConns, conn.UserID)
+			}
+		}
 		delete(s.connections, connID)
 	}
 }
 
+// closeConnection 以指定关闭码关闭连接，SSE 连接改为发送 close 事件后结束响应
+func (s *WebSocketServerImpl) closeConnection(conn *Connection, code int, reason string) {
+	if conn.sse != nil {
+		conn.sse.close(code, reason)
+		return
+	}
+	s.closeWithCode(conn.Conn, code, reason)
+}
+
+// abortConnection 立即断开连接，不告知原因
+func (s *WebSocketServerImpl) abortConnection(conn *Connection) {
+	if conn.sse != nil {
+		conn.sse.cancel()
+		return
+	}
+	conn.Conn.Close()
+}
+
+// closeWithCode 以指定关闭码关闭连接，WriteControl 可以与正在阻塞的写操作并发调用
+func (s *WebSocketServerImpl) closeWithCode(conn *websocket.Conn, code int, reason string) {
+	closeMessage := websocket.FormatCloseMessage(code, reason)
+	conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(s.config.WriteWait))
+	conn.Close()
+}
+
+// sendMessage 发送消息，连接有发送队列时只入队，由写协程异步写出
 func (s *WebSocketServerImpl) sendMessage(conn *Connection, message interface{}) error {
+	if conn.outbound != nil {
+		return conn.outbound.push(message)
+	}
+	return s.writeMessage(conn, message)
+}
+
+// writeMessage 按连接协商的编码同步写出消息
+func (s *WebSocketServerImpl) writeMessage(conn *Connection, message interface{}) error {
+	if conn.sse != nil {
+		return conn.sse.writeMessage(message, s.config.WriteWait)
+	}
+
+	encoder := conn.encoder
+	if encoder == nil {
+		encoder = jsonEncoder{}
+	}
+	frameType, data, err := encoder.Encode(message)
+	if err != nil {
+		return err
+	}
+
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+
+	conn.Conn.EnableWriteCompression(len(data) >= s.config.CompressionThreshold)
 	conn.Conn.SetWriteDeadline(time.Now().Add(s.config.WriteWait))
-	return conn.Conn.WriteJSON(message)
+	return conn.Conn.WriteMessage(frameType, data)
+}
+
+// decodeMessage 解析客户端消息，文本帧按 JSON 解析，二进制帧按协商的编码解析
+func (s *WebSocketServerImpl) decodeMessage(conn *Connection, frameType int, data []byte, msg *Message) error {
+	if frameType == websocket.BinaryMessage && conn.encoder != nil {
+		return conn.encoder.Decode(data, msg)
+	}
+	if err := json.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("消息格式错误: %w", err)
+	}
+	return nil
 }
 
 func (s *WebSocketServerImpl) sendError(conn *Connection, code, message string) {
@@ -454,8 +757,9 @@ func (s *WebSocketServerImpl) sendPingToAll() {
 		Timestamp: time.Now().UnixMilli(),
 	}
 
+	// SSE 连接使用注释行作为心跳
 	for _, conn := range s.connections {
-		if conn.IsActive {
+		if conn.IsActive && conn.sse == nil {
 			s.sendMessage(conn, pingMsg)
 		}
 	}
@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
+)
+
+// tokenProtocol 通过子协议传递 Token 时使用的协议名
+// 客户端发送 Sec-WebSocket-Protocol: bearer, <token>，服务端只回应 bearer
+const tokenProtocol = "bearer"
+
+// SetAuthManager 设置握手认证使用的认证管理器，需在 Start 之前调用
+func (s *WebSocketServerImpl) SetAuthManager(authManager data_collection.AuthManager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authManager = authManager
+}
+
+// authenticate 校验握手请求携带的 Token，未设置认证管理器时不要求认证
+// 返回的响应头需要在升级时写回：客户端提供了子协议时必须回应其中一个，否则浏览器会断开连接
+func (s *WebSocketServerImpl) authenticate(r *http.Request) (*data_collection.TokenClaims, http.Header, error) {
+	token, protocol := extractToken(r)
+
+	var responseHeader http.Header
+	if protocol != "" {
+		responseHeader = http.Header{"Sec-Websocket-Protocol": {protocol}}
+	}
+
+	s.mu.RLock()
+	authManager := s.authManager
+	s.mu.RUnlock()
+
+	if authManager == nil {
+		return nil, responseHeader, nil
+	}
+
+	// 认证未启用时 ValidateToken 对任意 Token 返回匿名用户
+	claims, err := authManager.ValidateToken(token)
+	if err != nil {
+		if token == "" {
+			return nil, responseHeader, fmt.Errorf("未提供Token")
+		}
+		return nil, responseHeader, fmt.Errorf("Token验证失败: %w", err)
+	}
+
+	if s.config.RequiredPermission != "" {
+		hasPermission, err := authManager.CheckPermission(token, s.config.RequiredPermission)
+		if err != nil {
+			return nil, responseHeader, fmt.Errorf("权限检查失败: %w", err)
+		}
+		if !hasPermission {
+			return nil, responseHeader, fmt.Errorf("用户 %s 缺少 %s 权限", claims.UserID, s.config.RequiredPermission)
+		}
+	}
+
+	return claims, responseHeader, nil
+}
+
+// extractToken 从查询参数 token、Authorization: Bearer 头或 Sec-WebSocket-Protocol 中取出 Token
+// 返回值 protocol 非空表示客户端提供了 bearer 子协议
+func extractToken(r *http.Request) (token, protocol string) {
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		token = strings.TrimPrefix(authHeader, "Bearer ")
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, p := range protocols {
+		if p != tokenProtocol {
+			continue
+		}
+		protocol = tokenProtocol
+		if i+1 < len(protocols) {
+			token = decodeProtocolToken(protocols[i+1])
+		}
+		break
+	}
+
+	// 查询参数优先，注意 URL 可能被代理记录到访问日志
+	if query := r.URL.Query().Get("token"); query != "" {
+		token = query
+	}
+	return token, protocol
+}
+
+// decodeProtocolToken 子协议只能包含 token 字符，客户端需要把 Token 转为无填充的 base64url 编码
+// 这里还原为认证管理器使用的标准 base64 编码，无法按 base64url 解码时原样返回
+func decodeProtocolToken(value string) string {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return value
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// userSymbolsLocked 返回用户所有连接已订阅的交易对，调用方需持有 s.mu
+func (s *WebSocketServerImpl) userSymbolsLocked(userID string) map[string]bool {
+	symbols := make(map[string]bool)
+	for _, connID := range s.userConns[userID] {
+		conn, exists := s.connections[connID]
+		if !exists {
+			continue
+		}
+		for _, key := range conn.Subscriptions {
+			if symbol := subscriptionSymbol(key); symbol != "" {
+				symbols[symbol] = true
+			}
+		}
+	}
+	return symbols
+}
+
+// subscriptionSymbol 返回订阅对应的交易对：频道取其交易对，旧版订阅本身就是交易对
+func subscriptionSymbol(key string) string {
+	channel, chErr := ParseChannel(key)
+	if chErr != nil {
+		return key
+	}
+	return channel.Symbol
+}
@@ -0,0 +1,154 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+)
+
+// SetBackplane 设置跨实例消息背板，需在 Start 之前调用
+func (s *WebSocketServerImpl) SetBackplane(backplane Backplane) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backplane = backplane
+}
+
+// handleBackplaneMessage 把背板消息推送给本实例的订阅者
+func (s *WebSocketServerImpl) handleBackplaneMessage(msg *BackplaneMessage) {
+	switch msg.Kind {
+	case BackplaneKindChannel:
+		channel, chErr := ParseChannel(msg.Target)
+		if chErr != nil {
+			s.logger.Warn("背板频道无效", zap.String("channel", msg.Target), zap.String("instance", msg.Instance))
+			return
+		}
+
+		key := channel.String()
+		state := s.getChannelState(key)
+		state.mu.Lock()
+		defer state.mu.Unlock()
+
+		s.deliverChannelLocked(state, &ChannelMessage{
+			Type:      MessageTypeUpdate,
+			Channel:   key,
+			Seq:       msg.Seq,
+			Data:      msg.Data,
+			Timestamp: msg.Timestamp,
+		})
+
+	case BackplaneKindSymbol:
+		// 本实例没有该交易对的订阅者时忽略
+		s.broadcastToSymbolLocal(msg.Target, msg.Data)
+
+	case BackplaneKindAll:
+		s.broadcastToAllLocal(msg.Data)
+
+	case BackplaneKindMetrics:
+		var ticker cache.ScannerTicker
+		if err := json.Unmarshal(msg.Data, &ticker); err != nil {
+			s.logger.Warn("背板行情指标无效", zap.String("symbol", msg.Target), zap.Error(err))
+			return
+		}
+		s.evaluateFilters(&ticker)
+
+	default:
+		s.logger.Warn("未知的背板消息类型", zap.String("kind", string(msg.Kind)), zap.String("instance", msg.Instance))
+	}
+}
+
+// startPresence 定期向背板上报连接数、频道订阅数和连接会话
+func (s *WebSocketServerImpl) startPresence(ctx context.Context) {
+	s.reportPresence()
+
+	ticker := time.NewTicker(s.config.PresenceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.IsRunning() {
+				s.reportPresence()
+			}
+		}
+	}
+}
+
+// reportPresence 上报一次在线状态
+func (s *WebSocketServerImpl) reportPresence() {
+	now := time.Now()
+	instanceID := s.backplane.InstanceID()
+
+	s.mu.RLock()
+	snapshot := &PresenceSnapshot{
+		Connections: len(s.connections),
+		Channels:    make(map[string]int, len(s.subscriptions)),
+	}
+	for key, connIDs := range s.subscriptions {
+		if len(connIDs) > 0 {
+			snapshot.Channels[key] = len(connIDs)
+		}
+	}
+	sessions := make([]*SessionInfo, 0, len(s.connections))
+	for _, conn := range s.connections {
+		sessions = append(sessions, &SessionInfo{
+			ConnID:        conn.ID,
+			Instance:      instanceID,
+			UserID:        conn.UserID,
+			Subscriptions: append([]string{}, conn.Subscriptions...),
+			CreatedAt:     conn.CreatedAt,
+			UpdatedAt:     now,
+		})
+	}
+	s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteWait)
+	defer cancel()
+
+	if err := s.backplane.UpdatePresence(ctx, snapshot); err != nil {
+		s.logger.Warn("上报在线状态失败", zap.Error(err))
+	}
+	if err := s.backplane.SaveSessions(ctx, sessions); err != nil {
+		s.logger.Warn("保存连接会话失败", zap.Error(err))
+	}
+}
+
+// saveSession 连接建立时登记会话
+func (s *WebSocketServerImpl) saveSession(conn *Connection) {
+	if s.backplane == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteWait)
+	defer cancel()
+
+	session := &SessionInfo{
+		ConnID:    conn.ID,
+		Instance:  s.backplane.InstanceID(),
+		UserID:    conn.UserID,
+		CreatedAt: conn.CreatedAt,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.backplane.SaveSessions(ctx, []*SessionInfo{session}); err != nil {
+		s.logger.Warn("保存连接会话失败", zap.String("conn_id", conn.ID), zap.Error(err))
+	}
+}
+
+// removeSession 连接关闭时删除会话
+func (s *WebSocketServerImpl) removeSession(connID string) {
+	if s.backplane == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteWait)
+	defer cancel()
+
+	if err := s.backplane.RemoveSession(ctx, connID); err != nil {
+		s.logger.Warn("删除连接会话失败", zap.String("conn_id", connID), zap.Error(err))
+	}
+}
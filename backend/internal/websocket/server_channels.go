@@ -0,0 +1,346 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SetSnapshotProvider 设置订阅和恢复时使用的快照提供者
+func (s *WebSocketServerImpl) SetSnapshotProvider(provider SnapshotProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshotProvider = provider
+}
+
+// handleChannelSubscribe 处理频道订阅：逐个频道校验并返回确认，随后为有快照的频道下发快照
+func (s *WebSocketServerImpl) handleChannelSubscribe(conn *Connection, requestID string, channels []string) {
+	accepted, errors := s.reserveChannels(conn, channels)
+	snapshots := s.loadSnapshots(accepted)
+
+	s.sendAck(conn, MessageTypeSubscribed, requestID, accepted, errors)
+
+	for _, channel := range accepted {
+		key := channel.String()
+		s.activateChannel(conn, key, func(state *channelState) {
+			if snapshot, ok := snapshots[key]; ok {
+				s.sendSnapshot(conn, key, state.seq, snapshot)
+			}
+		})
+	}
+
+	s.logger.Info("客户端订阅频道",
+		zap.String("conn_id", conn.ID),
+		zap.Int("channels", len(accepted)),
+		zap.Int("snapshots", len(snapshots)),
+		zap.Int("errors", len(errors)),
+	)
+}
+
+// handleResume 处理断线重连后的恢复请求
+// 对携带序列号的频道优先从重放缓冲区补发遗漏的消息，缓冲区不足时改为下发快照
+func (s *WebSocketServerImpl) handleResume(conn *Connection, msg *Message) {
+	names := append([]string{}, msg.Channels...)
+	extra := make([]string, 0, len(msg.Sequences))
+	for name := range msg.Sequences {
+		if !contains(names, name) {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	names = append(names, extra...)
+
+	sequences := make(map[string]uint64, len(msg.Sequences))
+	for name, seq := range msg.Sequences {
+		if channel, chErr := ParseChannel(name); chErr == nil {
+			sequences[channel.String()] = seq
+		}
+	}
+
+	accepted, errors := s.reserveChannels(conn, names)
+	snapshots := s.loadSnapshots(accepted)
+
+	s.sendAck(conn, MessageTypeResumed, msg.ID, accepted, errors)
+
+	replayed, resynced := 0, 0
+	for _, channel := range accepted {
+		key := channel.String()
+		lastSeq, hasSeq := sequences[key]
+		s.activateChannel(conn, key, func(state *channelState) {
+			if !hasSeq {
+				if snapshot, ok := snapshots[key]; ok {
+					s.sendSnapshot(conn, key, state.seq, snapshot)
+				}
+				return
+			}
+
+			if missed, ok := state.replay.since(lastSeq, state.seq); ok {
+				for _, message := range missed {
+					s.sendMessage(conn, message)
+				}
+				replayed += len(missed)
+				return
+			}
+
+			// 遗漏的消息已不在缓冲区，下发快照（可能为空）让客户端重建状态
+			s.sendSnapshot(conn, key, state.seq, snapshots[key])
+			resynced++
+		})
+	}
+
+	s.logger.Info("客户端恢复订阅",
+		zap.String("conn_id", conn.ID),
+		zap.Int("channels", len(accepted)),
+		zap.Int("replayed", replayed),
+		zap.Int("resynced", resynced),
+		zap.Int("errors", len(errors)),
+	)
+}
+
+// handleChannelUnsubscribe 处理频道取消订阅
+func (s *WebSocketServerImpl) handleChannelUnsubscribe(conn *Connection, requestID string, channels []string) {
+	var accepted []*Channel
+	var errors []*ChannelError
+
+	s.mu.Lock()
+	for _, name := range channels {
+		channel, chErr := ParseChannel(name)
+		if chErr != nil {
+			errors = append(errors, chErr)
+			continue
+		}
+
+		key := channel.String()
+		if !contains(conn.Subscriptions, key) {
+			errors = append(errors, &ChannelError{
+				Channel: name,
+				Code:    ErrCodeNotSubscribed,
+				Message: "未订阅该频道",
+			})
+			continue
+		}
+		conn.Subscriptions = removeFromSlice(conn.Subscriptions, key)
+		s.subscriptions[key] = removeFromSlice(s.subscriptions[key], conn.ID)
+		if len(s.subscriptions[key]) == 0 {
+			delete(s.subscriptions, key)
+		}
+		accepted = append(accepted, channel)
+	}
+	s.mu.Unlock()
+
+	s.sendAck(conn, MessageTypeUnsubscribed, requestID, accepted, errors)
+
+	s.logger.Info("客户端取消订阅频道",
+		zap.String("conn_id", conn.ID),
+		zap.Int("channels", len(accepted)),
+		zap.Int("errors", len(errors)),
+	)
+}
+
+// Publish 向频道的所有订阅者推送数据，频道没有订阅者时只记录到重放缓冲区
+// 配置了背板时由 Redis 分配全局序列号，消息经背板回到各实例后再推送
+func (s *WebSocketServerImpl) Publish(channelName string, data interface{}) error {
+	channel, chErr := ParseChannel(channelName)
+	if chErr != nil {
+		return chErr
+	}
+	if err := validatePayload(channel, data); err != nil {
+		return err
+	}
+
+	key := channel.String()
+	if s.backplane != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("序列化频道数据失败: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteWait)
+		defer cancel()
+		_, err = s.backplane.PublishChannel(ctx, key, raw)
+		return err
+	}
+
+	state := s.getChannelState(key)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	s.deliverChannelLocked(state, &ChannelMessage{
+		Type:      MessageTypeUpdate,
+		Channel:   key,
+		Seq:       state.seq + 1,
+		Data:      data,
+		Timestamp: time.Now().UnixMilli(),
+	})
+	return nil
+}
+
+// deliverChannelLocked 记录消息到重放缓冲区并推送给本实例的订阅者，调用方需持有 state.mu
+// 序列号不大于当前值的消息视为重复直接丢弃；序列号不连续时清空缓冲区，避免重放时出现缺口
+func (s *WebSocketServerImpl) deliverChannelLocked(state *channelState, message *ChannelMessage) {
+	if message.Seq <= state.seq {
+		return
+	}
+	if message.Seq != state.seq+1 {
+		state.replay = newReplayBuffer(s.config.ReplayBufferSize)
+	}
+	state.seq = message.Seq
+	state.replay.append(message)
+
+	s.mu.RLock()
+	targets := make([]*Connection, 0, len(s.subscriptions[message.Channel]))
+	for _, connID := range s.subscriptions[message.Channel] {
+		if conn, exists := s.connections[connID]; exists && conn.IsActive {
+			targets = append(targets, conn)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, conn := range targets {
+		if err := s.sendMessage(conn, message); err != nil {
+			s.logger.Warn("频道消息推送失败",
+				zap.String("conn_id", conn.ID),
+				zap.String("channel", message.Channel),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// reserveChannels 校验频道并记录到连接的订阅列表，此时尚不接收推送
+func (s *WebSocketServerImpl) reserveChannels(conn *Connection, names []string) ([]*Channel, []*ChannelError) {
+	var accepted []*Channel
+	var errors []*ChannelError
+	seen := make(map[string]bool, len(names))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	symbolLimit := 0
+	var userSymbols map[string]bool
+	if conn.UserID != "" && s.config.MaxSymbolsPerUser > 0 {
+		symbolLimit = s.config.MaxSymbolsPerUser
+		userSymbols = s.userSymbolsLocked(conn.UserID)
+	}
+
+	for _, name := range names {
+		channel, chErr := ParseChannel(name)
+		if chErr != nil {
+			errors = append(errors, chErr)
+			continue
+		}
+
+		key := channel.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if !contains(conn.Subscriptions, key) {
+			if limit := s.config.MaxSubscriptionsPerConnection; limit > 0 && len(conn.Subscriptions) >= limit {
+				errors = append(errors, &ChannelError{
+					Channel: name,
+					Code:    ErrCodeSubscriptionLimit,
+					Message: fmt.Sprintf("单个连接最多订阅 %d 个频道", limit),
+				})
+				continue
+			}
+			if symbolLimit > 0 && channel.Symbol != "" && !userSymbols[channel.Symbol] {
+				if len(userSymbols) >= symbolLimit {
+					errors = append(errors, &ChannelError{
+						Channel: name,
+						Code:    ErrCodeSymbolLimit,
+						Message: fmt.Sprintf("每个用户最多订阅 %d 个交易对", symbolLimit),
+					})
+					continue
+				}
+				userSymbols[channel.Symbol] = true
+			}
+			conn.Subscriptions = append(conn.Subscriptions, key)
+		}
+		accepted = append(accepted, channel)
+	}
+
+	return accepted, errors
+}
+
+// activateChannel 在持有频道锁的情况下把连接加入推送列表并执行 fn
+// 这样 fn 中下发的快照或重放消息与之后的实时推送之间不会出现遗漏或乱序
+func (s *WebSocketServerImpl) activateChannel(conn *Connection, key string, fn func(state *channelState)) {
+	state := s.getChannelState(key)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	s.mu.Lock()
+	if contains(conn.Subscriptions, key) && !contains(s.subscriptions[key], conn.ID) {
+		s.subscriptions[key] = append(s.subscriptions[key], conn.ID)
+	}
+	s.mu.Unlock()
+
+	fn(state)
+}
+
+// getChannelState 获取或创建频道状态
+func (s *WebSocketServerImpl) getChannelState(key string) *channelState {
+	s.channelStatesMu.Lock()
+	defer s.channelStatesMu.Unlock()
+
+	state, exists := s.channelStates[key]
+	if !exists {
+		state = &channelState{
+			replay: newReplayBuffer(s.config.ReplayBufferSize),
+		}
+		s.channelStates[key] = state
+	}
+	return state
+}
+
+// loadSnapshots 批量加载频道快照，失败时只记录日志
+func (s *WebSocketServerImpl) loadSnapshots(channels []*Channel) map[string]interface{} {
+	s.mu.RLock()
+	provider := s.snapshotProvider
+	s.mu.RUnlock()
+
+	if provider == nil || len(channels) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.SnapshotTimeout)
+	defer cancel()
+
+	snapshots, err := provider.GetSnapshots(ctx, channels)
+	if err != nil {
+		s.logger.Warn("加载频道快照失败", zap.Int("channels", len(channels)), zap.Error(err))
+		return nil
+	}
+	return snapshots
+}
+
+// sendAck 发送订阅相关确认
+func (s *WebSocketServerImpl) sendAck(conn *Connection, ackType, requestID string, channels []*Channel, errors []*ChannelError) {
+	ack := &SubscriptionAck{
+		Type:      ackType,
+		ID:        requestID,
+		Channels:  make([]string, len(channels)),
+		Errors:    errors,
+		Timestamp: time.Now().UnixMilli(),
+	}
+	for i, channel := range channels {
+		ack.Channels[i] = channel.String()
+	}
+	s.sendMessage(conn, ack)
+}
+
+// sendSnapshot 发送频道快照
+func (s *WebSocketServerImpl) sendSnapshot(conn *Connection, key string, seq uint64, data interface{}) {
+	s.sendMessage(conn, &ChannelMessage{
+		Type:      MessageTypeSnapshot,
+		Channel:   key,
+		Seq:       seq,
+		Data:      data,
+		Timestamp: time.Now().UnixMilli(),
+	})
+}
@@ -0,0 +1,214 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+)
+
+// SetMonitoringConfigSource 设置按监控配置 ID 订阅时使用的配置来源
+func (s *WebSocketServerImpl) SetMonitoringConfigSource(source MonitoringConfigSource) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+	s.configSource = source
+}
+
+// handleFilterSubscribe 处理过滤订阅：合并监控配置并校验条件，确认后推送当前已满足条件的交易对
+func (s *WebSocketServerImpl) handleFilterSubscribe(conn *Connection, msg *Message) {
+	if msg.Filter == nil {
+		s.sendError(conn, ErrCodeInvalidFilter, "缺少过滤条件")
+		return
+	}
+
+	predicate := msg.Filter
+	if predicate.ConfigID != 0 {
+		s.filtersMu.Lock()
+		source := s.configSource
+		s.filtersMu.Unlock()
+
+		if source == nil {
+			s.sendError(conn, ErrCodeInvalidFilter, "未启用监控配置")
+			return
+		}
+		config, err := source.GetByID(predicate.ConfigID)
+		if err != nil {
+			s.sendError(conn, ErrCodeInvalidFilter, fmt.Sprintf("加载监控配置 %d 失败: %v", predicate.ConfigID, err))
+			return
+		}
+		predicate = mergeMonitoringConfig(config, predicate)
+	}
+
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+
+	filters := s.filters[conn.ID]
+	if limit := s.config.MaxFiltersPerConnection; limit > 0 && len(filters) >= limit {
+		s.sendError(conn, ErrCodeFilterLimit, fmt.Sprintf("单个连接最多 %d 个过滤订阅", limit))
+		return
+	}
+
+	conn.filterSeq++
+	filter, err := newConnectionFilter(fmt.Sprintf("f%d", conn.filterSeq), conn, predicate)
+	if err != nil {
+		s.sendError(conn, ErrCodeInvalidFilter, err.Error())
+		return
+	}
+	s.filters[conn.ID] = append(filters, filter)
+
+	now := time.Now().UnixMilli()
+	s.sendMessage(conn, &FilterAck{
+		Type:      MessageTypeFilterSubscribed,
+		ID:        msg.ID,
+		FilterID:  filter.id,
+		Filter:    predicate,
+		Timestamp: now,
+	})
+
+	symbols := make([]string, 0, len(s.metrics))
+	for symbol := range s.metrics {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		metrics := s.metrics[symbol]
+		if !filter.match(metrics) {
+			continue
+		}
+		filter.matching[symbol] = true
+		s.sendMessage(conn, &FilterEvent{
+			Type:      MessageTypeFilterEnter,
+			FilterID:  filter.id,
+			Symbol:    symbol,
+			Data:      metrics.clone(),
+			Timestamp: now,
+		})
+	}
+
+	s.logger.Info("客户端订阅过滤条件",
+		zap.String("conn_id", conn.ID),
+		zap.String("filter_id", filter.id),
+		zap.Int64("config_id", predicate.ConfigID),
+		zap.Int("matching", len(filter.matching)),
+	)
+}
+
+// handleFilterUnsubscribe 处理取消过滤订阅
+func (s *WebSocketServerImpl) handleFilterUnsubscribe(conn *Connection, msg *Message) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+
+	filters := s.filters[conn.ID]
+	for i, filter := range filters {
+		if filter.id != msg.FilterID {
+			continue
+		}
+
+		filters = append(filters[:i], filters[i+1:]...)
+		if len(filters) == 0 {
+			delete(s.filters, conn.ID)
+		} else {
+			s.filters[conn.ID] = filters
+		}
+
+		s.sendMessage(conn, &FilterAck{
+			Type:      MessageTypeFilterUnsubscribed,
+			ID:        msg.ID,
+			FilterID:  filter.id,
+			Timestamp: time.Now().UnixMilli(),
+		})
+		s.logger.Info("客户端取消过滤订阅", zap.String("conn_id", conn.ID), zap.String("filter_id", filter.id))
+		return
+	}
+
+	s.sendError(conn, ErrCodeFilterNotFound, fmt.Sprintf("过滤订阅 %s 不存在", msg.FilterID))
+}
+
+// removeFilters 连接关闭时删除其过滤订阅
+func (s *WebSocketServerImpl) removeFilters(connID string) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+	delete(s.filters, connID)
+}
+
+// UpdateMarketMetrics 更新交易对的行情指标并评估过滤订阅
+// 配置了背板时经由背板发布，由每个实例评估本地连接的过滤订阅
+func (s *WebSocketServerImpl) UpdateMarketMetrics(ticker *cache.ScannerTicker) error {
+	if ticker == nil || ticker.Symbol == "" {
+		return fmt.Errorf("行情指标缺少交易对")
+	}
+
+	if s.backplane != nil {
+		data, err := json.Marshal(ticker)
+		if err != nil {
+			return fmt.Errorf("序列化行情指标失败: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteWait)
+		defer cancel()
+		return s.backplane.PublishMetrics(ctx, ticker.Symbol, data)
+	}
+
+	s.evaluateFilters(ticker)
+	return nil
+}
+
+// evaluateFilters 合并行情指标，向状态发生变化或持续满足条件的过滤订阅推送事件
+func (s *WebSocketServerImpl) evaluateFilters(ticker *cache.ScannerTicker) {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+
+	metrics, exists := s.metrics[ticker.Symbol]
+	if !exists {
+		metrics = &MarketMetrics{Symbol: ticker.Symbol}
+		s.metrics[ticker.Symbol] = metrics
+	}
+	metrics.merge(ticker)
+
+	// 所有事件共享同一份副本
+	var snapshot *MarketMetrics
+	now := time.Now().UnixMilli()
+
+	for _, filters := range s.filters {
+		for _, filter := range filters {
+			matched := filter.match(metrics)
+			wasMatching := filter.matching[metrics.Symbol]
+
+			var eventType string
+			switch {
+			case matched && wasMatching:
+				eventType = MessageTypeFilterUpdate
+			case matched:
+				eventType = MessageTypeFilterEnter
+				filter.matching[metrics.Symbol] = true
+			case wasMatching:
+				eventType = MessageTypeFilterExit
+				delete(filter.matching, metrics.Symbol)
+			default:
+				continue
+			}
+
+			if snapshot == nil {
+				snapshot = metrics.clone()
+			}
+			if err := s.sendMessage(filter.conn, &FilterEvent{
+				Type:      eventType,
+				FilterID:  filter.id,
+				Symbol:    metrics.Symbol,
+				Data:      snapshot,
+				Timestamp: now,
+			}); err != nil {
+				s.logger.Warn("过滤订阅推送失败",
+					zap.String("conn_id", filter.conn.ID),
+					zap.String("filter_id", filter.id),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package websocket
+
+import (
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// writeLoop 连接专属的写协程，按队列顺序写出消息，写失败时关闭连接
+func (s *WebSocketServerImpl) writeLoop(conn *Connection) {
+	queue := conn.outbound
+
+	for {
+		message, wait, closed := queue.next(time.Now())
+		if closed {
+			return
+		}
+
+		if message != nil {
+			if err := s.writeMessage(conn, message); err != nil {
+				s.logger.Warn("WebSocket写入失败", zap.String("conn_id", conn.ID), zap.Error(err))
+				queue.close()
+				s.abortConnection(conn)
+				return
+			}
+			queue.recordSent()
+			continue
+		}
+
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+
+		select {
+		case <-queue.notify:
+		case <-timeout:
+		case <-queue.done:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// disconnectSlowConsumer 断开发送队列持续溢出的连接，并告知客户端原因
+func (s *WebSocketServerImpl) disconnectSlowConsumer(conn *Connection) {
+	stats := conn.outbound.stats()
+	conn.outbound.close()
+	s.outboundCounters.slowConsumerDisconnects.Add(1)
+
+	s.logger.Warn("客户端消费过慢，断开连接",
+		zap.String("conn_id", conn.ID),
+		zap.Uint64("sent", stats.Sent),
+		zap.Uint64("dropped", stats.Dropped),
+		zap.Uint64("conflated", stats.Conflated),
+	)
+
+	s.closeConnection(conn, CloseCodeSlowConsumer, "slow consumer")
+}
+
+// applyMaxRate 应用客户端请求的最大推送频率，频率无效时返回错误消息并返回 false
+func (s *WebSocketServerImpl) applyMaxRate(conn *Connection, rate *float64) bool {
+	if rate == nil {
+		return true
+	}
+	if *rate < 0 || math.IsNaN(*rate) || math.IsInf(*rate, 0) {
+		s.sendError(conn, ErrCodeInvalidRate, "max_rate 必须是非负数")
+		return false
+	}
+	if conn.outbound != nil {
+		conn.outbound.setMaxRate(*rate)
+	}
+	return true
+}
+
+// GetOutboundMetrics 获取所有连接发送队列的汇总统计
+func (s *WebSocketServerImpl) GetOutboundMetrics() *OutboundMetrics {
+	metrics := &OutboundMetrics{
+		Sent:                    s.outboundCounters.sent.Load(),
+		Dropped:                 s.outboundCounters.dropped.Load(),
+		Conflated:               s.outboundCounters.conflated.Load(),
+		SlowConsumerDisconnects: s.outboundCounters.slowConsumerDisconnects.Load(),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, conn := range s.connections {
+		if conn.outbound != nil {
+			metrics.Queued += conn.outbound.len()
+		}
+	}
+	return metrics
+}
@@ -0,0 +1,149 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ServeSSE 以 Server-Sent Events 推送频道数据，供无法使用 WebSocket 的客户端使用
+//
+// 请求格式为 GET ?channels=ticker:BTCUSDT,signals，频道模型、消息格式和认证方式与 WebSocket 相同，
+// Token 可以放在查询参数 token 或 Authorization: Bearer 头中。SSE 连接与 WebSocket 连接一起
+// 登记在服务器中，共享频道推送、广播（包括 BroadcastManager）和发送队列；
+// 重连时携带 Last-Event-ID 即可从重放缓冲区补发遗漏的消息
+func (s *WebSocketServerImpl) ServeSSE(w http.ResponseWriter, r *http.Request) {
+	if s.GetConnectionCount() >= s.config.MaxConnections {
+		http.Error(w, "连接数已达上限", http.StatusServiceUnavailable)
+		return
+	}
+
+	var channels []string
+	for _, name := range strings.Split(r.URL.Query().Get("channels"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			channels = append(channels, name)
+		}
+	}
+	if len(channels) == 0 {
+		http.Error(w, "缺少 channels 参数", http.StatusBadRequest)
+		return
+	}
+	for _, name := range channels {
+		if _, chErr := ParseChannel(name); chErr != nil {
+			http.Error(w, chErr.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	sequences, err := parseEventID(lastEventID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// SSE 能直接返回 HTTP 状态码，认证失败不需要建立连接
+	claims, _, authErr := s.authenticate(r)
+	if authErr != nil {
+		s.logger.Warn("SSE认证失败", zap.String("remote_addr", r.RemoteAddr), zap.Error(authErr))
+		http.Error(w, authErr.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// 只沿用本次请求的频道的序列号
+	cursor := make(map[string]uint64, len(sequences))
+	for _, name := range channels {
+		channel, _ := ParseChannel(name)
+		if seq, ok := sequences[channel.String()]; ok {
+			cursor[channel.String()] = seq
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	stream, err := newSSEStream(w, cancel, cursor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	connection := &Connection{
+		ID:            generateConnectionID(),
+		Subscriptions: make([]string, 0),
+		LastPing:      time.Now(),
+		CreatedAt:     time.Now(),
+		IsActive:      true,
+		Encoding:      EncodingJSON,
+		encoder:       jsonEncoder{},
+		sse:           stream,
+	}
+	if claims != nil {
+		connection.UserID = claims.UserID
+		connection.ExpiresAt = claims.ExpiresAt
+	}
+	hasOutbound := s.attachOutbound(connection)
+
+	if err := s.addConnection(connection); err != nil {
+		if connection.outbound != nil {
+			connection.outbound.close()
+		}
+		s.logger.Warn("拒绝SSE连接", zap.String("user_id", connection.UserID), zap.Error(err))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // 关闭 nginx 的响应缓冲
+	w.WriteHeader(http.StatusOK)
+	stream.writeRetry(s.config.SSERetryInterval, s.config.WriteWait)
+
+	if hasOutbound {
+		go s.writeLoop(connection)
+	}
+	expiry := s.watchExpiry(connection)
+
+	s.saveSession(connection)
+	s.logger.Info("新SSE连接建立",
+		zap.String("conn_id", connection.ID),
+		zap.String("user_id", connection.UserID),
+		zap.Strings("channels", channels),
+		zap.Bool("resume", len(cursor) > 0),
+	)
+
+	if len(cursor) > 0 {
+		resume := make(map[string]uint64, len(cursor))
+		for channel, seq := range cursor {
+			resume[channel] = seq
+		}
+		s.handleResume(connection, &Message{Type: MessageTypeResume, Channels: channels, Sequences: resume})
+	} else {
+		s.handleChannelSubscribe(connection, "", channels)
+	}
+
+	// 心跳注释防止代理因空闲断开连接
+	heartbeat := time.NewTicker(s.config.SSEHeartbeatInterval)
+	defer heartbeat.Stop()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-heartbeat.C:
+			if err := stream.writeComment("heartbeat", s.config.WriteWait); err != nil {
+				break loop
+			}
+		}
+	}
+
+	stream.shutdown()
+	s.releaseConnection(connection, expiry)
+	s.logger.Info("SSE连接关闭", zap.String("conn_id", connection.ID))
+}
@@ -2,6 +2,9 @@ package websocket
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -291,3 +294,43 @@ func TestWebSocketServer_MessageValidation(t *testing.T) {
 	// 验证连接仍然活跃
 	assert.Equal(t, 1, server.GetConnectionCount())
 }
+
+// TestWebSocketServer_CheckOrigin 测试握手来源校验
+func TestWebSocketServer_CheckOrigin(t *testing.T) {
+	config := DefaultServerConfig()
+	config.AllowedOrigins = []string{"https://app.example.com"}
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.handleWebSocket))
+	defer httpServer.Close()
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+
+	dial := func(origin string) (*http.Response, error) {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if err == nil {
+			conn.Close()
+		}
+		return resp, err
+	}
+
+	// 不带 Origin 的非浏览器客户端、允许列表中的来源和同源请求可以升级
+	for _, origin := range []string{"", "https://APP.example.com", httpServer.URL} {
+		_, err := dial(origin)
+		assert.NoError(t, err, origin)
+	}
+
+	// 其他来源在握手时被拒绝
+	resp, err := dial("https://evil.example.com")
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// "*" 允许所有来源
+	config.AllowedOrigins = []string{"*"}
+	_, err = dial("https://evil.example.com")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,83 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+)
+
+// SnapshotProvider 频道快照提供者，订阅或恢复时用于下发当前状态
+type SnapshotProvider interface {
+	// GetSnapshots 批量获取频道快照，返回 频道名 -> Payload，没有快照的频道不出现在结果中
+	GetSnapshots(ctx context.Context, channels []*Channel) (map[string]interface{}, error)
+}
+
+// priceCacheSnapshotProvider 基于 Redis 价格缓存的快照提供者，仅支持 ticker 频道
+type priceCacheSnapshotProvider struct {
+	priceCache cache.PriceCache
+}
+
+// NewPriceCacheSnapshotProvider 创建基于价格缓存的快照提供者
+func NewPriceCacheSnapshotProvider(priceCache cache.PriceCache) SnapshotProvider {
+	return &priceCacheSnapshotProvider{
+		priceCache: priceCache,
+	}
+}
+
+// GetSnapshots 批量获取 ticker 频道快照
+func (p *priceCacheSnapshotProvider) GetSnapshots(ctx context.Context, channels []*Channel) (map[string]interface{}, error) {
+	var symbols []string
+	for _, channel := range channels {
+		if channel.Type == ChannelTicker {
+			symbols = append(symbols, channel.Symbol)
+		}
+	}
+
+	snapshots := make(map[string]interface{})
+	if len(symbols) == 0 {
+		return snapshots, nil
+	}
+
+	prices, err := p.priceCache.GetMultiplePrices(ctx, symbols)
+	if err != nil {
+		return nil, fmt.Errorf("获取价格快照失败: %w", err)
+	}
+
+	for symbol, price := range prices {
+		snapshots[TickerChannel(symbol)] = TickerPayloadFromPrice(price)
+	}
+
+	return snapshots, nil
+}
+
+// TickerPayloadFromPrice 将缓存价格转换为 ticker 频道数据
+// 推送协议（JSON/MessagePack/Protobuf）中价格为 double，精确的十进制价格在这里转换为 float64
+func TickerPayloadFromPrice(price *cache.PriceData) *TickerPayload {
+	payload := &TickerPayload{
+		Symbol:    price.Symbol,
+		LastPrice: price.LastPrice.InexactFloat64(),
+		Timestamp: price.Timestamp.UnixMilli(),
+	}
+
+	if price.BidPrice != nil {
+		payload.BidPrice = price.BidPrice.InexactFloat64()
+	}
+	if price.AskPrice != nil {
+		payload.AskPrice = price.AskPrice.InexactFloat64()
+	}
+	if price.High24h != nil {
+		payload.High24h = price.High24h.InexactFloat64()
+	}
+	if price.Low24h != nil {
+		payload.Low24h = price.Low24h.InexactFloat64()
+	}
+	if price.BaseVolume != nil {
+		payload.Volume24h = price.BaseVolume.InexactFloat64()
+	}
+	if price.Change24h != nil {
+		payload.ChangeRate24h = *price.Change24h
+	}
+
+	return payload
+}
@@ -0,0 +1,236 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// sseEvent 测试中解析的 SSE 事件
+type sseEvent struct {
+	ID      string
+	Event   string
+	Data    string
+	Comment string
+	Retry   string
+}
+
+// sseClient 逐个读取 SSE 事件
+type sseClient struct {
+	resp   *http.Response
+	reader *bufio.Reader
+	cancel context.CancelFunc
+}
+
+func dialSSE(t *testing.T, url string, header http.Header) (*sseClient, *http.Response) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	client := &sseClient{resp: resp, reader: bufio.NewReader(resp.Body), cancel: cancel}
+	t.Cleanup(client.close)
+	return client, resp
+}
+
+func (c *sseClient) close() {
+	c.cancel()
+	c.resp.Body.Close()
+}
+
+// next 读取下一个事件（以空行结束）
+func (c *sseClient) next(t *testing.T) *sseEvent {
+	event := &sseEvent{}
+	for {
+		line, err := c.reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return event
+		}
+
+		switch {
+		case strings.HasPrefix(line, ": "):
+			event.Comment = strings.TrimPrefix(line, ": ")
+		case strings.HasPrefix(line, "id: "):
+			event.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.Data = strings.TrimPrefix(line, "data: ")
+		case strings.HasPrefix(line, "retry: "):
+			event.Retry = strings.TrimPrefix(line, "retry: ")
+		}
+	}
+}
+
+// nextMessage 跳过心跳，读取下一条数据事件
+func (c *sseClient) nextMessage(t *testing.T) (*sseEvent, *channelTestMessage) {
+	for {
+		event := c.next(t)
+		if event.Data == "" {
+			continue
+		}
+		var msg channelTestMessage
+		require.NoError(t, json.Unmarshal([]byte(event.Data), &msg))
+		return event, &msg
+	}
+}
+
+// TestEventID 测试事件 ID 编解码
+func TestEventID(t *testing.T) {
+	cursor := map[string]uint64{"ticker:BTCUSDT": 12, "signals": 3, "kline:1m:ETHUSDT": 0}
+	eventID := formatEventID(cursor)
+	assert.Equal(t, "kline:1m:ETHUSDT=0,signals=3,ticker:BTCUSDT=12", eventID)
+
+	parsed, err := parseEventID(eventID)
+	require.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+
+	parsed, err = parseEventID("ticker:btcusdt=5")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{"ticker:BTCUSDT": 5}, parsed)
+
+	parsed, err = parseEventID("")
+	require.NoError(t, err)
+	assert.Empty(t, parsed)
+
+	for _, invalid := range []string{"ticker:BTCUSDT", "ticker:BTCUSDT=x", "unknown=1", "=1"} {
+		_, err := parseEventID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// TestWebSocketServer_SSE 测试 SSE 推送、心跳和 Last-Event-ID 恢复
+func TestWebSocketServer_SSE(t *testing.T) {
+	config := DefaultServerConfig()
+	config.SSEHeartbeatInterval = 50 * time.Millisecond
+
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	httpServer := httptest.NewServer(http.HandlerFunc(server.ServeSSE))
+	defer httpServer.Close()
+	streamURL := httpServer.URL + "?channels=ticker:btcusdt,signals"
+
+	t.Run("参数错误", func(t *testing.T) {
+		resp, err := http.Get(httpServer.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, err = http.Get(httpServer.URL + "?channels=unknown")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		req, _ := http.NewRequest(http.MethodGet, streamURL, nil)
+		req.Header.Set("Last-Event-ID", "invalid")
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	client, resp := dialSSE(t, streamURL, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "3000", client.next(t).Retry)
+
+	_, ack := client.nextMessage(t)
+	assert.Equal(t, MessageTypeSubscribed, ack.Type)
+	assert.ElementsMatch(t, []string{"ticker:BTCUSDT", "signals"}, ack.Channels)
+
+	require.NoError(t, server.Publish("ticker:BTCUSDT", &TickerPayload{Symbol: "BTCUSDT", LastPrice: 50000}))
+	event, msg := client.nextMessage(t)
+	assert.Equal(t, "ticker:BTCUSDT=1", event.ID)
+	assert.Equal(t, MessageTypeUpdate, msg.Type)
+	assert.Equal(t, uint64(1), msg.Seq)
+
+	require.NoError(t, server.Publish("signals", &SignalPayload{Symbol: "ETHUSDT", Type: "volume_spike"}))
+	event, _ = client.nextMessage(t)
+	assert.Equal(t, "signals=1,ticker:BTCUSDT=1", event.ID)
+
+	// 空闲时发送心跳注释
+	var heartbeat *sseEvent
+	for heartbeat == nil || heartbeat.Comment == "" {
+		heartbeat = client.next(t)
+	}
+	assert.Equal(t, "heartbeat", heartbeat.Comment)
+
+	// 断线期间的消息在重连后按 Last-Event-ID 补发
+	client.close()
+	assert.Eventually(t, func() bool { return server.GetConnectionCount() == 0 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, server.Publish("ticker:BTCUSDT", &TickerPayload{Symbol: "BTCUSDT", LastPrice: 50001}))
+	require.NoError(t, server.Publish("ticker:BTCUSDT", &TickerPayload{Symbol: "BTCUSDT", LastPrice: 50002}))
+
+	client, _ = dialSSE(t, streamURL, http.Header{"Last-Event-ID": {event.ID}})
+	_, ack = client.nextMessage(t)
+	assert.Equal(t, MessageTypeResumed, ack.Type)
+
+	event, msg = client.nextMessage(t)
+	assert.Equal(t, uint64(2), msg.Seq)
+	assert.Equal(t, "signals=1,ticker:BTCUSDT=2", event.ID)
+	event, msg = client.nextMessage(t)
+	assert.Equal(t, uint64(3), msg.Seq)
+	assert.Equal(t, "signals=1,ticker:BTCUSDT=3", event.ID)
+
+	// 与 WebSocket 连接共享广播路径
+	broadcastManager := NewBroadcastManager(nil, server, zap.NewNop())
+	require.NoError(t, broadcastManager.Start(context.Background()))
+	defer broadcastManager.Stop(context.Background())
+
+	require.NoError(t, broadcastManager.BroadcastToAll(&AlertPayload{ID: "a-1", Level: "warning", Title: "维护通知"}))
+	event, _ = client.nextMessage(t)
+	assert.Contains(t, event.Data, `"id":"a-1"`)
+}
+
+// TestWebSocketServer_SSEAuth 测试 SSE 认证和关闭事件
+func TestWebSocketServer_SSEAuth(t *testing.T) {
+	config := DefaultServerConfig()
+	config.MaxConnectionsPerUser = 1
+
+	server := NewWebSocketServer(config, zap.NewNop()).(*WebSocketServerImpl)
+	httpServer := httptest.NewServer(http.HandlerFunc(server.ServeSSE))
+	defer httpServer.Close()
+	streamURL := httpServer.URL + "?channels=signals"
+
+	server.SetAuthManager(&expiringAuthManager{expiresAt: time.Now().Add(300 * time.Millisecond)})
+
+	client, resp := dialSSE(t, streamURL, http.Header{"Authorization": {"Bearer token"}})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// 超过单用户连接数上限
+	resp, err := http.Get(streamURL + "&token=token")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// Token 过期后发送 close 事件并结束响应
+	var closeEvent *sseEvent
+	for closeEvent == nil || closeEvent.Event == "" {
+		closeEvent = client.next(t)
+	}
+	assert.Equal(t, "close", closeEvent.Event)
+	assert.JSONEq(t, `{"code":4002,"reason":"token expired"}`, closeEvent.Data)
+	assert.Eventually(t, func() bool { return server.GetConnectionCount() == 0 }, time.Second, 10*time.Millisecond)
+
+	_, setupManager, _ := setupAuthServer(t, config)
+	server.SetAuthManager(setupManager)
+	resp, err = http.Get(streamURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
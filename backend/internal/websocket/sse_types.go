@@ -0,0 +1,161 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sseStream SSE 连接的响应流
+//
+// 频道消息的事件 ID 是该连接已收到的各频道序列号，格式为 "频道=序列号,频道=序列号"；
+// 客户端重连时通过 Last-Event-ID 带回，服务端据此从重放缓冲区补发遗漏的消息
+type sseStream struct {
+	mu         sync.Mutex
+	w          http.ResponseWriter
+	flusher    http.Flusher
+	controller *http.ResponseController
+	cursor     map[string]uint64
+	closed     bool
+	cancel     context.CancelFunc
+}
+
+// newSSEStream 创建 SSE 响应流，ResponseWriter 不支持 Flush 时返回错误
+func newSSEStream(w http.ResponseWriter, cancel context.CancelFunc, cursor map[string]uint64) (*sseStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("响应不支持流式输出")
+	}
+	if cursor == nil {
+		cursor = make(map[string]uint64)
+	}
+	return &sseStream{
+		w:          w,
+		flusher:    flusher,
+		controller: http.NewResponseController(w),
+		cursor:     cursor,
+		cancel:     cancel,
+	}, nil
+}
+
+// writeMessage 以 JSON 写出一条消息，频道消息同时更新事件 ID
+func (st *sseStream) writeMessage(message interface{}, writeWait time.Duration) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("JSON 编码失败: %w", err)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.closed {
+		return fmt.Errorf("SSE 连接已关闭")
+	}
+
+	var frame strings.Builder
+	if channelMessage, ok := message.(*ChannelMessage); ok {
+		st.cursor[channelMessage.Channel] = channelMessage.Seq
+		frame.WriteString("id: ")
+		frame.WriteString(formatEventID(st.cursor))
+		frame.WriteString("\n")
+	}
+	frame.WriteString("data: ")
+	frame.Write(data)
+	frame.WriteString("\n\n")
+
+	return st.writeLocked(frame.String(), writeWait)
+}
+
+// writeComment 写出注释行，用于心跳和保持代理连接
+func (st *sseStream) writeComment(comment string, writeWait time.Duration) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.closed {
+		return fmt.Errorf("SSE 连接已关闭")
+	}
+	return st.writeLocked(": "+comment+"\n\n", writeWait)
+}
+
+// writeRetry 告知客户端断线后的重连间隔
+func (st *sseStream) writeRetry(retry, writeWait time.Duration) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.writeLocked(fmt.Sprintf("retry: %d\n\n", retry.Milliseconds()), writeWait)
+}
+
+func (st *sseStream) writeLocked(frame string, writeWait time.Duration) error {
+	// 底层连接不支持写超时时忽略
+	st.controller.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := st.w.Write([]byte(frame)); err != nil {
+		return err
+	}
+	st.flusher.Flush()
+	return nil
+}
+
+// close 发送 close 事件告知关闭原因（与 WebSocket 关闭码一致）后结束响应
+func (st *sseStream) close(code int, reason string) {
+	st.mu.Lock()
+	if !st.closed {
+		data, _ := json.Marshal(map[string]interface{}{"code": code, "reason": reason})
+		st.writeLocked("event: close\ndata: "+string(data)+"\n\n", time.Second)
+	}
+	st.mu.Unlock()
+	st.cancel()
+}
+
+// shutdown 标记响应流已结束，此后的写入都会失败
+// 处理函数返回前必须调用，否则写协程可能在响应结束后继续写入
+func (st *sseStream) shutdown() {
+	st.mu.Lock()
+	st.closed = true
+	st.mu.Unlock()
+	st.cancel()
+}
+
+// formatEventID 把各频道序列号编码为事件 ID，频道按名称排序
+func formatEventID(cursor map[string]uint64) string {
+	channels := make([]string, 0, len(cursor))
+	for channel := range cursor {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	parts := make([]string, len(channels))
+	for i, channel := range channels {
+		parts[i] = channel + "=" + strconv.FormatUint(cursor[channel], 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseEventID 解析 Last-Event-ID，空字符串返回空结果
+func parseEventID(eventID string) (map[string]uint64, error) {
+	cursor := make(map[string]uint64)
+	if strings.TrimSpace(eventID) == "" {
+		return cursor, nil
+	}
+
+	for _, part := range strings.Split(eventID, ",") {
+		sep := strings.LastIndex(part, "=")
+		if sep <= 0 {
+			return nil, fmt.Errorf("事件ID格式无效: %s", part)
+		}
+		channel, chErr := ParseChannel(part[:sep])
+		if chErr != nil {
+			return nil, chErr
+		}
+		seq, err := strconv.ParseUint(part[sep+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("事件ID序列号无效: %s", part)
+		}
+		cursor[channel.String()] = seq
+	}
+	return cursor, nil
+}
@@ -2,9 +2,13 @@ package websocket
 
 import (
 	"context"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/haxrd/cryptosignal-hunter/internal/cache"
+	"github.com/haxrd/cryptosignal-hunter/internal/data_collection"
 )
 
 // WebSocketServer WebSocket服务器接口
@@ -23,11 +27,35 @@ type WebSocketServer interface {
 	BroadcastToAll(message interface{}) error
 	SendToConnection(connID string, message interface{}) error
 
+	// 设置订阅和恢复时使用的快照提供者
+	SetSnapshotProvider(provider SnapshotProvider)
+
+	// 设置跨实例消息背板，需在 Start 之前调用
+	SetBackplane(backplane Backplane)
+
+	// 设置握手认证使用的认证管理器（与 REST API 相同），为空时不要求认证
+	SetAuthManager(authManager data_collection.AuthManager)
+
+	// 频道推送，data 必须是与频道类型匹配的 Payload
+	Publish(channel string, data interface{}) error
+
+	// 过滤订阅：按交易对合并行情指标并向满足条件的订阅推送
+	UpdateMarketMetrics(ticker *cache.ScannerTicker) error
+	// 设置按监控配置 ID 订阅时使用的配置来源
+	SetMonitoringConfigSource(source MonitoringConfigSource)
+
+	// 发送队列统计
+	GetOutboundMetrics() *OutboundMetrics
+
+	// SSE 推送入口，由 REST API 挂载到 /api/v1/stream
+	ServeSSE(w http.ResponseWriter, r *http.Request)
+
 	// 订阅管理
 	Subscribe(connID string, symbols []string) error
 	Unsubscribe(connID string, symbols []string) error
 	GetSubscriptions(connID string) []string
 	GetSubscribers(symbol string) []string
+	IsConnectionActive(connID string) bool
 }
 
 // Connection WebSocket连接
@@ -38,15 +66,30 @@ type Connection struct {
 	LastPing      time.Time       `json:"last_ping"`
 	CreatedAt     time.Time       `json:"created_at"`
 	IsActive      bool            `json:"is_active"`
+	Encoding      Encoding        `json:"encoding"`
+	UserID        string          `json:"user_id,omitempty"` // 认证用户，未启用认证时为空
+	ExpiresAt     time.Time       `json:"expires_at"`        // Token 过期时间，到期后服务端关闭连接
+
+	writeMu   sync.Mutex     // 串行化写操作，gorilla/websocket 不支持并发写
+	outbound  *outboundQueue // 发送队列，为空时同步写出
+	encoder   MessageEncoder // 推送消息编码器，为空时使用 JSON
+	filterSeq int            // 过滤订阅 ID 计数，由 filtersMu 保护
+	sse       *sseStream     // SSE 连接的响应流，WebSocket 连接为空
 }
 
 // Message WebSocket消息
 type Message struct {
-	Type      string      `json:"type"`
-	Symbol    string      `json:"symbol,omitempty"`
-	Symbols   []string    `json:"symbols,omitempty"`
-	Data      interface{} `json:"data,omitempty"`
-	Timestamp int64       `json:"timestamp"`
+	Type      string            `json:"type"`
+	ID        string            `json:"id,omitempty"` // 客户端请求ID，在确认消息中原样返回
+	Symbol    string            `json:"symbol,omitempty"`
+	Symbols   []string          `json:"symbols,omitempty"`
+	Channels  []string          `json:"channels,omitempty"`
+	Sequences map[string]uint64 `json:"sequences,omitempty"` // resume 时各频道最后收到的序列号
+	MaxRate   *float64          `json:"max_rate,omitempty"`  // 每个频道每秒最多推送的更新数，0 表示不限制
+	Filter    *FilterPredicate  `json:"filter,omitempty"`    // subscribe_filter 的过滤条件
+	FilterID  string            `json:"filter_id,omitempty"` // unsubscribe_filter 要取消的过滤订阅
+	Data      interface{}       `json:"data,omitempty"`
+	Timestamp int64             `json:"timestamp"`
 }
 
 // PriceUpdateMessage 价格更新消息
@@ -98,8 +141,38 @@ type ServerConfig struct {
 	HandshakeTimeout time.Duration `json:"handshake_timeout" yaml:"handshake_timeout"`
 
 	// 消息配置
-	MessageQueueSize int `json:"message_queue_size" yaml:"message_queue_size"`
-	MaxMessageSize   int `json:"max_message_size" yaml:"max_message_size"`
+	MessageQueueSize    int           `json:"message_queue_size" yaml:"message_queue_size"` // 每个连接的发送队列容量，0 表示同步写出
+	MaxMessageSize      int           `json:"max_message_size" yaml:"max_message_size"`
+	SlowConsumerTimeout time.Duration `json:"slow_consumer_timeout" yaml:"slow_consumer_timeout"` // 发送队列持续溢出多久后断开连接
+
+	// 压缩配置（permessage-deflate，由客户端在握手时协商）
+	EnableCompression    bool `json:"enable_compression" yaml:"enable_compression"`
+	CompressionLevel     int  `json:"compression_level" yaml:"compression_level"`         // flate 压缩级别，1 最快，9 压缩率最高
+	CompressionThreshold int  `json:"compression_threshold" yaml:"compression_threshold"` // 小于该字节数的消息不压缩
+
+	// 订阅配置
+	MaxSubscriptionsPerConnection int `json:"max_subscriptions_per_connection" yaml:"max_subscriptions_per_connection"`
+	MaxFiltersPerConnection       int `json:"max_filters_per_connection" yaml:"max_filters_per_connection"`
+
+	// 认证配置，仅在设置了认证管理器时生效
+	RequiredPermission    string `json:"required_permission" yaml:"required_permission"`           // 建立连接需要的权限，为空表示只校验 Token
+	MaxConnectionsPerUser int    `json:"max_connections_per_user" yaml:"max_connections_per_user"` // 0 表示不限制
+	MaxSymbolsPerUser     int    `json:"max_symbols_per_user" yaml:"max_symbols_per_user"`         // 用户所有连接合计订阅的交易对数，0 表示不限制
+
+	// 重放和快照配置
+	ReplayBufferSize int           `json:"replay_buffer_size" yaml:"replay_buffer_size"` // 每个频道保留的最近消息数
+	SnapshotTimeout  time.Duration `json:"snapshot_timeout" yaml:"snapshot_timeout"`
+
+	// SSE 配置
+	SSEHeartbeatInterval time.Duration `json:"sse_heartbeat_interval" yaml:"sse_heartbeat_interval"` // 心跳注释的发送间隔
+	SSERetryInterval     time.Duration `json:"sse_retry_interval" yaml:"sse_retry_interval"`         // 建议客户端断线后的重连间隔
+
+	// 多实例配置
+	PresenceInterval time.Duration `json:"presence_interval" yaml:"presence_interval"` // 向 Redis 上报在线状态的间隔
+
+	// 跨域配置：浏览器握手的 Origin 必须同源或在列表中，"*" 表示允许所有来源
+	// 不带 Origin 的非浏览器客户端不受限制
+	AllowedOrigins []string `json:"allowed_origins" yaml:"allowed_origins"`
 }
 
 // ServerStatus 服务器状态
@@ -125,5 +198,26 @@ func DefaultServerConfig() *ServerConfig {
 		HandshakeTimeout: 10 * time.Second,
 		MessageQueueSize: 256,
 		MaxMessageSize:   512,
+
+		SlowConsumerTimeout: 10 * time.Second,
+
+		EnableCompression:    true,
+		CompressionLevel:     1,
+		CompressionThreshold: 256,
+
+		MaxSubscriptionsPerConnection: 100,
+		MaxFiltersPerConnection:       10,
+
+		RequiredPermission:    "read",
+		MaxConnectionsPerUser: 10,
+		MaxSymbolsPerUser:     200,
+
+		ReplayBufferSize: 256,
+		SnapshotTimeout:  2 * time.Second,
+
+		SSEHeartbeatInterval: 15 * time.Second,
+		SSERetryInterval:     3 * time.Second,
+
+		PresenceInterval: 30 * time.Second,
 	}
 }
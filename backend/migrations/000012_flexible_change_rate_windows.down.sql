@@ -0,0 +1,8 @@
+-- 恢复价格变化率时间窗口的固定取值限制
+
+DELETE FROM price_change_rates WHERE window_size NOT IN ('1m', '5m', '15m', '1h', '4h', '1d');
+
+ALTER TABLE price_change_rates DROP CONSTRAINT IF EXISTS chk_window_size_valid;
+ALTER TABLE price_change_rates ADD CONSTRAINT chk_window_size_valid CHECK (window_size IN ('1m', '5m', '15m', '1h', '4h', '1d'));
+
+COMMENT ON COLUMN price_change_rates.window_size IS '时间窗口大小：1m, 5m, 15m';
@@ -0,0 +1,6 @@
+-- 放开价格变化率时间窗口限制，支持任意 数字+单位 的窗口（如 10s、30s、3m、1h、4h、24h）
+
+ALTER TABLE price_change_rates DROP CONSTRAINT IF EXISTS chk_window_size_valid;
+ALTER TABLE price_change_rates ADD CONSTRAINT chk_window_size_valid CHECK (window_size ~ '^[1-9][0-9]*[smhd]$');
+
+COMMENT ON COLUMN price_change_rates.window_size IS '时间窗口大小：数字+单位(s/m/h/d)，如 10s、1m、4h、1d';
@@ -0,0 +1,7 @@
+-- 删除多周期K线连续聚合（按依赖关系从上层到下层删除，刷新策略随视图一起删除）
+DROP MATERIALIZED VIEW IF EXISTS klines_1d;
+DROP MATERIALIZED VIEW IF EXISTS klines_4h;
+DROP MATERIALIZED VIEW IF EXISTS klines_1h;
+DROP MATERIALIZED VIEW IF EXISTS klines_15m;
+DROP MATERIALIZED VIEW IF EXISTS klines_5m;
+DROP MATERIALIZED VIEW IF EXISTS klines_1m;
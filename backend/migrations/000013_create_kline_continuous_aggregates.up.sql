@@ -0,0 +1,149 @@
+-- 创建多周期K线连续聚合（需要 TimescaleDB 2.9+ 支持分层连续聚合）
+-- 1m K线由 price_ticks 聚合生成，5m/15m/1h/4h/1d 依次由上一级聚合汇总，
+-- 用于补全交易所未拉取到的K线，保证采集了行情的交易对都有完整的各周期K线
+-- 使用 WITH NO DATA 创建以便在迁移事务内执行，历史数据由刷新策略逐步物化
+
+-- ========================================
+-- 1m：由 price_ticks 聚合
+-- ========================================
+
+-- price_ticks 中的成交量是交易所推送的24小时滚动成交量，
+-- 这里取桶内首尾之差作为该分钟成交量的近似值（滚动窗口移出的成交可能使差值为负，按0处理）
+CREATE MATERIALIZED VIEW klines_1m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    symbol,
+    time_bucket(INTERVAL '1 minute', timestamp) AS timestamp,
+    first(last_price, timestamp)                AS open,
+    max(last_price)                             AS high,
+    min(last_price)                             AS low,
+    last(last_price, timestamp)                 AS close,
+    GREATEST(COALESCE(last(base_volume, timestamp) - first(base_volume, timestamp), 0), 0)   AS base_volume,
+    GREATEST(COALESCE(last(quote_volume, timestamp) - first(quote_volume, timestamp), 0), 0) AS quote_volume
+FROM price_ticks
+GROUP BY symbol, time_bucket(INTERVAL '1 minute', timestamp)
+WITH NO DATA;
+
+-- ========================================
+-- 5m/15m/1h/4h/1d：逐级汇总
+-- ========================================
+
+CREATE MATERIALIZED VIEW klines_5m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    symbol,
+    time_bucket(INTERVAL '5 minutes', timestamp) AS timestamp,
+    first(open, timestamp)                       AS open,
+    max(high)                                    AS high,
+    min(low)                                     AS low,
+    last(close, timestamp)                       AS close,
+    sum(base_volume)                             AS base_volume,
+    sum(quote_volume)                            AS quote_volume
+FROM klines_1m
+GROUP BY symbol, time_bucket(INTERVAL '5 minutes', timestamp)
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW klines_15m
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    symbol,
+    time_bucket(INTERVAL '15 minutes', timestamp) AS timestamp,
+    first(open, timestamp)                        AS open,
+    max(high)                                     AS high,
+    min(low)                                      AS low,
+    last(close, timestamp)                        AS close,
+    sum(base_volume)                              AS base_volume,
+    sum(quote_volume)                             AS quote_volume
+FROM klines_5m
+GROUP BY symbol, time_bucket(INTERVAL '15 minutes', timestamp)
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW klines_1h
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    symbol,
+    time_bucket(INTERVAL '1 hour', timestamp) AS timestamp,
+    first(open, timestamp)                    AS open,
+    max(high)                                 AS high,
+    min(low)                                  AS low,
+    last(close, timestamp)                    AS close,
+    sum(base_volume)                          AS base_volume,
+    sum(quote_volume)                         AS quote_volume
+FROM klines_15m
+GROUP BY symbol, time_bucket(INTERVAL '1 hour', timestamp)
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW klines_4h
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    symbol,
+    time_bucket(INTERVAL '4 hours', timestamp) AS timestamp,
+    first(open, timestamp)                     AS open,
+    max(high)                                  AS high,
+    min(low)                                   AS low,
+    last(close, timestamp)                     AS close,
+    sum(base_volume)                           AS base_volume,
+    sum(quote_volume)                          AS quote_volume
+FROM klines_1h
+GROUP BY symbol, time_bucket(INTERVAL '4 hours', timestamp)
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW klines_1d
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    symbol,
+    time_bucket(INTERVAL '1 day', timestamp) AS timestamp,
+    first(open, timestamp)                   AS open,
+    max(high)                                AS high,
+    min(low)                                 AS low,
+    last(close, timestamp)                   AS close,
+    sum(base_volume)                         AS base_volume,
+    sum(quote_volume)                        AS quote_volume
+FROM klines_4h
+GROUP BY symbol, time_bucket(INTERVAL '1 day', timestamp)
+WITH NO DATA;
+
+-- ========================================
+-- 配置刷新策略
+-- ========================================
+
+-- 刷新窗口（最长7天）不超过 price_ticks 的保留期（000014 起为14天），避免刷新已删除的原始数据时清空聚合结果；
+-- 各聚合视图的保留期由 000014 按周期配置
+-- end_offset 留出一个周期，未完成的桶由实时聚合（materialized_only = false）直接从下级数据计算
+SELECT add_continuous_aggregate_policy('klines_1m',
+    start_offset => INTERVAL '3 hours',
+    end_offset => INTERVAL '1 minute',
+    schedule_interval => INTERVAL '1 minute');
+
+SELECT add_continuous_aggregate_policy('klines_5m',
+    start_offset => INTERVAL '6 hours',
+    end_offset => INTERVAL '5 minutes',
+    schedule_interval => INTERVAL '5 minutes');
+
+SELECT add_continuous_aggregate_policy('klines_15m',
+    start_offset => INTERVAL '1 day',
+    end_offset => INTERVAL '15 minutes',
+    schedule_interval => INTERVAL '15 minutes');
+
+SELECT add_continuous_aggregate_policy('klines_1h',
+    start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour');
+
+SELECT add_continuous_aggregate_policy('klines_4h',
+    start_offset => INTERVAL '7 days',
+    end_offset => INTERVAL '4 hours',
+    schedule_interval => INTERVAL '1 hour');
+
+SELECT add_continuous_aggregate_policy('klines_1d',
+    start_offset => INTERVAL '7 days',
+    end_offset => INTERVAL '1 day',
+    schedule_interval => INTERVAL '1 hour');
+
+-- 添加注释
+COMMENT ON VIEW klines_1m IS '1分钟K线连续聚合，由 price_ticks 生成，交易所K线缺失时使用';
+COMMENT ON VIEW klines_5m IS '5分钟K线连续聚合，由 klines_1m 汇总';
+COMMENT ON VIEW klines_15m IS '15分钟K线连续聚合，由 klines_5m 汇总';
+COMMENT ON VIEW klines_1h IS '1小时K线连续聚合，由 klines_15m 汇总';
+COMMENT ON VIEW klines_4h IS '4小时K线连续聚合，由 klines_1h 汇总';
+COMMENT ON VIEW klines_1d IS '1天K线连续聚合，由 klines_4h 汇总';
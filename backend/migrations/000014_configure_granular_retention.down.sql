@@ -0,0 +1,13 @@
+-- 恢复 000004 的统一保留策略（30天）
+SELECT remove_retention_policy('klines_4h', if_exists => true);
+SELECT remove_retention_policy('klines_1h', if_exists => true);
+SELECT remove_retention_policy('klines_15m', if_exists => true);
+SELECT remove_retention_policy('klines_5m', if_exists => true);
+SELECT remove_retention_policy('klines_1m', if_exists => true);
+
+DROP TABLE IF EXISTS kline_retention_policies;
+
+SELECT add_retention_policy('klines', INTERVAL '30 days', if_not_exists => true);
+
+SELECT remove_retention_policy('price_ticks', if_exists => true);
+SELECT add_retention_policy('price_ticks', INTERVAL '30 days');
@@ -0,0 +1,52 @@
+-- 按数据类型和K线周期区分保留期
+-- 原始行情只保留14天；klines 表混合了所有周期，TimescaleDB 保留策略按 chunk 整块删除，
+-- 无法区分周期，改为由应用按 kline_retention_policies 逐周期删除过期K线（1d/1w 永久保留）
+
+-- ========================================
+-- price_ticks：保留期由30天改为14天
+-- ========================================
+
+SELECT remove_retention_policy('price_ticks', if_exists => true);
+SELECT add_retention_policy('price_ticks', INTERVAL '14 days');
+
+-- ========================================
+-- klines：移除按 chunk 的统一保留策略，改为按周期保留
+-- ========================================
+
+SELECT remove_retention_policy('klines', if_exists => true);
+
+CREATE TABLE IF NOT EXISTS kline_retention_policies (
+    granularity VARCHAR(10) PRIMARY KEY,
+    retention_days INTEGER CHECK (retention_days IS NULL OR retention_days > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 交易所K线周期有大小写两种写法，两种都配置
+INSERT INTO kline_retention_policies (granularity, retention_days) VALUES
+    ('1m', 30),
+    ('5m', 90),
+    ('15m', 180),
+    ('30m', 180),
+    ('1h', 365),
+    ('1H', 365),
+    ('4h', 730),
+    ('4H', 730),
+    ('1d', NULL),
+    ('1D', NULL),
+    ('1w', NULL),
+    ('1W', NULL)
+ON CONFLICT (granularity) DO NOTHING;
+
+COMMENT ON TABLE kline_retention_policies IS 'K线按周期的保留期，未配置的周期永久保留';
+COMMENT ON COLUMN kline_retention_policies.retention_days IS '保留天数，NULL 表示永久保留';
+
+-- ========================================
+-- K线连续聚合：与 klines 表的周期保留期一致，1d 永久保留
+-- ========================================
+
+-- 保留期均大于刷新窗口（start_offset），删除旧数据不会被刷新覆盖
+SELECT add_retention_policy('klines_1m', INTERVAL '30 days', if_not_exists => true);
+SELECT add_retention_policy('klines_5m', INTERVAL '90 days', if_not_exists => true);
+SELECT add_retention_policy('klines_15m', INTERVAL '180 days', if_not_exists => true);
+SELECT add_retention_policy('klines_1h', INTERVAL '365 days', if_not_exists => true);
+SELECT add_retention_policy('klines_4h', INTERVAL '730 days', if_not_exists => true);
@@ -0,0 +1,2 @@
+-- 只删除归档清单，已导出的 Parquet 文件保留在归档存储中
+DROP TABLE IF EXISTS archive_manifests;
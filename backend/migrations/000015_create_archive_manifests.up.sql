@@ -0,0 +1,25 @@
+-- 过期数据归档清单
+-- 保留策略删除 chunk 之前，归档任务把 price_ticks / klines 按 表/交易对/天 导出为 Parquet 文件
+-- （本地目录或 S3 兼容存储），每个文件在此记录一行；历史读取按清单决定从归档还是数据库读取
+
+CREATE TABLE IF NOT EXISTS archive_manifests (
+    id BIGSERIAL PRIMARY KEY,
+    source_table VARCHAR(50) NOT NULL,
+    symbol VARCHAR(50) NOT NULL,
+    day DATE NOT NULL,
+    path TEXT NOT NULL,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    min_timestamp TIMESTAMPTZ NOT NULL,
+    max_timestamp TIMESTAMPTZ NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_archive_manifests_table_symbol_day UNIQUE (source_table, symbol, day)
+);
+
+-- 按表查询最近归档的日期
+CREATE INDEX IF NOT EXISTS idx_archive_manifests_table_day
+    ON archive_manifests (source_table, day DESC);
+
+COMMENT ON TABLE archive_manifests IS '已归档为 Parquet 的数据清单，每个 表/交易对/天 一个文件';
+COMMENT ON COLUMN archive_manifests.day IS '数据所属日期（UTC）';
+COMMENT ON COLUMN archive_manifests.path IS '文件在归档存储中的路径';
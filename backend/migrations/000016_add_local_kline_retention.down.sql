@@ -0,0 +1,2 @@
+-- 只删除保留期配置，已写入的本地K线保留在 klines 中
+DELETE FROM kline_retention_policies WHERE granularity LIKE 'local\_%';
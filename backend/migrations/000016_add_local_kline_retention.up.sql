@@ -0,0 +1,13 @@
+-- 本地聚合K线的保留期
+-- 由行情流本地聚合的K线以 local_ 前缀的周期写入 klines（如 local_1m），不覆盖同一时间的交易所K线；
+-- 保留期与同周期的交易所K线一致
+
+INSERT INTO kline_retention_policies (granularity, retention_days) VALUES
+    ('local_1s', 1),
+    ('local_1m', 30),
+    ('local_5m', 90),
+    ('local_15m', 180),
+    ('local_30m', 180),
+    ('local_1h', 365),
+    ('local_4h', 730)
+ON CONFLICT (granularity) DO NOTHING;